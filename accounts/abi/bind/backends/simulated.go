@@ -41,6 +41,7 @@ import (
 	"github.com/celo-org/celo-blockchain/eth/filters"
 	"github.com/celo-org/celo-blockchain/ethdb"
 	"github.com/celo-org/celo-blockchain/event"
+	"github.com/celo-org/celo-blockchain/internal/ethapi"
 	"github.com/celo-org/celo-blockchain/params"
 	"github.com/celo-org/celo-blockchain/rpc"
 )
@@ -692,6 +693,7 @@ func (m callmsg) GasPrice() *big.Int                   { return m.CallMsg.GasPri
 func (m callmsg) FeeCurrency() *common.Address         { return m.CallMsg.FeeCurrency }
 func (m callmsg) GatewayFeeRecipient() *common.Address { return m.CallMsg.GatewayFeeRecipient }
 func (m callmsg) GatewayFee() *big.Int                 { return m.CallMsg.GatewayFee }
+func (m callmsg) FeePayer() *common.Address            { return nil }
 func (m callmsg) Gas() uint64                          { return m.CallMsg.Gas }
 func (m callmsg) Value() *big.Int                      { return m.CallMsg.Value }
 func (m callmsg) Data() []byte                         { return m.CallMsg.Data }
@@ -746,6 +748,10 @@ func (fb *filterBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.
 	return nullSubscription()
 }
 
+func (fb *filterBackend) SubscribeTxPoolChangeEvent(ch chan<- core.TxPoolChangeEvent) event.Subscription {
+	return nullSubscription()
+}
+
 func (fb *filterBackend) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription {
 	return fb.bc.SubscribeChainEvent(ch)
 }
@@ -768,6 +774,10 @@ func (fb *filterBackend) ServiceFilter(ctx context.Context, ms *bloombits.Matche
 	panic("not supported")
 }
 
+func (fb *filterBackend) ArchiveProxy() *ethapi.ArchiveProxy {
+	return nil
+}
+
 func nullSubscription() event.Subscription {
 	return event.NewSubscription(func(quit <-chan struct{}) error {
 		<-quit
@@ -0,0 +1,229 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package balancewatch watches the CELO and fee currency balances of the
+// accounts operating a validator, such as its oracle and attestation service
+// wallets, and warns when they run low on gas money.
+package balancewatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/contracts/currency"
+	"github.com/celo-org/celo-blockchain/core"
+	"github.com/celo-org/celo-blockchain/core/state"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/core/vm"
+	"github.com/celo-org/celo-blockchain/event"
+	"github.com/celo-org/celo-blockchain/log"
+	"github.com/celo-org/celo-blockchain/metrics"
+	"github.com/celo-org/celo-blockchain/node"
+	"github.com/celo-org/celo-blockchain/rpc"
+)
+
+const (
+	// chainHeadChanSize is the size of channel listening to ChainHeadEvent.
+	chainHeadChanSize = 10
+
+	// alertInterval is the number of blocks between two warnings about the
+	// same balance remaining low.
+	alertInterval = 720
+
+	// CELO is the threshold key of the native currency.
+	CELO = "celo"
+)
+
+var lowGauge = metrics.NewRegisteredGauge("balancewatch/low", nil)
+
+// tokenUnit is the number of base units in a CELO or stable token.
+var tokenUnit = new(big.Float).SetInt(big.NewInt(1e18))
+
+// Config are the configuration parameters of the balance watcher.
+type Config struct {
+	Accounts   []common.Address    // Accounts whose balances are watched
+	Thresholds map[string]*big.Int `toml:",omitempty"` // Minimum balances, keyed by "celo" or fee currency address
+}
+
+// backend encompasses the functionality needed to read the balances
+type backend interface {
+	SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription
+	StateAndHeaderByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, error)
+	NewEVMRunner(ctx context.Context, header *types.Header, state vm.StateDB) vm.EVMRunner
+}
+
+// balanceKey identifies a watched balance. The currency is the zero address
+// for CELO.
+type balanceKey struct {
+	account  common.Address
+	currency common.Address
+}
+
+// Watcher reads the balances of the configured accounts in CELO and every
+// whitelisted fee currency at each block, reporting them as metrics and
+// warning about the ones below their threshold.
+type Watcher struct {
+	config  *Config
+	backend backend
+
+	celoThreshold   *big.Int
+	tokenThresholds map[common.Address]*big.Int
+
+	low    map[balanceKey]uint64 // Balances below threshold, with the block last warned at
+	gauges map[balanceKey]metrics.GaugeFloat64
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates a balance watcher and registers it in the node.
+func New(stack *node.Node, backend backend, config *Config) error {
+	w, err := newWatcher(backend, config)
+	if err != nil {
+		return err
+	}
+	stack.RegisterLifecycle(w)
+	return nil
+}
+
+func newWatcher(backend backend, config *Config) (*Watcher, error) {
+	if len(config.Accounts) == 0 {
+		return nil, errors.New("no accounts to watch")
+	}
+	w := &Watcher{
+		config:          config,
+		backend:         backend,
+		tokenThresholds: make(map[common.Address]*big.Int),
+		low:             make(map[balanceKey]uint64),
+		gauges:          make(map[balanceKey]metrics.GaugeFloat64),
+		quit:            make(chan struct{}),
+	}
+	for key, threshold := range config.Thresholds {
+		switch {
+		case strings.EqualFold(key, CELO):
+			w.celoThreshold = threshold
+		case common.IsHexAddress(key):
+			w.tokenThresholds[common.HexToAddress(key)] = threshold
+		default:
+			return nil, fmt.Errorf("invalid balance threshold currency %q", key)
+		}
+	}
+	return w, nil
+}
+
+// Start implements node.Lifecycle, starting to watch the balances.
+func (w *Watcher) Start() error {
+	headCh := make(chan core.ChainHeadEvent, chainHeadChanSize)
+	headSub := w.backend.SubscribeChainHeadEvent(headCh)
+
+	w.wg.Add(1)
+	go w.loop(headCh, headSub)
+
+	log.Info("Balance watcher started", "accounts", len(w.config.Accounts))
+	return nil
+}
+
+// Stop implements node.Lifecycle, terminating the watcher.
+func (w *Watcher) Stop() error {
+	close(w.quit)
+	w.wg.Wait()
+	return nil
+}
+
+func (w *Watcher) loop(headCh chan core.ChainHeadEvent, headSub event.Subscription) {
+	defer w.wg.Done()
+	defer headSub.Unsubscribe()
+
+	for {
+		select {
+		case ev := <-headCh:
+			if err := w.refresh(ev.Block.Header()); err != nil {
+				log.Debug("Failed to read watched balances", "number", ev.Block.Number(), "err", err)
+			}
+		case <-headSub.Err():
+			return
+		case <-w.quit:
+			return
+		}
+	}
+}
+
+// refresh reads the balances as of the given header.
+func (w *Watcher) refresh(head *types.Header) error {
+	statedb, header, err := w.backend.StateAndHeaderByNumberOrHash(context.Background(), rpc.BlockNumberOrHashWithHash(head.Hash(), false))
+	if err != nil {
+		return err
+	}
+	vmRunner := w.backend.NewEVMRunner(context.Background(), header, statedb)
+
+	currencies, err := currency.CurrencyWhitelist(vmRunner)
+	if err != nil {
+		// Keep watching the CELO balances before the contracts are deployed
+		currencies = nil
+	}
+	number := header.Number.Uint64()
+	for _, account := range w.config.Accounts {
+		w.check(number, balanceKey{account: account}, statedb.GetBalance(account), w.celoThreshold)
+		for _, token := range currencies {
+			balance, err := currency.GetBalanceOf(vmRunner, account, token)
+			if err != nil {
+				return err
+			}
+			w.check(number, balanceKey{account: account, currency: token}, balance, w.tokenThresholds[token])
+		}
+	}
+	lowGauge.Update(int64(len(w.low)))
+	return nil
+}
+
+// check reports a balance read at the given block, warning if it is below the
+// threshold. Warnings are repeated every alertInterval blocks while the balance
+// remains low.
+func (w *Watcher) check(number uint64, key balanceKey, balance, threshold *big.Int) {
+	gauge, ok := w.gauges[key]
+	if !ok {
+		gauge = metrics.GetOrRegisterGaugeFloat64(fmt.Sprintf("balancewatch/%s/%s", strings.ToLower(key.account.Hex()), key.currencyName()), nil)
+		w.gauges[key] = gauge
+	}
+	tokens, _ := new(big.Float).Quo(new(big.Float).SetInt(balance), tokenUnit).Float64()
+	gauge.Update(tokens)
+
+	warned, low := w.low[key]
+	if threshold == nil || balance.Cmp(threshold) >= 0 {
+		if low {
+			log.Info("Watched balance restored", "account", key.account, "currency", key.currencyName(), "balance", balance, "threshold", threshold)
+			delete(w.low, key)
+		}
+		return
+	}
+	if !low || number >= warned+alertInterval {
+		log.Warn("Watched balance below threshold, top up to keep paying for gas", "account", key.account, "currency", key.currencyName(), "balance", balance, "threshold", threshold)
+		w.low[key] = number
+	}
+}
+
+// currencyName returns the name of the currency in metrics and logs.
+func (k balanceKey) currencyName() string {
+	if k.currency == (common.Address{}) {
+		return CELO
+	}
+	return strings.ToLower(k.currency.Hex())
+}
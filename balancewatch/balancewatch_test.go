@@ -0,0 +1,89 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package balancewatch
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/celo-org/celo-blockchain/common"
+)
+
+func TestThresholds(t *testing.T) {
+	var (
+		account = common.Address{0x01}
+		token   = common.Address{0xcd}
+	)
+	w, err := newWatcher(nil, &Config{
+		Accounts: []common.Address{account},
+		Thresholds: map[string]*big.Int{
+			"CELO":      big.NewInt(100),
+			token.Hex(): big.NewInt(50),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.celoThreshold.Int64() != 100 || w.tokenThresholds[token].Int64() != 50 {
+		t.Fatalf("thresholds mismatch: have %v, %v", w.celoThreshold, w.tokenThresholds)
+	}
+	if _, err := newWatcher(nil, &Config{Accounts: []common.Address{account}, Thresholds: map[string]*big.Int{"cusd": big.NewInt(1)}}); err == nil {
+		t.Error("no error for unknown currency")
+	}
+	if _, err := newWatcher(nil, &Config{}); err == nil {
+		t.Error("no error for missing accounts")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	account := common.Address{0x02}
+	w, err := newWatcher(nil, &Config{Accounts: []common.Address{account}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		key       = balanceKey{account: account}
+		threshold = big.NewInt(1e18)
+	)
+	// Low balances are warned about once per alert interval
+	w.check(10, key, big.NewInt(5e17), threshold)
+	if warned, ok := w.low[key]; !ok || warned != 10 {
+		t.Fatalf("low balance not recorded: have %d, %v", warned, ok)
+	}
+	w.check(11, key, big.NewInt(4e17), threshold)
+	if warned := w.low[key]; warned != 10 {
+		t.Errorf("warning repeated before the alert interval, at %d", warned)
+	}
+	w.check(10+alertInterval, key, big.NewInt(4e17), threshold)
+	if warned := w.low[key]; warned != 10+alertInterval {
+		t.Errorf("warning not repeated after the alert interval, last at %d", warned)
+	}
+	// Topping up clears the warning
+	w.check(800, key, big.NewInt(2e18), threshold)
+	if _, ok := w.low[key]; ok {
+		t.Error("restored balance still low")
+	}
+	// Balances without threshold are only reported
+	token := balanceKey{account: account, currency: common.Address{0xcd}}
+	w.check(801, token, new(big.Int), nil)
+	if _, ok := w.low[token]; ok {
+		t.Error("balance without threshold found low")
+	}
+	if name := token.currencyName(); name != "0xcd00000000000000000000000000000000000000" {
+		t.Errorf("currency name mismatch: have %s", name)
+	}
+}
@@ -0,0 +1,294 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/celo-org/celo-blockchain/cmd/utils"
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/consensus/istanbul"
+	istanbulBackend "github.com/celo-org/celo-blockchain/consensus/istanbul/backend"
+	"github.com/celo-org/celo-blockchain/core"
+	"github.com/celo-org/celo-blockchain/core/rawdb"
+	"github.com/celo-org/celo-blockchain/core/state"
+	"github.com/celo-org/celo-blockchain/core/vm"
+	"github.com/celo-org/celo-blockchain/eth"
+	"github.com/celo-org/celo-blockchain/metrics"
+	"github.com/olekukonko/tablewriter"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	benchBlocksFlag = cli.StringFlag{
+		Name:  "blocks",
+		Usage: "RLP encoded blocks to replay, as written by geth export",
+	}
+	benchGenesisFlag = cli.StringFlag{
+		Name:  "genesis",
+		Usage: "Genesis file of the chain (default = genesis of the selected network)",
+	}
+	benchTopFlag = cli.IntFlag{
+		Name:  "top",
+		Usage: "Number of opcodes to report, ordered by total time",
+		Value: 32,
+	}
+
+	benchCommand = cli.Command{
+		Name:     "bench",
+		Usage:    "Benchmark commands",
+		Category: "BLOCKCHAIN COMMANDS",
+		Subcommands: []cli.Command{
+			{
+				Action: utils.MigrateFlags(benchImport),
+				Name:   "import",
+				Usage:  "Replay blocks against an in-memory state and report timings",
+				Flags: []cli.Flag{
+					benchBlocksFlag,
+					benchGenesisFlag,
+					benchTopFlag,
+					utils.NetworkFlag,
+					utils.AlfajoresFlag,
+					utils.BaklavaFlag,
+				},
+				Description: `
+    geth bench import --blocks chain.rlp
+
+imports the blocks into a fresh in-memory database, starting from the genesis,
+and reports the time spent per EVM opcode and per system call made by the
+protocol, e.g. while finalizing blocks. The blocks must start right after the
+genesis. Opcode timing adds overhead to every step, so the numbers are meant
+for comparing implementations, not as absolute measures.`,
+			},
+		},
+	}
+)
+
+// benchImport replays an exported chain segment and reports where the time
+// went.
+func benchImport(ctx *cli.Context) error {
+	file := ctx.String(benchBlocksFlag.Name)
+	if file == "" {
+		utils.Fatalf("Missing --%s flag", benchBlocksFlag.Name)
+	}
+	genesis := utils.MakeGenesis(ctx)
+	if path := ctx.String(benchGenesisFlag.Name); path != "" {
+		genesis = new(core.Genesis)
+		blob, err := os.Open(path)
+		if err != nil {
+			utils.Fatalf("Failed to read genesis file: %v", err)
+		}
+		err = json.NewDecoder(blob).Decode(genesis)
+		blob.Close()
+		if err != nil {
+			utils.Fatalf("Invalid genesis file: %v", err)
+		}
+	}
+	if genesis == nil {
+		genesis = core.MainnetGenesisBlock()
+	}
+	// System calls are timed through metrics, which are created on first use
+	metrics.Enabled = true
+
+	db := rawdb.NewMemoryDatabase()
+	chainConfig, _, err := core.SetupGenesisBlock(db, genesis)
+	if err != nil {
+		utils.Fatalf("Failed to write genesis block: %v", err)
+	}
+	config := *istanbul.DefaultConfig
+	config.ReplicaStateDBPath = ""
+	config.RoundStateDBPath = ""
+	config.ValidatorEnodeDBPath = ""
+	config.VersionCertificateDBPath = ""
+	if err := istanbul.ApplyParamsChainConfigToConfig(chainConfig, &config); err != nil {
+		utils.Fatalf("Invalid istanbul configuration: %v", err)
+	}
+	engine := istanbulBackend.New(&config, rawdb.NewMemoryDatabase()).(*istanbulBackend.Backend)
+	defer engine.Close()
+
+	// Prefetching would run the tracer concurrently and skew the timings
+	var (
+		timer = newOpcodeTimer()
+		cache = &core.CacheConfig{
+			TrieCleanLimit:      eth.DefaultConfig.TrieCleanCache,
+			TrieCleanNoPrefetch: true,
+			TrieDirtyLimit:      eth.DefaultConfig.TrieDirtyCache,
+			TrieTimeLimit:       eth.DefaultConfig.TrieTimeout,
+		}
+		vmcfg = vm.Config{Debug: true, Tracer: timer}
+	)
+	chain, err := core.NewBlockChain(db, cache, chainConfig, engine, vmcfg, nil, nil)
+	if err != nil {
+		utils.Fatalf("Failed to create blockchain: %v", err)
+	}
+	defer chain.Stop()
+
+	engine.SetChain(chain, chain.CurrentBlock, func(hash common.Hash) (*state.StateDB, error) {
+		return chain.StateAt(chain.GetHeaderByHash(hash).Root)
+	})
+	start := time.Now()
+	if err := utils.ImportChain(chain, file); err != nil {
+		utils.Fatalf("Import error: %v", err)
+	}
+	elapsed := time.Since(start)
+
+	var (
+		head = chain.CurrentBlock().NumberU64()
+		txs  int
+		gas  uint64
+	)
+	for number := uint64(1); number <= head; number++ {
+		block := chain.GetBlockByNumber(number)
+		txs += len(block.Transactions())
+		gas += block.GasUsed()
+	}
+	fmt.Printf("Replayed %d blocks with %d transactions and %d gas in %v (%.2f Mgas/s)\n\n",
+		head, txs, gas, common.PrettyDuration(elapsed), float64(gas)/1e6/elapsed.Seconds())
+
+	timer.report(os.Stdout, ctx.Int(benchTopFlag.Name))
+	reportSystemCalls(os.Stdout)
+	return nil
+}
+
+// opcodeTiming is the accumulated execution time of a single opcode.
+type opcodeTiming struct {
+	count uint64
+	total time.Duration
+}
+
+// opcodeTimer is an EVM tracer attributing the time between consecutive steps
+// to the opcode executed in the first of them.
+type opcodeTimer struct {
+	ops [256]opcodeTiming
+
+	env  *vm.EVM   // EVM of the last step, steps are only related within one
+	last vm.OpCode // Opcode of the last step
+	at   time.Time // Time of the last step, zero if there is none
+}
+
+func newOpcodeTimer() *opcodeTimer {
+	return new(opcodeTimer)
+}
+
+// flush attributes the time since the last step to its opcode.
+func (t *opcodeTimer) flush(now time.Time) {
+	if !t.at.IsZero() {
+		t.ops[t.last].count++
+		t.ops[t.last].total += now.Sub(t.at)
+	}
+	t.at = time.Time{}
+}
+
+func (t *opcodeTimer) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	t.at = time.Time{}
+	return nil
+}
+
+func (t *opcodeTimer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, rStack *vm.ReturnStack, rData []byte, contract *vm.Contract, depth int, err error) error {
+	now := time.Now()
+	if env != t.env {
+		// Static system calls are not announced through CaptureStart, so a new
+		// EVM is the only sign of a new top level call.
+		t.env, t.at = env, time.Time{}
+	}
+	t.flush(now)
+	t.last, t.at = op, now
+	return nil
+}
+
+func (t *opcodeTimer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, rStack *vm.ReturnStack, contract *vm.Contract, depth int, err error) error {
+	return nil
+}
+
+func (t *opcodeTimer) CaptureEnd(output []byte, gasUsed uint64, elapsed time.Duration, err error) error {
+	t.flush(time.Now())
+	return nil
+}
+
+// report writes the timings of the top most expensive opcodes.
+func (t *opcodeTimer) report(w io.Writer, top int) {
+	var (
+		ops   []int
+		total time.Duration
+	)
+	for op := range t.ops {
+		if t.ops[op].count > 0 {
+			ops = append(ops, op)
+			total += t.ops[op].total
+		}
+	}
+	sort.Slice(ops, func(i, j int) bool { return t.ops[ops[i]].total > t.ops[ops[j]].total })
+	if len(ops) > top {
+		ops = ops[:top]
+	}
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Opcode", "Count", "Total", "Average", "Share"})
+	for _, op := range ops {
+		timing := t.ops[op]
+		table.Append([]string{
+			vm.OpCode(op).String(),
+			fmt.Sprint(timing.count),
+			common.PrettyDuration(timing.total).String(),
+			common.PrettyDuration(timing.total / time.Duration(timing.count)).String(),
+			fmt.Sprintf("%.2f%%", 100*float64(timing.total)/float64(total)),
+		})
+	}
+	table.Render()
+	fmt.Fprintf(w, "Total EVM execution time: %v\n\n", common.PrettyDuration(total))
+}
+
+// reportSystemCalls writes the timings of the system calls, as gathered in the
+// default metrics registry. The timers only keep a sample of the measurements,
+// so the totals are estimated from the call counts and average durations.
+func reportSystemCalls(w io.Writer) {
+	const prefix = "contracts/systemcall/"
+
+	type call struct {
+		name  string
+		timer metrics.Timer
+	}
+	var calls []call
+	metrics.DefaultRegistry.Each(func(name string, i interface{}) {
+		if timer, ok := i.(metrics.Timer); ok && strings.HasPrefix(name, prefix) {
+			calls = append(calls, call{strings.TrimPrefix(name, prefix), timer.Snapshot()})
+		}
+	})
+	total := func(timer metrics.Timer) time.Duration {
+		return time.Duration(float64(timer.Count()) * timer.Mean())
+	}
+	sort.Slice(calls, func(i, j int) bool { return total(calls[i].timer) > total(calls[j].timer) })
+
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"System call", "Count", "Estimated total", "Average", "Max"})
+	for _, c := range calls {
+		table.Append([]string{
+			c.name,
+			fmt.Sprint(c.timer.Count()),
+			common.PrettyDuration(total(c.timer)).String(),
+			common.PrettyDuration(c.timer.Mean()).String(),
+			common.PrettyDuration(c.timer.Max()).String(),
+		})
+	}
+	table.Render()
+}
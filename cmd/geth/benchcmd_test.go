@@ -0,0 +1,50 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/celo-org/celo-blockchain/core/vm"
+	"github.com/celo-org/celo-blockchain/core/vm/runtime"
+)
+
+// Tests that the opcode timer attributes every executed step to its opcode.
+func TestOpcodeTimer(t *testing.T) {
+	// PUSH1 1, PUSH1 2, ADD, POP, STOP
+	code := []byte{byte(vm.PUSH1), 1, byte(vm.PUSH1), 2, byte(vm.ADD), byte(vm.POP), byte(vm.STOP)}
+
+	timer := newOpcodeTimer()
+	for i := 0; i < 3; i++ {
+		if _, _, err := runtime.Execute(code, nil, &runtime.Config{EVMConfig: vm.Config{Debug: true, Tracer: timer}}); err != nil {
+			t.Fatalf("execution failed: %v", err)
+		}
+	}
+	for op, want := range map[vm.OpCode]uint64{vm.PUSH1: 6, vm.ADD: 3, vm.POP: 3, vm.STOP: 3, vm.MUL: 0} {
+		if have := timer.ops[op].count; have != want {
+			t.Errorf("%v count mismatch: have %d, want %d", op, have, want)
+		}
+	}
+	var out bytes.Buffer
+	timer.report(&out, 2)
+	// Header and two opcode rows
+	if report := out.String(); !strings.Contains(report, "PUSH1") || strings.Count(report, "\n| ") != 3 {
+		t.Errorf("unexpected report:\n%s", report)
+	}
+}
@@ -35,6 +35,7 @@ import (
 	"github.com/celo-org/celo-blockchain/core/types"
 	"github.com/celo-org/celo-blockchain/eth/downloader"
 	"github.com/celo-org/celo-blockchain/event"
+	"github.com/celo-org/celo-blockchain/internal/era"
 	"github.com/celo-org/celo-blockchain/log"
 	"github.com/celo-org/celo-blockchain/metrics"
 	"github.com/celo-org/celo-blockchain/trie"
@@ -77,6 +78,7 @@ The dumpgenesis command dumps the genesis block configuration in JSON format to
 		ArgsUsage: "<filename> (<filename 2> ... <filename N>) ",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.NetworkFlag,
 			utils.AlfajoresFlag,
 			utils.BaklavaFlag,
 			utils.CacheFlag,
@@ -112,6 +114,7 @@ processing will proceed even if an individual RLP-file import failure occurs.`,
 		ArgsUsage: "<filename> [<blockNumFirst> <blockNumLast>]",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.NetworkFlag,
 			utils.AlfajoresFlag,
 			utils.BaklavaFlag,
 			utils.CacheFlag,
@@ -124,6 +127,48 @@ Optional second and third arguments control the first and
 last block to write. In this mode, the file will be appended
 if already existing. If the file ends with .gz, the output will
 be gzipped.`,
+	}
+	importHistoryCommand = cli.Command{
+		Action:    utils.MigrateFlags(importHistory),
+		Name:      "import-history",
+		Usage:     "Import blockchain history from era archives",
+		ArgsUsage: "<dir>",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+			utils.NetworkFlag,
+			utils.AlfajoresFlag,
+			utils.BaklavaFlag,
+			utils.CacheFlag,
+			utils.SyncModeFlag,
+			utils.GCModeFlag,
+			utils.TxLookupLimitFlag,
+		},
+		Category: "BLOCKCHAIN COMMANDS",
+		Description: `
+The import-history command verifies every era archive of the selected network
+found in the given directory against the accumulator it is named after, and
+imports the contained blocks in order, skipping those already present.`,
+	}
+	exportHistoryCommand = cli.Command{
+		Action:    utils.MigrateFlags(exportHistory),
+		Name:      "export-history",
+		Usage:     "Export blockchain history to era archives",
+		ArgsUsage: "<dir> <first> <last>",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+			utils.NetworkFlag,
+			utils.AlfajoresFlag,
+			utils.BaklavaFlag,
+			utils.CacheFlag,
+			utils.SyncModeFlag,
+		},
+		Category: "BLOCKCHAIN COMMANDS",
+		Description: `
+The export-history command writes the blocks in the range [first, last] with
+their receipts and total difficulties into era archives of 8192 blocks each.
+Every archive is named after an accumulator committing to its contents, and a
+checksums.txt listing is written next to them, so the directory can be served
+as-is over HTTP, CDNs or torrents and verified by the importing side.`,
 	}
 	importPreimagesCommand = cli.Command{
 		Action:    utils.MigrateFlags(importPreimages),
@@ -132,6 +177,7 @@ be gzipped.`,
 		ArgsUsage: "<datafile>",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.NetworkFlag,
 			utils.AlfajoresFlag,
 			utils.BaklavaFlag,
 			utils.CacheFlag,
@@ -148,6 +194,7 @@ be gzipped.`,
 		ArgsUsage: "<dumpfile>",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.NetworkFlag,
 			utils.AlfajoresFlag,
 			utils.BaklavaFlag,
 			utils.CacheFlag,
@@ -164,6 +211,7 @@ The export-preimages command export hash preimages to an RLP encoded stream`,
 		ArgsUsage: "<sourceChainDataDir> <sourceAncientChainDataDir>",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.NetworkFlag,
 			utils.AlfajoresFlag,
 			utils.BaklavaFlag,
 			utils.CacheFlag,
@@ -182,6 +230,7 @@ the second argument must be the directory containing the ancient blockchain to d
 		ArgsUsage: " ",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.NetworkFlag,
 			utils.AlfajoresFlag,
 			utils.BaklavaFlag,
 		},
@@ -196,6 +245,7 @@ Remove blockchain and state databases`,
 		ArgsUsage: "[<blockHash> | <blockNum>]...",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.NetworkFlag,
 			utils.AlfajoresFlag,
 			utils.BaklavaFlag,
 			utils.CacheFlag,
@@ -219,6 +269,7 @@ Use "geth dump 0" to dump the genesis block.`,
 			utils.DataDirFlag,
 			utils.AncientFlag,
 			utils.CacheFlag,
+			utils.NetworkFlag,
 			utils.AlfajoresFlag,
 			utils.BaklavaFlag,
 			utils.SyncModeFlag,
@@ -401,6 +452,62 @@ func exportChain(ctx *cli.Context) error {
 	return nil
 }
 
+// historyNetwork returns the network name era archives are labelled with.
+func historyNetwork(ctx *cli.Context) string {
+	if network := utils.GetNetwork(ctx); network != nil {
+		return network.Name
+	}
+	return "mainnet"
+}
+
+// importHistory imports the era archives found in the given directory.
+func importHistory(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		utils.Fatalf("This command requires an argument.")
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chain, db := utils.MakeChain(ctx, stack, false)
+	defer db.Close()
+	defer chain.Stop()
+
+	start := time.Now()
+	if err := utils.ImportHistory(chain, ctx.Args().First(), historyNetwork(ctx)); err != nil {
+		utils.Fatalf("Import error: %v\n", err)
+	}
+	fmt.Printf("Import done in %v\n", time.Since(start))
+	return nil
+}
+
+// exportHistory exports a range of blocks into era archives.
+func exportHistory(ctx *cli.Context) error {
+	if len(ctx.Args()) != 3 {
+		utils.Fatalf("This command requires three arguments.")
+	}
+	first, ferr := strconv.ParseUint(ctx.Args().Get(1), 10, 64)
+	last, lerr := strconv.ParseUint(ctx.Args().Get(2), 10, 64)
+	if ferr != nil || lerr != nil {
+		utils.Fatalf("Export error in parsing parameters: block number not an integer\n")
+	}
+	if first > last {
+		utils.Fatalf("Export error: first block %d is after last block %d\n", first, last)
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chain, db := utils.MakeChain(ctx, stack, true)
+	defer db.Close()
+	defer chain.Stop()
+
+	start := time.Now()
+	if err := utils.ExportHistory(chain, ctx.Args().First(), historyNetwork(ctx), first, last, era.MaxEra1Size); err != nil {
+		utils.Fatalf("Export error: %v\n", err)
+	}
+	fmt.Printf("Export done in %v\n", time.Since(start))
+	return nil
+}
+
 // importPreimages imports preimage data from the specified file.
 func importPreimages(ctx *cli.Context) error {
 	if len(ctx.Args()) < 1 {
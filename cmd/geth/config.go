@@ -22,15 +22,22 @@ import (
 	"fmt"
 	"math/big"
 	"os"
+	"os/signal"
 	"reflect"
+	"syscall"
 	"unicode"
 
+	"github.com/celo-org/celo-blockchain/balancewatch"
 	"github.com/celo-org/celo-blockchain/cmd/utils"
 	"github.com/celo-org/celo-blockchain/eth"
+	"github.com/celo-org/celo-blockchain/eth/downloader"
+	"github.com/celo-org/celo-blockchain/exporter"
+	"github.com/celo-org/celo-blockchain/indexer"
 	"github.com/celo-org/celo-blockchain/internal/ethapi"
 	"github.com/celo-org/celo-blockchain/log"
 	"github.com/celo-org/celo-blockchain/node"
 	"github.com/celo-org/celo-blockchain/params"
+	"github.com/celo-org/celo-blockchain/slasher"
 	whisper "github.com/celo-org/celo-blockchain/whisper/whisperv6"
 	"github.com/naoina/toml"
 	cli "gopkg.in/urfave/cli.v1"
@@ -79,6 +86,11 @@ type gethConfig struct {
 	Shh      whisper.Config
 	Node     node.Config
 	Ethstats ethstatsConfig
+	Slasher  slasher.Config
+	Exporter exporter.Config
+	Indexer  indexer.Config
+
+	BalanceWatch balancewatch.Config
 }
 
 func loadConfig(file string, cfg *gethConfig) error {
@@ -96,6 +108,26 @@ func loadConfig(file string, cfg *gethConfig) error {
 	return err
 }
 
+// reloadOnHangup applies the HTTP RPC policy of the config file to the running
+// node whenever the process receives SIGHUP. As on startup, the command line
+// flags take precedence over the file.
+func reloadOnHangup(ctx *cli.Context, stack *node.Node, file string) {
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGHUP)
+	defer signal.Stop(sigc)
+
+	for range sigc {
+		log.Info("Got hangup, reloading config", "file", file)
+		cfg := gethConfig{Node: defaultNodeConfig()}
+		if err := loadConfig(file, &cfg); err != nil {
+			log.Error("Failed to reload config", "err", err)
+			continue
+		}
+		utils.SetHTTPPolicy(ctx, &cfg.Node)
+		stack.SetHTTPPolicy(cfg.Node.HTTPCors, cfg.Node.HTTPVirtualHosts)
+	}
+}
+
 func defaultNodeConfig() node.Config {
 	cfg := node.DefaultConfig
 	cfg.Name = clientIdentifier
@@ -110,9 +142,10 @@ func defaultNodeConfig() node.Config {
 func makeConfigNode(ctx *cli.Context) (*node.Node, gethConfig) {
 	// Load defaults.
 	cfg := gethConfig{
-		Eth:  eth.DefaultConfig,
-		Shh:  whisper.DefaultConfig,
-		Node: defaultNodeConfig(),
+		Eth:      eth.DefaultConfig,
+		Shh:      whisper.DefaultConfig,
+		Node:     defaultNodeConfig(),
+		Exporter: exporter.DefaultConfig,
 	}
 
 	// Load config file.
@@ -137,6 +170,10 @@ func makeConfigNode(ctx *cli.Context) (*node.Node, gethConfig) {
 		log.Warn("The flag --ethstats is deprecated and will be removed in the future, please use --celostats")
 	}
 	utils.SetShhConfig(ctx, stack, &cfg.Shh)
+	utils.SetSlasherConfig(ctx, &cfg.Slasher)
+	utils.SetExporterConfig(ctx, &cfg.Exporter)
+	utils.SetIndexerConfig(ctx, &cfg.Indexer)
+	utils.SetBalanceWatchConfig(ctx, &cfg.BalanceWatch)
 
 	return stack, cfg
 }
@@ -157,6 +194,7 @@ func makeFullNode(ctx *cli.Context) (*node.Node, ethapi.Backend) {
 	if ctx.GlobalIsSet(utils.OverrideEHardforkFlag.Name) {
 		cfg.Eth.OverrideEHardfork = new(big.Int).SetUint64(ctx.GlobalUint64(utils.OverrideEHardforkFlag.Name))
 	}
+	checkValidatorConfig(&cfg.Eth)
 	backend := utils.RegisterEthService(stack, &cfg.Eth)
 
 	// Whisper must be explicitly enabled by specifying at least 1 whisper flag or in dev mode
@@ -182,9 +220,51 @@ func makeFullNode(ctx *cli.Context) (*node.Node, ethapi.Backend) {
 	if cfg.Ethstats.URL != "" {
 		utils.RegisterEthStatsService(stack, backend, cfg.Ethstats.URL)
 	}
+	// Watch governance for approved hard forks and the operating account
+	// balances on nodes holding the state.
+	if cfg.Eth.SyncMode == downloader.FullSync || cfg.Eth.SyncMode == downloader.FastSync {
+		utils.RegisterUpgradesService(stack, backend)
+		if len(cfg.BalanceWatch.Accounts) > 0 {
+			utils.RegisterBalanceWatchService(stack, backend, &cfg.BalanceWatch)
+		}
+	}
+	// Add the slasher if requested.
+	if cfg.Slasher.Enabled {
+		utils.RegisterSlasherService(stack, backend, &cfg.Slasher)
+	}
+	// Export the chain events if a sink is configured.
+	if cfg.Exporter.URL != "" {
+		utils.RegisterExporterService(stack, backend, &cfg.Exporter)
+	}
+	// Index the chain into a database if one is configured.
+	if cfg.Indexer.URL != "" {
+		utils.RegisterIndexerService(stack, backend, &cfg.Indexer)
+	}
 	return stack, backend
 }
 
+// checkValidatorConfig ensures the validator, replica and proxy settings are
+// consistent. They may come from both flags and the config file, so only the
+// final configuration can be checked.
+func checkValidatorConfig(cfg *eth.Config) {
+	if cfg.Istanbul.Validator || cfg.Istanbul.Proxy {
+		// Miners and proxies only makes sense if a full node is running
+		if cfg.SyncMode != downloader.FullSync && cfg.SyncMode != downloader.FastSync {
+			utils.Fatalf("Miners and Proxies must be run as a full node")
+		}
+	}
+	if cfg.Istanbul.Validator && cfg.Istanbul.Proxy {
+		utils.Fatalf("Proxies can't mine")
+	}
+	// Replicas and proxied validators only make sense if we are mining
+	if cfg.Istanbul.Replica && !cfg.Istanbul.Validator {
+		utils.Fatalf("Must run a replica with mining enabled or in dev mode.")
+	}
+	if cfg.Istanbul.Proxied && !cfg.Istanbul.Validator {
+		utils.Fatalf("Must run a proxied validator with mining enabled.")
+	}
+}
+
 // dumpConfig is the dumpconfig command.
 func dumpConfig(ctx *cli.Context) error {
 	_, cfg := makeConfigNode(ctx)
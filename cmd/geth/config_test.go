@@ -0,0 +1,74 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testConfig = `
+[Eth]
+SyncMode = "full"
+
+[Eth.TxPool]
+PriceLimit = 7
+
+[Eth.Istanbul]
+Validator = true
+
+[Node]
+HTTPPort = 9000
+`
+
+// Tests that dumpconfig emits the effective configuration, combining the
+// config file with the flags taking precedence.
+func TestDumpConfig(t *testing.T) {
+	datadir := tmpdir(t)
+	defer os.RemoveAll(datadir)
+
+	file := filepath.Join(datadir, "config.toml")
+	if err := ioutil.WriteFile(file, []byte(testConfig), 0600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	geth := runGeth(t, "--datadir", datadir, "--config", file, "--http.port", "9001", "dumpconfig")
+	geth.ExpectRegexp(`(?s)\[Eth\.TxPool\].*?PriceLimit = 7\n.*?\[Eth\.Istanbul\].*?Validator = true\n.*?\[Node\].*?HTTPPort = 9001\n`)
+	geth.WaitExit()
+	if status := geth.ExitStatus(); status != 0 {
+		t.Fatalf("dumpconfig failed with status %d", status)
+	}
+}
+
+// Tests that the EVM timeout of eth_call and gas estimation defaults to 50s
+// and is overridden by --rpc.evmtimeout.
+func TestDumpConfigEVMTimeout(t *testing.T) {
+	datadir := tmpdir(t)
+	defer os.RemoveAll(datadir)
+
+	geth := runGeth(t, "--datadir", datadir, "dumpconfig")
+	geth.ExpectRegexp(`(?s)\[Eth\].*?RPCEVMTimeout = 50000000000\n`)
+	geth.WaitExit()
+
+	geth = runGeth(t, "--datadir", datadir, "--rpc.evmtimeout", "3s", "dumpconfig")
+	geth.ExpectRegexp(`(?s)\[Eth\].*?RPCEVMTimeout = 3000000000\n`)
+	geth.WaitExit()
+	if status := geth.ExitStatus(); status != 0 {
+		t.Fatalf("dumpconfig failed with status %d", status)
+	}
+}
@@ -118,18 +118,7 @@ func remoteConsole(ctx *cli.Context) error {
 	// Attach to a remotely running geth instance and start the JavaScript console
 	endpoint := ctx.Args().First()
 	if endpoint == "" {
-		path := node.DefaultDataDir()
-		if ctx.GlobalIsSet(utils.DataDirFlag.Name) {
-			path = ctx.GlobalString(utils.DataDirFlag.Name)
-		}
-		if path != "" {
-			if ctx.GlobalBool(utils.BaklavaFlag.Name) {
-				path = filepath.Join(path, "baklava")
-			} else if ctx.GlobalBool(utils.AlfajoresFlag.Name) {
-				path = filepath.Join(path, "alfajores")
-			}
-		}
-		endpoint = fmt.Sprintf("%s/geth.ipc", path)
+		endpoint = localIPCEndpoint(ctx)
 	}
 	client, err := dialRPC(endpoint)
 	if err != nil {
@@ -160,6 +149,19 @@ func remoteConsole(ctx *cli.Context) error {
 	return nil
 }
 
+// localIPCEndpoint returns the IPC endpoint of a geth instance running on the
+// data directory configured by the CLI flags.
+func localIPCEndpoint(ctx *cli.Context) string {
+	path := node.DefaultDataDir()
+	if ctx.GlobalIsSet(utils.DataDirFlag.Name) {
+		path = ctx.GlobalString(utils.DataDirFlag.Name)
+	}
+	if network := utils.GetNetwork(ctx); path != "" && network != nil {
+		path = filepath.Join(path, network.DataDir)
+	}
+	return fmt.Sprintf("%s/geth.ipc", path)
+}
+
 // dialRPC returns a RPC client which connects to the given endpoint.
 // The check for empty endpoint implements the defaulting logic
 // for "geth attach" and "geth monitor" with no argument.
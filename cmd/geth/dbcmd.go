@@ -0,0 +1,485 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/celo-org/celo-blockchain/cmd/utils"
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/core/rawdb"
+	"github.com/celo-org/celo-blockchain/core/state"
+	"github.com/celo-org/celo-blockchain/ethdb"
+	"github.com/celo-org/celo-blockchain/log"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	dbMigrateTargetFlag = cli.StringFlag{
+		Name:  "to",
+		Usage: "Database engine to migrate the chain database to ('leveldb' or 'pebble')",
+	}
+
+	dbCompactDutyFlag = cli.IntFlag{
+		Name:  "duty",
+		Usage: "Percentage of time spent compacting, idling the rest to limit disk IO pressure (1-100)",
+		Value: 100,
+	}
+	dbBackupEndpointFlag = cli.StringFlag{
+		Name:  "endpoint",
+		Usage: "RPC endpoint of the running node to back up (default = IPC endpoint inside the datadir)",
+	}
+	dbStorageBlockFlag = cli.Uint64Flag{
+		Name:  "block",
+		Usage: "Block number of the state to report on (default = current head)",
+	}
+	dbStorageTopFlag = cli.IntFlag{
+		Name:  "top",
+		Usage: "Number of contracts listed in each ranking",
+		Value: 20,
+	}
+	dbStoragePreviousFlag = cli.StringFlag{
+		Name:  "previous",
+		Usage: "Previous JSON report to compute the deltas against",
+	}
+	dbStorageOutputFlag = cli.StringFlag{
+		Name:  "output",
+		Usage: "File to write the JSON report to, for later comparison",
+	}
+
+	dbCommand = cli.Command{
+		Name:      "db",
+		Usage:     "Low level database operations",
+		ArgsUsage: "",
+		Category:  "DATABASE COMMANDS",
+		Subcommands: []cli.Command{
+			dbInspectCmd,
+			dbMigrateCmd,
+			dbCompactCmd,
+			dbBackupCmd,
+			dbRestoreCmd,
+			dbStorageReportCmd,
+		},
+	}
+	dbInspectCmd = cli.Command{
+		Action:    utils.MigrateFlags(inspect),
+		Name:      "inspect",
+		Usage:     "Inspect the storage size and item count for each type of data in the database",
+		ArgsUsage: " ",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+			utils.AncientFlag,
+			utils.CacheFlag,
+			utils.NetworkFlag,
+			utils.AlfajoresFlag,
+			utils.BaklavaFlag,
+			utils.SyncModeFlag,
+		},
+		Description: `
+    geth db inspect
+
+iterates over the whole chain database and reports the size and number of
+items of every data category (headers, bodies, receipts, trie nodes, istanbul
+snapshots, light client data, ancient chain segments), e.g. to plan disk
+capacity or to verify that pruning took effect.`,
+	}
+	dbMigrateCmd = cli.Command{
+		Action:    utils.MigrateFlags(dbMigrate),
+		Name:      "migrate",
+		Usage:     "Migrate the chain database to a different key-value engine",
+		ArgsUsage: " ",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+			utils.NetworkFlag,
+			utils.AlfajoresFlag,
+			utils.BaklavaFlag,
+			utils.CacheFlag,
+			utils.SyncModeFlag,
+			dbMigrateTargetFlag,
+		},
+		Description: `
+    geth db migrate --to pebble
+
+copies every key of the chain database into a fresh database backed by the
+requested engine and swaps it in place of the original one. Ancient chain
+segments stored inside the chain database folder are moved over as-is. The
+original database is kept as a backup next to the migrated one and may be
+removed once the node is confirmed to run correctly. The node must be stopped
+while migrating.`,
+	}
+	dbCompactCmd = cli.Command{
+		Action:    utils.MigrateFlags(dbCompact),
+		Name:      "compact",
+		Usage:     "Compact the chain database",
+		ArgsUsage: " ",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+			utils.AncientFlag,
+			utils.NetworkFlag,
+			utils.AlfajoresFlag,
+			utils.BaklavaFlag,
+			utils.CacheFlag,
+			utils.SyncModeFlag,
+			dbCompactDutyFlag,
+		},
+		Description: `
+    geth db compact --duty 50
+
+flattens the entire key-value database, discarding deleted and overwritten
+entries, one key range at a time while reporting progress. The duty flag
+limits the share of time spent compacting, pausing between ranges so the disk
+remains responsive for other workloads. The node must be stopped while
+compacting.`,
+	}
+	dbBackupCmd = cli.Command{
+		Action:    utils.MigrateFlags(dbBackup),
+		Name:      "backup",
+		Usage:     "Back up the chain database of a running node",
+		ArgsUsage: "<file>",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+			utils.NetworkFlag,
+			utils.AlfajoresFlag,
+			utils.BaklavaFlag,
+			dbBackupEndpointFlag,
+		},
+		Description: `
+    geth db backup /backups/chaindata.bak
+
+instructs the running node to write a consistent snapshot of its chain
+database, including the ancient chain segments, into the given archive file.
+The node keeps running and importing blocks during the backup. The file is
+written by the node process, so the path must be accessible from it.`,
+	}
+	dbRestoreCmd = cli.Command{
+		Action:    utils.MigrateFlags(dbRestore),
+		Name:      "restore",
+		Usage:     "Restore the chain database from a backup",
+		ArgsUsage: "<file>",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+			utils.AncientFlag,
+			utils.NetworkFlag,
+			utils.AlfajoresFlag,
+			utils.BaklavaFlag,
+			utils.CacheFlag,
+			utils.SyncModeFlag,
+		},
+		Description: `
+    geth db restore /backups/chaindata.bak
+
+restores a backup created by 'geth db backup' into an empty chain database.
+The node must be stopped while restoring.`,
+	}
+	dbStorageReportCmd = cli.Command{
+		Action:    utils.MigrateFlags(dbStorageReport),
+		Name:      "storage-report",
+		Usage:     "Report the contracts using the most storage slots and code",
+		ArgsUsage: " ",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+			utils.AncientFlag,
+			utils.NetworkFlag,
+			utils.AlfajoresFlag,
+			utils.BaklavaFlag,
+			utils.CacheFlag,
+			utils.SyncModeFlag,
+			dbStorageBlockFlag,
+			dbStorageTopFlag,
+			dbStoragePreviousFlag,
+			dbStorageOutputFlag,
+		},
+		Description: `
+    geth db storage-report --block 8000000 --previous report.json --output new.json
+
+walks the whole state at the given block and lists the contracts holding the
+most storage slots and the largest code, e.g. for core contract developers to
+track the state growth they are responsible for. Given a previous report, the
+changes since then are listed too. The state of the block must be available,
+which for older blocks requires an archive node. The node must be stopped
+while reporting.`,
+	}
+)
+
+// chainDataName returns the name of the chain database folder used by the
+// configured sync mode.
+func chainDataName(ctx *cli.Context) string {
+	switch ctx.GlobalString(utils.SyncModeFlag.Name) {
+	case "light":
+		return "lightchaindata"
+	case "lightest":
+		return "lightestchaindata"
+	default:
+		return "chaindata"
+	}
+}
+
+// dbMigrate copies the chain database into a database backed by a different
+// key-value engine and swaps the two.
+func dbMigrate(ctx *cli.Context) error {
+	target := ctx.String(dbMigrateTargetFlag.Name)
+	if target != rawdb.DBLeveldb && target != rawdb.DBPebble {
+		utils.Fatalf("Invalid migration target '%s', allowed 'leveldb' or 'pebble'", target)
+	}
+	// Creating the node acquires the datadir lock, ensuring no instance is running
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	path := stack.ResolvePath(chainDataName(ctx))
+	source := rawdb.PreexistingDatabase(path)
+	switch {
+	case source == "":
+		utils.Fatalf("No database found at %s", path)
+	case source == target:
+		log.Info("Database already uses the requested engine", "path", path, "engine", target)
+		return nil
+	}
+	migrated := path + ".migrating"
+	if common.FileExist(migrated) {
+		utils.Fatalf("Leftover migration folder found at %s, remove it and retry", migrated)
+	}
+	cache := ctx.GlobalInt(utils.CacheFlag.Name) * ctx.GlobalInt(utils.CacheDatabaseFlag.Name) / 100
+
+	src, err := rawdb.NewKeyValueStore(source, path, cache/2, utils.MakeDatabaseHandles()/2, "")
+	if err != nil {
+		utils.Fatalf("Failed to open source database: %v", err)
+	}
+	dst, err := rawdb.NewKeyValueStore(target, migrated, cache/2, utils.MakeDatabaseHandles()/2, "")
+	if err != nil {
+		src.Close()
+		utils.Fatalf("Failed to create target database: %v", err)
+	}
+	log.Info("Migrating database", "path", path, "from", source, "to", target)
+	err = copyKeyValueStore(src, dst)
+	src.Close()
+	dst.Close()
+	if err != nil {
+		os.RemoveAll(migrated)
+		utils.Fatalf("Database migration failed: %v", err)
+	}
+	// Move any ancient store kept inside the database folder into the new one
+	if ancient := filepath.Join(path, "ancient"); common.FileExist(ancient) {
+		if err := os.Rename(ancient, filepath.Join(migrated, "ancient")); err != nil {
+			utils.Fatalf("Failed to move ancient store: %v", err)
+		}
+	}
+	backup := fmt.Sprintf("%s.%s.bak", path, source)
+	if err := os.Rename(path, backup); err != nil {
+		utils.Fatalf("Failed to back up original database: %v", err)
+	}
+	if err := os.Rename(migrated, path); err != nil {
+		utils.Fatalf("Failed to install migrated database: %v", err)
+	}
+	log.Info("Database migrated", "path", path, "engine", target, "backup", backup)
+	return nil
+}
+
+// copyKeyValueStore copies every entry of src into dst, reporting progress
+// periodically.
+func copyKeyValueStore(src ethdb.KeyValueStore, dst ethdb.KeyValueStore) error {
+	var (
+		it     = src.NewIterator(nil, nil)
+		batch  = dst.NewBatch()
+		start  = time.Now()
+		logged = time.Now()
+		count  uint64
+		size   common.StorageSize
+	)
+	defer it.Release()
+
+	for it.Next() {
+		if err := batch.Put(it.Key(), it.Value()); err != nil {
+			return err
+		}
+		count++
+		size += common.StorageSize(len(it.Key()) + len(it.Value()))
+
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Migrating database", "keys", count, "size", size, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	log.Info("Copied database entries", "keys", count, "size", size, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// dbCompact compacts the chain database range by range, throttled to the
+// requested duty cycle.
+func dbCompact(ctx *cli.Context) error {
+	duty := ctx.Int(dbCompactDutyFlag.Name)
+	if duty < 1 || duty > 100 {
+		utils.Fatalf("Invalid duty cycle %d, must be between 1 and 100", duty)
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack)
+	defer db.Close()
+
+	var (
+		start = time.Now()
+		busy  time.Duration
+	)
+	log.Info("Compacting chain database", "duty", fmt.Sprintf("%d%%", duty))
+	for b := 0; b < 256; b++ {
+		var (
+			from  = []byte{byte(b)}
+			to    []byte
+			began = time.Now()
+		)
+		if b < 255 {
+			to = []byte{byte(b + 1)}
+		}
+		if err := db.Compact(from, to); err != nil {
+			utils.Fatalf("Database compaction failed: %v", err)
+		}
+		took := time.Since(began)
+		busy += took
+
+		if done := b + 1; done%16 == 0 {
+			elapsed := time.Since(start)
+			eta := time.Duration(float64(elapsed) / float64(done) * float64(256-done))
+			log.Info("Compacting chain database", "range", fmt.Sprintf("0x%0.2X", b), "progress", fmt.Sprintf("%.2f%%", float64(done)*100/256),
+				"elapsed", common.PrettyDuration(elapsed), "eta", common.PrettyDuration(eta))
+		}
+		// Idle long enough to keep the compaction within the requested duty cycle
+		if duty < 100 && b < 255 {
+			time.Sleep(took * time.Duration(100-duty) / time.Duration(duty))
+		}
+	}
+	log.Info("Database compaction finished", "elapsed", common.PrettyDuration(time.Since(start)), "busy", common.PrettyDuration(busy))
+	return nil
+}
+
+// dbBackup requests a running node to write a backup of its chain database.
+func dbBackup(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		utils.Fatalf("This command requires an argument.")
+	}
+	file, err := filepath.Abs(ctx.Args().First())
+	if err != nil {
+		utils.Fatalf("Invalid backup path: %v", err)
+	}
+	endpoint := ctx.String(dbBackupEndpointFlag.Name)
+	if endpoint == "" {
+		endpoint = localIPCEndpoint(ctx)
+	}
+	client, err := dialRPC(endpoint)
+	if err != nil {
+		utils.Fatalf("Unable to attach to running geth: %v", err)
+	}
+	defer client.Close()
+
+	log.Info("Backing up chain database", "endpoint", endpoint, "file", file)
+	start := time.Now()
+
+	var stats rawdb.BackupStats
+	if err := client.Call(&stats, "debug_chaindbBackup", file); err != nil {
+		utils.Fatalf("Backup failed: %v", err)
+	}
+	log.Info("Backup complete", "file", file, "ancients", stats.Ancients, "entries", stats.Entries, "size", stats.Size, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// dbRestore restores a chain database backup into the stopped node.
+func dbRestore(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		utils.Fatalf("This command requires an argument.")
+	}
+	in, err := os.Open(ctx.Args().First())
+	if err != nil {
+		utils.Fatalf("Failed to open backup: %v", err)
+	}
+	defer in.Close()
+
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack)
+	defer db.Close()
+
+	if _, err := rawdb.ImportBackup(in, db); err != nil {
+		utils.Fatalf("Restore failed: %v", err)
+	}
+	return nil
+}
+
+// dbStorageReport walks the state at the requested block and reports the
+// contracts using the most storage.
+func dbStorageReport(ctx *cli.Context) error {
+	top := ctx.Int(dbStorageTopFlag.Name)
+	if top < 1 {
+		utils.Fatalf("Invalid contract count %d, must be positive", top)
+	}
+	var previous *storageReport
+	if file := ctx.String(dbStoragePreviousFlag.Name); file != "" {
+		var err error
+		if previous, err = readStorageReport(file); err != nil {
+			utils.Fatalf("Failed to read previous report: %v", err)
+		}
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chain, chainDb := utils.MakeChain(ctx, stack, true)
+	defer chainDb.Close()
+
+	block := chain.CurrentBlock()
+	if ctx.IsSet(dbStorageBlockFlag.Name) {
+		number := ctx.Uint64(dbStorageBlockFlag.Name)
+		if block = chain.GetBlockByNumber(number); block == nil {
+			utils.Fatalf("Block %d not found", number)
+		}
+	}
+	log.Info("Reporting storage usage", "number", block.NumberU64(), "hash", block.Hash(), "root", block.Root())
+	report, err := newStorageReport(state.NewDatabase(chainDb), block.Root(), top)
+	if err != nil {
+		utils.Fatalf("Failed to report storage usage: %v", err)
+	}
+	report.Block, report.Hash = block.NumberU64(), block.Hash()
+	if previous != nil {
+		report.compare(previous)
+	}
+	if file := ctx.String(dbStorageOutputFlag.Name); file != "" {
+		blob, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			utils.Fatalf("Failed to encode report: %v", err)
+		}
+		if err := ioutil.WriteFile(file, blob, 0644); err != nil {
+			utils.Fatalf("Failed to write report: %v", err)
+		}
+	}
+	report.print(os.Stdout)
+	return nil
+}
@@ -0,0 +1,120 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/celo-org/celo-blockchain/cmd/utils"
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/log"
+	"github.com/celo-org/celo-blockchain/mycelo/genesis"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	genesisBuildPathFlag = cli.StringFlag{
+		Name:  "buildpath",
+		Usage: "Directory of the core contracts truffle build artifacts (default: $CELO_MONOREPO/packages/protocol/build/contracts)",
+	}
+	genesisOutputFlag = cli.StringFlag{
+		Name:  "out",
+		Usage: "File to write the genesis to (default: stdout)",
+	}
+
+	genesisCommand = cli.Command{
+		Name:     "genesis",
+		Usage:    "Genesis generation commands",
+		Category: "BLOCKCHAIN COMMANDS",
+		Subcommands: []cli.Command{
+			{
+				Action:    utils.MigrateFlags(generateGenesis),
+				Name:      "generate",
+				Usage:     "Generate a complete Celo genesis from a YAML spec",
+				ArgsUsage: "<spec.yaml>",
+				Flags: []cli.Flag{
+					genesisBuildPathFlag,
+					genesisOutputFlag,
+				},
+				Description: `
+The generate command creates the genesis block of a private Celo network, with
+the core contracts deployed and initialized, the registry populated and the
+initial validators elected. The network is described by a YAML spec:
+
+  chainId: 1101
+  accounts:
+    mnemonic: "miss fire behind decide egg buyer honey seven advance uniform profit renew"
+    validators: 4
+    validatorsPerGroup: 2
+    developerAccounts: 10
+  istanbul:
+    epoch: 720
+    blockperiod: 5
+  config:
+    blockchain:
+      blockGasLimit: 20000000
+    election:
+      maxElectableValidators: 50
+
+The config section overrides the default contract parameters, using the layout
+of mycelo's genesis-config.json. Token amounts should be given as quoted strings.`,
+			},
+		},
+	}
+)
+
+// generateGenesis creates a genesis from a YAML spec and writes it as JSON.
+func generateGenesis(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		utils.Fatalf("This command requires the spec file as its only argument.")
+	}
+	spec, err := genesis.LoadSpec(ctx.Args().First())
+	if err != nil {
+		utils.Fatalf("Failed to load genesis spec: %v", err)
+	}
+	buildpath := ctx.String(genesisBuildPathFlag.Name)
+	if buildpath == "" {
+		if monorepo := os.Getenv("CELO_MONOREPO"); monorepo != "" {
+			buildpath = filepath.Join(monorepo, "packages/protocol/build/contracts")
+		} else {
+			utils.Fatalf("Missing --%s flag", genesisBuildPathFlag.Name)
+		}
+	}
+	if !common.FileExist(filepath.Join(buildpath, "Registry.json")) {
+		utils.Fatalf("No core contract build artifacts found in %s", buildpath)
+	}
+	gen, err := spec.Generate(buildpath)
+	if err != nil {
+		utils.Fatalf("Failed to generate genesis: %v", err)
+	}
+	out := os.Stdout
+	if path := ctx.String(genesisOutputFlag.Name); path != "" {
+		if out, err = os.Create(path); err != nil {
+			utils.Fatalf("Failed to create output file: %v", err)
+		}
+		defer out.Close()
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(gen); err != nil {
+		utils.Fatalf("Failed to write genesis: %v", err)
+	}
+	log.Info("Generated genesis", "chainId", spec.ChainID, "validators", spec.Accounts.NumValidators, "hash", gen.ToBlock(nil).Hash())
+	return nil
+}
@@ -44,6 +44,7 @@ import (
 	"github.com/celo-org/celo-blockchain/log"
 	"github.com/celo-org/celo-blockchain/metrics"
 	"github.com/celo-org/celo-blockchain/node"
+	"github.com/celo-org/celo-blockchain/params"
 	"github.com/celo-org/celo-blockchain/rpc"
 	gopsutil "github.com/shirou/gopsutil/mem"
 	cli "gopkg.in/urfave/cli.v1"
@@ -69,26 +70,37 @@ var (
 		utils.LegacyBootnodesV5Flag,
 		utils.DataDirFlag,
 		utils.AncientFlag,
+		utils.AncientRemoteFlag,
+		utils.AncientRemoteCacheFlag,
+		utils.DBEngineFlag,
 		utils.KeyStoreDirFlag,
 		utils.ExternalSignerFlag,
 		utils.NoUSBFlag,
 		utils.OverrideEHardforkFlag,
+		utils.ForkDryRunFlag,
+		utils.ForkDryRunBlocksFlag,
 		utils.TxPoolLocalsFlag,
 		utils.TxPoolNoLocalsFlag,
 		utils.TxPoolJournalFlag,
 		utils.TxPoolRejournalFlag,
+		utils.TxPoolRemoteJournalFlag,
 		utils.TxPoolPriceLimitFlag,
 		utils.TxPoolPriceBumpFlag,
 		utils.TxPoolAccountSlotsFlag,
 		utils.TxPoolGlobalSlotsFlag,
 		utils.TxPoolAccountQueueFlag,
 		utils.TxPoolGlobalQueueFlag,
+		utils.TxPoolFeePayerSlotsFlag,
 		utils.TxPoolLifetimeFlag,
 		utils.SyncModeFlag,
 		utils.ExitWhenSyncedFlag,
+		utils.ShutdownTimeoutFlag,
 		utils.GCModeFlag,
 		utils.SnapshotFlag,
 		utils.TxLookupLimitFlag,
+		utils.HistoryBlocksFlag,
+		utils.HistoryArchiveFlag,
+		utils.HistoryProxyFlag,
 		utils.LightServeFlag,
 		utils.LightIngressFlag,
 		utils.LightEgressFlag,
@@ -111,6 +123,10 @@ var (
 		utils.CacheGCFlag,
 		utils.CacheSnapshotFlag,
 		utils.CacheNoPrefetchFlag,
+		utils.CachePreheatFlag,
+		utils.CacheTraceFlag,
+		utils.CacheTraceDiskFlag,
+		utils.CacheTraceJournalFlag,
 		utils.ListenPortFlag,
 		utils.MaxPeersFlag,
 		utils.MaxPendingPeersFlag,
@@ -125,15 +141,32 @@ var (
 		utils.NetrestrictFlag,
 		utils.NodeKeyFileFlag,
 		utils.NodeKeyHexFlag,
+		utils.PersistPeersFlag,
 		utils.DNSDiscoveryFlag,
 		utils.DeveloperFlag,
 		utils.DeveloperPeriodFlag,
+		utils.DeveloperCeloFlag,
+		utils.DeveloperBuildPathFlag,
+		utils.DeveloperAccountsFlag,
+		utils.DeveloperMnemonicFlag,
+		utils.NetworkFlag,
 		utils.BaklavaFlag,
 		utils.AlfajoresFlag,
 		utils.VMEnableDebugFlag,
+		utils.VMTraceSamplingFlag,
 		utils.NetworkIdFlag,
 		utils.CeloStatsURLFlag,
 		utils.LegacyEthStatsURLFlag,
+		utils.SlasherFlag,
+		utils.SlasherSubmitFlag,
+		utils.SlasherAccountFlag,
+		utils.SlasherMinProfitFlag,
+		utils.ExporterURLFlag,
+		utils.ExporterPrefixFlag,
+		utils.IndexerURLFlag,
+		utils.IndexerStartFlag,
+		utils.BalanceWatchAccountsFlag,
+		utils.BalanceWatchThresholdsFlag,
 		utils.NoCompactionFlag,
 		utils.EWASMInterpreterFlag,
 		utils.EVMInterpreterFlag,
@@ -143,6 +176,13 @@ var (
 		utils.LegacyIstanbulProposerPolicyFlag,
 		utils.LegacyIstanbulLookbackWindowFlag,
 		utils.IstanbulReplicaFlag,
+		utils.IstanbulOptimisticAnnounceFlag,
+		utils.IstanbulChunkThresholdFlag,
+		utils.IstanbulValidatorLinkRelayFlag,
+		utils.IstanbulObserveFlag,
+		utils.IstanbulServeObserversFlag,
+		utils.IstanbulAnnouncePullThresholdFlag,
+		utils.IstanbulDedupCacheFlag,
 		utils.AnnounceQueryEnodeGossipPeriodFlag,
 		utils.AnnounceAggressiveQueryEnodeGossipOnEnablementFlag,
 		utils.PingIPFromPacketFlag,
@@ -182,11 +222,21 @@ var (
 		utils.LegacyWSApiFlag,
 		utils.WSAllowedOriginsFlag,
 		utils.LegacyWSAllowedOriginsFlag,
+		utils.WSMaxSubscriptionsFlag,
+		utils.WSMaxQueuedWritesFlag,
+		utils.WSMaxMessageSizeFlag,
 		utils.IPCDisabledFlag,
 		utils.IPCPathFlag,
 		utils.InsecureUnlockAllowedFlag,
 		utils.RPCGlobalGasCap,
+		utils.RPCGlobalEVMTimeoutFlag,
 		utils.RPCGlobalTxFeeCap,
+		utils.RPCEthCompatibilityFlag,
+		utils.RPCCallCacheTTLFlag,
+		utils.RPCCallCacheSizeFlag,
+		utils.RPCSlowQueryFlag,
+		utils.RPCAuthFlag,
+		utils.RPCJWTSecretFlag,
 	}
 
 	whisperFlags = []cli.Flag{
@@ -221,16 +271,25 @@ func init() {
 		initCommand,
 		importCommand,
 		exportCommand,
+		importHistoryCommand,
+		exportHistoryCommand,
+		benchCommand,
 		importPreimagesCommand,
 		exportPreimagesCommand,
 		copydbCommand,
 		removedbCommand,
 		dumpCommand,
 		dumpGenesisCommand,
+		genesisCommand,
 		inspectCommand,
+		// See dbcmd.go:
+		dbCommand,
 		// See accountcmd.go:
 		accountCommand,
+		// See validatorcmd.go:
+		validatorCommand,
 		walletCommand,
+		txCommand,
 		// See consolecmd.go:
 		consoleCommand,
 		attachCommand,
@@ -276,23 +335,24 @@ func main() {
 // This function should be called before launching devp2p stack.
 func prepare(ctx *cli.Context) {
 	// If we're running a known preset, log it for convenience.
+	network := utils.GetNetwork(ctx)
 	switch {
-	case ctx.GlobalIsSet(utils.BaklavaFlag.Name):
-		log.Info("Starting Geth on Baklava testnet...")
-
-	case ctx.GlobalIsSet(utils.AlfajoresFlag.Name):
-		log.Info("Starting Geth on Alfajores testnet...")
+	case network != nil:
+		log.Info(fmt.Sprintf("Starting Geth on Celo %s...", network.Name))
 
 	case ctx.GlobalIsSet(utils.DeveloperFlag.Name):
 		log.Info("Starting Geth in ephemeral dev mode...")
 
+	case ctx.GlobalIsSet(utils.DeveloperCeloFlag.Name):
+		log.Info("Starting Geth in ephemeral Celo dev mode...")
+
 	case !ctx.GlobalIsSet(utils.NetworkIdFlag.Name):
 		log.Info("Starting Geth on Celo mainnet...")
 	}
 	// If we're a full node on mainnet without --cache specified, bump default cache allowance
 	if ctx.GlobalString(utils.SyncModeFlag.Name) != "light" && !ctx.GlobalIsSet(utils.CacheFlag.Name) && !ctx.GlobalIsSet(utils.NetworkIdFlag.Name) {
 		// Make sure we're not on any supported preconfigured testnet either
-		if !ctx.GlobalIsSet(utils.DeveloperFlag.Name) && !ctx.GlobalIsSet(utils.AlfajoresFlag.Name) && !ctx.GlobalIsSet(utils.BaklavaFlag.Name) {
+		if !ctx.GlobalIsSet(utils.DeveloperFlag.Name) && !ctx.GlobalIsSet(utils.DeveloperCeloFlag.Name) && (network == nil || network.NetworkId == params.MainnetNetworkId) {
 			// Nope, we're really on mainnet. Bump that cache up!
 			log.Info("Bumping default cache on mainnet", "provided", ctx.GlobalInt(utils.CacheFlag.Name), "updated", 4096)
 			ctx.GlobalSet(utils.CacheFlag.Name, strconv.Itoa(4096))
@@ -356,6 +416,11 @@ func startNode(ctx *cli.Context, stack *node.Node, backend ethapi.Backend) {
 	// Start up the node itself
 	utils.StartNode(stack)
 
+	// Reload the HTTP RPC policy from the config file on SIGHUP
+	if file := ctx.GlobalString(configFileFlag.Name); file != "" {
+		go reloadOnHangup(ctx, stack, file)
+	}
+
 	// Unlock any account specifically requested
 	unlockAccounts(ctx, stack)
 
@@ -427,30 +492,9 @@ func startNode(ctx *cli.Context, stack *node.Node, backend ethapi.Backend) {
 		}()
 	}
 
-	isFullNode := ctx.GlobalString(utils.SyncModeFlag.Name) == "full" || ctx.GlobalString(utils.SyncModeFlag.Name) == "fast"
-	// Miners and proxies only makes sense if a full node is running
-	if ctx.GlobalBool(utils.ProxyFlag.Name) || ctx.GlobalBool(utils.MiningEnabledFlag.Name) || ctx.GlobalBool(utils.DeveloperFlag.Name) {
-		if !isFullNode {
-			utils.Fatalf("Miners and Proxies must be run as a full node")
-		}
-	}
-	// Replicas only makes sense if we are mining
-	if ctx.GlobalBool(utils.IstanbulReplicaFlag.Name) {
-		if !(ctx.GlobalBool(utils.MiningEnabledFlag.Name) || ctx.GlobalBool(utils.DeveloperFlag.Name)) {
-			utils.Fatalf("Must run a replica with mining enabled or in dev mode.")
-		}
-	}
-
-	// Start auxiliary services if enabled
-	if ctx.GlobalBool(utils.MiningEnabledFlag.Name) || ctx.GlobalBool(utils.DeveloperFlag.Name) {
-		if ctx.GlobalBool(utils.ProxyFlag.Name) {
-			utils.Fatalf("Proxies can't mine")
-		}
-		ethBackend, ok := backend.(*eth.EthAPIBackend)
-		if !ok {
-			utils.Fatalf("Ethereum service not running: %v", err)
-		}
-
+	// Start auxiliary services if enabled. Validating may be enabled by flags or
+	// the config file, which were already checked for consistency.
+	if ethBackend, ok := backend.(*eth.EthAPIBackend); ok && ethBackend.Config().Istanbul.Validator {
 		// Set the gas price to the limits from the CLI and start mining
 		gasprice := utils.GlobalBig(ctx, utils.LegacyMinerGasPriceFlag.Name)
 		ethBackend.TxPool().SetGasPrice(gasprice)
@@ -466,7 +510,7 @@ func startNode(ctx *cli.Context, stack *node.Node, backend ethapi.Backend) {
 			if err != nil {
 				return nil, err
 			}
-			return backend.NewEVMRunner(header, stateDB), nil
+			return backend.NewEVMRunner(context.Background(), header, stateDB), nil
 		}
 
 		blockchain_parameters.SpawnCheck(runnerFactory)
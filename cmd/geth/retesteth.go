@@ -493,6 +493,7 @@ func (api *RetestethAPI) mineBlock() error {
 
 				vmRunner := api.blockchain.NewEVMRunner(header, statedb)
 				receipt, err := core.ApplyTransaction(
+					context.Background(),
 					api.chainConfig,
 					api.blockchain,
 					&api.author,
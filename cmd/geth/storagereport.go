@@ -0,0 +1,238 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"time"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/core/state"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/crypto"
+	"github.com/celo-org/celo-blockchain/log"
+	"github.com/celo-org/celo-blockchain/rlp"
+	"github.com/celo-org/celo-blockchain/trie"
+	"github.com/olekukonko/tablewriter"
+)
+
+var emptyCodeHash = crypto.Keccak256(nil)
+
+// contractStorage is the storage used by a contract. The deltas are only set
+// if the contract is part of the previous report.
+type contractStorage struct {
+	Address  *common.Address `json:"address,omitempty"` // Nil if the preimage of the hash is unknown
+	Hash     common.Hash     `json:"hash"`
+	Slots    uint64          `json:"slots"`
+	CodeSize uint64          `json:"codeSize"`
+
+	SlotsDelta    *int64 `json:"slotsDelta,omitempty"`
+	CodeSizeDelta *int64 `json:"codeSizeDelta,omitempty"`
+}
+
+// name returns the address of the contract, or its hash if unknown.
+func (c *contractStorage) name() string {
+	if c.Address != nil {
+		return c.Address.Hex()
+	}
+	return c.Hash.Hex()
+}
+
+// storageReport holds the storage used by the contracts of the state at a
+// block, with the contracts using the most storage slots and code.
+type storageReport struct {
+	Block     uint64      `json:"block"`
+	Hash      common.Hash `json:"hash"`
+	Root      common.Hash `json:"root"`
+	Accounts  uint64      `json:"accounts"`
+	Contracts uint64      `json:"contracts"`
+	Slots     uint64      `json:"slots"`
+	CodeSize  uint64      `json:"codeSize"`
+
+	BySlots    []*contractStorage `json:"bySlots"`
+	ByCodeSize []*contractStorage `json:"byCodeSize"`
+
+	// Set if compared against a previous report
+	Previous   *uint64 `json:"previous,omitempty"`
+	SlotsDelta *int64  `json:"slotsDelta,omitempty"`
+}
+
+// newStorageReport walks the state with the given root and reports the top
+// contracts by storage slot count and by code size.
+func newStorageReport(db state.Database, root common.Hash, top int) (*storageReport, error) {
+	accTrie, err := db.OpenTrie(root)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		report    = &storageReport{Root: root}
+		contracts []*contractStorage
+		start     = time.Now()
+		logged    = time.Now()
+		accIter   = trie.NewIterator(accTrie.NodeIterator(nil))
+	)
+	for accIter.Next() {
+		report.Accounts++
+
+		var acc state.Account
+		if err := rlp.DecodeBytes(accIter.Value, &acc); err != nil {
+			return nil, fmt.Errorf("invalid account encountered during traversal: %v", err)
+		}
+		hasCode := !bytes.Equal(acc.CodeHash, emptyCodeHash)
+		if !hasCode && acc.Root == types.EmptyRootHash {
+			continue
+		}
+		contract := &contractStorage{Hash: common.BytesToHash(accIter.Key)}
+		if preimage := accTrie.GetKey(accIter.Key); preimage != nil {
+			addr := common.BytesToAddress(preimage)
+			contract.Address = &addr
+		}
+		if hasCode {
+			size, err := db.ContractCodeSize(contract.Hash, common.BytesToHash(acc.CodeHash))
+			if err != nil {
+				return nil, fmt.Errorf("missing code of %s: %v", contract.name(), err)
+			}
+			contract.CodeSize = uint64(size)
+		}
+		if acc.Root != types.EmptyRootHash {
+			storageTrie, err := db.OpenStorageTrie(contract.Hash, acc.Root)
+			if err != nil {
+				return nil, fmt.Errorf("missing storage of %s: %v", contract.name(), err)
+			}
+			storageIter := trie.NewIterator(storageTrie.NodeIterator(nil))
+			for storageIter.Next() {
+				contract.Slots++
+			}
+			if storageIter.Err != nil {
+				return nil, fmt.Errorf("failed to traverse storage of %s: %v", contract.name(), storageIter.Err)
+			}
+		}
+		report.Contracts++
+		report.Slots += contract.Slots
+		report.CodeSize += contract.CodeSize
+		contracts = append(contracts, contract)
+
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Traversing state", "accounts", report.Accounts, "contracts", report.Contracts, "slots", report.Slots, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if accIter.Err != nil {
+		return nil, fmt.Errorf("failed to traverse state: %v", accIter.Err)
+	}
+	report.BySlots = topContracts(contracts, top, func(c *contractStorage) uint64 { return c.Slots })
+	report.ByCodeSize = topContracts(contracts, top, func(c *contractStorage) uint64 { return c.CodeSize })
+
+	log.Info("Traversed state", "accounts", report.Accounts, "contracts", report.Contracts, "slots", report.Slots, "elapsed", common.PrettyDuration(time.Since(start)))
+	return report, nil
+}
+
+// topContracts returns the given number of contracts with the highest values,
+// skipping the ones with none.
+func topContracts(contracts []*contractStorage, top int, value func(*contractStorage) uint64) []*contractStorage {
+	sorted := make([]*contractStorage, 0, len(contracts))
+	for _, contract := range contracts {
+		if value(contract) > 0 {
+			sorted = append(sorted, contract)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return value(sorted[i]) > value(sorted[j]) })
+	if len(sorted) > top {
+		sorted = sorted[:top]
+	}
+	return sorted
+}
+
+// compare sets the deltas of the report since the previous one. Only the
+// contracts listed in both reports get a delta.
+func (r *storageReport) compare(previous *storageReport) {
+	known := make(map[common.Hash]*contractStorage)
+	for _, contracts := range [][]*contractStorage{previous.BySlots, previous.ByCodeSize} {
+		for _, contract := range contracts {
+			known[contract.Hash] = contract
+		}
+	}
+	for _, contracts := range [][]*contractStorage{r.BySlots, r.ByCodeSize} {
+		for _, contract := range contracts {
+			if old, ok := known[contract.Hash]; ok {
+				slots, code := int64(contract.Slots-old.Slots), int64(contract.CodeSize-old.CodeSize)
+				contract.SlotsDelta, contract.CodeSizeDelta = &slots, &code
+			}
+		}
+	}
+	slots := int64(r.Slots - previous.Slots)
+	r.Previous, r.SlotsDelta = &previous.Block, &slots
+}
+
+// readStorageReport reads a report previously written as JSON.
+func readStorageReport(file string) (*storageReport, error) {
+	blob, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	report := new(storageReport)
+	if err := json.Unmarshal(blob, report); err != nil {
+		return nil, fmt.Errorf("invalid storage report %s: %v", file, err)
+	}
+	return report, nil
+}
+
+// print writes the report as human readable tables.
+func (r *storageReport) print(w io.Writer) {
+	fmt.Fprintf(w, "Block %d (%s), state root %s\n", r.Block, r.Hash.Hex(), r.Root.Hex())
+	fmt.Fprintf(w, "%d accounts, %d contracts, %d storage slots, %s of code\n", r.Accounts, r.Contracts, r.Slots, common.StorageSize(r.CodeSize))
+	delta := func(d *int64) string { return "" }
+	if r.Previous != nil {
+		fmt.Fprintf(w, "%s storage slots since block %d\n", formatDelta(r.SlotsDelta), *r.Previous)
+		delta = formatDelta
+	}
+	for _, table := range []struct {
+		title     string
+		contracts []*contractStorage
+	}{
+		{"Top contracts by storage slots", r.BySlots},
+		{"Top contracts by code size", r.ByCodeSize},
+	} {
+		fmt.Fprintf(w, "\n%s\n", table.title)
+		tw := tablewriter.NewWriter(w)
+		tw.SetHeader([]string{"Contract", "Slots", "Slots Delta", "Code Size", "Code Delta"})
+		for _, contract := range table.contracts {
+			tw.Append([]string{
+				contract.name(),
+				fmt.Sprint(contract.Slots),
+				delta(contract.SlotsDelta),
+				common.StorageSize(contract.CodeSize).String(),
+				delta(contract.CodeSizeDelta),
+			})
+		}
+		tw.Render()
+	}
+}
+
+// formatDelta returns the signed delta, or "new" for a contract missing from
+// the previous report.
+func formatDelta(delta *int64) string {
+	if delta == nil {
+		return "new"
+	}
+	return fmt.Sprintf("%+d", *delta)
+}
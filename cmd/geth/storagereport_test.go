@@ -0,0 +1,127 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/core/rawdb"
+	"github.com/celo-org/celo-blockchain/core/state"
+)
+
+// Tests that the storage report ranks contracts by storage slots and code size,
+// and computes the deltas since a previous report.
+func TestStorageReport(t *testing.T) {
+	var (
+		db         = state.NewDatabase(rawdb.NewMemoryDatabase())
+		statedb, _ = state.New(common.Hash{}, db, nil)
+		large      = common.HexToAddress("0x01")
+		small      = common.HexToAddress("0x02")
+		code       = common.HexToAddress("0x03")
+		eoa        = common.HexToAddress("0x04")
+		fresh      = common.HexToAddress("0x05")
+	)
+	commit := func() common.Hash {
+		root, err := statedb.Commit(false)
+		if err != nil {
+			t.Fatalf("failed to commit state: %v", err)
+		}
+		if err := db.TrieDB().Commit(root, false, nil); err != nil {
+			t.Fatalf("failed to commit tries: %v", err)
+		}
+		return root
+	}
+	for i := 0; i < 3; i++ {
+		statedb.SetState(large, common.BigToHash(big.NewInt(int64(i))), common.HexToHash("0xff"))
+	}
+	statedb.SetState(small, common.Hash{}, common.HexToHash("0xff"))
+	statedb.SetCode(small, []byte{1})
+	statedb.SetCode(code, make([]byte, 100))
+	statedb.AddBalance(eoa, big.NewInt(1))
+	root := commit()
+
+	report, err := newStorageReport(db, root, 2)
+	if err != nil {
+		t.Fatalf("failed to create report: %v", err)
+	}
+	if report.Accounts != 4 || report.Contracts != 3 || report.Slots != 4 || report.CodeSize != 101 {
+		t.Errorf("totals mismatch: %d accounts, %d contracts, %d slots, %d code, want 4, 3, 4 and 101", report.Accounts, report.Contracts, report.Slots, report.CodeSize)
+	}
+	checkRanking := func(name string, contracts []*contractStorage, want ...common.Address) {
+		if len(contracts) != len(want) {
+			t.Fatalf("%s: contract count mismatch: have %d, want %d", name, len(contracts), len(want))
+		}
+		for i, contract := range contracts {
+			if contract.Address == nil || *contract.Address != want[i] {
+				t.Errorf("%s: contract %d mismatch: have %s, want %s", name, i, contract.name(), want[i].Hex())
+			}
+		}
+	}
+	checkRanking("slots", report.BySlots, large, small)
+	checkRanking("code", report.ByCodeSize, code, small)
+
+	// Round trip the report through a file, and compare a later state against it
+	file := filepath.Join(t.TempDir(), "report.json")
+	blob, _ := json.Marshal(report)
+	if err := ioutil.WriteFile(file, blob, 0644); err != nil {
+		t.Fatal(err)
+	}
+	previous, err := readStorageReport(file)
+	if err != nil {
+		t.Fatalf("failed to read report: %v", err)
+	}
+	statedb.SetState(large, common.HexToHash("0x10"), common.HexToHash("0xff"))
+	statedb.SetState(small, common.Hash{}, common.Hash{})
+	statedb.SetState(code, common.Hash{}, common.HexToHash("0xff"))
+	statedb.SetState(fresh, common.HexToHash("0x01"), common.HexToHash("0xff"))
+	statedb.SetState(fresh, common.HexToHash("0x02"), common.HexToHash("0xff"))
+	root = commit()
+
+	report, err = newStorageReport(db, root, 3)
+	if err != nil {
+		t.Fatalf("failed to create report: %v", err)
+	}
+	report.compare(previous)
+	if report.SlotsDelta == nil || *report.SlotsDelta != 3 {
+		t.Errorf("total slots delta mismatch: have %v, want 3", report.SlotsDelta)
+	}
+	checkRanking("slots after", report.BySlots, large, fresh, code)
+	for i, want := range []int64{1, 0, 1} {
+		delta := report.BySlots[i].SlotsDelta
+		switch {
+		case i == 1 && delta != nil:
+			t.Errorf("contract missing from the previous report has a delta: %d", *delta)
+		case i != 1 && (delta == nil || *delta != want):
+			t.Errorf("contract %d slots delta mismatch: have %v, want %d", i, delta, want)
+		}
+	}
+	if delta := report.ByCodeSize[0].CodeSizeDelta; delta == nil || *delta != 0 {
+		t.Errorf("code size delta mismatch: have %v, want 0", delta)
+	}
+	var out bytes.Buffer
+	report.print(&out)
+	if !strings.Contains(out.String(), "+3 storage slots since block 0") {
+		t.Errorf("printed report misses the total delta:\n%s", out.String())
+	}
+}
@@ -0,0 +1,395 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	ethereum "github.com/celo-org/celo-blockchain"
+	"github.com/celo-org/celo-blockchain/accounts"
+	"github.com/celo-org/celo-blockchain/accounts/keystore"
+	"github.com/celo-org/celo-blockchain/accounts/usbwallet"
+	"github.com/celo-org/celo-blockchain/cmd/utils"
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/common/hexutil"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/ethclient"
+	"github.com/celo-org/celo-blockchain/log"
+	"github.com/celo-org/celo-blockchain/rlp"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// txTimeout is the time allowed for the node queries of the tx commands.
+const txTimeout = 30 * time.Second
+
+var (
+	txOfflineFlag = cli.BoolFlag{
+		Name:  "offline",
+		Usage: "Sign without contacting any node, requiring the nonce, gas, gas price and chain ID in the transaction",
+	}
+	txEndpointFlag = cli.StringFlag{
+		Name:  "endpoint",
+		Usage: "RPC endpoint of the node used to fill in and submit transactions (default = IPC endpoint inside the datadir)",
+	}
+
+	txCommand = cli.Command{
+		Name:     "tx",
+		Usage:    "Sign transactions offline and submit them",
+		Category: "ACCOUNT COMMANDS",
+		Description: `
+Signs transactions on an air-gapped machine and submits them from an online one:
+
+    geth tx sign --offline unsigned.json > signed.txt
+    geth tx send signed.txt
+
+The unsigned transaction is given as JSON, with the fields of eth_sendTransaction
+and the chain ID the signature is bound to:
+
+    {
+      "from": "0x...",
+      "to": "0x...",
+      "nonce": "0x0",
+      "gas": "0x5208",
+      "gasPrice": "0x3b9aca00",
+      "value": "0xde0b6b3a7640000",
+      "data": "0x",
+      "feeCurrency": "0x...",
+      "gatewayFeeRecipient": "0x...",
+      "gatewayFee": "0x0",
+      "chainId": "0xa4ec"
+    }`,
+		Subcommands: []cli.Command{
+			{
+				Name:      "sign",
+				Usage:     "Sign a transaction with a keystore or hardware wallet account",
+				ArgsUsage: "<unsigned transaction JSON file, or - for stdin>",
+				Action:    utils.MigrateFlags(txSign),
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.KeyStoreDirFlag,
+					utils.PasswordFileFlag,
+					utils.LightKDFFlag,
+					utils.NoUSBFlag,
+					utils.NetworkFlag,
+					utils.AlfajoresFlag,
+					utils.BaklavaFlag,
+					txOfflineFlag,
+					txEndpointFlag,
+				},
+				Description: `
+Signs the transaction with the account given as "from", and prints it as
+hex encoded RLP. The signature is always bound to a chain ID (EIP-155), so
+the transaction cannot be replayed on another network.
+
+With --offline no node is contacted, and the nonce, gas, gas price and chain
+ID must be given. Otherwise the missing ones are filled in by the node at
+--endpoint.`,
+			},
+			{
+				Name:      "send",
+				Usage:     "Submit a signed transaction",
+				ArgsUsage: "<signed transaction file, or - for stdin>",
+				Action:    utils.MigrateFlags(txSend),
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.NetworkFlag,
+					utils.AlfajoresFlag,
+					utils.BaklavaFlag,
+					txEndpointFlag,
+				},
+				Description: `
+Submits a transaction signed by "geth tx sign" through the node at --endpoint,
+and prints its hash. Transactions without replay protection, or for another
+chain than the node's, are refused.`,
+			},
+		},
+	}
+)
+
+// unsignedTx is the JSON form of a transaction to sign.
+type unsignedTx struct {
+	From                *common.Address `json:"from"`
+	To                  *common.Address `json:"to"`
+	Nonce               *hexutil.Uint64 `json:"nonce"`
+	Gas                 *hexutil.Uint64 `json:"gas"`
+	GasPrice            *hexutil.Big    `json:"gasPrice"`
+	Value               *hexutil.Big    `json:"value"`
+	Data                *hexutil.Bytes  `json:"data"`
+	Input               *hexutil.Bytes  `json:"input"`
+	FeeCurrency         *common.Address `json:"feeCurrency"`
+	GatewayFeeRecipient *common.Address `json:"gatewayFeeRecipient"`
+	GatewayFee          *hexutil.Big    `json:"gatewayFee"`
+	EthCompatible       bool            `json:"ethCompatible"`
+	ChainID             *hexutil.Big    `json:"chainId"`
+}
+
+// data returns the input of the transaction, given as data or input.
+func (args *unsignedTx) data() []byte {
+	if args.Input != nil {
+		return *args.Input
+	}
+	if args.Data != nil {
+		return *args.Data
+	}
+	return nil
+}
+
+// fill sets the missing nonce, gas, gas price and chain ID, querying the node
+// unless offline, and checks the chain ID matches the node's.
+func (args *unsignedTx) fill(client *ethclient.Client) error {
+	if client == nil {
+		var missing []string
+		if args.Nonce == nil {
+			missing = append(missing, "nonce")
+		}
+		if args.Gas == nil {
+			missing = append(missing, "gas")
+		}
+		if args.GasPrice == nil {
+			missing = append(missing, "gasPrice")
+		}
+		if args.ChainID == nil {
+			missing = append(missing, "chainId")
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("missing %s, required offline", strings.Join(missing, ", "))
+		}
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), txTimeout)
+	defer cancel()
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return err
+	}
+	if args.ChainID == nil {
+		args.ChainID = (*hexutil.Big)(chainID)
+	} else if args.ChainID.ToInt().Cmp(chainID) != 0 {
+		return fmt.Errorf("chain ID %v does not match the node's %v", args.ChainID.ToInt(), chainID)
+	}
+	if args.Nonce == nil {
+		nonce, err := client.PendingNonceAt(ctx, *args.From)
+		if err != nil {
+			return err
+		}
+		args.Nonce = (*hexutil.Uint64)(&nonce)
+	}
+	if args.GasPrice == nil {
+		price, err := client.SuggestGasPriceInCurrency(ctx, args.FeeCurrency)
+		if err != nil {
+			return err
+		}
+		args.GasPrice = (*hexutil.Big)(price)
+	}
+	if args.Gas == nil {
+		gas, err := client.EstimateGas(ctx, ethereum.CallMsg{
+			From:                *args.From,
+			To:                  args.To,
+			FeeCurrency:         args.FeeCurrency,
+			GatewayFeeRecipient: args.GatewayFeeRecipient,
+			GatewayFee:          args.GatewayFee.ToInt(),
+			GasPrice:            args.GasPrice.ToInt(),
+			Value:               args.Value.ToInt(),
+			Data:                args.data(),
+			EthCompatible:       args.EthCompatible,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to estimate gas: %v", err)
+		}
+		args.Gas = (*hexutil.Uint64)(&gas)
+	}
+	return nil
+}
+
+// toTransaction returns the transaction to sign.
+func (args *unsignedTx) toTransaction() (*types.Transaction, error) {
+	value := args.Value.ToInt()
+	if value == nil {
+		value = new(big.Int)
+	}
+	if args.EthCompatible {
+		if args.FeeCurrency != nil || args.GatewayFeeRecipient != nil || args.GatewayFee != nil {
+			return nil, types.ErrEthCompatibleTransactionIsntCompatible
+		}
+		if args.To == nil {
+			return types.NewContractCreationEthCompatible(uint64(*args.Nonce), value, uint64(*args.Gas), args.GasPrice.ToInt(), args.data()), nil
+		}
+		return types.NewTransactionEthCompatible(uint64(*args.Nonce), *args.To, value, uint64(*args.Gas), args.GasPrice.ToInt(), args.data()), nil
+	}
+	if args.To == nil {
+		return types.NewContractCreation(uint64(*args.Nonce), value, uint64(*args.Gas), args.GasPrice.ToInt(), args.FeeCurrency, args.GatewayFeeRecipient, args.GatewayFee.ToInt(), args.data()), nil
+	}
+	return types.NewTransaction(uint64(*args.Nonce), *args.To, value, uint64(*args.Gas), args.GasPrice.ToInt(), args.FeeCurrency, args.GatewayFeeRecipient, args.GatewayFee.ToInt(), args.data()), nil
+}
+
+// readTxArg reads the file given as the single argument of a tx command, or
+// stdin if it is "-".
+func readTxArg(ctx *cli.Context, name string) []byte {
+	if len(ctx.Args()) != 1 {
+		utils.Fatalf("This command requires the %s as its only argument.", name)
+	}
+	var (
+		blob []byte
+		err  error
+	)
+	if path := ctx.Args().First(); path == "-" {
+		blob, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		blob, err = ioutil.ReadFile(path)
+	}
+	if err != nil {
+		utils.Fatalf("Failed to read %s: %v", name, err)
+	}
+	return blob
+}
+
+// dialTxEndpoint connects to the node used to fill in and submit transactions.
+func dialTxEndpoint(ctx *cli.Context) *ethclient.Client {
+	endpoint := ctx.String(txEndpointFlag.Name)
+	if endpoint == "" {
+		endpoint = localIPCEndpoint(ctx)
+	}
+	client, err := dialRPC(endpoint)
+	if err != nil {
+		utils.Fatalf("Unable to attach to running geth: %v", err)
+	}
+	return ethclient.NewClient(client)
+}
+
+// findSigner returns the wallet holding the account, looking into the keystore
+// and the default derivation path of the hardware wallets.
+func findSigner(am *accounts.Manager, address common.Address) (accounts.Wallet, accounts.Account, error) {
+	account := accounts.Account{Address: address}
+	for _, wallet := range am.Wallets() {
+		switch wallet.URL().Scheme {
+		case keystore.KeyStoreScheme:
+			if wallet.Contains(account) {
+				return wallet, account, nil
+			}
+		case usbwallet.LedgerScheme, usbwallet.TrezorScheme:
+			if err := wallet.Open(""); err != nil && err != accounts.ErrWalletAlreadyOpen {
+				log.Warn("Could not open hardware wallet", "url", wallet.URL(), "err", err)
+				continue
+			}
+			derived, err := wallet.Derive(accounts.DefaultBaseDerivationPath, true)
+			if err == nil && derived.Address == address {
+				return wallet, derived, nil
+			}
+			wallet.Close()
+		}
+	}
+	return nil, account, fmt.Errorf("account %x not found", address)
+}
+
+func txSign(ctx *cli.Context) error {
+	var args unsignedTx
+	if err := json.Unmarshal(readTxArg(ctx, "unsigned transaction"), &args); err != nil {
+		utils.Fatalf("Invalid unsigned transaction: %v", err)
+	}
+	if args.From == nil {
+		utils.Fatalf("Invalid unsigned transaction: missing from")
+	}
+	var client *ethclient.Client
+	if !ctx.Bool(txOfflineFlag.Name) {
+		client = dialTxEndpoint(ctx)
+	}
+	if err := args.fill(client); err != nil {
+		utils.Fatalf("Invalid unsigned transaction: %v", err)
+	}
+	if args.ChainID.ToInt().Sign() <= 0 {
+		utils.Fatalf("Invalid unsigned transaction: chain ID must be positive for replay protection")
+	}
+	tx, err := args.toTransaction()
+	if err != nil {
+		utils.Fatalf("Invalid unsigned transaction: %v", err)
+	}
+
+	stack, _ := makeConfigNode(ctx)
+	am := stack.AccountManager()
+	wallet, account, err := findSigner(am, *args.From)
+	if err != nil {
+		utils.Fatalf("Could not find signer: %v", err)
+	}
+	if wallet.URL().Scheme == keystore.KeyStoreScheme {
+		ks := am.Backends(keystore.KeyStoreType)[0].(*keystore.KeyStore)
+		account, _ = unlockAccount(ks, args.From.Hex(), 0, utils.MakePasswordList(ctx))
+	} else {
+		defer wallet.Close()
+		log.Info("Confirm the transaction on the hardware wallet", "url", wallet.URL())
+	}
+	signed, err := wallet.SignTx(account, tx, args.ChainID.ToInt())
+	if err != nil {
+		utils.Fatalf("Failed to sign transaction: %v", err)
+	}
+	raw, err := rlp.EncodeToBytes(signed)
+	if err != nil {
+		utils.Fatalf("Failed to encode transaction: %v", err)
+	}
+	log.Info("Signed transaction", "hash", signed.Hash(), "from", args.From, "nonce", signed.Nonce(), "chainid", signed.ChainId())
+	fmt.Println(hexutil.Encode(raw))
+	return nil
+}
+
+// decodeSignedTx decodes a hex encoded signed transaction, requiring it to be
+// replay protected.
+func decodeSignedTx(input string) (*types.Transaction, error) {
+	raw, err := hexutil.Decode(strings.TrimSpace(input))
+	if err != nil {
+		return nil, err
+	}
+	tx := new(types.Transaction)
+	if err := rlp.DecodeBytes(raw, tx); err != nil {
+		return nil, err
+	}
+	if !tx.Protected() {
+		return nil, errors.New("transaction is not replay protected")
+	}
+	return tx, nil
+}
+
+func txSend(ctx *cli.Context) error {
+	tx, err := decodeSignedTx(string(readTxArg(ctx, "signed transaction")))
+	if err != nil {
+		utils.Fatalf("Invalid signed transaction: %v", err)
+	}
+	client := dialTxEndpoint(ctx)
+
+	reqCtx, cancel := context.WithTimeout(context.Background(), txTimeout)
+	defer cancel()
+
+	chainID, err := client.ChainID(reqCtx)
+	if err != nil {
+		utils.Fatalf("Failed to retrieve chain ID: %v", err)
+	}
+	if tx.ChainId().Cmp(chainID) != 0 {
+		utils.Fatalf("Transaction signed for chain ID %v, node on %v", tx.ChainId(), chainID)
+	}
+	if err := client.SendTransaction(reqCtx, tx); err != nil {
+		utils.Fatalf("Failed to submit transaction: %v", err)
+	}
+	fmt.Println(tx.Hash().Hex())
+	return nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"io/ioutil"
+	"math/big"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/common/hexutil"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/rpc"
+)
+
+// testTxAPI accepts raw transactions on chain 44787.
+type testTxAPI struct {
+	sent chan hexutil.Bytes
+}
+
+func (api *testTxAPI) ChainId() hexutil.Big { return hexutil.Big(*big.NewInt(44787)) }
+
+func (api *testTxAPI) SendRawTransaction(raw hexutil.Bytes) {
+	api.sent <- raw
+}
+
+// Tests that transactions are signed offline with replay protection, and
+// submitted from another machine.
+func TestTxSignOfflineAndSend(t *testing.T) {
+	datadir := tmpDatadirWithKeystore(t)
+	unsigned := filepath.Join(datadir, "unsigned.json")
+	if err := ioutil.WriteFile(unsigned, []byte(`{
+  "from": "0xf466859ead1932d743d622cb74fc058882e8648a",
+  "to": "0x0000000000000000000000000000000000000002",
+  "nonce": "0x7",
+  "gas": "0x5208",
+  "gasPrice": "0x3b9aca00",
+  "value": "0x1",
+  "feeCurrency": "0x0000000000000000000000000000000000000003",
+  "chainId": "0xaef3"
+}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	passwords := filepath.Join(datadir, "password.txt")
+	if err := ioutil.WriteFile(passwords, []byte("foobar"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	geth := runGeth(t, "tx", "sign", "--offline", "--nousb", "--lightkdf", "--datadir", datadir, "--password", passwords, unsigned)
+	_, matches := geth.ExpectRegexp(`(0x[0-9a-f]+)\n`)
+	geth.ExpectExit()
+	if len(matches) != 2 {
+		t.Fatalf("signed transaction not printed: %q", geth.StderrText())
+	}
+	tx, err := decodeSignedTx(matches[1])
+	if err != nil {
+		t.Fatalf("invalid signed transaction: %v", err)
+	}
+	if tx.ChainId().Int64() != 44787 || tx.Nonce() != 7 || *tx.FeeCurrency() != common.HexToAddress("0x03") {
+		t.Errorf("signed transaction mismatch: chain ID %v, nonce %d, fee currency %x", tx.ChainId(), tx.Nonce(), tx.FeeCurrency())
+	}
+	from, err := types.Sender(types.NewEIP155Signer(tx.ChainId()), tx)
+	if err != nil || from != common.HexToAddress("0xf466859ead1932d743d622cb74fc058882e8648a") {
+		t.Errorf("sender mismatch: have %x, %v", from, err)
+	}
+
+	// Missing fields are not filled in offline
+	if err := ioutil.WriteFile(unsigned, []byte(`{"from": "0xf466859ead1932d743d622cb74fc058882e8648a", "chainId": "0xaef3"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	geth = runGeth(t, "tx", "sign", "--offline", "--nousb", "--datadir", datadir, unsigned)
+	geth.ExpectRegexp(`Fatal: Invalid unsigned transaction: missing nonce, gas, gasPrice, required offline\n`)
+	geth.ExpectExit()
+
+	// The signed transaction is submitted to the node
+	api := &testTxAPI{sent: make(chan hexutil.Bytes, 1)}
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", api); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+	endpoint := httptest.NewServer(server)
+	defer endpoint.Close()
+
+	signed := filepath.Join(datadir, "signed.txt")
+	if err := ioutil.WriteFile(signed, []byte(matches[1]+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	geth = runGeth(t, "tx", "send", "--endpoint", endpoint.URL, signed)
+	geth.Expect(tx.Hash().Hex() + "\n")
+	geth.ExpectExit()
+	if raw := <-api.sent; hexutil.Encode(raw) != matches[1] {
+		t.Errorf("submitted transaction mismatch: have %x", raw)
+	}
+}
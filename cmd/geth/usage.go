@@ -36,15 +36,25 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			configFileFlag,
 			utils.DataDirFlag,
 			utils.AncientFlag,
+			utils.AncientRemoteFlag,
+			utils.AncientRemoteCacheFlag,
+			utils.DBEngineFlag,
 			utils.KeyStoreDirFlag,
 			utils.NoUSBFlag,
 			utils.NetworkIdFlag,
+			utils.NetworkFlag,
 			utils.BaklavaFlag,
 			utils.AlfajoresFlag,
 			utils.SyncModeFlag,
 			utils.ExitWhenSyncedFlag,
+			utils.ShutdownTimeoutFlag,
 			utils.GCModeFlag,
 			utils.TxLookupLimitFlag,
+			utils.HistoryBlocksFlag,
+			utils.HistoryArchiveFlag,
+			utils.HistoryProxyFlag,
+			utils.ForkDryRunFlag,
+			utils.ForkDryRunBlocksFlag,
 			utils.CeloStatsURLFlag,
 			utils.IdentityFlag,
 			utils.LightKDFFlag,
@@ -71,6 +81,10 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 		Flags: []cli.Flag{
 			utils.DeveloperFlag,
 			utils.DeveloperPeriodFlag,
+			utils.DeveloperCeloFlag,
+			utils.DeveloperBuildPathFlag,
+			utils.DeveloperAccountsFlag,
+			utils.DeveloperMnemonicFlag,
 		},
 	},
 	{
@@ -80,12 +94,14 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.TxPoolNoLocalsFlag,
 			utils.TxPoolJournalFlag,
 			utils.TxPoolRejournalFlag,
+			utils.TxPoolRemoteJournalFlag,
 			utils.TxPoolPriceLimitFlag,
 			utils.TxPoolPriceBumpFlag,
 			utils.TxPoolAccountSlotsFlag,
 			utils.TxPoolGlobalSlotsFlag,
 			utils.TxPoolAccountQueueFlag,
 			utils.TxPoolGlobalQueueFlag,
+			utils.TxPoolFeePayerSlotsFlag,
 			utils.TxPoolLifetimeFlag,
 		},
 	},
@@ -100,6 +116,10 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.CacheGCFlag,
 			utils.CacheSnapshotFlag,
 			utils.CacheNoPrefetchFlag,
+			utils.CachePreheatFlag,
+			utils.CacheTraceFlag,
+			utils.CacheTraceDiskFlag,
+			utils.CacheTraceJournalFlag,
 		},
 	},
 	{
@@ -127,11 +147,21 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.WSPortFlag,
 			utils.WSApiFlag,
 			utils.WSAllowedOriginsFlag,
+			utils.WSMaxSubscriptionsFlag,
+			utils.WSMaxQueuedWritesFlag,
+			utils.WSMaxMessageSizeFlag,
 			utils.GraphQLEnabledFlag,
 			utils.GraphQLCORSDomainFlag,
 			utils.GraphQLVirtualHostsFlag,
 			utils.RPCGlobalGasCap,
+			utils.RPCGlobalEVMTimeoutFlag,
 			utils.RPCGlobalTxFeeCap,
+			utils.RPCEthCompatibilityFlag,
+			utils.RPCCallCacheTTLFlag,
+			utils.RPCCallCacheSizeFlag,
+			utils.RPCSlowQueryFlag,
+			utils.RPCAuthFlag,
+			utils.RPCJWTSecretFlag,
 			utils.JSpathFlag,
 			utils.ExecFlag,
 			utils.PreloadJSFlag,
@@ -155,6 +185,7 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.NodeKeyHexFlag,
 			utils.PingIPFromPacketFlag,
 			utils.UseInMemoryDiscoverTableFlag,
+			utils.PersistPeersFlag,
 		},
 	},
 	{
@@ -169,6 +200,7 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 		Name: "VIRTUAL MACHINE",
 		Flags: []cli.Flag{
 			utils.VMEnableDebugFlag,
+			utils.VMTraceSamplingFlag,
 			utils.EVMInterpreterFlag,
 			utils.EWASMInterpreterFlag,
 		},
@@ -191,6 +223,13 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 		Name: "ISTANBUL",
 		Flags: []cli.Flag{
 			utils.IstanbulReplicaFlag,
+			utils.IstanbulOptimisticAnnounceFlag,
+			utils.IstanbulChunkThresholdFlag,
+			utils.IstanbulValidatorLinkRelayFlag,
+			utils.IstanbulObserveFlag,
+			utils.IstanbulServeObserversFlag,
+			utils.IstanbulAnnouncePullThresholdFlag,
+			utils.IstanbulDedupCacheFlag,
 		},
 	},
 	{
@@ -211,6 +250,36 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.ProxyAllowPrivateIPFlag,
 		},
 	},
+	{
+		Name: "SLASHER",
+		Flags: []cli.Flag{
+			utils.SlasherFlag,
+			utils.SlasherSubmitFlag,
+			utils.SlasherAccountFlag,
+			utils.SlasherMinProfitFlag,
+		},
+	},
+	{
+		Name: "CHAIN EXPORTER",
+		Flags: []cli.Flag{
+			utils.ExporterURLFlag,
+			utils.ExporterPrefixFlag,
+		},
+	},
+	{
+		Name: "CHAIN INDEXER",
+		Flags: []cli.Flag{
+			utils.IndexerURLFlag,
+			utils.IndexerStartFlag,
+		},
+	},
+	{
+		Name: "BALANCE WATCHER",
+		Flags: []cli.Flag{
+			utils.BalanceWatchAccountsFlag,
+			utils.BalanceWatchThresholdsFlag,
+		},
+	},
 	{
 		Name: "DEPRECATED",
 		Flags: append([]cli.Flag{
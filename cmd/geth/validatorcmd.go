@@ -0,0 +1,468 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"time"
+
+	ethereum "github.com/celo-org/celo-blockchain"
+	"github.com/celo-org/celo-blockchain/accounts"
+	"github.com/celo-org/celo-blockchain/accounts/abi"
+	"github.com/celo-org/celo-blockchain/accounts/abi/bind"
+	"github.com/celo-org/celo-blockchain/accounts/keystore"
+	"github.com/celo-org/celo-blockchain/cmd/utils"
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/common/decimal"
+	"github.com/celo-org/celo-blockchain/common/hexutil"
+	"github.com/celo-org/celo-blockchain/contracts/abis"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/crypto"
+	blscrypto "github.com/celo-org/celo-blockchain/crypto/bls"
+	"github.com/celo-org/celo-blockchain/ethclient"
+	"github.com/celo-org/celo-blockchain/log"
+	"github.com/celo-org/celo-blockchain/params"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// validatorTxTimeout is the time allowed for a submitted transaction to be mined.
+const validatorTxTimeout = 2 * time.Minute
+
+var (
+	validatorFromFlag = cli.StringFlag{
+		Name:  "from",
+		Usage: "Account sending the transaction",
+	}
+	validatorSendFlag = cli.BoolFlag{
+		Name:  "send",
+		Usage: "Sign the transaction with the keystore and submit it, instead of printing it",
+	}
+	validatorEndpointFlag = cli.StringFlag{
+		Name:  "endpoint",
+		Usage: "RPC endpoint of the node used to resolve contracts and submit transactions (default = IPC endpoint inside the datadir)",
+	}
+	validatorFlags = []cli.Flag{
+		utils.DataDirFlag,
+		utils.KeyStoreDirFlag,
+		utils.PasswordFileFlag,
+		utils.LightKDFFlag,
+		utils.NetworkFlag,
+		utils.AlfajoresFlag,
+		utils.BaklavaFlag,
+		validatorFromFlag,
+		validatorSendFlag,
+		validatorEndpointFlag,
+	}
+
+	validatorCommand = cli.Command{
+		Name:     "validator",
+		Usage:    "Bootstrap a validator or validator group",
+		Category: "ACCOUNT COMMANDS",
+		Description: `
+Constructs the core contract transactions needed to run a validator. By default
+the transaction is printed as JSON, to be signed and submitted by other tools.
+With --send it is signed by the keystore account given with --from, submitted
+through the node at --endpoint and waited for.
+
+A validator is typically bootstrapped with:
+
+    geth validator create-account --from <validator> --name <name> --send
+    geth validator lock --from <validator> <amount> --send
+    geth validator register --from <validator> --send
+    geth validator affiliate --from <validator> <group> --send
+
+and a group with create-account, lock, register-group and add-member. Once
+registered, verify-keys checks the keys of the validator against its signer key.`,
+		Subcommands: []cli.Command{
+			{
+				Name:   "create-account",
+				Usage:  "Create a Celo account for the sender",
+				Action: utils.MigrateFlags(validatorCreateAccount),
+				Flags: append([]cli.Flag{
+					cli.StringFlag{Name: "name", Usage: "Optional name of the account"},
+				}, validatorFlags...),
+			},
+			{
+				Name:      "lock",
+				Usage:     "Lock CELO of the sender, to meet the validator or group requirements",
+				ArgsUsage: "<amount in CELO>",
+				Action:    utils.MigrateFlags(validatorLock),
+				Flags:     validatorFlags,
+			},
+			{
+				Name:   "register",
+				Usage:  "Register the sender as a validator, with itself as the signer",
+				Action: utils.MigrateFlags(validatorRegister),
+				Flags:  validatorFlags,
+				Description: `
+Registers the sender as a validator. The ECDSA and BLS public keys, as well as
+the BLS proof-of-possession, are derived from the sender's key, so the account
+must be in the keystore even if the transaction is not submitted.`,
+			},
+			{
+				Name:      "register-group",
+				Usage:     "Register the sender as a validator group",
+				ArgsUsage: "<commission, e.g. 0.1>",
+				Action:    utils.MigrateFlags(validatorRegisterGroup),
+				Flags:     validatorFlags,
+			},
+			{
+				Name:      "affiliate",
+				Usage:     "Affiliate the sending validator with a group",
+				ArgsUsage: "<group address>",
+				Action:    utils.MigrateFlags(validatorAffiliate),
+				Flags:     validatorFlags,
+			},
+			{
+				Name:      "add-member",
+				Usage:     "Add an affiliated validator to the sending group",
+				ArgsUsage: "<validator address>",
+				Action:    utils.MigrateFlags(validatorAddMember),
+				Flags:     validatorFlags,
+			},
+			{
+				Name:   "verify-keys",
+				Usage:  "Check the keys registered for the sending validator against its signer key",
+				Action: utils.MigrateFlags(validatorVerifyKeys),
+				Flags:  validatorFlags,
+				Description: `
+Checks that the ECDSA and BLS public keys registered for the validator are the
+ones derived from the key of its registered signer, that the signer is
+authorized by the validator account, and that the proof-of-possession of the
+BLS key for the account verifies. The signer key must be in the keystore. A
+misregistered BLS key makes the validator miss every epoch signature.`,
+			},
+		},
+	}
+)
+
+// validatorTx is the printed form of a constructed, unsigned transaction.
+type validatorTx struct {
+	From  common.Address `json:"from"`
+	To    common.Address `json:"to"`
+	Value *hexutil.Big   `json:"value"`
+	Data  hexutil.Bytes  `json:"data"`
+}
+
+// validatorSession holds the node connection and sender of a validator command.
+type validatorSession struct {
+	ctx    *cli.Context
+	client *ethclient.Client
+	from   common.Address
+
+	ks      *keystore.KeyStore
+	account accounts.Account
+}
+
+// newValidatorSession connects to the node and parses the sender account.
+func newValidatorSession(ctx *cli.Context) *validatorSession {
+	from := ctx.String(validatorFromFlag.Name)
+	if !common.IsHexAddress(from) {
+		utils.Fatalf("Option --%s must be a valid address", validatorFromFlag.Name)
+	}
+	endpoint := ctx.String(validatorEndpointFlag.Name)
+	if endpoint == "" {
+		endpoint = localIPCEndpoint(ctx)
+	}
+	client, err := dialRPC(endpoint)
+	if err != nil {
+		utils.Fatalf("Unable to attach to running geth: %v", err)
+	}
+	return &validatorSession{
+		ctx:    ctx,
+		client: ethclient.NewClient(client),
+		from:   common.HexToAddress(from),
+	}
+}
+
+// keystore opens the keystore of the datadir.
+func (s *validatorSession) keystore() *keystore.KeyStore {
+	if s.ks == nil {
+		cfg := defaultNodeConfig()
+		utils.SetNodeConfig(s.ctx, &cfg)
+		ks, _, err := cfg.GetKeyStore()
+		if err != nil {
+			utils.Fatalf("Failed to open keystore: %v", err)
+		}
+		s.ks = ks
+	}
+	return s.ks
+}
+
+// unlock opens the keystore and unlocks the sender account.
+func (s *validatorSession) unlock() (*keystore.KeyStore, accounts.Account) {
+	if s.account == (accounts.Account{}) {
+		s.account, _ = unlockAccount(s.keystore(), s.from.Hex(), 0, utils.MakePasswordList(s.ctx))
+	}
+	return s.ks, s.account
+}
+
+// contract resolves the address of a core contract through the registry.
+func (s *validatorSession) contract(id common.Hash) common.Address {
+	input, err := abis.Registry.Pack("getAddressFor", id)
+	if err != nil {
+		utils.Fatalf("Failed to pack registry lookup: %v", err)
+	}
+	registry := params.RegistrySmartContractAddress
+	output, err := s.client.CallContract(context.Background(), ethereum.CallMsg{To: &registry, Data: input}, nil)
+	if err != nil {
+		utils.Fatalf("Failed to look up contract in the registry: %v", err)
+	}
+	var address common.Address
+	if err := abis.Registry.Unpack(&address, "getAddressFor", output); err != nil || address == (common.Address{}) {
+		utils.Fatalf("Contract %x is not registered", id)
+	}
+	return address
+}
+
+// call queries a core contract at the latest block.
+func (s *validatorSession) call(id common.Hash, contract *abi.ABI, result interface{}, method string, args ...interface{}) error {
+	input, err := contract.Pack(method, args...)
+	if err != nil {
+		return err
+	}
+	to := s.contract(id)
+	output, err := s.client.CallContract(context.Background(), ethereum.CallMsg{To: &to, Data: input}, nil)
+	if err != nil {
+		return err
+	}
+	return contract.Unpack(result, method, output)
+}
+
+// transact constructs a call to a core contract, printing it or, if requested,
+// signing and submitting it and waiting for it to be mined.
+func (s *validatorSession) transact(id common.Hash, contract *abi.ABI, value *big.Int, method string, args ...interface{}) error {
+	if value == nil {
+		value = new(big.Int)
+	}
+	data, err := contract.Pack(method, args...)
+	if err != nil {
+		return err
+	}
+	to := s.contract(id)
+	if !s.ctx.Bool(validatorSendFlag.Name) {
+		out, err := json.MarshalIndent(&validatorTx{From: s.from, To: to, Value: (*hexutil.Big)(value), Data: data}, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+	ks, account := s.unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), validatorTxTimeout)
+	defer cancel()
+
+	nonce, err := s.client.PendingNonceAt(ctx, s.from)
+	if err != nil {
+		return err
+	}
+	gasPrice, err := s.client.SuggestGasPrice(ctx)
+	if err != nil {
+		return err
+	}
+	gas, err := s.client.EstimateGas(ctx, ethereum.CallMsg{From: s.from, To: &to, Value: value, Data: data})
+	if err != nil {
+		return fmt.Errorf("%s would fail: %v", method, err)
+	}
+	chainID, err := s.client.ChainID(ctx)
+	if err != nil {
+		return err
+	}
+	tx, err := ks.SignTx(account, types.NewTransaction(nonce, to, value, gas, gasPrice, nil, nil, nil, data), chainID)
+	if err != nil {
+		return err
+	}
+	if err := s.client.SendTransaction(ctx, tx); err != nil {
+		return err
+	}
+	log.Info("Submitted transaction", "method", method, "hash", tx.Hash())
+	receipt, err := bind.WaitMined(ctx, s.client, tx)
+	if err != nil {
+		return err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("%s failed in block #%d", method, receipt.BlockNumber)
+	}
+	log.Info("Transaction mined", "method", method, "hash", tx.Hash(), "number", receipt.BlockNumber, "gas", receipt.GasUsed)
+	return nil
+}
+
+// validatorArg parses the single positional argument of a validator command.
+func validatorArg(ctx *cli.Context, name string) string {
+	if len(ctx.Args()) != 1 {
+		utils.Fatalf("This command requires the %s as its only argument.", name)
+	}
+	return ctx.Args().First()
+}
+
+// validatorAddressArg parses the single address argument of a validator command.
+func validatorAddressArg(ctx *cli.Context, name string) common.Address {
+	arg := validatorArg(ctx, name)
+	if !common.IsHexAddress(arg) {
+		utils.Fatalf("Invalid %s: %s", name, arg)
+	}
+	return common.HexToAddress(arg)
+}
+
+func validatorCreateAccount(ctx *cli.Context) error {
+	s := newValidatorSession(ctx)
+	if err := s.transact(params.AccountsRegistryId, abis.Accounts, nil, "createAccount"); err != nil {
+		utils.Fatalf("Failed to create account: %v", err)
+	}
+	if name := ctx.String("name"); name != "" {
+		if err := s.transact(params.AccountsRegistryId, abis.Accounts, nil, "setName", name); err != nil {
+			utils.Fatalf("Failed to set account name: %v", err)
+		}
+	}
+	return nil
+}
+
+func validatorLock(ctx *cli.Context) error {
+	amount, err := decimal.New(validatorArg(ctx, "amount"), decimal.Precision(18))
+	if err != nil || amount.Sign() <= 0 {
+		utils.Fatalf("Invalid amount: %s", ctx.Args().First())
+	}
+	if err := newValidatorSession(ctx).transact(params.LockedGoldRegistryId, abis.LockedGold, amount, "lock"); err != nil {
+		utils.Fatalf("Failed to lock CELO: %v", err)
+	}
+	return nil
+}
+
+func validatorRegister(ctx *cli.Context) error {
+	s := newValidatorSession(ctx)
+	ks, account := s.unlock()
+
+	pubkey, err := ks.GetPublicKey(account)
+	if err != nil {
+		utils.Fatalf("Failed to retrieve public key: %v", err)
+	}
+	blsPubkey, blsPop, err := ks.GenerateProofOfPossessionBLS(account, account.Address)
+	if err != nil {
+		utils.Fatalf("Failed to generate BLS proof-of-possession: %v", err)
+	}
+	// The contract expects the 64 byte public key, without the 0x04 prefix
+	ecdsaPubkey := crypto.FromECDSAPub(pubkey)[1:]
+	if err := s.transact(params.ValidatorsRegistryId, abis.Validators, nil, "registerValidator", ecdsaPubkey, blsPubkey, blsPop); err != nil {
+		utils.Fatalf("Failed to register validator: %v", err)
+	}
+	return nil
+}
+
+func validatorRegisterGroup(ctx *cli.Context) error {
+	// Commissions are fixidity fractions with 24 decimals
+	commission, err := decimal.New(validatorArg(ctx, "commission"), decimal.Precision(24))
+	if err != nil || commission.Sign() < 0 || commission.Cmp(decimal.MustNew("1", decimal.Precision(24))) > 0 {
+		utils.Fatalf("Invalid commission, must be between 0 and 1: %s", ctx.Args().First())
+	}
+	if err := newValidatorSession(ctx).transact(params.ValidatorsRegistryId, abis.Validators, nil, "registerValidatorGroup", commission); err != nil {
+		utils.Fatalf("Failed to register validator group: %v", err)
+	}
+	return nil
+}
+
+func validatorAffiliate(ctx *cli.Context) error {
+	group := validatorAddressArg(ctx, "group address")
+	if err := newValidatorSession(ctx).transact(params.ValidatorsRegistryId, abis.Validators, nil, "affiliate", group); err != nil {
+		utils.Fatalf("Failed to affiliate: %v", err)
+	}
+	return nil
+}
+
+func validatorAddMember(ctx *cli.Context) error {
+	validator := validatorAddressArg(ctx, "validator address")
+	if err := newValidatorSession(ctx).transact(params.ValidatorsRegistryId, abis.Validators, nil, "addMember", validator); err != nil {
+		utils.Fatalf("Failed to add group member: %v", err)
+	}
+	return nil
+}
+
+// registeredValidator is the validator registration returned by the Validators
+// contract.
+type registeredValidator struct {
+	EcdsaPublicKey []byte
+	BlsPublicKey   []byte
+	Affiliation    common.Address
+	Score          *big.Int
+	Signer         common.Address
+}
+
+func validatorVerifyKeys(ctx *cli.Context) error {
+	s := newValidatorSession(ctx)
+
+	var registered registeredValidator
+	if err := s.call(params.ValidatorsRegistryId, abis.Validators, &registered, "getValidator", s.from); err != nil {
+		utils.Fatalf("Failed to retrieve the registration of validator %s: %v", s.from.Hex(), err)
+	}
+	var signerAccount common.Address
+	if err := s.call(params.AccountsRegistryId, abis.Accounts, &signerAccount, "signerToAccount", registered.Signer); err != nil {
+		utils.Fatalf("Failed to retrieve the account of signer %s: %v", registered.Signer.Hex(), err)
+	}
+
+	// The keys are derived from the registered signer key, proving possession of
+	// the BLS key for the validator account like at registration
+	ks := s.keystore()
+	signer, _ := unlockAccount(ks, registered.Signer.Hex(), 0, utils.MakePasswordList(ctx))
+	pubkey, err := ks.GetPublicKey(signer)
+	if err != nil {
+		utils.Fatalf("Failed to retrieve public key: %v", err)
+	}
+	blsPubkey, blsPop, err := ks.GenerateProofOfPossessionBLS(signer, s.from)
+	if err != nil {
+		utils.Fatalf("Failed to generate BLS proof-of-possession: %v", err)
+	}
+	var registeredBLS blscrypto.SerializedPublicKey
+	copy(registeredBLS[:], registered.BlsPublicKey)
+
+	checks := []struct {
+		name string
+		err  error
+	}{
+		{"Signer authorized by the validator account", nil},
+		{"ECDSA public key derived from the signer key", nil},
+		{"BLS public key derived from the signer key", nil},
+		{"BLS proof-of-possession for the validator account", blscrypto.VerifyProofOfPossession(registeredBLS, s.from, blsPop)},
+	}
+	if signerAccount != s.from {
+		checks[0].err = fmt.Errorf("signer %s belongs to account %s", registered.Signer.Hex(), signerAccount.Hex())
+	}
+	if ecdsaPubkey := crypto.FromECDSAPub(pubkey)[1:]; !bytes.Equal(ecdsaPubkey, registered.EcdsaPublicKey) {
+		checks[1].err = fmt.Errorf("registered %x, derived %x", registered.EcdsaPublicKey, ecdsaPubkey)
+	}
+	if !bytes.Equal(blsPubkey, registered.BlsPublicKey) {
+		checks[2].err = fmt.Errorf("registered %x, derived %x", registered.BlsPublicKey, blsPubkey)
+	}
+
+	fmt.Printf("Validator: %s\nSigner:    %s\n", s.from.Hex(), registered.Signer.Hex())
+	failed := 0
+	for _, check := range checks {
+		if check.err != nil {
+			fmt.Printf("FAIL  %s: %v\n", check.name, check.err)
+			failed++
+		} else {
+			fmt.Printf("OK    %s\n", check.name)
+		}
+	}
+	if failed > 0 {
+		utils.Fatalf("%d of %d validator key checks failed", failed, len(checks))
+	}
+	return nil
+}
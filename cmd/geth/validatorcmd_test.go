@@ -0,0 +1,156 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/celo-org/celo-blockchain/accounts"
+	"github.com/celo-org/celo-blockchain/accounts/keystore"
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/common/hexutil"
+	"github.com/celo-org/celo-blockchain/contracts/abis"
+	"github.com/celo-org/celo-blockchain/crypto"
+	blscrypto "github.com/celo-org/celo-blockchain/crypto/bls"
+	"github.com/celo-org/celo-blockchain/rpc"
+)
+
+var testValidatorsAddress = common.HexToAddress("0x00000000000000000000000000000000000f0001")
+
+// testRegistryAPI answers registry lookups, resolving every contract to the
+// same address.
+type testRegistryAPI struct{}
+
+func (testRegistryAPI) Call(args map[string]interface{}, block string) hexutil.Bytes {
+	return common.LeftPadBytes(testValidatorsAddress.Bytes(), 32)
+}
+
+// Tests that validator commands print the constructed transaction unless asked
+// to submit it.
+func TestValidatorAffiliate(t *testing.T) {
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", testRegistryAPI{}); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+	endpoint := httptest.NewServer(server)
+	defer endpoint.Close()
+
+	var (
+		from  = common.HexToAddress("0x0000000000000000000000000000000000000001")
+		group = common.HexToAddress("0x0000000000000000000000000000000000000002")
+	)
+	data, err := abis.Validators.Pack("affiliate", group)
+	if err != nil {
+		t.Fatal(err)
+	}
+	geth := runGeth(t, "validator", "affiliate", "--from", from.Hex(), "--endpoint", endpoint.URL, group.Hex())
+	geth.Expect(fmt.Sprintf(`{
+  "from": "%s",
+  "to": "%s",
+  "value": "0x0",
+  "data": "%s"
+}
+`, hexutil.Encode(from[:]), hexutil.Encode(testValidatorsAddress[:]), hexutil.Encode(data)))
+	geth.ExpectExit()
+}
+
+// testValidatorKeysAPI answers the registry and validator registration lookups
+// of a validator registered with the given public keys.
+type testValidatorKeysAPI struct {
+	account   common.Address
+	validator []byte // Packed getValidator output
+}
+
+func (api *testValidatorKeysAPI) Call(args map[string]interface{}, block string) hexutil.Bytes {
+	data := hexutil.MustDecode(args["data"].(string))
+	switch {
+	case bytes.Equal(data[:4], abis.Validators.Methods["getValidator"].ID):
+		return api.validator
+	case bytes.Equal(data[:4], abis.Accounts.Methods["signerToAccount"].ID):
+		return common.LeftPadBytes(api.account.Bytes(), 32)
+	}
+	return common.LeftPadBytes(testValidatorsAddress.Bytes(), 32)
+}
+
+// Tests that the keys registered for a validator are checked against the ones
+// derived from its signer key.
+func TestValidatorVerifyKeys(t *testing.T) {
+	datadir := tmpDatadirWithKeystore(t)
+	passwords := filepath.Join(datadir, "password.txt")
+	if err := ioutil.WriteFile(passwords, []byte("foobar"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	ks := keystore.NewKeyStore(filepath.Join(datadir, "keystore"), keystore.LightScryptN, keystore.LightScryptP)
+	signer := accounts.Account{Address: common.HexToAddress("0xf466859ead1932d743d622cb74fc058882e8648a")}
+	if err := ks.Unlock(signer, "foobar"); err != nil {
+		t.Fatal(err)
+	}
+	pubkey, err := ks.GetPublicKey(signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	account := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	blsPubkey, _, err := ks.GenerateProofOfPossessionBLS(signer, account)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verify := func(blsPubkey []byte) *testgeth {
+		output, err := abis.Validators.Methods["getValidator"].Outputs.Pack(crypto.FromECDSAPub(pubkey)[1:], blsPubkey, common.Address{}, new(big.Int), signer.Address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		server := rpc.NewServer()
+		if err := server.RegisterName("eth", &testValidatorKeysAPI{account: account, validator: output}); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(server.Stop)
+		endpoint := httptest.NewServer(server)
+		t.Cleanup(endpoint.Close)
+
+		return runGeth(t, "validator", "verify-keys", "--from", account.Hex(), "--endpoint", endpoint.URL,
+			"--datadir", datadir, "--password", passwords, "--lightkdf")
+	}
+
+	geth := verify(blsPubkey)
+	geth.Expect(fmt.Sprintf(`Validator: %s
+Signer:    %s
+OK    Signer authorized by the validator account
+OK    ECDSA public key derived from the signer key
+OK    BLS public key derived from the signer key
+OK    BLS proof-of-possession for the validator account
+`, account.Hex(), signer.Address.Hex()))
+	geth.ExpectExit()
+
+	// A BLS key registered from another signer key is reported
+	other, _ := crypto.GenerateKey()
+	otherBLS, _ := blscrypto.ECDSAToBLS(other)
+	otherPubkey, _ := blscrypto.PrivateToPublic(otherBLS)
+	geth = verify(otherPubkey[:])
+	geth.ExpectRegexp(`FAIL  BLS public key derived from the signer key: registered [0-9a-f]+, derived [0-9a-f]+
+FAIL  BLS proof-of-possession for the validator account: .*
+Fatal: 2 of 4 validator key checks failed
+`)
+	geth.ExpectExit()
+}
@@ -0,0 +1,84 @@
+// Copyright 2021 The celo Authors
+// This file is part of celo.
+//
+// celo is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// celo is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with celo. If not, see <http://www.gnu.org/licenses/>.
+
+// +build js,wasm
+
+// lightverify exposes Celo header verification to JavaScript. Build it with
+//
+//	GOOS=js GOARCH=wasm go build -o lightverify.wasm ./cmd/lightverify
+//
+// and load it with the wasm_exec.js support file of the Go distribution. It
+// registers a global celoLightVerify object:
+//
+//	const v = celoLightVerify.newVerifier(epochSize, genesisHeaderRLPHex)
+//	v.verifyHeader(headerRLPHex) // null if valid, an error message otherwise
+//	v.number()                   // last epoch block applied to the validator set
+package main
+
+import (
+	"syscall/js"
+
+	"github.com/celo-org/celo-blockchain/common/hexutil"
+	"github.com/celo-org/celo-blockchain/consensus/istanbul/lightverify"
+)
+
+func decodeHeader(s string) (*lightverify.Header, error) {
+	data, err := hexutil.Decode(s)
+	if err != nil {
+		return nil, err
+	}
+	return lightverify.DecodeHeader(data)
+}
+
+func newVerifier(this js.Value, args []js.Value) interface{} {
+	if len(args) != 2 {
+		return js.Global().Get("Error").New("expected epoch size and genesis header")
+	}
+	genesis, err := decodeHeader(args[1].String())
+	if err != nil {
+		return js.Global().Get("Error").New(err.Error())
+	}
+	v, err := lightverify.NewVerifierFromGenesis(uint64(args[0].Int()), genesis)
+	if err != nil {
+		return js.Global().Get("Error").New(err.Error())
+	}
+	return map[string]interface{}{
+		"verifyHeader": js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			if len(args) != 1 {
+				return "expected header"
+			}
+			h, err := decodeHeader(args[0].String())
+			if err == nil {
+				err = v.VerifyHeader(h)
+			}
+			if err != nil {
+				return err.Error()
+			}
+			return nil
+		}),
+		"number": js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			return float64(v.Number())
+		}),
+	}
+}
+
+func main() {
+	js.Global().Set("celoLightVerify", map[string]interface{}{
+		"newVerifier": js.FuncOf(newVerifier),
+	})
+	// Keep the exported functions alive.
+	select {}
+}
@@ -0,0 +1,188 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/core"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/internal/era"
+	"github.com/celo-org/celo-blockchain/log"
+)
+
+// historyChecksums is the name of the file listing the checksums of the
+// exported history archives.
+const historyChecksums = "checksums.txt"
+
+// ExportHistory exports the blocks [first, last] into era archives of step
+// blocks each in dir, along with a checksum listing for distribution.
+func ExportHistory(bc *core.BlockChain, dir string, network string, first, last, step uint64) error {
+	log.Info("Exporting blockchain history", "dir", dir, "first", first, "last", last)
+	if head := bc.CurrentBlock().NumberU64(); head < last {
+		return fmt.Errorf("export range exceeds chain head: head %d, last %d", head, last)
+	}
+	if step == 0 || step > era.MaxEra1Size {
+		return fmt.Errorf("invalid archive size %d, must be between 1 and %d", step, era.MaxEra1Size)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	var (
+		start     = time.Now()
+		reported  = time.Now()
+		checksums []string
+	)
+	for from := first; from <= last; from += step {
+		to := from + step - 1
+		if to > last {
+			to = last
+		}
+		name, checksum, err := exportArchive(bc, dir, network, int(from/step), from, to)
+		if err != nil {
+			return err
+		}
+		checksums = append(checksums, fmt.Sprintf("%x  %s", checksum, name))
+		if time.Since(reported) >= 8*time.Second {
+			log.Info("Exporting blocks", "exported", to-first+1, "elapsed", common.PrettyDuration(time.Since(start)))
+			reported = time.Now()
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, historyChecksums), []byte(strings.Join(checksums, "\n")+"\n"), 0644); err != nil {
+		return err
+	}
+	log.Info("Exported blockchain history", "dir", dir, "archives", len(checksums), "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// exportArchive writes the blocks [from, to] into a single archive, returning
+// its content addressed name and checksum.
+func exportArchive(bc *core.BlockChain, dir string, network string, index int, from, to uint64) (string, []byte, error) {
+	tmp := filepath.Join(dir, fmt.Sprintf("%s-%05d.era1.tmp", network, index))
+	f, err := os.Create(tmp)
+	if err != nil {
+		return "", nil, err
+	}
+	defer os.Remove(tmp)
+
+	var (
+		hasher  = sha256.New()
+		builder = era.NewBuilder(io.MultiWriter(f, hasher))
+	)
+	for number := from; number <= to; number++ {
+		block := bc.GetBlockByNumber(number)
+		if block == nil {
+			f.Close()
+			return "", nil, fmt.Errorf("export failed on #%d: not found", number)
+		}
+		receipts := bc.GetReceiptsByHash(block.Hash())
+		if receipts == nil {
+			receipts = types.Receipts{}
+		}
+		td := bc.GetTd(block.Hash(), number)
+		if td == nil {
+			f.Close()
+			return "", nil, fmt.Errorf("export failed on #%d: total difficulty not found", number)
+		}
+		if err := builder.Add(block, receipts, td); err != nil {
+			f.Close()
+			return "", nil, err
+		}
+	}
+	root, err := builder.Finalize()
+	if err != nil {
+		f.Close()
+		return "", nil, err
+	}
+	if err := f.Close(); err != nil {
+		return "", nil, err
+	}
+	name := era.Filename(network, index, root)
+	if err := os.Rename(tmp, filepath.Join(dir, name)); err != nil {
+		return "", nil, err
+	}
+	return name, hasher.Sum(nil), nil
+}
+
+// ImportHistory verifies and imports all era archives of a network in dir,
+// in ascending order, skipping blocks already present in the chain.
+func ImportHistory(chain *core.BlockChain, dir string, network string) error {
+	files, err := era.ReadDir(dir, network)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no %s history archives found in %s", network, dir)
+	}
+	var (
+		start    = time.Now()
+		reported = time.Now()
+		imported int
+	)
+	for _, name := range files {
+		e, err := era.Open(filepath.Join(dir, name))
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %v", name, err)
+		}
+		// Reject archives whose contents don't match their content address
+		root, err := e.Verify()
+		if err == nil && !strings.HasSuffix(name, fmt.Sprintf("-%s.era1", root.Hex()[2:10])) {
+			err = fmt.Errorf("accumulator %x does not match the file name", root)
+		}
+		if err != nil {
+			e.Close()
+			return fmt.Errorf("failed to verify %s: %v", name, err)
+		}
+		blocks := make(types.Blocks, 0, importBatchSize)
+		for number := e.Start(); number < e.Start()+e.Count(); number++ {
+			if number == 0 {
+				continue // Genesis is never imported
+			}
+			block, _, _, err := e.GetBlockByNumber(number)
+			if err != nil {
+				e.Close()
+				return err
+			}
+			blocks = append(blocks, block)
+			if len(blocks) == importBatchSize || number == e.Start()+e.Count()-1 {
+				if missing := missingBlocks(chain, blocks); len(missing) > 0 {
+					if _, err := chain.InsertChain(missing); err != nil {
+						e.Close()
+						return fmt.Errorf("failed to import %s: %v", name, err)
+					}
+					imported += len(missing)
+				}
+				blocks = blocks[:0]
+			}
+			if time.Since(reported) >= 8*time.Second {
+				log.Info("Importing blockchain history", "file", name, "number", number, "imported", imported, "elapsed", common.PrettyDuration(time.Since(start)))
+				reported = time.Now()
+			}
+		}
+		e.Close()
+	}
+	log.Info("Imported blockchain history", "archives", len(files), "blocks", imported, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
@@ -44,6 +44,10 @@ import (
 const (
 	queryEnodeGossipCooldownDuration         = 5 * time.Minute
 	versionCertificateGossipCooldownDuration = 5 * time.Minute
+
+	// valEnodeEntryMaxAge is the time after which the entries of the validator
+	// enode table whose version wasn't raised are considered stale
+	valEnodeEntryMaxAge = 24 * time.Hour
 )
 
 var (
@@ -87,6 +91,8 @@ type AnnounceNetwork interface {
 	// Multicast will send the eth message (with the message's payload and msgCode field set to the params
 	// payload and ethMsgCode respectively) to the nodes with the signing address in the destAddresses param.
 	Multicast(destAddresses []common.Address, payload []byte, ethMsgCode uint64, sendToSelf bool) error
+	// SigningDomains returns the domains messages are signed and verified in
+	SigningDomains() istanbul.SigningDomains
 }
 
 type AnnounceManagerConfig struct {
@@ -289,6 +295,11 @@ func (sb *Backend) announceThread() {
 
 				announcing = false
 				logger.Trace("Disabled periodic gossiping of announce message")
+			} else if announcing && sb.announceManager.externalEnodeChanged() {
+				// Rotate the announced enode right away, e.g. after an IP change,
+				// rather than at the next periodic update
+				logger.Info("External enode changed, announcing the new one", "enode", sb.SelfNode())
+				updateAnnounceVersionFunc()
 			}
 
 		case <-shareVersionCertificatesTicker.C:
@@ -396,7 +407,7 @@ func (m *AnnounceManager) shouldParticipateInAnnounce() (bool, error) {
 // pruneAnnounceDataStructures will remove entries that are not in the validator connection set from all announce related data structures.
 // The data structures that it prunes are:
 // 1)  lastQueryEnodeGossiped
-// 2)  valEnodeTable
+// 2)  valEnodeTable, which is also pruned of stale entries and compacted
 // 3)  lastVersionCertificatesGossiped
 // 4)  versionCertificateTable
 func (m *AnnounceManager) pruneAnnounceDataStructures() error {
@@ -421,6 +432,14 @@ func (m *AnnounceManager) pruneAnnounceDataStructures() error {
 		logger.Trace("Error in pruning valEnodeTable", "err", err)
 		return err
 	}
+	if err := m.valEnodeTable.PruneStaleEntries(valEnodeEntryMaxAge); err != nil {
+		logger.Trace("Error in pruning stale entries of valEnodeTable", "err", err)
+		return err
+	}
+	if err := m.valEnodeTable.Compact(); err != nil {
+		logger.Trace("Error in compacting valEnodeTable", "err", err)
+		return err
+	}
 
 	m.lastVersionCertificatesGossipedMu.Lock()
 	for remoteAddress := range m.lastVersionCertificatesGossiped {
@@ -612,7 +631,7 @@ func (m *AnnounceManager) generateQueryEnodeMsg(version uint, enodeQueries []*en
 		Timestamp:          getTimestamp(),
 	}, m.wallets().Ecdsa.Address)
 	// Sign the announce message
-	if err := msg.Sign(m.wallets().Ecdsa.Sign); err != nil {
+	if err := msg.SignInDomain(m.network.SigningDomains().Sign, m.wallets().Ecdsa.Sign); err != nil {
 		logger.Error("Error in signing a QueryEnode Message", "QueryEnodeMsg", msg.String(), "err", err)
 		return nil, err
 	}
@@ -665,7 +684,7 @@ func (m *AnnounceManager) handleQueryEnodeMsg(addr common.Address, peer consensu
 	defer m.gossipCache.MarkMessageProcessedBySelf(payload)
 
 	// Decode message
-	err := msg.FromPayload(payload, istanbul.GetSignatureAddress)
+	err := msg.FromPayloadInDomains(payload, m.network.SigningDomains().Verify, istanbul.GetSignatureAddress)
 	if err != nil {
 		logger.Error("Error in decoding received Istanbul Announce message", "err", err, "payload", hex.EncodeToString(payload))
 		return err
@@ -1071,6 +1090,27 @@ func (m *AnnounceManager) setAndShareUpdatedAnnounceVersion(version uint) error
 	})
 }
 
+// externalEnodeChanged returns whether the external enode of this standalone
+// validator differs from the one in its latest enode certificate. The enode of
+// proxied validators is the one of their proxies, whose changes already update
+// the announce version.
+func (m *AnnounceManager) externalEnodeChanged() bool {
+	if m.config.IsProxiedValidator {
+		return false
+	}
+	enodeCertMsgMap := m.RetrieveEnodeCertificateMsgMap()
+	if enodeCertMsgMap == nil {
+		// Nothing announced yet
+		return false
+	}
+	selfNode := m.addrProvider.SelfNode()
+	enodeCertMsg, ok := enodeCertMsgMap[selfNode.ID()]
+	if !ok {
+		return true
+	}
+	return enodeCertMsg.Msg.EnodeCertificate().EnodeURL != selfNode.URLv4()
+}
+
 func getTimestamp() uint {
 	// Unix() returns a int64, but we need a uint for the golang rlp encoding implmentation. Warning: This timestamp value will be truncated in 2106.
 	return uint(time.Now().Unix())
@@ -1139,7 +1179,7 @@ func (m *AnnounceManager) generateEnodeCertificateMsgs(version uint) (map[enode.
 			m.wallets().Ecdsa.Address,
 		)
 		// Sign the message
-		if err := msg.Sign(m.wallets().Ecdsa.Sign); err != nil {
+		if err := msg.SignInDomain(m.network.SigningDomains().Sign, m.wallets().Ecdsa.Sign); err != nil {
 			return nil, err
 		}
 
@@ -1156,7 +1196,7 @@ func (sb *Backend) handleEnodeCertificateMsg(_ consensus.Peer, payload []byte) e
 
 	var msg istanbul.Message
 	// Decode payload into msg
-	err := msg.FromPayload(payload, istanbul.GetSignatureAddress)
+	err := msg.FromPayloadInDomains(payload, sb.SigningDomains().Verify, istanbul.GetSignatureAddress)
 	if err != nil {
 		logger.Error("Error in decoding received Istanbul Enode Certificate message", "err", err, "payload", hex.EncodeToString(payload))
 		return err
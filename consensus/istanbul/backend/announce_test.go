@@ -1,11 +1,16 @@
 package backend
 
 import (
+	"net"
 	"testing"
 	"time"
 
 	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/consensus/consensustest"
 	"github.com/celo-org/celo-blockchain/consensus/istanbul"
+	"github.com/celo-org/celo-blockchain/crypto"
+	"github.com/celo-org/celo-blockchain/p2p"
+	"github.com/celo-org/celo-blockchain/p2p/enode"
 	"github.com/celo-org/celo-blockchain/rlp"
 )
 
@@ -215,7 +220,7 @@ func TestSetAndShareUpdatedAnnounceVersion(t *testing.T) {
 	enodeCertMsgs := engine.RetrieveEnodeCertificateMsgMap()
 
 	// For a standalone validator, there should be only one entry in the enodeCert Map.
-	// TODO.  Add test case for when the engine has proxies
+	// Proxied validators are covered by TestProxiedValidatorEnodeCertificates.
 	selfEnode := engine.SelfNode()
 	enodeCertMsg := enodeCertMsgs[selfEnode.ID()]
 
@@ -258,3 +263,120 @@ func TestSetAndShareUpdatedAnnounceVersion(t *testing.T) {
 
 	engine.StopAnnouncing()
 }
+
+// This function will test that a change of the external enode of a standalone
+// validator is detected until an enode certificate is generated for it.
+func TestExternalEnodeChanged(t *testing.T) {
+	genesisCfg, nodeKeys := getGenesisAndKeys(1, true)
+
+	chain, engine, _ := newBlockChainWithKeys(false, common.Address{}, false, genesisCfg, nodeKeys[0])
+	defer chain.Stop()
+	defer engine.StopAnnouncing()
+
+	// Certify the enode of this validator at an outdated IP
+	selfNode := engine.SelfNode()
+	oldNode := enode.NewV4(selfNode.Pubkey(), net.IP{10, 0, 0, 1}, selfNode.TCP(), selfNode.UDP())
+	announceVersion := engine.GetAnnounceVersion() + 10000
+	msg := istanbul.NewEnodeCeritifcateMessage(&istanbul.EnodeCertificate{EnodeURL: oldNode.URLv4(), Version: announceVersion}, engine.Address())
+	if err := engine.SetEnodeCertificateMsgMap(map[enode.ID]*istanbul.EnodeCertMsg{oldNode.ID(): {Msg: msg}}); err != nil {
+		t.Fatalf("error mismatch: have %v, want nil", err)
+	}
+	if !engine.announceManager.externalEnodeChanged() {
+		t.Errorf("external enode change not detected")
+	}
+
+	// Certifying the current enode completes the rotation
+	if err := engine.announceManager.setAndShareUpdatedAnnounceVersion(announceVersion + 1); err != nil {
+		t.Fatalf("error mismatch: have %v, want nil", err)
+	}
+	if engine.announceManager.externalEnodeChanged() {
+		t.Errorf("external enode change detected after rotation")
+	}
+}
+
+// Tests that a validator served by several proxies, each with its own p2p
+// identity, certifies the external enode of every proxy with its validator key
+// and directs each certificate to the validators assigned to that proxy, and
+// that remote validators then reach it through its assigned proxy.
+func TestProxiedValidatorEnodeCertificates(t *testing.T) {
+	genesisCfg, nodeKeys := getGenesisAndKeys(3, true)
+
+	valChain, valEngine, _ := newBlockChainWithKeys(false, common.Address{}, true, genesisCfg, nodeKeys[0])
+	defer valChain.Stop()
+	defer valEngine.StopAnnouncing()
+	remoteChain, remoteEngine, _ := newBlockChainWithKeys(false, common.Address{}, false, genesisCfg, nodeKeys[1])
+	defer remoteChain.Stop()
+	defer remoteEngine.StopAnnouncing()
+
+	// Add two proxies with node keys unrelated to the validator key
+	pv := valEngine.GetProxiedValidatorEngine()
+	externalNodes := make(map[enode.ID]*enode.Node)
+	for i := 0; i < 2; i++ {
+		proxyKey, _ := crypto.GenerateKey()
+		internal := enode.NewV4(&proxyKey.PublicKey, net.IP{10, 0, 0, byte(i + 1)}, 30503, 0)
+		external := enode.NewV4(&proxyKey.PublicKey, net.IP{192, 168, 0, byte(i + 1)}, 30303, 30303)
+		if err := pv.AddProxy(internal, external); err != nil {
+			t.Fatalf("failed to add proxy %d: %v", i, err)
+		}
+		if err := pv.RegisterProxyPeer(consensustest.NewMockPeer(internal, p2p.ProxyPurpose)); err != nil {
+			t.Fatalf("failed to peer proxy %d: %v", i, err)
+		}
+		externalNodes[internal.ID()] = external
+	}
+	// Wait for the proxies to be assigned and certified
+	time.Sleep(6 * time.Second)
+
+	proxies, assignments, err := pv.GetProxiesAndValAssignments()
+	if err != nil {
+		t.Fatalf("failed to retrieve proxy assignments: %v", err)
+	}
+	if len(proxies) != 2 {
+		t.Fatalf("proxy count mismatch: have %d, want 2", len(proxies))
+	}
+	enodeCerts := valEngine.RetrieveEnodeCertificateMsgMap()
+	if len(enodeCerts) != 2 {
+		t.Fatalf("enode certificate count mismatch: have %d, want 2", len(enodeCerts))
+	}
+	if enodeCerts[valEngine.SelfNode().ID()] != nil {
+		t.Errorf("enode of the validator itself certified")
+	}
+	assigned := make(map[common.Address]*enode.Node)
+	for id, external := range externalNodes {
+		enodeCertMsg := enodeCerts[external.ID()]
+		if enodeCertMsg == nil {
+			t.Fatalf("no enode certificate for proxy %v", id)
+		}
+		if enodeCertMsg.Msg.Address != valEngine.Address() {
+			t.Errorf("proxy %v: certificate signer mismatch: have %v, want %v", id, enodeCertMsg.Msg.Address, valEngine.Address())
+		}
+		if url := enodeCertMsg.Msg.EnodeCertificate().EnodeURL; url != external.URLv4() {
+			t.Errorf("proxy %v: certified enode mismatch: have %s, want %s", id, url, external.URLv4())
+		}
+		if len(enodeCertMsg.DestAddresses) != len(assignments[id]) {
+			t.Errorf("proxy %v: certificate destinations mismatch: have %v, want %v", id, enodeCertMsg.DestAddresses, assignments[id])
+		}
+		for _, address := range enodeCertMsg.DestAddresses {
+			assigned[address] = external
+		}
+	}
+	remoteAddress := remoteEngine.Address()
+	if len(assigned) != 2 || assigned[remoteAddress] == nil {
+		t.Fatalf("remote validators not all assigned a proxy: %v", assigned)
+	}
+
+	// The remote validator reaches the validator through its assigned proxy
+	payload, err := enodeCerts[assigned[remoteAddress].ID()].Msg.Payload()
+	if err != nil {
+		t.Fatalf("failed to encode enode certificate: %v", err)
+	}
+	if err := remoteEngine.handleEnodeCertificateMsg(nil, payload); err != nil {
+		t.Fatalf("failed to handle enode certificate: %v", err)
+	}
+	entries, err := remoteEngine.GetValEnodeTableEntries([]common.Address{valEngine.Address()})
+	if err != nil {
+		t.Fatalf("failed to retrieve val enode table entries: %v", err)
+	}
+	if entry := entries[valEngine.Address()]; entry == nil || entry.Node.URLv4() != assigned[remoteAddress].URLv4() {
+		t.Errorf("val enode table entry mismatch: have %v, want %v", entry, assigned[remoteAddress])
+	}
+}
@@ -17,11 +17,13 @@
 package backend
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math/big"
 
 	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/common/hexutil"
 	"github.com/celo-org/celo-blockchain/consensus"
 	"github.com/celo-org/celo-blockchain/consensus/istanbul"
 	vet "github.com/celo-org/celo-blockchain/consensus/istanbul/backend/internal/enodes"
@@ -29,10 +31,14 @@ import (
 	"github.com/celo-org/celo-blockchain/consensus/istanbul/core"
 	"github.com/celo-org/celo-blockchain/consensus/istanbul/proxy"
 	"github.com/celo-org/celo-blockchain/consensus/istanbul/validator"
+	ethCore "github.com/celo-org/celo-blockchain/core"
 	"github.com/celo-org/celo-blockchain/core/types"
 	blscrypto "github.com/celo-org/celo-blockchain/crypto/bls"
+	"github.com/celo-org/celo-blockchain/log"
 	"github.com/celo-org/celo-blockchain/p2p/enode"
+	"github.com/celo-org/celo-blockchain/rlp"
 	"github.com/celo-org/celo-blockchain/rpc"
+	"github.com/celo-org/celo-bls-go/snark"
 )
 
 // API is a user facing RPC API to dump Istanbul state
@@ -43,15 +49,20 @@ type API struct {
 
 // getHeaderByNumber retrieves the header requested block or current if unspecified.
 func (api *API) getHeaderByNumber(number *rpc.BlockNumber) (*types.Header, error) {
+	return headerByNumber(api.chain, number)
+}
+
+// headerByNumber retrieves the header requested block or current if unspecified.
+func headerByNumber(chain consensus.ChainHeaderReader, number *rpc.BlockNumber) (*types.Header, error) {
 	var header *types.Header
 	if number == nil || *number == rpc.LatestBlockNumber {
-		header = api.chain.CurrentHeader()
+		header = chain.CurrentHeader()
 	} else if *number == rpc.PendingBlockNumber {
 		return nil, fmt.Errorf("can't use pending block within istanbul")
 	} else if *number == rpc.EarliestBlockNumber {
-		header = api.chain.GetHeaderByNumber(0)
+		header = chain.GetHeaderByNumber(0)
 	} else {
-		header = api.chain.GetHeaderByNumber(uint64(*number))
+		header = chain.GetHeaderByNumber(uint64(*number))
 	}
 
 	if header == nil {
@@ -184,19 +195,29 @@ func (api *API) GetValEnodeTable() (map[string]*vet.ValEnodeEntryInfo, error) {
 	return api.istanbul.valEnodeTable.ValEnodeTableInfo()
 }
 
+// GetValidatorLinks retrieves the health of the direct links to the other validators
+func (api *API) GetValidatorLinks() (map[string]*ValidatorLinkInfo, error) {
+	return api.istanbul.vph.ValidatorLinksInfo(), nil
+}
+
+// ObservedMessages retrieves the consensus messages observed for the sequence being agreed on
+func (api *API) ObservedMessages() ([]*ObservedMessage, error) {
+	return api.istanbul.ObservedMessages()
+}
+
 func (api *API) GetVersionCertificateTableInfo() (map[string]*vet.VersionCertificateEntryInfo, error) {
 	return api.istanbul.announceManager.GetVersionCertificateTableInfo()
 }
 
 // GetCurrentRoundState retrieves the current IBFT RoundState
 func (api *API) GetCurrentRoundState() (*core.RoundStateSummary, error) {
-	api.istanbul.coreMu.RLock()
-	defer api.istanbul.coreMu.RUnlock()
+	return api.istanbul.CurrentRoundStateSummary()
+}
 
-	if !api.istanbul.coreStarted {
-		return nil, istanbul.ErrStoppedEngine
-	}
-	return api.istanbul.core.CurrentRoundState().Summary(), nil
+// EpochSignatureStatus retrieves the status of the signatures of the epoch SNARK data of the
+// latest epochs, reporting the ones the BLS signer failed to sign
+func (api *API) EpochSignatureStatus() ([]*core.EpochSignatureStatus, error) {
+	return api.istanbul.EpochSignatureStatus()
 }
 
 func (api *API) ForceRoundChange() (bool, error) {
@@ -294,3 +315,118 @@ func (api *API) GetLookbackWindow(number *rpc.BlockNumber) (uint64, error) {
 
 	return api.istanbul.LookbackWindow(header, state), nil
 }
+
+// GetBlockAttestation retrieves an RLP encoded attestation of the requested block
+// or current if unspecified, consisting of its header with the aggregated seal, the
+// validator set that sealed it and the validator set's seal by the previous epoch.
+func (api *API) GetBlockAttestation(number *rpc.BlockNumber) (hexutil.Bytes, error) {
+	header, err := api.getHeaderByNumber(number)
+	if err != nil {
+		return nil, err
+	}
+	att, err := api.istanbul.blockAttestation(header)
+	if err != nil {
+		return nil, err
+	}
+	return rlp.EncodeToBytes(att)
+}
+
+// DecodeExtra decodes the istanbul extra-data of the requested header or current
+// if unspecified, including the epoch snark data of the last block of an epoch.
+func (api *API) DecodeExtra(number *rpc.BlockNumber) (*DecodedExtra, error) {
+	header, err := api.getHeaderByNumber(number)
+	if err != nil {
+		return nil, err
+	}
+	return api.istanbul.decodeExtra(header)
+}
+
+// VerifyPlumoProof verifies a Plumo SNARK proof of the validator set transitions
+// from the first to the last given epoch, using the epoch data stored in the
+// chain as public inputs. It returns false if the proof does not hold.
+func (api *API) VerifyPlumoProof(verifyingKey, proof hexutil.Bytes, firstEpoch, lastEpoch hexutil.Uint64) (bool, error) {
+	err := api.istanbul.verifyPlumoProof(verifyingKey, proof, uint64(firstEpoch), uint64(lastEpoch))
+	if err == snark.VerificationError {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// CeloAPI is a user facing RPC API exposing Celo protocol details
+type CeloAPI struct {
+	chain    consensus.ChainHeaderReader
+	istanbul *Backend
+}
+
+// GetEpochRewards retrieves the rewards distributed at the end of the given epoch,
+// as computed by reexecuting its last block. The state of the block preceding it
+// must be available.
+func (api *CeloAPI) GetEpochRewards(ctx context.Context, epoch hexutil.Uint64) (*EpochRewards, error) {
+	return api.istanbul.epochRewards(ctx, uint64(epoch))
+}
+
+// EpochStats retrieves the stats recorded for the blocks of the given epoch, or
+// the current one if unspecified.
+func (api *CeloAPI) EpochStats(epoch *hexutil.Uint64) (*EpochStats, error) {
+	if epoch == nil {
+		number := api.chain.CurrentHeader().Number.Uint64()
+		return api.istanbul.EpochStats(istanbul.GetEpochNumber(number, api.istanbul.EpochSize()))
+	}
+	return api.istanbul.EpochStats(uint64(*epoch))
+}
+
+// GetRandomness retrieves the randomness revealed and committed to in the requested
+// block or current if unspecified, along with the randomness of the Random contract
+// after it.
+func (api *CeloAPI) GetRandomness(number *rpc.BlockNumber) (*BlockRandomness, error) {
+	header, err := headerByNumber(api.chain, number)
+	if err != nil {
+		return nil, err
+	}
+	bc, ok := api.chain.(*ethCore.BlockChain)
+	if !ok {
+		return nil, errNoFullChain
+	}
+	block := bc.GetBlock(header.Hash(), header.Number.Uint64())
+	if block == nil {
+		return nil, errUnknownBlock
+	}
+	return api.istanbul.blockRandomness(block)
+}
+
+// Randomness creates a subscription that fires with the randomness of every block
+// appended to the canonical chain.
+func (api *CeloAPI) Randomness(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	bc, ok := api.chain.(*ethCore.BlockChain)
+	if !ok {
+		return nil, errNoFullChain
+	}
+	var (
+		rpcSub = notifier.CreateSubscription()
+		events = make(chan ethCore.ChainEvent, 16)
+		sub    = bc.SubscribeChainEvent(events)
+	)
+	go func() {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case ev := <-events:
+				randomness, err := api.istanbul.blockRandomness(ev.Block)
+				if err != nil {
+					log.Debug("Failed to retrieve block randomness", "number", ev.Block.Number(), "hash", ev.Hash, "err", err)
+					continue
+				}
+				notifier.Notify(rpcSub.ID, randomness)
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
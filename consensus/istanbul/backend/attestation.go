@@ -0,0 +1,95 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"math/big"
+
+	"github.com/celo-org/celo-blockchain/consensus/istanbul"
+	"github.com/celo-org/celo-blockchain/consensus/istanbul/validator"
+	ethCore "github.com/celo-org/celo-blockchain/core"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/log"
+	"github.com/celo-org/celo-blockchain/rlp"
+)
+
+// BlockAttestation is a self-contained proof that a block was finalized by the
+// validators of its epoch, for verifiers that do not follow the chain.
+type BlockAttestation struct {
+	// Header is the attested header, its extra data holds the aggregated seal
+	Header *types.Header
+	// Validators is the validator set that sealed the header, in order
+	Validators []istanbul.ValidatorData
+	// EpochSnarkData is the seal of the previous epoch's validators over this
+	// validator set, taken from the last block of the previous epoch. It lets
+	// verifiers follow validator set changes from a trusted set, and is empty
+	// in the first epoch or when the block body is unavailable.
+	EpochSnarkData *types.EpochSnarkData
+}
+
+// blockAttestation assembles the attestation of the given header.
+func (sb *Backend) blockAttestation(header *types.Header) (*BlockAttestation, error) {
+	number := header.Number.Uint64()
+	if number == 0 {
+		return nil, errUnknownBlock
+	}
+	validators := sb.GetValidators(new(big.Int).SetUint64(number-1), header.ParentHash)
+	if len(validators) == 0 {
+		return nil, errUnknownBlock
+	}
+	att := &BlockAttestation{
+		Header:         header,
+		Validators:     make([]istanbul.ValidatorData, len(validators)),
+		EpochSnarkData: &types.EmptyEpochSnarkData,
+	}
+	for i, val := range validators {
+		att.Validators[i] = istanbul.ValidatorData{Address: val.Address(), BLSPublicKey: val.BLSPublicKey()}
+	}
+	if epoch := istanbul.GetEpochNumber(number, sb.EpochSize()); epoch > 1 {
+		if bc, ok := sb.chain.(*ethCore.BlockChain); ok {
+			if block := bc.GetBlockByNumber(istanbul.GetEpochLastBlockNumber(epoch-1, sb.EpochSize())); block != nil {
+				att.EpochSnarkData = block.EpochSnarkData()
+			}
+		}
+	}
+	return att, nil
+}
+
+// VerifyBlockAttestation decodes an attestation blob and verifies that its header
+// was sealed by a quorum of its validator set. Callers must check the validator
+// set against one they trust, e.g. by following the epoch snark data.
+func VerifyBlockAttestation(blob []byte) (*BlockAttestation, error) {
+	att := new(BlockAttestation)
+	if err := rlp.DecodeBytes(blob, att); err != nil {
+		return nil, err
+	}
+	if att.Header == nil || att.Header.Number == nil || att.Header.Number.Sign() == 0 {
+		return nil, errUnknownBlock
+	}
+	extra, err := types.ExtractIstanbulExtra(att.Header)
+	if err != nil {
+		return nil, err
+	}
+	if len(extra.AggregatedSeal.Signature) == 0 {
+		return nil, errEmptyAggregatedSeal
+	}
+	valSet := validator.NewSet(att.Validators)
+	if err := checkAggregatedSeal(log.Root(), att.Header.Hash(), valSet, extra.AggregatedSeal); err != nil {
+		return nil, err
+	}
+	return att, nil
+}
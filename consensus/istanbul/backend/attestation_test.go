@@ -0,0 +1,68 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/rlp"
+	. "github.com/onsi/gomega"
+)
+
+func TestBlockAttestation(t *testing.T) {
+	g := NewGomegaWithT(t)
+	genesisCfg, nodeKeys := getGenesisAndKeys(1, true)
+	chain, engine, _ := newBlockChainWithKeys(false, common.Address{}, false, genesisCfg, nodeKeys[0])
+	defer stopEngine(engine)
+	defer chain.Stop()
+
+	// the genesis block is not sealed
+	_, err := engine.blockAttestation(chain.Genesis().Header())
+	g.Expect(err).Should(BeIdenticalTo(errUnknownBlock))
+
+	block, err := makeBlock(nodeKeys, chain, engine, chain.Genesis())
+	g.Expect(err).ToNot(HaveOccurred())
+	att, err := engine.blockAttestation(block.Header())
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(att.Validators).Should(HaveLen(1))
+
+	// should verify
+	blob, err := rlp.EncodeToBytes(att)
+	g.Expect(err).ToNot(HaveOccurred())
+	verified, err := VerifyBlockAttestation(blob)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(verified.Header.Hash()).Should(Equal(block.Hash()))
+
+	// change header content and expect to invalidate signature
+	att.Header.GasUsed = 1
+	blob, err = rlp.EncodeToBytes(att)
+	g.Expect(err).ToNot(HaveOccurred())
+	_, err = VerifyBlockAttestation(blob)
+	g.Expect(err).Should(BeIdenticalTo(errInvalidSignature))
+
+	// claim a larger validator set and expect to fail the quorum check
+	att.Header = block.Header()
+	att.Validators = append(att.Validators, att.Validators[0], att.Validators[0])
+	att.Validators[1].Address = common.BigToAddress(big.NewInt(1))
+	att.Validators[2].Address = common.BigToAddress(big.NewInt(2))
+	blob, err = rlp.EncodeToBytes(att)
+	g.Expect(err).ToNot(HaveOccurred())
+	_, err = VerifyBlockAttestation(blob)
+	g.Expect(err).Should(BeIdenticalTo(errInsufficientSeals))
+}
@@ -43,9 +43,11 @@ import (
 	"github.com/celo-org/celo-blockchain/core"
 	"github.com/celo-org/celo-blockchain/core/state"
 	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/crypto"
 	blscrypto "github.com/celo-org/celo-blockchain/crypto/bls"
 	"github.com/celo-org/celo-blockchain/ethdb"
 	"github.com/celo-org/celo-blockchain/event"
+	"github.com/celo-org/celo-blockchain/internal/lifecycle"
 	"github.com/celo-org/celo-blockchain/log"
 	"github.com/celo-org/celo-blockchain/metrics"
 	"github.com/celo-org/celo-blockchain/p2p/enode"
@@ -113,6 +115,8 @@ func New(config *istanbul.Config, db ethdb.Database) consensus.Istanbul {
 		logger.Crit("Failed to create recent snapshots cache", "err", err)
 	}
 
+	knownMessages := NewKnownMessages(config.DedupCacheSize)
+
 	backend := &Backend{
 		config:                             config,
 		istanbulEventMux:                   new(event.TypeMux),
@@ -121,8 +125,14 @@ func New(config *istanbul.Config, db ethdb.Database) consensus.Istanbul {
 		recentSnapshots:                    recentSnapshots,
 		coreStarted:                        false,
 		announceRunning:                    false,
-		gossipCache:                        NewLRUGossipCache(inmemoryPeers, inmemoryMessages),
+		gossipCache:                        NewLRUGossipCache(knownMessages),
+		blockChunks:                        newBlockChunks(),
+		consensusPayloads:                  newConsensusPayloads(knownMessages),
+		observers:                          newConsensusObservers(),
+		observed:                           new(observedMessages),
 		announceThreadWg:                   new(sync.WaitGroup),
+		chainLoops:                         lifecycle.NewManager("istanbul"),
+		chainLoopsQuit:                     make(chan struct{}),
 		generateAndGossipQueryEnodeCh:      make(chan struct{}, 1),
 		updateAnnounceVersionCh:            make(chan struct{}, 1),
 		updatingCachedValidatorConnSetCond: sync.NewCond(&sync.Mutex{}),
@@ -221,6 +231,15 @@ type Backend struct {
 	validateState       func(block *types.Block, statedb *state.StateDB, receipts types.Receipts, usedGas uint64) error
 	onNewConsensusBlock func(block *types.Block, receipts []*types.Receipt, logs []*types.Log, state *state.StateDB)
 
+	// Domains the messages are signed in, set along with the chain
+	signingDomains *istanbul.SigningDomainSchedule
+
+	// Feed of the blocks reaching commit quorum, if optimistically announced
+	committedBlockFeed event.Feed
+
+	// Feed of the rounds started after a round change
+	newRoundFeed event.Feed
+
 	coreStarted bool
 	coreMu      sync.RWMutex
 
@@ -235,6 +254,19 @@ type Backend struct {
 
 	gossipCache GossipCache
 
+	// Chunked consensus messages being rebuilt
+	blockChunks *blockChunks
+
+	// Consensus messages announced to and by the peers
+	consensusPayloads *consensusPayloads
+
+	// Peers observing the consensus messages, and the ones observed by this node
+	observers *consensusObservers
+	observed  *observedMessages
+
+	// Stats of the epoch being imported
+	epochStats epochStatsRecorder
+
 	valEnodeTable *enodes.ValidatorEnodeDB
 
 	announceManager *AnnounceManager
@@ -249,6 +281,10 @@ type Backend struct {
 
 	updateAnnounceVersionCh chan struct{}
 
+	// Loops following the chain events, started by SetChain and stopped by Close
+	chainLoops     *lifecycle.Manager
+	chainLoopsQuit chan struct{}
+
 	delegateSignFeed  event.Feed
 	delegateSignScope event.SubscriptionScope
 
@@ -355,6 +391,61 @@ func (sb *Backend) IsValidating() bool {
 	return sb.coreStarted
 }
 
+// ProposedBlocks returns the number of blocks seen proposed by this validator
+// while elected.
+func (sb *Backend) ProposedBlocks() int64 {
+	return sb.blocksElectedAndProposedMeter.Count()
+}
+
+// MissedRounds returns the cumulative number of round changes seen to get
+// blocks agreed.
+func (sb *Backend) MissedRounds() int64 {
+	return sb.blocksTotalMissedRoundsMeter.Count()
+}
+
+// PeeredProxies returns the number of proxies of the proxied validator which
+// are peered with it.
+func (sb *Backend) PeeredProxies() int {
+	engine := sb.GetProxiedValidatorEngine()
+	if !sb.IsProxiedValidator() || engine == nil {
+		return 0
+	}
+	proxies, _, err := engine.GetProxiesAndValAssignments()
+	if err != nil {
+		return 0
+	}
+	peered := 0
+	for _, p := range proxies {
+		if p.IsPeered() {
+			peered++
+		}
+	}
+	return peered
+}
+
+// CurrentRoundStateSummary retrieves a summary of the current IBFT RoundState
+func (sb *Backend) CurrentRoundStateSummary() (*istanbulCore.RoundStateSummary, error) {
+	sb.coreMu.RLock()
+	defer sb.coreMu.RUnlock()
+
+	if !sb.coreStarted {
+		return nil, istanbul.ErrStoppedEngine
+	}
+	return sb.core.CurrentRoundState().Summary(), nil
+}
+
+// EpochSignatureStatus retrieves the status of the signatures of the epoch validator set data
+// of the latest epochs
+func (sb *Backend) EpochSignatureStatus() ([]*istanbulCore.EpochSignatureStatus, error) {
+	sb.coreMu.RLock()
+	defer sb.coreMu.RUnlock()
+
+	if !sb.coreStarted {
+		return nil, istanbul.ErrStoppedEngine
+	}
+	return sb.core.EpochSignatureStatus()
+}
+
 // IsValidator return if instance is a validator (either proxied or standalone)
 func (sb *Backend) IsValidator() bool {
 	return sb.config.Validator
@@ -420,8 +511,23 @@ func (sb *Backend) SelfNode() *enode.Node {
 	return sb.p2pserver.Self()
 }
 
+// nodeKeyIsValidatorKey returns whether the node key is the signing key of the
+// validator this node serves, in which case its node ID reveals the validator.
+func (sb *Backend) nodeKeyIsValidatorKey() bool {
+	if sb.p2pserver == nil {
+		return false
+	}
+	self := sb.SelfNode()
+	if self == nil || self.Pubkey() == nil {
+		return false
+	}
+	return crypto.PubkeyToAddress(*self.Pubkey()) == sb.ValidatorAddress()
+}
+
 // Close the backend
 func (sb *Backend) Close() error {
+	close(sb.chainLoopsQuit)
+	sb.chainLoops.Wait()
 	sb.delegateSignScope.Close()
 	var errs []error
 	if err := sb.valEnodeTable.Close(); err != nil {
@@ -536,11 +642,36 @@ func (sb *Backend) Commit(proposal istanbul.Proposal, aggregatedSeal types.Istan
 			return err
 		}
 	}
+	if sb.config.OptimisticAnnounce {
+		sb.committedBlockFeed.Send(core.NewCommittedBlockEvent{Block: block})
+	}
 	go sb.onNewConsensusBlock(block, result.Receipts, result.Logs, result.State)
 
 	return nil
 }
 
+// SubscribeCommittedBlockEvent registers a subscription of the blocks reaching
+// commit quorum, which are only sent if OptimisticAnnounce is enabled.
+func (sb *Backend) SubscribeCommittedBlockEvent(ch chan<- core.NewCommittedBlockEvent) event.Subscription {
+	return sb.committedBlockFeed.Subscribe(ch)
+}
+
+// NewRound implements istanbul.Backend.NewRound
+func (sb *Backend) NewRound(view *istanbul.View, proposer common.Address) {
+	// Sent asynchronously, as the subscribers may be waiting on the core
+	go sb.newRoundFeed.Send(istanbul.NewRoundEvent{
+		Sequence: new(big.Int).Set(view.Sequence),
+		Round:    new(big.Int).Set(view.Round),
+		Proposer: proposer,
+	})
+}
+
+// SubscribeNewRoundEvent registers a subscription of the rounds started after
+// a round change, with their proposer.
+func (sb *Backend) SubscribeNewRoundEvent(ch chan<- istanbul.NewRoundEvent) event.Subscription {
+	return sb.newRoundFeed.Subscribe(ch)
+}
+
 // EventMux implements istanbul.Backend.EventMux
 func (sb *Backend) EventMux() *event.TypeMux {
 	return sb.istanbulEventMux
@@ -597,6 +728,14 @@ func (sb *Backend) Verify(proposal istanbul.Proposal) (*istanbulCore.StateProces
 		return nil, 0, err
 	}
 
+	// Warm the state accessed by the transactions while applying them
+	if bc, ok := sb.chain.(*core.BlockChain); ok {
+		if parent := bc.GetHeader(block.ParentHash(), block.NumberU64()-1); parent != nil {
+			stopWarming := bc.WarmState(block, parent.Root)
+			defer stopWarming()
+		}
+	}
+
 	// Apply this block's transactions to update the state
 	receipts, logs, usedGas, err := sb.processBlock(block, state)
 	if err != nil {
@@ -993,6 +1132,16 @@ func (sb *Backend) RemoveProxy(node *enode.Node) error {
 	}
 }
 
+// SigningDomains returns the domains Istanbul messages are signed and verified
+// in for the current pending block (the next block right after the head block).
+func (sb *Backend) SigningDomains() istanbul.SigningDomains {
+	if sb.signingDomains == nil {
+		return istanbul.LegacySigningDomains
+	}
+	number := new(big.Int).Add(sb.currentBlock().Number(), common.Big1)
+	return sb.signingDomains.At(number)
+}
+
 // VerifyPendingBlockValidatorSignature will verify that the message sender is a validator that is responsible
 // for the current pending block (the next block right after the head block).
 func (sb *Backend) VerifyPendingBlockValidatorSignature(data []byte, sig []byte) (common.Address, error) {
@@ -23,10 +23,12 @@ import (
 	"time"
 
 	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/consensus/consensustest"
 	"github.com/celo-org/celo-blockchain/consensus/istanbul"
 	"github.com/celo-org/celo-blockchain/core"
 	"github.com/celo-org/celo-blockchain/core/types"
 	"github.com/celo-org/celo-blockchain/crypto"
+	"github.com/celo-org/celo-blockchain/internal/lifecycle"
 )
 
 func TestSign(t *testing.T) {
@@ -141,6 +143,43 @@ func TestNormalCommit(t *testing.T) {
 
 }
 
+func TestOptimisticAnnounceCommit(t *testing.T) {
+	chain, backend := newBlockChain(1, true)
+	defer chain.Stop()
+	backend.config.OptimisticAnnounce = true
+	block := makeBlockWithoutSeal(chain, backend, chain.Genesis())
+	expBlock, _ := backend.signBlock(block)
+	expectedSignature := make([]byte, types.IstanbulExtraBlsSignature)
+
+	committedCh := make(chan core.NewCommittedBlockEvent, 10)
+	committedSub := backend.SubscribeCommittedBlockEvent(committedCh)
+	defer committedSub.Unsubscribe()
+	newHeadCh := make(chan core.ChainHeadEvent, 10)
+	headSub := chain.SubscribeChainHeadEvent(newHeadCh)
+	defer headSub.Unsubscribe()
+
+	if err := backend.Commit(expBlock, types.IstanbulAggregatedSeal{Round: big.NewInt(0), Bitmap: big.NewInt(0), Signature: expectedSignature}, types.IstanbulEpochValidatorSetSeal{Bitmap: big.NewInt(0), Signature: nil}, nil); err != nil {
+		t.Fatalf("error mismatch: have %v, want %v", err, nil)
+	}
+
+	// The committed block is announced before being imported
+	var committed *types.Block
+	select {
+	case ev := <-committedCh:
+		committed = ev.Block
+	default:
+		t.Fatal("committed block not announced")
+	}
+	select {
+	case result := <-newHeadCh:
+		if result.Block.Hash() != committed.Hash() {
+			t.Errorf("hash mismatch: have %v, want %v", committed.Hash(), result.Block.Hash())
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("timeout")
+	}
+}
+
 func TestInvalidCommit(t *testing.T) {
 
 	chain, backend := newBlockChain(1, true)
@@ -172,3 +211,29 @@ func TestGetProposer(t *testing.T) {
 	}
 
 }
+
+func TestNodeKeyIsValidatorKey(t *testing.T) {
+	b := newBackend()
+	if !b.nodeKeyIsValidatorKey() {
+		t.Errorf("node key sharing the validator key not detected")
+	}
+	nodeKey, _ := crypto.GenerateKey()
+	b.SetP2PServer(consensustest.NewMockP2PServer(&nodeKey.PublicKey))
+	if b.nodeKeyIsValidatorKey() {
+		t.Errorf("separate node key reported as the validator key")
+	}
+}
+
+func TestCloseLeaks(t *testing.T) {
+	before := lifecycle.Running()
+	chain, engine := newBlockChain(1, true)
+	engine.StopValidating()
+	engine.StopAnnouncing()
+	chain.Stop()
+	if err := engine.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if leaked := lifecycle.Leaked(before); len(leaked) != 0 {
+		t.Errorf("goroutines leaked after closing the backend: %v", leaked)
+	}
+}
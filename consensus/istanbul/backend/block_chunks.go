@@ -0,0 +1,241 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/consensus/istanbul"
+	"github.com/celo-org/celo-blockchain/consensus/istanbul/backend/internal/erasure"
+	"github.com/celo-org/celo-blockchain/crypto"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// Consensus messages larger than the configured ChunkThreshold, in practice the
+// preprepares carrying the proposed blocks, are erasure coded into one shard per
+// destination validator instead of being sent whole to each of them. Every
+// validator relays the shard it was sent to the others, and rebuilds the message
+// once it holds enough shards, so the upstream bandwidth of the author is spread
+// among all the validators.
+
+const (
+	// minChunkRecipients is the minimum number of validators, other than the
+	// author, a consensus message is sent to for it to be chunked.
+	minChunkRecipients = 4
+
+	// maxChunkedPayloadSize is the maximum size of a chunked consensus message.
+	maxChunkedPayloadSize = 4 * 1024 * 1024
+
+	// inmemoryChunkedPayloads is the number of chunked consensus messages being
+	// rebuilt at once.
+	inmemoryChunkedPayloads = 16
+)
+
+var (
+	// errInvalidBlockChunk is returned when a block chunk is malformed.
+	errInvalidBlockChunk = errors.New("invalid block chunk")
+)
+
+// chunkDataShards returns the number of data shards of a consensus message sent
+// to the given number of validators, such that the shards of the honest ones are
+// enough to rebuild it.
+func chunkDataShards(recipients int) int {
+	// The author is part of the validator set, but not of the recipients
+	faulty := recipients / 3
+	return recipients - 2*faulty
+}
+
+// shouldChunk returns whether the consensus message should be sent as chunks to
+// the destination validators.
+func (sb *Backend) shouldChunk(destAddresses []common.Address, payload []byte, ethMsgCode uint64) bool {
+	if ethMsgCode != istanbul.ConsensusMsg || sb.config.ChunkThreshold == 0 {
+		return false
+	}
+	if uint64(len(payload)) < sb.config.ChunkThreshold || len(payload) > maxChunkedPayloadSize {
+		return false
+	}
+	return len(destAddresses) > minChunkRecipients
+}
+
+// multicastChunks erasure codes the consensus message and sends each of the
+// shards to a single destination validator, to be relayed to the others.
+func (sb *Backend) multicastChunks(destAddresses []common.Address, payload []byte) error {
+	recipients := make([]common.Address, 0, len(destAddresses))
+	for _, addr := range destAddresses {
+		if addr != sb.Address() {
+			recipients = append(recipients, addr)
+		}
+	}
+	dataShards := chunkDataShards(len(recipients))
+	shards, err := erasure.Encode(payload, dataShards, len(recipients)-dataShards)
+	if err != nil {
+		return err
+	}
+	hash := crypto.Keccak256Hash(payload)
+	for i, recipient := range recipients {
+		msg := istanbul.NewBlockChunkMessage(&istanbul.BlockChunk{
+			Hash:        hash,
+			Size:        uint64(len(payload)),
+			DataShards:  uint64(dataShards),
+			TotalShards: uint64(len(shards)),
+			Index:       uint64(i),
+			Recipient:   recipient,
+			Shard:       shards[i],
+		}, sb.Address())
+		if err := msg.SignInDomain(sb.SigningDomains().Sign, sb.Sign); err != nil {
+			return err
+		}
+		chunkPayload, err := msg.Payload()
+		if err != nil {
+			return err
+		}
+		if err := sb.Multicast([]common.Address{recipient}, chunkPayload, istanbul.BlockChunkMsg, false); err != nil {
+			return err
+		}
+	}
+	sb.logger.Trace("Sent consensus message as chunks", "hash", hash, "size", len(payload), "dataShards", dataShards, "totalShards", len(shards))
+	return nil
+}
+
+// handleBlockChunkMsg relays the chunks sent to this validator by their author to
+// the other validators, and handles the consensus messages once rebuilt.
+func (sb *Backend) handleBlockChunkMsg(payload []byte) {
+	logger := sb.logger.New("func", "handleBlockChunkMsg")
+
+	msg := new(istanbul.Message)
+	if err := msg.FromPayloadInDomains(payload, sb.SigningDomains().Verify, sb.VerifyPendingBlockValidatorSignature); err != nil {
+		logger.Debug("Failed to decode block chunk", "err", err)
+		return
+	}
+	chunk := msg.BlockChunk()
+	if err := validateBlockChunk(chunk); err != nil {
+		logger.Debug("Discarding block chunk", "from", msg.Address, "err", err)
+		return
+	}
+	if msg.Address == sb.Address() {
+		return
+	}
+	isNew, rebuilt := sb.blockChunks.add(msg.Address, chunk)
+	if isNew && chunk.Recipient == sb.Address() {
+		block := sb.currentBlock()
+		valSet := sb.getValidators(block.Number().Uint64(), block.Hash())
+
+		destAddresses := make([]common.Address, 0, valSet.Size())
+		for _, addr := range istanbul.MapValidatorsToAddresses(valSet.List()) {
+			if addr != sb.Address() && addr != msg.Address {
+				destAddresses = append(destAddresses, addr)
+			}
+		}
+		if err := sb.Multicast(destAddresses, payload, istanbul.BlockChunkMsg, false); err != nil {
+			logger.Warn("Error in relaying block chunk", "err", err)
+		}
+	}
+	if rebuilt != nil {
+		logger.Trace("Rebuilt chunked consensus message", "from", msg.Address, "hash", chunk.Hash)
+		if err := sb.istanbulEventMux.Post(istanbul.MessageEvent{Payload: rebuilt}); err != nil {
+			logger.Warn("Error in posting rebuilt consensus message", "err", err)
+		}
+	}
+}
+
+// validateBlockChunk checks the chunk is consistent with the shards it belongs to.
+func validateBlockChunk(chunk *istanbul.BlockChunk) error {
+	if chunk.TotalShards == 0 || chunk.TotalShards > erasure.MaxShards {
+		return errInvalidBlockChunk
+	}
+	if chunk.DataShards == 0 || chunk.DataShards > chunk.TotalShards || chunk.Index >= chunk.TotalShards {
+		return errInvalidBlockChunk
+	}
+	if chunk.Size > maxChunkedPayloadSize {
+		return errInvalidBlockChunk
+	}
+	shardSize := (chunk.Size + chunk.DataShards - 1) / chunk.DataShards
+	if shardSize == 0 {
+		shardSize = 1
+	}
+	if uint64(len(chunk.Shard)) != shardSize {
+		return errInvalidBlockChunk
+	}
+	return nil
+}
+
+// chunkAssembly holds the shards of a chunked consensus message received so far.
+type chunkAssembly struct {
+	author common.Address
+	chunk  *istanbul.BlockChunk // First chunk received, which the others must match
+	seen   []bool               // Shards received, kept once the message is rebuilt
+	shards [][]byte
+	count  int
+	done   bool
+}
+
+// blockChunks rebuilds the chunked consensus messages.
+type blockChunks struct {
+	mu         sync.Mutex
+	assemblies *lru.Cache // Chunk assemblies by consensus message hash
+}
+
+func newBlockChunks() *blockChunks {
+	assemblies, _ := lru.New(inmemoryChunkedPayloads)
+	return &blockChunks{assemblies: assemblies}
+}
+
+// add stores the chunk of a consensus message of the given author, returning
+// whether it is new, and the consensus message if it was rebuilt with it.
+func (c *blockChunks) add(author common.Address, chunk *istanbul.BlockChunk) (bool, []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var assembly *chunkAssembly
+	if cached, ok := c.assemblies.Get(chunk.Hash); ok {
+		assembly = cached.(*chunkAssembly)
+	} else {
+		assembly = &chunkAssembly{
+			author: author,
+			chunk:  chunk,
+			seen:   make([]bool, chunk.TotalShards),
+			shards: make([][]byte, chunk.TotalShards),
+		}
+		c.assemblies.Add(chunk.Hash, assembly)
+	}
+	first := assembly.chunk
+	if assembly.author != author || first.Size != chunk.Size || first.DataShards != chunk.DataShards || first.TotalShards != chunk.TotalShards {
+		return false, nil
+	}
+	if assembly.seen[chunk.Index] {
+		return false, nil
+	}
+	assembly.seen[chunk.Index] = true
+	if assembly.done {
+		return true, nil
+	}
+	assembly.shards[chunk.Index] = chunk.Shard
+	assembly.count++
+	if uint64(assembly.count) < chunk.DataShards {
+		return true, nil
+	}
+	shards := assembly.shards
+	assembly.done, assembly.shards = true, nil
+
+	payload, err := erasure.Decode(shards, int(chunk.DataShards), int(chunk.Size))
+	if err != nil || crypto.Keccak256Hash(payload) != chunk.Hash {
+		return true, nil
+	}
+	return true, payload
+}
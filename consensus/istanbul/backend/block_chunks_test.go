@@ -0,0 +1,126 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/consensus/istanbul"
+	"github.com/celo-org/celo-blockchain/consensus/istanbul/backend/internal/erasure"
+	"github.com/celo-org/celo-blockchain/crypto"
+)
+
+func makeBlockChunks(t *testing.T, payload []byte, recipients int) []*istanbul.BlockChunk {
+	dataShards := chunkDataShards(recipients)
+	shards, err := erasure.Encode(payload, dataShards, recipients-dataShards)
+	if err != nil {
+		t.Fatalf("failed to encode payload: %v", err)
+	}
+	chunks := make([]*istanbul.BlockChunk, len(shards))
+	for i, shard := range shards {
+		chunks[i] = &istanbul.BlockChunk{
+			Hash:        crypto.Keccak256Hash(payload),
+			Size:        uint64(len(payload)),
+			DataShards:  uint64(dataShards),
+			TotalShards: uint64(len(shards)),
+			Index:       uint64(i),
+			Recipient:   common.BigToAddress(common.Big1),
+			Shard:       shard,
+		}
+	}
+	return chunks
+}
+
+func TestChunkDataShards(t *testing.T) {
+	for recipients, want := range map[int]int{1: 1, 3: 1, 4: 2, 9: 3, 99: 33, 100: 34} {
+		if have := chunkDataShards(recipients); have != want {
+			t.Errorf("data shards mismatch for %d recipients: have %d, want %d", recipients, have, want)
+		}
+	}
+}
+
+func TestBlockChunkMessage(t *testing.T) {
+	payload := make([]byte, 1000)
+	rand.Read(payload)
+	chunk := makeBlockChunks(t, payload, 10)[3]
+
+	key, _ := crypto.GenerateKey()
+	author := crypto.PubkeyToAddress(key.PublicKey)
+	msg := istanbul.NewBlockChunkMessage(chunk, author)
+	if err := msg.Sign(func(data []byte) ([]byte, error) { return crypto.Sign(crypto.Keccak256(data), key) }); err != nil {
+		t.Fatalf("failed to sign message: %v", err)
+	}
+	encoded, err := msg.Payload()
+	if err != nil {
+		t.Fatalf("failed to encode message: %v", err)
+	}
+	decoded := new(istanbul.Message)
+	if err := decoded.FromPayload(encoded, istanbul.GetSignatureAddress); err != nil {
+		t.Fatalf("failed to decode message: %v", err)
+	}
+	if decoded.Address != author || decoded.BlockChunk() == nil {
+		t.Fatalf("decoded message mismatch: %v", decoded)
+	}
+	if have := decoded.BlockChunk(); have.Hash != chunk.Hash || have.Index != chunk.Index || !bytes.Equal(have.Shard, chunk.Shard) {
+		t.Errorf("decoded chunk mismatch: have %+v, want %+v", have, chunk)
+	}
+	if err := validateBlockChunk(decoded.BlockChunk()); err != nil {
+		t.Errorf("failed to validate chunk: %v", err)
+	}
+	invalid := *chunk
+	invalid.Shard = invalid.Shard[1:]
+	if err := validateBlockChunk(&invalid); err != errInvalidBlockChunk {
+		t.Errorf("error mismatch: have %v, want %v", err, errInvalidBlockChunk)
+	}
+}
+
+func TestBlockChunksAssembly(t *testing.T) {
+	payload := make([]byte, 5000)
+	rand.Read(payload)
+	chunks := makeBlockChunks(t, payload, 9)
+
+	var (
+		c      = newBlockChunks()
+		author = common.BigToAddress(common.Big2)
+		other  = common.BigToAddress(common.Big3)
+	)
+	// Chunks of other authors for the same message are ignored
+	if isNew, _ := c.add(author, chunks[8]); !isNew {
+		t.Fatalf("first chunk not new")
+	}
+	if isNew, _ := c.add(other, chunks[7]); isNew {
+		t.Fatalf("chunk of another author accepted")
+	}
+	if isNew, _ := c.add(author, chunks[8]); isNew {
+		t.Fatalf("duplicate chunk accepted")
+	}
+	// The message is rebuilt once enough chunks are received, from parity ones
+	if _, rebuilt := c.add(author, chunks[6]); rebuilt != nil {
+		t.Fatalf("message rebuilt from too few chunks")
+	}
+	isNew, rebuilt := c.add(author, chunks[0])
+	if !isNew || !bytes.Equal(rebuilt, payload) {
+		t.Fatalf("message not rebuilt")
+	}
+	// Later chunks are still new, for them to be relayed, but don't rebuild again
+	if isNew, rebuilt := c.add(author, chunks[1]); !isNew || rebuilt != nil {
+		t.Errorf("late chunk mismatch: new %v, rebuilt %v", isNew, rebuilt != nil)
+	}
+}
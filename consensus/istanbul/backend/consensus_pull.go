@@ -0,0 +1,209 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"sync"
+	"time"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/consensus"
+	"github.com/celo-org/celo-blockchain/consensus/istanbul"
+	"github.com/celo-org/celo-blockchain/crypto"
+	"github.com/celo-org/celo-blockchain/metrics"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// Consensus messages larger than the configured AnnouncePullThreshold, in
+// practice the preprepares carrying the proposed blocks, are not pushed whole to
+// the peers. Only their hash is announced, and each peer pulls the message from
+// the first peer announcing it, so that it is received once however many peers
+// it is forwarded by.
+
+const (
+	// inmemoryAnnouncedPayloads is the number of announced consensus messages kept
+	// for the peers to pull.
+	inmemoryAnnouncedPayloads = 32
+
+	// inmemoryPendingPulls is the number of consensus messages being pulled at once.
+	inmemoryPendingPulls = 64
+
+	// consensusPullTimeout is the time after which a consensus message is pulled
+	// from the next peer which announced it.
+	consensusPullTimeout = 1 * time.Second
+)
+
+var (
+	consensusAnnouncedMeter = metrics.NewRegisteredMeter("consensus/istanbul/backend/announcepull/announced", nil)
+	consensusPulledMeter    = metrics.NewRegisteredMeter("consensus/istanbul/backend/announcepull/pulled", nil)
+	consensusSkippedMeter   = metrics.NewRegisteredMeter("consensus/istanbul/backend/announcepull/skipped", nil)
+)
+
+// shouldAnnounce returns whether the consensus message should be announced to
+// the peers instead of being sent whole.
+func (sb *Backend) shouldAnnounce(payload []byte, ethMsgCode uint64) bool {
+	threshold := sb.config.AnnouncePullThreshold
+	return ethMsgCode == istanbul.ConsensusMsg && threshold > 0 && uint64(len(payload)) >= threshold
+}
+
+// handleConsensusAnnounceMsg pulls the announced consensus message from the peer,
+// unless it was received already or is being pulled from another peer.
+func (sb *Backend) handleConsensusAnnounceMsg(peer consensus.Peer, payload []byte) {
+	if len(payload) != common.HashLength {
+		sb.logger.Debug("Discarding invalid consensus announcement", "peer", peer)
+		return
+	}
+	hash := common.BytesToHash(payload)
+	if !sb.consensusPayloads.pullFrom(hash, peer) {
+		consensusSkippedMeter.Mark(1)
+		return
+	}
+	sb.pullConsensusMsg(hash, peer)
+}
+
+// pullConsensusMsg requests the consensus message from the peer, and from the
+// next peer which announced it if not received in time.
+func (sb *Backend) pullConsensusMsg(hash common.Hash, peer consensus.Peer) {
+	sb.logger.Trace("Pulling consensus message", "hash", hash, "peer", peer)
+	consensusPulledMeter.Mark(1)
+	if err := peer.Send(istanbul.ConsensusPullMsg, hash.Bytes()); err != nil {
+		sb.logger.Debug("Error in pulling consensus message", "hash", hash, "peer", peer, "err", err)
+	}
+	time.AfterFunc(consensusPullTimeout, func() {
+		if next := sb.consensusPayloads.nextPull(hash); next != nil {
+			sb.pullConsensusMsg(hash, next)
+		}
+	})
+}
+
+// handleConsensusPullMsg sends the consensus message this node announced to the
+// peer pulling it.
+func (sb *Backend) handleConsensusPullMsg(peer consensus.Peer, payload []byte) {
+	if len(payload) != common.HashLength {
+		sb.logger.Debug("Discarding invalid consensus pull request", "peer", peer)
+		return
+	}
+	hash := common.BytesToHash(payload)
+	msg := sb.consensusPayloads.announced(hash)
+	if msg == nil {
+		sb.logger.Debug("Pulled consensus message not announced", "hash", hash, "peer", peer)
+		return
+	}
+	// Sent directly, as announcing it again would loop
+	if err := peer.Send(istanbul.ConsensusMsg, msg); err != nil {
+		sb.logger.Debug("Error in sending pulled consensus message", "hash", hash, "peer", peer, "err", err)
+	}
+}
+
+// pendingPull is a consensus message being pulled, with the peers which
+// announced it and weren't pulled from yet.
+type pendingPull struct {
+	announcers []consensus.Peer
+}
+
+// consensusPayloads tracks the consensus messages announced to and by the peers.
+type consensusPayloads struct {
+	mu        sync.Mutex
+	announces *lru.Cache     // Payloads announced by this node, by hash
+	known     *KnownMessages // Consensus messages received or announced
+	pulls     *lru.Cache     // Pending pulls, by hash
+}
+
+func newConsensusPayloads(known *KnownMessages) *consensusPayloads {
+	announces, _ := lru.New(inmemoryAnnouncedPayloads)
+	pulls, _ := lru.New(inmemoryPendingPulls)
+	return &consensusPayloads{announces: announces, known: known, pulls: pulls}
+}
+
+// announce stores the payload for the peers to pull, and returns its hash.
+func (c *consensusPayloads) announce(payload []byte) common.Hash {
+	hash := crypto.Keccak256Hash(payload)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.announces.Add(hash, payload)
+	c.known.MarkBySelf(consensusMessageKind, hash)
+	consensusAnnouncedMeter.Mark(1)
+	return hash
+}
+
+// announced returns the payload with the given hash announced by this node.
+func (c *consensusPayloads) announced(hash common.Hash) []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if payload, ok := c.announces.Get(hash); ok {
+		return payload.([]byte)
+	}
+	return nil
+}
+
+// markKnown records the payload as received, to ignore its announcements, and
+// returns whether it was received for the first time.
+func (c *consensusPayloads) markKnown(payload []byte) bool {
+	hash := crypto.Keccak256Hash(payload)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fresh := !c.known.KnownBySelf(consensusMessageKind, hash)
+	c.known.MarkBySelf(consensusMessageKind, hash)
+	c.pulls.Remove(hash)
+	return fresh
+}
+
+// pullFrom records the announcement of the payload by the peer, and returns
+// whether it should be pulled from it right away.
+func (c *consensusPayloads) pullFrom(hash common.Hash, peer consensus.Peer) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.known.KnownBySelf(consensusMessageKind, hash) {
+		return false
+	}
+	if pending, ok := c.pulls.Get(hash); ok {
+		pull := pending.(*pendingPull)
+		pull.announcers = append(pull.announcers, peer)
+		return false
+	}
+	c.pulls.Add(hash, &pendingPull{})
+	return true
+}
+
+// nextPull returns the next peer to pull the payload from, if still missing.
+func (c *consensusPayloads) nextPull(hash common.Hash) consensus.Peer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.known.KnownBySelf(consensusMessageKind, hash) {
+		return nil
+	}
+	pending, ok := c.pulls.Get(hash)
+	if !ok {
+		return nil
+	}
+	pull := pending.(*pendingPull)
+	if len(pull.announcers) == 0 {
+		// Pulled again from the next peer announcing it
+		c.pulls.Remove(hash)
+		return nil
+	}
+	next := pull.announcers[0]
+	pull.announcers = pull.announcers[1:]
+	return next
+}
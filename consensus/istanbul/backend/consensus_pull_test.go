@@ -0,0 +1,119 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/celo-org/celo-blockchain/consensus"
+	"github.com/celo-org/celo-blockchain/consensus/istanbul"
+	"github.com/celo-org/celo-blockchain/crypto"
+	"github.com/celo-org/celo-blockchain/p2p/enode"
+)
+
+func newRecordingPeer() *recordingPeer {
+	key, _ := crypto.GenerateKey()
+	return &recordingPeer{
+		node: enode.NewV4(&key.PublicKey, net.IP{127, 0, 0, 1}, 30303, 30303),
+		sent: make(map[uint64][][]byte),
+	}
+}
+
+func waitForMessages(peer *recordingPeer, msgCode uint64, count int) [][]byte {
+	deadline := time.Now().Add(5 * time.Second)
+	for len(peer.messages(msgCode)) < count && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	return peer.messages(msgCode)
+}
+
+func TestConsensusPayloads(t *testing.T) {
+	var (
+		c       = newConsensusPayloads(NewKnownMessages(0))
+		payload = []byte("preprepare")
+		hash    = crypto.Keccak256Hash(payload)
+		first   = newRecordingPeer()
+		second  = newRecordingPeer()
+	)
+	// Only the first announcement is pulled right away, the others are queued
+	if !c.pullFrom(hash, first) {
+		t.Fatalf("first announcement not pulled")
+	}
+	if c.pullFrom(hash, second) {
+		t.Fatalf("second announcement pulled while pending")
+	}
+	if next := c.nextPull(hash); next != consensus.Peer(second) {
+		t.Fatalf("next pull mismatch: have %v, want %v", next, second)
+	}
+	if next := c.nextPull(hash); next != nil {
+		t.Fatalf("next pull mismatch: have %v, want none", next)
+	}
+	// Received payloads aren't pulled anymore
+	if !c.pullFrom(hash, first) {
+		t.Fatalf("announcement not pulled after exhausting the announcers")
+	}
+	c.markKnown(payload)
+	if c.pullFrom(hash, second) || c.nextPull(hash) != nil {
+		t.Errorf("known payload pulled")
+	}
+	// Only announced payloads can be pulled
+	if c.announced(hash) != nil {
+		t.Errorf("payload announced by another node available")
+	}
+	if c.announce(payload) != hash || !bytes.Equal(c.announced(hash), payload) {
+		t.Errorf("announced payload unavailable")
+	}
+}
+
+func TestConsensusAnnouncePull(t *testing.T) {
+	backend := newBackend()
+	backend.config.AnnouncePullThreshold = 100
+
+	var (
+		small = bytes.Repeat([]byte{1}, 99)
+		large = bytes.Repeat([]byte{2}, 100)
+		peer  = newRecordingPeer()
+	)
+	backend.Unicast(peer, small, istanbul.ConsensusMsg)
+	if sent := waitForMessages(peer, istanbul.ConsensusMsg, 1); len(sent) != 1 || !bytes.Equal(sent[0], small) {
+		t.Fatalf("small consensus message not sent whole")
+	}
+	backend.Unicast(peer, large, istanbul.ConsensusMsg)
+	announces := waitForMessages(peer, istanbul.ConsensusAnnounceMsg, 1)
+	if len(announces) != 1 || !bytes.Equal(announces[0], crypto.Keccak256(large)) {
+		t.Fatalf("large consensus message not announced")
+	}
+	// The peer pulls the announced message
+	backend.handleConsensusPullMsg(peer, announces[0])
+	if sent := peer.messages(istanbul.ConsensusMsg); len(sent) != 2 || !bytes.Equal(sent[1], large) {
+		t.Fatalf("pulled consensus message not sent")
+	}
+	// Announcements are pulled once, from the first peer
+	other := newRecordingPeer()
+	hash := crypto.Keccak256([]byte("preprepare"))
+	backend.handleConsensusAnnounceMsg(other, hash)
+	backend.handleConsensusAnnounceMsg(peer, hash)
+	if pulls := other.messages(istanbul.ConsensusPullMsg); len(pulls) != 1 || !bytes.Equal(pulls[0], hash) {
+		t.Fatalf("announced consensus message not pulled")
+	}
+	if pulls := peer.messages(istanbul.ConsensusPullMsg); len(pulls) != 0 {
+		t.Errorf("announced consensus message pulled twice")
+	}
+}
@@ -35,6 +35,7 @@ import (
 	ethCore "github.com/celo-org/celo-blockchain/core"
 	"github.com/celo-org/celo-blockchain/core/state"
 	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/core/vm"
 	blscrypto "github.com/celo-org/celo-blockchain/crypto/bls"
 	"github.com/celo-org/celo-blockchain/log"
 	"github.com/celo-org/celo-blockchain/rlp"
@@ -45,8 +46,6 @@ import (
 
 const (
 	inmemorySnapshots             = 128 // Number of recent vote snapshots to keep in memory
-	inmemoryPeers                 = 40
-	inmemoryMessages              = 1024
 	mobileAllowedClockSkew uint64 = 5
 )
 
@@ -316,7 +315,12 @@ func (sb *Backend) verifyAggregatedSeals(chain consensus.ChainHeaderReader, head
 }
 
 func (sb *Backend) verifyAggregatedSeal(headerHash common.Hash, validators istanbul.ValidatorSet, aggregatedSeal types.IstanbulAggregatedSeal) error {
-	logger := sb.logger.New("func", "Backend.verifyAggregatedSeal()")
+	return checkAggregatedSeal(sb.logger.New("func", "Backend.verifyAggregatedSeal()"), headerHash, validators, aggregatedSeal)
+}
+
+// checkAggregatedSeal verifies that the aggregated seal of the given header was
+// created by a quorum of the validator set.
+func checkAggregatedSeal(logger log.Logger, headerHash common.Hash, validators istanbul.ValidatorSet, aggregatedSeal types.IstanbulAggregatedSeal) error {
 	if len(aggregatedSeal.Signature) != types.IstanbulExtraBlsSignature {
 		return errInvalidAggregatedSeal
 	}
@@ -461,6 +465,12 @@ func (sb *Backend) LookbackWindow(header *types.Header, state *state.StateDB) ui
 // Note: The block header and state database might be updated to reflect any
 // consensus rules that happen at finalization (e.g. block rewards).
 func (sb *Backend) Finalize(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction) {
+	sb.finalize(chain, header, state, txs, nil)
+}
+
+// finalize implements Finalize, returning the epoch rewards distributed in the
+// last block of an epoch. See distributeEpochRewards for rateRunner.
+func (sb *Backend) finalize(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, rateRunner vm.EVMRunner) (*EpochRewards, error) {
 	start := time.Now()
 	defer sb.finalizationTimer.UpdateSince(start)
 
@@ -488,18 +498,23 @@ func (sb *Backend) Finalize(chain consensus.ChainHeaderReader, header *types.Hea
 		state.RevertToSnapshot(snapshot)
 	}
 
+	var (
+		rewards    *EpochRewards
+		rewardsErr error
+	)
 	lastBlockOfEpoch := istanbul.IsLastBlockOfEpoch(header.Number.Uint64(), sb.config.Epoch)
 	if lastBlockOfEpoch {
 		snapshot = state.Snapshot()
-		err = sb.distributeEpochRewards(header, state)
-		if err != nil {
-			sb.logger.Error("Failed to distribute epoch rewards", "blockNumber", header.Number, "err", err)
+		rewards, rewardsErr = sb.distributeEpochRewards(header, state, rateRunner)
+		if rewardsErr != nil {
+			sb.logger.Error("Failed to distribute epoch rewards", "blockNumber", header.Number, "err", rewardsErr)
 			state.RevertToSnapshot(snapshot)
 		}
 	}
 
 	header.Root = state.IntermediateRoot(chain.Config().IsEIP158(header.Number))
 	logger.Debug("Finalized", "duration", now().Sub(start), "lastInEpoch", lastBlockOfEpoch)
+	return rewards, rewardsErr
 }
 
 // FinalizeAndAssemble runs any post-transaction state modifications (e.g. block
@@ -586,6 +601,11 @@ func (sb *Backend) APIs(chain consensus.ChainHeaderReader) []rpc.API {
 		Version:   "1.0",
 		Service:   &API{chain: chain, istanbul: sb},
 		Public:    true,
+	}, {
+		Namespace: "celo",
+		Version:   "1.0",
+		Service:   &CeloAPI{chain: chain, istanbul: sb},
+		Public:    true,
 	}}
 }
 
@@ -593,10 +613,13 @@ func (sb *Backend) SetChain(chain consensus.ChainContext, currentBlock func() *t
 	sb.chain = chain
 	sb.currentBlock = currentBlock
 	sb.stateAt = stateAt
+	if genesis := chain.GetHeaderByNumber(0); genesis != nil {
+		sb.signingDomains = istanbul.NewSigningDomainSchedule(chain.Config(), genesis.Hash())
+	}
 
 	if bc, ok := chain.(*ethCore.BlockChain); ok {
-		go sb.newChainHeadLoop(bc)
-		go sb.updateReplicaStateLoop(bc)
+		sb.chainLoops.Go("newChainHeadLoop", func() { sb.newChainHeadLoop(bc) })
+		sb.chainLoops.Go("updateReplicaStateLoop", func() { sb.updateReplicaStateLoop(bc) })
 	}
 
 }
@@ -606,6 +629,10 @@ func (sb *Backend) newChainHeadLoop(bc *ethCore.BlockChain) {
 	// Batched. For stats & announce
 	chainHeadCh := make(chan ethCore.ChainHeadEvent, 10)
 	chainHeadSub := bc.SubscribeChainHeadEvent(chainHeadCh)
+	if chainHeadSub == nil {
+		// The chain was stopped before the loop started
+		return
+	}
 	defer chainHeadSub.Unsubscribe()
 
 	for {
@@ -615,6 +642,8 @@ func (sb *Backend) newChainHeadLoop(bc *ethCore.BlockChain) {
 		case err := <-chainHeadSub.Err():
 			log.Error("Error in istanbul's subscription to the blockchain's chainhead event", "err", err)
 			return
+		case <-sb.chainLoopsQuit:
+			return
 		}
 	}
 }
@@ -624,6 +653,9 @@ func (sb *Backend) updateReplicaStateLoop(bc *ethCore.BlockChain) {
 	// Unbatched event listener
 	chainEventCh := make(chan ethCore.ChainEvent, 10)
 	chainEventSub := bc.SubscribeChainEvent(chainEventCh)
+	if chainEventSub == nil {
+		return
+	}
 	defer chainEventSub.Unsubscribe()
 
 	for {
@@ -638,6 +670,8 @@ func (sb *Backend) updateReplicaStateLoop(bc *ethCore.BlockChain) {
 		case err := <-chainEventSub.Err():
 			log.Error("Error in istanbul's subscription to the blockchain's chain event", "err", err)
 			return
+		case <-sb.chainLoopsQuit:
+			return
 		}
 	}
 }
@@ -673,6 +707,9 @@ func (sb *Backend) StartValidating() error {
 	}
 
 	sb.logger.Info("Starting istanbul.Engine validating")
+	if sb.nodeKeyIsValidatorKey() {
+		sb.logger.Warn("The node key is the validator signing key, use a separate node key not to expose the validator by its node ID", "address", sb.ValidatorAddress())
+	}
 	if err := sb.core.Start(); err != nil {
 		return err
 	}
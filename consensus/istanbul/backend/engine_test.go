@@ -218,6 +218,7 @@ func TestVerifyHeaders(t *testing.T) {
 	}
 
 	// mock istanbul now() function
+	defer func(realNow func() time.Time) { now = realNow }(now)
 	now = func() time.Time {
 		return time.Unix(int64(headers[size-1].Time), 0)
 	}
@@ -0,0 +1,127 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/common/hexutil"
+	"github.com/celo-org/celo-blockchain/consensus"
+	"github.com/celo-org/celo-blockchain/consensus/istanbul"
+	"github.com/celo-org/celo-blockchain/core"
+	"github.com/celo-org/celo-blockchain/core/state"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/core/vm"
+)
+
+var errNoFullChain = errors.New("epoch rewards require a full blockchain")
+
+// EpochRewards is the breakdown of the rewards distributed in the last block of
+// an epoch. Validator payments are denominated in the stable token, everything
+// else in CELO.
+type EpochRewards struct {
+	Epoch       hexutil.Uint64 `json:"epoch"`
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	BlockHash   common.Hash    `json:"blockHash"`
+	Frozen      bool           `json:"frozen"` // Distribution was frozen, nothing was paid
+
+	TargetValidatorReward        *hexutil.Big          `json:"targetValidatorReward,omitempty"` // Maximum payment of a validator
+	ValidatorPayments            []*EpochRewardPayment `json:"validatorPayments,omitempty"`
+	TotalValidatorPayments       *hexutil.Big          `json:"totalValidatorPayments,omitempty"`
+	TotalValidatorPaymentsInCelo *hexutil.Big          `json:"totalValidatorPaymentsInCelo,omitempty"` // Minted to the reserve
+	StableToken                  common.Address        `json:"stableToken"`
+	ExchangeRate                 *EpochRewardsRate     `json:"exchangeRate,omitempty"` // Rate the validator payments were converted at
+
+	TargetVoterRewards *hexutil.Big `json:"targetVoterRewards,omitempty"`
+	VoterRewards       *hexutil.Big `json:"voterRewards,omitempty"` // Paid to the voters of the elected groups
+
+	CommunityFund    *EpochRewardPayment `json:"communityFund,omitempty"`
+	CarbonOffsetting *EpochRewardPayment `json:"carbonOffsetting,omitempty"`
+}
+
+// EpochRewardPayment is an amount paid to a single recipient.
+type EpochRewardPayment struct {
+	Recipient common.Address `json:"recipient"`
+	Amount    *hexutil.Big   `json:"amount"`
+}
+
+// EpochRewardsRate is an exchange rate, where Numerator stable token units are
+// worth Denominator CELO units.
+type EpochRewardsRate struct {
+	Numerator   *hexutil.Big `json:"numerator"`
+	Denominator *hexutil.Big `json:"denominator"`
+}
+
+// epochRewardsRecorder is a consensus engine that finalizes blocks like the
+// backend it wraps, retaining the epoch rewards distributed.
+type epochRewardsRecorder struct {
+	*Backend
+	rateRunner vm.EVMRunner
+
+	rewards *EpochRewards
+	err     error
+}
+
+func (r *epochRewardsRecorder) Finalize(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction) {
+	r.rewards, r.err = r.finalize(chain, header, state, txs, r.rateRunner)
+}
+
+// epochRewards reexecutes the last block of the given epoch on top of its
+// parent state and returns the rewards distributed in it. Stable token amounts
+// are converted at the rate of the parent block, which was the current block
+// when it was imported.
+func (sb *Backend) epochRewards(ctx context.Context, epoch uint64) (*EpochRewards, error) {
+	bc, ok := sb.chain.(*core.BlockChain)
+	if !ok {
+		return nil, errNoFullChain
+	}
+	if epoch == 0 {
+		return nil, errors.New("the genesis epoch has no rewards")
+	}
+	number := istanbul.GetEpochLastBlockNumber(epoch, sb.EpochSize())
+	block := bc.GetBlockByNumber(number)
+	if block == nil {
+		return nil, errUnknownBlock
+	}
+	parent := bc.GetBlock(block.ParentHash(), number-1)
+	if parent == nil {
+		return nil, errUnknownBlock
+	}
+	statedb, err := bc.StateAt(parent.Root())
+	if err != nil {
+		return nil, fmt.Errorf("state of block %d unavailable: %v", parent.NumberU64(), err)
+	}
+	recorder := &epochRewardsRecorder{
+		Backend:    sb,
+		rateRunner: bc.NewEVMRunner(parent.Header(), statedb.Copy()),
+	}
+	processor := core.NewStateProcessor(bc.Config(), bc, recorder)
+	if _, _, _, err := processor.Process(ctx, block, statedb, *bc.GetVMConfig()); err != nil {
+		return nil, err
+	}
+	if recorder.err != nil {
+		return nil, recorder.err
+	}
+	rewards := recorder.rewards
+	rewards.Epoch = hexutil.Uint64(epoch)
+	rewards.BlockNumber = hexutil.Uint64(number)
+	rewards.BlockHash = block.Hash()
+	return rewards, nil
+}
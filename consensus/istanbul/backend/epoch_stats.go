@@ -0,0 +1,200 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"sync"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/common/hexutil"
+	"github.com/celo-org/celo-blockchain/consensus/istanbul"
+	"github.com/celo-org/celo-blockchain/core"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/ethdb"
+)
+
+const (
+	dbKeyEpochStatsPrefix = "istanbul-epoch-stats"
+)
+
+// errNoEpochStats is returned when no block of the requested epoch was recorded.
+var errNoEpochStats = errors.New("no stats recorded for the epoch")
+
+// EpochStats aggregates the blocks of an epoch imported by the node. A block is
+// recorded once its child is imported, as the signers of a block are only final
+// then. Blocks imported before the node was upgraded, or replaced by a reorg,
+// are not accounted for.
+type EpochStats struct {
+	Epoch      hexutil.Uint64 `json:"epoch"`
+	FirstBlock hexutil.Uint64 `json:"firstBlock"` // First block recorded
+	LastBlock  hexutil.Uint64 `json:"lastBlock"`  // Last block recorded
+	Blocks     hexutil.Uint64 `json:"blocks"`     // Number of blocks recorded
+
+	// Activity of the local validator, if any
+	Validator common.Address `json:"validator"`
+	Elected   hexutil.Uint64 `json:"elected"`  // Blocks the validator was elected to sign
+	Proposed  hexutil.Uint64 `json:"proposed"` // Blocks proposed by the validator
+	Signed    hexutil.Uint64 `json:"signed"`   // Blocks signed by the validator, once elected
+	Missed    hexutil.Uint64 `json:"missed"`   // Blocks not signed by the validator, once elected
+
+	Rounds       hexutil.Uint64 `json:"rounds"`       // Sum of the rounds the blocks were agreed on
+	AverageRound float64        `json:"averageRound"` // Average round the blocks were agreed on
+
+	Transactions hexutil.Uint64                  `json:"transactions"`
+	Fees         map[common.Address]*hexutil.Big `json:"fees"` // Gas fees by fee currency, the zero address being CELO
+}
+
+// epochStatsKey returns the database key of the stats of the given epoch.
+func epochStatsKey(epoch uint64) []byte {
+	key := make([]byte, len(dbKeyEpochStatsPrefix)+8)
+	copy(key, dbKeyEpochStatsPrefix)
+	binary.BigEndian.PutUint64(key[len(dbKeyEpochStatsPrefix):], epoch)
+	return key
+}
+
+// loadEpochStats reads the stats of the given epoch from the database.
+func loadEpochStats(db ethdb.KeyValueReader, epoch uint64) (*EpochStats, error) {
+	blob, err := db.Get(epochStatsKey(epoch))
+	if err != nil {
+		return nil, errNoEpochStats
+	}
+	stats := new(EpochStats)
+	if err := json.Unmarshal(blob, stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// store writes the stats to the database.
+func (s *EpochStats) store(db ethdb.KeyValueWriter) error {
+	blob, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return db.Put(epochStatsKey(uint64(s.Epoch)), blob)
+}
+
+// addFee adds the fee paid in the given currency.
+func (s *EpochStats) addFee(currency common.Address, fee *big.Int) {
+	total, ok := s.Fees[currency]
+	if !ok {
+		total = new(hexutil.Big)
+		s.Fees[currency] = total
+	}
+	(*big.Int)(total).Add((*big.Int)(total), fee)
+}
+
+// epochStatsRecorder maintains the stats of the epoch of the latest block.
+type epochStatsRecorder struct {
+	mu    sync.Mutex
+	stats *EpochStats
+}
+
+// recordEpochStats adds the parent of the given block to the stats of its epoch.
+func (sb *Backend) recordEpochStats(child *types.Block) {
+	number := child.NumberU64()
+	if number <= 1 {
+		return
+	}
+	number--
+	parent := sb.chain.GetHeader(child.ParentHash(), number)
+	if parent == nil {
+		return
+	}
+	childExtra, err := types.ExtractIstanbulExtra(child.Header())
+	if err != nil {
+		return
+	}
+	epoch := istanbul.GetEpochNumber(number, sb.EpochSize())
+
+	sb.epochStats.mu.Lock()
+	defer sb.epochStats.mu.Unlock()
+
+	stats := sb.epochStats.stats
+	if stats == nil || uint64(stats.Epoch) != epoch {
+		if stats, err = loadEpochStats(sb.db, epoch); err != nil {
+			stats = &EpochStats{
+				Epoch:      hexutil.Uint64(epoch),
+				FirstBlock: hexutil.Uint64(number),
+				Fees:       make(map[common.Address]*hexutil.Big),
+			}
+		}
+	}
+	// Blocks replaced by a reorg were recorded already
+	if stats.Blocks > 0 && uint64(stats.LastBlock) >= number {
+		return
+	}
+	stats.LastBlock = hexutil.Uint64(number)
+	stats.Blocks++
+	stats.Rounds += hexutil.Uint64(childExtra.ParentAggregatedSeal.Round.Uint64())
+	stats.AverageRound = float64(stats.Rounds) / float64(stats.Blocks)
+
+	// The signers of the block are checked against the validators elected for it
+	sb.coreMu.RLock()
+	validatorAddress := sb.ValidatorAddress()
+	valSet := sb.getValidators(number-1, parent.ParentHash)
+	sb.coreMu.RUnlock()
+
+	stats.Validator = validatorAddress
+	if parent.Coinbase == validatorAddress {
+		stats.Proposed++
+	}
+	if index, _ := valSet.GetByAddress(validatorAddress); index >= 0 {
+		stats.Elected++
+		if childExtra.ParentAggregatedSeal.Bitmap.Bit(index) != 0 {
+			stats.Signed++
+		} else {
+			stats.Missed++
+		}
+	}
+
+	// Transactions and fees require the block bodies and receipts
+	if bc, ok := sb.chain.(*core.BlockChain); ok {
+		block := bc.GetBlock(parent.Hash(), number)
+		receipts := bc.GetReceiptsByHash(parent.Hash())
+		if block != nil && receipts != nil {
+			for i, tx := range block.Transactions() {
+				if i >= len(receipts) {
+					break
+				}
+				var currency common.Address
+				if tx.FeeCurrency() != nil {
+					currency = *tx.FeeCurrency()
+				}
+				stats.addFee(currency, new(big.Int).Mul(tx.GasPrice(), new(big.Int).SetUint64(receipts[i].GasUsed)))
+			}
+			stats.Transactions += hexutil.Uint64(len(block.Transactions()))
+		}
+	}
+
+	if err := stats.store(sb.db); err != nil {
+		sb.logger.Warn("Failed to store epoch stats", "epoch", epoch, "err", err)
+	}
+	sb.epochStats.stats = stats
+}
+
+// EpochStats returns the stats recorded for the given epoch.
+func (sb *Backend) EpochStats(epoch uint64) (*EpochStats, error) {
+	sb.epochStats.mu.Lock()
+	defer sb.epochStats.mu.Unlock()
+
+	return loadEpochStats(sb.db, epoch)
+}
@@ -0,0 +1,75 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"testing"
+	"time"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/consensus/istanbul"
+)
+
+func TestRecordEpochStats(t *testing.T) {
+	genesis, keys := getGenesisAndKeys(1, true)
+	chain, engine, _ := newBlockChainWithKeys(false, common.Address{}, false, genesis, keys[0])
+	defer stopEngine(engine)
+
+	if _, err := engine.EpochStats(1); err != errNoEpochStats {
+		t.Fatalf("error mismatch: have %v, want %v", err, errNoEpochStats)
+	}
+	block := chain.Genesis()
+	for i := 0; i < 3; i++ {
+		next, err := makeBlock(keys, chain, engine, block)
+		if err != nil {
+			t.Fatalf("failed to make block %d: %v", i+1, err)
+		}
+		block = next
+	}
+	// Blocks are recorded as their children become the chain head
+	epoch := istanbul.GetEpochNumber(1, engine.EpochSize())
+	for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+		if stats, err := engine.EpochStats(epoch); err == nil && stats.LastBlock == 2 {
+			break
+		}
+		if time.Since(start) > 5*time.Second {
+			t.Fatalf("blocks not recorded")
+		}
+	}
+	// Recording a block again leaves the stats unchanged
+	engine.recordEpochStats(block)
+
+	stats, err := engine.EpochStats(epoch)
+	if err != nil {
+		t.Fatalf("failed to retrieve epoch stats: %v", err)
+	}
+	if stats.FirstBlock != 1 || stats.LastBlock != 2 || stats.Blocks != 2 {
+		t.Errorf("recorded blocks mismatch: have %d-%d (%d), want 1-2 (2)", stats.FirstBlock, stats.LastBlock, stats.Blocks)
+	}
+	if stats.Validator != engine.Address() || stats.Elected != 2 || stats.Proposed != 2 || stats.Signed != 2 || stats.Missed != 0 {
+		t.Errorf("validator activity mismatch: have %+v", stats)
+	}
+	if stats.Rounds != 0 || stats.AverageRound != 0 || stats.Transactions != 0 || len(stats.Fees) != 0 {
+		t.Errorf("block activity mismatch: have %+v", stats)
+	}
+
+	// The stats are persisted
+	loaded, err := loadEpochStats(engine.db, uint64(stats.Epoch))
+	if err != nil || loaded.Blocks != 2 {
+		t.Errorf("stored stats mismatch: have %+v, %v", loaded, err)
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"math/big"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/common/hexutil"
+	"github.com/celo-org/celo-blockchain/consensus/istanbul"
+	ethCore "github.com/celo-org/celo-blockchain/core"
+	"github.com/celo-org/celo-blockchain/core/types"
+	blscrypto "github.com/celo-org/celo-blockchain/crypto/bls"
+)
+
+// DecodedExtra is the istanbul extra-data of a header, decoded for tooling.
+type DecodedExtra struct {
+	Version                   hexutil.Uint                    `json:"version"` // Version of the payload format
+	Vanity                    hexutil.Bytes                   `json:"vanity"`
+	AddedValidators           []common.Address                `json:"addedValidators"`
+	AddedValidatorsPublicKeys []blscrypto.SerializedPublicKey `json:"addedValidatorsPublicKeys"`
+	RemovedValidators         *hexutil.Big                    `json:"removedValidators"` // Bitmap of the removed validators
+	Seal                      hexutil.Bytes                   `json:"seal"`
+	AggregatedSeal            *DecodedSeal                    `json:"aggregatedSeal"`
+	ParentAggregatedSeal      *DecodedSeal                    `json:"parentAggregatedSeal"`
+
+	// EpochSnarkData is the seal of the validators over the next epoch's ones,
+	// only set for the last block of an epoch if its body is available
+	EpochSnarkData *DecodedSeal `json:"epochSnarkData,omitempty"`
+}
+
+// DecodedSeal is an aggregated BLS seal, along with the indices of the validators
+// in its bitmap.
+type DecodedSeal struct {
+	Bitmap    *hexutil.Big   `json:"bitmap"`
+	Signers   []hexutil.Uint `json:"signers"`
+	Signature hexutil.Bytes  `json:"signature"`
+	Round     *hexutil.Big   `json:"round,omitempty"`
+}
+
+func newDecodedSeal(bitmap *big.Int, signature []byte, round *big.Int) *DecodedSeal {
+	seal := &DecodedSeal{Signers: []hexutil.Uint{}, Signature: signature}
+	if bitmap != nil {
+		seal.Bitmap = (*hexutil.Big)(bitmap)
+		for i := 0; i < bitmap.BitLen(); i++ {
+			if bitmap.Bit(i) == 1 {
+				seal.Signers = append(seal.Signers, hexutil.Uint(i))
+			}
+		}
+	}
+	if round != nil {
+		seal.Round = (*hexutil.Big)(round)
+	}
+	return seal
+}
+
+// decodeExtra decodes the istanbul extra-data of the given header.
+func (sb *Backend) decodeExtra(header *types.Header) (*DecodedExtra, error) {
+	extra, version, err := types.DecodeIstanbulExtra(header)
+	if err != nil {
+		return nil, err
+	}
+	decoded := &DecodedExtra{
+		Version:                   hexutil.Uint(version),
+		Vanity:                    header.Extra[:types.IstanbulExtraVanity],
+		AddedValidators:           extra.AddedValidators,
+		AddedValidatorsPublicKeys: extra.AddedValidatorsPublicKeys,
+		RemovedValidators:         (*hexutil.Big)(extra.RemovedValidators),
+		Seal:                      extra.Seal,
+		AggregatedSeal:            newDecodedSeal(extra.AggregatedSeal.Bitmap, extra.AggregatedSeal.Signature, extra.AggregatedSeal.Round),
+		ParentAggregatedSeal:      newDecodedSeal(extra.ParentAggregatedSeal.Bitmap, extra.ParentAggregatedSeal.Signature, extra.ParentAggregatedSeal.Round),
+	}
+	if number := header.Number.Uint64(); number > 0 && istanbul.IsLastBlockOfEpoch(number, sb.EpochSize()) {
+		if bc, ok := sb.chain.(*ethCore.BlockChain); ok {
+			if block := bc.GetBlock(header.Hash(), number); block != nil && block.EpochSnarkData() != nil {
+				data := block.EpochSnarkData()
+				decoded.EpochSnarkData = newDecodedSeal(data.Bitmap, data.Signature, nil)
+			}
+		}
+	}
+	return decoded, nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"testing"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/common/hexutil"
+	"github.com/celo-org/celo-blockchain/core/types"
+	. "github.com/onsi/gomega"
+)
+
+func TestDecodeExtra(t *testing.T) {
+	g := NewGomegaWithT(t)
+	genesisCfg, nodeKeys := getGenesisAndKeys(1, true)
+	chain, engine, _ := newBlockChainWithKeys(false, common.Address{}, false, genesisCfg, nodeKeys[0])
+	defer stopEngine(engine)
+	defer chain.Stop()
+
+	// the genesis block adds the validators
+	decoded, err := engine.decodeExtra(chain.Genesis().Header())
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(decoded.Version).Should(BeEquivalentTo(types.IstanbulExtraV1))
+	g.Expect(decoded.AddedValidators).Should(HaveLen(1))
+	g.Expect(decoded.AddedValidatorsPublicKeys).Should(HaveLen(1))
+
+	// sealed blocks carry the signers of the aggregated seal
+	block, err := makeBlock(nodeKeys, chain, engine, chain.Genesis())
+	g.Expect(err).ToNot(HaveOccurred())
+	decoded, err = engine.decodeExtra(block.Header())
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(decoded.AddedValidators).Should(BeEmpty())
+	g.Expect(decoded.Seal).ShouldNot(BeEmpty())
+	g.Expect(decoded.AggregatedSeal.Signers).Should(Equal([]hexutil.Uint{0}))
+	g.Expect(decoded.AggregatedSeal.Signature).ShouldNot(BeEmpty())
+	g.Expect(decoded.EpochSnarkData).Should(BeNil())
+
+	// unknown payload formats are rejected
+	header := types.CopyHeader(block.Header())
+	header.Extra = append(header.Extra[:types.IstanbulExtraVanity:types.IstanbulExtraVanity], 0xc1, 0x80)
+	_, err = engine.decodeExtra(header)
+	g.Expect(err).Should(BeIdenticalTo(types.ErrUnknownIstanbulExtraVersion))
+}
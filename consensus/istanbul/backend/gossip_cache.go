@@ -22,8 +22,120 @@ import (
 	"github.com/celo-org/celo-blockchain/common"
 	"github.com/celo-org/celo-blockchain/consensus/istanbul"
 	"github.com/celo-org/celo-blockchain/log"
+	"github.com/celo-org/celo-blockchain/metrics"
 )
 
+const (
+	// knownMessageEntrySize is the estimated memory footprint in bytes of an
+	// entry of the known messages caches, including the LRU bookkeeping.
+	knownMessageEntrySize = 192
+
+	// minKnownMessages is the minimum number of entries of the known messages
+	// caches, whatever the memory budget.
+	minKnownMessages = 1024
+
+	// selfKnownMessagesShare is the inverse of the share of the entries for the
+	// messages known by this node, the rest being for the messages known by peers.
+	selfKnownMessagesShare = 8
+)
+
+// messageKind is the namespace of a known message, for the protocols sharing
+// the known messages caches not to collide.
+type messageKind int
+
+const (
+	gossipMessageKind    messageKind = iota // Gossiped query enode and version certificates messages
+	consensusMessageKind                    // Consensus messages, pushed whole or pulled
+	numMessageKinds
+)
+
+var messageKindNames = [numMessageKinds]string{"gossip", "consensus"}
+
+var (
+	knownMessagesHitMeters      [numMessageKinds]metrics.Meter
+	knownMessagesMissMeters     [numMessageKinds]metrics.Meter
+	knownMessagesEvictionsMeter = metrics.NewRegisteredMeter("consensus/istanbul/backend/known/evictions", nil)
+	knownMessagesSelfGauge      = metrics.NewRegisteredGauge("consensus/istanbul/backend/known/self", nil)
+	knownMessagesPeersGauge     = metrics.NewRegisteredGauge("consensus/istanbul/backend/known/peers", nil)
+)
+
+func init() {
+	for kind, name := range messageKindNames {
+		knownMessagesHitMeters[kind] = metrics.NewRegisteredMeter("consensus/istanbul/backend/known/"+name+"/hit", nil)
+		knownMessagesMissMeters[kind] = metrics.NewRegisteredMeter("consensus/istanbul/backend/known/"+name+"/miss", nil)
+	}
+}
+
+type selfKnownKey struct {
+	kind messageKind
+	hash common.Hash
+}
+
+type peerKnownKey struct {
+	kind messageKind
+	peer common.Address
+	hash common.Hash
+}
+
+// KnownMessages is the message deduplication cache shared by the istanbul
+// protocols. It tracks the messages known by this node and by each of its peers
+// within a memory budget, so that spamming it only evicts the oldest entries.
+type KnownMessages struct {
+	self  *lru.Cache // Hashes of the messages known by this node
+	peers *lru.Cache // Hashes of the messages known by each peer
+}
+
+// NewKnownMessages creates the known messages caches within the given memory
+// budget in megabytes.
+func NewKnownMessages(budget uint64) *KnownMessages {
+	entries := int(budget * 1024 * 1024 / knownMessageEntrySize)
+	if entries < minKnownMessages {
+		entries = minKnownMessages
+	}
+	onEvicted := func(key, value interface{}) { knownMessagesEvictionsMeter.Mark(1) }
+
+	self, err := lru.NewWithEvict(entries/selfKnownMessagesShare, onEvicted)
+	if err != nil {
+		log.Crit("Failed to create known messages cache", "err", err)
+	}
+	peers, err := lru.NewWithEvict(entries-entries/selfKnownMessagesShare, onEvicted)
+	if err != nil {
+		log.Crit("Failed to create peer known messages cache", "err", err)
+	}
+	return &KnownMessages{self: self, peers: peers}
+}
+
+// MarkBySelf records the message as known by this node.
+func (km *KnownMessages) MarkBySelf(kind messageKind, hash common.Hash) {
+	km.self.Add(selfKnownKey{kind, hash}, struct{}{})
+	knownMessagesSelfGauge.Update(int64(km.self.Len()))
+}
+
+// KnownBySelf returns whether the message is known by this node.
+func (km *KnownMessages) KnownBySelf(kind messageKind, hash common.Hash) bool {
+	return km.known(km.self, kind, selfKnownKey{kind, hash})
+}
+
+// MarkByPeer records the message as known by the peer.
+func (km *KnownMessages) MarkByPeer(kind messageKind, peer common.Address, hash common.Hash) {
+	km.peers.Add(peerKnownKey{kind, peer, hash}, struct{}{})
+	knownMessagesPeersGauge.Update(int64(km.peers.Len()))
+}
+
+// KnownByPeer returns whether the message is known by the peer.
+func (km *KnownMessages) KnownByPeer(kind messageKind, peer common.Address, hash common.Hash) bool {
+	return km.known(km.peers, kind, peerKnownKey{kind, peer, hash})
+}
+
+func (km *KnownMessages) known(cache *lru.Cache, kind messageKind, key interface{}) bool {
+	if cache.Contains(key) {
+		knownMessagesHitMeters[kind].Mark(1)
+		return true
+	}
+	knownMessagesMissMeters[kind].Mark(1)
+	return false
+}
+
 type GossipCache interface {
 	MarkMessageProcessedByPeer(peerNodeAddr common.Address, payload []byte)
 	CheckIfMessageProcessedByPeer(peerNodeAddr common.Address, payload []byte) bool
@@ -32,60 +144,28 @@ type GossipCache interface {
 	CheckIfMessageProcessedBySelf(payload []byte) bool
 }
 
+// LRUGossipCache is the GossipCache of the gossiped messages within the known
+// messages caches.
 type LRUGossipCache struct {
-	peerRecentMessages *lru.ARCCache // the cache of peer's recent messages
-	selfRecentMessages *lru.ARCCache // the cache of self recent messages
+	known *KnownMessages
 }
 
-func NewLRUGossipCache(peerCacheSize, selfCacheSize int) *LRUGossipCache {
-	logger := log.New()
-	peerRecentMessages, err := lru.NewARC(inmemoryPeers)
-	if err != nil {
-		logger.Crit("Failed to create recent messages cache", "err", err)
-	}
-	selfRecentMessages, err := lru.NewARC(inmemoryMessages)
-	if err != nil {
-		logger.Crit("Failed to create known messages cache", "err", err)
-	}
-	return &LRUGossipCache{
-		peerRecentMessages: peerRecentMessages,
-		selfRecentMessages: selfRecentMessages,
-	}
+func NewLRUGossipCache(known *KnownMessages) *LRUGossipCache {
+	return &LRUGossipCache{known: known}
 }
 
 func (gc *LRUGossipCache) MarkMessageProcessedByPeer(peerNodeAddr common.Address, payload []byte) {
-	ms, ok := gc.peerRecentMessages.Get(peerNodeAddr)
-	var m *lru.ARCCache
-	if ok {
-		m, _ = ms.(*lru.ARCCache)
-	} else {
-		m, _ = lru.NewARC(inmemoryMessages)
-		gc.peerRecentMessages.Add(peerNodeAddr, m)
-	}
-	payloadHash := istanbul.RLPHash(payload)
-	m.Add(payloadHash, true)
+	gc.known.MarkByPeer(gossipMessageKind, peerNodeAddr, istanbul.RLPHash(payload))
 }
 
 func (gc *LRUGossipCache) CheckIfMessageProcessedByPeer(peerNodeAddr common.Address, payload []byte) bool {
-	ms, ok := gc.peerRecentMessages.Get(peerNodeAddr)
-	var m *lru.ARCCache
-	if ok {
-		m, _ = ms.(*lru.ARCCache)
-		payloadHash := istanbul.RLPHash(payload)
-		_, ok := m.Get(payloadHash)
-		return ok
-	}
-
-	return false
+	return gc.known.KnownByPeer(gossipMessageKind, peerNodeAddr, istanbul.RLPHash(payload))
 }
 
 func (gc *LRUGossipCache) MarkMessageProcessedBySelf(payload []byte) {
-	payloadHash := istanbul.RLPHash(payload)
-	gc.selfRecentMessages.Add(payloadHash, true)
+	gc.known.MarkBySelf(gossipMessageKind, istanbul.RLPHash(payload))
 }
 
 func (gc *LRUGossipCache) CheckIfMessageProcessedBySelf(payload []byte) bool {
-	payloadHash := istanbul.RLPHash(payload)
-	_, ok := gc.selfRecentMessages.Get(payloadHash)
-	return ok
+	return gc.known.KnownBySelf(gossipMessageKind, istanbul.RLPHash(payload))
 }
@@ -0,0 +1,59 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/celo-org/celo-blockchain/common"
+)
+
+func TestKnownMessages(t *testing.T) {
+	var (
+		km    = NewKnownMessages(1)
+		hash  = common.HexToHash("0x01")
+		peerA = common.HexToAddress("0x0a")
+		peerB = common.HexToAddress("0x0b")
+	)
+	// Messages are known per kind and per peer
+	km.MarkBySelf(gossipMessageKind, hash)
+	km.MarkByPeer(gossipMessageKind, peerA, hash)
+	if !km.KnownBySelf(gossipMessageKind, hash) || !km.KnownByPeer(gossipMessageKind, peerA, hash) {
+		t.Fatalf("marked message unknown")
+	}
+	if km.KnownBySelf(consensusMessageKind, hash) || km.KnownByPeer(consensusMessageKind, peerA, hash) {
+		t.Errorf("message known for another kind")
+	}
+	if km.KnownByPeer(gossipMessageKind, peerB, hash) {
+		t.Errorf("message known by another peer")
+	}
+
+	// The caches are bounded by the memory budget, evicting the oldest messages
+	entries := 1024 * 1024 / knownMessageEntrySize
+	for i := 0; i < entries; i++ {
+		other := common.BigToHash(big.NewInt(int64(i + 2)))
+		km.MarkBySelf(consensusMessageKind, other)
+		km.MarkByPeer(consensusMessageKind, peerB, other)
+	}
+	if km.self.Len()+km.peers.Len() > entries {
+		t.Errorf("known messages over budget: have %d, want at most %d", km.self.Len()+km.peers.Len(), entries)
+	}
+	if km.KnownBySelf(gossipMessageKind, hash) || km.KnownByPeer(gossipMessageKind, peerA, hash) {
+		t.Errorf("oldest messages not evicted")
+	}
+}
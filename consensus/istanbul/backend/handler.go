@@ -54,6 +54,17 @@ func (sb *Backend) HandleMsg(addr common.Address, msg p2p.Msg, peer consensus.Pe
 		logger.Error("Failed to decode message payload", "err", err, "from", addr)
 		return true, errDecodeFailed
 	}
+	fresh := false
+	if msg.Code == istanbul.ConsensusMsg {
+		// Ignore the later announcements of this consensus message
+		if fresh = sb.consensusPayloads.markKnown(data); fresh {
+			sb.forwardToObservers(peer, data)
+		}
+	}
+	if msg.Code == istanbul.ConsensusObserveMsg {
+		go sb.handleConsensusObserveMsg(peer)
+		return true, nil
+	}
 
 	if sb.IsProxy() {
 		switch msg.Code {
@@ -65,11 +76,14 @@ func (sb *Backend) HandleMsg(addr common.Address, msg p2p.Msg, peer consensus.Pe
 		case istanbul.ConsensusMsg:
 			fallthrough
 		case istanbul.EnodeCertificateMsg:
+			fallthrough
+		case istanbul.BlockChunkMsg:
 			// This will handle the following messages:
 			// 1) ValEnodesShareMsg
 			// 2) FwdMsg
 			// 3) ConsensusMsg
 			// 4) EnodeCertificateMsg
+			// 5) BlockChunkMsg
 			// No error on skipped messages
 			return sb.proxyEngine.HandleMsg(peer, msg.Code, data)
 		case istanbul.DelegateSignMsg:
@@ -84,6 +98,15 @@ func (sb *Backend) HandleMsg(addr common.Address, msg p2p.Msg, peer consensus.Pe
 		case istanbul.VersionCertificatesMsg:
 			go sb.handleVersionCertificatesMsg(addr, peer, data)
 			return true, nil
+		case istanbul.ConsensusRelayMsg:
+			go sb.handleConsensusRelayMsg(data)
+			return true, nil
+		case istanbul.ConsensusAnnounceMsg:
+			go sb.handleConsensusAnnounceMsg(peer, data)
+			return true, nil
+		case istanbul.ConsensusPullMsg:
+			go sb.handleConsensusPullMsg(peer, data)
+			return true, nil
 		case istanbul.ValidatorHandshakeMsg:
 			logger.Warn("Received unexpected Istanbul validator handshake message")
 			return true, nil
@@ -99,6 +122,18 @@ func (sb *Backend) HandleMsg(addr common.Address, msg p2p.Msg, peer consensus.Pe
 				Payload: data,
 			})
 			return true, nil
+		case istanbul.BlockChunkMsg:
+			go sb.handleBlockChunkMsg(data)
+			return true, nil
+		case istanbul.ConsensusRelayMsg:
+			go sb.handleConsensusRelayMsg(data)
+			return true, nil
+		case istanbul.ConsensusAnnounceMsg:
+			go sb.handleConsensusAnnounceMsg(peer, data)
+			return true, nil
+		case istanbul.ConsensusPullMsg:
+			go sb.handleConsensusPullMsg(peer, data)
+			return true, nil
 		case istanbul.DelegateSignMsg:
 			if sb.shouldHandleDelegateSign(peer) {
 				go sb.delegateSignFeed.Send(istanbul.MessageWithPeerIDEvent{
@@ -130,6 +165,16 @@ func (sb *Backend) HandleMsg(addr common.Address, msg p2p.Msg, peer consensus.Pe
 		// Handle messages as replica validator
 		switch msg.Code {
 		case istanbul.ConsensusMsg:
+			if sb.config.ObserveConsensus && fresh {
+				go sb.observeConsensusMsg(peer, data)
+			}
+			return true, nil
+		case istanbul.ConsensusAnnounceMsg:
+			if sb.config.ObserveConsensus {
+				go sb.handleConsensusAnnounceMsg(peer, data)
+			}
+			return true, nil
+		case istanbul.BlockChunkMsg, istanbul.ConsensusRelayMsg, istanbul.ConsensusPullMsg:
 			// Ignore consensus messages
 			return true, nil
 		case istanbul.DelegateSignMsg:
@@ -331,6 +376,7 @@ func (sb *Backend) newChainHead(newBlock *types.Block) {
 
 	// Update metrics for whether we were elected and signed the parent of this block.
 	sb.UpdateMetricsForParentOfBlock(newBlock)
+	sb.recordEpochStats(newBlock)
 
 	// If this is the last block of the epoch:
 	// * Print an easy to find log message giving our address and whether we're elected in next epoch.
@@ -360,6 +406,10 @@ func (sb *Backend) newChainHead(newBlock *types.Block) {
 		}
 	}
 
+	if sb.config.ObserveConsensus {
+		sb.observed.prune(newBlock.Number())
+	}
+
 	sb.blocksFinalizedTransactionsGauge.Update(int64(len(newBlock.Transactions())))
 	sb.blocksFinalizedGasUsedGauge.Update(int64(newBlock.GasUsed()))
 	sb.logger.Trace("End newChainHead", "number", newBlock.Number().Uint64())
@@ -385,6 +435,10 @@ func (sb *Backend) RegisterPeer(peer consensus.Peer, isProxiedPeer bool) error {
 		logger.Debug("Error sending all version certificates", "err", err)
 	}
 
+	if sb.config.ObserveConsensus {
+		go sb.requestConsensusObservation(peer)
+	}
+
 	return nil
 }
 
@@ -394,6 +448,7 @@ func (sb *Backend) UnregisterPeer(peer consensus.Peer, isProxiedPeer bool) {
 	} else if sb.IsProxiedValidator() {
 		sb.proxiedValidatorEngine.UnregisterProxyPeer(peer)
 	}
+	sb.observers.remove(peer.Node().ID())
 }
 
 // Handshake allows the initiating peer to identify itself as a validator
@@ -478,7 +533,7 @@ func (sb *Backend) readValidatorHandshakeMessage(peer consensus.Peer) (bool, err
 	}
 
 	var msg istanbul.Message
-	err = msg.FromPayload(payload, sb.verifyValidatorHandshakeMessage)
+	err = msg.FromPayloadInDomains(payload, sb.SigningDomains().Verify, sb.verifyValidatorHandshakeMessage)
 	if err != nil {
 		return false, err
 	}
@@ -108,6 +108,12 @@ func (gdb *GenericDB) Write(batch *leveldb.Batch) error {
 	return gdb.db.Write(batch, gdb.writeOptions)
 }
 
+// Compact compacts the whole underlying store, discarding the deleted and
+// overwritten entries
+func (gdb *GenericDB) Compact() error {
+	return gdb.db.CompactRange(util.Range{})
+}
+
 // Iterate will iterate through each entry in the db whose key has the prefix
 // keyPrefix, and call `onEntry` with the bytes of the key (without the prefix)
 // and the bytes of the value
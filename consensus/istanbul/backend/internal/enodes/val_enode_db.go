@@ -18,6 +18,7 @@ package enodes
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -37,7 +38,7 @@ import (
 
 // Keys in the node database.
 const (
-	valEnodeDBVersion = 4
+	valEnodeDBVersion = 5
 )
 
 // ValidatorEnodeHandler is handler to Add/Remove events. Events execute within write lock
@@ -205,6 +206,7 @@ func (vet *ValidatorEnodeDB) UpsertHighestKnownVersion(valEnodeEntries []*istanb
 		if err != nil {
 			return err
 		}
+		setLastUpdatedTimestamp(addressEntry)
 		entryBytes, err := rlp.EncodeToBytes(addressEntry)
 		if err != nil {
 			return err
@@ -236,6 +238,11 @@ func (vet *ValidatorEnodeDB) UpsertHighestKnownVersion(valEnodeEntries []*istanb
 		newAddressEntry.Version = existingAddressEntry.Version
 		newAddressEntry.LastQueryTimestamp = existingAddressEntry.LastQueryTimestamp
 
+		// Regossiped certificates of the same version don't refresh the entry
+		if newAddressEntry.HighestKnownVersion == existingAddressEntry.HighestKnownVersion {
+			newAddressEntry.LastUpdatedTimestamp = existingAddressEntry.LastUpdatedTimestamp
+		}
+
 		// Set NumQueryAttemptsForHKVersion to 0
 		newAddressEntry.NumQueryAttemptsForHKVersion = 0
 
@@ -265,6 +272,7 @@ func (vet *ValidatorEnodeDB) UpsertVersionAndEnode(valEnodeEntries []*istanbul.A
 		if err != nil {
 			return err
 		}
+		setLastUpdatedTimestamp(addressEntry)
 		entryBytes, err := rlp.EncodeToBytes(addressEntry)
 		if err != nil {
 			return err
@@ -295,6 +303,9 @@ func (vet *ValidatorEnodeDB) UpsertVersionAndEnode(valEnodeEntries []*istanbul.A
 		// "Backfill" all other fields
 		newAddressEntry.PublicKey = existingAddressEntry.PublicKey
 		newAddressEntry.LastQueryTimestamp = existingAddressEntry.LastQueryTimestamp
+		if newAddressEntry.Version == existingAddressEntry.Version {
+			newAddressEntry.LastUpdatedTimestamp = existingAddressEntry.LastUpdatedTimestamp
+		}
 
 		// Update HighestKnownVersion, if needed
 		if newAddressEntry.Version > existingAddressEntry.HighestKnownVersion {
@@ -340,6 +351,7 @@ func (vet *ValidatorEnodeDB) UpdateQueryEnodeStats(valEnodeEntries []*istanbul.A
 		if err != nil {
 			return err
 		}
+		setLastUpdatedTimestamp(addressEntry)
 		entryBytes, err := rlp.EncodeToBytes(addressEntry)
 		if err != nil {
 			return err
@@ -373,6 +385,7 @@ func (vet *ValidatorEnodeDB) UpdateQueryEnodeStats(valEnodeEntries []*istanbul.A
 		newAddressEntry.Node = existingAddressEntry.Node
 		newAddressEntry.Version = existingAddressEntry.Version
 		newAddressEntry.HighestKnownVersion = existingAddressEntry.HighestKnownVersion
+		newAddressEntry.LastUpdatedTimestamp = existingAddressEntry.LastUpdatedTimestamp
 
 		return onNewEntry(batch, newAddressEntry)
 	}
@@ -385,6 +398,15 @@ func (vet *ValidatorEnodeDB) UpdateQueryEnodeStats(valEnodeEntries []*istanbul.A
 	return nil
 }
 
+// setLastUpdatedTimestamp sets the update time of new or updated entries which
+// don't backfill the existing one.
+func setLastUpdatedTimestamp(addressEntry *istanbul.AddressEntry) {
+	if addressEntry.LastUpdatedTimestamp == nil {
+		currentTime := time.Now()
+		addressEntry.LastUpdatedTimestamp = &currentTime
+	}
+}
+
 // upsert will update or insert a validator enode entry given that the existing entry
 // is older (determined by the version) than the new one
 // TODO - In addition to modifying the val_enode_db, this function also will disconnect
@@ -448,6 +470,31 @@ func (vet *ValidatorEnodeDB) PruneEntries(addressesToKeep map[common.Address]boo
 	return vet.gdb.Write(batch)
 }
 
+// PruneStaleEntries will remove the entries whose version wasn't raised within maxAge
+func (vet *ValidatorEnodeDB) PruneStaleEntries(maxAge time.Duration) error {
+	vet.lock.Lock()
+	defer vet.lock.Unlock()
+	batch := new(leveldb.Batch)
+	err := vet.iterateOverAddressEntries(func(address common.Address, entry *istanbul.AddressEntry) error {
+		if entry.LastUpdatedTimestamp != nil && time.Since(*entry.LastUpdatedTimestamp) > maxAge {
+			vet.logger.Trace("Deleting stale entry from valEnodeTable", "address", address, "lastUpdated", entry.LastUpdatedTimestamp)
+			return vet.addDeleteToBatch(batch, address)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return vet.gdb.Write(batch)
+}
+
+// Compact will reclaim the space of the removed entries in the underlying store
+func (vet *ValidatorEnodeDB) Compact() error {
+	vet.lock.Lock()
+	defer vet.lock.Unlock()
+	return vet.gdb.Compact()
+}
+
 func (vet *ValidatorEnodeDB) RefreshValPeers(valConnSet map[common.Address]bool, ourAddress common.Address) {
 	// We use a R lock since we don't modify levelDB table
 	vet.lock.RLock()
@@ -534,7 +581,8 @@ type ValEnodeEntryInfo struct {
 	Version                      uint   `json:"version"`
 	HighestKnownVersion          uint   `json:"highestKnownVersion"`
 	NumQueryAttemptsForHKVersion uint   `json:"numQueryAttemptsForHKVersion"`
-	LastQueryTimestamp           string `json:"lastQueryTimestamp"` // Unix timestamp
+	LastQueryTimestamp           string `json:"lastQueryTimestamp"`   // Unix timestamp
+	LastUpdatedTimestamp         string `json:"lastUpdatedTimestamp"` // Unix timestamp
 }
 
 // ValEnodeTableInfo gives basic information for each entry of the table
@@ -562,6 +610,9 @@ func (vet *ValidatorEnodeDB) ValEnodeTableInfo() (map[string]*ValEnodeEntryInfo,
 			if valEnodeEntry.LastQueryTimestamp != nil {
 				entryInfo.LastQueryTimestamp = valEnodeEntry.LastQueryTimestamp.String()
 			}
+			if valEnodeEntry.LastUpdatedTimestamp != nil {
+				entryInfo.LastUpdatedTimestamp = strconv.FormatInt(valEnodeEntry.LastUpdatedTimestamp.Unix(), 10)
+			}
 
 			valEnodeTableInfo[address.Hex()] = entryInfo
 		}
@@ -2,6 +2,7 @@ package enodes
 
 import (
 	"testing"
+	"time"
 
 	"github.com/celo-org/celo-blockchain/common"
 	"github.com/celo-org/celo-blockchain/consensus/istanbul"
@@ -153,3 +154,46 @@ func TestTableToString(t *testing.T) {
 		t.Errorf("String() error: got: %s", vet.String())
 	}
 }
+
+func TestPruneStaleEntries(t *testing.T) {
+	vet, err := OpenValidatorEnodeDB("", &mockListener{})
+	if err != nil {
+		t.Fatal("Failed to open DB")
+	}
+
+	batch := []*istanbul.AddressEntry{
+		{Address: addressA, Node: nodeA, Version: 2},
+		{Address: addressB, Node: nodeB, Version: 2},
+	}
+	vet.UpsertVersionAndEnode(batch)
+	time.Sleep(50 * time.Millisecond)
+
+	// Only raising the version refreshes the entry
+	vet.UpsertVersionAndEnode([]*istanbul.AddressEntry{{Address: addressA, Node: nodeA, Version: 2}})
+	vet.UpsertVersionAndEnode([]*istanbul.AddressEntry{{Address: addressB, Node: nodeB, Version: 3}})
+
+	if err := vet.PruneStaleEntries(25 * time.Millisecond); err != nil {
+		t.Fatalf("Failed to prune stale entries: %v", err)
+	}
+	if err := vet.Compact(); err != nil {
+		t.Fatalf("Failed to compact: %v", err)
+	}
+
+	if _, err := vet.GetNodeFromAddress(addressA); err == nil {
+		t.Errorf("It should have NOT found %s after prune", addressA.Hex())
+	}
+	if _, err := vet.GetAddressFromNodeID(nodeA.ID()); err == nil {
+		t.Errorf("It should have NOT found %s after prune", nodeA.ID())
+	}
+	if _, err := vet.GetNodeFromAddress(addressB); err != nil {
+		t.Errorf("It should have found %s after prune", addressB.Hex())
+	}
+
+	info, err := vet.ValEnodeTableInfo()
+	if err != nil {
+		t.Fatalf("Failed to get table info: %v", err)
+	}
+	if entryInfo := info[addressB.Hex()]; entryInfo == nil || entryInfo.LastUpdatedTimestamp == "" {
+		t.Errorf("Missing update timestamp of %s: %v", addressB.Hex(), entryInfo)
+	}
+}
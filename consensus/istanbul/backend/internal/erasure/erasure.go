@@ -0,0 +1,251 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package erasure implements a systematic Reed-Solomon erasure code over GF(2^8),
+// used to split large consensus messages into shards any subset of which, as
+// large as the number of data shards, is enough to rebuild the message.
+package erasure
+
+import (
+	"errors"
+)
+
+// MaxShards is the maximum number of data and parity shards.
+const MaxShards = 256
+
+var (
+	ErrInvalidShardCount = errors.New("invalid number of shards")
+	ErrTooFewShards      = errors.New("too few shards to rebuild data")
+	ErrShardSize         = errors.New("shards of different sizes")
+)
+
+// Galois field tables, for the field generated by x^8 + x^4 + x^3 + x^2 + 1.
+var (
+	expTable [510]byte
+	logTable [256]byte
+)
+
+func init() {
+	x := 1
+	for i := 0; i < 255; i++ {
+		expTable[i] = byte(x)
+		expTable[i+255] = byte(x)
+		logTable[x] = byte(i)
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= 0x11d
+		}
+	}
+}
+
+func galMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return expTable[int(logTable[a])+int(logTable[b])]
+}
+
+func galInv(a byte) byte {
+	return expTable[255-int(logTable[a])]
+}
+
+func galExp(a byte, n int) byte {
+	if n == 0 {
+		return 1
+	}
+	if a == 0 {
+		return 0
+	}
+	return expTable[(int(logTable[a])*n)%255]
+}
+
+type matrix [][]byte
+
+func newMatrix(rows, cols int) matrix {
+	m := make(matrix, rows)
+	for i := range m {
+		m[i] = make([]byte, cols)
+	}
+	return m
+}
+
+func (m matrix) mul(other matrix) matrix {
+	res := newMatrix(len(m), len(other[0]))
+	for i := range m {
+		for j := range other[0] {
+			var v byte
+			for k := range other {
+				v ^= galMul(m[i][k], other[k][j])
+			}
+			res[i][j] = v
+		}
+	}
+	return res
+}
+
+// invert returns the inverse of the square matrix, by Gauss-Jordan elimination.
+func (m matrix) invert() (matrix, error) {
+	n := len(m)
+	work := newMatrix(n, 2*n)
+	for i := range m {
+		copy(work[i], m[i])
+		work[i][n+i] = 1
+	}
+	for col := 0; col < n; col++ {
+		pivot := col
+		for pivot < n && work[pivot][col] == 0 {
+			pivot++
+		}
+		if pivot == n {
+			return nil, errors.New("singular matrix")
+		}
+		work[col], work[pivot] = work[pivot], work[col]
+
+		scale := galInv(work[col][col])
+		for j := range work[col] {
+			work[col][j] = galMul(work[col][j], scale)
+		}
+		for row := 0; row < n; row++ {
+			if row == col || work[row][col] == 0 {
+				continue
+			}
+			factor := work[row][col]
+			for j := range work[row] {
+				work[row][j] ^= galMul(factor, work[col][j])
+			}
+		}
+	}
+	inv := newMatrix(n, n)
+	for i := range inv {
+		copy(inv[i], work[i][n:])
+	}
+	return inv, nil
+}
+
+// encodingMatrix returns the total x data matrix whose top rows are the identity,
+// and any data rows of which are invertible.
+func encodingMatrix(data, total int) (matrix, error) {
+	vandermonde := newMatrix(total, data)
+	for i := range vandermonde {
+		for j := range vandermonde[i] {
+			vandermonde[i][j] = galExp(byte(i), j)
+		}
+	}
+	top, err := vandermonde[:data].invert()
+	if err != nil {
+		return nil, err
+	}
+	return vandermonde.mul(top), nil
+}
+
+// Encode splits data into dataShards equally sized shards, zero padding the last
+// one, and appends parityShards parity shards to them.
+func Encode(data []byte, dataShards, parityShards int) ([][]byte, error) {
+	if dataShards <= 0 || parityShards < 0 || dataShards+parityShards > MaxShards {
+		return nil, ErrInvalidShardCount
+	}
+	m, err := encodingMatrix(dataShards, dataShards+parityShards)
+	if err != nil {
+		return nil, err
+	}
+	size := (len(data) + dataShards - 1) / dataShards
+	if size == 0 {
+		size = 1
+	}
+	padded := make([]byte, size*dataShards)
+	copy(padded, data)
+
+	shards := make([][]byte, dataShards+parityShards)
+	for i := 0; i < dataShards; i++ {
+		shards[i] = padded[i*size : (i+1)*size]
+	}
+	for i := dataShards; i < len(shards); i++ {
+		shards[i] = make([]byte, size)
+		for j := 0; j < dataShards; j++ {
+			mulAdd(shards[i], shards[j], m[i][j])
+		}
+	}
+	return shards, nil
+}
+
+// Decode rebuilds the data of the given size from its shards, with nil for the
+// missing ones. At least dataShards of them are required.
+func Decode(shards [][]byte, dataShards int, size int) ([]byte, error) {
+	if dataShards <= 0 || len(shards) < dataShards || len(shards) > MaxShards {
+		return nil, ErrInvalidShardCount
+	}
+	var (
+		rows      = make([]int, 0, dataShards)
+		shardSize = -1
+	)
+	for i, shard := range shards {
+		if shard == nil {
+			continue
+		}
+		if shardSize == -1 {
+			shardSize = len(shard)
+		} else if len(shard) != shardSize {
+			return nil, ErrShardSize
+		}
+		if len(rows) < dataShards {
+			rows = append(rows, i)
+		}
+	}
+	if len(rows) < dataShards {
+		return nil, ErrTooFewShards
+	}
+	if size < 0 || size > shardSize*dataShards {
+		return nil, ErrShardSize
+	}
+	data := make([]byte, shardSize*dataShards)
+
+	// Data shards are copied as is if all available
+	if rows[dataShards-1] == dataShards-1 {
+		for i := 0; i < dataShards; i++ {
+			copy(data[i*shardSize:], shards[i])
+		}
+		return data[:size], nil
+	}
+	m, err := encodingMatrix(dataShards, len(shards))
+	if err != nil {
+		return nil, err
+	}
+	sub := newMatrix(dataShards, dataShards)
+	for i, row := range rows {
+		copy(sub[i], m[row])
+	}
+	decoding, err := sub.invert()
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < dataShards; i++ {
+		out := data[i*shardSize : (i+1)*shardSize]
+		for j, row := range rows {
+			mulAdd(out, shards[row], decoding[i][j])
+		}
+	}
+	return data[:size], nil
+}
+
+// mulAdd adds the product of the input by the coefficient to the output.
+func mulAdd(out, in []byte, c byte) {
+	if c == 0 {
+		return
+	}
+	for i, b := range in {
+		out[i] ^= galMul(c, b)
+	}
+}
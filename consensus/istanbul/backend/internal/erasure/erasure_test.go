@@ -0,0 +1,86 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package erasure
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	testCases := []struct {
+		size, data, parity int
+	}{
+		{0, 1, 0},
+		{1, 1, 2},
+		{100, 3, 6},
+		{1000, 10, 20},
+		{4096, 34, 65},
+		{12345, 100, 156},
+	}
+	rng := rand.New(rand.NewSource(1))
+	for i, tc := range testCases {
+		data := make([]byte, tc.size)
+		rng.Read(data)
+
+		shards, err := Encode(data, tc.data, tc.parity)
+		if err != nil {
+			t.Fatalf("test %d: failed to encode: %v", i, err)
+		}
+		if len(shards) != tc.data+tc.parity {
+			t.Fatalf("test %d: shard count mismatch: have %d, want %d", i, len(shards), tc.data+tc.parity)
+		}
+		// Drop as many random shards as there are parity ones
+		available := make([][]byte, len(shards))
+		copy(available, shards)
+		for _, j := range rng.Perm(len(shards))[:tc.parity] {
+			available[j] = nil
+		}
+		decoded, err := Decode(available, tc.data, tc.size)
+		if err != nil {
+			t.Fatalf("test %d: failed to decode: %v", i, err)
+		}
+		if !bytes.Equal(decoded, data) {
+			t.Errorf("test %d: decoded data mismatch", i)
+		}
+		// One more missing shard can't be recovered from
+		if tc.parity > 0 {
+			for j := range available {
+				if available[j] != nil {
+					available[j] = nil
+					break
+				}
+			}
+			if _, err := Decode(available, tc.data, tc.size); err != ErrTooFewShards {
+				t.Errorf("test %d: error mismatch: have %v, want %v", i, err, ErrTooFewShards)
+			}
+		}
+	}
+}
+
+func TestInvalidShards(t *testing.T) {
+	if _, err := Encode([]byte{1}, 0, 1); err != ErrInvalidShardCount {
+		t.Errorf("error mismatch: have %v, want %v", err, ErrInvalidShardCount)
+	}
+	if _, err := Encode([]byte{1}, 200, 57); err != ErrInvalidShardCount {
+		t.Errorf("error mismatch: have %v, want %v", err, ErrInvalidShardCount)
+	}
+	if _, err := Decode([][]byte{{1}, {1, 2}}, 2, 2); err != ErrShardSize {
+		t.Errorf("error mismatch: have %v, want %v", err, ErrShardSize)
+	}
+}
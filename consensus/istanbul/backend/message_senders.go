@@ -50,7 +50,17 @@ func (sb *Backend) Multicast(destAddresses []common.Address, payload []byte, eth
 
 	var err error
 
-	if sb.IsProxiedValidator() {
+	// Forwarded before being announced, which would mark it as known
+	if ethMsgCode == istanbul.ConsensusMsg && sb.consensusPayloads.markKnown(payload) {
+		sb.forwardToObservers(nil, payload)
+	}
+
+	if sb.shouldChunk(destAddresses, payload, ethMsgCode) {
+		err = sb.multicastChunks(destAddresses, payload)
+		if err != nil {
+			logger.Warn("Error in sending chunked message", "err", err)
+		}
+	} else if sb.IsProxiedValidator() {
 		err = sb.proxiedValidatorEngine.SendForwardMsgToAllProxies(destAddresses, ethMsgCode, payload)
 		if err != nil {
 			logger.Warn("Error in sending forward message to the proxies", "err", err)
@@ -60,6 +70,14 @@ func (sb *Backend) Multicast(destAddresses []common.Address, payload []byte, eth
 		if len(destPeers) > 0 {
 			sb.asyncMulticast(destPeers, payload, ethMsgCode)
 		}
+		// Fall back to relaying through other validators when direct links are down
+		if ethMsgCode == istanbul.ConsensusMsg && sb.config.ValidatorLinkRelay && destAddresses != nil {
+			if unlinked := sb.unlinkedValidators(destAddresses, destPeers); len(unlinked) > 0 {
+				if err := sb.requestConsensusRelay(unlinked, payload); err != nil {
+					logger.Warn("Error in requesting consensus message relay", "err", err)
+				}
+			}
+		}
 	}
 
 	if sendToSelf {
@@ -112,6 +130,10 @@ func (sb *Backend) Gossip(payload []byte, ethMsgCode uint64) error {
 func (sb *Backend) asyncMulticast(destPeers map[enode.ID]consensus.Peer, payload []byte, ethMsgCode uint64) {
 	logger := sb.logger.New("func", "AsyncMulticastCeloMsg", "msgCode", ethMsgCode)
 
+	if sb.shouldAnnounce(payload, ethMsgCode) {
+		hash := sb.consensusPayloads.announce(payload)
+		payload, ethMsgCode = hash.Bytes(), istanbul.ConsensusAnnounceMsg
+	}
 	for _, peer := range destPeers {
 		peer := peer // Create new instance of peer for the goroutine
 		go func() {
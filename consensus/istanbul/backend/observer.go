@@ -0,0 +1,245 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/consensus"
+	"github.com/celo-org/celo-blockchain/consensus/istanbul"
+	"github.com/celo-org/celo-blockchain/metrics"
+	"github.com/celo-org/celo-blockchain/p2p/enode"
+)
+
+// Full nodes may observe the consensus of the validators without taking part
+// in it. An observer asks its peers to forward it the consensus messages they
+// see, and records the ones of the sequence being agreed on, once checked to be
+// signed by its validators. The peers serving observers forward them each
+// consensus message the first time they send or receive it.
+
+const (
+	// maxConsensusObservers is the number of peers a node forwards the consensus
+	// messages to.
+	maxConsensusObservers = 16
+
+	// maxObservedMessages is the number of consensus messages an observer records
+	// for the current sequence.
+	maxObservedMessages = 1024
+)
+
+var (
+	// errNotObserving is returned when the observed consensus messages are
+	// requested from a node not observing them.
+	errNotObserving = errors.New("not observing consensus")
+
+	consensusObserversGauge = metrics.NewRegisteredGauge("consensus/istanbul/backend/observer/observers", nil)
+	observedMessagesMeter   = metrics.NewRegisteredMeter("consensus/istanbul/backend/observer/messages", nil)
+)
+
+// ObservedMessage is a consensus message recorded by an observer.
+type ObservedMessage struct {
+	Code     string         `json:"code"`
+	Sequence *big.Int       `json:"sequence"`
+	Round    *big.Int       `json:"round"`
+	Sender   common.Address `json:"sender"`
+	Digest   common.Hash    `json:"digest"`   // Hash of the proposal, empty for round changes without a prepared certificate
+	Peer     string         `json:"peer"`     // Node the message was received from
+	Received int64          `json:"received"` // Unix timestamp in milliseconds
+}
+
+// consensusObservers is the set of peers the consensus messages are forwarded to.
+type consensusObservers struct {
+	mu    sync.RWMutex
+	peers map[enode.ID]consensus.Peer
+}
+
+func newConsensusObservers() *consensusObservers {
+	return &consensusObservers{peers: make(map[enode.ID]consensus.Peer)}
+}
+
+// add adds the peer to the observers, unless there are too many of them.
+func (o *consensusObservers) add(peer consensus.Peer) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if _, ok := o.peers[peer.Node().ID()]; !ok && len(o.peers) >= maxConsensusObservers {
+		return false
+	}
+	o.peers[peer.Node().ID()] = peer
+	consensusObserversGauge.Update(int64(len(o.peers)))
+	return true
+}
+
+// remove removes the peer from the observers.
+func (o *consensusObservers) remove(id enode.ID) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	delete(o.peers, id)
+	consensusObserversGauge.Update(int64(len(o.peers)))
+}
+
+// targets returns the observers but the given one.
+func (o *consensusObservers) targets(except enode.ID) map[enode.ID]consensus.Peer {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	targets := make(map[enode.ID]consensus.Peer, len(o.peers))
+	for id, peer := range o.peers {
+		if id != except {
+			targets[id] = peer
+		}
+	}
+	return targets
+}
+
+// observedMessages is the record of the consensus messages of the current
+// sequence, in the order they were received.
+type observedMessages struct {
+	mu       sync.Mutex
+	messages []*ObservedMessage
+}
+
+// add records the message, dropping the oldest one if too many are recorded.
+func (o *observedMessages) add(msg *ObservedMessage) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if len(o.messages) >= maxObservedMessages {
+		o.messages = o.messages[1:]
+	}
+	o.messages = append(o.messages, msg)
+}
+
+// prune drops the messages of the sequences up to the given one.
+func (o *observedMessages) prune(sequence *big.Int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	kept := o.messages[:0]
+	for _, msg := range o.messages {
+		if msg.Sequence.Cmp(sequence) > 0 {
+			kept = append(kept, msg)
+		}
+	}
+	for i := len(kept); i < len(o.messages); i++ {
+		o.messages[i] = nil
+	}
+	o.messages = kept
+}
+
+// list returns the recorded messages.
+func (o *observedMessages) list() []*ObservedMessage {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return append([]*ObservedMessage{}, o.messages...)
+}
+
+// requestConsensusObservation asks the peer to forward the consensus messages
+// to this node.
+func (sb *Backend) requestConsensusObservation(peer consensus.Peer) {
+	if err := peer.Send(istanbul.ConsensusObserveMsg, []byte{}); err != nil {
+		sb.logger.Debug("Error in requesting consensus observation", "peer", peer, "err", err)
+	}
+}
+
+// handleConsensusObserveMsg adds the peer to the observers of the consensus
+// messages, if this node serves them.
+func (sb *Backend) handleConsensusObserveMsg(peer consensus.Peer) {
+	if !sb.config.ServeObservers {
+		sb.logger.Trace("Ignoring consensus observation request", "peer", peer)
+		return
+	}
+	if !sb.observers.add(peer) {
+		sb.logger.Debug("Too many consensus observers, ignoring request", "peer", peer)
+	}
+}
+
+// forwardToObservers sends the consensus message to the observers, but the
+// peer it was received from, if any.
+func (sb *Backend) forwardToObservers(from consensus.Peer, payload []byte) {
+	if !sb.config.ServeObservers {
+		return
+	}
+	var except enode.ID
+	if from != nil {
+		except = from.Node().ID()
+	}
+	if targets := sb.observers.targets(except); len(targets) > 0 {
+		sb.asyncMulticast(targets, payload, istanbul.ConsensusMsg)
+	}
+}
+
+// observeConsensusMsg records the consensus message if signed by a validator
+// and part of a sequence not agreed on yet.
+func (sb *Backend) observeConsensusMsg(peer consensus.Peer, payload []byte) {
+	msg := new(istanbul.Message)
+	if err := msg.FromPayloadInDomains(payload, sb.SigningDomains().Verify, sb.VerifyPendingBlockValidatorSignature); err != nil {
+		sb.logger.Debug("Discarding observed consensus message", "peer", peer, "err", err)
+		return
+	}
+	observed := &ObservedMessage{
+		Sender:   msg.Address,
+		Peer:     peer.Node().ID().String(),
+		Received: time.Now().UnixNano() / int64(time.Millisecond),
+	}
+	var view *istanbul.View
+	switch msg.Code {
+	case istanbul.MsgPreprepare:
+		preprepare := msg.Preprepare()
+		observed.Code, view, observed.Digest = "preprepare", preprepare.View, preprepare.Proposal.Hash()
+	case istanbul.MsgPrepare:
+		prepare := msg.Prepare()
+		observed.Code, view, observed.Digest = "prepare", prepare.View, prepare.Digest
+	case istanbul.MsgCommit:
+		commit := msg.Commit()
+		observed.Code, view, observed.Digest = "commit", commit.Subject.View, commit.Subject.Digest
+	case istanbul.MsgRoundChange:
+		roundChange := msg.RoundChange()
+		observed.Code, view = "roundChange", roundChange.View
+		if roundChange.HasPreparedCertificate() {
+			observed.Digest = roundChange.PreparedCertificate.Proposal.Hash()
+		}
+	default:
+		sb.logger.Debug("Discarding observed message of unknown code", "peer", peer, "code", msg.Code)
+		return
+	}
+	if view == nil || view.Sequence == nil || view.Round == nil {
+		sb.logger.Debug("Discarding observed consensus message without view", "peer", peer)
+		return
+	}
+	if view.Sequence.Cmp(sb.currentBlock().Number()) <= 0 {
+		return
+	}
+	observed.Sequence, observed.Round = view.Sequence, view.Round
+	sb.observed.add(observed)
+	observedMessagesMeter.Mark(1)
+}
+
+// ObservedMessages returns the consensus messages observed for the sequences
+// not agreed on yet.
+func (sb *Backend) ObservedMessages() ([]*ObservedMessage, error) {
+	if !sb.config.ObserveConsensus {
+		return nil, errNotObserving
+	}
+	return sb.observed.list(), nil
+}
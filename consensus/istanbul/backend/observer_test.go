@@ -0,0 +1,110 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/consensus"
+	"github.com/celo-org/celo-blockchain/consensus/istanbul"
+	"github.com/celo-org/celo-blockchain/crypto"
+	"github.com/celo-org/celo-blockchain/p2p/enode"
+)
+
+func TestServeConsensusObservers(t *testing.T) {
+	backend := newBackend()
+	backend.config.ServeObservers = true
+	backend.SetBroadcaster(&peersBroadcaster{peers: map[enode.ID]consensus.Peer{}})
+
+	var (
+		observer = newRecordingPeer()
+		payload  = []byte("consensus message")
+	)
+	backend.handleConsensusObserveMsg(observer)
+
+	// Consensus messages are forwarded once to the observers
+	backend.Multicast([]common.Address{backend.Address()}, payload, istanbul.ConsensusMsg, false)
+	if sent := waitForMessages(observer, istanbul.ConsensusMsg, 1); len(sent) != 1 || !bytes.Equal(sent[0], payload) {
+		t.Fatalf("consensus message not forwarded to the observer")
+	}
+	backend.Multicast([]common.Address{backend.Address()}, payload, istanbul.ConsensusMsg, false)
+	backend.forwardToObservers(observer, []byte("received from the observer"))
+
+	// Observers are dropped when disconnected
+	backend.UnregisterPeer(observer, false)
+	backend.Multicast([]common.Address{backend.Address()}, []byte("other consensus message"), istanbul.ConsensusMsg, false)
+
+	time.Sleep(50 * time.Millisecond)
+	if sent := observer.messages(istanbul.ConsensusMsg); len(sent) != 1 {
+		t.Errorf("forwarded messages mismatch: have %d, want 1", len(sent))
+	}
+}
+
+func TestObserveConsensus(t *testing.T) {
+	genesis, keys := getGenesisAndKeys(2, true)
+	_, backend, _ := newBlockChainWithKeys(false, common.Address{}, false, genesis, keys[0])
+
+	if _, err := backend.ObservedMessages(); err != errNotObserving {
+		t.Fatalf("error mismatch: have %v, want %v", err, errNotObserving)
+	}
+	backend.config.ObserveConsensus = true
+
+	prepare := func(key *ecdsa.PrivateKey, sequence int64) []byte {
+		subject := &istanbul.Subject{
+			View:   &istanbul.View{Round: big.NewInt(0), Sequence: big.NewInt(sequence)},
+			Digest: common.HexToHash("0x01"),
+		}
+		msg := istanbul.NewPrepareMessage(subject, crypto.PubkeyToAddress(key.PublicKey))
+		if err := msg.Sign(func(data []byte) ([]byte, error) { return crypto.Sign(crypto.Keccak256(data), key) }); err != nil {
+			t.Fatalf("failed to sign prepare: %v", err)
+		}
+		payload, _ := msg.Payload()
+		return payload
+	}
+	stranger, _ := crypto.GenerateKey()
+	peer := newRecordingPeer()
+
+	backend.observeConsensusMsg(peer, prepare(keys[1], 1))
+	backend.observeConsensusMsg(peer, prepare(keys[1], 0)) // Agreed on already
+	backend.observeConsensusMsg(peer, prepare(stranger, 1))
+
+	observed, err := backend.ObservedMessages()
+	if err != nil {
+		t.Fatalf("failed to retrieve observed messages: %v", err)
+	}
+	if len(observed) != 1 {
+		t.Fatalf("observed messages mismatch: have %d, want 1", len(observed))
+	}
+	msg := observed[0]
+	if msg.Code != "prepare" || msg.Sequence.Int64() != 1 || msg.Round.Int64() != 0 || msg.Sender != crypto.PubkeyToAddress(keys[1].PublicKey) || msg.Digest != common.HexToHash("0x01") {
+		t.Errorf("observed message mismatch: have %+v", msg)
+	}
+	if msg.Peer != peer.Node().ID().String() {
+		t.Errorf("observed message peer mismatch: have %s, want %s", msg.Peer, peer.Node().ID())
+	}
+
+	// Messages are dropped once their sequence is agreed on
+	backend.observed.prune(big.NewInt(1))
+	if observed, _ := backend.ObservedMessages(); len(observed) != 0 {
+		t.Errorf("observed messages not pruned: have %d", len(observed))
+	}
+}
@@ -33,6 +33,9 @@ type validatorPeerHandler struct {
 	threadRunningMu sync.RWMutex
 	threadWg        *sync.WaitGroup
 	threadQuit      chan struct{}
+
+	links   map[common.Address]*validatorLink // Health of the direct links to the other validators
+	linksMu sync.RWMutex
 }
 
 func newVPH(sb *Backend) *validatorPeerHandler {
@@ -40,6 +43,7 @@ func newVPH(sb *Backend) *validatorPeerHandler {
 		sb:         sb,
 		threadWg:   new(sync.WaitGroup),
 		threadQuit: make(chan struct{}),
+		links:      make(map[common.Address]*validatorLink),
 	}
 }
 
@@ -75,6 +79,7 @@ func (vph *validatorPeerHandler) thread() {
 	defer vph.threadWg.Done()
 
 	refreshValidatorPeersTicker := time.NewTicker(1 * time.Minute)
+	checkValidatorLinksTicker := time.NewTicker(validatorLinkCheckPeriod)
 
 	refreshValPeersFunc := func() {
 		if vph.MaintainValConnections() {
@@ -91,8 +96,14 @@ func (vph *validatorPeerHandler) thread() {
 		case <-refreshValidatorPeersTicker.C:
 			refreshValPeersFunc()
 
+		case <-checkValidatorLinksTicker.C:
+			if vph.MaintainValConnections() {
+				vph.checkValidatorLinks()
+			}
+
 		case <-vph.threadQuit:
 			refreshValidatorPeersTicker.Stop()
+			checkValidatorLinksTicker.Stop()
 			return
 		}
 	}
@@ -0,0 +1,101 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"errors"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/consensus/istanbul"
+	istanbulCore "github.com/celo-org/celo-blockchain/consensus/istanbul/core"
+	"github.com/celo-org/celo-blockchain/core/types"
+	blscrypto "github.com/celo-org/celo-blockchain/crypto/bls"
+	"github.com/celo-org/celo-bls-go/snark"
+)
+
+var errInvalidEpochRange = errors.New("last epoch precedes first epoch")
+
+// plumoEpochBlock assembles the snark circuit inputs of the given epoch from the
+// last block of the epoch, matching the epoch validator set data sealed in it.
+func (sb *Backend) plumoEpochBlock(epoch uint64) (*snark.EpochBlock, error) {
+	if epoch == 0 {
+		return nil, errors.New("the genesis epoch has no epoch block")
+	}
+	number := istanbul.GetEpochLastBlockNumber(epoch, sb.EpochSize())
+	header := sb.chain.GetHeaderByNumber(number)
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	extra, err := types.ExtractIstanbulExtra(header)
+	if err != nil {
+		return nil, err
+	}
+	// The validators elected for the next epoch are the ones after this block
+	valSet := sb.getValidators(number, header.Hash())
+	if valSet.Size() == 0 {
+		return nil, errUnknownBlock
+	}
+	validators := valSet.List()
+
+	// The circuit reads the keys from a single buffer
+	keys := make([]byte, len(validators)*snark.PUBLIC_KEY_BYTES)
+	block := &snark.EpochBlock{
+		Index:         uint16(epoch),
+		MaxNonSigners: uint32(valSet.Size() - valSet.MinQuorumSize()),
+		MaxValidators: uint(istanbulCore.MaxValidators),
+		PublicKeys:    make([][]byte, len(validators)),
+	}
+	for i, val := range validators {
+		key := val.BLSPublicKey()
+		block.PublicKeys[i] = keys[i*snark.PUBLIC_KEY_BYTES : (i+1)*snark.PUBLIC_KEY_BYTES]
+		copy(block.PublicKeys[i], key[:])
+	}
+	if extra.AggregatedSeal.Round != nil {
+		block.Round = uint8(extra.AggregatedSeal.Round.Uint64())
+	}
+	// Epochs before the Donut fork carry no entropy, see generateEpochValidatorSetData
+	if sb.ChainConfig().IsDonut(header.Number) {
+		parentHash := sb.HashForBlock(number - sb.EpochSize())
+		if parentHash == (common.Hash{}) {
+			return nil, errUnknownBlock
+		}
+		epochEntropy := blscrypto.EpochEntropyFromHash(header.Hash())
+		parentEntropy := blscrypto.EpochEntropyFromHash(parentHash)
+		block.EpochEntropy = epochEntropy[:]
+		block.ParentEntropy = parentEntropy[:]
+		block.MaxNonSigners = istanbulCore.MaxValidators - uint32(valSet.MinQuorumSize())
+	}
+	return block, nil
+}
+
+// verifyPlumoProof checks a Plumo proof of the validator set transitions from the
+// first to the last epoch against the epoch blocks stored in the chain. It
+// returns snark.VerificationError if the proof does not hold.
+func (sb *Backend) verifyPlumoProof(verifyingKey, proof []byte, firstEpoch, lastEpoch uint64) error {
+	if lastEpoch < firstEpoch {
+		return errInvalidEpochRange
+	}
+	first, err := sb.plumoEpochBlock(firstEpoch)
+	if err != nil {
+		return err
+	}
+	last, err := sb.plumoEpochBlock(lastEpoch)
+	if err != nil {
+		return err
+	}
+	return snark.VerifyEpochs(verifyingKey, proof, *first, *last)
+}
@@ -0,0 +1,52 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"testing"
+
+	"github.com/celo-org/celo-blockchain/common"
+	. "github.com/onsi/gomega"
+)
+
+func TestPlumoEpochBlock(t *testing.T) {
+	g := NewGomegaWithT(t)
+	genesisCfg, nodeKeys := getGenesisAndKeys(1, true)
+	chain, engine, _ := newBlockChainWithKeys(false, common.Address{}, false, genesisCfg, nodeKeys[0])
+	defer stopEngine(engine)
+	defer chain.Stop()
+
+	// the first epoch has not ended yet
+	_, err := engine.plumoEpochBlock(1)
+	g.Expect(err).Should(BeIdenticalTo(errUnknownBlock))
+
+	block := chain.Genesis()
+	for i := uint64(0); i < engine.EpochSize(); i++ {
+		block, err = makeBlock(nodeKeys, chain, engine, block)
+		g.Expect(err).ToNot(HaveOccurred())
+	}
+	epochBlock, err := engine.plumoEpochBlock(1)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(epochBlock.Index).Should(BeEquivalentTo(1))
+	g.Expect(epochBlock.PublicKeys).Should(HaveLen(1))
+	g.Expect(epochBlock.MaxNonSigners).Should(BeEquivalentTo(0))
+	// Donut is not activated in the test chain, so there is no entropy
+	g.Expect(epochBlock.EpochEntropy).Should(BeEmpty())
+
+	err = engine.verifyPlumoProof(nil, nil, 2, 1)
+	g.Expect(err).Should(BeIdenticalTo(errInvalidEpochRange))
+}
@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/common/hexutil"
 	"github.com/celo-org/celo-blockchain/consensus/istanbul"
 	"github.com/celo-org/celo-blockchain/consensus/istanbul/uptime"
 	"github.com/celo-org/celo-blockchain/consensus/istanbul/uptime/store"
@@ -40,48 +41,57 @@ import (
 	"github.com/celo-org/celo-blockchain/params"
 )
 
-func (sb *Backend) distributeEpochRewards(header *types.Header, state *state.StateDB) error {
+// distributeEpochRewards distributes the rewards of the epoch ending with the
+// given block and returns how they were split. The stable token rewards of the
+// validators are converted to CELO at the rate in the state of rateRunner, or
+// of the current block if nil.
+func (sb *Backend) distributeEpochRewards(header *types.Header, state *state.StateDB, rateRunner vm.EVMRunner) (*EpochRewards, error) {
 	start := time.Now()
 	defer sb.rewardDistributionTimer.UpdateSince(start)
 	logger := sb.logger.New("func", "Backend.distributeEpochPaymentsAndRewards", "blocknum", header.Number.Uint64())
 
 	vmRunner := sb.chain.NewEVMRunner(header, state)
+	rewards := new(EpochRewards)
 	// Check if reward distribution has been frozen and return early without error if it is.
 	if frozen, err := freezer.IsFrozen(vmRunner, params.EpochRewardsRegistryId); err != nil {
 		logger.Warn("Failed to determine if epoch rewards are frozen", "err", err)
 	} else if frozen {
 		logger.Debug("Epoch rewards are frozen, skipping distribution")
-		return nil
+		rewards.Frozen = true
+		return rewards, nil
 	}
 
 	// Get necessary Addresses First
 	reserveAddress, err := contracts.GetRegisteredAddress(vmRunner, params.ReserveRegistryId)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	stableTokenAddress, err := contracts.GetRegisteredAddress(vmRunner, params.StableTokenRegistryId)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	carbonOffsettingPartnerAddress, err := epoch_rewards.GetCarbonOffsettingPartnerAddress(vmRunner)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	err = epoch_rewards.UpdateTargetVotingYield(vmRunner)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	validatorReward, totalVoterRewards, communityReward, carbonOffsettingPartnerReward, err := epoch_rewards.CalculateTargetEpochRewards(vmRunner)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if carbonOffsettingPartnerAddress == common.ZeroAddress {
 		carbonOffsettingPartnerReward = big.NewInt(0)
 	}
+	rewards.TargetValidatorReward = (*hexutil.Big)(validatorReward)
+	rewards.TargetVoterRewards = (*hexutil.Big)(totalVoterRewards)
+	rewards.CarbonOffsetting = &EpochRewardPayment{Recipient: carbonOffsettingPartnerAddress, Amount: (*hexutil.Big)(carbonOffsettingPartnerReward)}
 
 	logger.Debug("Calculated target rewards", "validatorReward", validatorReward, "totalVoterRewards", totalVoterRewards, "communityReward", communityReward)
 
@@ -92,52 +102,61 @@ func (sb *Backend) distributeEpochRewards(header *types.Header, state *state.Sta
 
 		err := errors.New("Unable to fetch validator set to update scores and distribute rewards")
 		logger.Error(err.Error())
-		return err
+		return nil, err
 	}
 
 	uptimes, err := sb.updateValidatorScores(header, state, valSet)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	totalValidatorRewards, err := sb.distributeValidatorRewards(vmRunner, valSet, validatorReward)
+	validatorPayments, totalValidatorRewards, err := sb.distributeValidatorRewards(vmRunner, valSet, validatorReward)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	rewards.ValidatorPayments = validatorPayments
+	rewards.TotalValidatorPayments = (*hexutil.Big)(totalValidatorRewards)
 
 	// TODO(HF) Use vmRunner instead of current block's one
-	currentBlockVMRunner, err := sb.chain.NewEVMRunnerForCurrentBlock()
-	if err != nil {
-		return err
+	if rateRunner == nil {
+		if rateRunner, err = sb.chain.NewEVMRunnerForCurrentBlock(); err != nil {
+			return nil, err
+		}
 	}
-	currencyManager := currency.NewManager(currentBlockVMRunner)
+	currencyManager := currency.NewManager(rateRunner)
 
 	// Validator rewards were paid in cUSD, convert that amount to CELO and add it to the Reserve
 	stableTokenCurrency, err := currencyManager.GetCurrency(&stableTokenAddress)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	totalValidatorRewardsConvertedToCelo := stableTokenCurrency.ToCELO(totalValidatorRewards)
+	rewards.StableToken = stableTokenAddress
+	rate := stableTokenCurrency.Rate()
+	rewards.ExchangeRate = &EpochRewardsRate{Numerator: (*hexutil.Big)(rate.Numerator()), Denominator: (*hexutil.Big)(rate.Denominator())}
+	rewards.TotalValidatorPaymentsInCelo = (*hexutil.Big)(totalValidatorRewardsConvertedToCelo)
 
 	if err = gold_token.Mint(vmRunner, reserveAddress, totalValidatorRewardsConvertedToCelo); err != nil {
-		return err
+		return nil, err
 	}
 
-	if err := sb.distributeCommunityRewards(vmRunner, communityReward); err != nil {
-		return err
+	if rewards.CommunityFund, err = sb.distributeCommunityRewards(vmRunner, communityReward); err != nil {
+		return nil, err
 	}
 
-	if err := sb.distributeVoterRewards(vmRunner, valSet, totalVoterRewards, uptimes); err != nil {
-		return err
+	voterRewards, err := sb.distributeVoterRewards(vmRunner, valSet, totalVoterRewards, uptimes)
+	if err != nil {
+		return nil, err
 	}
+	rewards.VoterRewards = (*hexutil.Big)(voterRewards)
 
 	if carbonOffsettingPartnerReward.Cmp(new(big.Int)) != 0 {
 		if err = gold_token.Mint(vmRunner, carbonOffsettingPartnerAddress, carbonOffsettingPartnerReward); err != nil {
-			return err
+			return nil, err
 		}
 	}
 
-	return nil
+	return rewards, nil
 }
 
 func (sb *Backend) updateValidatorScores(header *types.Header, state *state.StateDB, valSet []istanbul.Validator) ([]*big.Int, error) {
@@ -170,7 +189,8 @@ func (sb *Backend) updateValidatorScores(header *types.Header, state *state.Stat
 	return uptimes, nil
 }
 
-func (sb *Backend) distributeValidatorRewards(vmRunner vm.EVMRunner, valSet []istanbul.Validator, maxReward *big.Int) (*big.Int, error) {
+func (sb *Backend) distributeValidatorRewards(vmRunner vm.EVMRunner, valSet []istanbul.Validator, maxReward *big.Int) ([]*EpochRewardPayment, *big.Int, error) {
+	var payments []*EpochRewardPayment
 	totalValidatorRewards := big.NewInt(0)
 	for _, val := range valSet {
 		sb.logger.Debug("Distributing epoch reward for validator", "address", val.Address())
@@ -179,41 +199,46 @@ func (sb *Backend) distributeValidatorRewards(vmRunner vm.EVMRunner, valSet []is
 			sb.logger.Error("Error in distributing rewards to validator", "address", val.Address(), "err", err)
 			continue
 		}
+		payments = append(payments, &EpochRewardPayment{Recipient: val.Address(), Amount: (*hexutil.Big)(validatorReward)})
 		totalValidatorRewards.Add(totalValidatorRewards, validatorReward)
 	}
-	return totalValidatorRewards, nil
+	return payments, totalValidatorRewards, nil
 }
 
-func (sb *Backend) distributeCommunityRewards(vmRunner vm.EVMRunner, communityReward *big.Int) error {
+func (sb *Backend) distributeCommunityRewards(vmRunner vm.EVMRunner, communityReward *big.Int) (*EpochRewardPayment, error) {
 	governanceAddress, err := contracts.GetRegisteredAddress(vmRunner, params.GovernanceRegistryId)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	reserveAddress, err := contracts.GetRegisteredAddress(vmRunner, params.ReserveRegistryId)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	lowReserve, err := epoch_rewards.IsReserveLow(vmRunner)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	var recipient common.Address
 	if lowReserve && reserveAddress != common.ZeroAddress {
-		return gold_token.Mint(vmRunner, reserveAddress, communityReward)
+		recipient = reserveAddress
 	} else if governanceAddress != common.ZeroAddress {
 		// TODO: How to split eco fund here
-		return gold_token.Mint(vmRunner, governanceAddress, communityReward)
+		recipient = governanceAddress
+	} else {
+		return &EpochRewardPayment{Amount: new(hexutil.Big)}, nil
 	}
-	return nil
+	payment := &EpochRewardPayment{Recipient: recipient, Amount: (*hexutil.Big)(communityReward)}
+	return payment, gold_token.Mint(vmRunner, recipient, communityReward)
 }
 
-func (sb *Backend) distributeVoterRewards(vmRunner vm.EVMRunner, valSet []istanbul.Validator, maxTotalRewards *big.Int, uptimes []*big.Int) error {
+func (sb *Backend) distributeVoterRewards(vmRunner vm.EVMRunner, valSet []istanbul.Validator, maxTotalRewards *big.Int, uptimes []*big.Int) (*big.Int, error) {
 
 	lockedGoldAddress, err := contracts.GetRegisteredAddress(vmRunner, params.LockedGoldRegistryId)
 	if err != nil {
-		return err
+		return nil, err
 	} else if lockedGoldAddress == common.ZeroAddress {
-		return errors.New("Unable to fetch locked gold address for epoch rewards distribution")
+		return nil, errors.New("Unable to fetch locked gold address for epoch rewards distribution")
 	}
 
 	// Select groups that elected at least one validator aggregate their uptimes.
@@ -223,7 +248,7 @@ func (sb *Backend) distributeVoterRewards(vmRunner vm.EVMRunner, valSet []istanb
 	for i, val := range valSet {
 		group, err := validators.GetMembershipInLastEpoch(vmRunner, val.Address())
 		if err != nil {
-			return err
+			return nil, err
 		}
 		if _, ok := groupElectedValidator[group]; !ok {
 			groups = append(groups, group)
@@ -235,10 +260,10 @@ func (sb *Backend) distributeVoterRewards(vmRunner vm.EVMRunner, valSet []istanb
 
 	electionRewards, err := election.DistributeEpochRewards(vmRunner, groups, maxTotalRewards, groupUptimes)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return gold_token.Mint(vmRunner, lockedGoldAddress, electionRewards)
+	return electionRewards, gold_token.Mint(vmRunner, lockedGoldAddress, electionRewards)
 }
 
 func (sb *Backend) setInitialGoldTokenTotalSupplyIfUnset(vmRunner vm.EVMRunner) error {
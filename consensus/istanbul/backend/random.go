@@ -17,14 +17,60 @@
 package backend
 
 import (
+	"errors"
+
 	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/common/hexutil"
 	"github.com/celo-org/celo-blockchain/contracts/random"
+	"github.com/celo-org/celo-blockchain/core/types"
 	"github.com/celo-org/celo-blockchain/crypto"
 )
 
 // String for creating the random seed
 var randomSeedString = []byte("Randomness seed string")
 
+var errRandomNotRunning = errors.New("random contract not deployed")
+
+// BlockRandomness is the randomness of a block in the commit/reveal flow of the
+// Random contract.
+type BlockRandomness struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	BlockHash   common.Hash    `json:"blockHash"`
+	Proposer    common.Address `json:"proposer"`
+	Revealed    common.Hash    `json:"revealed"`   // Randomness revealed by the proposer
+	Committed   common.Hash    `json:"committed"`  // Commitment to the proposer's next randomness
+	Randomness  common.Hash    `json:"randomness"` // Randomness of the Random contract after the block
+}
+
+// blockRandomness retrieves the randomness of the given block. The state of the
+// block must be available.
+func (sb *Backend) blockRandomness(block *types.Block) (*BlockRandomness, error) {
+	state, err := sb.stateAt(block.Hash())
+	if err != nil {
+		return nil, err
+	}
+	vmRunner := sb.chain.NewEVMRunner(block.Header(), state)
+	if !random.IsRunning(vmRunner) {
+		return nil, errRandomNotRunning
+	}
+	randomness, err := random.Random(vmRunner)
+	if err != nil {
+		return nil, err
+	}
+	proposer, err := sb.Author(block.Header())
+	if err != nil {
+		return nil, err
+	}
+	return &BlockRandomness{
+		BlockNumber: hexutil.Uint64(block.NumberU64()),
+		BlockHash:   block.Hash(),
+		Proposer:    proposer,
+		Revealed:    block.Randomness().Revealed,
+		Committed:   block.Randomness().Committed,
+		Randomness:  randomness,
+	}, nil
+}
+
 // GenerateRandomness will generate the random beacon randomness
 func (sb *Backend) GenerateRandomness(parentHash common.Hash) (common.Hash, common.Hash, error) {
 	logger := sb.logger.New("func", "GenerateRandomness")
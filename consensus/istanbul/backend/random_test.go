@@ -0,0 +1,141 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/core"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/rpc"
+)
+
+var (
+	testRandomAddress = common.HexToAddress("0xface")
+	testRandomness    = common.HexToHash("0x0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef")
+)
+
+// newRandomTest returns a single validator chain with a Random contract pinned
+// at genesis, whose every method returns testRandomness, along with its engine
+// served over an in-process client.
+func newRandomTest(t *testing.T) (*core.BlockChain, *Backend, *rpc.Client, func()) {
+	genesis, keys := getGenesisAndKeys(1, true)
+	config := *genesis.Config
+	config.CoreContracts = map[string]common.Address{"Random": testRandomAddress}
+	genesis.Config = &config
+
+	// PUSH32 testRandomness PUSH1 0 MSTORE RETURN(0, 32)
+	code := append(append([]byte{0x7f}, testRandomness.Bytes()...), common.FromHex("0x60005260206000f3")...)
+	genesis.Alloc[testRandomAddress] = core.GenesisAccount{Code: code, Balance: common.Big0}
+	chain, engine, _ := newBlockChainWithKeys(false, common.Address{}, false, genesis, keys[0])
+
+	server := rpc.NewServer()
+	for _, api := range engine.APIs(chain) {
+		if err := server.RegisterName(api.Namespace, api.Service); err != nil {
+			t.Fatal(err)
+		}
+	}
+	client := rpc.DialInProc(server)
+	return chain, engine, client, func() {
+		client.Close()
+		server.Stop()
+		stopEngine(engine)
+		chain.Stop()
+	}
+}
+
+// checkRandomness checks the randomness served for the given block.
+func checkRandomness(t *testing.T, engine *Backend, block *types.Block, randomness *BlockRandomness) {
+	t.Helper()
+	if uint64(randomness.BlockNumber) != block.NumberU64() || randomness.BlockHash != block.Hash() {
+		t.Errorf("block %d: randomness of block %d %x", block.NumberU64(), randomness.BlockNumber, randomness.BlockHash)
+	}
+	if randomness.Proposer != engine.Address() {
+		t.Errorf("block %d: proposer mismatch: have %x, want %x", block.NumberU64(), randomness.Proposer, engine.Address())
+	}
+	if randomness.Revealed != block.Randomness().Revealed || randomness.Committed != block.Randomness().Committed {
+		t.Errorf("block %d: header randomness mismatch: have %x/%x, want %x/%x", block.NumberU64(),
+			randomness.Revealed, randomness.Committed, block.Randomness().Revealed, block.Randomness().Committed)
+	}
+	if randomness.Randomness != testRandomness {
+		t.Errorf("block %d: contract randomness mismatch: have %x, want %x", block.NumberU64(), randomness.Randomness, testRandomness)
+	}
+}
+
+// Tests that the randomness of each block appended to the chain is sent to
+// subscribers, and served by number afterwards.
+func TestRandomness(t *testing.T) {
+	chain, engine, client, stop := newRandomTest(t)
+	defer stop()
+
+	notifications := make(chan *BlockRandomness, 16)
+	sub, err := client.Subscribe(context.Background(), "celo", notifications, "randomness")
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	blocks := []*types.Block{chain.Genesis()}
+	for i := 1; i <= 2; i++ {
+		block, err := makeBlock(nil, chain, engine, blocks[i-1])
+		if err != nil {
+			t.Fatalf("failed to make block %d: %v", i, err)
+		}
+		blocks = append(blocks, block)
+
+		select {
+		case randomness := <-notifications:
+			checkRandomness(t, engine, block, randomness)
+		case err := <-sub.Err():
+			t.Fatalf("subscription failed: %v", err)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("block %d: randomness not sent", i)
+		}
+	}
+	// The randomness is served for any block, or the current one by default
+	for _, block := range blocks[1:] {
+		var randomness *BlockRandomness
+		if err := client.Call(&randomness, "celo_getRandomness", rpc.BlockNumber(block.NumberU64())); err != nil {
+			t.Fatalf("block %d: failed to get randomness: %v", block.NumberU64(), err)
+		}
+		checkRandomness(t, engine, block, randomness)
+	}
+	var randomness *BlockRandomness
+	if err := client.Call(&randomness, "celo_getRandomness"); err != nil {
+		t.Fatalf("failed to get current randomness: %v", err)
+	}
+	checkRandomness(t, engine, blocks[2], randomness)
+
+	if err := client.Call(&randomness, "celo_getRandomness", rpc.BlockNumber(3)); err == nil {
+		t.Errorf("served randomness of unknown block")
+	}
+}
+
+// Tests that no randomness is served without the Random contract.
+func TestRandomnessNotRunning(t *testing.T) {
+	chain, engine := newBlockChain(1, true)
+	defer stopEngine(engine)
+	defer chain.Stop()
+
+	api := &CeloAPI{chain: chain, istanbul: engine}
+	if _, err := api.GetRandomness(nil); err != errRandomNotRunning {
+		t.Errorf("error mismatch: have %v, want %v", err, errRandomNotRunning)
+	}
+}
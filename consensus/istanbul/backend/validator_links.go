@@ -0,0 +1,239 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/consensus"
+	"github.com/celo-org/celo-blockchain/consensus/istanbul"
+	"github.com/celo-org/celo-blockchain/metrics"
+	"github.com/celo-org/celo-blockchain/p2p"
+	"github.com/celo-org/celo-blockchain/p2p/enode"
+)
+
+// The validator peer handler keeps direct links with the validators of the
+// current and next epochs. Their health is checked periodically, and the ones
+// down for a few checks in a row are dialed back. Consensus messages for a
+// validator without a direct link may be relayed by other validators.
+
+const (
+	// validatorLinkCheckPeriod is the interval between health checks of the
+	// direct links to the other validators.
+	validatorLinkCheckPeriod = 15 * time.Second
+
+	// maxValidatorLinkFailures is the number of failed health checks in a row
+	// after which a validator is dialed back.
+	maxValidatorLinkFailures = 2
+
+	// validatorRelayFanout is the number of validators asked to relay a consensus
+	// message to the validators without a direct link.
+	validatorRelayFanout = 3
+)
+
+var (
+	validatorLinksUpGauge   = metrics.NewRegisteredGauge("consensus/istanbul/backend/validatorlinks/up", nil)
+	validatorLinksDownGauge = metrics.NewRegisteredGauge("consensus/istanbul/backend/validatorlinks/down", nil)
+	validatorRedialMeter    = metrics.NewRegisteredMeter("consensus/istanbul/backend/validatorlinks/redial", nil)
+)
+
+// validatorLink is the health of the direct link to another validator.
+type validatorLink struct {
+	node          *enode.Node
+	connected     bool
+	lastConnected time.Time
+	failures      int    // Failed health checks in a row
+	redials       uint64 // Number of times the validator was dialed back
+}
+
+// ValidatorLinkInfo gives basic information on the direct link to a validator.
+type ValidatorLinkInfo struct {
+	Enode         string `json:"enode"`
+	Connected     bool   `json:"connected"`
+	LastConnected string `json:"lastConnected"` // Unix timestamp
+	Failures      int    `json:"failures"`
+	Redials       uint64 `json:"redials"`
+}
+
+// checkValidatorLinks checks the direct links to the other validators of the
+// validator connection set, and dials back the ones down for too long.
+func (vph *validatorPeerHandler) checkValidatorLinks() {
+	logger := vph.sb.logger.New("func", "checkValidatorLinks")
+
+	valConnSet, err := vph.sb.RetrieveValidatorConnSet()
+	if err != nil {
+		logger.Warn("Error in retrieving val conn set", "err", err)
+		return
+	}
+	links := make(map[common.Address]*validatorLink)
+	if !valConnSet[vph.sb.ValidatorAddress()] {
+		vph.setValidatorLinks(links)
+		return
+	}
+	connected := vph.sb.broadcaster.FindPeers(nil, p2p.ValidatorPurpose)
+
+	vph.linksMu.Lock()
+	defer vph.linksMu.Unlock()
+
+	var up, down int64
+	for addr := range valConnSet {
+		if addr == vph.sb.ValidatorAddress() {
+			continue
+		}
+		// Validators not announced yet can't be dialed
+		node, err := vph.sb.valEnodeTable.GetNodeFromAddress(addr)
+		if err != nil || node == nil {
+			continue
+		}
+		link := vph.links[addr]
+		if link == nil || link.node.ID() != node.ID() {
+			link = &validatorLink{node: node}
+		}
+		links[addr] = link
+
+		if _, ok := connected[node.ID()]; ok {
+			link.connected, link.lastConnected, link.failures = true, time.Now(), 0
+			up++
+			continue
+		}
+		link.connected = false
+		link.failures++
+		down++
+		if link.failures >= maxValidatorLinkFailures {
+			// Removing the peer first resets its dial backoff
+			logger.Debug("Dialing back validator", "address", addr, "enode", node)
+			vph.RemoveValidatorPeer(node)
+			vph.sb.p2pserver.AddPeer(node, p2p.ValidatorPurpose)
+			vph.sb.p2pserver.AddTrustedPeer(node, p2p.ValidatorPurpose)
+			link.failures = 0
+			link.redials++
+			validatorRedialMeter.Mark(1)
+		}
+	}
+	vph.links = links
+	validatorLinksUpGauge.Update(up)
+	validatorLinksDownGauge.Update(down)
+}
+
+func (vph *validatorPeerHandler) setValidatorLinks(links map[common.Address]*validatorLink) {
+	vph.linksMu.Lock()
+	defer vph.linksMu.Unlock()
+
+	vph.links = links
+	validatorLinksUpGauge.Update(0)
+	validatorLinksDownGauge.Update(0)
+}
+
+// ValidatorLinksInfo gives basic information on the direct link to each of the
+// other validators.
+func (vph *validatorPeerHandler) ValidatorLinksInfo() map[string]*ValidatorLinkInfo {
+	vph.linksMu.RLock()
+	defer vph.linksMu.RUnlock()
+
+	info := make(map[string]*ValidatorLinkInfo, len(vph.links))
+	for addr, link := range vph.links {
+		linkInfo := &ValidatorLinkInfo{
+			Enode:     link.node.String(),
+			Connected: link.connected,
+			Failures:  link.failures,
+			Redials:   link.redials,
+		}
+		if !link.lastConnected.IsZero() {
+			linkInfo.LastConnected = strconv.FormatInt(link.lastConnected.Unix(), 10)
+		}
+		info[addr.Hex()] = linkInfo
+	}
+	return info
+}
+
+// unlinkedValidators returns the destination validators, other than this one,
+// which none of the destination peers is a link to.
+func (sb *Backend) unlinkedValidators(destAddresses []common.Address, destPeers map[enode.ID]consensus.Peer) []common.Address {
+	var unlinked []common.Address
+	for _, addr := range destAddresses {
+		if addr == sb.Address() {
+			continue
+		}
+		if node, err := sb.valEnodeTable.GetNodeFromAddress(addr); err == nil && node != nil {
+			if _, ok := destPeers[node.ID()]; ok {
+				continue
+			}
+		}
+		unlinked = append(unlinked, addr)
+	}
+	return unlinked
+}
+
+// requestConsensusRelay asks a few linked validators to relay the consensus
+// message to the validators this node has no direct link to.
+func (sb *Backend) requestConsensusRelay(destAddresses []common.Address, payload []byte) error {
+	msg := istanbul.NewForwardMessage(&istanbul.ForwardMessage{
+		Code:          istanbul.ConsensusMsg,
+		DestAddresses: destAddresses,
+		Msg:           payload,
+	}, sb.Address())
+	if err := msg.SignInDomain(sb.SigningDomains().Sign, sb.Sign); err != nil {
+		return err
+	}
+	relayPayload, err := msg.Payload()
+	if err != nil {
+		return err
+	}
+	relayers := make(map[enode.ID]consensus.Peer, validatorRelayFanout)
+	for id, peer := range sb.broadcaster.FindPeers(nil, p2p.ValidatorPurpose) {
+		if len(relayers) == validatorRelayFanout {
+			break
+		}
+		relayers[id] = peer
+	}
+	sb.logger.Trace("Requesting consensus message relay", "destAddresses", common.ConvertToStringSlice(destAddresses), "relayers", len(relayers))
+	sb.asyncMulticast(relayers, relayPayload, istanbul.ConsensusRelayMsg)
+	return nil
+}
+
+// handleConsensusRelayMsg relays the consensus message of another validator to
+// the destination validators this node has a direct link to.
+func (sb *Backend) handleConsensusRelayMsg(payload []byte) {
+	logger := sb.logger.New("func", "handleConsensusRelayMsg")
+
+	msg := new(istanbul.Message)
+	if err := msg.FromPayloadInDomains(payload, sb.SigningDomains().Verify, sb.VerifyPendingBlockValidatorSignature); err != nil {
+		logger.Debug("Failed to decode consensus relay message", "err", err)
+		return
+	}
+	fwdMsg := msg.ForwardMessage()
+	if fwdMsg == nil || fwdMsg.Code != istanbul.ConsensusMsg {
+		logger.Debug("Discarding invalid consensus relay message", "from", msg.Address)
+		return
+	}
+	destAddresses := make([]common.Address, 0, len(fwdMsg.DestAddresses))
+	for _, addr := range fwdMsg.DestAddresses {
+		if addr != sb.Address() && addr != msg.Address {
+			destAddresses = append(destAddresses, addr)
+		}
+	}
+	// An empty destination list would find all the peers
+	if len(destAddresses) == 0 {
+		return
+	}
+	if destPeers := sb.getPeersFromDestAddresses(destAddresses); len(destPeers) > 0 {
+		logger.Trace("Relaying consensus message", "from", msg.Address, "recipients", len(destPeers))
+		sb.asyncMulticast(destPeers, fwdMsg.Msg, istanbul.ConsensusMsg)
+	}
+}
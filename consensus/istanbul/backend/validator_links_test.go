@@ -0,0 +1,135 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"bytes"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/consensus"
+	"github.com/celo-org/celo-blockchain/consensus/istanbul"
+	"github.com/celo-org/celo-blockchain/crypto"
+	"github.com/celo-org/celo-blockchain/p2p"
+	"github.com/celo-org/celo-blockchain/p2p/enode"
+)
+
+// recordingPeer records the messages sent to it.
+type recordingPeer struct {
+	node *enode.Node
+
+	mu   sync.Mutex
+	sent map[uint64][][]byte
+}
+
+func (p *recordingPeer) Send(msgCode uint64, data interface{}) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sent[msgCode] = append(p.sent[msgCode], data.([]byte))
+	return nil
+}
+
+func (p *recordingPeer) messages(msgCode uint64) [][]byte {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.sent[msgCode]
+}
+
+func (p *recordingPeer) Node() *enode.Node                         { return p.node }
+func (p *recordingPeer) Version() int                              { return istanbul.Celo67 }
+func (p *recordingPeer) ReadMsg() (p2p.Msg, error)                 { return p2p.Msg{}, nil }
+func (p *recordingPeer) Inbound() bool                             { return false }
+func (p *recordingPeer) PurposeIsSet(purpose p2p.PurposeFlag) bool { return true }
+
+// peersBroadcaster finds peers among a fixed set.
+type peersBroadcaster struct {
+	peers map[enode.ID]consensus.Peer
+}
+
+func (b *peersBroadcaster) FindPeers(targets map[enode.ID]bool, purpose p2p.PurposeFlag) map[enode.ID]consensus.Peer {
+	found := make(map[enode.ID]consensus.Peer)
+	for id, peer := range b.peers {
+		if targets == nil || targets[id] {
+			found[id] = peer
+		}
+	}
+	return found
+}
+
+func TestConsensusRelay(t *testing.T) {
+	genesis, keys := getGenesisAndKeys(3, true)
+	chain, backend, _ := newBlockChainWithKeys(false, common.Address{}, false, genesis, keys[0])
+	defer chain.Stop()
+
+	var (
+		author    = crypto.PubkeyToAddress(keys[1].PublicKey)
+		linked    = crypto.PubkeyToAddress(keys[2].PublicKey)
+		unlinked  = common.BigToAddress(common.Big3)
+		linkedKey = keys[2].PublicKey
+		peer      = &recordingPeer{
+			node: enode.NewV4(&linkedKey, net.IP{127, 0, 0, 1}, 30303, 30303),
+			sent: make(map[uint64][][]byte),
+		}
+	)
+	if err := backend.valEnodeTable.UpsertVersionAndEnode([]*istanbul.AddressEntry{{Address: linked, Node: peer.node, Version: 1}}); err != nil {
+		t.Fatalf("failed to add validator enode: %v", err)
+	}
+	backend.SetBroadcaster(&peersBroadcaster{peers: map[enode.ID]consensus.Peer{peer.node.ID(): peer}})
+
+	destPeers := backend.getPeersFromDestAddresses([]common.Address{linked, unlinked})
+	if have := backend.unlinkedValidators([]common.Address{backend.Address(), linked, unlinked}, destPeers); len(have) != 1 || have[0] != unlinked {
+		t.Fatalf("unlinked validators mismatch: have %v, want %v", have, []common.Address{unlinked})
+	}
+
+	// Relay requests of validators are relayed to the linked destinations only
+	payload := []byte("consensus message")
+	msg := istanbul.NewForwardMessage(&istanbul.ForwardMessage{
+		Code:          istanbul.ConsensusMsg,
+		DestAddresses: []common.Address{linked, unlinked},
+		Msg:           payload,
+	}, author)
+	if err := msg.Sign(func(data []byte) ([]byte, error) { return crypto.Sign(crypto.Keccak256(data), keys[1]) }); err != nil {
+		t.Fatalf("failed to sign relay message: %v", err)
+	}
+	relayPayload, _ := msg.Payload()
+	backend.handleConsensusRelayMsg(relayPayload)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(peer.messages(istanbul.ConsensusMsg)) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if relayed := peer.messages(istanbul.ConsensusMsg); len(relayed) != 1 || !bytes.Equal(relayed[0], payload) {
+		t.Fatalf("relayed messages mismatch: have %d", len(relayed))
+	}
+
+	// Relay requests of non validators are ignored
+	stranger, _ := crypto.GenerateKey()
+	msg = istanbul.NewForwardMessage(msg.ForwardMessage(), crypto.PubkeyToAddress(stranger.PublicKey))
+	if err := msg.Sign(func(data []byte) ([]byte, error) { return crypto.Sign(crypto.Keccak256(data), stranger) }); err != nil {
+		t.Fatalf("failed to sign relay message: %v", err)
+	}
+	relayPayload, _ = msg.Payload()
+	backend.handleConsensusRelayMsg(relayPayload)
+
+	time.Sleep(50 * time.Millisecond)
+	if relayed := peer.messages(istanbul.ConsensusMsg); len(relayed) != 1 {
+		t.Errorf("relay request of non validator relayed: have %d messages", len(relayed))
+	}
+}
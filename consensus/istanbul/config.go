@@ -54,6 +54,7 @@ type Config struct {
 	RoundStateDBPath            string         `toml:",omitempty"` // The location for the round states DB
 	Validator                   bool           `toml:",omitempty"` // Specified if this node is configured to validate  (specifically if --mine command line is set)
 	Replica                     bool           `toml:",omitempty"` // Specified if this node is configured to be a replica
+	OptimisticAnnounce          bool           `toml:",omitempty"` // Specifies if blocks should be announced to peers as soon as they reach commit quorum, before being imported
 
 	// Proxy Configs
 	Proxy                   bool           `toml:",omitempty"` // Specifies if this node is a proxy
@@ -68,6 +69,22 @@ type Config struct {
 	AnnounceAggressiveQueryEnodeGossipOnEnablement bool   `toml:",omitempty"` // Specifies if this node should aggressively query enodes on announce enablement
 	AnnounceAdditionalValidatorsToGossip           int64  `toml:",omitempty"` // Specifies the number of additional non-elected validators to gossip an announce
 
+	// Chunked propagation configs
+	ChunkThreshold uint64 `toml:",omitempty"` // Size in bytes from which consensus messages are erasure coded among the validators, 0 to disable
+
+	// Announce and pull configs
+	AnnouncePullThreshold uint64 `toml:",omitempty"` // Size in bytes from which consensus messages are announced to the peers, which pull them, 0 to disable
+
+	// Known messages configs
+	DedupCacheSize uint64 `toml:",omitempty"` // Memory budget in megabytes of the caches of the messages known by this node and its peers
+
+	// Validator link configs
+	ValidatorLinkRelay bool `toml:",omitempty"` // Specifies if consensus messages to validators without a direct link should be relayed by other validators
+
+	// Consensus observer configs
+	ObserveConsensus bool `toml:",omitempty"` // Specifies if this node should ask its peers for the consensus messages and record them
+	ServeObservers   bool `toml:",omitempty"` // Specifies if the consensus messages seen by this node should be forwarded to the peers observing them
+
 	// Load test config
 	LoadTestCSVFile string `toml:",omitempty"` // If non-empty, specifies the file to write out csv metrics about the block production cycle to.
 }
@@ -94,11 +111,18 @@ var DefaultConfig = &Config{
 	RoundStateDBPath:               "roundstates",
 	Validator:                      false,
 	Replica:                        false,
+	OptimisticAnnounce:             false,
 	Proxy:                          false,
 	Proxied:                        false,
 	AnnounceQueryEnodeGossipPeriod: 300, // 5 minutes
 	AnnounceAggressiveQueryEnodeGossipOnEnablement: true,
 	AnnounceAdditionalValidatorsToGossip:           10,
+	ChunkThreshold:                                 0, // disable by default
+	AnnouncePullThreshold:                          0, // disable by default
+	DedupCacheSize:                                 8,
+	ValidatorLinkRelay:                             false,
+	ObserveConsensus:                               false,
+	ServeObservers:                                 false,
 	LoadTestCSVFile:                                "", // disable by default
 }
 
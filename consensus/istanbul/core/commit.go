@@ -27,10 +27,10 @@ import (
 	blscrypto "github.com/celo-org/celo-blockchain/crypto/bls"
 )
 
-// maxValidators represents the maximum number of validators the SNARK circuit supports
+// MaxValidators represents the maximum number of validators the SNARK circuit supports
 // The prover code will then pad any proofs to this maximum to ensure consistent proof structure
 // TODO: Eventually make this governable
-const maxValidators = uint32(150)
+const MaxValidators = uint32(150)
 
 func (c *core) sendCommit() {
 	logger := c.newLogger("func", "sendCommit")
@@ -79,9 +79,9 @@ func (c *core) generateEpochValidatorSetData(blockNumber uint64, round uint8, bl
 		return nil, nil, false, errors.New("unknown block")
 	}
 
-	maxNonSigners = maxValidators - uint32(newValSet.MinQuorumSize())
+	maxNonSigners = MaxValidators - uint32(newValSet.MinQuorumSize())
 	message, extraData, err := blscrypto.EncodeEpochSnarkDataCIP22(
-		blsPubKeys, maxNonSigners, maxValidators,
+		blsPubKeys, maxNonSigners, MaxValidators,
 		uint16(istanbul.GetEpochNumber(blockNumber, c.config.Epoch)),
 		round,
 		blscrypto.EpochEntropyFromHash(blockHash),
@@ -113,12 +113,17 @@ func (c *core) broadcastCommit(sub *istanbul.Subject) {
 	}
 	var epochValidatorSetSeal blscrypto.SerializedSignature
 	if err == nil {
-		epochValidatorSetSeal, err = c.backend.SignBLS(epochValidatorSetData, epochValidatorSetExtraData, true, cip22)
-		if err != nil {
-			logger.Error("Failed to sign epoch validator set seal", "err", err)
+		var signed bool
+		if epochValidatorSetSeal, signed = c.signEpochValidatorSetSeal(sub, committedSeal, epochValidatorSetData, epochValidatorSetExtraData, cip22); !signed {
 			return
 		}
 	}
+	c.broadcastCommitMessage(sub, committedSeal, epochValidatorSetSeal)
+}
+
+// broadcastCommitMessage broadcasts the COMMIT message of the subject, holding
+// the given seals.
+func (c *core) broadcastCommitMessage(sub *istanbul.Subject, committedSeal, epochValidatorSetSeal blscrypto.SerializedSignature) {
 	istMsg := istanbul.NewCommitMessage(&istanbul.CommittedSubject{
 		Subject:               sub,
 		CommittedSeal:         committedSeal[:],
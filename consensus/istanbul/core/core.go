@@ -79,6 +79,9 @@ type CoreBackend interface {
 	// the given validator
 	CheckSignature(data []byte, addr common.Address, sig []byte) error
 
+	// SigningDomains returns the domains messages are signed and verified in
+	SigningDomains() istanbul.SigningDomains
+
 	// GetCurrentHeadBlock retrieves the last block
 	GetCurrentHeadBlock() istanbul.Proposal
 
@@ -102,6 +105,10 @@ type CoreBackend interface {
 
 	IsPrimaryForSeq(seq *big.Int) bool
 	UpdateReplicaState(seq *big.Int)
+
+	// NewRound notifies that a round change moved the current sequence to the
+	// given round, proposed by the given validator.
+	NewRound(view *istanbul.View, proposer common.Address)
 }
 
 type core struct {
@@ -307,7 +314,7 @@ func (c *core) finalizeMessage(msg *istanbul.Message) ([]byte, error) {
 	// Add sender address
 	msg.Address = c.address
 
-	if err := msg.Sign(c.backend.Sign); err != nil {
+	if err := msg.SignInDomain(c.backend.SigningDomains().Sign, c.backend.Sign); err != nil {
 		return nil, err
 	}
 
@@ -490,6 +497,7 @@ func (c *core) startNewRound(round *big.Int) error {
 
 	// Update the roundstate db
 	c.current.StartNewRound(round, valSet, nextProposer)
+	c.backend.NewRound(newView, nextProposer.Address())
 
 	// Process backlog
 	c.processPendingRequests()
@@ -0,0 +1,118 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"time"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/consensus/istanbul"
+	blscrypto "github.com/celo-org/celo-blockchain/crypto/bls"
+)
+
+const (
+	// epochSignatureAttempts is the number of times the epoch validator set data
+	// is signed before giving up on the round.
+	epochSignatureAttempts = 5
+	// epochSignatureRetryDelay is the delay before the first retry, doubled on
+	// each further one.
+	epochSignatureRetryDelay = 250 * time.Millisecond
+)
+
+// EpochSignatureStatus reports the signature of the epoch validator set data,
+// the epoch SNARK data, of the last block of an epoch.
+type EpochSignatureStatus struct {
+	Number   uint64      `json:"number"`
+	Hash     common.Hash `json:"hash"`
+	Round    uint64      `json:"round"`
+	Attempts uint64      `json:"attempts"`
+	Signed   bool        `json:"signed"`
+	Error    string      `json:"error,omitempty"` // Error of the last failed attempt
+}
+
+// signEpochValidatorSetSeal signs the epoch validator set data of the subject.
+// If the BLS signer fails, it returns false and retries in the background,
+// posting an epochSealSignedEvent to broadcast the commit once signed.
+func (c *core) signEpochValidatorSetSeal(sub *istanbul.Subject, committedSeal blscrypto.SerializedSignature, data, extraData []byte, cip22 bool) (blscrypto.SerializedSignature, bool) {
+	status := &EpochSignatureStatus{
+		Number: c.current.Proposal().Number().Uint64(),
+		Hash:   sub.Digest,
+		Round:  sub.View.Round.Uint64(),
+	}
+	seal, err := c.tryEpochValidatorSetSeal(status, data, extraData, cip22)
+	if err == nil {
+		return seal, true
+	}
+	logger := c.newLogger("func", "signEpochValidatorSetSeal", "number", status.Number, "hash", status.Hash)
+	logger.Warn("Failed to sign epoch validator set seal, retrying", "err", err)
+
+	go func() {
+		delay := epochSignatureRetryDelay
+		for status.Attempts < epochSignatureAttempts {
+			time.Sleep(delay)
+			delay *= 2
+
+			seal, err := c.tryEpochValidatorSetSeal(status, data, extraData, cip22)
+			if err == nil {
+				logger.Info("Signed epoch validator set seal", "attempts", status.Attempts)
+				c.sendEvent(epochSealSignedEvent{sub: sub, committedSeal: committedSeal, epochValidatorSetSeal: seal})
+				return
+			}
+			logger.Warn("Failed to sign epoch validator set seal", "attempts", status.Attempts, "err", err)
+		}
+		logger.Error("Gave up signing epoch validator set seal", "attempts", status.Attempts)
+	}()
+	return blscrypto.SerializedSignature{}, false
+}
+
+// tryEpochValidatorSetSeal makes an attempt at signing the epoch validator set
+// data, persisting its outcome in the status.
+func (c *core) tryEpochValidatorSetSeal(status *EpochSignatureStatus, data, extraData []byte, cip22 bool) (blscrypto.SerializedSignature, error) {
+	seal, err := c.backend.SignBLS(data, extraData, true, cip22)
+	status.Attempts++
+	status.Signed = err == nil
+	if err != nil {
+		status.Error = err.Error()
+	} else {
+		status.Error = ""
+	}
+	if err := c.rsdb.UpdateEpochSignatureStatus(status); err != nil {
+		c.logger.Warn("Failed to persist epoch signature status", "number", status.Number, "err", err)
+	}
+	return seal, err
+}
+
+// handleEpochSealSigned broadcasts the commit of a subject whose epoch validator
+// set data was signed after retrying, unless consensus moved on meanwhile.
+func (c *core) handleEpochSealSigned(ev epochSealSignedEvent) {
+	logger := c.newLogger("func", "handleEpochSealSigned", "subject", ev.sub)
+	if c.current.View().Cmp(ev.sub.View) != 0 || c.current.State() != StatePrepared && c.current.State() != StateCommitted {
+		logger.Info("Dropping epoch validator set seal of a previous round")
+		return
+	}
+	if proposal := c.current.Proposal(); proposal == nil || proposal.Hash() != ev.sub.Digest {
+		logger.Info("Dropping epoch validator set seal of another proposal")
+		return
+	}
+	c.broadcastCommitMessage(ev.sub, ev.committedSeal, ev.epochValidatorSetSeal)
+}
+
+// EpochSignatureStatus returns the status of the signatures of the epoch
+// validator set data of the latest epochs.
+func (c *core) EpochSignatureStatus() ([]*EpochSignatureStatus, error) {
+	return c.rsdb.GetEpochSignatureStatuses()
+}
@@ -0,0 +1,79 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEpochSignatureRetry(t *testing.T) {
+	sys := NewTestSystemWithBackendDonut(4, 1, 1, 2)
+	for _, b := range sys.backends {
+		b.epochSignatureFailures = 2
+	}
+	close := sys.Run(true)
+	defer close()
+
+	// Every block is the last of an epoch, whose data can only be signed on the third attempt
+	sys.backends[0].NewRequest(makeBlock(1))
+	<-time.After(3 * time.Second)
+
+	for i, b := range sys.backends {
+		if len(b.committedMsgs) != 1 {
+			t.Fatalf("backend %d committed %d blocks, want 1", i, len(b.committedMsgs))
+		}
+		if seal := b.committedMsgs[0].aggregatedEpochValidatorSetSeal; seal.Bitmap == nil || seal.Bitmap.Sign() == 0 {
+			t.Errorf("backend %d committed no epoch validator set seal", i)
+		}
+		statuses, err := b.engine.EpochSignatureStatus()
+		if err != nil {
+			t.Fatalf("failed to get epoch signature status: %v", err)
+		}
+		if len(statuses) != 1 {
+			t.Fatalf("backend %d has %d epoch signature statuses, want 1", i, len(statuses))
+		}
+		if status := statuses[0]; !status.Signed || status.Attempts != 3 || status.Number != 1 || status.Error != "" {
+			t.Errorf("backend %d epoch signature status mismatch: %+v", i, status)
+		}
+	}
+}
+
+func TestRSDBEpochSignatureStatuses(t *testing.T) {
+	rsdb, _ := newRoundStateDB("", &RoundStateDBOptions{withGarbageCollector: false})
+	defer rsdb.Close()
+
+	for number := uint64(1); number <= epochSignatureStatusesToSave+4; number++ {
+		err := rsdb.UpdateEpochSignatureStatus(&EpochSignatureStatus{Number: number, Attempts: 1, Error: "failed"})
+		finishOnError(t, err)
+	}
+	// Later attempts override the status of the block
+	last := &EpochSignatureStatus{Number: epochSignatureStatusesToSave + 4, Attempts: 2, Signed: true}
+	finishOnError(t, rsdb.UpdateEpochSignatureStatus(last))
+
+	statuses, err := rsdb.GetEpochSignatureStatuses()
+	finishOnError(t, err)
+	if len(statuses) != epochSignatureStatusesToSave {
+		t.Fatalf("%d statuses stored, want %d", len(statuses), epochSignatureStatusesToSave)
+	}
+	if statuses[0].Number != 5 {
+		t.Errorf("oldest status of block %d, want 5", statuses[0].Number)
+	}
+	if status := statuses[len(statuses)-1]; *status != *last {
+		t.Errorf("latest status mismatch: have %+v, want %+v", status, last)
+	}
+}
@@ -18,6 +18,7 @@ package core
 
 import (
 	"github.com/celo-org/celo-blockchain/consensus/istanbul"
+	blscrypto "github.com/celo-org/celo-blockchain/crypto/bls"
 )
 
 type backlogEvent struct {
@@ -30,3 +31,9 @@ type resendRoundChangeEvent struct {
 type timeoutAndMoveToNextRoundEvent struct {
 	view *istanbul.View
 }
+
+type epochSealSignedEvent struct {
+	sub                   *istanbul.Subject
+	committedSeal         blscrypto.SerializedSignature
+	epochValidatorSetSeal blscrypto.SerializedSignature
+}
@@ -74,6 +74,7 @@ func (c *core) subscribeEvents() {
 		istanbul.MessageEvent{},
 		// internal events
 		backlogEvent{},
+		epochSealSignedEvent{},
 	)
 	c.timeoutSub = c.backend.EventMux().Subscribe(
 		timeoutAndMoveToNextRoundEvent{},
@@ -126,6 +127,8 @@ func (c *core) handleEvents() {
 						logger.Warn("Error in handling istanbul message that was sent from a backlog event", "err", err)
 					}
 				}
+			case epochSealSignedEvent:
+				c.handleEpochSealSigned(ev)
 			}
 		case event, ok := <-c.timeoutSub.Chan():
 			if !ok {
@@ -166,7 +169,7 @@ func (c *core) handleMsg(payload []byte) error {
 	// Decode message and check its signature
 	msg := new(istanbul.Message)
 	logger.Debug("Got new message", "payload", hexutil.Encode(payload))
-	if err := msg.FromPayload(payload, c.validateFn); err != nil {
+	if err := msg.FromPayloadInDomains(payload, c.backend.SigningDomains().Verify, c.validateFn); err != nil {
 		logger.Debug("Failed to decode message from payload", "err", err)
 		return err
 	}
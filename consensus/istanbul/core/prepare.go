@@ -46,13 +46,10 @@ func (c *core) verifyPreparedCertificate(preparedCertificate istanbul.PreparedCe
 	seen := make(map[common.Address]bool)
 
 	var view *istanbul.View
+	domains := c.backend.SigningDomains().Verify
 	for _, message := range preparedCertificate.PrepareOrCommitMessages {
-		data, err := message.PayloadNoSig()
-		if err != nil {
-			return nil, err
-		}
 		// Verify message signed by a validator
-		signer, err := c.validateFn(data, message.Signature)
+		signer, err := message.RecoverSigner(domains, c.validateFn)
 		if err != nil {
 			return nil, err
 		}
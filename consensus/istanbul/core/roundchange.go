@@ -60,17 +60,13 @@ func (c *core) handleRoundChangeCertificate(proposal istanbul.Subject, roundChan
 	preferredDigest := common.Hash{}
 	seen := make(map[common.Address]bool)
 	decodedMessages := make([]istanbul.RoundChange, len(roundChangeCertificate.RoundChangeMessages))
+	domains := c.backend.SigningDomains().Verify
 	for i := range roundChangeCertificate.RoundChangeMessages {
 		// use a different variable each time since we'll store a pointer to the variable
 		message := roundChangeCertificate.RoundChangeMessages[i]
 
 		// Verify message signed by a validator
-		data, err := message.PayloadNoSig()
-		if err != nil {
-			return err
-		}
-
-		signer, err := c.validateFn(data, message.Signature)
+		signer, err := message.RecoverSigner(domains, c.validateFn)
 		if err != nil {
 			return err
 		}
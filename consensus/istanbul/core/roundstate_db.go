@@ -40,6 +40,9 @@ const (
 	dbVersionKey = "version"  // Version of the database to flush if changes
 	lastViewKey  = "lastView" // Last View that we know of
 	rsKey        = "rs"       // Database Key Pefix for RoundState
+	epochSigKey  = "epochSig" // Database Key Prefix for EpochSignatureStatus
+
+	epochSignatureStatusesToSave = 16 // Number of epoch signature statuses kept
 )
 
 type RoundStateDB interface {
@@ -49,6 +52,10 @@ type RoundStateDB interface {
 	GetOldestValidView() (*istanbul.View, error)
 	GetRoundStateFor(view *istanbul.View) (RoundState, error)
 	UpdateLastRoundState(rs RoundState) error
+	// UpdateEpochSignatureStatus stores the status, dropping the oldest ones
+	UpdateEpochSignatureStatus(status *EpochSignatureStatus) error
+	// GetEpochSignatureStatuses returns the stored statuses, by block number
+	GetEpochSignatureStatuses() ([]*EpochSignatureStatus, error)
 	Close() error
 }
 
@@ -230,6 +237,46 @@ func (rsdb *roundStateDBImpl) GetRoundStateFor(view *istanbul.View) (RoundState,
 	return &entry, nil
 }
 
+func (rsdb *roundStateDBImpl) UpdateEpochSignatureStatus(status *EpochSignatureStatus) error {
+	entryBytes, err := rlp.EncodeToBytes(status)
+	if err != nil {
+		return err
+	}
+	if err := rsdb.db.Put(epochSig2Key(status.Number), entryBytes, nil); err != nil {
+		return err
+	}
+	// Drop the statuses of the oldest epochs
+	iter := rsdb.db.NewIterator(util.BytesPrefix([]byte(epochSigKey)), nil)
+	defer iter.Release()
+
+	var keys [][]byte
+	for iter.Next() {
+		keys = append(keys, common.CopyBytes(iter.Key()))
+	}
+	for len(keys) > epochSignatureStatusesToSave {
+		if err := rsdb.db.Delete(keys[0], nil); err != nil {
+			return err
+		}
+		keys = keys[1:]
+	}
+	return iter.Error()
+}
+
+func (rsdb *roundStateDBImpl) GetEpochSignatureStatuses() ([]*EpochSignatureStatus, error) {
+	iter := rsdb.db.NewIterator(util.BytesPrefix([]byte(epochSigKey)), nil)
+	defer iter.Release()
+
+	statuses := []*EpochSignatureStatus{}
+	for iter.Next() {
+		var status EpochSignatureStatus
+		if err := rlp.DecodeBytes(iter.Value(), &status); err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, &status)
+	}
+	return statuses, iter.Error()
+}
+
 func (rsdb *roundStateDBImpl) Close() error {
 	if rsdb.opts.withGarbageCollector {
 		rsdb.stopGarbageCollector()
@@ -295,6 +342,16 @@ func view2Key(view *istanbul.View) []byte {
 	return buff
 }
 
+// epochSig2Key encodes a block number so that the keys maintain its order
+func epochSig2Key(number uint64) []byte {
+	prefix := []byte(epochSigKey)
+	buff := make([]byte, len(prefix)+8)
+
+	copy(buff, prefix)
+	binary.BigEndian.PutUint64(buff[len(prefix):], number)
+	return buff
+}
+
 func key2View(key []byte) *istanbul.View {
 	prefixLen := len([]byte(rsKey))
 	seq := binary.BigEndian.Uint64(key[prefixLen : prefixLen+8])
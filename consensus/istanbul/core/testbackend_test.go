@@ -20,9 +20,11 @@ import (
 	"bytes"
 	"crypto/ecdsa"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"math"
 	"math/big"
+	"sync/atomic"
 	"time"
 
 	"github.com/celo-org/celo-blockchain/common"
@@ -70,6 +72,9 @@ type testSystemBackend struct {
 	verifyImpl func(proposal istanbul.Proposal) (*StateProcessResult, time.Duration, error)
 
 	donutBlock *big.Int
+
+	// Number of epoch validator set data signatures failing, accessed atomically
+	epochSignatureFailures int32
 }
 
 type testCommittedMsgs struct {
@@ -158,6 +163,9 @@ func (self *testSystemBackend) Gossip(payload []byte, ethMsgCode uint64) error {
 }
 
 func (self *testSystemBackend) SignBLS(data []byte, extra []byte, useComposite, cip22 bool) (blscrypto.SerializedSignature, error) {
+	if useComposite && atomic.AddInt32(&self.epochSignatureFailures, -1) >= 0 {
+		return blscrypto.SerializedSignature{}, errors.New("bls signer unavailable")
+	}
 	privateKey, _ := bls.DeserializePrivateKey(self.blsKey)
 	defer privateKey.Destroy()
 
@@ -211,6 +219,10 @@ func (self *testSystemBackend) CheckSignature([]byte, common.Address, []byte) er
 	return nil
 }
 
+func (self *testSystemBackend) SigningDomains() istanbul.SigningDomains {
+	return istanbul.LegacySigningDomains
+}
+
 func (self *testSystemBackend) CheckValidatorSignature(data []byte, sig []byte) (common.Address, error) {
 	return istanbul.CheckValidatorSignature(self.peers, data, sig)
 }
@@ -264,6 +276,8 @@ func (self *testSystemBackend) ParentBlockValidators(proposal istanbul.Proposal)
 
 func (self *testSystemBackend) UpdateReplicaState(seq *big.Int) { /* pass */ }
 
+func (self *testSystemBackend) NewRound(view *istanbul.View, proposer common.Address) { /* pass */ }
+
 func (self *testSystemBackend) finalizeAndReturnMessage(msg *istanbul.Message) (istanbul.Message, error) {
 	message := new(istanbul.Message)
 	data, err := self.engine.(*core).finalizeMessage(msg)
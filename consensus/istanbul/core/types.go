@@ -34,6 +34,8 @@ type Engine interface {
 	ParentCommits() MessageSet
 	// ForceRoundChange will force round change to the current desiredRound + 1
 	ForceRoundChange()
+	// EpochSignatureStatus returns the status of the latest epoch validator set data signatures
+	EpochSignatureStatus() ([]*EpochSignatureStatus, error)
 }
 
 // State represents the IBFT state
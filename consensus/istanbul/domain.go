@@ -0,0 +1,177 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package istanbul
+
+import (
+	"math/big"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/params"
+	"github.com/celo-org/celo-blockchain/rlp"
+)
+
+// SigningDomainTransition is the number of blocks on each side of the E fork
+// during which messages signed both with and without a signing domain are
+// accepted, so that validators switching at slightly different heads keep
+// understanding each other.
+const SigningDomainTransition = 100
+
+// signingDomainTag prefixes every domain separated signing payload, so that it
+// can never be mistaken for any other payload signed by a validator key.
+const signingDomainTag = "celo-istanbul-message"
+
+// SigningDomain binds the signature of a message to a network, so that it
+// cannot be replayed on another network. The network is identified by its
+// chain ID and genesis hash only: unlike the fork ID, they never change, so
+// that the validators keep understanding each other across the hard forks
+// without any transition. The code of the message is bound to the signature
+// too, so that it cannot be replayed as another type of message.
+type SigningDomain struct {
+	ChainID *big.Int
+	Genesis common.Hash
+}
+
+// SigningDomains are the domains Istanbul messages are signed and verified
+// in at a given block. A nil domain stands for legacy signatures, made over
+// the message payload only.
+type SigningDomains struct {
+	Sign   *SigningDomain   // Domain new messages are signed in
+	Verify []*SigningDomain // Domains signatures are accepted in, by preference
+}
+
+// LegacySigningDomains only sign and accept legacy signatures.
+var LegacySigningDomains = SigningDomains{Verify: []*SigningDomain{nil}}
+
+// SigningDomainSchedule holds the domains Istanbul messages are signed and
+// verified in around the E fork of a chain, so that they are only computed
+// once. Domain separated signatures are used from the E fork on.
+type SigningDomainSchedule struct {
+	fork *big.Int // E fork block, nil if the chain never switches to domains
+
+	early      SigningDomains // Domains in the transition before the fork
+	transition SigningDomains // Domains in the transition after the fork
+	forked     SigningDomains // Domains after the transition
+}
+
+// NewSigningDomainSchedule returns the signing domain schedule of the chain
+// with the given config and genesis.
+func NewSigningDomainSchedule(config *params.ChainConfig, genesis common.Hash) *SigningDomainSchedule {
+	if config.EBlock == nil {
+		return &SigningDomainSchedule{}
+	}
+	domain := &SigningDomain{ChainID: config.ChainID, Genesis: genesis}
+	return &SigningDomainSchedule{
+		fork:       config.EBlock,
+		early:      SigningDomains{Verify: []*SigningDomain{nil, domain}},
+		transition: SigningDomains{Sign: domain, Verify: []*SigningDomain{domain, nil}},
+		forked:     SigningDomains{Sign: domain, Verify: []*SigningDomain{domain}},
+	}
+}
+
+// At returns the domains Istanbul messages are signed and verified in at the
+// given block. The signatures of the validators switching at slightly
+// different heads are accepted on both sides of the fork.
+func (s *SigningDomainSchedule) At(number *big.Int) SigningDomains {
+	if s == nil || s.fork == nil {
+		return LegacySigningDomains
+	}
+	distance := new(big.Int).Sub(number, s.fork)
+	transition := distance.CmpAbs(big.NewInt(SigningDomainTransition)) < 0
+	switch {
+	case distance.Sign() < 0 && transition:
+		return s.early
+	case distance.Sign() < 0:
+		return LegacySigningDomains
+	case transition:
+		return s.transition
+	default:
+		return s.forked
+	}
+}
+
+// NewSigningDomains returns the domains Istanbul messages are signed and
+// verified in at the given block of the chain with the given config and
+// genesis.
+func NewSigningDomains(config *params.ChainConfig, genesis common.Hash, number *big.Int) SigningDomains {
+	return NewSigningDomainSchedule(config, genesis).At(number)
+}
+
+// SigningPayload returns the payload signed for the message in the given
+// domain, or the legacy payload if the domain is nil.
+func (m *Message) SigningPayload(domain *SigningDomain) ([]byte, error) {
+	payloadNoSig, err := m.PayloadNoSig()
+	if err != nil || domain == nil {
+		return payloadNoSig, err
+	}
+	return rlp.EncodeToBytes([]interface{}{
+		signingDomainTag,
+		domain.ChainID,
+		domain.Genesis,
+		m.Code,
+		payloadNoSig,
+	})
+}
+
+// SignInDomain signs the message in the given domain, or with a legacy
+// signature if the domain is nil.
+func (m *Message) SignInDomain(domain *SigningDomain, signingFn func(data []byte) ([]byte, error)) error {
+	payload, err := m.SigningPayload(domain)
+	if err != nil {
+		return err
+	}
+	m.Signature, err = signingFn(payload)
+	return err
+}
+
+// RecoverSigner returns the signer of the message validated by validateFn,
+// trying each of the given domains in turn. The address returned only differs
+// from the one of the message if no domain yields the latter.
+func (m *Message) RecoverSigner(domains []*SigningDomain, validateFn func([]byte, []byte) (common.Address, error)) (common.Address, error) {
+	var (
+		signer common.Address
+		err    error
+	)
+	for _, domain := range domains {
+		var payload []byte
+		if payload, err = m.SigningPayload(domain); err != nil {
+			return common.Address{}, err
+		}
+		if signer, err = validateFn(payload, m.Signature); err == nil && signer == m.Address {
+			return signer, nil
+		}
+	}
+	return signer, err
+}
+
+// FromPayloadInDomains decodes b into the message like FromPayload does,
+// accepting signatures made in any of the given domains.
+func (m *Message) FromPayloadInDomains(b []byte, domains []*SigningDomain, validateFn func([]byte, []byte) (common.Address, error)) error {
+	if err := rlp.DecodeBytes(b, &m); err != nil {
+		return err
+	}
+	if validateFn == nil {
+		return nil
+	}
+	signer, err := m.RecoverSigner(domains, validateFn)
+	if err != nil {
+		return err
+	}
+	if signer != m.Address {
+		return ErrInvalidSigner
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package istanbul
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/crypto"
+	"github.com/celo-org/celo-blockchain/params"
+)
+
+func TestNewSigningDomains(t *testing.T) {
+	config := *params.IstanbulTestChainConfig
+	config.EBlock = big.NewInt(1000)
+	genesis := common.HexToHash("0x01")
+
+	tests := []struct {
+		number uint64
+		sign   bool
+		verify []bool // Whether each accepted domain is domain separated
+	}{
+		{number: 1, verify: []bool{false}},
+		{number: 1000 - SigningDomainTransition, verify: []bool{false}},
+		{number: 1000 - SigningDomainTransition + 1, verify: []bool{false, true}},
+		{number: 1000, sign: true, verify: []bool{true, false}},
+		{number: 1000 + SigningDomainTransition - 1, sign: true, verify: []bool{true, false}},
+		{number: 1000 + SigningDomainTransition, sign: true, verify: []bool{true}},
+	}
+	for _, tt := range tests {
+		domains := NewSigningDomains(&config, genesis, new(big.Int).SetUint64(tt.number))
+		if (domains.Sign != nil) != tt.sign {
+			t.Errorf("block %d: domain separated signing mismatch: have %v, want %v", tt.number, domains.Sign != nil, tt.sign)
+		}
+		if len(domains.Verify) != len(tt.verify) {
+			t.Errorf("block %d: accepted domain count mismatch: have %d, want %d", tt.number, len(domains.Verify), len(tt.verify))
+			continue
+		}
+		for i, domain := range domains.Verify {
+			if (domain != nil) != tt.verify[i] {
+				t.Errorf("block %d: accepted domain %d mismatch: have %v, want %v", tt.number, i, domain != nil, tt.verify[i])
+			}
+		}
+	}
+	// The domain only depends on the network, not on the block
+	fork := NewSigningDomains(&config, genesis, big.NewInt(1000))
+	later := NewSigningDomains(&config, genesis, big.NewInt(1000000))
+	if want := (SigningDomain{ChainID: config.ChainID, Genesis: genesis}); *fork.Sign != want || *later.Sign != want {
+		t.Errorf("domain mismatch: have %+v and %+v, want %+v", fork.Sign, later.Sign, want)
+	}
+	// Without the fork, only legacy signatures are used
+	config.EBlock = nil
+	if domains := NewSigningDomains(&config, genesis, big.NewInt(1000)); domains.Sign != nil || len(domains.Verify) != 1 || domains.Verify[0] != nil {
+		t.Errorf("unforked chain uses domain separated signatures: %+v", domains)
+	}
+}
+
+func TestSigningDomainSeparation(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	sign := func(data []byte) ([]byte, error) {
+		return crypto.Sign(crypto.Keccak256(data), key)
+	}
+	mainnet := &SigningDomain{ChainID: big.NewInt(42220), Genesis: common.HexToHash("0x01")}
+	testnet := &SigningDomain{ChainID: big.NewInt(44787), Genesis: common.HexToHash("0x01")}
+	other := &SigningDomain{ChainID: big.NewInt(42220), Genesis: common.HexToHash("0x02")}
+
+	newMessage := func(code uint64, domain *SigningDomain) []byte {
+		msg := NewPrepareMessage(&Subject{
+			View:   &View{Round: big.NewInt(1), Sequence: big.NewInt(2)},
+			Digest: common.HexToHash("0x1234"),
+		}, addr)
+		msg.Code = code
+		if err := msg.SignInDomain(domain, sign); err != nil {
+			t.Fatalf("failed to sign message: %v", err)
+		}
+		payload, err := msg.Payload()
+		if err != nil {
+			t.Fatalf("failed to encode message: %v", err)
+		}
+		return payload
+	}
+	tests := []struct {
+		name    string
+		payload []byte
+		domains []*SigningDomain
+		valid   bool
+	}{
+		{"same domain", newMessage(MsgPrepare, mainnet), []*SigningDomain{mainnet}, true},
+		{"legacy", newMessage(MsgPrepare, nil), []*SigningDomain{nil}, true},
+		{"legacy in transition", newMessage(MsgPrepare, nil), []*SigningDomain{mainnet, nil}, true},
+		{"domain in transition", newMessage(MsgPrepare, mainnet), []*SigningDomain{nil, mainnet}, true},
+		{"legacy after transition", newMessage(MsgPrepare, nil), []*SigningDomain{mainnet}, false},
+		{"other network", newMessage(MsgPrepare, testnet), []*SigningDomain{mainnet}, false},
+		{"other genesis", newMessage(MsgPrepare, other), []*SigningDomain{mainnet}, false},
+		{"domain as legacy", newMessage(MsgPrepare, mainnet), []*SigningDomain{nil}, false},
+	}
+	for _, tt := range tests {
+		msg := new(Message)
+		err := msg.FromPayloadInDomains(tt.payload, tt.domains, GetSignatureAddress)
+		if valid := err == nil; valid != tt.valid {
+			t.Errorf("%s: validity mismatch: have %v (%v), want %v", tt.name, valid, err, tt.valid)
+		}
+	}
+	// A signature made for one type of message is not valid for another
+	payload := newMessage(MsgPrepare, mainnet)
+	msg := new(Message)
+	if err := msg.FromPayloadInDomains(payload, []*SigningDomain{mainnet}, GetSignatureAddress); err != nil {
+		t.Fatalf("failed to decode message: %v", err)
+	}
+	msg.Code = MsgCommit
+	if signer, _ := msg.RecoverSigner([]*SigningDomain{mainnet}, GetSignatureAddress); signer == addr {
+		t.Errorf("signature accepted for another message type")
+	}
+}
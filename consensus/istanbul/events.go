@@ -16,7 +16,12 @@
 
 package istanbul
 
-import "github.com/celo-org/celo-blockchain/p2p/enode"
+import (
+	"math/big"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/p2p/enode"
+)
 
 // RequestEvent is posted to propose a proposal
 type RequestEvent struct {
@@ -37,3 +42,11 @@ type MessageWithPeerIDEvent struct {
 // FinalCommittedEvent is posted when a proposal is committed
 type FinalCommittedEvent struct {
 }
+
+// NewRoundEvent is sent when a round change moves the current sequence to a
+// new round, with the proposer of that round.
+type NewRoundEvent struct {
+	Sequence *big.Int
+	Round    *big.Int
+	Proposer common.Address
+}
@@ -0,0 +1,201 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package lightverify
+
+import (
+	"errors"
+
+	"github.com/celo-org/celo-blockchain/crypto/blake2s"
+	"github.com/celo-org/celo-blockchain/crypto/bls12377"
+)
+
+const (
+	// PublicKeyLength is the length of a compressed BLS public key (a G2 point).
+	PublicKeyLength = 96
+	// SignatureLength is the length of a compressed BLS signature (a G1 point).
+	SignatureLength = 48
+
+	fieldElementLength = 48
+
+	// Flags stored in the most significant bits of a compressed point.
+	greatestFlag = 0x80
+	infinityFlag = 0x40
+	flagsMask    = greatestFlag | infinityFlag
+
+	// Number of counter values tried before hashing to the curve gives up.
+	hashToCurveAttempts = 256
+)
+
+// xofDomain is the Blake2Xs personalization used when hashing messages to G1.
+var xofDomain = []byte("ULforxof")
+
+var (
+	// ErrInvalidPublicKey is returned if a BLS public key cannot be decoded.
+	ErrInvalidPublicKey = errors.New("invalid BLS public key")
+	// ErrInvalidSignature is returned if a BLS signature cannot be decoded or does not verify.
+	ErrInvalidSignature = errors.New("invalid BLS signature")
+	// ErrHashToCurve is returned if no curve point is found for a message.
+	ErrHashToCurve = errors.New("could not hash message to curve")
+)
+
+// reverse returns a copy of b with the byte order reversed, converting
+// between the little-endian encoding used by the BLS library and the
+// big-endian one used by the curve implementation.
+func reverse(b []byte) []byte {
+	out := make([]byte, len(b))
+	for i := range b {
+		out[len(b)-1-i] = b[i]
+	}
+	return out
+}
+
+// DecodePublicKey decodes a compressed BLS public key and checks that it
+// belongs to the prime order subgroup of G2.
+func DecodePublicKey(in []byte) (*bls12377.PointG2, error) {
+	if len(in) != PublicKeyLength {
+		return nil, ErrInvalidPublicKey
+	}
+	c0 := reverse(in[:fieldElementLength])
+	c1 := reverse(in[fieldElementLength:])
+	flags := c1[0] & flagsMask
+	c1[0] &^= flagsMask
+	if flags&infinityFlag != 0 {
+		return nil, ErrInvalidPublicKey
+	}
+	g2 := bls12377.NewG2()
+	p, err := g2.FromXCoordinate(append(c0, c1...), flags&greatestFlag != 0)
+	if err != nil || !g2.InCorrectSubgroup(p) {
+		return nil, ErrInvalidPublicKey
+	}
+	return p, nil
+}
+
+// DecodeSignature decodes a compressed BLS signature and checks that it
+// belongs to the prime order subgroup of G1.
+func DecodeSignature(in []byte) (*bls12377.PointG1, error) {
+	if len(in) != SignatureLength {
+		return nil, ErrInvalidSignature
+	}
+	x := reverse(in)
+	flags := x[0] & flagsMask
+	x[0] &^= flagsMask
+	if flags&infinityFlag != 0 {
+		return nil, ErrInvalidSignature
+	}
+	g1 := bls12377.NewG1()
+	p, err := g1.FromXCoordinate(x, flags&greatestFlag != 0)
+	if err != nil || !g1.InCorrectSubgroup(p) {
+		return nil, ErrInvalidSignature
+	}
+	return p, nil
+}
+
+// xof expands msg into length bytes of output using Blake2Xs: a root hash of
+// msg is computed first and every 32 byte block of the output is then a hash
+// of the root parameterised with the block index.
+func xof(msg []byte, length int) ([]byte, error) {
+	root, err := blake2s.New(&blake2s.Config{
+		Person: xofDomain,
+		Tree: &blake2s.Tree{
+			Fanout:     1,
+			MaxDepth:   1,
+			NodeOffset: uint64(length) << 32,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	root.Write(msg)
+	h0 := root.Sum(nil)
+
+	blocks := (length + blake2s.Size - 1) / blake2s.Size
+	out := make([]byte, 0, blocks*blake2s.Size)
+	for i := 0; i < blocks; i++ {
+		size := blake2s.Size
+		if i == blocks-1 && length%blake2s.Size != 0 {
+			size = length % blake2s.Size
+		}
+		h, err := blake2s.New(&blake2s.Config{
+			Size:   uint8(size),
+			Person: xofDomain,
+			Tree: &blake2s.Tree{
+				LeafSize:      blake2s.Size,
+				NodeOffset:    uint64(i) | uint64(length)<<32,
+				InnerHashSize: blake2s.Size,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		h.Write(h0)
+		out = h.Sum(out)
+	}
+	return out, nil
+}
+
+// HashToG1 maps a message to a point of the prime order subgroup of G1 using
+// the try-and-increment method of the BLS library with its direct hasher.
+func HashToG1(msg, extraData []byte) (*bls12377.PointG1, error) {
+	g1 := bls12377.NewG1()
+	for c := 0; c < hashToCurveAttempts; c++ {
+		input := append(append([]byte{byte(c)}, extraData...), msg...)
+		h, err := xof(input, 2*blake2s.Size)
+		if err != nil {
+			return nil, err
+		}
+		// The candidate x takes the low 377 bits, the bit above them selects the y.
+		x := reverse(h[:fieldElementLength])
+		greatest := x[0]&0x02 != 0
+		x[0] &= 0x01
+		p, err := g1.FromXCoordinate(x, greatest)
+		if err != nil {
+			continue
+		}
+		g1.ClearCofactor(p)
+		if g1.IsZero(p) {
+			continue
+		}
+		return g1.Affine(p), nil
+	}
+	return nil, ErrHashToCurve
+}
+
+// AggregatePublicKeys sums the given public keys.
+func AggregatePublicKeys(keys []*bls12377.PointG2) *bls12377.PointG2 {
+	g2 := bls12377.NewG2()
+	apk := g2.Zero()
+	for _, k := range keys {
+		g2.Add(apk, apk, k)
+	}
+	return apk
+}
+
+// VerifySignature checks a (possibly aggregated) BLS signature over msg
+// against the (possibly aggregated) public key.
+func VerifySignature(apk *bls12377.PointG2, msg, extraData []byte, sig *bls12377.PointG1) error {
+	h, err := HashToG1(msg, extraData)
+	if err != nil {
+		return err
+	}
+	e := bls12377.NewPairingEngine()
+	e.AddPair(sig, bls12377.NewG2().One())
+	e.AddPairInv(h, apk)
+	if !e.Check() {
+		return ErrInvalidSignature
+	}
+	return nil
+}
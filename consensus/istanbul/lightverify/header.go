@@ -0,0 +1,135 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package lightverify
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/rlp"
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	// ExtraVanity is the number of extra-data bytes reserved for validator vanity.
+	ExtraVanity = 32
+
+	// msgCommit is the istanbul message code of commit messages, which the
+	// aggregated seal commits to.
+	msgCommit = 2
+)
+
+// ErrInvalidExtra is returned if the extra-data of a header cannot be decoded.
+var ErrInvalidExtra = errors.New("invalid istanbul header extra-data")
+
+// Header is a Celo block header. Its RLP encoding is the same as the one of
+// the core types, so headers can be decoded straight from the wire format.
+type Header struct {
+	ParentHash  common.Hash
+	Coinbase    common.Address
+	Root        common.Hash
+	TxHash      common.Hash
+	ReceiptHash common.Hash
+	Bloom       [256]byte
+	Number      *big.Int
+	GasUsed     uint64
+	Time        uint64
+	Extra       []byte
+}
+
+// AggregatedSeal is the aggregated BLS signature of a quorum of validators.
+type AggregatedSeal struct {
+	// Bitmap has an active bit for each validator that signed
+	Bitmap *big.Int
+	// Signature is the aggregation of the validator signatures
+	Signature []byte
+	// Round is the consensus round in which the signature was created
+	Round *big.Int
+}
+
+// Extra is the istanbul payload of the header extra-data.
+type Extra struct {
+	// AddedValidators are the validators that have been added in the block
+	AddedValidators []common.Address
+	// AddedValidatorsPublicKeys are the compressed BLS public keys of the added validators
+	AddedValidatorsPublicKeys [][PublicKeyLength]byte
+	// RemovedValidators is a bitmap having an active bit for each removed validator
+	RemovedValidators *big.Int
+	// Seal is an ECDSA signature by the proposer
+	Seal []byte
+	// AggregatedSeal is the aggregated BLS signature of the block
+	AggregatedSeal AggregatedSeal
+	// ParentAggregatedSeal is the aggregated BLS signature of the parent block
+	ParentAggregatedSeal AggregatedSeal
+}
+
+// DecodeHeader decodes an RLP encoded header.
+func DecodeHeader(data []byte) (*Header, error) {
+	h := new(Header)
+	if err := rlp.DecodeBytes(data, h); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// DecodeExtra decodes the istanbul payload of the header extra-data.
+func DecodeExtra(h *Header) (*Extra, error) {
+	if len(h.Extra) < ExtraVanity {
+		return nil, ErrInvalidExtra
+	}
+	extra := new(Extra)
+	if err := rlp.DecodeBytes(h.Extra[ExtraVanity:], extra); err != nil {
+		return nil, err
+	}
+	return extra, nil
+}
+
+// Hash returns the block hash of the header, which leaves the aggregated
+// seal out of the extra-data as it cannot sign itself.
+func (h *Header) Hash() (common.Hash, error) {
+	extra, err := DecodeExtra(h)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	extra.AggregatedSeal = AggregatedSeal{}
+	payload, err := rlp.EncodeToBytes(extra)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	filtered := *h
+	filtered.Extra = append(append([]byte{}, h.Extra[:ExtraVanity]...), payload...)
+
+	sha := sha3.NewLegacyKeccak256()
+	if err := rlp.Encode(sha, &filtered); err != nil {
+		return common.Hash{}, err
+	}
+	var hash common.Hash
+	sha.Sum(hash[:0])
+	return hash, nil
+}
+
+// committedSeal returns the message signed by validators committing to the
+// given block hash in the given round.
+func committedSeal(hash common.Hash, round *big.Int) []byte {
+	var buf bytes.Buffer
+	buf.Write(hash.Bytes())
+	buf.Write(round.Bytes())
+	buf.WriteByte(msgCommit)
+	return buf.Bytes()
+}
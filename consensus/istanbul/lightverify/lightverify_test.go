@@ -0,0 +1,291 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package lightverify
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/consensus/istanbul"
+	istanbulCore "github.com/celo-org/celo-blockchain/consensus/istanbul/core"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/crypto"
+	blscrypto "github.com/celo-org/celo-blockchain/crypto/bls"
+	"github.com/celo-org/celo-blockchain/crypto/bls12377"
+	"github.com/celo-org/celo-blockchain/rlp"
+	"github.com/celo-org/celo-bls-go/bls"
+)
+
+type testValidator struct {
+	address   common.Address
+	blsKey    []byte
+	publicKey blscrypto.SerializedPublicKey
+}
+
+func newTestValidator(t *testing.T, index int) testValidator {
+	key, err := crypto.ToECDSA(crypto.Keccak256([]byte(fmt.Sprintf("lightverify validator %d", index))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	blsKey, err := blscrypto.ECDSAToBLS(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, err := blscrypto.PrivateToPublic(blsKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testValidator{crypto.PubkeyToAddress(key.PublicKey), blsKey, publicKey}
+}
+
+func (v testValidator) sign(t *testing.T, msg []byte) []byte {
+	privateKey, err := bls.DeserializePrivateKey(v.blsKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer privateKey.Destroy()
+	signature, err := privateKey.SignMessage(msg, []byte{}, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer signature.Destroy()
+	serialized, err := signature.Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return serialized
+}
+
+func TestHashToG1(t *testing.T) {
+	g1 := bls12377.NewG1()
+	for i := 0; i < 32; i++ {
+		msg := []byte(fmt.Sprintf("message %d", i))
+		// The BLS library returns the little-endian x and y coordinates followed by an infinity flag
+		want, err := bls.HashDirect(msg, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, err := HashToG1(msg, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		have := g1.ToBytes(p)
+		if !bytes.Equal(have[:48], reverse(want[:48])) || !bytes.Equal(have[48:], reverse(want[48:96])) {
+			t.Errorf("message %d: hash mismatch", i)
+		}
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	msg, extra := []byte("message"), []byte("extra")
+	var keys []*bls12377.PointG2
+	var signatures [][]byte
+	for i := 0; i < 4; i++ {
+		v := newTestValidator(t, i)
+		key, err := DecodePublicKey(v.publicKey[:])
+		if err != nil {
+			t.Fatalf("validator %d: %v", i, err)
+		}
+		privateKey, _ := bls.DeserializePrivateKey(v.blsKey)
+		signature, _ := privateKey.SignMessage(msg, extra, false, false)
+		serialized, _ := signature.Serialize()
+		sig, err := DecodeSignature(serialized)
+		if err != nil {
+			t.Fatalf("validator %d: %v", i, err)
+		}
+		if err := VerifySignature(key, msg, extra, sig); err != nil {
+			t.Errorf("validator %d: %v", i, err)
+		}
+		if err := VerifySignature(key, msg, nil, sig); err != ErrInvalidSignature {
+			t.Errorf("validator %d: signature verified with different extra data", i)
+		}
+		keys = append(keys, key)
+		signatures = append(signatures, serialized)
+	}
+	aggregated, err := blscrypto.AggregateSignatures(signatures)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := DecodeSignature(aggregated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifySignature(AggregatePublicKeys(keys), msg, extra, sig); err != nil {
+		t.Errorf("aggregated signature: %v", err)
+	}
+	if err := VerifySignature(AggregatePublicKeys(keys[1:]), msg, extra, sig); err != ErrInvalidSignature {
+		t.Errorf("aggregated signature verified against a subset of the keys")
+	}
+}
+
+func TestCommittedSeal(t *testing.T) {
+	hash, round := common.HexToHash("0x1234"), big.NewInt(3)
+	if msgCommit != istanbul.MsgCommit {
+		t.Fatalf("commit message code mismatch: have %d, want %d", msgCommit, istanbul.MsgCommit)
+	}
+	if !bytes.Equal(committedSeal(hash, round), istanbulCore.PrepareCommittedSeal(hash, round)) {
+		t.Errorf("committed seal mismatch")
+	}
+}
+
+func makeExtra(t *testing.T, added []testValidator, removed *big.Int, seal types.IstanbulAggregatedSeal) []byte {
+	extra := &types.IstanbulExtra{
+		AddedValidators:           []common.Address{},
+		AddedValidatorsPublicKeys: []blscrypto.SerializedPublicKey{},
+		RemovedValidators:         removed,
+		Seal:                      []byte{},
+		AggregatedSeal:            seal,
+		ParentAggregatedSeal:      types.IstanbulAggregatedSeal{Bitmap: big.NewInt(0), Signature: []byte{}, Round: big.NewInt(0)},
+	}
+	for _, v := range added {
+		extra.AddedValidators = append(extra.AddedValidators, v.address)
+		extra.AddedValidatorsPublicKeys = append(extra.AddedValidatorsPublicKeys, v.publicKey)
+	}
+	payload, err := rlp.EncodeToBytes(extra)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append(make([]byte, types.IstanbulExtraVanity), payload...)
+}
+
+// sealedHeader returns the RLP encoding of a header sealed by the validators of the bitmap.
+func sealedHeader(t *testing.T, number uint64, validators []testValidator, bitmap int64, added []testValidator, removed int64) []byte {
+	header := &types.Header{
+		Number: new(big.Int).SetUint64(number),
+		Time:   number,
+		Extra:  makeExtra(t, added, big.NewInt(removed), types.IstanbulAggregatedSeal{}),
+	}
+	round := big.NewInt(1)
+	msg := istanbulCore.PrepareCommittedSeal(header.Hash(), round)
+	var signatures [][]byte
+	for i, v := range validators {
+		if bitmap&(1<<uint(i)) != 0 {
+			signatures = append(signatures, v.sign(t, msg))
+		}
+	}
+	signature, err := blscrypto.AggregateSignatures(signatures)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header.Extra = makeExtra(t, added, big.NewInt(removed), types.IstanbulAggregatedSeal{Bitmap: big.NewInt(bitmap), Signature: signature, Round: round})
+	data, err := rlp.EncodeToBytes(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func mustDecodeHeader(t *testing.T, data []byte) *Header {
+	h, err := DecodeHeader(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func TestHeaderHash(t *testing.T) {
+	validators := []testValidator{newTestValidator(t, 0), newTestValidator(t, 1)}
+	data := sealedHeader(t, 5, validators, 3, validators[1:], 1)
+	var header types.Header
+	if err := rlp.DecodeBytes(data, &header); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := mustDecodeHeader(t, data).Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash != header.Hash() {
+		t.Errorf("hash mismatch: have %x, want %x", hash, header.Hash())
+	}
+}
+
+func TestVerifier(t *testing.T) {
+	const epochSize = 3
+	var all []testValidator
+	for i := 0; i < 5; i++ {
+		all = append(all, newTestValidator(t, i))
+	}
+	genesisSet, nextSet := all[:4], append(append([]testValidator{}, all[1:4]...), all[4])
+
+	genesis := &types.Header{Number: big.NewInt(0), Extra: makeExtra(t, genesisSet, big.NewInt(0), types.IstanbulAggregatedSeal{})}
+	genesisData, _ := rlp.EncodeToBytes(genesis)
+	v, err := NewVerifierFromGenesis(epochSize, mustDecodeHeader(t, genesisData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Validators().Size() != 4 {
+		t.Fatalf("genesis validator set size mismatch: have %d, want 4", v.Validators().Size())
+	}
+
+	// Three out of four validators make a quorum, two do not
+	if err := v.VerifyHeader(mustDecodeHeader(t, sealedHeader(t, 1, genesisSet, 0x3, nil, 0))); err != ErrInsufficientSeals {
+		t.Errorf("block 1 without quorum: have %v, want %v", err, ErrInsufficientSeals)
+	}
+	if err := v.VerifyHeader(mustDecodeHeader(t, sealedHeader(t, 1, genesisSet, 0xb, nil, 0))); err != nil {
+		t.Errorf("block 1: %v", err)
+	}
+	// The claimed signers must match the signature
+	forged := mustDecodeHeader(t, sealedHeader(t, 2, genesisSet, 0x7, nil, 0))
+	extra, _ := DecodeExtra(forged)
+	extra.AggregatedSeal.Bitmap = big.NewInt(0xe)
+	payload, _ := rlp.EncodeToBytes(extra)
+	forged.Extra = append(forged.Extra[:ExtraVanity], payload...)
+	if err := v.VerifyHeader(forged); err != ErrInvalidSignature {
+		t.Errorf("block 2 with forged bitmap: have %v, want %v", err, ErrInvalidSignature)
+	}
+	// Blocks after the epoch need the epoch block first
+	if err := v.VerifyHeader(mustDecodeHeader(t, sealedHeader(t, 4, nextSet, 0xf, nil, 0))); err != ErrUnexpectedBlock {
+		t.Errorf("block 4 before epoch block: have %v, want %v", err, ErrUnexpectedBlock)
+	}
+	// The epoch block replaces the first validator
+	if err := v.VerifyHeader(mustDecodeHeader(t, sealedHeader(t, 3, genesisSet, 0xf, all[4:], 0x1))); err != nil {
+		t.Fatalf("block 3: %v", err)
+	}
+	if v.Number() != 3 {
+		t.Errorf("epoch block not applied: have %d, want 3", v.Number())
+	}
+	for i, val := range v.Validators().List() {
+		if val.Address != nextSet[i].address {
+			t.Errorf("validator %d mismatch: have %x, want %x", i, val.Address, nextSet[i].address)
+		}
+	}
+	if err := v.VerifyHeader(mustDecodeHeader(t, sealedHeader(t, 4, genesisSet, 0xf, nil, 0))); err != ErrInvalidSignature {
+		t.Errorf("block 4 sealed by the previous set: have %v, want %v", err, ErrInvalidSignature)
+	}
+	if err := v.VerifyHeader(mustDecodeHeader(t, sealedHeader(t, 5, nextSet, 0xe, nil, 0))); err != nil {
+		t.Errorf("block 5: %v", err)
+	}
+}
+
+func TestWASMBuild(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping wasm build in short mode")
+	}
+	gobin := filepath.Join(runtime.GOROOT(), "bin", "go")
+	cmd := exec.Command(gobin, "build", "-o", os.DevNull, ".")
+	cmd.Env = append(os.Environ(), "GOOS=js", "GOARCH=wasm", "CGO_ENABLED=0")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("wasm build failed: %v\n%s", err, out)
+	}
+}
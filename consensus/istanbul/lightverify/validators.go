@@ -0,0 +1,117 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package lightverify
+
+import (
+	"errors"
+	"math"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/crypto/bls12377"
+)
+
+// ErrInvalidValidatorSetDiff is returned if the validator set changes carried
+// by a header cannot be applied to the tracked validator set.
+var ErrInvalidValidatorSetDiff = errors.New("invalid validator set diff")
+
+// Validator is a member of the validator set.
+type Validator struct {
+	Address   common.Address
+	PublicKey [PublicKeyLength]byte
+
+	key *bls12377.PointG2
+}
+
+// ValidatorSet is the ordered list of validators signing blocks, as it
+// appears in the aggregated seal bitmaps.
+type ValidatorSet struct {
+	validators []*Validator
+}
+
+// NewValidatorSet creates a validator set from the addresses and compressed
+// BLS public keys of its members.
+func NewValidatorSet(addresses []common.Address, publicKeys [][PublicKeyLength]byte) (*ValidatorSet, error) {
+	s := new(ValidatorSet)
+	if err := s.add(addresses, publicKeys); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Size returns the number of validators in the set.
+func (s *ValidatorSet) Size() int { return len(s.validators) }
+
+// MinQuorumSize returns the minimum number of validators that must sign a block.
+func (s *ValidatorSet) MinQuorumSize() int { return int(math.Ceil(float64(2*s.Size()) / 3)) }
+
+// List returns the validators of the set, in order.
+func (s *ValidatorSet) List() []Validator {
+	list := make([]Validator, len(s.validators))
+	for i, v := range s.validators {
+		list[i] = *v
+	}
+	return list
+}
+
+// Copy returns a copy of the validator set.
+func (s *ValidatorSet) Copy() *ValidatorSet {
+	return &ValidatorSet{validators: append([]*Validator{}, s.validators...)}
+}
+
+// Apply updates the set with the validator changes of an epoch block: the
+// removed validators are filtered out and the added ones appended.
+func (s *ValidatorSet) Apply(extra *Extra) error {
+	if extra.RemovedValidators != nil && extra.RemovedValidators.BitLen() > len(s.validators) {
+		return ErrInvalidValidatorSetDiff
+	}
+	kept := make([]*Validator, 0, len(s.validators))
+	for i, v := range s.validators {
+		if extra.RemovedValidators == nil || extra.RemovedValidators.Bit(i) == 0 {
+			kept = append(kept, v)
+		}
+	}
+	next := &ValidatorSet{validators: kept}
+	if err := next.add(extra.AddedValidators, extra.AddedValidatorsPublicKeys); err != nil {
+		return err
+	}
+	s.validators = next.validators
+	return nil
+}
+
+func (s *ValidatorSet) add(addresses []common.Address, publicKeys [][PublicKeyLength]byte) error {
+	if len(addresses) != len(publicKeys) {
+		return ErrInvalidValidatorSetDiff
+	}
+	present := make(map[common.Address]bool, len(s.validators)+len(addresses))
+	for _, v := range s.validators {
+		present[v.Address] = true
+	}
+	added := make([]*Validator, 0, len(addresses))
+	for i, addr := range addresses {
+		if present[addr] {
+			return ErrInvalidValidatorSetDiff
+		}
+		present[addr] = true
+		key, err := DecodePublicKey(publicKeys[i][:])
+		if err != nil {
+			return err
+		}
+		added = append(added, &Validator{Address: addr, PublicKey: publicKeys[i], key: key})
+	}
+	s.validators = append(s.validators, added...)
+	return nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package lightverify verifies Celo block headers against the validator set
+// that sealed them, tracking the validator set across epochs.
+//
+// Unlike the istanbul backend it only depends on pure Go packages (the BLS12-377
+// curve implementation, Blake2s, Keccak and RLP), so it builds without cgo and
+// for GOOS=js GOARCH=wasm, allowing headers to be verified in a browser.
+package lightverify
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/crypto/bls12377"
+)
+
+var (
+	// ErrInvalidAggregatedSeal is returned if the aggregated seal of a header is malformed.
+	ErrInvalidAggregatedSeal = errors.New("invalid aggregated seal")
+	// ErrInsufficientSeals is returned if fewer validators than the quorum signed a header.
+	ErrInsufficientSeals = errors.New("not enough seals to reach quorum")
+	// ErrUnexpectedBlock is returned if a header does not belong to the epoch
+	// of the tracked validator set.
+	ErrUnexpectedBlock = errors.New("header is not part of the current epoch")
+)
+
+// VerifyAggregatedSeal checks that the aggregated seal was created for the
+// given block hash by a quorum of the validator set.
+func VerifyAggregatedSeal(validators *ValidatorSet, hash common.Hash, seal AggregatedSeal) error {
+	if len(seal.Signature) != SignatureLength || seal.Bitmap == nil || seal.Round == nil {
+		return ErrInvalidAggregatedSeal
+	}
+	if seal.Bitmap.BitLen() > validators.Size() {
+		return ErrInvalidAggregatedSeal
+	}
+	keys := make([]*bls12377.PointG2, 0, validators.Size())
+	for i, v := range validators.validators {
+		if seal.Bitmap.Bit(i) == 1 {
+			keys = append(keys, v.key)
+		}
+	}
+	if len(keys) < validators.MinQuorumSize() {
+		return ErrInsufficientSeals
+	}
+	sig, err := DecodeSignature(seal.Signature)
+	if err != nil {
+		return err
+	}
+	return VerifySignature(AggregatePublicKeys(keys), committedSeal(hash, seal.Round), []byte{}, sig)
+}
+
+// Verifier verifies headers in ascending order starting from a trusted
+// validator set, following the validator set changes of epoch blocks.
+type Verifier struct {
+	epochSize  uint64
+	number     uint64
+	validators *ValidatorSet
+}
+
+// NewVerifier creates a verifier trusting the validator set in charge of the
+// epoch starting after the given block, which must be the genesis block or
+// the last block of an epoch.
+func NewVerifier(epochSize, number uint64, validators *ValidatorSet) (*Verifier, error) {
+	if epochSize == 0 || number%epochSize != 0 {
+		return nil, fmt.Errorf("block %d is not the last block of an epoch", number)
+	}
+	return &Verifier{epochSize: epochSize, number: number, validators: validators.Copy()}, nil
+}
+
+// NewVerifierFromGenesis creates a verifier trusting the validator set
+// registered in the genesis header.
+func NewVerifierFromGenesis(epochSize uint64, genesis *Header) (*Verifier, error) {
+	if genesis.Number == nil || genesis.Number.Sign() != 0 {
+		return nil, errors.New("not a genesis header")
+	}
+	extra, err := DecodeExtra(genesis)
+	if err != nil {
+		return nil, err
+	}
+	validators, err := NewValidatorSet(extra.AddedValidators, extra.AddedValidatorsPublicKeys)
+	if err != nil {
+		return nil, err
+	}
+	return NewVerifier(epochSize, 0, validators)
+}
+
+// Number returns the number of the last epoch block applied to the validator set.
+func (v *Verifier) Number() uint64 { return v.number }
+
+// Validators returns a copy of the validator set of the current epoch.
+func (v *Verifier) Validators() *ValidatorSet { return v.validators.Copy() }
+
+// VerifyHeader verifies the aggregated seal of a header of the current epoch.
+// Headers within the epoch may be skipped, but the last block of every epoch
+// must be verified to move on to the next validator set.
+func (v *Verifier) VerifyHeader(h *Header) error {
+	if h.Number == nil || !h.Number.IsUint64() {
+		return ErrUnexpectedBlock
+	}
+	number := h.Number.Uint64()
+	if number <= v.number || number > v.number+v.epochSize {
+		return ErrUnexpectedBlock
+	}
+	extra, err := DecodeExtra(h)
+	if err != nil {
+		return err
+	}
+	hash, err := h.Hash()
+	if err != nil {
+		return err
+	}
+	if err := VerifyAggregatedSeal(v.validators, hash, extra.AggregatedSeal); err != nil {
+		return err
+	}
+	if number == v.number+v.epochSize {
+		next := v.validators.Copy()
+		if err := next.Apply(extra); err != nil {
+			return err
+		}
+		v.validators, v.number = next, number
+	}
+	return nil
+}
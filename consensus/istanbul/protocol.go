@@ -29,6 +29,7 @@ const (
 
 	// Supported versions
 	Celo66 = 66 // incorporates changes from eth/65 (EIP-2464)
+	Celo67 = 67 // adds the fork configuration to the status message
 )
 
 // protocolName is the official short name of the protocol used during capability negotiation.
@@ -36,10 +37,10 @@ const ProtocolName = "istanbul"
 
 // ProtocolVersions are the supported versions of the istanbul protocol (first is primary).
 // (First is primary in the sense that it's the most current one supported)
-var ProtocolVersions = []uint{Celo66}
+var ProtocolVersions = []uint{Celo67, Celo66}
 
 // protocolLengths are the number of implemented message corresponding to different protocol versions.
-var ProtocolLengths = map[uint]uint64{Celo64: 22, Celo65: 27, Celo66: 27}
+var ProtocolLengths = map[uint]uint64{Celo64: 22, Celo65: 27, Celo66: 30, Celo67: 30}
 
 // Message codes for istanbul related messages
 // If you want to add a code, you need to increment the protocolLengths Array size
@@ -53,10 +54,15 @@ const (
 	VersionCertificatesMsg = 0x16
 	EnodeCertificateMsg    = 0x17
 	ValidatorHandshakeMsg  = 0x18
+	BlockChunkMsg          = 0x19
+	ConsensusRelayMsg      = 0x1a
+	ConsensusAnnounceMsg   = 0x1b
+	ConsensusPullMsg       = 0x1c
+	ConsensusObserveMsg    = 0x1d
 )
 
 func IsIstanbulMsg(msg p2p.Msg) bool {
-	return msg.Code >= ConsensusMsg && msg.Code <= ValidatorHandshakeMsg
+	return msg.Code >= ConsensusMsg && msg.Code <= ConsensusObserveMsg
 }
 
 // IsGossipedMsg specifies which messages should be gossiped throughout the network (as opposed to directly sent to a peer).
@@ -21,9 +21,9 @@ import (
 	"github.com/celo-org/celo-blockchain/consensus/istanbul"
 )
 
-// handleConsensusMsg is invoked by the proxy to forward valid consensus messages to
-// it's proxied validator
-func (p *proxyEngine) handleConsensusMsg(peer consensus.Peer, payload []byte) (bool, error) {
+// handleConsensusMsg is invoked by the proxy to forward valid consensus messages, and
+// block chunks, to it's proxied validator
+func (p *proxyEngine) handleConsensusMsg(peer consensus.Peer, msgCode uint64, payload []byte) (bool, error) {
 	logger := p.logger.New("func", "handleConsensusMsg")
 
 	// Verify that this message is not from the proxied validator
@@ -37,7 +37,7 @@ func (p *proxyEngine) handleConsensusMsg(peer consensus.Peer, payload []byte) (b
 	msg := new(istanbul.Message)
 
 	// Verify that this message is created by a legitimate validator before forwarding to the proxied validator.
-	if err := msg.FromPayload(payload, p.backend.VerifyPendingBlockValidatorSignature); err != nil {
+	if err := msg.FromPayloadInDomains(payload, p.backend.SigningDomains().Verify, p.backend.VerifyPendingBlockValidatorSignature); err != nil {
 		logger.Error("Got a consensus message signed by a validator not within the pending block validator set.", "err", err)
 		return true, istanbul.ErrUnauthorizedAddress
 	}
@@ -45,7 +45,7 @@ func (p *proxyEngine) handleConsensusMsg(peer consensus.Peer, payload []byte) (b
 	// Need to forward the message to the proxied validators
 	logger.Trace("Forwarding consensus message to proxied validators", "from", peer.Node().ID())
 	for proxiedValidator := range p.proxiedValidators {
-		p.backend.Unicast(proxiedValidator, payload, istanbul.ConsensusMsg)
+		p.backend.Unicast(proxiedValidator, payload, msgCode)
 	}
 
 	return true, nil
@@ -34,7 +34,7 @@ func (p *proxyEngine) handleEnodeCertificateMsgFromRemoteVal(peer consensus.Peer
 
 	// Verify that this message is created by a legitimate validator before forwarding to the proxied validator (it should
 	// be within the validator connection set).
-	if err := msg.FromPayload(payload, p.backend.VerifyValidatorConnectionSetSignature); err != nil {
+	if err := msg.FromPayloadInDomains(payload, p.backend.SigningDomains().Verify, p.backend.VerifyValidatorConnectionSetSignature); err != nil {
 		logger.Error("Got an enodeCertificate message signed by a validator not within the validator connection set.", "err", err)
 		return true, istanbul.ErrUnauthorizedAddress
 	}
@@ -59,7 +59,7 @@ func (p *proxyEngine) handleEnodeCertificateMsgFromProxiedValidator(peer consens
 
 	msg := new(istanbul.Message)
 	// Decode message
-	err := msg.FromPayload(payload, istanbul.GetSignatureAddress)
+	err := msg.FromPayloadInDomains(payload, p.backend.SigningDomains().Verify, istanbul.GetSignatureAddress)
 	if err != nil {
 		logger.Error("Error in decoding received Enode Certificate message from forward message", "err", err, "payload", hex.EncodeToString(payload))
 		return false, err
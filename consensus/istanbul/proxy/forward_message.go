@@ -39,7 +39,7 @@ func (pv *proxiedValidatorEngine) sendForwardMsg(ps *proxySet, destAddresses []c
 			}, pv.backend.Address())
 
 			// Sign the message
-			if err := msg.Sign(pv.backend.Sign); err != nil {
+			if err := msg.SignInDomain(pv.backend.SigningDomains().Sign, pv.backend.Sign); err != nil {
 				logger.Error("Error in signing an Istanbul Forward Message", "ForwardMsg", msg.String(), "err", err)
 				return err
 			}
@@ -71,7 +71,7 @@ func (p *proxyEngine) handleForwardMsg(peer consensus.Peer, payload []byte) (boo
 
 	istMsg := new(istanbul.Message)
 
-	if err := istMsg.FromPayload(payload, istanbul.GetSignatureAddress); err != nil {
+	if err := istMsg.FromPayloadInDomains(payload, p.backend.SigningDomains().Verify, istanbul.GetSignatureAddress); err != nil {
 		logger.Error("Failed to decode message from payload", "from", peer.Node().ID(), "err", err)
 		return true, err
 	}
@@ -45,6 +45,9 @@ type BackendForProxiedValidatorEngine interface {
 	// Sign signs input data with the validator's ecdsa signing key
 	Sign([]byte) ([]byte, error)
 
+	// SigningDomains returns the domains messages are signed and verified in
+	SigningDomains() istanbul.SigningDomains
+
 	// Multicast sends a message to it's connected nodes filtered on the 'addresses' parameter (where each address
 	// is associated with those node's signing key)
 	// If sendToSelf is set to true, then the function will send an event to self via a message event
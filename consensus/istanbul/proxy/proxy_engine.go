@@ -64,6 +64,9 @@ type BackendForProxyEngine interface {
 	// validator connection set and that the message's address field matches the message's signature's signer
 	VerifyValidatorConnectionSetSignature(data []byte, sig []byte) (common.Address, error)
 
+	// SigningDomains returns the domains messages are signed and verified in
+	SigningDomains() istanbul.SigningDomains
+
 	// GetProxy returns the proxy engine created for this Backend.  Note: This should be only used for the unit tests.
 	GetProxyEngine() ProxyEngine
 }
@@ -101,8 +104,8 @@ func (p *proxyEngine) HandleMsg(peer consensus.Peer, msgCode uint64, payload []b
 		return p.handleValEnodesShareMsg(peer, payload)
 	} else if msgCode == istanbul.FwdMsg {
 		return p.handleForwardMsg(peer, payload)
-	} else if msgCode == istanbul.ConsensusMsg {
-		return p.handleConsensusMsg(peer, payload)
+	} else if msgCode == istanbul.ConsensusMsg || msgCode == istanbul.BlockChunkMsg {
+		return p.handleConsensusMsg(peer, msgCode, payload)
 	} else if msgCode == istanbul.EnodeCertificateMsg {
 		// See if the message is coming from the proxied validator
 		p.proxiedValidatorsMu.RLock()
@@ -56,7 +56,7 @@ func (pv *proxiedValidatorEngine) generateValEnodesShareMsg(remoteValidators []c
 	}, pv.backend.Address())
 
 	// Sign the validator enode share message
-	if err := msg.Sign(pv.backend.Sign); err != nil {
+	if err := msg.SignInDomain(pv.backend.SigningDomains().Sign, pv.backend.Sign); err != nil {
 		logger.Error("Error in signing an Istanbul ValEnodesShare Message", "ValEnodesShareMsg", msg.String(), "err", err)
 		return nil, err
 	}
@@ -115,7 +115,7 @@ func (p *proxyEngine) handleValEnodesShareMsg(peer consensus.Peer, payload []byt
 	p.proxiedValidatorsMu.RUnlock()
 	msg := new(istanbul.Message)
 	// Decode message
-	err := msg.FromPayload(payload, istanbul.GetSignatureAddress)
+	err := msg.FromPayloadInDomains(payload, p.backend.SigningDomains().Verify, istanbul.GetSignatureAddress)
 	if err != nil {
 		logger.Error("Error in decoding received Istanbul Validator Enode Share message", "err", err, "payload", hex.EncodeToString(payload), "sender address", msg.Address)
 		return true, err
@@ -0,0 +1,148 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package testvectors
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/consensus/istanbul"
+	istanbulCore "github.com/celo-org/celo-blockchain/consensus/istanbul/core"
+	"github.com/celo-org/celo-blockchain/core/types"
+	blscrypto "github.com/celo-org/celo-blockchain/crypto/bls"
+	"github.com/celo-org/celo-blockchain/rlp"
+)
+
+var errInsufficientSeals = errors.New("not enough seals to reach quorum")
+
+// Client is a consensus implementation run against the vectors.
+type Client interface {
+	// DecodeMessage decodes a signed message payload, returning its code, the
+	// encoding of its inner message and the address recovered from its signature.
+	DecodeMessage(payload []byte) (code uint64, msg []byte, signer common.Address, err error)
+
+	// VerifySeal verifies the encoded aggregated seal commits to the hash, signed
+	// by a quorum of the validator set.
+	VerifySeal(hash common.Hash, seal []byte, validators istanbul.ValidatorSet) error
+
+	// DecodeExtra decodes the Istanbul extra-data of a header.
+	DecodeExtra(extra []byte) (*types.IstanbulExtra, error)
+}
+
+// Reference is the client of the reference node.
+type Reference struct{}
+
+// DecodeMessage implements Client.
+func (Reference) DecodeMessage(payload []byte) (uint64, []byte, common.Address, error) {
+	var msg istanbul.Message
+	var signer common.Address
+	err := msg.FromPayload(payload, func(data, sig []byte) (common.Address, error) {
+		addr, err := istanbul.GetSignatureAddress(data, sig)
+		signer = addr
+		return addr, err
+	})
+	if err != nil && err != istanbul.ErrInvalidSigner {
+		return 0, nil, common.Address{}, err
+	}
+	return msg.Code, msg.Msg, signer, nil
+}
+
+// VerifySeal implements Client.
+func (Reference) VerifySeal(hash common.Hash, seal []byte, validators istanbul.ValidatorSet) error {
+	var aggregatedSeal types.IstanbulAggregatedSeal
+	if err := rlp.DecodeBytes(seal, &aggregatedSeal); err != nil {
+		return err
+	}
+	var publicKeys []blscrypto.SerializedPublicKey
+	for i := 0; i < validators.Size(); i++ {
+		if aggregatedSeal.Bitmap.Bit(i) == 1 {
+			publicKeys = append(publicKeys, validators.GetByIndex(uint64(i)).BLSPublicKey())
+		}
+	}
+	if len(publicKeys) < validators.MinQuorumSize() {
+		return errInsufficientSeals
+	}
+	proposalSeal := istanbulCore.PrepareCommittedSeal(hash, aggregatedSeal.Round)
+	return blscrypto.VerifyAggregatedSignature(publicKeys, proposalSeal, []byte{}, aggregatedSeal.Signature, false, false)
+}
+
+// DecodeExtra implements Client.
+func (Reference) DecodeExtra(extra []byte) (*types.IstanbulExtra, error) {
+	return types.ExtractIstanbulExtra(&types.Header{Extra: extra})
+}
+
+// Run runs the client against every vector of the suite, returning the
+// failures of the vectors it doesn't conform to.
+func Run(client Client, suite *Suite) []error {
+	var failures []error
+	fail := func(kind, name string, format string, args ...interface{}) {
+		failures = append(failures, fmt.Errorf("%s %q: %s", kind, name, fmt.Sprintf(format, args...)))
+	}
+	for _, v := range suite.Messages {
+		code, msg, signer, err := client.DecodeMessage(v.Payload)
+		switch {
+		case err != nil:
+			fail("message", v.Name, "decoding failed: %v", err)
+		case code != v.Code:
+			fail("message", v.Name, "code %d, want %d", code, v.Code)
+		case !bytes.Equal(msg, v.Msg):
+			fail("message", v.Name, "inner message %x, want %x", msg, v.Msg)
+		case (signer == v.Sender) != v.Valid:
+			fail("message", v.Name, "signed by %v, sent by %v, want valid %t", signer, v.Sender, v.Valid)
+		}
+	}
+	validators := suite.ValidatorSet()
+	for _, v := range suite.Seals {
+		if err := client.VerifySeal(v.Hash, v.Seal, validators); (err == nil) != v.Valid {
+			fail("seal", v.Name, "verification error %v, want valid %t", err, v.Valid)
+		}
+	}
+	for _, v := range suite.Extras {
+		extra, err := client.DecodeExtra(v.Extra)
+		if (err == nil) != v.Valid {
+			fail("extra", v.Name, "decoding error %v, want valid %t", err, v.Valid)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if len(extra.AddedValidators) != len(v.AddedValidators) {
+			fail("extra", v.Name, "%d added validators, want %d", len(extra.AddedValidators), len(v.AddedValidators))
+			continue
+		}
+		for i, addr := range v.AddedValidators {
+			if extra.AddedValidators[i] != addr {
+				fail("extra", v.Name, "added validator %d is %v, want %v", i, extra.AddedValidators[i], addr)
+			}
+		}
+		if len(extra.AddedValidatorsPublicKeys) != len(v.AddedValidatorsPublicKeys) {
+			fail("extra", v.Name, "%d added public keys, want %d", len(extra.AddedValidatorsPublicKeys), len(v.AddedValidatorsPublicKeys))
+			continue
+		}
+		for i, key := range v.AddedValidatorsPublicKeys {
+			if extra.AddedValidatorsPublicKeys[i] != key {
+				fail("extra", v.Name, "added public key %d is %x, want %x", i, extra.AddedValidatorsPublicKeys[i], key)
+			}
+		}
+		if v.RemovedValidators != nil && extra.RemovedValidators.Cmp(v.RemovedValidators.ToInt()) != 0 {
+			fail("extra", v.Name, "removed validators %b, want %b", extra.RemovedValidators, v.RemovedValidators.ToInt())
+		}
+	}
+	return failures
+}
@@ -0,0 +1,321 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package testvectors generates canonical Istanbul consensus test vectors, the
+// signed messages, aggregated seals and header extra-data of the reference node,
+// and runs alternative implementations against them.
+//
+// The vectors are derived from fixed validator keys, so generating them twice
+// yields the same suite. The suite in testdata/vectors.json is kept in sync with
+// the generator, and can be consumed by clients written in other languages.
+package testvectors
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/common/hexutil"
+	"github.com/celo-org/celo-blockchain/consensus/istanbul"
+	istanbulCore "github.com/celo-org/celo-blockchain/consensus/istanbul/core"
+	"github.com/celo-org/celo-blockchain/consensus/istanbul/validator"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/crypto"
+	blscrypto "github.com/celo-org/celo-blockchain/crypto/bls"
+	"github.com/celo-org/celo-blockchain/rlp"
+	"github.com/celo-org/celo-bls-go/bls"
+)
+
+// Suite holds the test vectors of each kind.
+type Suite struct {
+	Validators []Validator     `json:"validators"`
+	Messages   []MessageVector `json:"messages"`
+	Seals      []SealVector    `json:"seals"`
+	Extras     []ExtraVector   `json:"extras"`
+}
+
+// Validator is a member of the validator set signing the vectors.
+type Validator struct {
+	Address      common.Address                `json:"address"`
+	BLSPublicKey blscrypto.SerializedPublicKey `json:"blsPublicKey"`
+}
+
+// MessageVector is a signed consensus message, as sent over the wire.
+type MessageVector struct {
+	Name    string         `json:"name"`
+	Code    uint64         `json:"code"`
+	Sender  common.Address `json:"sender"`
+	Msg     hexutil.Bytes  `json:"msg"`     // Encoding of the inner message
+	Payload hexutil.Bytes  `json:"payload"` // Encoding of the signed message
+	Valid   bool           `json:"valid"`   // Whether the payload is signed by the sender
+}
+
+// SealVector is an aggregated seal committing to a block hash in a round.
+type SealVector struct {
+	Name  string        `json:"name"`
+	Hash  common.Hash   `json:"hash"`
+	Seal  hexutil.Bytes `json:"seal"`  // Encoding of the aggregated seal
+	Valid bool          `json:"valid"` // Whether the seal is signed by a quorum of the validators
+}
+
+// ExtraVector is the Istanbul extra-data of a header.
+type ExtraVector struct {
+	Name                      string                          `json:"name"`
+	Extra                     hexutil.Bytes                   `json:"extra"` // Header extra-data, vanity included
+	AddedValidators           []common.Address                `json:"addedValidators,omitempty"`
+	AddedValidatorsPublicKeys []blscrypto.SerializedPublicKey `json:"addedValidatorsPublicKeys,omitempty"`
+	RemovedValidators         *hexutil.Big                    `json:"removedValidators,omitempty"`
+	Valid                     bool                            `json:"valid"` // Whether the extra-data decodes
+}
+
+// Read decodes a suite from its JSON encoding.
+func Read(r io.Reader) (*Suite, error) {
+	var suite Suite
+	if err := json.NewDecoder(r).Decode(&suite); err != nil {
+		return nil, err
+	}
+	return &suite, nil
+}
+
+// Write encodes the suite as JSON.
+func (s *Suite) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// signer holds the keys of a validator.
+type signer struct {
+	key    *ecdsa.PrivateKey
+	blsKey []byte
+}
+
+func newSigner(index int) (*signer, error) {
+	key, err := crypto.ToECDSA(crypto.Keccak256([]byte(fmt.Sprintf("istanbul test vector validator %d", index))))
+	if err != nil {
+		return nil, err
+	}
+	blsKey, err := blscrypto.ECDSAToBLS(key)
+	if err != nil {
+		return nil, err
+	}
+	return &signer{key: key, blsKey: blsKey}, nil
+}
+
+func (s *signer) address() common.Address {
+	return crypto.PubkeyToAddress(s.key.PublicKey)
+}
+
+func (s *signer) sign(data []byte) ([]byte, error) {
+	return crypto.Sign(crypto.Keccak256(data), s.key)
+}
+
+func (s *signer) signBLS(data []byte) ([]byte, error) {
+	privateKey, err := bls.DeserializePrivateKey(s.blsKey)
+	if err != nil {
+		return nil, err
+	}
+	defer privateKey.Destroy()
+
+	signature, err := privateKey.SignMessage(data, []byte{}, false, false)
+	if err != nil {
+		return nil, err
+	}
+	defer signature.Destroy()
+	return signature.Serialize()
+}
+
+// Generate generates the suite, signed by the given number of validators.
+func Generate(validators int) (*Suite, error) {
+	if validators < 2 {
+		return nil, fmt.Errorf("%d validators, at least 2 required", validators)
+	}
+	suite := new(Suite)
+	signers := make([]*signer, validators)
+	for i := range signers {
+		s, err := newSigner(i)
+		if err != nil {
+			return nil, err
+		}
+		publicKey, err := blscrypto.PrivateToPublic(s.blsKey)
+		if err != nil {
+			return nil, err
+		}
+		signers[i] = s
+		suite.Validators = append(suite.Validators, Validator{Address: s.address(), BLSPublicKey: publicKey})
+	}
+	block := types.NewBlockWithHeader(&types.Header{
+		Number:   big.NewInt(1),
+		GasUsed:  21000,
+		Time:     1,
+		Extra:    make([]byte, types.IstanbulExtraVanity),
+		Coinbase: signers[0].address(),
+	})
+	view := &istanbul.View{Sequence: big.NewInt(1), Round: big.NewInt(0)}
+	subject := &istanbul.Subject{View: view, Digest: block.Hash()}
+
+	if err := suite.generateMessages(signers, block, subject); err != nil {
+		return nil, err
+	}
+	if err := suite.generateSeals(signers, subject); err != nil {
+		return nil, err
+	}
+	if err := suite.generateExtras(); err != nil {
+		return nil, err
+	}
+	return suite, nil
+}
+
+func (s *Suite) generateMessages(signers []*signer, block *types.Block, subject *istanbul.Subject) error {
+	committedSeal, err := signers[0].signBLS(istanbulCore.PrepareCommittedSeal(subject.Digest, subject.View.Round))
+	if err != nil {
+		return err
+	}
+	sender := signers[0].address()
+	messages := []struct {
+		name string
+		msg  *istanbul.Message
+	}{
+		{"preprepare", istanbul.NewPreprepareMessage(&istanbul.Preprepare{View: subject.View, Proposal: block}, sender)},
+		{"prepare", istanbul.NewPrepareMessage(subject, sender)},
+		{"commit", istanbul.NewCommitMessage(&istanbul.CommittedSubject{Subject: subject, CommittedSeal: committedSeal, EpochValidatorSetSeal: []byte{}}, sender)},
+		{"roundchange", istanbul.NewRoundChangeMessage(&istanbul.RoundChange{
+			View:                &istanbul.View{Sequence: subject.View.Sequence, Round: big.NewInt(1)},
+			PreparedCertificate: istanbul.EmptyPreparedCertificate(),
+		}, sender)},
+	}
+	for _, m := range messages {
+		if err := m.msg.Sign(signers[0].sign); err != nil {
+			return err
+		}
+		payload, err := m.msg.Payload()
+		if err != nil {
+			return err
+		}
+		s.Messages = append(s.Messages, MessageVector{Name: m.name, Code: m.msg.Code, Sender: sender, Msg: m.msg.Msg, Payload: payload, Valid: true})
+	}
+	// A message claiming to be sent by another validator than its signer
+	forged := istanbul.NewPrepareMessage(subject, signers[1].address())
+	if err := forged.Sign(signers[0].sign); err != nil {
+		return err
+	}
+	payload, err := forged.Payload()
+	if err != nil {
+		return err
+	}
+	s.Messages = append(s.Messages, MessageVector{Name: "prepare forged sender", Code: forged.Code, Sender: forged.Address, Msg: forged.Msg, Payload: payload})
+	return nil
+}
+
+func (s *Suite) generateSeals(signers []*signer, subject *istanbul.Subject) error {
+	seal := istanbulCore.PrepareCommittedSeal(subject.Digest, subject.View.Round)
+	signatures := make([][]byte, len(signers))
+	for i, signer := range signers {
+		signature, err := signer.signBLS(seal)
+		if err != nil {
+			return err
+		}
+		signatures[i] = signature
+	}
+	quorum := s.ValidatorSet().MinQuorumSize()
+
+	add := func(name string, signed []int, claimed []int, valid bool) error {
+		var aggregated [][]byte
+		for _, i := range signed {
+			aggregated = append(aggregated, signatures[i])
+		}
+		signature, err := blscrypto.AggregateSignatures(aggregated)
+		if err != nil {
+			return err
+		}
+		bitmap := new(big.Int)
+		for _, i := range claimed {
+			bitmap.SetBit(bitmap, i, 1)
+		}
+		encoded, err := rlp.EncodeToBytes(&types.IstanbulAggregatedSeal{Bitmap: bitmap, Signature: signature, Round: subject.View.Round})
+		if err != nil {
+			return err
+		}
+		s.Seals = append(s.Seals, SealVector{Name: name, Hash: subject.Digest, Seal: encoded, Valid: valid})
+		return nil
+	}
+	all := make([]int, len(signers))
+	for i := range all {
+		all[i] = i
+	}
+	if err := add("all validators", all, all, true); err != nil {
+		return err
+	}
+	if err := add("quorum", all[:quorum], all[:quorum], true); err != nil {
+		return err
+	}
+	if err := add("below quorum", all[:quorum-1], all[:quorum-1], false); err != nil {
+		return err
+	}
+	// The bitmap claims a validator whose signature is not aggregated
+	return add("bitmap mismatch", all[:quorum], all[1:quorum+1], false)
+}
+
+func (s *Suite) generateExtras() error {
+	add := func(name string, extra *types.IstanbulExtra) error {
+		payload, err := rlp.EncodeToBytes(extra)
+		if err != nil {
+			return err
+		}
+		s.Extras = append(s.Extras, ExtraVector{
+			Name:                      name,
+			Extra:                     append(make([]byte, types.IstanbulExtraVanity), payload...),
+			AddedValidators:           extra.AddedValidators,
+			AddedValidatorsPublicKeys: extra.AddedValidatorsPublicKeys,
+			RemovedValidators:         (*hexutil.Big)(extra.RemovedValidators),
+			Valid:                     true,
+		})
+		return nil
+	}
+	empty := &types.IstanbulExtra{
+		AddedValidators:           []common.Address{},
+		AddedValidatorsPublicKeys: []blscrypto.SerializedPublicKey{},
+		RemovedValidators:         new(big.Int),
+		Seal:                      []byte{},
+		AggregatedSeal:            types.IstanbulAggregatedSeal{Bitmap: new(big.Int), Signature: []byte{}, Round: new(big.Int)},
+		ParentAggregatedSeal:      types.IstanbulAggregatedSeal{Bitmap: new(big.Int), Signature: []byte{}, Round: new(big.Int)},
+	}
+	if err := add("empty", empty); err != nil {
+		return err
+	}
+	changed := *empty
+	changed.AddedValidators = []common.Address{s.Validators[0].Address}
+	changed.AddedValidatorsPublicKeys = []blscrypto.SerializedPublicKey{s.Validators[0].BLSPublicKey}
+	changed.RemovedValidators = big.NewInt(2)
+	if err := add("validator set diff", &changed); err != nil {
+		return err
+	}
+	// Extra-data shorter than the vanity
+	s.Extras = append(s.Extras, ExtraVector{Name: "truncated vanity", Extra: make([]byte, types.IstanbulExtraVanity-1)})
+	return nil
+}
+
+// ValidatorSet returns the validator set signing the vectors.
+func (s *Suite) ValidatorSet() istanbul.ValidatorSet {
+	validators := make([]istanbul.ValidatorData, len(s.Validators))
+	for i, v := range s.Validators {
+		validators[i] = istanbul.ValidatorData{Address: v.Address, BLSPublicKey: v.BLSPublicKey}
+	}
+	return validator.NewSet(validators)
+}
@@ -0,0 +1,108 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package testvectors
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/consensus/istanbul"
+	"github.com/celo-org/celo-blockchain/core/types"
+)
+
+var update = flag.Bool("update", false, "regenerate the test vectors in testdata")
+
+var suitePath = filepath.Join("testdata", "vectors.json")
+
+func TestSuiteUpToDate(t *testing.T) {
+	suite, err := Generate(4)
+	if err != nil {
+		t.Fatalf("failed to generate the suite: %v", err)
+	}
+	var generated bytes.Buffer
+	if err := suite.Write(&generated); err != nil {
+		t.Fatalf("failed to encode the suite: %v", err)
+	}
+	if *update {
+		if err := ioutil.WriteFile(suitePath, generated.Bytes(), 0644); err != nil {
+			t.Fatalf("failed to write the suite: %v", err)
+		}
+	}
+	stored, err := ioutil.ReadFile(suitePath)
+	if err != nil {
+		t.Fatalf("failed to read the suite: %v", err)
+	}
+	if !bytes.Equal(generated.Bytes(), stored) {
+		t.Errorf("%s is out of date, regenerate it with go test -run TestSuiteUpToDate -update", suitePath)
+	}
+}
+
+func TestReferenceConformance(t *testing.T) {
+	stored, err := ioutil.ReadFile(suitePath)
+	if err != nil {
+		t.Fatalf("failed to read the suite: %v", err)
+	}
+	suite, err := Read(bytes.NewReader(stored))
+	if err != nil {
+		t.Fatalf("failed to decode the suite: %v", err)
+	}
+	if len(suite.Messages) == 0 || len(suite.Seals) == 0 || len(suite.Extras) == 0 {
+		t.Fatalf("suite missing vectors: %d messages, %d seals, %d extras", len(suite.Messages), len(suite.Seals), len(suite.Extras))
+	}
+	for _, err := range Run(Reference{}, suite) {
+		t.Error(err)
+	}
+}
+
+// lenientClient accepts any seal, as a client skipping the seal verification.
+type lenientClient struct {
+	Reference
+}
+
+func (lenientClient) VerifySeal(common.Hash, []byte, istanbul.ValidatorSet) error { return nil }
+
+// decodingClient decodes the extra-data without stripping its vanity.
+type decodingClient struct {
+	Reference
+}
+
+func (decodingClient) DecodeExtra(extra []byte) (*types.IstanbulExtra, error) {
+	return types.ExtractIstanbulExtra(&types.Header{Extra: append(make([]byte, types.IstanbulExtraVanity), extra...)})
+}
+
+func TestNonConformance(t *testing.T) {
+	suite, err := Generate(4)
+	if err != nil {
+		t.Fatalf("failed to generate the suite: %v", err)
+	}
+	if failures := Run(lenientClient{}, suite); len(failures) == 0 {
+		t.Errorf("client accepting invalid seals conforms")
+	}
+	if failures := Run(decodingClient{}, suite); len(failures) == 0 {
+		t.Errorf("client misreading the extra-data conforms")
+	}
+}
+
+func TestGenerateValidators(t *testing.T) {
+	if _, err := Generate(1); err == nil {
+		t.Errorf("suite generated with a single validator")
+	}
+}
@@ -394,6 +394,34 @@ type ForwardMessage struct {
 	DestAddresses []common.Address
 }
 
+// ## BlockChunk ######################################################################
+
+// NewBlockChunkMessage constructs a Message instance with the given sender and
+// blockChunk. Both the blockChunk instance and the serialized bytes of
+// blockChunk are part of the returned Message.
+func NewBlockChunkMessage(blockChunk *BlockChunk, sender common.Address) *Message {
+	message := &Message{
+		Address:    sender,
+		Code:       BlockChunkMsg,
+		blockChunk: blockChunk,
+	}
+	setMessageBytes(message, blockChunk)
+	return message
+}
+
+// BlockChunk is an erasure coded shard of a large consensus message, such as a
+// preprepare carrying a block. The author of the message sends each shard to a
+// single validator, which relays it to the others.
+type BlockChunk struct {
+	Hash        common.Hash    // Hash of the chunked consensus message payload
+	Size        uint64         // Size of the chunked consensus message payload
+	DataShards  uint64         // Number of shards needed to rebuild the payload
+	TotalShards uint64         // Number of data and parity shards
+	Index       uint64         // Index of the shard
+	Recipient   common.Address // Validator relaying the shard to the others
+	Shard       []byte
+}
+
 // ===============================================================
 //
 // define the IstanbulQueryEnode message format, the QueryEnodeMsgCache entries, the queryEnode send function (both the gossip version and the "retrieve from cache" version), and the announce get function
@@ -514,6 +542,7 @@ type Message struct {
 	enodeCertificate    *EnodeCertificate
 	versionCertificates []*VersionCertificate
 	valEnodeShareData   *ValEnodesShareData
+	blockChunk          *BlockChunk
 }
 
 // setMessageBytes sets the Msg field of msg to the rlp serialised bytes of
@@ -527,14 +556,9 @@ func setMessageBytes(msg *Message, innerMessage interface{}) {
 	msg.Msg = bytes
 }
 
+// Sign signs the message with a legacy signature, see SignInDomain.
 func (m *Message) Sign(signingFn func(data []byte) ([]byte, error)) error {
-	// Construct and encode a message with no signature
-	payloadNoSig, err := m.PayloadNoSig()
-	if err != nil {
-		return err
-	}
-	m.Signature, err = signingFn(payloadNoSig)
-	return err
+	return m.SignInDomain(nil, signingFn)
 }
 
 func (m *Message) DecodeRLP(stream *rlp.Stream) error {
@@ -594,6 +618,10 @@ func (m *Message) DecodeRLP(stream *rlp.Stream) error {
 		var v *ValEnodesShareData
 		err = m.decode(&v)
 		m.valEnodeShareData = v
+	case BlockChunkMsg:
+		var c *BlockChunk
+		err = m.decode(&c)
+		m.blockChunk = c
 	default:
 		err = fmt.Errorf("unrecognised message code %d", m.Code)
 	}
@@ -603,31 +631,10 @@ func (m *Message) DecodeRLP(stream *rlp.Stream) error {
 
 // FromPayload decodes b into a Message instance it will set one of the private
 // fields committedSubject, prePrepare, prepare or roundChange depending on the
-// type of the message.
+// type of the message. Only legacy signatures are accepted, see
+// FromPayloadInDomains.
 func (m *Message) FromPayload(b []byte, validateFn func([]byte, []byte) (common.Address, error)) error {
-	// Decode Message
-	err := rlp.DecodeBytes(b, &m)
-	if err != nil {
-		return err
-	}
-
-	// Validate message (on a message without Signature)
-	if validateFn != nil {
-		var payload []byte
-		payload, err = m.PayloadNoSig()
-		if err != nil {
-			return err
-		}
-
-		signed_val_addr, err := validateFn(payload, m.Signature)
-		if err != nil {
-			return err
-		}
-		if signed_val_addr != m.Address {
-			return ErrInvalidSigner
-		}
-	}
-	return nil
+	return m.FromPayloadInDomains(b, LegacySigningDomains.Verify, validateFn)
 }
 
 func (m *Message) Payload() ([]byte, error) {
@@ -698,6 +705,11 @@ func (m *Message) ValEnodesShareData() *ValEnodesShareData {
 	return m.valEnodeShareData
 }
 
+// BlockChunk returns the block chunk if this is a block chunk message.
+func (m *Message) BlockChunk() *BlockChunk {
+	return m.blockChunk
+}
+
 func (m *Message) Copy() *Message {
 	return &Message{
 		Code:      m.Code,
@@ -773,6 +785,7 @@ type AddressEntry struct {
 	HighestKnownVersion          uint
 	NumQueryAttemptsForHKVersion uint
 	LastQueryTimestamp           *time.Time
+	LastUpdatedTimestamp         *time.Time // Time the Version or HighestKnownVersion was last raised
 }
 
 func (ae *AddressEntry) String() string {
@@ -792,6 +805,7 @@ type AddressEntryRLP struct {
 	HighestKnownVersion          uint
 	NumQueryAttemptsForHKVersion uint
 	LastQueryTimestamp           []byte
+	LastUpdatedTimestamp         []byte
 }
 
 // EncodeRLP serializes AddressEntry into the Ethereum RLP format.
@@ -812,6 +826,14 @@ func (ae *AddressEntry) EncodeRLP(w io.Writer) error {
 			return err
 		}
 	}
+	var lastUpdatedTimestampBytes []byte
+	if ae.LastUpdatedTimestamp != nil {
+		var err error
+		lastUpdatedTimestampBytes, err = ae.LastUpdatedTimestamp.MarshalBinary()
+		if err != nil {
+			return err
+		}
+	}
 
 	return rlp.Encode(w, AddressEntryRLP{Address: ae.Address,
 		CompressedPublicKey:          publicKeyBytes,
@@ -819,7 +841,8 @@ func (ae *AddressEntry) EncodeRLP(w io.Writer) error {
 		Version:                      ae.Version,
 		HighestKnownVersion:          ae.HighestKnownVersion,
 		NumQueryAttemptsForHKVersion: ae.NumQueryAttemptsForHKVersion,
-		LastQueryTimestamp:           lastQueryTimestampBytes})
+		LastQueryTimestamp:           lastQueryTimestampBytes,
+		LastUpdatedTimestamp:         lastUpdatedTimestampBytes})
 }
 
 // DecodeRLP implements rlp.Decoder, and load the AddressEntry fields from a RLP stream.
@@ -851,13 +874,22 @@ func (ae *AddressEntry) DecodeRLP(s *rlp.Stream) error {
 		}
 	}
 
+	var lastUpdatedTimestamp *time.Time
+	if len(entry.LastUpdatedTimestamp) > 0 {
+		lastUpdatedTimestamp = &time.Time{}
+		if err := lastUpdatedTimestamp.UnmarshalBinary(entry.LastUpdatedTimestamp); err != nil {
+			return err
+		}
+	}
+
 	*ae = AddressEntry{Address: entry.Address,
 		PublicKey:                    publicKey,
 		Node:                         node,
 		Version:                      entry.Version,
 		HighestKnownVersion:          entry.HighestKnownVersion,
 		NumQueryAttemptsForHKVersion: entry.NumQueryAttemptsForHKVersion,
-		LastQueryTimestamp:           lastQueryTimestamp}
+		LastQueryTimestamp:           lastQueryTimestamp,
+		LastUpdatedTimestamp:         lastUpdatedTimestamp}
 	return nil
 }
 
@@ -11,15 +11,20 @@ import (
 
 var (
 	Registry             *abi.ABI = mustParseAbi("Registry", RegistryStr)
+	Accounts             *abi.ABI = mustParseAbi("Accounts", AccountsStr)
 	BlockchainParameters *abi.ABI = mustParseAbi("BlockchainParameters", BlockchainParametersStr)
 	SortedOracles        *abi.ABI = mustParseAbi("SortedOracles", SortedOraclesStr)
 	ERC20                *abi.ABI = mustParseAbi("ERC20", ERC20Str)
 	FeeCurrency          *abi.ABI = mustParseAbi("FeeCurrency", FeeCurrencyStr)
+	DoubleSigningSlasher *abi.ABI = mustParseAbi("DoubleSigningSlasher", DoubleSigningSlasherStr)
+	DowntimeSlasher      *abi.ABI = mustParseAbi("DowntimeSlasher", DowntimeSlasherStr)
 	Elections            *abi.ABI = mustParseAbi("Elections", ElectionsStr)
 	EpochRewards         *abi.ABI = mustParseAbi("EpochRewards", EpochRewardsStr)
 	Freezer              *abi.ABI = mustParseAbi("Freezer", FreezerStr)
 	GasPriceMinimum      *abi.ABI = mustParseAbi("GasPriceMinimum", GasPriceMinimumStr)
 	GoldToken            *abi.ABI = mustParseAbi("GoldToken", GoldTokenStr)
+	Governance           *abi.ABI = mustParseAbi("Governance", GovernanceStr)
+	LockedGold           *abi.ABI = mustParseAbi("LockedGold", LockedGoldStr)
 	Random               *abi.ABI = mustParseAbi("Random", RandomStr)
 	Validators           *abi.ABI = mustParseAbi("Validators", ValidatorsStr)
 )
@@ -33,14 +38,19 @@ func mustParseAbi(name, abiStr string) *abi.ABI {
 }
 
 var byRegistryId = map[common.Hash]*abi.ABI{
+	params.AccountsRegistryId:             Accounts,
 	params.BlockchainParametersRegistryId: BlockchainParameters,
 	params.SortedOraclesRegistryId:        SortedOracles,
 	params.FeeCurrencyWhitelistRegistryId: FeeCurrency,
+	params.DoubleSigningSlasherRegistryId: DoubleSigningSlasher,
+	params.DowntimeSlasherRegistryId:      DowntimeSlasher,
 	params.ElectionRegistryId:             Elections,
 	params.EpochRewardsRegistryId:         EpochRewards,
 	params.FreezerRegistryId:              Freezer,
 	params.GasPriceMinimumRegistryId:      GasPriceMinimum,
 	params.GoldTokenRegistryId:            GoldToken,
+	params.GovernanceRegistryId:           Governance,
+	params.LockedGoldRegistryId:           LockedGold,
 	params.RandomRegistryId:               Random,
 	params.ValidatorsRegistryId:           Validators,
 }
@@ -0,0 +1,18 @@
+package accounts
+
+import (
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/contracts"
+	"github.com/celo-org/celo-blockchain/contracts/abis"
+	"github.com/celo-org/celo-blockchain/core/vm"
+	"github.com/celo-org/celo-blockchain/params"
+)
+
+var signerToAccountMethod = contracts.NewRegisteredContractMethod(params.AccountsRegistryId, abis.Accounts, "signerToAccount", params.MaxGasForSignerToAccount)
+
+// SignerToAccount returns the account the signer is authorized for, or the signer itself if it is not an authorized signer
+func SignerToAccount(vmRunner vm.EVMRunner, signer common.Address) (common.Address, error) {
+	var account common.Address
+	err := signerToAccountMethod.Query(vmRunner, &account, signer)
+	return account, err
+}
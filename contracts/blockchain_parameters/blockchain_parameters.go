@@ -52,12 +52,12 @@ func getMinimumVersion(vmRunner vm.EVMRunner) (*params.VersionInfo, error) {
 
 // GetIntrinsicGasForAlternativeFeeCurrencyOrDefault retrieves the intrisic gas for transactions that pay gas in
 // with an alternative currency (not CELO).
-// In case of error, it returns the default value
-func GetIntrinsicGasForAlternativeFeeCurrencyOrDefault(vmRunner vm.EVMRunner) uint64 {
+// In case of error, it returns the default value of the given intrinsic gas schedule
+func GetIntrinsicGasForAlternativeFeeCurrencyOrDefault(vmRunner vm.EVMRunner, schedule *params.IntrinsicGasSchedule) uint64 {
 	gas, err := getIntrinsicGasForAlternativeFeeCurrency(vmRunner)
 	if err != nil {
-		log.Trace("Default gas", "gas", params.IntrinsicGasForAlternativeFeeCurrency, "method", "intrinsicGasForAlternativeFeeCurrency")
-		return params.IntrinsicGasForAlternativeFeeCurrency
+		log.Trace("Default gas", "gas", schedule.AlternativeFeeCurrency, "method", "intrinsicGasForAlternativeFeeCurrency")
+		return schedule.AlternativeFeeCurrency
 	}
 	log.Trace("Reading gas", "gas", gas)
 	return gas
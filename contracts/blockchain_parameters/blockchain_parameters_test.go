@@ -53,7 +53,8 @@ func TestGetIntrinsicGasForAlternativeFeeCurrency(t *testing.T) {
 }
 
 func TestGetIntrinsicGasForAlternativeFeeCurrencyOrDefault(t *testing.T) {
-	testutil.TestReturnsDefaultOnFailingRunner(t, params.IntrinsicGasForAlternativeFeeCurrency, GetIntrinsicGasForAlternativeFeeCurrencyOrDefault)
+	testutil.TestReturnsDefaultOnFailingRunner(t, params.IntrinsicGasForAlternativeFeeCurrency, GetIntrinsicGasForAlternativeFeeCurrencyOrDefault, params.DefaultIntrinsicGasSchedule)
+	testutil.TestReturnsDefaultOnFailingRunner(t, uint64(70000), GetIntrinsicGasForAlternativeFeeCurrencyOrDefault, &params.IntrinsicGasSchedule{AlternativeFeeCurrency: 70000})
 	t.Run("should return gas for alternative currency", func(t *testing.T) {
 		g := NewGomegaWithT(t)
 
@@ -65,7 +66,7 @@ func TestGetIntrinsicGasForAlternativeFeeCurrencyOrDefault(t *testing.T) {
 			},
 		)
 
-		gas := GetIntrinsicGasForAlternativeFeeCurrencyOrDefault(runner)
+		gas := GetIntrinsicGasForAlternativeFeeCurrencyOrDefault(runner, params.DefaultIntrinsicGasSchedule)
 		g.Expect(gas).To(Equal(uint64(50000)))
 	})
 }
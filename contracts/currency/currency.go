@@ -59,6 +59,11 @@ func (c *Currency) FromCELO(celoAmount *big.Int) *big.Int {
 	return c.toCELORate.FromBase(celoAmount)
 }
 
+// Rate returns the exchange rate of CELO to the currency
+func (c *Currency) Rate() ExchangeRate {
+	return c.toCELORate
+}
+
 // CmpToCurrency compares a currency amount to an amount in a different currency
 func (c *Currency) CmpToCurrency(currencyAmount *big.Int, sndCurrencyAmount *big.Int, sndCurrency *Currency) int {
 	if c == sndCurrency || c.Address == sndCurrency.Address {
@@ -116,6 +121,16 @@ func (er *ExchangeRate) FromBase(goldAmount *big.Int) *big.Int {
 	return new(big.Int).Div(new(big.Int).Mul(goldAmount, er.numerator), er.denominator)
 }
 
+// Numerator returns the amount of token equivalent to Denominator base units
+func (er *ExchangeRate) Numerator() *big.Int {
+	return new(big.Int).Set(er.numerator)
+}
+
+// Denominator returns the amount of base units equivalent to Numerator token
+func (er *ExchangeRate) Denominator() *big.Int {
+	return new(big.Int).Set(er.denominator)
+}
+
 // CurrencyManager provides an interface to access different fee currencies on a given point in time (header,state)
 // and doing comparison or fetching exchange rates
 //
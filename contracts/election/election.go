@@ -16,12 +16,15 @@
 package election
 
 import (
+	"fmt"
 	"math/big"
 	"sort"
 
 	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/common/math"
 	"github.com/celo-org/celo-blockchain/contracts"
 	"github.com/celo-org/celo-blockchain/contracts/abis"
+	"github.com/celo-org/celo-blockchain/contracts/locked_gold"
 	"github.com/celo-org/celo-blockchain/core/vm"
 	"github.com/celo-org/celo-blockchain/log"
 	"github.com/celo-org/celo-blockchain/params"
@@ -34,6 +37,8 @@ var (
 	getTotalVotesForEligibleValidatorGroupsMethod = contracts.NewRegisteredContractMethod(params.ElectionRegistryId, abis.Elections, "getTotalVotesForEligibleValidatorGroups", params.MaxGasForGetEligibleValidatorGroupsVoteTotals)
 	getGroupEpochRewardsMethod                    = contracts.NewRegisteredContractMethod(params.ElectionRegistryId, abis.Elections, "getGroupEpochRewards", params.MaxGasForGetGroupEpochRewards)
 	distributeEpochRewardsMethod                  = contracts.NewRegisteredContractMethod(params.ElectionRegistryId, abis.Elections, "distributeEpochRewards", params.MaxGasForDistributeEpochRewards)
+	getGroupsVotedForByAccountMethod              = contracts.NewRegisteredContractMethod(params.ElectionRegistryId, abis.Elections, "getGroupsVotedForByAccount", params.MaxGasForGetGroupsVotedFor)
+	getTotalVotesForGroupByAccountMethod          = contracts.NewRegisteredContractMethod(params.ElectionRegistryId, abis.Elections, "getTotalVotesForGroupByAccount", params.MaxGasForGetVotesForGroup)
 )
 
 func GetElectedValidators(vmRunner vm.EVMRunner) ([]common.Address, error) {
@@ -79,6 +84,9 @@ func getTotalVotesForEligibleValidatorGroups(vmRunner vm.EVMRunner) ([]voteTotal
 	if err != nil {
 		return nil, err
 	}
+	if len(groups) != len(values) {
+		return nil, fmt.Errorf("got %d vote totals for %d groups", len(values), len(groups))
+	}
 
 	voteTotals := make([]voteTotal, len(groups))
 	for i, group := range groups {
@@ -124,25 +132,7 @@ func DistributeEpochRewards(vmRunner vm.EVMRunner, groups []common.Address, maxT
 			}
 		}
 
-		// Sorting in descending order is necessary to match the order on-chain.
-		// TODO: We could make this more efficient by only moving the newly vote member.
-		sort.SliceStable(voteTotals, func(j, k int) bool {
-			return voteTotals[j].Value.Cmp(voteTotals[k].Value) > 0
-		})
-
-		lesser := common.ZeroAddress
-		greater := common.ZeroAddress
-		for j, voteTotal := range voteTotals {
-			if voteTotal.Group == group {
-				if j > 0 {
-					greater = voteTotals[j-1].Group
-				}
-				if j+1 < len(voteTotals) {
-					lesser = voteTotals[j+1].Group
-				}
-				break
-			}
-		}
+		lesser, greater := sortVoteTotals(voteTotals, group)
 		err := distributeEpochRewardsMethod.Execute(vmRunner, nil, common.Big0, group, reward, lesser, greater)
 		if err != nil {
 			return totalRewards, err
@@ -151,3 +141,92 @@ func DistributeEpochRewards(vmRunner vm.EVMRunner, groups []common.Address, maxT
 	}
 	return totalRewards, nil
 }
+
+// sortVoteTotals sorts the vote totals after the votes for the group changed, and
+// returns the groups with the next lower and higher totals.
+func sortVoteTotals(voteTotals []voteTotal, group common.Address) (lesser, greater common.Address) {
+	// Sorting in descending order is necessary to match the order on-chain.
+	// TODO: We could make this more efficient by only moving the newly vote member.
+	sort.SliceStable(voteTotals, func(j, k int) bool {
+		return voteTotals[j].Value.Cmp(voteTotals[k].Value) > 0
+	})
+
+	for j, voteTotal := range voteTotals {
+		if voteTotal.Group == group {
+			if j > 0 {
+				greater = voteTotals[j-1].Group
+			}
+			if j+1 < len(voteTotals) {
+				lesser = voteTotals[j+1].Group
+			}
+			break
+		}
+	}
+	return lesser, greater
+}
+
+// VoteDecrements are the arguments of Election.forceDecrementVotes, which the
+// slashing contracts take to revoke the votes of the slashed accounts. They are
+// indexed like the groups the account voted for.
+type VoteDecrements struct {
+	Lessers  []common.Address
+	Greaters []common.Address
+	Indices  []*big.Int
+}
+
+// GetSlashingVoteDecrements returns the vote decrements needed to slash each of the
+// accounts, in order, by the penalty. Like LockedGold.slash, the penalty is capped
+// to the locked gold of the account, and votes are only revoked when the nonvoting
+// locked gold does not cover it, from the last group voted for to the first one.
+func GetSlashingVoteDecrements(vmRunner vm.EVMRunner, accounts []common.Address, penalty *big.Int) ([]VoteDecrements, error) {
+	voteTotals, err := getTotalVotesForEligibleValidatorGroups(vmRunner)
+	if err != nil {
+		return nil, err
+	}
+	decrements := make([]VoteDecrements, len(accounts))
+	for i, account := range accounts {
+		nonvoting, err := locked_gold.GetAccountNonvotingLockedGold(vmRunner, account)
+		if err != nil {
+			return nil, err
+		}
+		if nonvoting.Cmp(penalty) >= 0 {
+			continue
+		}
+		var groups []common.Address
+		if err := getGroupsVotedForByAccountMethod.Query(vmRunner, &groups, account); err != nil {
+			return nil, err
+		}
+		votes := make([]*big.Int, len(groups))
+		locked := new(big.Int).Set(nonvoting)
+		for j, group := range groups {
+			if err := getTotalVotesForGroupByAccountMethod.Query(vmRunner, &votes[j], group, account); err != nil {
+				return nil, err
+			}
+			locked.Add(locked, votes[j])
+		}
+		remaining := new(big.Int).Sub(math.BigMin(penalty, locked), nonvoting)
+		if remaining.Sign() <= 0 {
+			continue
+		}
+		decrements[i] = VoteDecrements{
+			Lessers:  make([]common.Address, len(groups)),
+			Greaters: make([]common.Address, len(groups)),
+			Indices:  make([]*big.Int, len(groups)),
+		}
+		for j := range groups {
+			decrements[i].Indices[j] = big.NewInt(int64(j))
+		}
+		for j := len(groups) - 1; j >= 0 && remaining.Sign() > 0; j-- {
+			value := math.BigMin(remaining, votes[j])
+			remaining = new(big.Int).Sub(remaining, value)
+			for _, voteTotal := range voteTotals {
+				if voteTotal.Group == groups[j] {
+					voteTotal.Value.Sub(voteTotal.Value, value)
+					decrements[i].Lessers[j], decrements[i].Greaters[j] = sortVoteTotals(voteTotals, groups[j])
+					break
+				}
+			}
+		}
+	}
+	return decrements, nil
+}
@@ -6,7 +6,10 @@ import (
 
 	"github.com/celo-org/celo-blockchain/common"
 	"github.com/celo-org/celo-blockchain/contracts"
+	"github.com/celo-org/celo-blockchain/contracts/abis"
 	"github.com/celo-org/celo-blockchain/contracts/testutil"
+	"github.com/celo-org/celo-blockchain/params"
+	. "github.com/onsi/gomega"
 )
 
 func TestGetElectedValidators(t *testing.T) {
@@ -32,3 +35,72 @@ func TestGetGroupEpochRewards(t *testing.T) {
 // func TestDistributeEpochRewards(t *testing.T) {
 
 // }
+
+type electionMock struct {
+	groups     []common.Address
+	totals     []*big.Int
+	votedFor   map[common.Address][]common.Address
+	votes      map[common.Address]map[common.Address]*big.Int
+	nonvotings map[common.Address]*big.Int
+}
+
+func (m *electionMock) GetTotalVotesForEligibleValidatorGroups() ([]common.Address, []*big.Int) {
+	return m.groups, m.totals
+}
+
+func (m *electionMock) GetGroupsVotedForByAccount(account common.Address) []common.Address {
+	return m.votedFor[account]
+}
+
+func (m *electionMock) GetTotalVotesForGroupByAccount(group, account common.Address) *big.Int {
+	return m.votes[account][group]
+}
+
+func (m *electionMock) GetAccountNonvotingLockedGold(account common.Address) *big.Int {
+	return m.nonvotings[account]
+}
+
+func TestGetSlashingVoteDecrements(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	var (
+		electionAddress   = common.HexToAddress("0x01")
+		lockedGoldAddress = common.HexToAddress("0x02")
+		group1            = common.HexToAddress("0x11")
+		group2            = common.HexToAddress("0x12")
+		group3            = common.HexToAddress("0x13")
+		voter             = common.HexToAddress("0x21")
+		nonvoter          = common.HexToAddress("0x22")
+	)
+	mock := &electionMock{
+		groups:   []common.Address{group1, group2, group3},
+		totals:   []*big.Int{big.NewInt(300), big.NewInt(200), big.NewInt(100)},
+		votedFor: map[common.Address][]common.Address{voter: {group1, group3}},
+		votes: map[common.Address]map[common.Address]*big.Int{
+			voter: {group1: big.NewInt(50), group3: big.NewInt(30)},
+		},
+		nonvotings: map[common.Address]*big.Int{voter: big.NewInt(10), nonvoter: big.NewInt(100)},
+	}
+	runner := testutil.NewMockEVMRunner()
+	registry := testutil.NewRegistryMock()
+	runner.RegisterContract(params.RegistrySmartContractAddress, registry)
+	election := testutil.NewContractMock(abis.Elections, mock)
+	registry.AddContract(params.ElectionRegistryId, electionAddress)
+	runner.RegisterContract(electionAddress, &election)
+	lockedGold := testutil.NewContractMock(abis.LockedGold, mock)
+	registry.AddContract(params.LockedGoldRegistryId, lockedGoldAddress)
+	runner.RegisterContract(lockedGoldAddress, &lockedGold)
+
+	decrements, err := GetSlashingVoteDecrements(runner, []common.Address{voter, nonvoter}, big.NewInt(60))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(decrements).To(HaveLen(2))
+
+	// 50 votes are revoked: all 30 for group3, then 20 for group1, leaving
+	// group1 (280) > group2 (200) > group3 (70)
+	g.Expect(decrements[0].Lessers).To(Equal([]common.Address{group2, common.ZeroAddress}))
+	g.Expect(decrements[0].Greaters).To(Equal([]common.Address{common.ZeroAddress, group2}))
+	g.Expect(decrements[0].Indices).To(Equal([]*big.Int{big.NewInt(0), big.NewInt(1)}))
+
+	// The nonvoting locked gold covers the penalty
+	g.Expect(decrements[1].Lessers).To(BeEmpty())
+}
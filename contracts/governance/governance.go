@@ -0,0 +1,110 @@
+package governance
+
+import (
+	"math/big"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/contracts"
+	"github.com/celo-org/celo-blockchain/contracts/abis"
+	"github.com/celo-org/celo-blockchain/core/vm"
+	"github.com/celo-org/celo-blockchain/params"
+)
+
+var (
+	getDequeueMethod       = contracts.NewRegisteredContractMethod(params.GovernanceRegistryId, abis.Governance, "getDequeue", params.MaxGasForGetDequeue)
+	getProposalMethod      = contracts.NewRegisteredContractMethod(params.GovernanceRegistryId, abis.Governance, "getProposal", params.MaxGasForReadProposal)
+	getProposalStageMethod = contracts.NewRegisteredContractMethod(params.GovernanceRegistryId, abis.Governance, "getProposalStage", params.MaxGasForReadProposal)
+	getProposalTxMethod    = contracts.NewRegisteredContractMethod(params.GovernanceRegistryId, abis.Governance, "getProposalTransaction", params.MaxGasForReadProposal)
+	isApprovedMethod       = contracts.NewRegisteredContractMethod(params.GovernanceRegistryId, abis.Governance, "isApproved", params.MaxGasForReadProposal)
+)
+
+// Stage is the stage of a governance proposal
+type Stage uint8
+
+// Stages of a proposal, as defined by Proposals.Stage
+const (
+	StageNone Stage = iota
+	StageQueued
+	StageApproval
+	StageReferendum
+	StageExecution
+	StageExpiration
+)
+
+func (s Stage) String() string {
+	switch s {
+	case StageNone:
+		return "none"
+	case StageQueued:
+		return "queued"
+	case StageApproval:
+		return "approval"
+	case StageReferendum:
+		return "referendum"
+	case StageExecution:
+		return "execution"
+	case StageExpiration:
+		return "expiration"
+	default:
+		return "unknown"
+	}
+}
+
+// Proposal is a governance proposal
+type Proposal struct {
+	Proposer         common.Address
+	Deposit          *big.Int
+	Timestamp        *big.Int
+	TransactionCount *big.Int
+	DescriptionURL   string
+}
+
+// GetDequeue returns the ids of the proposals dequeued for approval and voting,
+// where zero marks an empty slot
+func GetDequeue(vmRunner vm.EVMRunner) ([]*big.Int, error) {
+	var dequeue []*big.Int
+	err := getDequeueMethod.Query(vmRunner, &dequeue)
+	return dequeue, err
+}
+
+// GetProposal returns the proposal with the given id
+func GetProposal(vmRunner vm.EVMRunner, id *big.Int) (*Proposal, error) {
+	proposal := new(Proposal)
+	err := getProposalMethod.Query(vmRunner, &[]interface{}{&proposal.Proposer, &proposal.Deposit, &proposal.Timestamp, &proposal.TransactionCount, &proposal.DescriptionURL}, id)
+	if err != nil {
+		return nil, err
+	}
+	return proposal, nil
+}
+
+// ProposalTransaction is one of the transactions executed by a governance proposal
+type ProposalTransaction struct {
+	Value       *big.Int
+	Destination common.Address
+	Data        []byte
+}
+
+// GetProposalTransaction returns the transaction at the given index of the proposal
+// with the given id
+func GetProposalTransaction(vmRunner vm.EVMRunner, id *big.Int, index uint64) (*ProposalTransaction, error) {
+	tx := new(ProposalTransaction)
+	err := getProposalTxMethod.Query(vmRunner, &[]interface{}{&tx.Value, &tx.Destination, &tx.Data}, id, new(big.Int).SetUint64(index))
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// GetProposalStage returns the current stage of the proposal with the given id
+func GetProposalStage(vmRunner vm.EVMRunner, id *big.Int) (Stage, error) {
+	var stage uint8
+	err := getProposalStageMethod.Query(vmRunner, &stage, id)
+	return Stage(stage), err
+}
+
+// IsApproved returns whether the proposal with the given id was approved by the approver
+func IsApproved(vmRunner vm.EVMRunner, id *big.Int) (bool, error) {
+	var approved bool
+	err := isApprovedMethod.Query(vmRunner, &approved, id)
+	return approved, err
+}
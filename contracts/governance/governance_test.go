@@ -0,0 +1,90 @@
+package governance
+
+import (
+	"math/big"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/contracts"
+	"github.com/celo-org/celo-blockchain/contracts/abis"
+	"github.com/celo-org/celo-blockchain/contracts/testutil"
+	"github.com/celo-org/celo-blockchain/params"
+)
+
+var governanceAddress = common.HexToAddress("0x0d1")
+
+func TestGovernanceAbi(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	stage, ok := abis.Governance.Methods["getProposalStage"]
+	g.Expect(ok).To(BeTrue())
+	g.Expect(stage.Inputs).To(HaveLen(1))
+	g.Expect(stage.Outputs).To(HaveLen(1))
+
+	tx, ok := abis.Governance.Methods["getProposalTransaction"]
+	g.Expect(ok).To(BeTrue())
+	g.Expect(tx.Inputs).To(HaveLen(2))
+	g.Expect(tx.Outputs).To(HaveLen(3))
+}
+
+func TestGetProposalStage(t *testing.T) {
+	testutil.TestFailOnFailingRunner(t, GetProposalStage, big.NewInt(1))
+	testutil.TestFailsWhenContractNotDeployed(t, contracts.ErrSmartContractNotDeployed, GetProposalStage, big.NewInt(1))
+
+	t.Run("should return the proposal stage", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		runner := testutil.NewMockEVMRunner()
+		registry := testutil.NewRegistryMock()
+		runner.RegisterContract(params.RegistrySmartContractAddress, registry)
+		registry.AddContract(params.GovernanceRegistryId, governanceAddress)
+
+		contract := testutil.NewSingleMethodContract(params.GovernanceRegistryId, "getProposalStage",
+			func(id *big.Int) uint8 {
+				if id.Uint64() == 7 {
+					return uint8(StageExecution)
+				}
+				return uint8(StageNone)
+			},
+		)
+		runner.RegisterContract(governanceAddress, contract)
+
+		stage, err := GetProposalStage(runner, big.NewInt(7))
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(stage).To(Equal(StageExecution))
+
+		stage, err = GetProposalStage(runner, big.NewInt(8))
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(stage).To(Equal(StageNone))
+	})
+}
+
+func TestGetProposalTransaction(t *testing.T) {
+	testutil.TestFailOnFailingRunner(t, GetProposalTransaction, big.NewInt(1), uint64(0))
+	testutil.TestFailsWhenContractNotDeployed(t, contracts.ErrSmartContractNotDeployed, GetProposalTransaction, big.NewInt(1), uint64(0))
+
+	t.Run("should return the proposal transaction at the index", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+
+		runner := testutil.NewMockEVMRunner()
+		registry := testutil.NewRegistryMock()
+		runner.RegisterContract(params.RegistrySmartContractAddress, registry)
+		registry.AddContract(params.GovernanceRegistryId, governanceAddress)
+
+		destination := common.HexToAddress("0xde5")
+		contract := testutil.NewSingleMethodContract(params.GovernanceRegistryId, "getProposalTransaction",
+			func(id, index *big.Int) (*big.Int, common.Address, []byte) {
+				return new(big.Int).Add(id, index), destination, []byte{byte(index.Uint64())}
+			},
+		)
+		runner.RegisterContract(governanceAddress, contract)
+
+		tx, err := GetProposalTransaction(runner, big.NewInt(7), 2)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(tx.Value.Uint64()).To(Equal(uint64(9)))
+		g.Expect(tx.Destination).To(Equal(destination))
+		g.Expect(tx.Data).To(Equal([]byte{2}))
+	})
+}
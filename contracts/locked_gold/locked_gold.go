@@ -0,0 +1,20 @@
+package locked_gold
+
+import (
+	"math/big"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/contracts"
+	"github.com/celo-org/celo-blockchain/contracts/abis"
+	"github.com/celo-org/celo-blockchain/core/vm"
+	"github.com/celo-org/celo-blockchain/params"
+)
+
+var getAccountNonvotingLockedGoldMethod = contracts.NewRegisteredContractMethod(params.LockedGoldRegistryId, abis.LockedGold, "getAccountNonvotingLockedGold", params.MaxGasForGetLockedGold)
+
+// GetAccountNonvotingLockedGold returns the locked gold of the account that is not used for voting
+func GetAccountNonvotingLockedGold(vmRunner vm.EVMRunner, account common.Address) (*big.Int, error) {
+	var nonvoting *big.Int
+	err := getAccountNonvotingLockedGoldMethod.Query(vmRunner, &nonvoting, account)
+	return nonvoting, err
+}
@@ -10,11 +10,31 @@ import (
 
 var getAddressMethod = NewBoundMethod(params.RegistrySmartContractAddress, abis.Registry, "getAddressFor", params.MaxGasForGetAddressFor)
 
+// chainConfigRunner is implemented by the runners aware of the chain configuration,
+// which may pin core contract addresses at genesis.
+type chainConfigRunner interface {
+	ChainConfig() *params.ChainConfig
+}
+
 // TODO(kevjue) - Re-Enable caching of the retrieved registered address
 // See this commit for the removed code for caching:  https://github.com/celo-org/geth/commit/43a275273c480d307a3d2b3c55ca3b3ee31ec7dd.
 
-// GetRegisteredAddress returns the address on the registry for a given id
+// GetRegisteredAddress returns the address on the registry for a given id, or the
+// address pinned at genesis if the registry has none.
 func GetRegisteredAddress(vmRunner vm.EVMRunner, registryId common.Hash) (common.Address, error) {
+	address, err := getRegistryAddress(vmRunner, registryId)
+	if err == ErrRegistryContractNotDeployed || err == ErrSmartContractNotDeployed {
+		if runner, ok := vmRunner.(chainConfigRunner); ok && runner.ChainConfig() != nil {
+			if pinned, ok := runner.ChainConfig().PinnedCoreContract(registryId); ok {
+				return pinned, nil
+			}
+		}
+	}
+	return address, err
+}
+
+// getRegistryAddress queries the registry for the address of the given id.
+func getRegistryAddress(vmRunner vm.EVMRunner, registryId common.Hash) (common.Address, error) {
 
 	vmRunner.StopGasMetering()
 	defer vmRunner.StartGasMetering()
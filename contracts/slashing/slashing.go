@@ -0,0 +1,60 @@
+package slashing
+
+import (
+	"math/big"
+
+	"github.com/celo-org/celo-blockchain/contracts"
+	"github.com/celo-org/celo-blockchain/contracts/abis"
+	"github.com/celo-org/celo-blockchain/core/vm"
+	"github.com/celo-org/celo-blockchain/params"
+)
+
+var (
+	downtimeSlashingIncentivesMethod      = contracts.NewRegisteredContractMethod(params.DowntimeSlasherRegistryId, abis.DowntimeSlasher, "slashingIncentives", params.MaxGasForReadSlashingParameter)
+	doubleSigningSlashingIncentivesMethod = contracts.NewRegisteredContractMethod(params.DoubleSigningSlasherRegistryId, abis.DoubleSigningSlasher, "slashingIncentives", params.MaxGasForReadSlashingParameter)
+	slashableDowntimeMethod               = contracts.NewRegisteredContractMethod(params.DowntimeSlasherRegistryId, abis.DowntimeSlasher, "slashableDowntime", params.MaxGasForReadSlashingParameter)
+	isBitmapSetForIntervalMethod          = contracts.NewRegisteredContractMethod(params.DowntimeSlasherRegistryId, abis.DowntimeSlasher, "isBitmapSetForInterval", params.MaxGasForIsBitmapSetForInterval)
+)
+
+// Incentives are the amounts of locked gold taken from the slashed accounts and
+// given to the reporter of the evidence, for each of the validator and its group.
+type Incentives struct {
+	Penalty *big.Int
+	Reward  *big.Int
+}
+
+// GetDowntimeSlashingIncentives returns the incentives of the downtime slasher
+func GetDowntimeSlashingIncentives(vmRunner vm.EVMRunner) (*Incentives, error) {
+	return getSlashingIncentives(vmRunner, downtimeSlashingIncentivesMethod)
+}
+
+// GetDoubleSigningSlashingIncentives returns the incentives of the double signing slasher
+func GetDoubleSigningSlashingIncentives(vmRunner vm.EVMRunner) (*Incentives, error) {
+	return getSlashingIncentives(vmRunner, doubleSigningSlashingIncentivesMethod)
+}
+
+func getSlashingIncentives(vmRunner vm.EVMRunner, method *contracts.BoundMethod) (*Incentives, error) {
+	incentives := new(Incentives)
+	err := method.Query(vmRunner, &[]interface{}{&incentives.Penalty, &incentives.Reward})
+	if err != nil {
+		return nil, err
+	}
+	return incentives, nil
+}
+
+// GetSlashableDowntime returns the number of consecutive blocks a validator has to miss to be slashed
+func GetSlashableDowntime(vmRunner vm.EVMRunner) (uint64, error) {
+	var window *big.Int
+	if err := slashableDowntimeMethod.Query(vmRunner, &window); err != nil {
+		return 0, err
+	}
+	return window.Uint64(), nil
+}
+
+// IsBitmapSetForInterval returns whether the signature bitmap of the blocks of the
+// interval was stored by the downtime slasher, which is needed to slash for it
+func IsBitmapSetForInterval(vmRunner vm.EVMRunner, start, end uint64) (bool, error) {
+	var isSet bool
+	err := isBitmapSetForIntervalMethod.Query(vmRunner, &isSet, new(big.Int).SetUint64(start), new(big.Int).SetUint64(end))
+	return isSet, err
+}
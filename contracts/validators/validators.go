@@ -42,6 +42,7 @@ var (
 	getValidatorBlsPublicKeyFromSignerMethod = contracts.NewRegisteredContractMethod(params.ValidatorsRegistryId, abis.Validators, "getValidatorBlsPublicKeyFromSigner", params.MaxGasForGetValidator)
 	getMembershipInLastEpochFromSignerMethod = contracts.NewRegisteredContractMethod(params.ValidatorsRegistryId, abis.Validators, "getMembershipInLastEpochFromSigner", params.MaxGasForGetMembershipInLastEpoch)
 	getValidatorMethod                       = contracts.NewRegisteredContractMethod(params.ValidatorsRegistryId, abis.Validators, "getValidator", params.MaxGasForGetValidator)
+	getMembershipHistoryMethod               = contracts.NewRegisteredContractMethod(params.ValidatorsRegistryId, abis.Validators, "getMembershipHistory", params.MaxGasForGetMembershipHistory)
 	updateValidatorScoreFromSignerMethod     = contracts.NewRegisteredContractMethod(params.ValidatorsRegistryId, abis.Validators, "updateValidatorScoreFromSigner", params.MaxGasForUpdateValidatorScore)
 	distributeEpochPaymentsFromSignerMethod  = contracts.NewRegisteredContractMethod(params.ValidatorsRegistryId, abis.Validators, "distributeEpochPaymentsFromSigner", params.MaxGasForDistributeEpochPayment)
 )
@@ -120,3 +121,36 @@ func GetMembershipInLastEpoch(vmRunner vm.EVMRunner, validator common.Address) (
 	}
 	return group, nil
 }
+
+// MembershipHistory is the history of the group memberships of a validator, with
+// the epoch each membership started at. Tail is the index of the first entry.
+type MembershipHistory struct {
+	Epochs                        []*big.Int
+	Groups                        []common.Address
+	LastRemovedFromGroupTimestamp *big.Int
+	Tail                          *big.Int
+}
+
+// GetMembershipHistory returns the group membership history of the validator
+func GetMembershipHistory(vmRunner vm.EVMRunner, validator common.Address) (*MembershipHistory, error) {
+	history := new(MembershipHistory)
+	err := getMembershipHistoryMethod.Query(vmRunner, &[]interface{}{&history.Epochs, &history.Groups, &history.LastRemovedFromGroupTimestamp, &history.Tail}, validator)
+	if err != nil {
+		return nil, err
+	}
+	if len(history.Epochs) != len(history.Groups) {
+		return nil, fmt.Errorf("got %d membership epochs for %d groups", len(history.Epochs), len(history.Groups))
+	}
+	return history, nil
+}
+
+// MembershipAt returns the group of the validator at the given epoch, along with
+// the index of the history entry, which the contracts take to look it up.
+func (h *MembershipHistory) MembershipAt(epoch uint64) (common.Address, *big.Int, error) {
+	for i := len(h.Epochs) - 1; i >= 0; i-- {
+		if h.Epochs[i].Uint64() <= epoch {
+			return h.Groups[i], new(big.Int).Add(h.Tail, big.NewInt(int64(i))), nil
+		}
+	}
+	return common.ZeroAddress, nil, fmt.Errorf("no group membership at epoch %d", epoch)
+}
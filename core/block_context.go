@@ -5,6 +5,7 @@ import (
 	"github.com/celo-org/celo-blockchain/contracts/blockchain_parameters"
 	"github.com/celo-org/celo-blockchain/contracts/currency"
 	"github.com/celo-org/celo-blockchain/core/vm"
+	"github.com/celo-org/celo-blockchain/params"
 )
 
 // BlockContext represents contextual information about the blockchain state
@@ -12,13 +13,14 @@ import (
 type BlockContext struct {
 	whitelistedCurrencies     map[common.Address]struct{}
 	gasForAlternativeCurrency uint64
+	intrinsicGasSchedule      *params.IntrinsicGasSchedule
 }
 
 // NewBlockContext creates a block context for a given block (represented by the
-// header & state).
+// header & state) and the intrinsic gas schedule active at the block.
 // state MUST be pointing to header's stateRoot
-func NewBlockContext(vmRunner vm.EVMRunner) BlockContext {
-	gasForAlternativeCurrency := blockchain_parameters.GetIntrinsicGasForAlternativeFeeCurrencyOrDefault(vmRunner)
+func NewBlockContext(vmRunner vm.EVMRunner, schedule *params.IntrinsicGasSchedule) BlockContext {
+	gasForAlternativeCurrency := blockchain_parameters.GetIntrinsicGasForAlternativeFeeCurrencyOrDefault(vmRunner, schedule)
 
 	whitelistedCurrenciesArr, err := currency.CurrencyWhitelist(vmRunner)
 	if err != nil {
@@ -33,6 +35,7 @@ func NewBlockContext(vmRunner vm.EVMRunner) BlockContext {
 	return BlockContext{
 		whitelistedCurrencies:     whitelistedCurrencies,
 		gasForAlternativeCurrency: gasForAlternativeCurrency,
+		intrinsicGasSchedule:      schedule,
 	}
 }
 
@@ -42,6 +45,12 @@ func (bc *BlockContext) GetIntrinsicGasForAlternativeFeeCurrency() uint64 {
 	return bc.gasForAlternativeCurrency
 }
 
+// GetIntrinsicGasSchedule retrieves the Celo specific intrinsic gas schedule
+// active at the block
+func (bc *BlockContext) GetIntrinsicGasSchedule() *params.IntrinsicGasSchedule {
+	return bc.intrinsicGasSchedule
+}
+
 // IsWhitelisted indicates if the currency is whitelisted as a fee currency
 func (bc *BlockContext) IsWhitelisted(feeCurrency *common.Address) bool {
 	if feeCurrency == nil {
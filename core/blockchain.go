@@ -18,6 +18,7 @@
 package core
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -78,6 +79,7 @@ var (
 
 	blockPrefetchExecuteTimer   = metrics.NewRegisteredTimer("chain/prefetch/executes", nil)
 	blockPrefetchInterruptMeter = metrics.NewRegisteredMeter("chain/prefetch/interrupts", nil)
+	blockPrefetchWarmTimer      = metrics.NewRegisteredTimer("chain/prefetch/warms", nil)
 
 	errInsertionInterrupted = errors.New("insertion is interrupted")
 	errCommitmentNotFound   = errors.New("randomness commitment not found")
@@ -127,6 +129,8 @@ type CacheConfig struct {
 	TrieDirtyDisabled   bool          // Whether to disable trie write caching and GC altogether (archive node)
 	TrieTimeLimit       time.Duration // Time limit after which to flush the current in-memory trie to disk
 	SnapshotLimit       int           // Memory allowance (MB) to use for caching snapshot entries in memory
+	StatePreheat        bool          // Whether to load the state of the hot core contracts into the caches on startup
+	HistoryBlocks       uint64        // Number of recent blocks whose bodies and receipts are retained (0 = all)
 
 	SnapshotWait bool // Wait for snapshot construction on startup. TODO(karalabe): This is a dirty hack for testing, nuke it
 }
@@ -171,6 +175,10 @@ type BlockChain struct {
 	//  * nil: disable tx reindexer/deleter, but still index new blocks
 	txLookupLimit uint64
 
+	// historyTail is the number of the oldest block whose body and receipts
+	// are retained, older ones being pruned except for the genesis.
+	historyTail uint64
+
 	hc            *HeaderChain
 	rmLogsFeed    event.Feed
 	chainFeed     event.Feed
@@ -194,16 +202,22 @@ type BlockChain struct {
 	txLookupCache *lru.Cache     // Cache for the most recent transaction lookup data.
 	futureBlocks  *lru.Cache     // future blocks are blocks added for later processing
 
-	quit          chan struct{}  // blockchain quit channel
-	wg            sync.WaitGroup // chain processing wait group for shutting down
-	running       int32          // 0 if chain is running, 1 when stopped
-	procInterrupt int32          // interrupt signaler for block processing
+	quit          chan struct{}      // blockchain quit channel
+	wg            sync.WaitGroup     // chain processing wait group for shutting down
+	running       int32              // 0 if chain is running, 1 when stopped
+	procInterrupt int32              // interrupt signaler for block processing
+	procCtx       context.Context    // context of block processing, cancelled with procInterrupt
+	procCancel    context.CancelFunc // cancels the block processing context
 
 	engine     consensus.Engine
 	validator  Validator  // Block and state validator interface
 	prefetcher Prefetcher // Block state prefetcher interface
+	warmer     *statePrefetcher // Concurrent state warmer for blocks not pre-cached
 	processor  Processor  // Block transaction processor interface
 	vmConfig   vm.Config
+	forkDryRun *forkDryRun // Hard fork executed in shadow, if any
+
+	traceSampler *TraceSampler // Tracer of a fraction of the imported transactions, if any
 
 	badBlocks          *lru.Cache                     // Bad block cache
 	shouldPreserve     func(*types.Block) bool        // Function used to determine whether should preserve the given block.
@@ -244,8 +258,10 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 		vmConfig:       vmConfig,
 		badBlocks:      badBlocks,
 	}
+	bc.procCtx, bc.procCancel = context.WithCancel(context.Background())
 	bc.validator = NewBlockValidator(chainConfig, bc, engine)
 	bc.prefetcher = newStatePrefetcher(chainConfig, bc, engine)
+	bc.warmer = newStatePrefetcher(chainConfig, bc, engine)
 	bc.processor = NewStateProcessor(chainConfig, bc, engine)
 
 	var err error
@@ -377,6 +393,17 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 		bc.txLookupLimit = *txLookupLimit
 		go bc.maintainTxIndex(txIndexBlock)
 	}
+	// If history pruning is requested, start moving the history window
+	if tail := rawdb.ReadHistoryTail(bc.db); tail != nil {
+		bc.historyTail = *tail
+	}
+	if bc.cacheConfig.HistoryBlocks > 0 {
+		if bc.cacheConfig.HistoryBlocks < TriesInMemory {
+			log.Warn("Sanitizing invalid block history window", "provided", bc.cacheConfig.HistoryBlocks, "updated", TriesInMemory)
+			bc.cacheConfig.HistoryBlocks = TriesInMemory
+		}
+		go bc.maintainHistory()
+	}
 	// If periodic cache journal is required, spin it up.
 	if bc.cacheConfig.TrieCleanRejournal > 0 {
 		if bc.cacheConfig.TrieCleanRejournal < time.Minute {
@@ -390,6 +417,11 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 			triedb.SaveCachePeriodically(bc.cacheConfig.TrieCleanJournal, bc.cacheConfig.TrieCleanRejournal, bc.quit)
 		}()
 	}
+	// If cold start state preheating is requested, spin it up.
+	if bc.cacheConfig.StatePreheat {
+		bc.wg.Add(1)
+		go bc.preheatState()
+	}
 	return bc, nil
 }
 
@@ -829,7 +861,7 @@ func (bc *BlockChain) GetBody(hash common.Hash) *types.Body {
 		return body
 	}
 	number := bc.hc.GetBlockNumber(hash)
-	if number == nil {
+	if number == nil || bc.HistoryPruned(*number) {
 		return nil
 	}
 	body := rawdb.ReadBody(bc.db, hash, *number)
@@ -849,7 +881,7 @@ func (bc *BlockChain) GetBodyRLP(hash common.Hash) rlp.RawValue {
 		return cached.(rlp.RawValue)
 	}
 	number := bc.hc.GetBlockNumber(hash)
-	if number == nil {
+	if number == nil || bc.HistoryPruned(*number) {
 		return nil
 	}
 	body := rawdb.ReadBodyRLP(bc.db, hash, *number)
@@ -866,6 +898,9 @@ func (bc *BlockChain) HasBlock(hash common.Hash, number uint64) bool {
 	if bc.blockCache.Contains(hash) {
 		return true
 	}
+	if bc.HistoryPruned(number) {
+		return false
+	}
 	return rawdb.HasBody(bc.db, hash, number)
 }
 
@@ -904,6 +939,9 @@ func (bc *BlockChain) GetBlock(hash common.Hash, number uint64) *types.Block {
 	if block, ok := bc.blockCache.Get(hash); ok {
 		return block.(*types.Block)
 	}
+	if bc.HistoryPruned(number) {
+		return nil
+	}
 	block := rawdb.ReadBlock(bc.db, hash, number)
 	if block == nil {
 		return nil
@@ -938,7 +976,7 @@ func (bc *BlockChain) GetReceiptsByHash(hash common.Hash) types.Receipts {
 		return receipts.(types.Receipts)
 	}
 	number := rawdb.ReadHeaderNumber(bc.db, hash)
-	if number == nil {
+	if number == nil || bc.HistoryPruned(*number) {
 		return nil
 	}
 	receipts := rawdb.ReadReceipts(bc.db, hash, *number, bc.chainConfig)
@@ -1010,10 +1048,10 @@ func (bc *BlockChain) Stop() {
 
 		for _, offset := range []uint64{0, 1, TriesInMemory - 1} {
 			if number := bc.CurrentBlock().NumberU64(); number > offset {
-				recent := bc.GetBlockByNumber(number - offset)
+				recent := bc.GetHeaderByNumber(number - offset)
 
-				log.Info("Writing cached state to disk", "block", recent.Number(), "hash", recent.Hash(), "root", recent.Root())
-				if err := triedb.Commit(recent.Root(), true, nil); err != nil {
+				log.Info("Writing cached state to disk", "block", recent.Number, "hash", recent.Hash(), "root", recent.Root)
+				if err := triedb.Commit(recent.Root, true, nil); err != nil {
 					log.Error("Failed to commit recent state trie", "err", err)
 				}
 			}
@@ -1041,10 +1079,11 @@ func (bc *BlockChain) Stop() {
 }
 
 // StopInsert interrupts all insertion methods, causing them to return
-// errInsertionInterrupted as soon as possible. Insertion is permanently disabled after
-// calling this method.
+// errInsertionInterrupted as soon as possible, aborting the execution of the block
+// being processed. Insertion is permanently disabled after calling this method.
 func (bc *BlockChain) StopInsert() {
 	atomic.StoreInt32(&bc.procInterrupt, 1)
+	bc.procCancel()
 }
 
 // insertStopped returns true after StopInsert has been called.
@@ -1418,12 +1457,30 @@ func (bc *BlockChain) SetTxLookupLimit(limit uint64) {
 	bc.txLookupLimit = limit
 }
 
+// SetTraceSampler sets the sampler tracing a fraction of the transactions of the
+// imported blocks. It must be set before blocks are imported.
+func (bc *BlockChain) SetTraceSampler(sampler *TraceSampler) {
+	bc.traceSampler = sampler
+}
+
+// TraceSampler returns the sampler tracing a fraction of the transactions of the
+// imported blocks, or nil if sampling is disabled.
+func (bc *BlockChain) TraceSampler() *TraceSampler {
+	return bc.traceSampler
+}
+
 // TxLookupLimit retrieves the txlookup limit used by blockchain to prune
 // stale transaction indices.
 func (bc *BlockChain) TxLookupLimit() uint64 {
 	return bc.txLookupLimit
 }
 
+// ArchiveMode returns whether the state of every block is kept, rather than only
+// the one of the recent blocks.
+func (bc *BlockChain) ArchiveMode() bool {
+	return bc.cacheConfig.TrieDirtyDisabled
+}
+
 var lastWrite uint64
 
 // writeBlockWithoutState writes only the block and its metadata to the database,
@@ -1816,6 +1873,7 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool) (int, er
 		return it.index, err
 	}
 	// No validation errors for the first block (or chain prefix skipped)
+	var prefetched bool // Whether the next block was pre-cached as a followup
 	for ; block != nil && err == nil || err == ErrKnownBlock; block, err = it.next() {
 		// If the chain is terminating, stop processing blocks
 		if bc.insertStopped() {
@@ -1868,11 +1926,20 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool) (int, er
 		if err != nil {
 			return it.index, err
 		}
+		// Unless pre-cached as a followup, warm the state accessed by the block
+		// concurrently with its execution.
+		stopWarming := func() {}
+		if !prefetched {
+			stopWarming = bc.WarmState(block, parent.Root)
+		}
+		prefetched = false
+
 		// If we have a followup block, run that against the current state to pre-cache
 		// transactions and probabilistically some of the account/storage trie nodes.
 		var followupInterrupt uint32
 		if !bc.cacheConfig.TrieCleanNoPrefetch {
 			if followup, err := it.peek(); followup != nil && err == nil {
+				prefetched = true
 				throwaway, _ := state.New(parent.Root, bc.stateCache, bc.snaps)
 				go func(start time.Time, followup *types.Block, throwaway *state.StateDB, interrupt *uint32) {
 					bc.prefetcher.Prefetch(followup, throwaway, bc.vmConfig, &followupInterrupt)
@@ -1886,10 +1953,15 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool) (int, er
 		}
 		// Process block using the parent state as reference point
 		substart := time.Now()
-		receipts, logs, usedGas, err := bc.processor.Process(block, statedb, bc.vmConfig)
+		receipts, logs, usedGas, err := bc.processor.Process(bc.procCtx, block, statedb, bc.vmConfig)
+		stopWarming()
 		if err != nil {
-			bc.reportBlock(block, receipts, err)
 			atomic.StoreUint32(&followupInterrupt, 1)
+			if bc.procCtx.Err() != nil {
+				log.Debug("Premature abort during block processing", "number", block.Number(), "hash", block.Hash())
+				return it.index, errInsertionInterrupted
+			}
+			bc.reportBlock(block, receipts, err)
 			return it.index, err
 		}
 		// Update the metrics touched during block processing
@@ -1921,6 +1993,9 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool) (int, er
 
 		blockValidationTimer.Update(time.Since(substart) - (statedb.AccountHashes + statedb.StorageHashes - triehash))
 
+		// Execute the block in shadow if dry running a hard fork
+		bc.shadowExecute(block, parent, receipts, usedGas)
+
 		// Write the block to the chain and get the status.
 		substart = time.Now()
 		status, err := bc.insertPreprocessedBlock(block, receipts, logs, statedb, false)
@@ -2386,6 +2461,129 @@ func (bc *BlockChain) maintainTxIndex(ancients uint64) {
 	}
 }
 
+// WarmState starts loading the state accessed by the transactions of the block
+// into the caches, concurrently with its execution on top of the state with
+// the given root. The returned function interrupts warming once executed.
+func (bc *BlockChain) WarmState(block *types.Block, root common.Hash) func() {
+	if bc.cacheConfig.TrieCleanNoPrefetch || len(block.Transactions()) == 0 {
+		return func() {}
+	}
+	interrupt := new(uint32)
+	go func(start time.Time) {
+		bc.warmer.Warm(block, root, interrupt)
+
+		blockPrefetchWarmTimer.Update(time.Since(start))
+		if atomic.LoadUint32(interrupt) == 1 {
+			blockPrefetchInterruptMeter.Mark(1)
+		}
+	}(time.Now())
+	return func() { atomic.StoreUint32(interrupt, 1) }
+}
+
+// HistoryTail returns the number of the oldest block whose body and receipts
+// are retained.
+func (bc *BlockChain) HistoryTail() uint64 {
+	return atomic.LoadUint64(&bc.historyTail)
+}
+
+// HistoryPruned returns whether the body and receipts of the block with the
+// given number were pruned. The genesis block is never pruned.
+func (bc *BlockChain) HistoryPruned(number uint64) bool {
+	return number != 0 && number < bc.HistoryTail()
+}
+
+// maintainHistory is responsible for pruning the bodies and receipts of the
+// blocks falling out of the history window, configured by the number of
+// recent blocks retained. Headers are kept, so that the chain remains
+// verifiable and the pruned blocks can be fetched from archive nodes.
+//
+// Blocks already moved to the ancient store are not deleted from it, but are
+// served as pruned all the same, so that the history window is consistent.
+func (bc *BlockChain) maintainHistory() {
+	var (
+		done   chan struct{}                  // Non-nil if background pruning routine is active.
+		headCh = make(chan ChainHeadEvent, 1) // Buffered to avoid locking up the event feed
+	)
+	sub := bc.SubscribeChainHeadEvent(headCh)
+	if sub == nil {
+		return
+	}
+	defer sub.Unsubscribe()
+
+	prune := func(head uint64) {
+		defer func() { done <- struct{}{} }()
+		bc.pruneHistory(head)
+	}
+	done = make(chan struct{})
+	go prune(bc.CurrentBlock().NumberU64())
+
+	for {
+		select {
+		case head := <-headCh:
+			if done == nil {
+				done = make(chan struct{})
+				go prune(head.Block.NumberU64())
+			}
+		case <-done:
+			done = nil
+		case <-bc.quit:
+			return
+		}
+	}
+}
+
+// pruneHistory deletes the bodies and receipts of the canonical blocks older
+// than the history window ending at the given head, moving the history tail.
+func (bc *BlockChain) pruneHistory(head uint64) {
+	limit := bc.cacheConfig.HistoryBlocks
+	if head < limit {
+		return
+	}
+	var (
+		start  = time.Now()
+		from   = bc.HistoryTail()
+		target = head - limit + 1
+		batch  = bc.db.NewBatch()
+	)
+	if from == 0 {
+		from = 1 // Always keep the genesis block
+	}
+	if target <= from {
+		return
+	}
+	for number := from; number < target; number++ {
+		if hash := rawdb.ReadCanonicalHash(bc.db, number); hash != (common.Hash{}) {
+			rawdb.DeleteBody(batch, hash, number)
+			rawdb.DeleteReceipts(batch, hash, number)
+
+			bc.bodyCache.Remove(hash)
+			bc.bodyRLPCache.Remove(hash)
+			bc.receiptsCache.Remove(hash)
+			bc.blockCache.Remove(hash)
+		}
+		if batch.ValueSize() > ethdb.IdealBatchSize || number == target-1 {
+			// Move the tail first, so that readers never find pruned data missing
+			rawdb.WriteHistoryTail(batch, number+1)
+			atomic.StoreUint64(&bc.historyTail, number+1)
+			if err := batch.Write(); err != nil {
+				log.Crit("Failed to prune block history", "err", err)
+			}
+			batch.Reset()
+
+			select {
+			case <-bc.quit:
+				return
+			default:
+			}
+		}
+	}
+	logger := log.Debug
+	if target-from > 1 {
+		logger = log.Info
+	}
+	logger("Pruned block history", "from", from, "tail", target, "elapsed", common.PrettyDuration(time.Since(start)))
+}
+
 // BadBlocks returns a list of the last 'bad blocks' that the client has seen on the network
 func (bc *BlockChain) BadBlocks() []*types.Block {
 	blocks := make([]*types.Block, 0, bc.badBlocks.Len())
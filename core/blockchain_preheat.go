@@ -0,0 +1,97 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"time"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/contracts"
+	"github.com/celo-org/celo-blockchain/core/vm/vmcontext"
+	"github.com/celo-org/celo-blockchain/log"
+	"github.com/celo-org/celo-blockchain/params"
+)
+
+// preheatNodeLimit caps the number of storage trie nodes loaded per contract
+// while preheating, keeping huge contracts from monopolising the clean cache.
+const preheatNodeLimit = 100000
+
+// preheatRegistryIds are the core contracts touched by almost every block,
+// whose state is loaded into the caches on startup if preheating is enabled.
+var preheatRegistryIds = []common.Hash{
+	params.ElectionRegistryId,
+	params.ValidatorsRegistryId,
+	params.LockedGoldRegistryId,
+	params.StableTokenRegistryId,
+	params.GoldTokenRegistryId,
+	params.FeeCurrencyWhitelistRegistryId,
+	params.GasPriceMinimumRegistryId,
+	params.SortedOraclesRegistryId,
+	params.BlockchainParametersRegistryId,
+}
+
+// preheatState loads the code and the storage trie nodes of the hot core
+// contracts at the current head into the database caches, so the first blocks
+// processed after a restart don't have to hit the disk for every access.
+func (bc *BlockChain) preheatState() {
+	defer bc.wg.Done()
+
+	var (
+		start = time.Now()
+		head  = bc.CurrentBlock()
+	)
+	statedb, err := bc.StateAt(head.Root())
+	if err != nil {
+		log.Warn("Failed to open state for preheating", "number", head.NumberU64(), "err", err)
+		return
+	}
+	// Resolve the contracts through the registry, skipping unregistered ones
+	vmRunner := vmcontext.NewEVMRunner(bc, head.Header(), statedb)
+	addresses := []common.Address{params.RegistrySmartContractAddress}
+	for _, id := range preheatRegistryIds {
+		addr, err := contracts.GetRegisteredAddress(vmRunner, id)
+		if err != nil {
+			continue
+		}
+		addresses = append(addresses, addr)
+	}
+	var nodes, code int
+	for _, addr := range addresses {
+		code += len(statedb.GetCode(addr))
+
+		storage := statedb.StorageTrie(addr)
+		if storage == nil {
+			continue
+		}
+		it := storage.NodeIterator(nil)
+		for count := 0; count < preheatNodeLimit && it.Next(true); count++ {
+			nodes++
+			if count%1000 == 0 {
+				select {
+				case <-bc.quit:
+					return
+				default:
+				}
+			}
+		}
+		if err := it.Error(); err != nil {
+			log.Debug("Failed to preheat contract storage", "address", addr, "err", err)
+		}
+	}
+	log.Info("Preheated state caches", "number", head.NumberU64(), "contracts", len(addresses), "nodes", nodes,
+		"code", common.StorageSize(code), "elapsed", common.PrettyDuration(time.Since(start)))
+}
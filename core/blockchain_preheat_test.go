@@ -0,0 +1,140 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/celo-org/celo-blockchain/common"
+	mockEngine "github.com/celo-org/celo-blockchain/consensus/consensustest"
+	"github.com/celo-org/celo-blockchain/core/rawdb"
+	"github.com/celo-org/celo-blockchain/core/state"
+	"github.com/celo-org/celo-blockchain/core/vm"
+	"github.com/celo-org/celo-blockchain/crypto"
+	"github.com/celo-org/celo-blockchain/params"
+)
+
+// preheatTestGenesis returns a genesis whose registry has code and storage but
+// registers no contracts.
+func preheatTestGenesis() *Genesis {
+	return &Genesis{
+		Config: params.IstanbulTestChainConfig,
+		Alloc: GenesisAlloc{
+			params.RegistrySmartContractAddress: {
+				Balance: common.Big0,
+				Code:    []byte{0x00},
+				Storage: map[common.Hash]common.Hash{{0x01}: {0x01}, {0x02}: {0x02}},
+			},
+		},
+	}
+}
+
+// Tests that enabling state preheating on a chain without registered core
+// contracts neither fails chain creation nor blocks shutdown.
+func TestStatePreheat(t *testing.T) {
+	var (
+		db    = rawdb.NewMemoryDatabase()
+		gspec = preheatTestGenesis()
+	)
+	gspec.MustCommit(db)
+
+	cacheConfig := *defaultCacheConfig
+	cacheConfig.StatePreheat = true
+
+	chain, err := NewBlockChain(db, &cacheConfig, gspec.Config, mockEngine.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		chain.Stop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("chain shutdown blocked by preheating")
+	}
+}
+
+// Tests that preheating loads the storage trie nodes and the code of the
+// registry into the caches, and that nothing is loaded without it.
+func TestStatePreheatCaches(t *testing.T) {
+	gspec := preheatTestGenesis()
+
+	// Collect the storage trie nodes of the registry from a separate database
+	var (
+		nodes    []common.Hash
+		codeHash = crypto.Keccak256Hash(gspec.Alloc[params.RegistrySmartContractAddress].Code)
+	)
+	gendb := rawdb.NewMemoryDatabase()
+	statedb, err := state.New(gspec.MustCommit(gendb).Root(), state.NewDatabase(gendb), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for it := statedb.StorageTrie(params.RegistrySmartContractAddress).NodeIterator(nil); it.Next(true); {
+		if it.Hash() != (common.Hash{}) {
+			nodes = append(nodes, it.Hash())
+		}
+	}
+	if len(nodes) == 0 {
+		t.Fatalf("registry storage trie has no stored nodes")
+	}
+	// newChain creates a chain without preheating or snapshot generation, which
+	// would load the registry state by itself
+	newChain := func() (*BlockChain, *readCountingDatabase) {
+		db := &readCountingDatabase{Database: rawdb.NewMemoryDatabase()}
+		gspec.MustCommit(db)
+
+		cacheConfig := *defaultCacheConfig
+		cacheConfig.SnapshotLimit = 0
+
+		chain, err := NewBlockChain(db, &cacheConfig, gspec.Config, mockEngine.NewFaker(), vm.Config{}, nil, nil)
+		if err != nil {
+			t.Fatalf("failed to create chain: %v", err)
+		}
+		return chain, db
+	}
+	// cached returns whether the registry state is served without database reads
+	cached := func(chain *BlockChain, db *readCountingDatabase) bool {
+		reads := atomic.LoadInt64(&db.reads)
+		for _, hash := range nodes {
+			if _, err := chain.stateCache.TrieDB().Node(hash); err != nil {
+				t.Fatalf("failed to read node %x: %v", hash, err)
+			}
+		}
+		if _, err := chain.stateCache.ContractCode(common.Hash{}, codeHash); err != nil {
+			t.Fatalf("failed to read code: %v", err)
+		}
+		return atomic.LoadInt64(&db.reads) == reads
+	}
+	chain, db := newChain()
+	defer chain.Stop()
+	if cached(chain, db) {
+		t.Errorf("registry state cached without preheating")
+	}
+
+	chain, db = newChain()
+	defer chain.Stop()
+	chain.wg.Add(1)
+	chain.preheatState()
+	if !cached(chain, db) {
+		t.Errorf("registry state not cached after preheating")
+	}
+}
@@ -17,6 +17,7 @@
 package core
 
 import (
+	"context"
 	"io/ioutil"
 	"math/big"
 	"math/rand"
@@ -146,7 +147,7 @@ func testBlockChainImport(chain types.Blocks, blockchain *BlockChain) error {
 		if err != nil {
 			return err
 		}
-		receipts, _, usedGas, err := blockchain.processor.Process(block, statedb, vm.Config{})
+		receipts, _, usedGas, err := blockchain.processor.Process(context.Background(), block, statedb, vm.Config{})
 		if err != nil {
 			blockchain.reportBlock(block, receipts, err)
 			return err
@@ -198,6 +199,30 @@ func TestLastBlock(t *testing.T) {
 	}
 }
 
+// Tests that block processing aborts with the error of its context once the
+// context is done, leaving the block importable.
+func TestProcessCancelled(t *testing.T) {
+	_, blockchain, err := newCanonical(mockEngine.NewFaker(), 0, true)
+	if err != nil {
+		t.Fatalf("failed to create pristine chain: %v", err)
+	}
+	defer blockchain.Stop()
+
+	blocks := makeBlockChain(blockchain.CurrentBlock(), 1, mockEngine.NewFullFaker(), blockchain.db, 0)
+	statedb, err := state.New(blockchain.CurrentBlock().Root(), blockchain.stateCache, nil)
+	if err != nil {
+		t.Fatalf("failed to open state: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, _, err := blockchain.processor.Process(ctx, blocks[0], statedb, vm.Config{}); err != context.Canceled {
+		t.Fatalf("processing error mismatch: have %v, want %v", err, context.Canceled)
+	}
+	if _, err := blockchain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert block: %v", err)
+	}
+}
+
 // Tests that given a starting canonical chain of a given size, it can be extended
 // with various length chains.
 func TestExtendCanonicalHeaders(t *testing.T) { testExtendCanonical(t, false) }
@@ -2903,3 +2928,61 @@ func TestInitThenFailCreateContract(t *testing.T) {
 		}
 	}
 }
+
+// Tests that pruning the block history deletes the bodies and receipts of the
+// blocks falling out of the window, keeping their headers and the genesis.
+func TestHistoryPruning(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		gspec   = &Genesis{Config: params.TestChainConfig}
+		genesis = gspec.MustCommit(db)
+	)
+	blocks, _ := GenerateChain(gspec.Config, genesis, mockEngine.NewFaker(), db, 200, nil)
+
+	chain, err := NewBlockChain(db, nil, gspec.Config, mockEngine.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	chain.cacheConfig.HistoryBlocks = TriesInMemory
+	chain.pruneHistory(chain.CurrentBlock().NumberU64())
+
+	tail := uint64(200 - TriesInMemory + 1)
+	if have := chain.HistoryTail(); have != tail {
+		t.Fatalf("history tail mismatch: have %d, want %d", have, tail)
+	}
+	if stored := rawdb.ReadHistoryTail(db); stored == nil || *stored != tail {
+		t.Fatalf("stored history tail mismatch: have %v, want %d", stored, tail)
+	}
+	if chain.GetBlockByNumber(0) == nil {
+		t.Error("genesis block pruned")
+	}
+	for _, block := range blocks {
+		number, hash := block.NumberU64(), block.Hash()
+		if chain.GetHeaderByNumber(number) == nil {
+			t.Fatalf("header #%d pruned", number)
+		}
+		pruned := number < tail
+		if have := chain.HistoryPruned(number); have != pruned {
+			t.Fatalf("block #%d pruned mismatch: have %v, want %v", number, have, pruned)
+		}
+		if have := rawdb.HasBody(db, hash, number); have == pruned {
+			t.Errorf("block #%d body stored mismatch: have %v, want %v", number, have, !pruned)
+		}
+		if have := chain.GetBlockByNumber(number) != nil; have == pruned {
+			t.Errorf("block #%d served mismatch: have %v, want %v", number, have, !pruned)
+		}
+		if have := chain.GetReceiptsByHash(hash) != nil; have == pruned {
+			t.Errorf("block #%d receipts served mismatch: have %v, want %v", number, have, !pruned)
+		}
+	}
+	// Moving the head only prunes the blocks leaving the window
+	chain.pruneHistory(chain.CurrentBlock().NumberU64() + 1)
+	if have := chain.HistoryTail(); have != tail+1 {
+		t.Fatalf("history tail mismatch after new head: have %d, want %d", have, tail+1)
+	}
+}
@@ -88,4 +88,8 @@ var (
 	// ErrUnprotectedTransaction is returned if replay protection is required (post-Donut) but the transaction doesn't
 	// use it.
 	ErrUnprotectedTransaction = errors.New("replay protection is required")
+
+	// ErrSponsoredTransactionsNotSupported is returned if the transaction has a separate fee payer
+	// but support for this kind of transaction is not enabled (pre-E hard fork).
+	ErrSponsoredTransactionsNotSupported = errors.New("support for sponsored transactions is not enabled")
 )
@@ -24,9 +24,17 @@ import (
 // NewTxsEvent is posted when a batch of transactions enter the transaction pool.
 type NewTxsEvent struct{ Txs []*types.Transaction }
 
+// TxPoolChangeEvent is posted when transactions are added to, replaced in or
+// removed from the transaction pool.
+type TxPoolChangeEvent struct{ Changes []TxPoolChange }
+
 // NewMinedBlockEvent is posted when a block has been imported.
 type NewMinedBlockEvent struct{ Block *types.Block }
 
+// NewCommittedBlockEvent is posted when a block has reached commit quorum, before
+// it is imported.
+type NewCommittedBlockEvent struct{ Block *types.Block }
+
 // RemovedLogsEvent is posted when a reorg happens
 type RemovedLogsEvent struct{ Logs []*types.Log }
 
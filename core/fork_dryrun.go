@@ -0,0 +1,143 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/core/state"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/log"
+	"github.com/celo-org/celo-blockchain/metrics"
+)
+
+var (
+	forkDryRunCheckedMeter  = metrics.NewRegisteredMeter("chain/forkdryrun/checked", nil)
+	forkDryRunDivergedMeter = metrics.NewRegisteredMeter("chain/forkdryrun/diverged", nil)
+)
+
+// forkDryRun executes the blocks leading to the activation of a hard fork a
+// second time, in shadow, with the fork already active, and reports where the
+// post-fork rules diverge from the pre-fork ones.
+type forkDryRun struct {
+	fork       string
+	start, end uint64 // Range of blocks executed in shadow, end excluded
+
+	processor Processor // Processor applying the post-fork rules
+	checked   uint64    // Number of blocks executed in shadow
+	diverged  uint64    // Number of blocks diverging after the fork
+}
+
+// SetForkDryRun executes in shadow with the rules of the given hard fork the
+// given number of blocks preceding its activation, or following the current
+// head if its activation block isn't scheduled.
+func (bc *BlockChain) SetForkDryRun(fork string, blocks uint64) error {
+	if blocks == 0 {
+		return fmt.Errorf("no blocks to dry run hard fork %s on", fork)
+	}
+	activation, known := bc.chainConfig.CeloHardforkBlock(fork)
+	if !known {
+		return fmt.Errorf("unknown hard fork %s", fork)
+	}
+	head := bc.CurrentBlock().NumberU64()
+	start, end := head+1, head+1+blocks
+	if activation != nil {
+		if activation.Uint64() <= start {
+			return fmt.Errorf("hard fork %s already active at block %d", fork, activation)
+		}
+		end = activation.Uint64()
+		if end-start > blocks {
+			start = end - blocks
+		}
+	}
+	config, _ := bc.chainConfig.WithCeloHardfork(fork, new(big.Int).SetUint64(start))
+
+	bc.chainmu.Lock()
+	defer bc.chainmu.Unlock()
+
+	bc.forkDryRun = &forkDryRun{
+		fork:      fork,
+		start:     start,
+		end:       end,
+		processor: NewStateProcessor(config, bc, bc.engine),
+	}
+	log.Info("Dry running hard fork", "fork", fork, "from", start, "to", end-1)
+	return nil
+}
+
+// shadowExecute executes the block with the post-fork rules on top of its
+// parent state, and reports any divergence from its actual execution.
+func (bc *BlockChain) shadowExecute(block *types.Block, parent *types.Header, receipts types.Receipts, usedGas uint64) {
+	dr := bc.forkDryRun
+	if dr == nil || block.NumberU64() < dr.start || block.NumberU64() >= dr.end {
+		return
+	}
+	logger := log.New("fork", dr.fork, "number", block.Number(), "hash", block.Hash())
+
+	var divergence string
+	statedb, err := state.New(parent.Root, bc.stateCache, bc.snaps)
+	if err != nil {
+		logger.Warn("Failed to open state for the fork dry run", "err", err)
+		return
+	}
+	shadowReceipts, _, shadowGas, err := dr.processor.Process(bc.procCtx, block, statedb, bc.vmConfig)
+	if err != nil {
+		divergence = fmt.Sprintf("block rejected: %v", err)
+	} else {
+		root := statedb.IntermediateRoot(bc.chainConfig.IsEIP158(block.Number()))
+		divergence = executionDivergence(block.Root(), receipts, usedGas, root, shadowReceipts, shadowGas)
+	}
+	dr.checked++
+	forkDryRunCheckedMeter.Mark(1)
+	if divergence != "" {
+		dr.diverged++
+		forkDryRunDivergedMeter.Mark(1)
+		logger.Warn("Hard fork dry run diverged", "divergence", divergence)
+	}
+	if block.NumberU64() == dr.end-1 {
+		log.Info("Hard fork dry run completed", "fork", dr.fork, "checked", dr.checked, "diverged", dr.diverged)
+	}
+}
+
+// executionDivergence describes the first difference between the results of two
+// executions of a block, or returns an empty string if they match.
+func executionDivergence(root common.Hash, receipts types.Receipts, usedGas uint64, shadowRoot common.Hash, shadowReceipts types.Receipts, shadowGas uint64) string {
+	if usedGas != shadowGas {
+		return fmt.Sprintf("gas used %d, %d after the fork", usedGas, shadowGas)
+	}
+	if len(receipts) != len(shadowReceipts) {
+		return fmt.Sprintf("%d receipts, %d after the fork", len(receipts), len(shadowReceipts))
+	}
+	for i, receipt := range receipts {
+		shadow := shadowReceipts[i]
+		if receipt.Status != shadow.Status {
+			return fmt.Sprintf("receipt %d status %d, %d after the fork", i, receipt.Status, shadow.Status)
+		}
+		if receipt.GasUsed != shadow.GasUsed {
+			return fmt.Sprintf("receipt %d gas used %d, %d after the fork", i, receipt.GasUsed, shadow.GasUsed)
+		}
+		if len(receipt.Logs) != len(shadow.Logs) {
+			return fmt.Sprintf("receipt %d has %d logs, %d after the fork", i, len(receipt.Logs), len(shadow.Logs))
+		}
+	}
+	if root != shadowRoot {
+		return fmt.Sprintf("state root %x, %x after the fork", root, shadowRoot)
+	}
+	return ""
+}
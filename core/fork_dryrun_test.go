@@ -0,0 +1,78 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"testing"
+
+	"github.com/celo-org/celo-blockchain/common"
+	mockEngine "github.com/celo-org/celo-blockchain/consensus/consensustest"
+	"github.com/celo-org/celo-blockchain/core/types"
+)
+
+func TestForkDryRun(t *testing.T) {
+	_, blockchain, err := newCanonical(mockEngine.NewFaker(), 0, true)
+	if err != nil {
+		t.Fatalf("failed to create pristine chain: %v", err)
+	}
+	defer blockchain.Stop()
+
+	// Only known forks not active yet can be dry run
+	if err := blockchain.SetForkDryRun("f", 5); err == nil {
+		t.Errorf("unknown hard fork dry run")
+	}
+	if err := blockchain.SetForkDryRun("churrito", 5); err == nil {
+		t.Errorf("active hard fork dry run")
+	}
+	if err := blockchain.SetForkDryRun("donut", 0); err == nil {
+		t.Errorf("hard fork dry run on no blocks")
+	}
+	// Without an activation block, the blocks following the head are executed in shadow
+	if err := blockchain.SetForkDryRun("donut", 5); err != nil {
+		t.Fatalf("failed to dry run hard fork: %v", err)
+	}
+	if dr := blockchain.forkDryRun; dr.start != 1 || dr.end != 6 {
+		t.Fatalf("dry run range mismatch: have [%d, %d), want [1, 6)", dr.start, dr.end)
+	}
+	blocks := makeBlockChain(blockchain.CurrentBlock(), 10, mockEngine.NewFaker(), blockchain.db, canonicalSeed)
+	if _, err := blockchain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	if dr := blockchain.forkDryRun; dr.checked != 5 || dr.diverged != 0 {
+		t.Errorf("dry run results mismatch: have %d checked and %d diverged, want 5 and 0", dr.checked, dr.diverged)
+	}
+}
+
+func TestExecutionDivergence(t *testing.T) {
+	var (
+		root     = common.HexToHash("0x01")
+		receipts = types.Receipts{{Status: types.ReceiptStatusSuccessful, GasUsed: 21000}}
+	)
+	if divergence := executionDivergence(root, receipts, 21000, root, receipts, 21000); divergence != "" {
+		t.Errorf("identical executions diverged: %s", divergence)
+	}
+	failed := types.Receipts{{Status: types.ReceiptStatusFailed, GasUsed: 21000}}
+	if divergence := executionDivergence(root, receipts, 21000, root, failed, 21000); divergence == "" {
+		t.Errorf("receipt status divergence not reported")
+	}
+	if divergence := executionDivergence(root, receipts, 21000, root, receipts, 22000); divergence == "" {
+		t.Errorf("gas used divergence not reported")
+	}
+	if divergence := executionDivergence(root, receipts, 21000, common.HexToHash("0x02"), receipts, 21000); divergence == "" {
+		t.Errorf("state root divergence not reported")
+	}
+}
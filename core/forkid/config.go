@@ -0,0 +1,136 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package forkid
+
+import (
+	"fmt"
+	"hash/crc32"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/celo-org/celo-blockchain/params"
+)
+
+// ForkBlock is the activation block of a scheduled fork.
+type ForkBlock struct {
+	Name  string // Lower cased name of the fork, e.g. "donut"
+	Block uint64
+}
+
+// Config summarizes the fork configuration of a chain, including the forks not
+// activated yet, which the fork ID does not cover past the next one. Peers
+// exchange it to spot nodes scheduling the forks differently.
+type Config struct {
+	Checksum [4]byte     // CRC32 checksum of the fork names and blocks
+	Forks    []ForkBlock // Scheduled forks, sorted by name
+}
+
+// Mismatch is a fork scheduled differently by two configurations, where a nil
+// block means the fork is not scheduled.
+type Mismatch struct {
+	Name   string
+	Local  *uint64
+	Remote *uint64
+}
+
+// NewConfig summarizes the fork configuration of the given chain config.
+func NewConfig(config *params.ChainConfig) Config {
+	forks := gatherNamedForks(config)
+
+	hash := uint32(0)
+	for _, fork := range forks {
+		hash = crc32.Update(hash, crc32.IEEETable, []byte(fork.Name))
+		hash = checksumUpdate(hash, fork.Block)
+	}
+	return Config{Checksum: checksumToBytes(hash), Forks: forks}
+}
+
+// Compare returns the forks scheduled differently by the local and the remote
+// configurations, sorted by name. A fork unknown to either side only counts as
+// a mismatch if the other side schedules it.
+func (c *Config) Compare(remote *Config) []Mismatch {
+	var (
+		local       = forkBlocks(c.Forks)
+		remoteForks = forkBlocks(remote.Forks)
+		mismatches  []Mismatch
+	)
+	for name, block := range local {
+		if other, ok := remoteForks[name]; !ok || other != block {
+			mismatches = append(mismatches, newMismatch(name, local, remoteForks))
+		}
+	}
+	for name := range remoteForks {
+		if _, ok := local[name]; !ok {
+			mismatches = append(mismatches, newMismatch(name, local, remoteForks))
+		}
+	}
+	sort.Slice(mismatches, func(i, j int) bool { return mismatches[i].Name < mismatches[j].Name })
+	return mismatches
+}
+
+func forkBlocks(forks []ForkBlock) map[string]uint64 {
+	blocks := make(map[string]uint64, len(forks))
+	for _, fork := range forks {
+		blocks[fork.Name] = fork.Block
+	}
+	return blocks
+}
+
+func newMismatch(name string, local, remote map[string]uint64) Mismatch {
+	mismatch := Mismatch{Name: name}
+	if block, ok := local[name]; ok {
+		mismatch.Local = &block
+	}
+	if block, ok := remote[name]; ok {
+		mismatch.Remote = &block
+	}
+	return mismatch
+}
+
+// gatherNamedForks returns the scheduled forks of the chain config, including
+// the ones at genesis, named after their config field without the "Block" suffix.
+// The intrinsic gas schedules are named after their index, e.g. "intrinsicgas0".
+func gatherNamedForks(config *params.ChainConfig) []ForkBlock {
+	kind := reflect.TypeOf(params.ChainConfig{})
+	conf := reflect.ValueOf(config).Elem()
+
+	var forks []ForkBlock
+	for i := 0; i < kind.NumField(); i++ {
+		field := kind.Field(i)
+		if !strings.HasSuffix(field.Name, "Block") || field.Type != reflect.TypeOf(new(big.Int)) {
+			continue
+		}
+		if rule := conf.Field(i).Interface().(*big.Int); rule != nil {
+			forks = append(forks, ForkBlock{
+				Name:  strings.ToLower(strings.TrimSuffix(field.Name, "Block")),
+				Block: rule.Uint64(),
+			})
+		}
+	}
+	for i, schedule := range config.IntrinsicGasSchedules {
+		if schedule.Block != nil {
+			forks = append(forks, ForkBlock{
+				Name:  fmt.Sprintf("intrinsicgas%d", i),
+				Block: schedule.Block.Uint64(),
+			})
+		}
+	}
+	sort.Slice(forks, func(i, j int) bool { return forks[i].Name < forks[j].Name })
+	return forks
+}
@@ -0,0 +1,88 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package forkid
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/celo-org/celo-blockchain/params"
+	"github.com/celo-org/celo-blockchain/rlp"
+)
+
+func TestNewConfig(t *testing.T) {
+	config := NewConfig(&params.ChainConfig{
+		HomesteadBlock: big.NewInt(0),
+		ChurritoBlock:  big.NewInt(10),
+		DonutBlock:     big.NewInt(20),
+	})
+	want := []ForkBlock{{"churrito", 10}, {"donut", 20}, {"homestead", 0}}
+	if !reflect.DeepEqual(config.Forks, want) {
+		t.Errorf("forks mismatch: have %v, want %v", config.Forks, want)
+	}
+	// Intrinsic gas schedules are named after their index
+	scheduled := NewConfig(&params.ChainConfig{
+		ChurritoBlock:         big.NewInt(10),
+		IntrinsicGasSchedules: []*params.IntrinsicGasSchedule{{Block: big.NewInt(15)}, {Block: big.NewInt(30)}},
+	})
+	if want := []ForkBlock{{"churrito", 10}, {"intrinsicgas0", 15}, {"intrinsicgas1", 30}}; !reflect.DeepEqual(scheduled.Forks, want) {
+		t.Errorf("scheduled forks mismatch: have %v, want %v", scheduled.Forks, want)
+	}
+	// The checksum covers the names and blocks of the forks
+	if other := NewConfig(&params.ChainConfig{HomesteadBlock: big.NewInt(0), ChurritoBlock: big.NewInt(10), DonutBlock: big.NewInt(20)}); other.Checksum != config.Checksum {
+		t.Errorf("checksum mismatch for identical configs: %x != %x", other.Checksum, config.Checksum)
+	}
+	for i, other := range []*params.ChainConfig{
+		{HomesteadBlock: big.NewInt(0), ChurritoBlock: big.NewInt(10), DonutBlock: big.NewInt(21)},
+		{HomesteadBlock: big.NewInt(0), ChurritoBlock: big.NewInt(20), DonutBlock: big.NewInt(10)},
+		{HomesteadBlock: big.NewInt(0), ChurritoBlock: big.NewInt(10)},
+	} {
+		if NewConfig(other).Checksum == config.Checksum {
+			t.Errorf("test %d: checksum collision with a different config", i)
+		}
+	}
+	// The config is sent over the wire in the handshake
+	blob, err := rlp.EncodeToBytes(config)
+	if err != nil {
+		t.Fatalf("failed to encode config: %v", err)
+	}
+	var decoded Config
+	if err := rlp.DecodeBytes(blob, &decoded); err != nil {
+		t.Fatalf("failed to decode config: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, config) {
+		t.Errorf("config mismatch after encoding: have %v, want %v", decoded, config)
+	}
+}
+
+func TestCompareConfig(t *testing.T) {
+	local := NewConfig(&params.ChainConfig{ChurritoBlock: big.NewInt(10), DonutBlock: big.NewInt(20)})
+	remote := NewConfig(&params.ChainConfig{ChurritoBlock: big.NewInt(10), DonutBlock: big.NewInt(30), EBlock: big.NewInt(40)})
+
+	if mismatches := local.Compare(&local); len(mismatches) != 0 {
+		t.Errorf("identical configs mismatch: %v", mismatches)
+	}
+	donutLocal, donutRemote, e := uint64(20), uint64(30), uint64(40)
+	want := []Mismatch{
+		{Name: "donut", Local: &donutLocal, Remote: &donutRemote},
+		{Name: "e", Remote: &e},
+	}
+	if mismatches := local.Compare(&remote); !reflect.DeepEqual(mismatches, want) {
+		t.Errorf("mismatches differ: have %v, want %v", mismatches, want)
+	}
+}
@@ -74,6 +74,12 @@ func NewID(chain Blockchain) ID {
 	)
 }
 
+// NewIDAt calculates the fork ID of the chain with the given config and genesis
+// at the given head, without needing the chain itself.
+func NewIDAt(config *params.ChainConfig, genesis common.Hash, head uint64) ID {
+	return newID(config, genesis, head)
+}
+
 // newID is the internal version of NewID, which takes extracted values as its
 // arguments instead of a chain. The reason is to allow testing the IDs without
 // having to simulate an entire blockchain.
@@ -213,7 +219,8 @@ func checksumToBytes(hash uint32) [4]byte {
 	return blob
 }
 
-// gatherForks gathers all the known forks and creates a sorted list out of them.
+// gatherForks gathers all the known forks, including the activation blocks of
+// the Celo intrinsic gas schedules, and creates a sorted list out of them.
 func gatherForks(config *params.ChainConfig) []uint64 {
 	// Gather all the fork block numbers via reflection
 	kind := reflect.TypeOf(params.ChainConfig{})
@@ -235,6 +242,12 @@ func gatherForks(config *params.ChainConfig) []uint64 {
 			forks = append(forks, rule.Uint64())
 		}
 	}
+	// Celo specific rules activated by block also fork the chain
+	for _, schedule := range config.IntrinsicGasSchedules {
+		if schedule.Block != nil {
+			forks = append(forks, schedule.Block.Uint64())
+		}
+	}
 	// Sort the fork block numbers to permit chronological XOR
 	for i := 0; i < len(forks); i++ {
 		for j := i + 1; j < len(forks); j++ {
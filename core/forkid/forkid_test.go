@@ -19,6 +19,8 @@ package forkid
 import (
 	"bytes"
 	"math"
+	"math/big"
+	"reflect"
 	"testing"
 
 	"github.com/celo-org/celo-blockchain/common"
@@ -37,7 +39,43 @@ func TestCreation(t *testing.T) {
 		config  *params.ChainConfig
 		genesis common.Hash
 		cases   []testcase
-	}{}
+	}{
+		// Mainnet test cases
+		{
+			params.MainnetChainConfig,
+			params.MainnetGenesisHash,
+			[]testcase{
+				{0, ID{Hash: checksumToBytes(0x61b9f5a6), Next: 6774000}},       // Unsynced
+				{6773999, ID{Hash: checksumToBytes(0x61b9f5a6), Next: 6774000}}, // Last Istanbul block
+				{6774000, ID{Hash: checksumToBytes(0x40fa05bd), Next: 0}},       // First Churrito and Donut block
+				{10000000, ID{Hash: checksumToBytes(0x40fa05bd), Next: 0}},      // Future Donut block
+			},
+		},
+		// Baklava test cases
+		{
+			params.BaklavaChainConfig,
+			params.BaklavaGenesisHash,
+			[]testcase{
+				{0, ID{Hash: checksumToBytes(0x2e958026), Next: 2719099}},       // Unsynced
+				{2719098, ID{Hash: checksumToBytes(0x2e958026), Next: 2719099}}, // Last Istanbul block
+				{2719099, ID{Hash: checksumToBytes(0x8bad9abf), Next: 5002000}}, // First Churrito block
+				{5001999, ID{Hash: checksumToBytes(0x8bad9abf), Next: 5002000}}, // Last Churrito block
+				{5002000, ID{Hash: checksumToBytes(0xb759dbf4), Next: 0}},       // First Donut block
+				{8000000, ID{Hash: checksumToBytes(0xb759dbf4), Next: 0}},       // Future Donut block
+			},
+		},
+		// Alfajores test cases
+		{
+			params.AlfajoresChainConfig,
+			params.AlfajoresGenesisHash,
+			[]testcase{
+				{0, ID{Hash: checksumToBytes(0xb168b530), Next: 4960000}},       // Unsynced
+				{4959999, ID{Hash: checksumToBytes(0xb168b530), Next: 4960000}}, // Last Istanbul block
+				{4960000, ID{Hash: checksumToBytes(0x0ef28287), Next: 0}},       // First Churrito and Donut block
+				{8000000, ID{Hash: checksumToBytes(0x0ef28287), Next: 0}},       // Future Donut block
+			},
+		},
+	}
 	for i, tt := range tests {
 		for j, ttt := range tt.cases {
 			if have := newID(tt.config, tt.genesis, ttt.head); have != ttt.want {
@@ -54,7 +92,43 @@ func TestValidation(t *testing.T) {
 		head uint64
 		id   ID
 		err  error
-	}{}
+	}{
+		// Local is mainnet Istanbul, remote announces the same. No future fork is announced.
+		{6000000, ID{Hash: checksumToBytes(0x61b9f5a6), Next: 0}, nil},
+
+		// Local is mainnet Istanbul, remote announces the same. Remote also announces
+		// Donut at the same block, simply accept.
+		{6000000, ID{Hash: checksumToBytes(0x61b9f5a6), Next: 6774000}, nil},
+
+		// Local is mainnet Istanbul, remote announces the same. Remote also announces
+		// a future fork we don't know about yet, accept.
+		{6000000, ID{Hash: checksumToBytes(0x61b9f5a6), Next: 6800000}, nil},
+
+		// Local is mainnet Donut, remote announces Istanbul and Donut at the same
+		// block. Remote is simply out of sync, accept.
+		{7000000, ID{Hash: checksumToBytes(0x61b9f5a6), Next: 6774000}, nil},
+
+		// Local is mainnet Istanbul, remote announces Donut. Local is out of sync, accept.
+		{6000000, ID{Hash: checksumToBytes(0x40fa05bd), Next: 0}, nil},
+
+		// Local is mainnet Donut, remote announces Istanbul without Donut. Remote
+		// needs software update.
+		{7000000, ID{Hash: checksumToBytes(0x61b9f5a6), Next: 0}, ErrRemoteStale},
+
+		// Local is mainnet Donut, remote announces Istanbul with Donut at a different
+		// block. Remote schedules the forks differently, reject.
+		{7000000, ID{Hash: checksumToBytes(0x61b9f5a6), Next: 6800000}, ErrRemoteStale},
+
+		// Local is mainnet Donut, remote announces Donut and a fork we have already
+		// passed without knowing about it. Local needs software update, reject.
+		{7000000, ID{Hash: checksumToBytes(0x40fa05bd), Next: 6900000}, ErrLocalIncompatibleOrStale},
+
+		// Local is mainnet, remote is Alfajores. Different genesis, reject.
+		{7000000, ID{Hash: checksumToBytes(0x0ef28287), Next: 0}, ErrLocalIncompatibleOrStale},
+
+		// Local is mainnet Istanbul, remote is Baklava Istanbul. Different genesis, reject.
+		{0, ID{Hash: checksumToBytes(0x2e958026), Next: 2719099}, ErrLocalIncompatibleOrStale},
+	}
 	for i, tt := range tests {
 		filter := newFilter(params.MainnetChainConfig, params.MainnetGenesisHash, func() uint64 { return tt.head })
 		if err := filter(tt.id); err != tt.err {
@@ -85,3 +159,32 @@ func TestEncoding(t *testing.T) {
 		}
 	}
 }
+
+// Tests that the activation blocks of the Celo intrinsic gas schedules fork the
+// chain like the named forks do.
+func TestIntrinsicGasScheduleForks(t *testing.T) {
+	config := *params.MainnetChainConfig
+	config.IntrinsicGasSchedules = []*params.IntrinsicGasSchedule{
+		{Block: big.NewInt(6774000), GatewayFee: 1000},
+		{Block: big.NewInt(8000000), GatewayFee: 2000},
+	}
+	if have, want := gatherForks(&config), []uint64{6774000, 8000000}; !reflect.DeepEqual(have, want) {
+		t.Fatalf("forks mismatch: have %v, want %v", have, want)
+	}
+	// Nodes not knowing about the schedule still agree on the past forks
+	if have, want := newID(&config, params.MainnetGenesisHash, 7000000), (ID{Hash: checksumToBytes(0x40fa05bd), Next: 8000000}); have != want {
+		t.Errorf("fork ID mismatch before the schedule: have %x, want %x", have, want)
+	}
+	after := newID(&config, params.MainnetGenesisHash, 8000000)
+	if after.Hash == checksumToBytes(0x40fa05bd) || after.Next != 0 {
+		t.Errorf("fork ID not updated by the schedule: %x", after)
+	}
+	// Past the schedule, nodes without it are rejected
+	filter := newFilter(&config, params.MainnetGenesisHash, func() uint64 { return 8000001 })
+	if err := filter(ID{Hash: checksumToBytes(0x40fa05bd), Next: 0}); err != ErrRemoteStale {
+		t.Errorf("unscheduled remote validation error mismatch: have %v, want %v", err, ErrRemoteStale)
+	}
+	if err := filter(after); err != nil {
+		t.Errorf("scheduled remote rejected: %v", err)
+	}
+}
@@ -376,6 +376,20 @@ func DefaultAlfajoresGenesisBlock() *Genesis {
 	}
 }
 
+// NetworkGenesisBlock returns the genesis block of the given pre-configured
+// network, or nil if it is not known.
+func NetworkGenesisBlock(network *params.Network) *Genesis {
+	switch network.GenesisHash {
+	case params.MainnetGenesisHash:
+		return MainnetGenesisBlock()
+	case params.BaklavaGenesisHash:
+		return DefaultBaklavaGenesisBlock()
+	case params.AlfajoresGenesisHash:
+		return DefaultAlfajoresGenesisBlock()
+	}
+	return nil
+}
+
 // DeveloperGenesisBlock returns the 'geth --dev' genesis block.
 func DeveloperGenesisBlock() *Genesis {
 	// Override the default period to the user requested one
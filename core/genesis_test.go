@@ -45,6 +45,22 @@ func TestMainnetGenesisBlock(t *testing.T) {
 	}
 }
 
+func TestNetworkGenesisBlock(t *testing.T) {
+	for _, network := range params.Networks {
+		genesis := NetworkGenesisBlock(network)
+		if genesis == nil {
+			t.Errorf("%s: no genesis block", network.Name)
+			continue
+		}
+		if hash := genesis.ToBlock(nil).Hash(); hash != network.GenesisHash {
+			t.Errorf("%s: wrong genesis hash, got %v, want %v", network.Name, hash.Hex(), network.GenesisHash.Hex())
+		}
+		if genesis.Config != network.ChainConfig {
+			t.Errorf("%s: genesis chain config mismatch", network.Name)
+		}
+	}
+}
+
 func TestSetupGenesis(t *testing.T) {
 	customghash := common.HexToHash("0xade49833713207ecf7d4807ca34b1246b014ef3992ec231deb1e0ee56289c1c8")
 	alloc := &GenesisAlloc{}
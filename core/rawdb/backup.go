@@ -0,0 +1,227 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/ethdb"
+	"github.com/celo-org/celo-blockchain/log"
+	"github.com/celo-org/celo-blockchain/rlp"
+)
+
+// backupVersion is the version number of the database backup format.
+const backupVersion = 1
+
+var (
+	// errBackupVersion is returned if a backup archive has an unsupported version.
+	errBackupVersion = errors.New("unsupported backup version")
+
+	// errRestoreNotEmpty is returned if a backup is restored into a database
+	// already containing chain data.
+	errRestoreNotEmpty = errors.New("database not empty")
+)
+
+// backupHeader is the first item of a database backup archive.
+type backupHeader struct {
+	Version  uint64
+	Ancients uint64 // Number of ancient blocks following the header
+}
+
+// backupAncient is the archived representation of a single ancient block.
+type backupAncient struct {
+	Hash     []byte
+	Header   []byte
+	Body     []byte
+	Receipts []byte
+	Td       []byte
+}
+
+// backupEntry is the archived representation of a single key-value entry.
+type backupEntry struct {
+	Key   []byte
+	Value []byte
+}
+
+// BackupStats contains the amount of data written or read by a backup.
+type BackupStats struct {
+	Ancients uint64             `json:"ancients"` // Number of ancient blocks
+	Entries  uint64             `json:"entries"`  // Number of key-value entries
+	Size     common.StorageSize `json:"size"`     // Total size of the key-value entries
+}
+
+// ExportBackup writes a consistent, gzip compressed backup of the database to
+// w. The key-value store is read from a snapshot, so the database may be used
+// concurrently, e.g. by a running node. Ancient blocks are captured after the
+// snapshot is taken, guaranteeing that every block moved out of the key-value
+// store by the freezer is included.
+func ExportBackup(w io.Writer, db ethdb.Database) (*BackupStats, error) {
+	snap, err := db.NewSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	defer snap.Release()
+
+	// Ancients are only ever appended after being written to the key-value store
+	// and deleted afterwards, so reading the count after the snapshot is safe.
+	frozen, err := db.Ancients()
+	if err != nil && err != errNotSupported {
+		return nil, err
+	}
+	var (
+		stats  = new(BackupStats)
+		start  = time.Now()
+		logged = time.Now()
+	)
+	gz := gzip.NewWriter(w)
+	if err := rlp.Encode(gz, &backupHeader{Version: backupVersion, Ancients: frozen}); err != nil {
+		return nil, err
+	}
+	for number := uint64(0); number < frozen; number++ {
+		var item backupAncient
+		for _, field := range []struct {
+			kind string
+			blob *[]byte
+		}{
+			{freezerHashTable, &item.Hash},
+			{freezerHeaderTable, &item.Header},
+			{freezerBodiesTable, &item.Body},
+			{freezerReceiptTable, &item.Receipts},
+			{freezerDifficultyTable, &item.Td},
+		} {
+			if *field.blob, err = db.Ancient(field.kind, number); err != nil {
+				return nil, fmt.Errorf("failed to read ancient %s #%d: %v", field.kind, number, err)
+			}
+		}
+		if err := rlp.Encode(gz, &item); err != nil {
+			return nil, err
+		}
+		stats.Ancients++
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Exporting ancient blocks", "exported", stats.Ancients, "total", frozen, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	it := snap.NewIterator(nil, nil)
+	defer it.Release()
+
+	for it.Next() {
+		if err := rlp.Encode(gz, &backupEntry{Key: it.Key(), Value: it.Value()}); err != nil {
+			return nil, err
+		}
+		stats.Entries++
+		stats.Size += common.StorageSize(len(it.Key()) + len(it.Value()))
+
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Exporting database entries", "entries", stats.Entries, "size", stats.Size, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	log.Info("Exported database backup", "ancients", stats.Ancients, "entries", stats.Entries, "size", stats.Size, "elapsed", common.PrettyDuration(time.Since(start)))
+	return stats, nil
+}
+
+// ImportBackup restores a backup written by ExportBackup into db, which must
+// not contain any chain data yet.
+func ImportBackup(r io.Reader, db ethdb.Database) (*BackupStats, error) {
+	if ReadHeadHeaderHash(db) != (common.Hash{}) {
+		return nil, errRestoreNotEmpty
+	}
+	if frozen, err := db.Ancients(); err == nil && frozen > 0 {
+		return nil, errRestoreNotEmpty
+	}
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	stream := rlp.NewStream(gz, 0)
+
+	var header backupHeader
+	if err := stream.Decode(&header); err != nil {
+		return nil, fmt.Errorf("failed to read backup header: %v", err)
+	}
+	if header.Version != backupVersion {
+		return nil, fmt.Errorf("%w: have %d, want %d", errBackupVersion, header.Version, backupVersion)
+	}
+	var (
+		stats  = new(BackupStats)
+		start  = time.Now()
+		logged = time.Now()
+	)
+	for number := uint64(0); number < header.Ancients; number++ {
+		var item backupAncient
+		if err := stream.Decode(&item); err != nil {
+			return nil, fmt.Errorf("failed to read ancient #%d: %v", number, err)
+		}
+		if err := db.AppendAncient(number, item.Hash, item.Header, item.Body, item.Receipts, item.Td); err != nil {
+			return nil, fmt.Errorf("failed to restore ancient #%d: %v", number, err)
+		}
+		stats.Ancients++
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Importing ancient blocks", "imported", stats.Ancients, "total", header.Ancients, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if header.Ancients > 0 {
+		if err := db.Sync(); err != nil {
+			return nil, err
+		}
+	}
+	batch := db.NewBatch()
+	for {
+		var entry backupEntry
+		if err := stream.Decode(&entry); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read database entry: %v", err)
+		}
+		if err := batch.Put(entry.Key, entry.Value); err != nil {
+			return nil, err
+		}
+		stats.Entries++
+		stats.Size += common.StorageSize(len(entry.Key) + len(entry.Value))
+
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return nil, err
+			}
+			batch.Reset()
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Importing database entries", "entries", stats.Entries, "size", stats.Size, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := batch.Write(); err != nil {
+		return nil, err
+	}
+	log.Info("Imported database backup", "ancients", stats.Ancients, "entries", stats.Entries, "size", stats.Size, "elapsed", common.PrettyDuration(time.Since(start)))
+	return stats, nil
+}
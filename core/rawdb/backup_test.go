@@ -0,0 +1,110 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/ethdb"
+	"github.com/celo-org/celo-blockchain/ethdb/memorydb"
+	"github.com/celo-org/celo-blockchain/rlp"
+)
+
+// newTestFreezerDB creates a memory backed database with a freezer in a
+// temporary directory.
+func newTestFreezerDB(t *testing.T, dir string) ethdb.Database {
+	db, err := NewDatabaseWithFreezer(memorydb.New(), filepath.Join(dir, "ancient"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestBackupRoundtrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	src := newTestFreezerDB(t, filepath.Join(dir, "src"))
+	defer src.Close()
+
+	// Freeze a few blocks and keep the head in the key-value store
+	var head *types.Header
+	for i := uint64(0); i < 4; i++ {
+		head = &types.Header{Number: new(big.Int).SetUint64(i), Extra: []byte{byte(i)}}
+		headerBlob, _ := rlp.EncodeToBytes(head)
+		bodyBlob, _ := rlp.EncodeToBytes(&types.Body{})
+		receiptsBlob, _ := rlp.EncodeToBytes([]*types.ReceiptForStorage{})
+		tdBlob, _ := rlp.EncodeToBytes(big.NewInt(int64(i + 1)))
+
+		if i < 3 {
+			if err := src.AppendAncient(i, head.Hash().Bytes(), headerBlob, bodyBlob, receiptsBlob, tdBlob); err != nil {
+				t.Fatal(err)
+			}
+		} else {
+			WriteHeader(src, head)
+		}
+	}
+	WriteHeadHeaderHash(src, head.Hash())
+
+	var archive bytes.Buffer
+	exported, err := ExportBackup(&archive, src)
+	if err != nil {
+		t.Fatalf("failed to export backup: %v", err)
+	}
+	if exported.Ancients != 3 {
+		t.Fatalf("exported ancients mismatch: have %d, want %d", exported.Ancients, 3)
+	}
+	// Mutations after the export must not affect the restored database
+	WriteHeadHeaderHash(src, types.EmptyRootHash)
+
+	dst := newTestFreezerDB(t, filepath.Join(dir, "dst"))
+	defer dst.Close()
+
+	imported, err := ImportBackup(bytes.NewReader(archive.Bytes()), dst)
+	if err != nil {
+		t.Fatalf("failed to import backup: %v", err)
+	}
+	if *imported != *exported {
+		t.Fatalf("import stats mismatch: have %+v, want %+v", imported, exported)
+	}
+	if have := ReadHeadHeaderHash(dst); have != head.Hash() {
+		t.Fatalf("head header mismatch: have %x, want %x", have, head.Hash())
+	}
+	for i := uint64(0); i < 3; i++ {
+		want, _ := src.Ancient(freezerHeaderTable, i)
+		have, err := dst.Ancient(freezerHeaderTable, i)
+		if err != nil || !bytes.Equal(have, want) {
+			t.Fatalf("ancient header #%d mismatch: have %x, want %x (%v)", i, have, want, err)
+		}
+	}
+	if ReadHeader(dst, head.Hash(), 3) == nil {
+		t.Fatalf("key-value header missing after restore")
+	}
+	// Restoring into a populated database must be rejected
+	if _, err := ImportBackup(bytes.NewReader(archive.Bytes()), dst); err != errRestoreNotEmpty {
+		t.Fatalf("restore into populated database: have %v, want %v", err, errRestoreNotEmpty)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"sync/atomic"
 	"time"
 
@@ -122,8 +123,16 @@ func NewDatabase(db ethdb.KeyValueStore) ethdb.Database {
 // value data store with a freezer moving immutable chain segments into cold
 // storage.
 func NewDatabaseWithFreezer(db ethdb.KeyValueStore, freezer string, namespace string) (ethdb.Database, error) {
+	return NewDatabaseWithRemoteFreezer(db, freezer, nil, 0, namespace)
+}
+
+// NewDatabaseWithRemoteFreezer creates a high level database on top of a given
+// key-value data store with a freezer moving immutable chain segments into cold
+// storage. Sealed freezer data files are offloaded to the given remote store, if
+// any, keeping only cacheFiles of them per table on local disk.
+func NewDatabaseWithRemoteFreezer(db ethdb.KeyValueStore, freezer string, remote AncientRemote, cacheFiles int, namespace string) (ethdb.Database, error) {
 	// Create the idle freezer instance
-	frdb, err := newFreezer(freezer, namespace)
+	frdb, err := newFreezer(freezer, namespace, remote, cacheFiles)
 	if err != nil {
 		return nil, err
 	}
@@ -238,6 +247,117 @@ func NewLevelDBDatabaseWithFreezer(file string, cache int, handles int, freezer
 	return frdb, nil
 }
 
+const (
+	// DBLeveldb is the identifier of the LevelDB key-value store backend.
+	DBLeveldb = "leveldb"
+
+	// DBPebble is the identifier of the Pebble key-value store backend.
+	DBPebble = "pebble"
+)
+
+// PreexistingDatabase checks the given data directory whether a database is
+// already instantiated at that location, and if so, returns the type of database
+// (or the empty string).
+func PreexistingDatabase(path string) string {
+	if _, err := os.Stat(filepath.Join(path, "CURRENT")); err != nil {
+		return "" // No pre-existing db
+	}
+	if matches, err := filepath.Glob(filepath.Join(path, "OPTIONS*")); len(matches) > 0 || err != nil {
+		if err != nil {
+			panic(err) // only possible if the pattern is malformed
+		}
+		return DBPebble
+	}
+	return DBLeveldb
+}
+
+// NewKeyValueStore opens a persistent key-value store backed by the requested
+// engine. If no engine is requested, the engine of any pre-existing database
+// at the given location is used, falling back to LevelDB for fresh databases.
+func NewKeyValueStore(engine string, file string, cache int, handles int, namespace string) (ethdb.KeyValueStore, error) {
+	existing := PreexistingDatabase(file)
+	if engine != "" && existing != "" && engine != existing {
+		return nil, fmt.Errorf("db.engine choice was %v but found pre-existing %v database in specified data directory", engine, existing)
+	}
+	if engine == "" {
+		engine = existing
+	}
+	switch engine {
+	case DBPebble:
+		log.Info("Using pebble as the backing database")
+		return newPebbleDB(file, cache, handles, namespace)
+	case DBLeveldb, "":
+		log.Info("Using leveldb as the backing database")
+		return leveldb.New(file, cache, handles, namespace)
+	default:
+		return nil, fmt.Errorf("unknown db.engine %q", engine)
+	}
+}
+
+// NewEngineDatabase creates a persistent key-value database backed by the given
+// engine, without a freezer moving immutable chain segments into cold storage.
+func NewEngineDatabase(engine string, file string, cache int, handles int, namespace string) (ethdb.Database, error) {
+	kvdb, err := NewKeyValueStore(engine, file, cache, handles, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return NewDatabase(kvdb), nil
+}
+
+// NewEngineDatabaseWithFreezer creates a persistent key-value database backed by
+// the given engine, with a freezer moving immutable chain segments into cold
+// storage.
+func NewEngineDatabaseWithFreezer(engine string, file string, cache int, handles int, freezer string, namespace string) (ethdb.Database, error) {
+	kvdb, err := NewKeyValueStore(engine, file, cache, handles, namespace)
+	if err != nil {
+		return nil, err
+	}
+	frdb, err := NewDatabaseWithFreezer(kvdb, freezer, namespace)
+	if err != nil {
+		kvdb.Close()
+		return nil, err
+	}
+	return frdb, nil
+}
+
+// NewEngineDatabaseWithRemoteFreezer creates a persistent key-value database
+// backed by the given engine, with a freezer moving immutable chain segments
+// into cold storage, offloading sealed segments to a remote store.
+func NewEngineDatabaseWithRemoteFreezer(engine string, file string, cache int, handles int, freezer string, remote AncientRemote, cacheFiles int, namespace string) (ethdb.Database, error) {
+	kvdb, err := NewKeyValueStore(engine, file, cache, handles, namespace)
+	if err != nil {
+		return nil, err
+	}
+	frdb, err := NewDatabaseWithRemoteFreezer(kvdb, freezer, remote, cacheFiles, namespace)
+	if err != nil {
+		kvdb.Close()
+		return nil, err
+	}
+	return frdb, nil
+}
+
+// stat stores the size and item count of a database category.
+type stat struct {
+	size  common.StorageSize
+	count uint64
+}
+
+// Add accounts an item of the given size to the category.
+func (s *stat) Add(size common.StorageSize) {
+	s.size += size
+	s.count++
+}
+
+// Size returns the total size of the category as a human readable string.
+func (s *stat) Size() string {
+	return s.size.String()
+}
+
+// Count returns the number of items in the category as a string.
+func (s *stat) Count() string {
+	return fmt.Sprintf("%d", s.count)
+}
+
 // InspectDatabase traverses the entire database and checks the size
 // of all different categories of data.
 func InspectDatabase(db ethdb.Database) error {
@@ -250,34 +370,33 @@ func InspectDatabase(db ethdb.Database) error {
 		logged = time.Now()
 
 		// Key-value store statistics
-		total           common.StorageSize
-		headerSize      common.StorageSize
-		bodySize        common.StorageSize
-		receiptSize     common.StorageSize
-		tdSize          common.StorageSize
-		numHashPairing  common.StorageSize
-		hashNumPairing  common.StorageSize
-		trieSize        common.StorageSize
-		txlookupSize    common.StorageSize
-		accountSnapSize common.StorageSize
-		storageSnapSize common.StorageSize
-		preimageSize    common.StorageSize
-		bloomBitsSize   common.StorageSize
-
-		// Ancient store statistics
-		ancientHeaders  common.StorageSize
-		ancientBodies   common.StorageSize
-		ancientReceipts common.StorageSize
-		ancientHashes   common.StorageSize
-		ancientTds      common.StorageSize
+		headers         stat
+		bodies          stat
+		receipts        stat
+		tds             stat
+		numHashPairings stat
+		hashNumPairings stat
+		tries           stat
+		txLookups       stat
+		accountSnaps    stat
+		storageSnaps    stat
+		preimages       stat
+		bloomBits       stat
+		istanbulSnaps   stat
+		uptimes         stat
 
 		// Les statistic
-		chtTrieNodes   common.StorageSize
-		bloomTrieNodes common.StorageSize
+		chtTrieNodes   stat
+		bloomTrieNodes stat
+		chtRoots       stat
+		bloomTrieRoots stat
 
 		// Meta- and unaccounted data
-		metadata    common.StorageSize
-		unaccounted common.StorageSize
+		metadata    stat
+		unaccounted stat
+
+		// Totals
+		total common.StorageSize
 	)
 	// Inspect key-value database first.
 	for it.Next() {
@@ -288,44 +407,57 @@ func InspectDatabase(db ethdb.Database) error {
 		total += size
 		switch {
 		case bytes.HasPrefix(key, headerPrefix) && bytes.HasSuffix(key, headerTDSuffix):
-			tdSize += size
+			tds.Add(size)
 		case bytes.HasPrefix(key, headerPrefix) && bytes.HasSuffix(key, headerHashSuffix):
-			numHashPairing += size
+			numHashPairings.Add(size)
 		case bytes.HasPrefix(key, headerPrefix) && len(key) == (len(headerPrefix)+8+common.HashLength):
-			headerSize += size
+			headers.Add(size)
 		case bytes.HasPrefix(key, headerNumberPrefix) && len(key) == (len(headerNumberPrefix)+common.HashLength):
-			hashNumPairing += size
+			hashNumPairings.Add(size)
 		case bytes.HasPrefix(key, blockBodyPrefix) && len(key) == (len(blockBodyPrefix)+8+common.HashLength):
-			bodySize += size
+			bodies.Add(size)
 		case bytes.HasPrefix(key, blockReceiptsPrefix) && len(key) == (len(blockReceiptsPrefix)+8+common.HashLength):
-			receiptSize += size
+			receipts.Add(size)
 		case bytes.HasPrefix(key, txLookupPrefix) && len(key) == (len(txLookupPrefix)+common.HashLength):
-			txlookupSize += size
+			txLookups.Add(size)
 		case bytes.HasPrefix(key, SnapshotAccountPrefix) && len(key) == (len(SnapshotAccountPrefix)+common.HashLength):
-			accountSnapSize += size
+			accountSnaps.Add(size)
 		case bytes.HasPrefix(key, SnapshotStoragePrefix) && len(key) == (len(SnapshotStoragePrefix)+2*common.HashLength):
-			storageSnapSize += size
+			storageSnaps.Add(size)
 		case bytes.HasPrefix(key, preimagePrefix) && len(key) == (len(preimagePrefix)+common.HashLength):
-			preimageSize += size
+			preimages.Add(size)
 		case bytes.HasPrefix(key, bloomBitsPrefix) && len(key) == (len(bloomBitsPrefix)+10+common.HashLength):
-			bloomBitsSize += size
+			bloomBits.Add(size)
+		case bytes.HasPrefix(key, istanbulSnapshotPrefix) && len(key) == (len(istanbulSnapshotPrefix)+common.HashLength):
+			istanbulSnaps.Add(size)
+		case bytes.HasPrefix(key, uptimePrefix) && len(key) == (len(uptimePrefix)+8):
+			uptimes.Add(size)
 		case bytes.HasPrefix(key, []byte("cht-")) && len(key) == 4+common.HashLength:
-			chtTrieNodes += size
+			chtTrieNodes.Add(size)
 		case bytes.HasPrefix(key, []byte("blt-")) && len(key) == 4+common.HashLength:
-			bloomTrieNodes += size
+			bloomTrieNodes.Add(size)
+		case bytes.HasPrefix(key, []byte("chtRootV2-")) && len(key) == 10+8:
+			chtRoots.Add(size)
+		case bytes.HasPrefix(key, []byte("bltRoot-")) && len(key) == 8+8:
+			bloomTrieRoots.Add(size)
 		case len(key) == common.HashLength:
-			trieSize += size
+			tries.Add(size)
 		default:
 			var accounted bool
-			for _, meta := range [][]byte{databaseVerisionKey, headHeaderKey, headBlockKey, headFastBlockKey, fastTrieProgressKey} {
+			for _, meta := range [][]byte{databaseVerisionKey, headHeaderKey, headBlockKey, headFastBlockKey, lastPivotKey, fastTrieProgressKey,
+				snapshotRootKey, snapshotJournalKey, snapshotGeneratorKey, snapshotRecoveryKey, txIndexTailKey, fastTxLookupLimitKey, historyTailKey} {
 				if bytes.Equal(key, meta) {
-					metadata += size
+					metadata.Add(size)
 					accounted = true
 					break
 				}
 			}
+			if !accounted && bytes.HasPrefix(key, configPrefix) {
+				metadata.Add(size)
+				accounted = true
+			}
 			if !accounted {
-				unaccounted += size
+				unaccounted.Add(size)
 			}
 		}
 		count += 1
@@ -335,44 +467,58 @@ func InspectDatabase(db ethdb.Database) error {
 		}
 	}
 	// Inspect append-only file store then.
-	ancients := []*common.StorageSize{&ancientHeaders, &ancientBodies, &ancientReceipts, &ancientHashes, &ancientTds}
+	var (
+		ancientHeaders  stat
+		ancientBodies   stat
+		ancientReceipts stat
+		ancientHashes   stat
+		ancientTds      stat
+	)
+	frozen, _ := db.Ancients()
+
+	ancients := []*stat{&ancientHeaders, &ancientBodies, &ancientReceipts, &ancientHashes, &ancientTds}
 	for i, category := range []string{freezerHeaderTable, freezerBodiesTable, freezerReceiptTable, freezerHashTable, freezerDifficultyTable} {
 		if size, err := db.AncientSize(category); err == nil {
-			*ancients[i] += common.StorageSize(size)
+			ancients[i].size += common.StorageSize(size)
+			ancients[i].count = frozen
 			total += common.StorageSize(size)
 		}
 	}
 	// Display the database statistic.
 	stats := [][]string{
-		{"Key-Value store", "Headers", headerSize.String()},
-		{"Key-Value store", "Bodies", bodySize.String()},
-		{"Key-Value store", "Receipts", receiptSize.String()},
-		{"Key-Value store", "Difficulties", tdSize.String()},
-		{"Key-Value store", "Block number->hash", numHashPairing.String()},
-		{"Key-Value store", "Block hash->number", hashNumPairing.String()},
-		{"Key-Value store", "Transaction index", txlookupSize.String()},
-		{"Key-Value store", "Bloombit index", bloomBitsSize.String()},
-		{"Key-Value store", "Trie nodes", trieSize.String()},
-		{"Key-Value store", "Trie preimages", preimageSize.String()},
-		{"Key-Value store", "Account snapshot", accountSnapSize.String()},
-		{"Key-Value store", "Storage snapshot", storageSnapSize.String()},
-		{"Key-Value store", "Singleton metadata", metadata.String()},
-		{"Ancient store", "Headers", ancientHeaders.String()},
-		{"Ancient store", "Bodies", ancientBodies.String()},
-		{"Ancient store", "Receipts", ancientReceipts.String()},
-		{"Ancient store", "Difficulties", ancientTds.String()},
-		{"Ancient store", "Block number->hash", ancientHashes.String()},
-		{"Light client", "CHT trie nodes", chtTrieNodes.String()},
-		{"Light client", "Bloom trie nodes", bloomTrieNodes.String()},
+		{"Key-Value store", "Headers", headers.Size(), headers.Count()},
+		{"Key-Value store", "Bodies", bodies.Size(), bodies.Count()},
+		{"Key-Value store", "Receipts", receipts.Size(), receipts.Count()},
+		{"Key-Value store", "Difficulties", tds.Size(), tds.Count()},
+		{"Key-Value store", "Block number->hash", numHashPairings.Size(), numHashPairings.Count()},
+		{"Key-Value store", "Block hash->number", hashNumPairings.Size(), hashNumPairings.Count()},
+		{"Key-Value store", "Transaction index", txLookups.Size(), txLookups.Count()},
+		{"Key-Value store", "Bloombit index", bloomBits.Size(), bloomBits.Count()},
+		{"Key-Value store", "Trie nodes", tries.Size(), tries.Count()},
+		{"Key-Value store", "Trie preimages", preimages.Size(), preimages.Count()},
+		{"Key-Value store", "Account snapshot", accountSnaps.Size(), accountSnaps.Count()},
+		{"Key-Value store", "Storage snapshot", storageSnaps.Size(), storageSnaps.Count()},
+		{"Key-Value store", "Istanbul snapshots", istanbulSnaps.Size(), istanbulSnaps.Count()},
+		{"Key-Value store", "Validator uptimes", uptimes.Size(), uptimes.Count()},
+		{"Key-Value store", "Singleton metadata", metadata.Size(), metadata.Count()},
+		{"Ancient store", "Headers", ancientHeaders.Size(), ancientHeaders.Count()},
+		{"Ancient store", "Bodies", ancientBodies.Size(), ancientBodies.Count()},
+		{"Ancient store", "Receipts", ancientReceipts.Size(), ancientReceipts.Count()},
+		{"Ancient store", "Difficulties", ancientTds.Size(), ancientTds.Count()},
+		{"Ancient store", "Block number->hash", ancientHashes.Size(), ancientHashes.Count()},
+		{"Light client", "CHT trie nodes", chtTrieNodes.Size(), chtTrieNodes.Count()},
+		{"Light client", "CHT roots", chtRoots.Size(), chtRoots.Count()},
+		{"Light client", "Bloom trie nodes", bloomTrieNodes.Size(), bloomTrieNodes.Count()},
+		{"Light client", "Bloom trie roots", bloomTrieRoots.Size(), bloomTrieRoots.Count()},
 	}
 	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Database", "Category", "Size"})
-	table.SetFooter([]string{"", "Total", total.String()})
+	table.SetHeader([]string{"Database", "Category", "Size", "Items"})
+	table.SetFooter([]string{"", "Total", total.String(), " "})
 	table.AppendBulk(stats)
 	table.Render()
 
-	if unaccounted > 0 {
-		log.Error("Database contains unaccounted data", "size", unaccounted)
+	if unaccounted.size > 0 {
+		log.Error("Database contains unaccounted data", "size", unaccounted.size, "count", unaccounted.count)
 	}
 	return nil
 }
@@ -0,0 +1,65 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Tests that the engine of an existing database is detected and respected.
+func TestKeyValueStoreEngineSelection(t *testing.T) {
+	dir, err := ioutil.TempDir("", "engine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, engine := range []string{DBLeveldb, DBPebble} {
+		path := filepath.Join(dir, engine)
+		if have := PreexistingDatabase(path); have != "" {
+			t.Fatalf("%s: unexpected preexisting database: %s", engine, have)
+		}
+		db, err := NewKeyValueStore(engine, path, 0, 0, "")
+		if err != nil {
+			t.Fatalf("%s: failed to create database: %v", engine, err)
+		}
+		if err := db.Put([]byte("key"), []byte("value")); err != nil {
+			t.Fatalf("%s: failed to write: %v", engine, err)
+		}
+		db.Close()
+
+		if have := PreexistingDatabase(path); have != engine {
+			t.Fatalf("preexisting engine mismatch: have %s, want %s", have, engine)
+		}
+		// Reopening without an explicit engine must pick the existing one
+		db, err = NewKeyValueStore("", path, 0, 0, "")
+		if err != nil {
+			t.Fatalf("%s: failed to reopen database: %v", engine, err)
+		}
+		if val, err := db.Get([]byte("key")); err != nil || string(val) != "value" {
+			t.Fatalf("%s: value mismatch: have %q (%v), want %q", engine, val, err, "value")
+		}
+		db.Close()
+	}
+	// Requesting a different engine than the existing one must fail
+	if _, err := NewKeyValueStore(DBPebble, filepath.Join(dir, DBLeveldb), 0, 0, ""); err == nil {
+		t.Fatalf("expected engine mismatch error")
+	}
+}
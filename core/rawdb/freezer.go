@@ -31,6 +31,7 @@ import (
 	"github.com/celo-org/celo-blockchain/log"
 	"github.com/celo-org/celo-blockchain/metrics"
 	"github.com/celo-org/celo-blockchain/params"
+	"github.com/celo-org/celo-blockchain/rlp"
 	"github.com/prometheus/tsdb/fileutil"
 )
 
@@ -83,8 +84,10 @@ type freezer struct {
 }
 
 // newFreezer creates a chain freezer that moves ancient chain data into
-// append-only flat file containers.
-func newFreezer(datadir string, namespace string) (*freezer, error) {
+// append-only flat file containers. If a remote store is given, sealed data
+// files are offloaded to it, keeping at most cacheFiles of them per table on
+// local disk.
+func newFreezer(datadir string, namespace string, remote AncientRemote, cacheFiles int) (*freezer, error) {
 	// Create the initial freezer object
 	var (
 		readMeter  = metrics.NewRegisteredMeter(namespace+"ancient/read", nil)
@@ -113,7 +116,11 @@ func newFreezer(datadir string, namespace string) (*freezer, error) {
 		quit:         make(chan struct{}),
 	}
 	for name, disableSnappy := range freezerNoSnappy {
-		table, err := newTable(datadir, name, readMeter, writeMeter, sizeGauge, disableSnappy)
+		var tableRemote *freezerRemote
+		if remote != nil {
+			tableRemote = newFreezerRemote(remote, cacheFiles)
+		}
+		table, err := newTable(datadir, name, readMeter, writeMeter, sizeGauge, disableSnappy, tableRemote)
 		if err != nil {
 			for _, table := range freezer.tables {
 				table.Close()
@@ -347,12 +354,20 @@ func (f *freezer) freeze(db ethdb.KeyValueStore) {
 				log.Error("Block header missing, can't freeze", "number", f.frozen, "hash", hash)
 				break
 			}
-			body := ReadBodyRLP(nfdb, hash, f.frozen)
+			body, receipts := ReadBodyRLP(nfdb, hash, f.frozen), ReadReceiptsRLP(nfdb, hash, f.frozen)
+			if tail := ReadHistoryTail(nfdb); tail != nil && f.frozen < *tail {
+				// Pruned history is frozen as empty placeholders, keeping the header
+				if len(body) == 0 {
+					body = rlp.EmptyList
+				}
+				if len(receipts) == 0 {
+					receipts = rlp.EmptyList
+				}
+			}
 			if len(body) == 0 {
 				log.Error("Block body missing, can't freeze", "number", f.frozen, "hash", hash)
 				break
 			}
-			receipts := ReadReceiptsRLP(nfdb, hash, f.frozen)
 			if len(receipts) == 0 {
 				log.Error("Block receipts missing, can't freeze", "number", f.frozen, "hash", hash)
 				break
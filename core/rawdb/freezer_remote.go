@@ -0,0 +1,187 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"os"
+	"path/filepath"
+	"sync/atomic"
+
+	"github.com/celo-org/celo-blockchain/common"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// AncientRemote is an object store holding sealed freezer data files. Once a
+// freezer table moves on to a new head file, the previous one is immutable
+// and gets uploaded to the remote store, after which the local copy only acts
+// as a cache that may be evicted and re-downloaded on demand.
+type AncientRemote interface {
+	// Has reports whether the named data file exists in the remote store.
+	Has(name string) (bool, error)
+
+	// Upload stores the local file at path in the remote store under name.
+	Upload(name string, path string) error
+
+	// Download retrieves the named data file from the remote store into path.
+	Download(name string, path string) error
+
+	// Delete removes the named data file from the remote store.
+	Delete(name string) error
+}
+
+// defaultAncientCacheFiles is the number of sealed data files per freezer table
+// kept locally when the ancient store is backed by a remote.
+const defaultAncientCacheFiles = 4
+
+// freezerRemote tracks the remote backing of the sealed data files of a single
+// freezer table. All methods assume the owning table's write lock is held,
+// unless noted otherwise.
+type freezerRemote struct {
+	store AncientRemote
+	table *freezerTable
+	cache *lru.Cache // Sealed data file numbers present locally and safe to evict
+
+	// epoch is bumped on every truncation, invalidating in-flight uploads of
+	// files that may have been rewritten in the meantime.
+	epoch uint64
+
+	// deleted is closed once the remote copies dropped by the last truncation
+	// are deleted. Uploads wait for it, lest a deletion hits a fresh upload.
+	deleted chan struct{}
+}
+
+// newFreezerRemote creates the remote backing of a freezer table, keeping at
+// most cacheFiles sealed data files locally.
+func newFreezerRemote(store AncientRemote, cacheFiles int) *freezerRemote {
+	if cacheFiles <= 0 {
+		cacheFiles = defaultAncientCacheFiles
+	}
+	r := &freezerRemote{store: store, deleted: make(chan struct{})}
+	close(r.deleted)
+	r.cache, _ = lru.NewWithEvict(cacheFiles, r.evict)
+	return r
+}
+
+// evict drops the local copy of an uploaded data file. It is invoked by the
+// cache while the table's write lock is held.
+func (r *freezerRemote) evict(key, value interface{}) {
+	num := key.(uint32)
+	if num >= r.table.headId {
+		return // Never drop the head, might have been rewound by a truncation
+	}
+	r.table.releaseFile(num)
+	if err := os.Remove(filepath.Join(r.table.path, r.table.fileName(num))); err != nil && !os.IsNotExist(err) {
+		r.table.logger.Warn("Failed to evict cached ancient file", "file", num, "err", err)
+	}
+}
+
+// fetch ensures the given data file is present locally, downloading it from
+// the remote store if it was evicted before.
+func (r *freezerRemote) fetch(num uint32) error {
+	name := r.table.fileName(num)
+	path := filepath.Join(r.table.path, name)
+	if common.FileExist(path) {
+		return nil
+	}
+	exists, err := r.store.Has(name)
+	if err != nil || !exists {
+		return err // Fresh file, will be created by the opener
+	}
+	r.table.logger.Debug("Fetching remote ancient file", "file", name)
+	tmp := path + ".download"
+	if err := r.store.Download(name, tmp); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// cached marks a sealed data file as locally present and remotely stored.
+func (r *freezerRemote) cached(num uint32) {
+	r.cache.Add(num, struct{}{})
+}
+
+// seal schedules the upload of a data file that just stopped being the head
+// of the table. It does not block on the upload.
+func (r *freezerRemote) seal(num uint32) {
+	epoch, deleted := atomic.LoadUint64(&r.epoch), r.deleted
+	go func() {
+		<-deleted
+
+		name := r.table.fileName(num)
+		if err := r.store.Upload(name, filepath.Join(r.table.path, name)); err != nil {
+			r.table.logger.Warn("Failed to upload sealed ancient file", "file", name, "err", err)
+			return
+		}
+		r.table.lock.Lock()
+		defer r.table.lock.Unlock()
+
+		// If the table was truncated meanwhile, the upload may be stale
+		if atomic.LoadUint64(&r.epoch) != epoch || num >= r.table.headId {
+			r.store.Delete(name)
+			return
+		}
+		r.cached(num)
+	}()
+}
+
+// truncated invalidates in-flight uploads and drops the remote copies of the
+// new head, which becomes mutable again, and of all data files above it. The
+// remote copies are deleted in the background, not to hold the table's write
+// lock across remote round trips.
+func (r *freezerRemote) truncated(head uint32) {
+	atomic.AddUint64(&r.epoch, 1)
+	for _, key := range r.cache.Keys() {
+		if num := key.(uint32); num >= head {
+			r.cache.Remove(key)
+		}
+	}
+	prev, done := r.deleted, make(chan struct{})
+	r.deleted = done
+
+	go func() {
+		defer close(done)
+		<-prev
+
+		for num := head; ; num++ {
+			name := r.table.fileName(num)
+			exists, err := r.store.Has(name)
+			if err != nil || !exists {
+				return
+			}
+			if err := r.store.Delete(name); err != nil {
+				r.table.logger.Warn("Failed to delete truncated remote ancient file", "file", name, "err", err)
+			}
+		}
+	}()
+}
+
+// resume uploads any sealed data files that are present locally but missing
+// from the remote store, e.g. because the node was stopped mid-upload.
+func (r *freezerRemote) resume() {
+	for num := r.table.tailId; num < r.table.headId; num++ {
+		name := r.table.fileName(num)
+		if !common.FileExist(filepath.Join(r.table.path, name)) {
+			continue
+		}
+		if exists, err := r.store.Has(name); err == nil && exists {
+			r.cached(num)
+		} else {
+			r.seal(num)
+		}
+	}
+}
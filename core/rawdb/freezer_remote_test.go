@@ -0,0 +1,159 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/metrics"
+)
+
+// dirRemote is an AncientRemote backed by a local directory.
+type dirRemote struct {
+	dir string
+}
+
+func (r *dirRemote) Has(name string) (bool, error) {
+	return common.FileExist(filepath.Join(r.dir, name)), nil
+}
+
+func (r *dirRemote) Upload(name string, path string) error {
+	blob, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(r.dir, name), blob, 0644)
+}
+
+func (r *dirRemote) Download(name string, path string) error {
+	blob, err := ioutil.ReadFile(filepath.Join(r.dir, name))
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, blob, 0644)
+}
+
+func (r *dirRemote) Delete(name string) error {
+	return os.Remove(filepath.Join(r.dir, name))
+}
+
+// waitRemote waits until the given number of data files are uploaded and at
+// most the given number of sealed data files remain cached locally.
+func waitRemote(t *testing.T, f *freezerTable, dir string, uploaded int, cached int) {
+	done := func() bool {
+		if files, _ := ioutil.ReadDir(dir); len(files) != uploaded {
+			return false
+		}
+		f.lock.RLock()
+		defer f.lock.RUnlock()
+
+		var local int
+		for num := f.tailId; num < f.headId; num++ {
+			if common.FileExist(filepath.Join(f.path, f.fileName(num))) {
+				local++
+			}
+		}
+		return local <= cached
+	}
+	for i := 0; i < 500; i++ {
+		if done() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	files, _ := ioutil.ReadDir(dir)
+	t.Fatalf("remote not settled: have %d files, want %d", len(files), uploaded)
+}
+
+// TestFreezerRemoteOffload tests that sealed data files are uploaded, evicted
+// locally beyond the cache allowance, fetched back on demand and deleted from
+// the remote upon truncation.
+func TestFreezerRemoteOffload(t *testing.T) {
+	t.Parallel()
+
+	local, err := ioutil.TempDir("", "freezer-local")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(local)
+	remoteDir, err := ioutil.TempDir("", "freezer-remote")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(remoteDir)
+
+	fname := fmt.Sprintf("remote-%d", rand.Uint64())
+	remote := newFreezerRemote(&dirRemote{dir: remoteDir}, 2)
+
+	// Write 15 bytes 30 times, results in 10 files of which 9 are sealed
+	f, err := newRemoteTable(local, fname, metrics.NewMeter(), metrics.NewMeter(), metrics.NewGauge(), 50, true, remote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	for x := 0; x < 30; x++ {
+		f.Append(uint64(x), getChunk(15, x))
+	}
+	// Only two of the sealed files may remain cached locally
+	waitRemote(t, f, remoteDir, 9, 2)
+
+	// All items must be retrievable, fetching evicted files from the remote
+	for y := 0; y < 30; y++ {
+		got, err := f.Retrieve(uint64(y))
+		if err != nil {
+			t.Fatalf("failed to retrieve item %d: %v", y, err)
+		}
+		if exp := getChunk(15, y); !bytes.Equal(got, exp) {
+			t.Fatalf("item %d mismatch: have %x, want %x", y, got, exp)
+		}
+	}
+	// Truncating must drop the remote files above the new head
+	if err := f.truncate(10); err != nil {
+		t.Fatal(err)
+	}
+	waitRemote(t, f, remoteDir, 3, 3)
+	if _, err := f.Retrieve(10); err != errOutOfBounds {
+		t.Fatalf("truncated item retrievable: %v", err)
+	}
+	if got, err := f.Retrieve(9); err != nil || !bytes.Equal(got, getChunk(15, 9)) {
+		t.Fatalf("head item mismatch after truncation: %x, %v", got, err)
+	}
+
+	// Rewriting the truncated items must upload the new data files after the
+	// stale remote copies are gone
+	for x := 10; x < 30; x++ {
+		f.Append(uint64(x), getChunk(15, 100+x))
+	}
+	waitRemote(t, f, remoteDir, 9, 2)
+	for y := 0; y < 30; y++ {
+		exp := getChunk(15, y)
+		if y >= 10 {
+			exp = getChunk(15, 100+y)
+		}
+		if got, err := f.Retrieve(uint64(y)); err != nil || !bytes.Equal(got, exp) {
+			t.Fatalf("item %d mismatch after rewrite: have %x, want %x, err %v", y, got, exp, err)
+		}
+	}
+}
@@ -43,6 +43,10 @@ var (
 
 	// errNotSupported is returned if the database doesn't support the required operation.
 	errNotSupported = errors.New("this operation is not supported")
+
+	// errOffloaded is returned internally if an item resides in a data file that
+	// was evicted locally and has to be fetched from the remote store.
+	errOffloaded = errors.New("data file offloaded")
 )
 
 // indexEntry contains the number/id of the file that the data resides in, aswell as the
@@ -99,13 +103,15 @@ type freezerTable struct {
 	writeMeter metrics.Meter // Meter for measuring the effective amount of data written
 	sizeGauge  metrics.Gauge // Gauge for tracking the combined size of all freezer tables
 
+	remote *freezerRemote // Remote store backing sealed data files (nil if local only)
+
 	logger log.Logger   // Logger with database path and table name ambedded
 	lock   sync.RWMutex // Mutex protecting the data file descriptors
 }
 
 // newTable opens a freezer table with default settings - 2G files
-func newTable(path string, name string, readMeter metrics.Meter, writeMeter metrics.Meter, sizeGauge metrics.Gauge, disableSnappy bool) (*freezerTable, error) {
-	return newCustomTable(path, name, readMeter, writeMeter, sizeGauge, 2*1000*1000*1000, disableSnappy)
+func newTable(path string, name string, readMeter metrics.Meter, writeMeter metrics.Meter, sizeGauge metrics.Gauge, disableSnappy bool, remote *freezerRemote) (*freezerTable, error) {
+	return newRemoteTable(path, name, readMeter, writeMeter, sizeGauge, 2*1000*1000*1000, disableSnappy, remote)
 }
 
 // openFreezerFileForAppend opens a freezer table file and seeks to the end
@@ -150,6 +156,12 @@ func truncateFreezerFile(file *os.File, size int64) error {
 // non existent. Both files are truncated to the shortest common length to ensure
 // they don't go out of sync.
 func newCustomTable(path string, name string, readMeter metrics.Meter, writeMeter metrics.Meter, sizeGauge metrics.Gauge, maxFilesize uint32, noCompression bool) (*freezerTable, error) {
+	return newRemoteTable(path, name, readMeter, writeMeter, sizeGauge, maxFilesize, noCompression, nil)
+}
+
+// newRemoteTable opens a freezer table like newCustomTable does, optionally
+// backing its sealed data files by a remote store.
+func newRemoteTable(path string, name string, readMeter metrics.Meter, writeMeter metrics.Meter, sizeGauge metrics.Gauge, maxFilesize uint32, noCompression bool, remote *freezerRemote) (*freezerTable, error) {
 	// Ensure the containing directory exists and open the indexEntry file
 	if err := os.MkdirAll(path, 0755); err != nil {
 		return nil, err
@@ -178,6 +190,10 @@ func newCustomTable(path string, name string, readMeter metrics.Meter, writeMete
 		logger:        log.New("database", path, "table", name),
 		noCompression: noCompression,
 		maxFileSize:   maxFilesize,
+		remote:        remote,
+	}
+	if remote != nil {
+		remote.table = tab
 	}
 	if err := tab.repair(); err != nil {
 		tab.Close()
@@ -191,6 +207,9 @@ func newCustomTable(path string, name string, readMeter metrics.Meter, writeMete
 	}
 	tab.sizeGauge.Inc(int64(size))
 
+	if tab.remote != nil {
+		tab.remote.resume()
+	}
 	return tab, nil
 }
 
@@ -315,6 +334,10 @@ func (t *freezerTable) preopen() (err error) {
 	t.releaseFilesAfter(0, false)
 	// Open all except head in RDONLY
 	for i := t.tailId; i < t.headId; i++ {
+		// Remotely backed files are fetched lazily upon first access
+		if t.remote != nil && !common.FileExist(filepath.Join(t.path, t.fileName(i))) {
+			continue
+		}
 		if _, err = t.openFile(i, openFreezerFileForReadOnly); err != nil {
 			return err
 		}
@@ -365,6 +388,10 @@ func (t *freezerTable) truncate(items uint64) error {
 		// Set back the historic head
 		t.head = newHead
 		atomic.StoreUint32(&t.headId, expected.filenum)
+
+		if t.remote != nil {
+			t.remote.truncated(expected.filenum)
+		}
 	}
 	if err := truncateFreezerFile(t.head, int64(expected.offset)); err != nil {
 		return err
@@ -407,17 +434,24 @@ func (t *freezerTable) Close() error {
 	return nil
 }
 
+// fileName returns the name of the data file with the given number.
+func (t *freezerTable) fileName(num uint32) string {
+	if t.noCompression {
+		return fmt.Sprintf("%s.%04d.rdat", t.name, num)
+	}
+	return fmt.Sprintf("%s.%04d.cdat", t.name, num)
+}
+
 // openFile assumes that the write-lock is held by the caller
 func (t *freezerTable) openFile(num uint32, opener func(string) (*os.File, error)) (f *os.File, err error) {
 	var exist bool
 	if f, exist = t.files[num]; !exist {
-		var name string
-		if t.noCompression {
-			name = fmt.Sprintf("%s.%04d.rdat", t.name, num)
-		} else {
-			name = fmt.Sprintf("%s.%04d.cdat", t.name, num)
+		if t.remote != nil {
+			if err = t.remote.fetch(num); err != nil {
+				return nil, err
+			}
 		}
-		f, err = opener(filepath.Join(t.path, name))
+		f, err = opener(filepath.Join(t.path, t.fileName(num)))
 		if err != nil {
 			return nil, err
 		}
@@ -489,6 +523,11 @@ func (t *freezerTable) Append(item uint64, blob []byte) error {
 		t.releaseFile(t.headId)
 		t.openFile(t.headId, openFreezerFileForReadOnly)
 
+		// The old head is immutable from now on, offload it if requested
+		if t.remote != nil {
+			t.remote.seal(t.headId)
+		}
+
 		// Swap out the current head
 		t.head = newHead
 		atomic.StoreUint32(&t.headBytes, 0)
@@ -552,44 +591,81 @@ func (t *freezerTable) getBounds(item uint64) (uint32, uint32, uint32, error) {
 // Retrieve looks up the data offset of an item with the given number and retrieves
 // the raw binary blob from the data file.
 func (t *freezerTable) Retrieve(item uint64) ([]byte, error) {
+	for {
+		blob, offloaded, err := t.retrieve(item)
+		if err == errOffloaded {
+			// The data file was offloaded, fetch it back under the write lock
+			// and redo the lookup, as the table might have changed meanwhile
+			if err := t.openRemoteFile(offloaded); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		t.readMeter.Mark(int64(len(blob) + 2*indexEntrySize))
+
+		if t.noCompression {
+			return blob, nil
+		}
+		return snappy.Decode(nil, blob)
+	}
+}
+
+// retrieve looks up and reads the raw binary blob of an item under the read lock.
+// If the data file holding it was offloaded to the remote store, errOffloaded is
+// returned along with the number of the data file.
+func (t *freezerTable) retrieve(item uint64) ([]byte, uint32, error) {
 	t.lock.RLock()
+	defer t.lock.RUnlock()
+
 	// Ensure the table and the item is accessible
 	if t.index == nil || t.head == nil {
-		t.lock.RUnlock()
-		return nil, errClosed
+		return nil, 0, errClosed
 	}
 	if atomic.LoadUint64(&t.items) <= item {
-		t.lock.RUnlock()
-		return nil, errOutOfBounds
+		return nil, 0, errOutOfBounds
 	}
 	// Ensure the item was not deleted from the tail either
 	if uint64(t.itemOffset) > item {
-		t.lock.RUnlock()
-		return nil, errOutOfBounds
+		return nil, 0, errOutOfBounds
 	}
 	startOffset, endOffset, filenum, err := t.getBounds(item - uint64(t.itemOffset))
 	if err != nil {
-		t.lock.RUnlock()
-		return nil, err
+		return nil, 0, err
 	}
 	dataFile, exist := t.files[filenum]
 	if !exist {
-		t.lock.RUnlock()
-		return nil, fmt.Errorf("missing data file %d", filenum)
+		if t.remote != nil {
+			return nil, filenum, errOffloaded
+		}
+		return nil, 0, fmt.Errorf("missing data file %d", filenum)
 	}
-	// Retrieve the data itself, decompress and return
+	// Retrieve the data itself
 	blob := make([]byte, endOffset-startOffset)
 	if _, err := dataFile.ReadAt(blob, int64(startOffset)); err != nil {
-		t.lock.RUnlock()
-		return nil, err
+		return nil, 0, err
 	}
-	t.lock.RUnlock()
-	t.readMeter.Mark(int64(len(blob) + 2*indexEntrySize))
+	return blob, 0, nil
+}
 
-	if t.noCompression {
-		return blob, nil
+// openRemoteFile opens a sealed, remotely backed data file for reading,
+// downloading it first if it's not cached locally.
+func (t *freezerTable) openRemoteFile(num uint32) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.index == nil || t.head == nil {
+		return errClosed
+	}
+	if _, err := t.openFile(num, openFreezerFileForReadOnly); err != nil {
+		return err
+	}
+	if num < t.headId {
+		t.remote.cached(num)
 	}
-	return snappy.Decode(nil, blob)
+	return nil
 }
 
 // has returns an indicator whether the specified number data
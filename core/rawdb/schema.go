@@ -59,6 +59,9 @@ var (
 	// txIndexTailKey tracks the oldest block whose transactions have been indexed.
 	txIndexTailKey = []byte("TransactionIndexTail")
 
+	// historyTailKey tracks the oldest block whose body and receipts are retained.
+	historyTailKey = []byte("BlockHistoryTail")
+
 	// fastTxLookupLimitKey tracks the transaction lookup limit during fast sync.
 	fastTxLookupLimitKey = []byte("FastTransactionLookupLimit")
 
@@ -76,6 +79,9 @@ var (
 	SnapshotAccountPrefix = []byte("a") // SnapshotAccountPrefix + account hash -> account trie value
 	SnapshotStoragePrefix = []byte("o") // SnapshotStoragePrefix + account hash + storage hash -> storage trie value
 
+	istanbulSnapshotPrefix = []byte("istanbul-snapshot") // istanbulSnapshotPrefix + hash -> istanbul validator set snapshot
+	uptimePrefix           = []byte("uptime")            // uptimePrefix + epoch (uint64 big endian) -> validator uptimes
+
 	preimagePrefix = []byte("secure-key-")      // preimagePrefix + hash -> preimage
 	configPrefix   = []byte("ethereum-config-") // config prefix for the db
 
@@ -146,7 +152,7 @@ func headerTDKey(number uint64, hash common.Hash) []byte {
 // uptimeKey = uptimePrefix + epoch number
 func uptimeKey(epoch uint64) []byte {
 	// abuse encodeBlockNumber for epochs
-	return append([]byte("uptime"), encodeBlockNumber(epoch)...)
+	return append(uptimePrefix, encodeBlockNumber(epoch)...)
 }
 
 // headerHashKey = headerPrefix + num (uint64 big endian) + headerHashSuffix
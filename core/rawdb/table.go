@@ -115,6 +115,17 @@ func (t *table) NewIterator(prefix []byte, start []byte) ethdb.Iterator {
 	}
 }
 
+// NewSnapshot creates a database snapshot based on the current state.
+// The created snapshot will not be affected by all following mutations
+// happened on the database.
+func (t *table) NewSnapshot() (ethdb.Snapshot, error) {
+	snap, err := t.db.NewSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	return &tableSnapshot{snap: snap, prefix: t.prefix}, nil
+}
+
 // Stat returns a particular internal stat of the database.
 func (t *table) Stat(property string) (string, error) {
 	return t.db.Stat(property)
@@ -257,3 +268,35 @@ func (iter *tableIterator) Value() []byte {
 func (iter *tableIterator) Release() {
 	iter.iter.Release()
 }
+
+// tableSnapshot is a wrapper around a database snapshot that prefixes each key
+// access with a pre-configured string.
+type tableSnapshot struct {
+	snap   ethdb.Snapshot
+	prefix string
+}
+
+// Has retrieves if a prefixed version of a key is present in the snapshot.
+func (s *tableSnapshot) Has(key []byte) (bool, error) {
+	return s.snap.Has(append([]byte(s.prefix), key...))
+}
+
+// Get retrieves the given prefixed key if it's present in the snapshot.
+func (s *tableSnapshot) Get(key []byte) ([]byte, error) {
+	return s.snap.Get(append([]byte(s.prefix), key...))
+}
+
+// NewIterator creates a binary-alphabetical iterator over a subset of the
+// snapshot content with a particular key prefix, starting at a particular
+// initial key (or after, if it does not exist).
+func (s *tableSnapshot) NewIterator(prefix []byte, start []byte) ethdb.Iterator {
+	return &tableIterator{
+		iter:   s.snap.NewIterator(append([]byte(s.prefix), prefix...), start),
+		prefix: s.prefix,
+	}
+}
+
+// Release releases associated resources.
+func (s *tableSnapshot) Release() {
+	s.snap.Release()
+}
@@ -17,6 +17,8 @@
 package core
 
 import (
+	"runtime"
+	"sync"
 	"sync/atomic"
 
 	"github.com/celo-org/celo-blockchain/common"
@@ -78,6 +80,69 @@ func (p *statePrefetcher) Prefetch(block *types.Block, statedb *state.StateDB, c
 	}
 }
 
+// Warm concurrently loads the state accessed by the transactions of a block
+// into the caches, ahead of its sequential execution on top of the state with
+// the given root. Transactions are spread over workers, each executing its
+// share on a throwaway copy of the state, regardless of the dependencies
+// between them: the goal is not to execute them successfully, but to warm the
+// account and storage trie nodes and the contract code they touch, which no
+// ordering can change much.
+func (p *statePrefetcher) Warm(block *types.Block, root common.Hash, interrupt *uint32) {
+	txs := block.Transactions()
+	workers := runtime.NumCPU()
+	if workers > len(txs) {
+		workers = len(txs)
+	}
+	var (
+		header = block.Header()
+		signer = types.MakeSigner(p.config, header.Number)
+		jobs   = make(chan *types.Transaction, len(txs))
+		wg     sync.WaitGroup
+	)
+	for _, tx := range txs {
+		jobs <- tx
+	}
+	close(jobs)
+
+	for i := 0; i < workers; i++ {
+		statedb, err := state.New(root, p.bc.stateCache, p.bc.snaps)
+		if err != nil {
+			return
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for tx := range jobs {
+				if interrupt != nil && atomic.LoadUint32(interrupt) == 1 {
+					return
+				}
+				warmTransaction(p.config, p.bc, signer, statedb, header, tx)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// warmTransaction loads the accounts and contract code referenced by the
+// transaction, and executes it without nonce check to warm the storage it
+// accesses.
+func warmTransaction(config *params.ChainConfig, bc *BlockChain, signer types.Signer, statedb *state.StateDB, header *types.Header, tx *types.Transaction) {
+	// Recovering the sender also caches it in the transaction for execution
+	from, err := types.Sender(signer, tx)
+	if err != nil {
+		return
+	}
+	statedb.GetBalance(from)
+	for _, addr := range []*common.Address{tx.To(), tx.FeeCurrency(), tx.GatewayFeeRecipient()} {
+		if addr != nil {
+			statedb.GetCode(*addr)
+		}
+	}
+	msg := types.NewMessage(from, tx.To(), tx.Nonce(), tx.Value(), tx.Gas(), tx.GasPrice(), tx.FeeCurrency(), tx.GatewayFeeRecipient(), tx.GatewayFee(), tx.Data(), tx.EthCompatible(), false)
+	vm := vm.NewEVM(NewEVMContext(msg, header, bc, nil), statedb, config, bc.vmConfig)
+	ApplyMessage(vm, msg, new(GasPool).AddGas(tx.Gas()), bc.NewEVMRunner(header, statedb))
+}
+
 // precacheTransaction attempts to apply a transaction to the given state database
 // and uses the input parameters for its environment. The goal is not to execute
 // the transaction successfully, rather to warm up touched data slots.
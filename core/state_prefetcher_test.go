@@ -0,0 +1,104 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"context"
+	"math/big"
+	"sync/atomic"
+	"testing"
+
+	"github.com/celo-org/celo-blockchain/common"
+	mockEngine "github.com/celo-org/celo-blockchain/consensus/consensustest"
+	"github.com/celo-org/celo-blockchain/core/rawdb"
+	"github.com/celo-org/celo-blockchain/core/state"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/core/vm"
+	"github.com/celo-org/celo-blockchain/crypto"
+	"github.com/celo-org/celo-blockchain/ethdb"
+	"github.com/celo-org/celo-blockchain/params"
+)
+
+// readCountingDatabase counts the reads reaching the database.
+type readCountingDatabase struct {
+	ethdb.Database
+	reads int64
+}
+
+func (db *readCountingDatabase) Get(key []byte) ([]byte, error) {
+	atomic.AddInt64(&db.reads, 1)
+	return db.Database.Get(key)
+}
+
+// Tests that warming the state of a block saves its execution from reading the
+// database.
+func TestWarmState(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		alloc   = GenesisAlloc{address: {Balance: big.NewInt(1000000000)}}
+	)
+	for i := 0; i < 256; i++ {
+		alloc[common.BigToAddress(big.NewInt(int64(i+1)))] = GenesisAccount{Balance: big.NewInt(1)}
+	}
+	var (
+		gspec   = &Genesis{Config: params.TestChainConfig, Alloc: alloc}
+		gendb   = rawdb.NewMemoryDatabase()
+		genesis = gspec.MustCommit(gendb)
+		signer  = types.NewEIP155Signer(gspec.Config.ChainID)
+	)
+	blocks, _ := GenerateChain(gspec.Config, genesis, mockEngine.NewFaker(), gendb, 1, func(i int, block *BlockGen) {
+		for j := 0; j < 32; j++ {
+			to := common.BigToAddress(big.NewInt(int64(8*j + 1)))
+			tx, err := types.SignTx(types.NewTransaction(block.TxNonce(address), to, big.NewInt(1000), params.TxGas, nil, nil, nil, nil, nil), signer, key)
+			if err != nil {
+				panic(err)
+			}
+			block.AddTx(tx)
+		}
+	})
+	// execute processes the block on a cold chain, returning the database
+	// reads of the execution
+	execute := func(warm bool) int64 {
+		db := &readCountingDatabase{Database: rawdb.NewMemoryDatabase()}
+		gspec.MustCommit(db)
+
+		chain, err := NewBlockChain(db, &CacheConfig{TrieCleanLimit: 256, TrieDirtyLimit: 256, TrieTimeLimit: defaultCacheConfig.TrieTimeLimit}, gspec.Config, mockEngine.NewFaker(), vm.Config{}, nil, nil)
+		if err != nil {
+			t.Fatalf("failed to create tester chain: %v", err)
+		}
+		defer chain.Stop()
+
+		root := chain.Genesis().Root()
+		if warm {
+			chain.warmer.Warm(blocks[0], root, nil)
+		}
+		statedb, err := state.New(root, chain.stateCache, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reads := atomic.LoadInt64(&db.reads)
+		if _, _, _, err := chain.processor.Process(context.Background(), blocks[0], statedb, chain.vmConfig); err != nil {
+			t.Fatalf("failed to process block: %v", err)
+		}
+		return atomic.LoadInt64(&db.reads) - reads
+	}
+	cold, warm := execute(false), execute(true)
+	if warm >= cold {
+		t.Errorf("warming saved no database reads: %d warm, %d cold", warm, cold)
+	}
+}
@@ -17,6 +17,8 @@
 package core
 
 import (
+	"context"
+
 	"github.com/celo-org/celo-blockchain/common"
 	"github.com/celo-org/celo-blockchain/consensus"
 	"github.com/celo-org/celo-blockchain/consensus/misc"
@@ -25,6 +27,7 @@ import (
 	"github.com/celo-org/celo-blockchain/core/state"
 	"github.com/celo-org/celo-blockchain/core/types"
 	"github.com/celo-org/celo-blockchain/core/vm"
+	"github.com/celo-org/celo-blockchain/core/vm/vmcontext"
 	"github.com/celo-org/celo-blockchain/crypto"
 	"github.com/celo-org/celo-blockchain/params"
 )
@@ -54,13 +57,13 @@ func NewStateProcessor(config *params.ChainConfig, bc *BlockChain, engine consen
 // Process returns the receipts and logs accumulated during the process and
 // returns the amount of gas that was used in the process. If any of the
 // transactions failed to execute due to insufficient gas it will return an error.
-func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg vm.Config) (types.Receipts, []*types.Log, uint64, error) {
+func (p *StateProcessor) Process(ctx context.Context, block *types.Block, statedb *state.StateDB, cfg vm.Config) (types.Receipts, []*types.Log, uint64, error) {
 	var (
 		receipts types.Receipts
 		usedGas  = new(uint64)
 		header   = block.Header()
 		allLogs  []*types.Log
-		vmRunner = p.bc.NewEVMRunner(block.Header(), statedb)
+		vmRunner = vmcontext.NewEVMRunnerWithContext(ctx, p.bc, block.Header(), statedb)
 		gp       = new(GasPool).AddGas(blockchain_parameters.GetBlockGasLimitOrDefault(vmRunner))
 	)
 	// Mutate the block and state according to any hard-fork specs
@@ -83,8 +86,17 @@ func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg
 	}
 	// Iterate over and process the individual transactions
 	for i, tx := range block.Transactions() {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, 0, err
+		}
 		statedb.Prepare(tx.Hash(), block.Hash(), i)
-		receipt, err := ApplyTransaction(p.config, p.bc, nil, gp, statedb, header, tx, usedGas, cfg, vmRunner)
+		txCfg := cfg
+		if sampler := p.bc.TraceSampler(); sampler != nil && !cfg.Debug {
+			if tracer := sampler.sample(); tracer != nil {
+				txCfg.Debug, txCfg.Tracer = true, tracer
+			}
+		}
+		receipt, err := ApplyTransaction(ctx, p.config, p.bc, nil, gp, statedb, header, tx, usedGas, txCfg, vmRunner)
 		if err != nil {
 			return nil, nil, 0, err
 		}
@@ -97,14 +109,19 @@ func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg
 	// Add the block receipt with logs from the non-transaction core contract calls (if there were any)
 	receipts = AddBlockReceipt(receipts, statedb, block.Hash())
 
+	// System calls aborted by the context leave the state incomplete
+	if err := ctx.Err(); err != nil {
+		return nil, nil, 0, err
+	}
 	return receipts, allLogs, *usedGas, nil
 }
 
 // ApplyTransaction attempts to apply a transaction to the given state database
 // and uses the input parameters for its environment. It returns the receipt
 // for the transaction, gas used and an error if the transaction failed,
-// indicating the block was invalid.
-func ApplyTransaction(config *params.ChainConfig, bc ChainContext, txFeeRecipient *common.Address, gp *GasPool, statedb *state.StateDB, header *types.Header, tx *types.Transaction, usedGas *uint64, cfg vm.Config, vmRunner vm.EVMRunner) (*types.Receipt, error) {
+// indicating the block was invalid. The execution is aborted once the context
+// is done, returning the error of the context.
+func ApplyTransaction(ctx context.Context, config *params.ChainConfig, bc ChainContext, txFeeRecipient *common.Address, gp *GasPool, statedb *state.StateDB, header *types.Header, tx *types.Transaction, usedGas *uint64, cfg vm.Config, vmRunner vm.EVMRunner) (*types.Receipt, error) {
 	if config.IsDonut(header.Number) && !tx.Protected() {
 		return nil, ErrUnprotectedTransaction
 	}
@@ -114,13 +131,17 @@ func ApplyTransaction(config *params.ChainConfig, bc ChainContext, txFeeRecipien
 	}
 
 	// Create a new context to be used in the EVM environment
-	ctx := NewEVMContext(msg, header, bc, txFeeRecipient)
+	evmContext := NewEVMContext(msg, header, bc, txFeeRecipient)
 	// Create a new environment which holds all relevant information
 	// about the transaction and calling mechanisms.
-	vmenv := vm.NewEVM(ctx, statedb, config, cfg)
+	vmenv := vm.NewEVM(evmContext, statedb, config, cfg)
+	vmenv.CancelOnDone(ctx)
 
 	// Apply the transaction to the current state (included in the env)
 	result, err := ApplyMessage(vmenv, msg, gp, vmRunner)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
 	if err != nil {
 		return nil, err
 	}
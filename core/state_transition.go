@@ -78,6 +78,9 @@ type Message interface {
 	FeeCurrency() *common.Address
 	GatewayFeeRecipient() *common.Address
 	GatewayFee() *big.Int
+	// FeePayer specifies the account paying for gas and gateway fees of sponsored
+	// transactions. nil means the sender pays them.
+	FeePayer() *common.Address
 	Value() *big.Int
 
 	Nonce() uint64
@@ -89,7 +92,7 @@ type Message interface {
 }
 
 func CheckEthCompatibility(msg Message) error {
-	if msg.EthCompatible() && !(msg.FeeCurrency() == nil && msg.GatewayFeeRecipient() == nil && msg.GatewayFee().Sign() == 0) {
+	if msg.EthCompatible() && !(msg.FeeCurrency() == nil && msg.GatewayFeeRecipient() == nil && msg.GatewayFee().Sign() == 0 && msg.FeePayer() == nil) {
 		return types.ErrEthCompatibleTransactionIsntCompatible
 	}
 	return nil
@@ -98,9 +101,10 @@ func CheckEthCompatibility(msg Message) error {
 // ExecutionResult includes all output after executing given evm
 // message no matter the execution itself is successful or not.
 type ExecutionResult struct {
-	UsedGas    uint64 // Total used gas but include the refunded gas
-	Err        error  // Any error encountered during the execution(listed in core/vm/errors.go)
-	ReturnData []byte // Returned data from evm(function result or data supplied with revert opcode)
+	UsedGas     uint64 // Total used gas but include the refunded gas
+	RefundedGas uint64 // Total gas refunded after execution
+	Err         error  // Any error encountered during the execution(listed in core/vm/errors.go)
+	ReturnData  []byte // Returned data from evm(function result or data supplied with revert opcode)
 }
 
 // Unwrap returns the internal evm error which allows us for further
@@ -131,7 +135,10 @@ func (result *ExecutionResult) Revert() []byte {
 }
 
 // IntrinsicGas computes the 'intrinsic gas' for a message with the given data.
-func IntrinsicGas(data []byte, contractCreation bool, feeCurrency *common.Address, gasForAlternativeCurrency uint64, isEIP2028 bool) (uint64, error) {
+// The Celo specific costs are taken from the given schedule, except the one of
+// paying in a non-native currency which is governed by the BlockchainParameters
+// contract and passed as gasForAlternativeCurrency.
+func IntrinsicGas(data []byte, contractCreation bool, feeCurrency, gatewayFeeRecipient *common.Address, gasForAlternativeCurrency uint64, schedule *params.IntrinsicGasSchedule, isEIP2028 bool) (uint64, error) {
 	// Set the starting gas for the raw transaction
 	var gas uint64
 	if contractCreation {
@@ -185,6 +192,14 @@ func IntrinsicGas(data []byte, contractCreation bool, feeCurrency *common.Addres
 		}
 		gas += gasForAlternativeCurrency
 	}
+	// The gateway fee is only charged if a recipient is set, see payFees.
+	if gatewayFeeRecipient != nil {
+		if (math.MaxUint64 - gas) < schedule.GatewayFee {
+			log.Debug("IntrinsicGas", "gas uint overflow")
+			return 0, ErrGasUintOverflow
+		}
+		gas += schedule.GatewayFee
+	}
 
 	return gas, nil
 }
@@ -230,6 +245,14 @@ func ApplyMessageWithoutGasPriceMinimum(evm *vm.EVM, msg Message, gp *GasPool, v
 }
 
 // to returns the recipient of the message.
+// feePayer returns the account paying the fees of the message.
+func (st *StateTransition) feePayer() common.Address {
+	if feePayer := st.msg.FeePayer(); feePayer != nil {
+		return *feePayer
+	}
+	return st.msg.From()
+}
+
 func (st *StateTransition) to() common.Address {
 	if st.msg == nil || st.msg.To() == nil /* contract creation */ {
 		return common.Address{}
@@ -237,7 +260,7 @@ func (st *StateTransition) to() common.Address {
 	return *st.msg.To()
 }
 
-// payFees deducts gas and gateway fees from sender balance and adds the purchased amount of gas to the state.
+// payFees deducts gas and gateway fees from the fee payer balance and adds the purchased amount of gas to the state.
 func (st *StateTransition) payFees() error {
 	if !currency.IsWhitelisted(st.vmRunner, st.msg.FeeCurrency()) {
 		log.Trace("Fee currency not whitelisted", "fee currency address", st.msg.FeeCurrency())
@@ -251,7 +274,7 @@ func (st *StateTransition) payFees() error {
 		feeVal.Add(feeVal, st.msg.GatewayFee())
 	}
 
-	if !st.canPayFee(st.msg.From(), feeVal, st.msg.FeeCurrency()) {
+	if !st.canPayFee(st.feePayer(), feeVal, st.msg.FeeCurrency()) {
 		return ErrInsufficientFundsForFees
 	}
 	if err := st.gp.SubGas(st.msg.Gas()); err != nil {
@@ -260,7 +283,7 @@ func (st *StateTransition) payFees() error {
 
 	st.initialGas = st.msg.Gas()
 	st.gas += st.msg.Gas()
-	err := st.debitFee(st.msg.From(), feeVal, st.msg.FeeCurrency())
+	err := st.debitFee(st.feePayer(), feeVal, st.msg.FeeCurrency())
 	return err
 }
 
@@ -386,11 +409,11 @@ func (st *StateTransition) TransitionDb() (*ExecutionResult, error) {
 	// First check this message satisfies all consensus rules before
 	// applying the message. The rules include these clauses
 	//
-	// 0. If the message is from an eth-compatible transaction, that we support those
-	//    and that none of the non-eth-compatible fields are present
+	// 0. If the message is from an eth-compatible or a sponsored transaction, that we
+	//    support those and that none of the non-eth-compatible fields are present
 	// 1. the nonce of the message caller is correct
 	// 2. the gas price meets the minimum gas price
-	// 3. caller has enough balance (in the right currency) to cover transaction fee
+	// 3. fee payer has enough balance (in the right currency) to cover transaction fee
 	// 4. the amount of gas required is available in the block
 	// 5. the purchased gas is enough to cover intrinsic usage
 	// 6. there is no overflow when calculating intrinsic gas
@@ -403,6 +426,9 @@ func (st *StateTransition) TransitionDb() (*ExecutionResult, error) {
 	if err := CheckEthCompatibility(st.msg); err != nil {
 		return nil, err
 	}
+	if st.msg.FeePayer() != nil && !st.evm.ChainConfig().IsEHardfork(st.evm.BlockNumber) {
+		return nil, ErrSponsoredTransactionsNotSupported
+	}
 
 	// Check clauses 1-2
 	if err := st.preCheck(); err != nil {
@@ -414,12 +440,13 @@ func (st *StateTransition) TransitionDb() (*ExecutionResult, error) {
 	contractCreation := msg.To() == nil
 
 	// Calculate intrinsic gas, check clauses 5-6
+	schedule := st.evm.ChainConfig().IntrinsicGasSchedule(st.evm.BlockNumber)
 	gasForAlternativeCurrency := uint64(0)
 	// If the fee currency is nil, do not retrieve the intrinsic gas adjustment from the chain state, as it will not be used.
 	if msg.FeeCurrency() != nil {
-		gasForAlternativeCurrency = blockchain_parameters.GetIntrinsicGasForAlternativeFeeCurrencyOrDefault(st.vmRunner)
+		gasForAlternativeCurrency = blockchain_parameters.GetIntrinsicGasForAlternativeFeeCurrencyOrDefault(st.vmRunner, schedule)
 	}
-	gas, err := IntrinsicGas(st.data, contractCreation, msg.FeeCurrency(), gasForAlternativeCurrency, istanbul)
+	gas, err := IntrinsicGas(st.data, contractCreation, msg.FeeCurrency(), msg.GatewayFeeRecipient(), gasForAlternativeCurrency, schedule, istanbul)
 	if err != nil {
 		return nil, err
 	}
@@ -452,7 +479,7 @@ func (st *StateTransition) TransitionDb() (*ExecutionResult, error) {
 		ret, st.gas, vmerr = st.evm.Call(sender, st.to(), st.data, st.gas, st.value)
 	}
 
-	st.refundGas()
+	refund := st.refundGas()
 
 	err = st.distributeTxFees()
 	if err != nil {
@@ -460,9 +487,10 @@ func (st *StateTransition) TransitionDb() (*ExecutionResult, error) {
 	}
 
 	return &ExecutionResult{
-		UsedGas:    st.gasUsed(),
-		Err:        vmerr,
-		ReturnData: ret,
+		UsedGas:     st.gasUsed(),
+		RefundedGas: refund,
+		Err:         vmerr,
+		ReturnData:  ret,
 	}, nil
 }
 
@@ -478,7 +506,7 @@ func (st *StateTransition) distributeTxFees() error {
 	refund := new(big.Int).Mul(new(big.Int).SetUint64(st.gas), st.gasPrice)
 	gasUsed := new(big.Int).SetUint64(st.gasUsed())
 	totalTxFee := new(big.Int).Mul(gasUsed, st.gasPrice)
-	from := st.msg.From()
+	from := st.feePayer()
 
 	// Divide the transaction into a base (the minimum transaction fee) and tip (any extra).
 	baseTxFee := new(big.Int).Mul(gasUsed, st.gasPriceMinimum)
@@ -525,8 +553,9 @@ func (st *StateTransition) distributeTxFees() error {
 	return nil
 }
 
-// refundGas adds unused gas back the state transition and gas pool.
-func (st *StateTransition) refundGas() {
+// refundGas adds unused gas back the state transition and gas pool, and returns
+// the amount of gas refunded.
+func (st *StateTransition) refundGas() uint64 {
 	refund := st.state.GetRefund()
 	// Apply refund counter, capped to half of the used gas.
 	if refund > st.gasUsed()/2 {
@@ -537,6 +566,7 @@ func (st *StateTransition) refundGas() {
 	// Also return remaining gas to the block gas counter so it is
 	// available for the next transaction.
 	st.gp.AddGas(st.gas)
+	return refund
 }
 
 // gasUsed returns the amount of gas used up by the state transition.
@@ -0,0 +1,232 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	mrand "math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/core/vm"
+	"github.com/celo-org/celo-blockchain/metrics"
+)
+
+// maxSampledContracts is the number of contracts the trace sampler keeps
+// statistics for. Once reached, the half of the contracts that used the least
+// gas is forgotten.
+const maxSampledContracts = 10000
+
+var (
+	sampledTxMeter       = metrics.NewRegisteredMeter("chain/sampling/txs", nil)
+	sampledRevertMeter   = metrics.NewRegisteredMeter("chain/sampling/reverts", nil)
+	sampledFailureMeter  = metrics.NewRegisteredMeter("chain/sampling/failures", nil)
+	sampledGasHistogram  = metrics.NewRegisteredHistogram("chain/sampling/gas", nil, metrics.NewExpDecaySample(1028, 0.015))
+	sampledContractGauge = metrics.NewRegisteredGauge("chain/sampling/contracts", nil)
+)
+
+// SampledContract holds the statistics of the sampled transactions calling or
+// creating a contract.
+type SampledContract struct {
+	Address      common.Address `json:"address"`
+	Transactions uint64         `json:"transactions"`
+	Reverts      uint64         `json:"reverts"`
+	Failures     uint64         `json:"failures"` // Exceptional halts, reverts excluded
+	Gas          uint64         `json:"gas"`      // Gas used by execution, intrinsic gas excluded
+}
+
+// TraceSamplingStats are the aggregate statistics of the sampled transactions.
+type TraceSamplingStats struct {
+	Since        time.Time          `json:"since"`
+	Rate         float64            `json:"rate"`
+	Transactions uint64             `json:"transactions"`
+	Reverts      uint64             `json:"reverts"`
+	Failures     uint64             `json:"failures"`
+	RevertRate   float64            `json:"revertRate"`
+	Contracts    []*SampledContract `json:"contracts"` // Sorted by gas used, descending
+}
+
+// TraceSampler traces a fraction of the transactions of imported blocks with a
+// lightweight tracer, aggregating statistics about them. It gives continuous
+// insight into the execution of the chain without the cost of full tracing.
+type TraceSampler struct {
+	rate float64
+
+	lock      sync.Mutex
+	rand      *mrand.Rand
+	since     time.Time
+	txs       uint64
+	reverts   uint64
+	failures  uint64
+	contracts map[common.Address]*SampledContract
+}
+
+// NewTraceSampler creates a trace sampler tracing the given fraction of the
+// transactions.
+func NewTraceSampler(rate float64) *TraceSampler {
+	var seed [8]byte
+	rand.Read(seed[:])
+	return &TraceSampler{
+		rate:      rate,
+		rand:      mrand.New(mrand.NewSource(int64(binary.BigEndian.Uint64(seed[:])))),
+		since:     time.Now(),
+		contracts: make(map[common.Address]*SampledContract),
+	}
+}
+
+// Rate returns the fraction of the transactions traced.
+func (s *TraceSampler) Rate() float64 {
+	return s.rate
+}
+
+// sample returns the tracer of the next transaction executed, or nil if the
+// transaction is not sampled.
+func (s *TraceSampler) sample() *samplingTracer {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.rand.Float64() >= s.rate {
+		return nil
+	}
+	return &samplingTracer{sampler: s}
+}
+
+// record adds the outcome of a sampled transaction to the statistics. Plain
+// transfers, executing no code, are only counted in the totals.
+func (s *TraceSampler) record(to common.Address, gas uint64, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	contract := new(SampledContract)
+	if gas > 0 || err != nil {
+		if contract = s.contracts[to]; contract == nil {
+			if len(s.contracts) >= maxSampledContracts {
+				s.prune()
+			}
+			contract = &SampledContract{Address: to}
+			s.contracts[to] = contract
+		}
+	}
+	s.txs++
+	contract.Transactions++
+	contract.Gas += gas
+
+	sampledTxMeter.Mark(1)
+	sampledGasHistogram.Update(int64(gas))
+	sampledContractGauge.Update(int64(len(s.contracts)))
+	switch {
+	case errors.Is(err, vm.ErrExecutionReverted):
+		s.reverts++
+		contract.Reverts++
+		sampledRevertMeter.Mark(1)
+	case err != nil:
+		s.failures++
+		contract.Failures++
+		sampledFailureMeter.Mark(1)
+	}
+}
+
+// prune forgets the half of the contracts that used the least gas.
+func (s *TraceSampler) prune() {
+	contracts := s.sorted()
+	for _, contract := range contracts[len(contracts)/2:] {
+		delete(s.contracts, contract.Address)
+	}
+}
+
+// sorted returns the contracts sorted by gas used, descending.
+func (s *TraceSampler) sorted() []*SampledContract {
+	contracts := make([]*SampledContract, 0, len(s.contracts))
+	for _, contract := range s.contracts {
+		contracts = append(contracts, contract)
+	}
+	sort.Slice(contracts, func(i, j int) bool {
+		if contracts[i].Gas != contracts[j].Gas {
+			return contracts[i].Gas > contracts[j].Gas
+		}
+		return contracts[i].Transactions > contracts[j].Transactions
+	})
+	return contracts
+}
+
+// Stats returns the statistics of the transactions sampled so far, with the
+// given number of contracts that used the most gas.
+func (s *TraceSampler) Stats(top int) *TraceSamplingStats {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	stats := &TraceSamplingStats{
+		Since:        s.since,
+		Rate:         s.rate,
+		Transactions: s.txs,
+		Reverts:      s.reverts,
+		Failures:     s.failures,
+		Contracts:    []*SampledContract{},
+	}
+	if s.txs > 0 {
+		stats.RevertRate = float64(s.reverts) / float64(s.txs)
+	}
+	for i, contract := range s.sorted() {
+		if i == top {
+			break
+		}
+		entry := *contract
+		stats.Contracts = append(stats.Contracts, &entry)
+	}
+	return stats
+}
+
+// Reset clears the statistics of the transactions sampled so far.
+func (s *TraceSampler) Reset() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.since = time.Now()
+	s.txs, s.reverts, s.failures = 0, 0, 0
+	s.contracts = make(map[common.Address]*SampledContract)
+	sampledContractGauge.Update(0)
+}
+
+// samplingTracer is the tracer of a sampled transaction. It only looks at the
+// outermost call frame, leaving the execution of the opcodes untouched.
+type samplingTracer struct {
+	sampler *TraceSampler
+	to      common.Address
+}
+
+func (t *samplingTracer) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	t.to = to
+	return nil
+}
+
+func (t *samplingTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, rStack *vm.ReturnStack, rData []byte, contract *vm.Contract, depth int, err error) error {
+	return nil
+}
+
+func (t *samplingTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, rStack *vm.ReturnStack, contract *vm.Contract, depth int, err error) error {
+	return nil
+}
+
+func (t *samplingTracer) CaptureEnd(output []byte, gasUsed uint64, _ time.Duration, err error) error {
+	t.sampler.record(t.to, gasUsed, err)
+	return nil
+}
@@ -0,0 +1,91 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/celo-org/celo-blockchain/common"
+	mockEngine "github.com/celo-org/celo-blockchain/consensus/consensustest"
+	"github.com/celo-org/celo-blockchain/core/rawdb"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/core/vm"
+	"github.com/celo-org/celo-blockchain/crypto"
+	"github.com/celo-org/celo-blockchain/params"
+)
+
+// Tests that the sampled transactions of imported blocks are aggregated by
+// contract, and plain transfers only counted in the totals.
+func TestTraceSampler(t *testing.T) {
+	var (
+		key, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr     = crypto.PubkeyToAddress(key.PublicKey)
+		reverter = common.HexToAddress("0x1000")
+		storer   = common.HexToAddress("0x2000")
+		db       = rawdb.NewMemoryDatabase()
+		gspec    = &Genesis{
+			Config: params.IstanbulTestChainConfig,
+			Alloc: GenesisAlloc{
+				addr: {Balance: big.NewInt(1000000)},
+				// PUSH1 0 PUSH1 0 REVERT
+				reverter: {Balance: new(big.Int), Code: []byte{byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.REVERT)}},
+				// PUSH1 1 PUSH1 0 SSTORE
+				storer: {Balance: new(big.Int), Code: []byte{byte(vm.PUSH1), 1, byte(vm.PUSH1), 0, byte(vm.SSTORE)}},
+			},
+		}
+		genesis = gspec.MustCommit(db)
+		signer  = types.NewEIP155Signer(gspec.Config.ChainID)
+	)
+	chain, _ := GenerateChain(gspec.Config, genesis, mockEngine.NewFaker(), db, 1, func(i int, gen *BlockGen) {
+		for _, to := range []common.Address{reverter, storer, storer, common.HexToAddress("0x3000")} {
+			tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(addr), to, big.NewInt(0), 100000, nil, nil, nil, nil, nil), signer, key)
+			gen.AddTx(tx)
+		}
+	})
+	blockchain, _ := NewBlockChain(db, nil, gspec.Config, mockEngine.NewFaker(), vm.Config{}, nil, nil)
+	defer blockchain.Stop()
+
+	sampler := NewTraceSampler(1)
+	blockchain.SetTraceSampler(sampler)
+	if i, err := blockchain.InsertChain(chain); err != nil {
+		t.Fatalf("failed to insert chain[%d]: %v", i, err)
+	}
+	stats := sampler.Stats(10)
+	if stats.Transactions != 4 || stats.Reverts != 1 || stats.Failures != 0 {
+		t.Fatalf("totals mismatch: have %d txs, %d reverts and %d failures, want 4, 1 and 0", stats.Transactions, stats.Reverts, stats.Failures)
+	}
+	if stats.RevertRate != 0.25 {
+		t.Errorf("revert rate mismatch: have %v, want 0.25", stats.RevertRate)
+	}
+	if len(stats.Contracts) != 2 {
+		t.Fatalf("contract count mismatch: have %d, want 2", len(stats.Contracts))
+	}
+	if top := stats.Contracts[0]; top.Address != storer || top.Transactions != 2 || top.Reverts != 0 || top.Gas == 0 {
+		t.Errorf("top contract mismatch: %+v", top)
+	}
+	if last := stats.Contracts[1]; last.Address != reverter || last.Transactions != 1 || last.Reverts != 1 {
+		t.Errorf("reverting contract mismatch: %+v", last)
+	}
+	if stats := sampler.Stats(1); len(stats.Contracts) != 1 {
+		t.Errorf("limited contract count mismatch: have %d, want 1", len(stats.Contracts))
+	}
+	sampler.Reset()
+	if stats := sampler.Stats(10); stats.Transactions != 0 || len(stats.Contracts) != 0 {
+		t.Errorf("statistics not reset: %+v", stats)
+	}
+}
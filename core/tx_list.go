@@ -24,6 +24,7 @@ import (
 	"sync/atomic"
 
 	"github.com/celo-org/celo-blockchain/common"
+	cmath "github.com/celo-org/celo-blockchain/common/math"
 	"github.com/celo-org/celo-blockchain/core/types"
 	"github.com/celo-org/celo-blockchain/log"
 )
@@ -193,13 +194,19 @@ func (m *txSortedMap) Remove(nonce uint64) bool {
 // prevent getting into and invalid state. This is not something that should ever
 // happen but better to be self correcting than failing!
 func (m *txSortedMap) Ready(start uint64) types.Transactions {
+	return m.ReadyWhile(start, func(*types.Transaction) bool { return true })
+}
+
+// ReadyWhile is like Ready, but stops at the first transaction not satisfying
+// the given condition.
+func (m *txSortedMap) ReadyWhile(start uint64, ok func(*types.Transaction) bool) types.Transactions {
 	// Short circuit if no transactions are available
 	if m.index.Len() == 0 || (*m.index)[0] > start {
 		return nil
 	}
 	// Otherwise start accumulating incremental transactions
 	var ready types.Transactions
-	for next := (*m.index)[0]; m.index.Len() > 0 && (*m.index)[0] == next; next++ {
+	for next := (*m.index)[0]; m.index.Len() > 0 && (*m.index)[0] == next && ok(m.items[next]); next++ {
 		ready = append(ready, m.items[next])
 		delete(m.items, next)
 		heap.Pop(m.index)
@@ -257,6 +264,7 @@ type txList struct {
 	nativegaspricefloor *big.Int                    // Lowest gas price minimum in the native currency
 	gaspricefloors      map[common.Address]*big.Int // Lowest gas price minimum per currency (reset only if it is below the gpm)
 	gascap              uint64                      // Gas limit of the highest spending transaction (reset only if exceeds block limit)
+	underpriced         bool                        // Whether the last promotion stopped at a transaction under the gas price minimum
 
 	ctx *atomic.Value // transaction pool context
 }
@@ -340,25 +348,32 @@ func (l *txList) Add(tx *types.Transaction, priceBump uint64) (bool, *types.Tran
 	// Otherwise overwrite the old transaction with the current one
 	// caps can only increase and floors can only decrease in this function
 	l.txs.Put(tx)
-	if feeCurrency := tx.FeeCurrency(); feeCurrency == nil {
+	if tx.FeePayer() != nil {
+		// The fees of sponsored transactions are not paid by the sender
+		if value := tx.Value(); l.nativecostcap.Cmp(value) < 0 {
+			l.nativecostcap = value
+		}
+	} else if feeCurrency := tx.FeeCurrency(); feeCurrency == nil {
 		if cost := tx.Cost(); l.nativecostcap.Cmp(cost) < 0 {
 			l.nativecostcap = cost
 		}
-		if gasPrice := tx.GasPrice(); l.nativegaspricefloor == nil || l.nativegaspricefloor.Cmp(gasPrice) > 0 {
-			l.nativegaspricefloor = gasPrice
-		}
 	} else {
 		fee := tx.Fee()
 		if oldFee, ok := l.feecaps[*feeCurrency]; !ok || oldFee.Cmp(fee) < 0 {
 			l.feecaps[*feeCurrency] = fee
 		}
-		if gasFloor, ok := l.gaspricefloors[*feeCurrency]; !ok || gasFloor.Cmp(tx.GasPrice()) > 0 {
-			l.gaspricefloors[*feeCurrency] = tx.GasPrice()
-		}
 		if value := tx.Value(); l.nativecostcap.Cmp(value) < 0 {
 			l.nativecostcap = value
 		}
 	}
+	// The gas price floors cover sponsored transactions as well
+	if feeCurrency := tx.FeeCurrency(); feeCurrency == nil {
+		if gasPrice := tx.GasPrice(); l.nativegaspricefloor == nil || l.nativegaspricefloor.Cmp(gasPrice) > 0 {
+			l.nativegaspricefloor = gasPrice
+		}
+	} else if gasFloor, ok := l.gaspricefloors[*feeCurrency]; !ok || gasFloor.Cmp(tx.GasPrice()) > 0 {
+		l.gaspricefloors[*feeCurrency] = tx.GasPrice()
+	}
 	if gas := tx.Gas(); l.gascap < gas {
 		l.gascap = gas
 	}
@@ -409,7 +424,10 @@ func (l *txList) Filter(nativeCostLimit *big.Int, feeLimits map[common.Address]*
 
 	// Filter out all the transactions above the account's funds
 	removed := l.txs.Filter(func(tx *types.Transaction) bool {
-		if feeCurrency := tx.FeeCurrency(); feeCurrency == nil {
+		if tx.FeePayer() != nil {
+			// The fee payer balance is checked separately, see dropUnpayableSponsored
+			return tx.Value().Cmp(nativeCostLimit) > 0 || tx.Gas() > gasLimit
+		} else if feeCurrency := tx.FeeCurrency(); feeCurrency == nil {
 			log.Trace("Transaction Filter", "hash", tx.Hash(), "Fee currency", tx.FeeCurrency(), "Cost", tx.Cost(), "Cost Limit", nativeCostLimit, "Gas", tx.Gas(), "Gas Limit", gasLimit)
 			return tx.Cost().Cmp(nativeCostLimit) > 0 || tx.Gas() > gasLimit
 		} else {
@@ -479,6 +497,54 @@ func (l *txList) FilterOnGasLimit(gasLimit uint64) (types.Transactions, types.Tr
 	return removed, invalids
 }
 
+// FilterUnderpriced removes all transactions from the list with a gas price
+// lower than the gas price minimum of their fee currency. Every removed
+// transaction is returned for any post-removal maintenance. Strict-mode
+// invalidated transactions are also returned.
+//
+// This method uses the cached gas price floors to quickly decide if there's even
+// a point in comparing the gas prices, raising the floors up to the minimums once
+// the transactions are filtered.
+func (l *txList) FilterUnderpriced(minimum func(feeCurrency *common.Address) *big.Int) (types.Transactions, types.Transactions) {
+	// We can bail if the floors >= the gas price minimums
+	canBail := l.nativegaspricefloor == nil || l.nativegaspricefloor.Cmp(minimum(nil)) >= 0
+	for feeCurrency, floor := range l.gaspricefloors {
+		feeCurrency := feeCurrency
+		if floor.Cmp(minimum(&feeCurrency)) < 0 {
+			canBail = false
+		}
+	}
+	if canBail {
+		return nil, nil
+	}
+	removed := l.txs.Filter(func(tx *types.Transaction) bool {
+		return tx.GasPrice().Cmp(minimum(tx.FeeCurrency())) < 0
+	})
+	// All the remaining transactions are priced at the minimums at least
+	if l.nativegaspricefloor != nil {
+		l.nativegaspricefloor = cmath.BigMax(l.nativegaspricefloor, minimum(nil))
+	}
+	for feeCurrency, floor := range l.gaspricefloors {
+		feeCurrency := feeCurrency
+		l.gaspricefloors[feeCurrency] = cmath.BigMax(floor, minimum(&feeCurrency))
+	}
+	if len(removed) == 0 {
+		return nil, nil
+	}
+	// If the list was strict, filter anything above the lowest nonce
+	var invalids types.Transactions
+	if l.strict {
+		lowest := uint64(math.MaxUint64)
+		for _, tx := range removed {
+			if nonce := tx.Nonce(); lowest > nonce {
+				lowest = nonce
+			}
+		}
+		invalids = l.txs.Filter(func(tx *types.Transaction) bool { return tx.Nonce() > lowest })
+	}
+	return removed, invalids
+}
+
 // Cap places a hard limit on the number of items, returning all transactions
 // exceeding that limit.
 func (l *txList) Cap(threshold int) types.Transactions {
@@ -512,6 +578,19 @@ func (l *txList) Ready(start uint64) types.Transactions {
 	return l.txs.Ready(start)
 }
 
+// ReadyPriced is like Ready, but stops at the first transaction with a gas price
+// lower than the gas price minimum of its fee currency, which stays in the list.
+func (l *txList) ReadyPriced(start uint64, minimum func(feeCurrency *common.Address) *big.Int) types.Transactions {
+	l.underpriced = false
+	return l.txs.ReadyWhile(start, func(tx *types.Transaction) bool {
+		if tx.GasPrice().Cmp(minimum(tx.FeeCurrency())) < 0 {
+			l.underpriced = true
+			return false
+		}
+		return true
+	})
+}
+
 // Len returns the length of the transaction list.
 func (l *txList) Len() int {
 	return l.txs.Len()
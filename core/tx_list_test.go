@@ -17,9 +17,11 @@
 package core
 
 import (
+	"math/big"
 	"math/rand"
 	"testing"
 
+	"github.com/celo-org/celo-blockchain/common"
 	"github.com/celo-org/celo-blockchain/core/types"
 	"github.com/celo-org/celo-blockchain/crypto"
 )
@@ -49,3 +51,47 @@ func TestStrictTxListAdd(t *testing.T) {
 		}
 	}
 }
+
+// Tests that transactions under the gas price minimum are filtered out of strict
+// lists along with the ones they invalidate, and held back on promotion.
+func TestTxListGasPriceMinimum(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	txs := types.Transactions{
+		pricedTransaction(0, 0, big.NewInt(10), key),
+		pricedTransaction(1, 0, big.NewInt(2), key),
+		pricedTransaction(2, 0, big.NewInt(10), key),
+	}
+	calls := 0
+	minimum := func(*common.Address) *big.Int {
+		calls++
+		return big.NewInt(5)
+	}
+
+	list := newTxList(true, nil)
+	for _, tx := range txs {
+		list.Add(tx, DefaultTxPoolConfig.PriceBump)
+	}
+	removed, invalids := list.FilterUnderpriced(minimum)
+	if len(removed) != 1 || removed[0] != txs[1] || len(invalids) != 1 || invalids[0] != txs[2] {
+		t.Fatalf("filtered transactions mismatch: removed %v, invalidated %v", removed, invalids)
+	}
+	if list.Len() != 1 {
+		t.Fatalf("remaining transaction count mismatch: have %d, want 1", list.Len())
+	}
+	// The floors were raised to the minimum, the remaining transactions are not compared again
+	calls = 0
+	if removed, invalids = list.FilterUnderpriced(minimum); removed != nil || invalids != nil || calls != 1 {
+		t.Errorf("floors not raised: removed %v, invalidated %v, %d minimum lookups", removed, invalids, calls)
+	}
+
+	queue := newTxList(false, nil)
+	for _, tx := range txs {
+		queue.Add(tx, DefaultTxPoolConfig.PriceBump)
+	}
+	if ready := queue.ReadyPriced(0, minimum); len(ready) != 1 || ready[0] != txs[0] || !queue.underpriced {
+		t.Fatalf("ready transactions mismatch: have %v, underpriced %v", ready, queue.underpriced)
+	}
+	if ready := queue.ReadyPriced(1, func(*common.Address) *big.Int { return common.Big1 }); len(ready) != 2 || queue.underpriced {
+		t.Fatalf("ready transactions mismatch: have %v, underpriced %v", ready, queue.underpriced)
+	}
+}
@@ -18,7 +18,6 @@ package core
 
 import (
 	"errors"
-	"math"
 	"math/big"
 	"sort"
 	"strconv"
@@ -31,6 +30,7 @@ import (
 	"github.com/celo-org/celo-blockchain/consensus"
 	"github.com/celo-org/celo-blockchain/contracts/blockchain_parameters"
 	"github.com/celo-org/celo-blockchain/contracts/currency"
+	gpm "github.com/celo-org/celo-blockchain/contracts/gasprice_minimum"
 	"github.com/celo-org/celo-blockchain/core/state"
 	"github.com/celo-org/celo-blockchain/core/types"
 	"github.com/celo-org/celo-blockchain/core/vm"
@@ -89,6 +89,29 @@ var (
 	// ErrTransfersFrozen is returned if a transaction attempts to transfer between
 	// non-whitelisted addresses while transfers are frozen.
 	ErrTransfersFrozen = errors.New("transfers are currently frozen")
+
+	// ErrValidUntilPassed is returned if a transaction is submitted with a valid
+	// until block lower than the pending block.
+	ErrValidUntilPassed = errors.New("valid until block passed")
+
+	// ErrInvalidFeePayer is returned if a sponsored transaction contains an invalid
+	// fee payer signature.
+	ErrInvalidFeePayer = errors.New("invalid fee payer")
+
+	// ErrFeePayerSlotsExceeded is returned if the fee payer of a sponsored transaction
+	// already sponsors the maximum number of transactions permitted in the pool.
+	ErrFeePayerSlotsExceeded = errors.New("fee payer slots exceeded")
+)
+
+const (
+	// reorgReinjectDepth is the maximum number of blocks dropped by a reorg for
+	// their transactions to be reinjected into the pool.
+	reorgReinjectDepth = 64
+
+	// reorgMaxWalk is the maximum number of blocks the new head can be ahead of
+	// the old one for reorgs to be looked for, covering the batches of blocks
+	// imported during sync.
+	reorgMaxWalk = 4096
 )
 
 var (
@@ -102,6 +125,7 @@ var (
 	pendingReplaceMeter   = metrics.NewRegisteredMeter("txpool/pending/replace", nil)
 	pendingRateLimitMeter = metrics.NewRegisteredMeter("txpool/pending/ratelimit", nil) // Dropped due to rate limiting
 	pendingNofundsMeter   = metrics.NewRegisteredMeter("txpool/pending/nofunds", nil)   // Dropped due to out-of-funds
+	pendingGPMMeter       = metrics.NewRegisteredMeter("txpool/pending/gpm", nil)       // Demoted under the gas price minimum
 
 	// Metrics for the queued pool
 	queuedDiscardMeter   = metrics.NewRegisteredMeter("txpool/queued/discard", nil)
@@ -109,6 +133,10 @@ var (
 	queuedRateLimitMeter = metrics.NewRegisteredMeter("txpool/queued/ratelimit", nil) // Dropped due to rate limiting
 	queuedNofundsMeter   = metrics.NewRegisteredMeter("txpool/queued/nofunds", nil)   // Dropped due to out-of-funds
 	queuedEvictionMeter  = metrics.NewRegisteredMeter("txpool/queued/eviction", nil)  // Dropped due to lifetime
+	queuedGPMMeter       = metrics.NewRegisteredMeter("txpool/queued/gpm", nil)       // Promoted once over the gas price minimum again
+
+	// Metrics for transactions bounded by a valid until block
+	validUntilDropMeter = metrics.NewRegisteredMeter("txpool/validuntil/drop", nil) // Dropped past their valid until block
 
 	// General tx metrics
 	knownTxMeter       = metrics.NewRegisteredMeter("txpool/known", nil)
@@ -116,6 +144,12 @@ var (
 	invalidTxMeter     = metrics.NewRegisteredMeter("txpool/invalid", nil)
 	underpricedTxMeter = metrics.NewRegisteredMeter("txpool/underpriced", nil)
 
+	// Metrics for the transactions of the blocks dropped by reorgs
+	reorgReinjectMeter  = metrics.NewRegisteredMeter("txpool/reorg/reinject", nil) // Reinjected into the pool
+	reorgInvalidMeter   = metrics.NewRegisteredMeter("txpool/reorg/invalid", nil)  // Invalid on the new chain
+	reorgSkippedMeter   = metrics.NewRegisteredMeter("txpool/reorg/skipped", nil)  // Reorgs too deep for reinjection
+	reorgDepthHistogram = metrics.NewRegisteredHistogram("txpool/reorg/depth", nil, metrics.NewExpDecaySample(1028, 0.015))
+
 	pendingGauge = metrics.NewRegisteredGauge("txpool/pending", nil)
 	queuedGauge  = metrics.NewRegisteredGauge("txpool/queued", nil)
 	localGauge   = metrics.NewRegisteredGauge("txpool/local", nil)
@@ -174,6 +208,8 @@ type TxPoolConfig struct {
 	Journal   string           // Journal of local transactions to survive node restarts
 	Rejournal time.Duration    // Time interval to regenerate the local transaction journal
 
+	RemoteJournal string // Journal of remote transactions to survive node restarts (disabled if empty)
+
 	PriceLimit uint64 // Minimum gas price to enforce for acceptance into the pool
 	PriceBump  uint64 // Minimum price bump percentage to replace an already existing transaction (nonce)
 
@@ -182,6 +218,8 @@ type TxPoolConfig struct {
 	AccountQueue uint64 // Maximum number of non-executable transaction slots permitted per account
 	GlobalQueue  uint64 // Maximum number of non-executable transaction slots for all accounts
 
+	FeePayerSlots uint64 // Maximum number of transactions sponsored by a single fee payer
+
 	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
 }
 
@@ -199,6 +237,8 @@ var DefaultTxPoolConfig = TxPoolConfig{
 	AccountQueue: 64,
 	GlobalQueue:  1024,
 
+	FeePayerSlots: 16,
+
 	Lifetime: 3 * time.Hour,
 }
 
@@ -230,6 +270,10 @@ func (config *TxPoolConfig) sanitize() TxPoolConfig {
 		log.Warn("Sanitizing invalid txpool global queue", "provided", conf.GlobalQueue, "updated", DefaultTxPoolConfig.GlobalQueue)
 		conf.GlobalQueue = DefaultTxPoolConfig.GlobalQueue
 	}
+	if conf.FeePayerSlots < 1 {
+		log.Warn("Sanitizing invalid txpool fee payer slots", "provided", conf.FeePayerSlots, "updated", DefaultTxPoolConfig.FeePayerSlots)
+		conf.FeePayerSlots = DefaultTxPoolConfig.FeePayerSlots
+	}
 	if conf.Lifetime < 1 {
 		log.Warn("Sanitizing invalid txpool lifetime", "provided", conf.Lifetime, "updated", DefaultTxPoolConfig.Lifetime)
 		conf.Lifetime = DefaultTxPoolConfig.Lifetime
@@ -255,6 +299,7 @@ type TxPool struct {
 	chain       blockChain
 	gasPrice    *big.Int
 	txFeed      event.Feed
+	changeFeed  event.Feed
 	scope       event.SubscriptionScope
 	signer      types.Signer
 	mu          sync.RWMutex
@@ -269,8 +314,11 @@ type TxPool struct {
 	currentMaxGas   uint64         // Current gas limit for transaction caps
 	currentCtx      atomic.Value   // Current block context (holds a txPoolContext)
 
-	locals  *accountSet // Set of local transaction to exempt from eviction rules
-	journal *txJournal  // Journal of local transaction to back up to disk
+	gasPriceMinimums map[common.Address]*big.Int // Gas price minimums of the pending block per fee currency, fetched lazily
+
+	locals        *accountSet // Set of local transaction to exempt from eviction rules
+	journal       *txJournal  // Journal of local transaction to back up to disk
+	remoteJournal *txJournal  // Journal of remote transactions to back up to disk
 
 	pending map[common.Address]*txList   // All currently processable transactions
 	queue   map[common.Address]*txList   // Queued but non-processable transactions
@@ -278,6 +326,14 @@ type TxPool struct {
 	all     *txLookup                    // All transactions to allow lookups
 	priced  *txPricedList                // All transactions sorted by price. One heap per fee currency.
 
+	changes    []TxPoolChange           // Changes to the pool contents not yet sent
+	validUntil map[common.Hash]uint64   // Last block local transactions may be included in, if bounded
+	included   map[common.Hash]struct{} // Transactions included by the blocks of the last reset
+
+	sponsored map[common.Address]map[common.Hash]struct{} // Sponsored transactions per fee payer, see sponsoredLane
+
+	currencyMetrics map[string]*currencyMetrics // Composition gauges per fee currency label
+
 	chainHeadCh     chan ChainHeadEvent
 	chainHeadSub    event.Subscription
 	reqResetCh      chan *txpoolResetRequest
@@ -308,6 +364,8 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 		queue:           make(map[common.Address]*txList),
 		beats:           make(map[common.Address]time.Time),
 		all:             newTxLookup(),
+		validUntil:      make(map[common.Hash]uint64),
+		sponsored:       make(map[common.Address]map[common.Hash]struct{}),
 		chainHeadCh:     make(chan ChainHeadEvent, chainHeadChanSize),
 		reqResetCh:      make(chan *txpoolResetRequest),
 		reqPromoteCh:    make(chan *accountSet),
@@ -340,6 +398,20 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 			log.Warn("Failed to rotate transaction journal", "err", err)
 		}
 	}
+	// If remote journaling is enabled, load the previous pool contents from disk.
+	// The transactions are validated again, dropping those no longer valid.
+	if config.RemoteJournal != "" {
+		pool.remoteJournal = newTxJournal(config.RemoteJournal)
+
+		if err := pool.remoteJournal.load(func(txs []*types.Transaction) []error { return pool.addTxs(txs, false, true) }); err != nil {
+			log.Warn("Failed to load remote transaction journal", "err", err)
+		}
+		pool.mu.Lock()
+		if err := pool.remoteJournal.rotate(pool.remote()); err != nil {
+			log.Warn("Failed to rotate remote transaction journal", "err", err)
+		}
+		pool.mu.Unlock()
+	}
 
 	// Subscribe events from blockchain and start the main event loop.
 	pool.chainHeadSub = pool.chain.SubscribeChainHeadEvent(pool.chainHeadCh)
@@ -384,11 +456,19 @@ func (pool *TxPool) loop() {
 
 		// Handle stats reporting ticks
 		case <-report.C:
+			var currencies map[string]*currencyTxStats
 			pool.mu.RLock()
 			pending, queued := pool.stats()
 			stales := pool.priced.stales
+			if metrics.Enabled {
+				currencies = pool.currencyStats()
+			}
 			pool.mu.RUnlock()
 
+			if currencies != nil {
+				pool.updateCurrencyMetrics(currencies)
+			}
+
 			if pending != prevPending || queued != prevQueued || stales != prevStales {
 				log.Debug("Transaction pool status report", "executable", pending, "queued", queued, "stales", stales)
 				prevPending, prevQueued, prevStales = pending, queued, stales
@@ -406,12 +486,14 @@ func (pool *TxPool) loop() {
 				if time.Since(pool.beats[addr]) > pool.config.Lifetime {
 					list := pool.queue[addr].Flatten()
 					for _, tx := range list {
-						pool.removeTx(tx.Hash(), true)
+						pool.removeTx(tx.Hash(), true, TxPoolRemoveExpired)
 					}
 					queuedEvictionMeter.Mark(int64(len(list)))
 				}
 			}
+			changes := pool.takeChanges()
 			pool.mu.Unlock()
+			pool.sendChanges(changes)
 
 		// Handle local transaction journal rotation
 		case <-journal.C:
@@ -422,6 +504,13 @@ func (pool *TxPool) loop() {
 				}
 				pool.mu.Unlock()
 			}
+			if pool.remoteJournal != nil {
+				pool.mu.Lock()
+				if err := pool.remoteJournal.rotate(pool.remote()); err != nil {
+					log.Warn("Failed to rotate remote tx journal", "err", err)
+				}
+				pool.mu.Unlock()
+			}
 		}
 	}
 }
@@ -438,6 +527,16 @@ func (pool *TxPool) Stop() {
 	if pool.journal != nil {
 		pool.journal.close()
 	}
+	// Remote transactions are not journaled as they arrive, store the final
+	// contents of the pool.
+	if pool.remoteJournal != nil {
+		pool.mu.Lock()
+		if err := pool.remoteJournal.rotate(pool.remote()); err != nil {
+			log.Warn("Failed to rotate remote tx journal", "err", err)
+		}
+		pool.mu.Unlock()
+		pool.remoteJournal.close()
+	}
 	log.Info("Transaction pool stopped")
 }
 
@@ -459,12 +558,14 @@ func (pool *TxPool) GasPrice() *big.Int {
 // new transaction, and drops all transactions below this threshold.
 func (pool *TxPool) SetGasPrice(price *big.Int) {
 	pool.mu.Lock()
-	defer pool.mu.Unlock()
-
 	pool.gasPrice = price
 	for _, tx := range pool.priced.Cap(price, pool.locals) {
-		pool.removeTx(tx.Hash(), false)
+		pool.removeTx(tx.Hash(), false, TxPoolRemovePricedOut)
 	}
+	changes := pool.takeChanges()
+	pool.mu.Unlock()
+
+	pool.sendChanges(changes)
 	log.Info("Transaction pool price threshold updated", "price", price)
 }
 
@@ -482,7 +583,7 @@ func (pool *TxPool) setGasLimit(gasLimit uint64) {
 	for _, list := range pool.queue {
 		rm, _ := list.FilterOnGasLimit(gasLimit)
 		for _, tx := range rm {
-			pool.removeTx(tx.Hash(), false)
+			pool.removeTx(tx.Hash(), false, TxPoolRemoveUnpayable)
 		}
 	}
 }
@@ -497,7 +598,7 @@ func (pool *TxPool) handleDonutActivation() {
 		return true
 	})
 	for hash := range toRemove {
-		pool.removeTx(hash, true)
+		pool.removeTx(hash, true, TxPoolRemoveUnprotected)
 	}
 }
 
@@ -572,16 +673,48 @@ func (pool *TxPool) Locals() []common.Address {
 	return pool.locals.flatten()
 }
 
-// local retrieves all currently known local transactions, grouped by origin
-// account and sorted by nonce. The returned transaction set is a copy and can be
-// freely modified by calling code.
+// local retrieves all currently known local transactions to journal, grouped by
+// origin account and sorted by nonce. Transactions bounded by a valid until block
+// are left out, as the bound would be lost across restarts. The returned
+// transaction set is a copy and can be freely modified by calling code.
 func (pool *TxPool) local() map[common.Address]types.Transactions {
 	txs := make(map[common.Address]types.Transactions)
 	for addr := range pool.locals.accounts {
 		if pending := pool.pending[addr]; pending != nil {
-			txs[addr] = append(txs[addr], pending.Flatten()...)
+			txs[addr] = append(txs[addr], pool.unbounded(pending.Flatten())...)
 		}
 		if queued := pool.queue[addr]; queued != nil {
+			txs[addr] = append(txs[addr], pool.unbounded(queued.Flatten())...)
+		}
+	}
+	return txs
+}
+
+// unbounded filters out the transactions bounded by a valid until block.
+func (pool *TxPool) unbounded(txs types.Transactions) types.Transactions {
+	if len(pool.validUntil) == 0 {
+		return txs
+	}
+	kept := txs[:0]
+	for _, tx := range txs {
+		if _, ok := pool.validUntil[tx.Hash()]; !ok {
+			kept = append(kept, tx)
+		}
+	}
+	return kept
+}
+
+// remote retrieves all currently known remote transactions, grouped by origin
+// account and sorted by nonce. The caller must hold pool.mu.
+func (pool *TxPool) remote() map[common.Address]types.Transactions {
+	txs := make(map[common.Address]types.Transactions)
+	for addr, pending := range pool.pending {
+		if !pool.locals.contains(addr) {
+			txs[addr] = append(txs[addr], pending.Flatten()...)
+		}
+	}
+	for addr, queued := range pool.queue {
+		if !pool.locals.contains(addr) {
 			txs[addr] = append(txs[addr], queued.Flatten()...)
 		}
 	}
@@ -624,6 +757,15 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	if err != nil {
 		return ErrInvalidSender
 	}
+	// Sponsored transactions must be signed by their fee payer as well
+	if tx.FeePayer() != nil {
+		if !pool.eHardfork {
+			return ErrSponsoredTransactionsNotSupported
+		}
+		if _, err := types.FeePayerSender(tx); err != nil {
+			return ErrInvalidFeePayer
+		}
+	}
 
 	isWhitelisted := pool.ctx().IsWhitelisted(tx.FeeCurrency())
 	if !isWhitelisted {
@@ -644,8 +786,14 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	if err != nil {
 		return err
 	}
+	if tx.FeePayer() != nil {
+		if err := pool.validateSponsored(tx, from); err != nil {
+			return err
+		}
+	}
 
-	intrGas, err := IntrinsicGas(tx.Data(), tx.To() == nil, tx.FeeCurrency(), pool.ctx().GetIntrinsicGasForAlternativeFeeCurrency(), pool.istanbul)
+	ctx := pool.ctx()
+	intrGas, err := IntrinsicGas(tx.Data(), tx.To() == nil, tx.FeeCurrency(), tx.GatewayFeeRecipient(), ctx.GetIntrinsicGasForAlternativeFeeCurrency(), ctx.GetIntrinsicGasSchedule(), pool.istanbul)
 	if err != nil {
 		log.Debug("validateTx gas less than intrinsic gas", "intrGas", intrGas, "err", err)
 		return err
@@ -692,7 +840,7 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err e
 		for _, tx := range drop {
 			log.Debug("Discarding freshly underpriced transaction", "hash", tx.Hash(), "price", tx.GasPrice())
 			underpricedTxMeter.Mark(1)
-			pool.removeTx(tx.Hash(), false)
+			pool.removeTx(tx.Hash(), false, TxPoolRemovePricedOut)
 		}
 	}
 	// Try to replace an existing transaction in the pending pool
@@ -709,9 +857,13 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err e
 			pool.all.Remove(old.Hash())
 			pool.priced.Removed(1)
 			pendingReplaceMeter.Mark(1)
+			pool.recordReplace(tx, old)
+		} else {
+			pool.recordAdd(tx)
 		}
 		pool.all.Add(tx)
 		pool.priced.Put(tx)
+		pool.trackSponsored(tx)
 		pool.journalTx(from, tx)
 		pool.queueTxEvent(tx)
 		log.Trace("Pooled new executable transaction", "hash", hash, "from", from, "to", tx.To())
@@ -725,6 +877,11 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err e
 	if err != nil {
 		return false, err
 	}
+	if !replaced {
+		pool.recordAdd(tx)
+	}
+	pool.trackSponsored(tx)
+
 	// Mark local addresses and journal local transactions
 	if local {
 		if !pool.locals.contains(from) {
@@ -761,6 +918,7 @@ func (pool *TxPool) enqueueTx(hash common.Hash, tx *types.Transaction) (bool, er
 		pool.all.Remove(old.Hash())
 		pool.priced.Removed(1)
 		queuedReplaceMeter.Mark(1)
+		pool.recordReplace(tx, old)
 	} else {
 		// Nothing was replaced, bump the queued counter
 		queuedGauge.Inc(1)
@@ -783,10 +941,13 @@ func (pool *TxPool) enqueueTx(hash common.Hash, tx *types.Transaction) (bool, er
 // journalTx adds the specified transaction to the local disk journal if it is
 // deemed to have been sent from a local account.
 func (pool *TxPool) journalTx(from common.Address, tx *types.Transaction) {
-	// Only journal if it's enabled and the transaction is local
+	// Only journal if it's enabled and the transaction is local and unbounded
 	if pool.journal == nil || !pool.locals.contains(from) {
 		return
 	}
+	if _, ok := pool.validUntil[tx.Hash()]; ok {
+		return
+	}
 	if err := pool.journal.insert(tx); err != nil {
 		log.Warn("Failed to journal local transaction", "err", err)
 	}
@@ -809,6 +970,7 @@ func (pool *TxPool) promoteTx(addr common.Address, hash common.Hash, tx *types.T
 		pool.all.Remove(hash)
 		pool.priced.Removed(1)
 		pendingDiscardMeter.Mark(1)
+		pool.recordRemove(TxPoolRemovePricedOut, tx)
 		return false
 	}
 	// Otherwise discard any previous transaction and mark this
@@ -816,6 +978,7 @@ func (pool *TxPool) promoteTx(addr common.Address, hash common.Hash, tx *types.T
 		pool.all.Remove(old.Hash())
 		pool.priced.Removed(1)
 		pendingReplaceMeter.Mark(1)
+		pool.recordReplace(tx, old)
 	} else {
 		// Nothing was replaced, bump the pending counter
 		pendingGauge.Inc(1)
@@ -849,6 +1012,34 @@ func (pool *TxPool) AddLocal(tx *types.Transaction) error {
 	return errs[0]
 }
 
+// AddLocalUntil enqueues a single local transaction into the pool like AddLocal,
+// dropping it if it is not included by the given block. It will not be journaled
+// nor propagated past that block.
+func (pool *TxPool) AddLocalUntil(tx *types.Transaction, validUntil uint64) error {
+	hash := tx.Hash()
+
+	pool.mu.Lock()
+	if pool.all.Get(hash) != nil {
+		pool.mu.Unlock()
+		knownTxMeter.Mark(1)
+		return ErrAlreadyKnown
+	}
+	if next := pool.chain.CurrentBlock().NumberU64() + 1; validUntil < next {
+		pool.mu.Unlock()
+		return ErrValidUntilPassed
+	}
+	pool.validUntil[hash] = validUntil
+	pool.mu.Unlock()
+
+	if err := pool.AddLocal(tx); err != nil {
+		pool.mu.Lock()
+		delete(pool.validUntil, hash)
+		pool.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
 // AddRemotes enqueues a batch of transactions into the pool if they are valid. If the
 // senders are not among the locally tracked ones, full pricing constraints will apply.
 //
@@ -980,7 +1171,7 @@ func (pool *TxPool) Has(hash common.Hash) bool {
 
 // removeTx removes a single transaction from the queue, moving all subsequent
 // transactions back to the future queue.
-func (pool *TxPool) removeTx(hash common.Hash, outofbound bool) {
+func (pool *TxPool) removeTx(hash common.Hash, outofbound bool, reason TxPoolRemoveReason) {
 	// Fetch the transaction we wish to delete
 	tx := pool.all.Get(hash)
 	if tx == nil {
@@ -990,6 +1181,7 @@ func (pool *TxPool) removeTx(hash common.Hash, outofbound bool) {
 
 	// Remove it from the list of known transactions
 	pool.all.Remove(hash)
+	pool.recordRemove(reason, tx)
 	if outofbound {
 		pool.priced.Removed(1)
 	}
@@ -1176,6 +1368,7 @@ func (pool *TxPool) runReorg(done chan struct{}, reset *txpoolResetRequest, dirt
 		highestPending := list.LastElement()
 		pool.pendingNonces.set(addr, highestPending.Nonce()+1)
 	}
+	changes := pool.takeChanges()
 	pool.mu.Unlock()
 
 	// Notify subsystems for newly added transactions
@@ -1193,77 +1386,121 @@ func (pool *TxPool) runReorg(done chan struct{}, reset *txpoolResetRequest, dirt
 		}
 		pool.txFeed.Send(NewTxsEvent{txs})
 	}
+	pool.sendChanges(changes)
+}
+
+// reorgTransactions returns the transactions of the blocks dropped from the old
+// chain which the new one does not include, along with the transactions of the
+// new chain since the common ancestor. Only the number of dropped blocks is
+// bounded by reorgReinjectDepth, so that shallow reorgs are also handled when
+// the new head is far ahead of the old one, as happens when importing batches
+// of blocks during sync. It returns false if the pool should not be reset.
+func (pool *TxPool) reorgTransactions(oldHead, newHead *types.Header) (reinject, included types.Transactions, ok bool) {
+	oldNum, newNum := oldHead.Number.Uint64(), newHead.Number.Uint64()
+	if newNum > oldNum+reorgMaxWalk {
+		// The new head is too far ahead (will happen during fast sync)
+		log.Debug("Skipping distant transaction reorg", "old", oldNum, "new", newNum)
+		return nil, nil, true
+	}
+	if oldNum > newNum+reorgReinjectDepth {
+		log.Debug("Skipping deep transaction reorg", "depth", oldNum-newNum)
+		reorgSkippedMeter.Mark(1)
+		return nil, nil, true
+	}
+	rem := pool.chain.GetBlock(oldHead.Hash(), oldNum)
+	if rem == nil {
+		// This can happen if a setHead is performed, where we simply discard the old
+		// head from the chain.
+		// If that is the case, we don't have the lost transactions any more, and
+		// there's nothing to add
+		if newNum < oldNum {
+			// If the reorg ended up on a lower number, it's indicative of setHead being the cause
+			log.Debug("Skipping transaction reset caused by setHead",
+				"old", oldHead.Hash(), "oldnum", oldNum, "new", newHead.Hash(), "newnum", newNum)
+		} else {
+			// If we reorged to a same or higher number, then it's not a case of setHead
+			log.Warn("Transaction pool reset with missing oldhead",
+				"old", oldHead.Hash(), "oldnum", oldNum, "new", newHead.Hash(), "newnum", newNum)
+		}
+		return nil, nil, false
+	}
+	// Walk the headers of the new chain down to the old head first, which is
+	// usually its ancestor, before loading any block
+	var (
+		add       = newHead
+		segment   []*types.Header // Headers of the new chain since the common ancestor
+		discarded types.Transactions
+	)
+	for add.Number.Uint64() > oldNum {
+		segment = append(segment, add)
+		if add = pool.chain.GetHeader(add.ParentHash, add.Number.Uint64()-1); add == nil {
+			log.Error("Unrooted new chain seen by tx pool", "block", newHead.Number, "hash", newHead.Hash())
+			return nil, nil, false
+		}
+	}
+	if add.Hash() == oldHead.Hash() {
+		return nil, nil, true
+	}
+	// Some blocks were dropped, collect their transactions
+	for rem.NumberU64() > add.Number.Uint64() {
+		discarded = append(discarded, rem.Transactions()...)
+		if rem = pool.chain.GetBlock(rem.ParentHash(), rem.NumberU64()-1); rem == nil {
+			log.Error("Unrooted old chain seen by tx pool", "block", oldHead.Number, "hash", oldHead.Hash())
+			return nil, nil, false
+		}
+	}
+	for rem.Hash() != add.Hash() {
+		if oldNum-rem.NumberU64() >= reorgReinjectDepth {
+			log.Debug("Skipping deep transaction reorg", "depth", oldNum-rem.NumberU64())
+			reorgSkippedMeter.Mark(1)
+			return nil, nil, true
+		}
+		discarded = append(discarded, rem.Transactions()...)
+		if rem = pool.chain.GetBlock(rem.ParentHash(), rem.NumberU64()-1); rem == nil {
+			log.Error("Unrooted old chain seen by tx pool", "block", oldHead.Number, "hash", oldHead.Hash())
+			return nil, nil, false
+		}
+		segment = append(segment, add)
+		if add = pool.chain.GetHeader(add.ParentHash, add.Number.Uint64()-1); add == nil {
+			log.Error("Unrooted new chain seen by tx pool", "block", newHead.Number, "hash", newHead.Hash())
+			return nil, nil, false
+		}
+	}
+	for _, header := range segment {
+		block := pool.chain.GetBlock(header.Hash(), header.Number.Uint64())
+		if block == nil {
+			log.Error("Missing block of the new chain seen by tx pool", "block", header.Number, "hash", header.Hash())
+			return nil, nil, false
+		}
+		included = append(included, block.Transactions()...)
+	}
+	reorgDepthHistogram.Update(int64(oldNum - rem.NumberU64()))
+	return types.TxDifference(discarded, included), included, true
 }
 
 // reset retrieves the current state of the blockchain and ensures the content
 // of the transaction pool is valid with regard to the chain state.
 func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 	// If we're reorging an old state, reinject all dropped transactions
-	var reinject types.Transactions
+	var reinject, included types.Transactions
 
 	if oldHead != nil && oldHead.Hash() != newHead.ParentHash {
-		// If the reorg is too deep, avoid doing it (will happen during fast sync)
-		oldNum := oldHead.Number.Uint64()
-		newNum := newHead.Number.Uint64()
-
-		if depth := uint64(math.Abs(float64(oldNum) - float64(newNum))); depth > 64 {
-			log.Debug("Skipping deep transaction reorg", "depth", depth)
-		} else {
-			// Reorg seems shallow enough to pull in all transactions into memory
-			var discarded, included types.Transactions
-			var (
-				rem = pool.chain.GetBlock(oldHead.Hash(), oldHead.Number.Uint64())
-				add = pool.chain.GetBlock(newHead.Hash(), newHead.Number.Uint64())
-			)
-			if rem == nil {
-				// This can happen if a setHead is performed, where we simply discard the old
-				// head from the chain.
-				// If that is the case, we don't have the lost transactions any more, and
-				// there's nothing to add
-				if newNum < oldNum {
-					// If the reorg ended up on a lower number, it's indicative of setHead being the cause
-					log.Debug("Skipping transaction reset caused by setHead",
-						"old", oldHead.Hash(), "oldnum", oldNum, "new", newHead.Hash(), "newnum", newNum)
-				} else {
-					// If we reorged to a same or higher number, then it's not a case of setHead
-					log.Warn("Transaction pool reset with missing oldhead",
-						"old", oldHead.Hash(), "oldnum", oldNum, "new", newHead.Hash(), "newnum", newNum)
-				}
-				return
-			}
-			for rem.NumberU64() > add.NumberU64() {
-				discarded = append(discarded, rem.Transactions()...)
-				if rem = pool.chain.GetBlock(rem.ParentHash(), rem.NumberU64()-1); rem == nil {
-					log.Error("Unrooted old chain seen by tx pool", "block", oldHead.Number, "hash", oldHead.Hash())
-					return
-				}
-			}
-			for add.NumberU64() > rem.NumberU64() {
-				included = append(included, add.Transactions()...)
-				if add = pool.chain.GetBlock(add.ParentHash(), add.NumberU64()-1); add == nil {
-					log.Error("Unrooted new chain seen by tx pool", "block", newHead.Number, "hash", newHead.Hash())
-					return
-				}
-			}
-			for rem.Hash() != add.Hash() {
-				discarded = append(discarded, rem.Transactions()...)
-				if rem = pool.chain.GetBlock(rem.ParentHash(), rem.NumberU64()-1); rem == nil {
-					log.Error("Unrooted old chain seen by tx pool", "block", oldHead.Number, "hash", oldHead.Hash())
-					return
-				}
-				included = append(included, add.Transactions()...)
-				if add = pool.chain.GetBlock(add.ParentHash(), add.NumberU64()-1); add == nil {
-					log.Error("Unrooted new chain seen by tx pool", "block", newHead.Number, "hash", newHead.Hash())
-					return
-				}
-			}
-			reinject = types.TxDifference(discarded, included)
+		var ok bool
+		if reinject, included, ok = pool.reorgTransactions(oldHead, newHead); !ok {
+			return
 		}
 	}
 	// Initialize the internal state to the current head
 	if newHead == nil {
 		newHead = pool.chain.CurrentBlock().Header() // Special case during testing
 	}
+	// Track the transactions of the new head, to tell them apart from ones with reused nonces
+	if oldHead != nil && oldHead.Hash() == newHead.ParentHash {
+		if block := pool.chain.GetBlock(newHead.Hash(), newHead.Number.Uint64()); block != nil {
+			included = block.Transactions()
+		}
+	}
+	pool.included = includedSet(included)
 	statedb, err := pool.chain.StateAt(newHead.Root)
 	if err != nil {
 		log.Error("Failed to reset txpool state", "err", err)
@@ -1274,20 +1511,39 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 
 	pool.currentVMRunner = pool.chain.NewEVMRunner(newHead, statedb)
 	pool.currentMaxGas = blockchain_parameters.GetBlockGasLimitOrDefault(pool.currentVMRunner)
+	pool.gasPriceMinimums = make(map[common.Address]*big.Int)
+	// Transactions are validated against the rules of the next pending block
+	next := new(big.Int).Add(newHead.Number, big.NewInt(1))
 	// atomic store of the new txPoolContext
 	newCtx := txPoolContext{
-		NewBlockContext(pool.currentVMRunner),
+		NewBlockContext(pool.currentVMRunner, pool.chainconfig.IntrinsicGasSchedule(next)),
 		currency.NewManager(pool.currentVMRunner),
 	}
 	pool.currentCtx.Store(newCtx)
 
-	// Inject any transactions discarded due to reorgs
-	log.Debug("Reinjecting stale transactions", "count", len(reinject))
-	senderCacher.recover(pool.signer, reinject)
-	pool.addTxsLocked(reinject, false)
+	// Inject any transactions discarded due to reorgs, validating them against the
+	// new head like fresh ones, fee currency included
+	if len(reinject) > 0 {
+		senderCacher.recover(pool.signer, reinject)
+		errs, _ := pool.addTxsLocked(reinject, false)
+
+		var added, invalid int
+		for _, err := range errs {
+			switch err {
+			case nil:
+				added++
+			case ErrAlreadyKnown:
+			default:
+				invalid++
+			}
+		}
+		reorgReinjectMeter.Mark(int64(added))
+		reorgInvalidMeter.Mark(int64(invalid))
+		log.Debug("Reinjected stale transactions", "count", added, "invalid", invalid)
+	}
+	pool.dropValidUntil(next.Uint64())
 
 	// Update all fork indicator by next pending block number.
-	next := new(big.Int).Add(newHead.Number, big.NewInt(1))
 	pool.istanbul = pool.chainconfig.IsIstanbul(next)
 	wasDonut := pool.donut
 	pool.donut = pool.chainconfig.IsDonut(next)
@@ -1295,11 +1551,58 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 		pool.handleDonutActivation()
 	}
 	pool.eHardfork = pool.chainconfig.IsEHardfork(next)
+
+	pool.dropUnpayableSponsored()
+}
+
+// dropValidUntil removes the transactions which can no longer be included before
+// their valid until block, given the number of the pending block.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) dropValidUntil(pending uint64) {
+	var dropped int
+	for hash, validUntil := range pool.validUntil {
+		if pool.all.Get(hash) == nil {
+			// Included or dropped for other reasons
+			delete(pool.validUntil, hash)
+			continue
+		}
+		if validUntil < pending {
+			pool.removeTx(hash, true, TxPoolRemoveValidUntil)
+			delete(pool.validUntil, hash)
+			dropped++
+		}
+	}
+	if dropped > 0 {
+		validUntilDropMeter.Mark(int64(dropped))
+		log.Debug("Dropped transactions past their valid until block", "count", dropped, "pending", pending)
+	}
+}
+
+// gasPriceMinimum returns the gas price minimum of the pending block in the given
+// fee currency, as updated by the GasPriceMinimum contract at every block.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) gasPriceMinimum(feeCurrency *common.Address) *big.Int {
+	var key common.Address
+	if feeCurrency != nil {
+		key = *feeCurrency
+	}
+	if minimum, ok := pool.gasPriceMinimums[key]; ok {
+		return minimum
+	}
+	minimum, err := gpm.GetGasPriceMinimum(pool.currentVMRunner, feeCurrency)
+	if err != nil {
+		log.Debug("Failed to retrieve gas price minimum", "currency", feeCurrency, "err", err)
+	}
+	pool.gasPriceMinimums[key] = minimum
+	return minimum
 }
 
 // promoteExecutables moves transactions that have become processable from the
 // future queue to the set of pending transactions. During this process, all
-// invalidated transactions (low nonce, low balance) are deleted.
+// invalidated transactions (low nonce, low balance) are deleted. Transactions
+// under the gas price minimum are held back until it drops.
 func (pool *TxPool) promoteExecutables(accounts []common.Address) []*types.Transaction {
 	// Track the promoted transactions to broadcast them at once
 	var promoted []*types.Transaction
@@ -1316,6 +1619,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) []*types.Trans
 			hash := tx.Hash()
 			pool.all.Remove(hash)
 		}
+		pool.recordStale(forwards...)
 		log.Trace("Removed old queued transactions", "count", len(forwards))
 		// Get balances in each currency
 		balances := make(map[common.Address]*big.Int)
@@ -1330,11 +1634,16 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) []*types.Trans
 			hash := tx.Hash()
 			pool.all.Remove(hash)
 		}
+		pool.recordRemove(TxPoolRemoveUnpayable, drops...)
 		log.Trace("Removed unpayable queued transactions", "count", len(drops))
 		queuedNofundsMeter.Mark(int64(len(drops)))
 
 		// Gather all executable transactions and promote them
-		readies := list.Ready(pool.pendingNonces.get(addr))
+		underpriced := list.underpriced
+		readies := list.ReadyPriced(pool.pendingNonces.get(addr), pool.gasPriceMinimum)
+		if underpriced && len(readies) > 0 {
+			queuedGPMMeter.Mark(int64(len(readies)))
+		}
 		for _, tx := range readies {
 			hash := tx.Hash()
 			if pool.promoteTx(addr, hash, tx) {
@@ -1353,6 +1662,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) []*types.Trans
 				pool.all.Remove(hash)
 				log.Trace("Removed cap-exceeding queued transaction", "hash", hash)
 			}
+			pool.recordRemove(TxPoolRemoveCapped, caps...)
 			queuedRateLimitMeter.Mark(int64(len(caps)))
 		}
 		// Mark all the items dropped as removed
@@ -1419,6 +1729,7 @@ func (pool *TxPool) truncatePending() {
 						log.Trace("Removed fairness-exceeding pending transaction", "hash", hash)
 					}
 					pool.priced.Removed(len(caps))
+					pool.recordRemove(TxPoolRemoveCapped, caps...)
 					pendingGauge.Dec(int64(len(caps)))
 					if pool.locals.contains(offenders[i]) {
 						localGauge.Dec(int64(len(caps)))
@@ -1446,6 +1757,7 @@ func (pool *TxPool) truncatePending() {
 					log.Trace("Removed fairness-exceeding pending transaction", "hash", hash)
 				}
 				pool.priced.Removed(len(caps))
+				pool.recordRemove(TxPoolRemoveCapped, caps...)
 				pendingGauge.Dec(int64(len(caps)))
 				if pool.locals.contains(addr) {
 					localGauge.Dec(int64(len(caps)))
@@ -1486,7 +1798,7 @@ func (pool *TxPool) truncateQueue() {
 		// Drop all transactions if they are less than the overflow
 		if size := uint64(list.Len()); size <= drop {
 			for _, tx := range list.Flatten() {
-				pool.removeTx(tx.Hash(), true)
+				pool.removeTx(tx.Hash(), true, TxPoolRemoveCapped)
 			}
 			drop -= size
 			queuedRateLimitMeter.Mark(int64(size))
@@ -1495,7 +1807,7 @@ func (pool *TxPool) truncateQueue() {
 		// Otherwise drop only last few transactions
 		txs := list.Flatten()
 		for i := len(txs) - 1; i >= 0 && drop > 0; i-- {
-			pool.removeTx(txs[i].Hash(), true)
+			pool.removeTx(txs[i].Hash(), true, TxPoolRemoveCapped)
 			drop--
 			queuedRateLimitMeter.Mark(1)
 		}
@@ -1504,7 +1816,7 @@ func (pool *TxPool) truncateQueue() {
 
 // demoteUnexecutables removes invalid and processed transactions from the pools
 // executable/pending queue and any subsequent transactions that become unexecutable
-// are moved back into the future queue.
+// are moved back into the future queue, as are the ones under the gas price minimum.
 func (pool *TxPool) demoteUnexecutables() {
 	// Iterate over all accounts and demote any non-executable transactions
 	for addr, list := range pool.pending {
@@ -1517,6 +1829,7 @@ func (pool *TxPool) demoteUnexecutables() {
 			pool.all.Remove(hash)
 			log.Trace("Removed old pending transaction", "hash", hash)
 		}
+		pool.recordStale(olds...)
 		// Get balances in each currency
 		balances := make(map[common.Address]*big.Int)
 		allCurrencies := list.FeeCurrencies()
@@ -1531,9 +1844,16 @@ func (pool *TxPool) demoteUnexecutables() {
 			log.Trace("Removed unpayable pending transaction", "hash", hash)
 			pool.all.Remove(hash)
 		}
+		pool.recordRemove(TxPoolRemoveUnpayable, drops...)
 		pool.priced.Removed(len(olds) + len(drops))
 		pendingNofundsMeter.Mark(int64(len(drops)))
 
+		// Queue any transactions under the gas price minimum back, until it drops
+		underpriced, invalidated := list.FilterUnderpriced(pool.gasPriceMinimum)
+		pendingGPMMeter.Mark(int64(len(underpriced) + len(invalidated)))
+		invalids = append(invalids, underpriced...)
+		invalids = append(invalids, invalidated...)
+
 		for _, tx := range invalids {
 			hash := tx.Hash()
 			log.Trace("Demoting pending transaction", "hash", hash)
@@ -1561,7 +1881,24 @@ func (pool *TxPool) demoteUnexecutables() {
 }
 
 // ValidateTransactorBalanceCoversTx validates transactor has enough funds to cover transaction cost: V + GP * GL.
+// The fees of sponsored transactions are covered by their fee payer instead.
 func ValidateTransactorBalanceCoversTx(tx *types.Transaction, from common.Address, currentState *state.StateDB, currentVMRunner vm.EVMRunner, eHardfork bool) error {
+	if feePayer := tx.FeePayer(); feePayer != nil {
+		if currentState.GetBalance(from).Cmp(tx.Value()) < 0 {
+			log.Debug("validateTx insufficient funds", "balance", currentState.GetBalance(from).String())
+			return ErrInsufficientFunds
+		}
+		balance, err := feeBalanceOf(*feePayer, tx.FeeCurrency(), currentState, currentVMRunner)
+		if err != nil {
+			log.Debug("validateTx error in getting fee payer balance", "feeCurrency", tx.FeeCurrency(), "error", err)
+			return err
+		}
+		if balance.Cmp(tx.Fee()) < 0 {
+			log.Debug("validateTx insufficient fee payer funds", "feePayer", feePayer, "feeCurrency", tx.FeeCurrency(), "balance", balance)
+			return ErrInsufficientFunds
+		}
+		return nil
+	}
 	if tx.FeeCurrency() == nil && currentState.GetBalance(from).Cmp(tx.Cost()) < 0 {
 		log.Debug("Insufficient funds",
 			"from", from, "Transaction cost", tx.Cost(), "to", tx.To(),
@@ -1595,6 +1932,14 @@ func ValidateTransactorBalanceCoversTx(tx *types.Transaction, from common.Addres
 	return nil
 }
 
+// feeBalanceOf returns the balance of the account in the given fee currency.
+func feeBalanceOf(account common.Address, feeCurrency *common.Address, currentState *state.StateDB, currentVMRunner vm.EVMRunner) (*big.Int, error) {
+	if feeCurrency == nil {
+		return currentState.GetBalance(account), nil
+	}
+	return currency.GetBalanceOf(currentVMRunner, account, *feeCurrency)
+}
+
 // addressByHeartbeat is an account address tagged with its last activity timestamp.
 type addressByHeartbeat struct {
 	address   common.Address
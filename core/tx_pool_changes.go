@@ -0,0 +1,123 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/event"
+)
+
+// TxPoolChangeKind is the kind of a change to the transaction pool contents.
+type TxPoolChangeKind string
+
+const (
+	TxPoolAdd     TxPoolChangeKind = "add"     // A transaction entered the pool
+	TxPoolReplace TxPoolChangeKind = "replace" // A transaction replaced another one with the same nonce
+	TxPoolRemove  TxPoolChangeKind = "remove"  // A transaction left the pool
+)
+
+// TxPoolRemoveReason is the reason a transaction was removed from the pool.
+type TxPoolRemoveReason string
+
+const (
+	TxPoolRemoveIncluded     TxPoolRemoveReason = "included"     // Included in the chain
+	TxPoolRemoveInvalidNonce TxPoolRemoveReason = "invalidNonce" // Nonce used by another transaction included in the chain
+	TxPoolRemovePricedOut    TxPoolRemoveReason = "pricedOut"    // Evicted in favour of better priced transactions
+	TxPoolRemoveUnpayable    TxPoolRemoveReason = "unpayable"    // Balance too low or gas above the block gas limit
+	TxPoolRemoveCapped       TxPoolRemoveReason = "capped"       // Account or pool limits exceeded
+	TxPoolRemoveExpired      TxPoolRemoveReason = "expired"      // Queued for longer than the pool lifetime
+	TxPoolRemoveUnprotected  TxPoolRemoveReason = "unprotected"  // Not replay protected, which is required since Donut
+	TxPoolRemoveValidUntil   TxPoolRemoveReason = "validUntil"   // Not included by its valid until block
+)
+
+// TxPoolChange is a single change to the transaction pool contents.
+type TxPoolChange struct {
+	Kind     TxPoolChangeKind
+	Tx       *types.Transaction // Transaction added, removed or the replacement
+	Replaced *types.Transaction // Transaction replaced, for replacements
+	Reason   TxPoolRemoveReason // Reason of the removal, for removals
+}
+
+// SubscribeTxPoolChangeEvent registers a subscription of TxPoolChangeEvent and
+// starts sending event to the given channel.
+func (pool *TxPool) SubscribeTxPoolChangeEvent(ch chan<- TxPoolChangeEvent) event.Subscription {
+	return pool.scope.Track(pool.changeFeed.Subscribe(ch))
+}
+
+// recordAdd records a transaction entering the pool.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) recordAdd(tx *types.Transaction) {
+	pool.changes = append(pool.changes, TxPoolChange{Kind: TxPoolAdd, Tx: tx})
+}
+
+// recordReplace records a transaction replacing another one.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) recordReplace(tx, replaced *types.Transaction) {
+	pool.changes = append(pool.changes, TxPoolChange{Kind: TxPoolReplace, Tx: tx, Replaced: replaced})
+}
+
+// recordRemove records transactions leaving the pool.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) recordRemove(reason TxPoolRemoveReason, txs ...*types.Transaction) {
+	for _, tx := range txs {
+		pool.changes = append(pool.changes, TxPoolChange{Kind: TxPoolRemove, Tx: tx, Reason: reason})
+	}
+}
+
+// recordStale records transactions dropped for having a nonce lower than their
+// account's, telling apart the ones included in the latest blocks.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) recordStale(txs ...*types.Transaction) {
+	for _, tx := range txs {
+		if _, ok := pool.included[tx.Hash()]; ok {
+			pool.recordRemove(TxPoolRemoveIncluded, tx)
+		} else {
+			pool.recordRemove(TxPoolRemoveInvalidNonce, tx)
+		}
+	}
+}
+
+// takeChanges returns the changes recorded since the last call.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) takeChanges() []TxPoolChange {
+	changes := pool.changes
+	pool.changes = nil
+	return changes
+}
+
+// sendChanges notifies subsystems of changes to the pool contents. It must be
+// called without holding the pool lock, as subscribers may block.
+func (pool *TxPool) sendChanges(changes []TxPoolChange) {
+	if len(changes) > 0 {
+		pool.changeFeed.Send(TxPoolChangeEvent{changes})
+	}
+}
+
+// includedSet returns the hashes of the given transactions.
+func includedSet(txs types.Transactions) map[common.Hash]struct{} {
+	set := make(map[common.Hash]struct{}, len(txs))
+	for _, tx := range txs {
+		set[tx.Hash()] = struct{}{}
+	}
+	return set
+}
@@ -0,0 +1,143 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/metrics"
+)
+
+// nativeCurrencyLabel is the metric label used for transactions paying fees in CELO.
+const nativeCurrencyLabel = "celo"
+
+// currencyTxStats aggregates the pool composition of a single fee currency.
+type currencyTxStats struct {
+	pending, queued int64
+	gas             uint64
+	prices          []*big.Int
+}
+
+// currencyMetrics holds the gauges reported for a single fee currency.
+type currencyMetrics struct {
+	pending     metrics.Gauge
+	queued      metrics.Gauge
+	gas         metrics.Gauge
+	minPrice    metrics.Gauge
+	medianPrice metrics.Gauge
+}
+
+// newCurrencyMetrics registers (or retrieves) the gauges of a fee currency.
+func newCurrencyMetrics(label string) *currencyMetrics {
+	prefix := "txpool/currency/" + label + "/"
+	return &currencyMetrics{
+		pending:     metrics.GetOrRegisterGauge(prefix+"pending", nil),
+		queued:      metrics.GetOrRegisterGauge(prefix+"queued", nil),
+		gas:         metrics.GetOrRegisterGauge(prefix+"gas", nil),
+		minPrice:    metrics.GetOrRegisterGauge(prefix+"price/min", nil),
+		medianPrice: metrics.GetOrRegisterGauge(prefix+"price/median", nil),
+	}
+}
+
+// update reports the given stats, resetting all gauges if stats is nil.
+func (m *currencyMetrics) update(stats *currencyTxStats) {
+	if stats == nil {
+		stats = new(currencyTxStats)
+	}
+	m.pending.Update(stats.pending)
+	m.queued.Update(stats.queued)
+	m.gas.Update(clampInt64(new(big.Int).SetUint64(stats.gas)))
+
+	if len(stats.prices) == 0 {
+		m.minPrice.Update(0)
+		m.medianPrice.Update(0)
+		return
+	}
+	sort.Slice(stats.prices, func(i, j int) bool { return stats.prices[i].Cmp(stats.prices[j]) < 0 })
+	m.minPrice.Update(clampInt64(stats.prices[0]))
+	m.medianPrice.Update(clampInt64(stats.prices[len(stats.prices)/2]))
+}
+
+// clampInt64 converts v into an int64, saturating at math.MaxInt64.
+func clampInt64(v *big.Int) int64 {
+	if !v.IsInt64() {
+		return math.MaxInt64
+	}
+	return v.Int64()
+}
+
+// currencyLabel returns the metric label for the given fee currency.
+func currencyLabel(feeCurrency *common.Address) string {
+	if feeCurrency == nil {
+		return nativeCurrencyLabel
+	}
+	return strings.ToLower(feeCurrency.Hex())
+}
+
+// currencyStats collects the per fee currency composition of the pool.
+//
+// The caller must hold pool.mu.
+func (pool *TxPool) currencyStats() map[string]*currencyTxStats {
+	stats := make(map[string]*currencyTxStats)
+	collect := func(list *txList, pending bool) {
+		for _, tx := range list.txs.items {
+			label := currencyLabel(tx.FeeCurrency())
+			s := stats[label]
+			if s == nil {
+				s = new(currencyTxStats)
+				stats[label] = s
+			}
+			if pending {
+				s.pending++
+			} else {
+				s.queued++
+			}
+			s.gas += tx.Gas()
+			s.prices = append(s.prices, tx.GasPrice())
+		}
+	}
+	for _, list := range pool.pending {
+		collect(list, true)
+	}
+	for _, list := range pool.queue {
+		collect(list, false)
+	}
+	return stats
+}
+
+// updateCurrencyMetrics reports the given per fee currency composition of the
+// pool through the metrics registry. Currencies that vanished from the pool
+// since the last update have their gauges reset to zero.
+//
+// Only the pool loop may call it, as it owns the gauges.
+func (pool *TxPool) updateCurrencyMetrics(stats map[string]*currencyTxStats) {
+	if pool.currencyMetrics == nil {
+		pool.currencyMetrics = make(map[string]*currencyMetrics)
+	}
+	for label := range stats {
+		if _, ok := pool.currencyMetrics[label]; !ok {
+			pool.currencyMetrics[label] = newCurrencyMetrics(label)
+		}
+	}
+	for label, m := range pool.currencyMetrics {
+		m.update(stats[label])
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/crypto"
+	"github.com/celo-org/celo-blockchain/metrics"
+)
+
+func TestCurrencyMetricsUpdate(t *testing.T) {
+	// Use standard gauges directly, as registered ones are no-ops with metrics disabled
+	m := &currencyMetrics{
+		pending:     new(metrics.StandardGauge),
+		queued:      new(metrics.StandardGauge),
+		gas:         new(metrics.StandardGauge),
+		minPrice:    new(metrics.StandardGauge),
+		medianPrice: new(metrics.StandardGauge),
+	}
+	m.update(&currencyTxStats{
+		pending: 2,
+		queued:  1,
+		gas:     63000,
+		prices:  []*big.Int{big.NewInt(3), big.NewInt(1), big.NewInt(2)},
+	})
+	if have := m.pending.Value(); have != 2 {
+		t.Errorf("pending mismatch: have %d, want %d", have, 2)
+	}
+	if have := m.queued.Value(); have != 1 {
+		t.Errorf("queued mismatch: have %d, want %d", have, 1)
+	}
+	if have := m.gas.Value(); have != 63000 {
+		t.Errorf("gas mismatch: have %d, want %d", have, 63000)
+	}
+	if have := m.minPrice.Value(); have != 1 {
+		t.Errorf("min price mismatch: have %d, want %d", have, 1)
+	}
+	if have := m.medianPrice.Value(); have != 2 {
+		t.Errorf("median price mismatch: have %d, want %d", have, 2)
+	}
+	// Currencies leaving the pool must be reset
+	m.update(nil)
+	if m.pending.Value() != 0 || m.queued.Value() != 0 || m.gas.Value() != 0 || m.minPrice.Value() != 0 || m.medianPrice.Value() != 0 {
+		t.Errorf("gauges not reset after currency left the pool")
+	}
+}
+
+func TestCurrencyStats(t *testing.T) {
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+	txs := []*types.Transaction{
+		pricedTransaction(0, 100000, big.NewInt(3), key),
+		pricedTransaction(1, 100000, big.NewInt(1), key),
+		pricedTransaction(5, 50000, big.NewInt(2), key),
+	}
+	for i, err := range pool.AddRemotesSync(txs) {
+		if err != nil {
+			t.Fatalf("tx %d: failed to add: %v", i, err)
+		}
+	}
+	pool.mu.RLock()
+	stats := pool.currencyStats()
+	pool.mu.RUnlock()
+
+	if len(stats) != 1 {
+		t.Fatalf("currency count mismatch: have %d, want %d", len(stats), 1)
+	}
+	s := stats[nativeCurrencyLabel]
+	if s == nil {
+		t.Fatalf("missing native currency stats")
+	}
+	if s.pending != 2 || s.queued != 1 {
+		t.Errorf("composition mismatch: have %d pending %d queued, want 2 pending 1 queued", s.pending, s.queued)
+	}
+	if s.gas != 250000 {
+		t.Errorf("gas mismatch: have %d, want %d", s.gas, 250000)
+	}
+	if len(s.prices) != 3 {
+		t.Errorf("price count mismatch: have %d, want %d", len(s.prices), 3)
+	}
+}
+
+func TestCurrencyLabel(t *testing.T) {
+	if have := currencyLabel(nil); have != nativeCurrencyLabel {
+		t.Errorf("native label mismatch: have %s, want %s", have, nativeCurrencyLabel)
+	}
+	addr := common.HexToAddress("0x765DE816845861e75A25fCA122bb6898B8B1282a")
+	if have, want := currencyLabel(&addr), "0x765de816845861e75a25fca122bb6898b8b1282a"; have != want {
+		t.Errorf("currency label mismatch: have %s, want %s", have, want)
+	}
+	if have := clampInt64(new(big.Int).Lsh(common.Big1, 70)); have != math.MaxInt64 {
+		t.Errorf("clamped value mismatch: have %d, want %d", have, int64(math.MaxInt64))
+	}
+}
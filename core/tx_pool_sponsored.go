@@ -0,0 +1,150 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/log"
+	"github.com/celo-org/celo-blockchain/metrics"
+)
+
+// Sponsored transactions live in the lists of their senders like any other, but
+// their fees are owed by a fee payer the pool does not otherwise track. The pool
+// keeps them in a separate lane per fee payer, bounded in count, whose fees the
+// fee payer balance has to cover altogether.
+
+// Metrics for sponsored transactions
+var sponsoredNofundsMeter = metrics.NewRegisteredMeter("txpool/sponsored/nofunds", nil) // Dropped due to fee payer out-of-funds
+
+// sponsoredLane returns the pooled transactions sponsored by the given fee payer,
+// pruning the ones which left the pool.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) sponsoredLane(feePayer common.Address) []*types.Transaction {
+	lane := pool.sponsored[feePayer]
+	txs := make([]*types.Transaction, 0, len(lane))
+	for hash := range lane {
+		if tx := pool.all.Get(hash); tx != nil {
+			txs = append(txs, tx)
+		} else {
+			delete(lane, hash)
+		}
+	}
+	if len(lane) == 0 {
+		delete(pool.sponsored, feePayer)
+	}
+	return txs
+}
+
+// validateSponsored checks the fee payer of a sponsored transaction has a free
+// slot in its lane, and enough funds to cover the fees of the transaction along
+// with the ones it already sponsors in the same currency.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) validateSponsored(tx *types.Transaction, from common.Address) error {
+	feePayer := *tx.FeePayer()
+
+	fees := tx.Fee()
+	count := uint64(1)
+	for _, other := range pool.sponsoredLane(feePayer) {
+		// Skip the transaction being replaced, if any
+		if sender, _ := types.Sender(pool.signer, other); sender == from && other.Nonce() == tx.Nonce() {
+			continue
+		}
+		count++
+		if sameFeeCurrency(other.FeeCurrency(), tx.FeeCurrency()) {
+			fees.Add(fees, other.Fee())
+		}
+	}
+	if count > pool.config.FeePayerSlots {
+		return ErrFeePayerSlotsExceeded
+	}
+	balance, err := pool.feeBalanceOf(feePayer, tx.FeeCurrency())
+	if err != nil {
+		return err
+	}
+	if balance.Cmp(fees) < 0 {
+		log.Debug("Insufficient fee payer funds", "feePayer", feePayer, "feeCurrency", tx.FeeCurrency(), "fees", fees, "balance", balance)
+		return ErrInsufficientFunds
+	}
+	return nil
+}
+
+// trackSponsored adds a sponsored transaction to the lane of its fee payer.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) trackSponsored(tx *types.Transaction) {
+	feePayer := tx.FeePayer()
+	if feePayer == nil {
+		return
+	}
+	if pool.sponsored[*feePayer] == nil {
+		pool.sponsored[*feePayer] = make(map[common.Hash]struct{})
+	}
+	pool.sponsored[*feePayer][tx.Hash()] = struct{}{}
+}
+
+// dropUnpayableSponsored removes the sponsored transactions whose fee payers can
+// no longer cover the fees of their lanes, keeping the cheapest ones they can.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) dropUnpayableSponsored() {
+	for feePayer := range pool.sponsored {
+		byCurrency := make(map[common.Address][]*types.Transaction)
+		for _, tx := range pool.sponsoredLane(feePayer) {
+			var feeCurrency common.Address
+			if tx.FeeCurrency() != nil {
+				feeCurrency = *tx.FeeCurrency()
+			}
+			byCurrency[feeCurrency] = append(byCurrency[feeCurrency], tx)
+		}
+		for _, txs := range byCurrency {
+			balance, err := pool.feeBalanceOf(feePayer, txs[0].FeeCurrency())
+			if err != nil {
+				balance = new(big.Int)
+			}
+			sort.Slice(txs, func(i, j int) bool { return txs[i].Fee().Cmp(txs[j].Fee()) < 0 })
+
+			fees := new(big.Int)
+			for _, tx := range txs {
+				if fees.Add(fees, tx.Fee()).Cmp(balance) <= 0 {
+					continue
+				}
+				log.Trace("Removed unpayable sponsored transaction", "hash", tx.Hash(), "feePayer", feePayer)
+				pool.removeTx(tx.Hash(), true, TxPoolRemoveUnpayable)
+				sponsoredNofundsMeter.Mark(1)
+			}
+		}
+		pool.sponsoredLane(feePayer)
+	}
+}
+
+// feeBalanceOf returns the balance of the account in the given fee currency.
+func (pool *TxPool) feeBalanceOf(account common.Address, feeCurrency *common.Address) (*big.Int, error) {
+	return feeBalanceOf(account, feeCurrency, pool.currentState, pool.currentVMRunner)
+}
+
+func sameFeeCurrency(a, b *common.Address) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
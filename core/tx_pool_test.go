@@ -328,6 +328,152 @@ func TestInvalidTransactions(t *testing.T) {
 	}
 }
 
+// Tests that the intrinsic gas schedule of the chain config is used to validate
+// transactions paying a gateway fee.
+func TestTransactionIntrinsicGasSchedule(t *testing.T) {
+	t.Parallel()
+
+	config := *params.TestChainConfig
+	config.IntrinsicGasSchedules = []*params.IntrinsicGasSchedule{{Block: big.NewInt(0), GatewayFee: 5000}}
+	pool := NewTxPool(testTxPoolConfig, &config, newTestBlockchain())
+	defer pool.Stop()
+
+	key, _ := crypto.GenerateKey()
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000))
+
+	if err := pool.AddRemote(lesTransaction(0, params.TxGas, big.NewInt(50), key)); err != ErrIntrinsicGas {
+		t.Error("expected", ErrIntrinsicGas, "got", err)
+	}
+	if err := pool.AddRemote(lesTransaction(0, params.TxGas+5000, big.NewInt(50), key)); err != nil {
+		t.Error("expected", nil, "got", err)
+	}
+	// Transactions without a gateway fee recipient are not charged
+	if err := pool.AddRemote(transaction(1, params.TxGas, key)); err != nil {
+		t.Error("expected", nil, "got", err)
+	}
+}
+
+// Tests that local transactions bounded by a valid until block are dropped once
+// they can no longer be included, and are left out of the journal meanwhile.
+func TestTransactionValidUntil(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+	other, _ := crypto.GenerateKey()
+
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000))
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(other.PublicKey), big.NewInt(1000000))
+
+	bounded, unbounded := transaction(0, 100000, key), transaction(0, 100000, other)
+	if err := pool.AddLocalUntil(bounded, 0); err != ErrValidUntilPassed {
+		t.Fatal("expected", ErrValidUntilPassed, "got", err)
+	}
+	if err := pool.AddLocalUntil(bounded, 2); err != nil {
+		t.Fatalf("failed to add bounded transaction: %v", err)
+	}
+	if err := pool.AddLocalUntil(bounded, 5); err != ErrAlreadyKnown {
+		t.Fatal("expected", ErrAlreadyKnown, "got", err)
+	}
+	if err := pool.AddLocal(unbounded); err != nil {
+		t.Fatalf("failed to add unbounded transaction: %v", err)
+	}
+	pool.mu.Lock()
+	locals := pool.local()
+	pool.mu.Unlock()
+	if txs := locals[crypto.PubkeyToAddress(key.PublicKey)]; len(txs) != 0 {
+		t.Errorf("bounded transactions journaled: %v", txs)
+	}
+	if txs := locals[crypto.PubkeyToAddress(other.PublicKey)]; len(txs) != 1 {
+		t.Errorf("unbounded transactions mismatch: have %d, want %d", len(txs), 1)
+	}
+	// The transaction may still be included in the pending block 2
+	<-pool.requestReset(nil, &types.Header{Number: big.NewInt(1)})
+	if pool.all.Get(bounded.Hash()) == nil {
+		t.Fatalf("bounded transaction dropped before its valid until block")
+	}
+	// But not anymore past it
+	<-pool.requestReset(nil, &types.Header{Number: big.NewInt(2)})
+	if pool.all.Get(bounded.Hash()) != nil {
+		t.Errorf("bounded transaction not dropped past its valid until block")
+	}
+	if pool.all.Get(unbounded.Hash()) == nil {
+		t.Errorf("unbounded transaction dropped")
+	}
+	if len(pool.validUntil) != 0 {
+		t.Errorf("valid until blocks leaked: %v", pool.validUntil)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+func sponsoredTransaction(nonce uint64, gaslimit uint64, key, feePayerKey *ecdsa.PrivateKey) *types.Transaction {
+	signer := types.NewEIP155Signer(params.TestChainConfig.ChainID)
+	tx := types.NewTransaction(nonce, common.Address{}, big.NewInt(100), gaslimit, big.NewInt(1), nil, nil, nil, nil)
+	tx, _ = types.SignTx(tx.WithFeePayer(crypto.PubkeyToAddress(feePayerKey.PublicKey)), signer, key)
+	tx, _ = types.SignFeePayer(tx, feePayerKey)
+	return tx
+}
+
+// Tests that sponsored transactions are only accepted from the E hard fork on,
+// and are bounded by the slots and the balance of their fee payer.
+func TestTransactionSponsored(t *testing.T) {
+	t.Parallel()
+
+	key, _ := crypto.GenerateKey()
+	other, _ := crypto.GenerateKey()
+	payer, _ := crypto.GenerateKey()
+
+	// Sponsored transactions are rejected before the E hard fork
+	pool := NewTxPool(testTxPoolConfig, params.TestChainConfig, newTestBlockchain())
+	defer pool.Stop()
+
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(key.PublicKey), big.NewInt(100))
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(payer.PublicKey), big.NewInt(1000000))
+	if err := pool.AddRemote(sponsoredTransaction(0, 100000, key, payer)); err != ErrSponsoredTransactionsNotSupported {
+		t.Fatal("expected", ErrSponsoredTransactionsNotSupported, "got", err)
+	}
+
+	config := *params.TestChainConfig
+	config.EBlock = big.NewInt(0)
+	poolConfig := testTxPoolConfig
+	poolConfig.FeePayerSlots = 2
+	pool = NewTxPool(poolConfig, &config, newTestBlockchain())
+	defer pool.Stop()
+
+	// The sender only covers the value, the fee payer the fees
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(key.PublicKey), big.NewInt(200))
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(other.PublicKey), big.NewInt(100))
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(payer.PublicKey), big.NewInt(300000))
+
+	unsigned, _ := types.SignTx(transaction(0, 100000, key).WithFeePayer(crypto.PubkeyToAddress(payer.PublicKey)), pool.signer, key)
+	if err := pool.AddRemote(unsigned); err != ErrInvalidFeePayer {
+		t.Fatal("expected", ErrInvalidFeePayer, "got", err)
+	}
+	if errs := pool.AddRemotesSync([]*types.Transaction{sponsoredTransaction(0, 100000, key, payer), sponsoredTransaction(1, 100000, key, payer)}); errs[0] != nil || errs[1] != nil {
+		t.Fatalf("failed to add sponsored transactions: %v", errs)
+	}
+	if pending, _ := pool.Stats(); pending != 2 {
+		t.Fatalf("pending transactions mismatch: have %d, want %d", pending, 2)
+	}
+	if err := pool.AddRemote(sponsoredTransaction(0, 100000, other, payer)); err != ErrFeePayerSlotsExceeded {
+		t.Fatal("expected", ErrFeePayerSlotsExceeded, "got", err)
+	}
+	// Sponsored transactions the fee payer can no longer afford are dropped
+	pool.currentState.SubBalance(crypto.PubkeyToAddress(payer.PublicKey), big.NewInt(150000))
+	<-pool.requestReset(nil, nil)
+	if pending, queued := pool.Stats(); pending+queued != 1 {
+		t.Fatalf("remaining transactions mismatch: have %d, want %d", pending+queued, 1)
+	}
+	if err := pool.AddRemote(sponsoredTransaction(0, 100000, other, payer)); err != ErrInsufficientFunds {
+		t.Fatal("expected", ErrInsufficientFunds, "got", err)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 func TestTransactionQueue(t *testing.T) {
 	t.Parallel()
 
@@ -419,7 +565,7 @@ func TestTransactionChainFork(t *testing.T) {
 	if _, err := pool.add(tx, false); err != nil {
 		t.Error("didn't expect error", err)
 	}
-	pool.removeTx(tx.Hash(), true)
+	pool.removeTx(tx.Hash(), true, TxPoolRemoveExpired)
 
 	// reset the pool's internal state
 	resetState()
@@ -428,6 +574,98 @@ func TestTransactionChainFork(t *testing.T) {
 	}
 }
 
+// reorgTestChain is a test blockchain serving the blocks of several forks, for the
+// pool to look up the transactions dropped by reorgs.
+type reorgTestChain struct {
+	*testBlockChain
+	blocks map[common.Hash]*types.Block
+}
+
+func (bc *reorgTestChain) GetBlock(hash common.Hash, number uint64) *types.Block {
+	return bc.blocks[hash]
+}
+
+func (bc *reorgTestChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if block := bc.blocks[hash]; block != nil {
+		return block.Header()
+	}
+	return nil
+}
+
+// extend appends n blocks of the given fork to the parent, the first one holding
+// the given transactions, and returns the last one.
+func (bc *reorgTestChain) extend(parent *types.Block, n int, fork byte, txs ...*types.Transaction) *types.Block {
+	for i := 0; i < n; i++ {
+		header := &types.Header{
+			ParentHash: parent.Hash(),
+			Number:     new(big.Int).Add(parent.Number(), common.Big1),
+			Extra:      []byte{fork},
+		}
+		parent = types.NewBlock(header, txs, nil, nil)
+		bc.blocks[parent.Hash()] = parent
+		txs = nil
+	}
+	return parent
+}
+
+// Tests that the transactions of the blocks dropped by a shallow reorg are
+// reinjected into the pool, even if the new head is far ahead as during sync.
+func TestTransactionReorgReinjection(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+	other, _ := crypto.GenerateKey()
+
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	chain := &reorgTestChain{testBlockChain: newTestBlockchain(), blocks: make(map[common.Hash]*types.Block)}
+	chain.statedb.AddBalance(addr, big.NewInt(100000000000000))
+	pool.chain = chain
+	<-pool.requestReset(nil, nil)
+
+	var (
+		tx1 = transaction(0, 100000, key)
+		tx2 = transaction(0, 100000, other)
+		tx3 = transaction(1, 100000, key)
+
+		genesis  = chain.extend(types.NewBlock(&types.Header{Number: common.Big0}, nil, nil, nil), 10, 0)
+		oldHead  = chain.extend(genesis, 2, 1, tx1, tx2, tx3)
+		newHead  = chain.extend(genesis, 200, 2, tx2)
+		deepHead = chain.extend(genesis, reorgReinjectDepth+1, 3, tx1)
+	)
+	// The old head being an ancestor of the new one, nothing was dropped
+	if reinject, _, ok := pool.reorgTransactions(genesis.Header(), newHead.Header()); !ok || len(reinject) != 0 {
+		t.Fatalf("ancestor reorg reinjection mismatch: have %d (%v), want 0", len(reinject), ok)
+	}
+	// Too many blocks were dropped by the reorg
+	if reinject, _, ok := pool.reorgTransactions(deepHead.Header(), newHead.Header()); !ok || len(reinject) != 0 {
+		t.Fatalf("deep reorg reinjection mismatch: have %d (%v), want 0", len(reinject), ok)
+	}
+	// The transactions dropped and not included in the new chain are reinjected
+	reinject, included, ok := pool.reorgTransactions(oldHead.Header(), newHead.Header())
+	if !ok {
+		t.Fatalf("shallow reorg skipped")
+	}
+	if len(reinject) != 2 || reinject[0].Hash() != tx1.Hash() || reinject[1].Hash() != tx3.Hash() {
+		t.Fatalf("shallow reorg reinjection mismatch: have %d transactions, want 2", len(reinject))
+	}
+	if len(included) != 1 || included[0].Hash() != tx2.Hash() {
+		t.Fatalf("shallow reorg inclusions mismatch: have %d transactions, want 1", len(included))
+	}
+	<-pool.requestReset(oldHead.Header(), newHead.Header())
+
+	pending, _ := pool.Pending()
+	if len(pending[addr]) != 2 {
+		t.Errorf("reinjected transaction count mismatch: have %d, want 2", len(pending[addr]))
+	}
+	if pool.Get(tx2.Hash()) != nil {
+		t.Errorf("transaction included in the new chain reinjected")
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 func TestTransactionDoubleNonce(t *testing.T) {
 	t.Parallel()
 
@@ -834,6 +1072,58 @@ func TestHandleDonutActivation(t *testing.T) {
 	}
 }
 
+// Tests that pending transactions under the gas price minimum are moved back
+// to the queue, along with the ones they invalidate, and promoted again once
+// the gas price minimum drops.
+func TestTransactionGasPriceMinimum(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	account := crypto.PubkeyToAddress(key.PublicKey)
+	pool.currentState.AddBalance(account, big.NewInt(1000000))
+
+	txs := []*types.Transaction{
+		pricedTransaction(0, 100000, big.NewInt(3), key),
+		pricedTransaction(1, 100000, big.NewInt(1), key),
+		pricedTransaction(2, 100000, big.NewInt(3), key),
+	}
+	for _, err := range pool.AddRemotesSync(txs) {
+		if err != nil {
+			t.Fatalf("failed to add transaction: %v", err)
+		}
+	}
+	if pending, queued := pool.Stats(); pending != 3 || queued != 0 {
+		t.Fatalf("pool size mismatch: have %d pending and %d queued, want 3 and 0", pending, queued)
+	}
+	// Raise the gas price minimum over the second transaction
+	pool.mu.Lock()
+	pool.gasPriceMinimums[common.Address{}] = big.NewInt(2)
+	pool.demoteUnexecutables()
+	pool.mu.Unlock()
+
+	if pending, queued := pool.Stats(); pending != 1 || queued != 2 {
+		t.Fatalf("pool size mismatch after the raise: have %d pending and %d queued, want 1 and 2", pending, queued)
+	}
+	// Held back on promotion while underpriced
+	pool.mu.Lock()
+	pool.pendingNonces.set(account, 1)
+	if promoted := pool.promoteExecutables([]common.Address{account}); len(promoted) != 0 {
+		t.Errorf("underpriced transactions promoted: %v", promoted)
+	}
+	// Promoted again once the gas price minimum drops
+	pool.gasPriceMinimums[common.Address{}] = big.NewInt(1)
+	if promoted := pool.promoteExecutables([]common.Address{account}); len(promoted) != 2 {
+		t.Errorf("promoted transaction count mismatch: have %d, want 2", len(promoted))
+	}
+	pool.mu.Unlock()
+
+	if pending, queued := pool.Stats(); pending != 3 || queued != 0 {
+		t.Fatalf("pool size mismatch after the drop: have %d pending and %d queued, want 3 and 0", pending, queued)
+	}
+}
+
 // Tests that if the transaction count belonging to a single account goes above
 // some threshold, the higher transactions are dropped to prevent DOS attacks.
 func TestTransactionQueueAccountLimiting(t *testing.T) {
@@ -1828,6 +2118,84 @@ func TestTransactionReplacement(t *testing.T) {
 	}
 }
 
+// Tests that additions, replacements and removals of transactions are announced
+// to the change subscribers, along with the reason of the removals.
+func TestTransactionPoolChangeEvents(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	pool.currentState.AddBalance(addr, big.NewInt(1000000000))
+
+	events := make(chan TxPoolChangeEvent, 32)
+	sub := pool.SubscribeTxPoolChangeEvent(events)
+	defer sub.Unsubscribe()
+
+	// Add a pending transaction and replace it
+	cheap := pricedTransaction(0, 100000, big.NewInt(1), key)
+	if err := pool.addRemoteSync(cheap); err != nil {
+		t.Fatalf("failed to add pending transaction: %v", err)
+	}
+	if err := validateChanges(events, TxPoolChange{Kind: TxPoolAdd, Tx: cheap}); err != nil {
+		t.Fatalf("pending addition: %v", err)
+	}
+	replacement := pricedTransaction(0, 100000, big.NewInt(2), key)
+	if err := pool.addRemoteSync(replacement); err != nil {
+		t.Fatalf("failed to replace pending transaction: %v", err)
+	}
+	if err := validateChanges(events, TxPoolChange{Kind: TxPoolReplace, Tx: replacement, Replaced: cheap}); err != nil {
+		t.Fatalf("pending replacement: %v", err)
+	}
+	// Add a queued transaction and price it out
+	queued := pricedTransaction(2, 100000, big.NewInt(1), key)
+	if err := pool.addRemoteSync(queued); err != nil {
+		t.Fatalf("failed to add queued transaction: %v", err)
+	}
+	if err := validateChanges(events, TxPoolChange{Kind: TxPoolAdd, Tx: queued}); err != nil {
+		t.Fatalf("queued addition: %v", err)
+	}
+	pool.SetGasPrice(big.NewInt(2))
+	if err := validateChanges(events, TxPoolChange{Kind: TxPoolRemove, Tx: queued, Reason: TxPoolRemovePricedOut}); err != nil {
+		t.Fatalf("repricing removal: %v", err)
+	}
+	// Use the nonce of the pending transaction outside of the pool
+	pool.chain.(*testBlockChain).statedb.SetNonce(addr, 1)
+	<-pool.requestReset(nil, nil)
+	if err := validateChanges(events, TxPoolChange{Kind: TxPoolRemove, Tx: replacement, Reason: TxPoolRemoveInvalidNonce}); err != nil {
+		t.Fatalf("stale removal: %v", err)
+	}
+}
+
+// validateChanges checks that exactly the given changes are announced, in order.
+func validateChanges(events chan TxPoolChangeEvent, want ...TxPoolChange) error {
+	var received []TxPoolChange
+
+	for len(received) < len(want) {
+		select {
+		case ev := <-events:
+			received = append(received, ev.Changes...)
+		case <-time.After(time.Second):
+			return fmt.Errorf("change #%d not fired", len(received))
+		}
+	}
+	if len(received) > len(want) {
+		return fmt.Errorf("more than %d changes fired: %v", len(want), received[len(want):])
+	}
+	for i, change := range received {
+		if change.Kind != want[i].Kind || change.Tx != want[i].Tx || change.Replaced != want[i].Replaced || change.Reason != want[i].Reason {
+			return fmt.Errorf("change #%d mismatch: have %+v, want %+v", i, change, want[i])
+		}
+	}
+	select {
+	case ev := <-events:
+		return fmt.Errorf("more than %d changes fired: %v", len(want), ev.Changes)
+	case <-time.After(50 * time.Millisecond):
+	}
+	return nil
+}
+
 // Tests that local transactions are journaled to disk, but remote transactions
 // get discarded between restarts.
 func TestTransactionJournaling(t *testing.T)         { testTransactionJournaling(t, false) }
@@ -1944,6 +2312,68 @@ func testTransactionJournaling(t *testing.T, nolocals bool) {
 	pool.Stop()
 }
 
+// Tests that remote transactions, both pending and queued, survive a restart if
+// the remote journal is enabled, and that they are validated again on load.
+func TestTransactionRemoteJournaling(t *testing.T) {
+	t.Parallel()
+
+	// Create a temporary file for the journal
+	file, err := ioutil.TempFile("", "")
+	if err != nil {
+		t.Fatalf("failed to create temporary journal: %v", err)
+	}
+	journal := file.Name()
+	defer os.Remove(journal)
+
+	file.Close()
+	os.Remove(journal)
+
+	blockchain := newTestBlockchain()
+
+	config := testTxPoolConfig
+	config.NoLocals = true
+	config.RemoteJournal = journal
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+
+	// Add two pending and a queued transaction of one account, and one of another
+	first, _ := crypto.GenerateKey()
+	second, _ := crypto.GenerateKey()
+
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(first.PublicKey), big.NewInt(1000000000))
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(second.PublicKey), big.NewInt(1000000000))
+
+	for i, tx := range []*types.Transaction{
+		pricedTransaction(0, 100000, big.NewInt(1), first),
+		pricedTransaction(1, 100000, big.NewInt(1), first),
+		pricedTransaction(3, 100000, big.NewInt(1), first),
+		pricedTransaction(0, 100000, big.NewInt(1), second),
+	} {
+		if err := pool.addRemoteSync(tx); err != nil {
+			t.Fatalf("failed to add remote transaction %d: %v", i, err)
+		}
+	}
+	if pending, queued := pool.Stats(); pending != 3 || queued != 1 {
+		t.Fatalf("transactions mismatched: have %d pending and %d queued, want 3 and 1", pending, queued)
+	}
+	// Restart the pool with the first transaction of the second account included
+	pool.Stop()
+	blockchain.statedb.SetNonce(crypto.PubkeyToAddress(second.PublicKey), 1)
+	oldstatedb := blockchain.statedb
+	blockchain = newTestBlockchain()
+	blockchain.statedb = oldstatedb
+
+	pool = NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	if pending, queued := pool.Stats(); pending != 2 || queued != 1 {
+		t.Fatalf("transactions mismatched after restart: have %d pending and %d queued, want 2 and 1", pending, queued)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 // TestTransactionStatusCheck tests that the pool can correctly retrieve the
 // pending status of individual transactions.
 func TestTransactionStatusCheck(t *testing.T) {
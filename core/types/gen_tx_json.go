@@ -28,6 +28,10 @@ func (t txdata) MarshalJSON() ([]byte, error) {
 		V                   *hexutil.Big    `json:"v" gencodec:"required"`
 		R                   *hexutil.Big    `json:"r" gencodec:"required"`
 		S                   *hexutil.Big    `json:"s" gencodec:"required"`
+		FeePayer            *common.Address `json:"feePayer,omitempty" rlp:"-"`
+		FeePayerV           *hexutil.Big    `json:"feePayerV,omitempty" rlp:"-"`
+		FeePayerR           *hexutil.Big    `json:"feePayerR,omitempty" rlp:"-"`
+		FeePayerS           *hexutil.Big    `json:"feePayerS,omitempty" rlp:"-"`
 		Hash                *common.Hash    `json:"hash" rlp:"-"`
 		EthCompatible       bool            `json:"ethCompatible" rlp:"-"`
 	}
@@ -44,6 +48,10 @@ func (t txdata) MarshalJSON() ([]byte, error) {
 	enc.V = (*hexutil.Big)(t.V)
 	enc.R = (*hexutil.Big)(t.R)
 	enc.S = (*hexutil.Big)(t.S)
+	enc.FeePayer = t.FeePayer
+	enc.FeePayerV = (*hexutil.Big)(t.FeePayerV)
+	enc.FeePayerR = (*hexutil.Big)(t.FeePayerR)
+	enc.FeePayerS = (*hexutil.Big)(t.FeePayerS)
 	enc.Hash = t.Hash
 	enc.EthCompatible = t.EthCompatible
 	return json.Marshal(&enc)
@@ -64,6 +72,10 @@ func (t *txdata) UnmarshalJSON(input []byte) error {
 		V                   *hexutil.Big    `json:"v" gencodec:"required"`
 		R                   *hexutil.Big    `json:"r" gencodec:"required"`
 		S                   *hexutil.Big    `json:"s" gencodec:"required"`
+		FeePayer            *common.Address `json:"feePayer,omitempty" rlp:"-"`
+		FeePayerV           *hexutil.Big    `json:"feePayerV,omitempty" rlp:"-"`
+		FeePayerR           *hexutil.Big    `json:"feePayerR,omitempty" rlp:"-"`
+		FeePayerS           *hexutil.Big    `json:"feePayerS,omitempty" rlp:"-"`
 		Hash                *common.Hash    `json:"hash" rlp:"-"`
 		EthCompatible       *bool           `json:"ethCompatible" rlp:"-"`
 	}
@@ -115,6 +127,18 @@ func (t *txdata) UnmarshalJSON(input []byte) error {
 		return errors.New("missing required field 's' for txdata")
 	}
 	t.S = (*big.Int)(dec.S)
+	if dec.FeePayer != nil {
+		t.FeePayer = dec.FeePayer
+	}
+	if dec.FeePayerV != nil {
+		t.FeePayerV = (*big.Int)(dec.FeePayerV)
+	}
+	if dec.FeePayerR != nil {
+		t.FeePayerR = (*big.Int)(dec.FeePayerR)
+	}
+	if dec.FeePayerS != nil {
+		t.FeePayerS = (*big.Int)(dec.FeePayerS)
+	}
 	if dec.Hash != nil {
 		t.Hash = dec.Hash
 	}
@@ -34,9 +34,24 @@ var (
 
 	// ErrInvalidIstanbulHeaderExtra is returned if the length of extra-data is less than 32 bytes
 	ErrInvalidIstanbulHeaderExtra = errors.New("invalid istanbul header extra-data")
-	EmptyBlockSeal                = []byte{}
+	// ErrUnknownIstanbulExtraVersion is returned if the extra-data payload format is not supported
+	ErrUnknownIstanbulExtraVersion = errors.New("unknown istanbul header extra-data version")
+	EmptyBlockSeal                 = []byte{}
 )
 
+// Versions of the istanbul extra-data payload format.
+const (
+	// IstanbulExtraV1 holds the validator set diff, the proposer seal, the aggregated
+	// seal and the parent aggregated seal.
+	IstanbulExtraV1 uint = 1
+)
+
+// istanbulExtraVersions maps the number of fields of the extra-data payload to the
+// version of its format. New formats must differ in their field count.
+var istanbulExtraVersions = map[uint64]uint{
+	6: IstanbulExtraV1,
+}
+
 type IstanbulAggregatedSeal struct {
 	// Bitmap is a bitmap having an active bit for each validator that signed this block
 	Bitmap *big.Int
@@ -141,6 +156,36 @@ func ExtractIstanbulExtra(h *Header) (*IstanbulExtra, error) {
 	return istanbulExtra, nil
 }
 
+// DecodeIstanbulExtra decodes the extra-data of the header like ExtractIstanbulExtra,
+// returning the version of its payload format alongside. It returns an error if the
+// format is unknown, rather than decoding a newer payload partially.
+func DecodeIstanbulExtra(h *Header) (*IstanbulExtra, uint, error) {
+	if len(h.Extra) < IstanbulExtraVanity {
+		return nil, 0, ErrInvalidIstanbulHeaderExtra
+	}
+	payload := h.Extra[IstanbulExtraVanity:]
+	kind, content, _, err := rlp.Split(payload)
+	if err != nil {
+		return nil, 0, err
+	}
+	if kind != rlp.List {
+		return nil, 0, ErrInvalidIstanbulHeaderExtra
+	}
+	fields, err := rlp.CountValues(content)
+	if err != nil {
+		return nil, 0, err
+	}
+	version, ok := istanbulExtraVersions[uint64(fields)]
+	if !ok {
+		return nil, 0, ErrUnknownIstanbulExtraVersion
+	}
+	var istanbulExtra *IstanbulExtra
+	if err := rlp.DecodeBytes(payload, &istanbulExtra); err != nil {
+		return nil, 0, err
+	}
+	return istanbulExtra, version, nil
+}
+
 // IstanbulFilteredHeader returns a filtered header which some information (like seal, aggregated signature)
 // are clean to fulfill the Istanbul hash rules. It returns nil if the extra-data cannot be
 // decoded/encoded by rlp.
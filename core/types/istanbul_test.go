@@ -91,3 +91,30 @@ func TestExtractToIstanbul(t *testing.T) {
 		}
 	}
 }
+
+func TestDecodeIstanbulExtra(t *testing.T) {
+	vanity := bytes.Repeat([]byte{0x00}, IstanbulExtraVanity)
+	testCases := []struct {
+		istRawData      []byte
+		expectedVersion uint
+		expectedErr     error
+	}{
+		{hexutil.MustDecode("0xf6ea9444add0ec310f115a0e603b2d7db9f067778eaf8a94294fc7e8f22b3bcdcf955dd7ff3ba2ed833f8212c00c80c3808080c3808080"), IstanbulExtraV1, nil},
+		// an extra field makes an unknown format
+		{hexutil.MustDecode("0xf7ea9444add0ec310f115a0e603b2d7db9f067778eaf8a94294fc7e8f22b3bcdcf955dd7ff3ba2ed833f8212c00c80c3808080c380808080"), 0, ErrUnknownIstanbulExtraVersion},
+		// not a list
+		{hexutil.MustDecode("0x80"), 0, ErrInvalidIstanbulHeaderExtra},
+	}
+	for i, test := range testCases {
+		extra, version, err := DecodeIstanbulExtra(&Header{Extra: append(vanity, test.istRawData...)})
+		if err != test.expectedErr {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, test.expectedErr)
+		}
+		if version != test.expectedVersion {
+			t.Errorf("test %d: version mismatch: have %d, want %d", i, version, test.expectedVersion)
+		}
+		if err == nil && len(extra.AddedValidators) != 2 {
+			t.Errorf("test %d: added validators mismatch: have %d, want 2", i, len(extra.AddedValidators))
+		}
+	}
+}
@@ -48,9 +48,10 @@ type Transaction struct {
 	time time.Time // Time first seen locally (spam avoidance)
 
 	// caches
-	hash atomic.Value
-	size atomic.Value
-	from atomic.Value
+	hash     atomic.Value
+	size     atomic.Value
+	from     atomic.Value
+	feePayer atomic.Value
 }
 
 type txdata struct {
@@ -69,6 +70,12 @@ type txdata struct {
 	R *big.Int `json:"r" gencodec:"required"`
 	S *big.Int `json:"s" gencodec:"required"`
 
+	// Fee payer of sponsored transactions and its signature values, nil otherwise
+	FeePayer  *common.Address `json:"feePayer,omitempty" rlp:"-"`
+	FeePayerV *big.Int        `json:"feePayerV,omitempty" rlp:"-"`
+	FeePayerR *big.Int        `json:"feePayerR,omitempty" rlp:"-"`
+	FeePayerS *big.Int        `json:"feePayerS,omitempty" rlp:"-"`
+
 	// This is only used when marshaling to JSON.
 	Hash *common.Hash `json:"hash" rlp:"-"`
 
@@ -88,6 +95,9 @@ type txdataMarshaling struct {
 	V                   *hexutil.Big
 	R                   *hexutil.Big
 	S                   *hexutil.Big
+	FeePayerV           *hexutil.Big
+	FeePayerR           *hexutil.Big
+	FeePayerS           *hexutil.Big
 	EthCompatible       bool
 }
 
@@ -215,7 +225,9 @@ func isProtectedV(V *big.Int) bool {
 
 // EncodeRLP implements rlp.Encoder
 func (tx *Transaction) EncodeRLP(w io.Writer) error {
-	if tx.data.EthCompatible {
+	if tx.data.FeePayer != nil {
+		return rlp.Encode(w, toSponsoredRlpList(tx.data))
+	} else if tx.data.EthCompatible {
 		return rlp.Encode(w, toEthCompatibleRlpList(tx.data))
 	} else {
 		return rlp.Encode(w, &tx.data)
@@ -239,6 +251,10 @@ func (tx *Transaction) DecodeRLP(s *rlp.Stream) (err error) {
 		rlpList := ethCompatibleTxRlpList{}
 		err = rlp.DecodeBytes(raw, &rlpList)
 		tx.data = fromEthCompatibleRlpList(rlpList)
+	} else if numElems == sponsoredTxNumFields {
+		rlpList := sponsoredTxRlpList{}
+		err = rlp.DecodeBytes(raw, &rlpList)
+		tx.data = fromSponsoredRlpList(rlpList)
 	} else {
 		err = rlp.DecodeBytes(raw, &tx.data)
 	}
@@ -338,14 +354,18 @@ func (tx *Transaction) Size() common.StorageSize {
 		return size.(common.StorageSize)
 	}
 	c := writeCounter(0)
-	rlp.Encode(&c, &tx.data)
+	if tx.data.FeePayer != nil {
+		rlp.Encode(&c, toSponsoredRlpList(tx.data))
+	} else {
+		rlp.Encode(&c, &tx.data)
+	}
 	tx.size.Store(common.StorageSize(c))
 	return common.StorageSize(c)
 }
 
 // CheckEthCompatibility checks that the Celo-only fields are nil-or-0 if EthCompatible is true
 func (tx *Transaction) CheckEthCompatibility() error {
-	if tx.EthCompatible() && !(tx.FeeCurrency() == nil && tx.GatewayFeeRecipient() == nil && tx.GatewayFee().Sign() == 0) {
+	if tx.EthCompatible() && !(tx.FeeCurrency() == nil && tx.GatewayFeeRecipient() == nil && tx.GatewayFee().Sign() == 0 && tx.FeePayer() == nil) {
 		return ErrEthCompatibleTransactionIsntCompatible
 	}
 	return nil
@@ -373,6 +393,11 @@ func (tx *Transaction) AsMessage(s Signer) (Message, error) {
 
 	var err error
 	msg.from, err = Sender(s, tx)
+	if err != nil || tx.data.FeePayer == nil {
+		return msg, err
+	}
+	feePayer, err := FeePayerSender(tx)
+	msg.feePayer = &feePayer
 	return msg, err
 }
 
@@ -569,6 +594,7 @@ type Message struct {
 	data                []byte
 	ethCompatible       bool
 	checkNonce          bool
+	feePayer            *common.Address
 }
 
 func NewMessage(from common.Address, to *common.Address, nonce uint64, amount *big.Int, gasLimit uint64, gasPrice *big.Int, feeCurrency, gatewayFeeRecipient *common.Address, gatewayFee *big.Int, data []byte, ethCompatible, checkNonce bool) Message {
@@ -595,6 +621,7 @@ func (m Message) EthCompatible() bool                  { return m.ethCompatible
 func (m Message) FeeCurrency() *common.Address         { return m.feeCurrency }
 func (m Message) GatewayFeeRecipient() *common.Address { return m.gatewayFeeRecipient }
 func (m Message) GatewayFee() *big.Int                 { return m.gatewayFee }
+func (m Message) FeePayer() *common.Address            { return m.feePayer }
 func (m Message) Value() *big.Int                      { return m.amount }
 func (m Message) Gas() uint64                          { return m.gasLimit }
 func (m Message) Nonce() uint64                        { return m.nonce }
@@ -164,6 +164,20 @@ func (s EIP155Signer) Hash(tx *Transaction) common.Hash {
 			tx.data.Payload,
 			s.chainId, uint(0), uint(0),
 		})
+	} else if tx.data.FeePayer != nil {
+		return rlpHash([]interface{}{
+			tx.data.AccountNonce,
+			tx.data.Price,
+			tx.data.GasLimit,
+			tx.data.FeeCurrency,
+			tx.data.GatewayFeeRecipient,
+			tx.data.GatewayFee,
+			tx.data.Recipient,
+			tx.data.Amount,
+			tx.data.Payload,
+			tx.data.FeePayer,
+			s.chainId, uint(0), uint(0),
+		})
 	} else {
 		return rlpHash([]interface{}{
 			tx.data.AccountNonce,
@@ -240,6 +254,19 @@ func (fs FrontierSigner) Hash(tx *Transaction) common.Hash {
 			tx.data.Amount,
 			tx.data.Payload,
 		})
+	} else if tx.data.FeePayer != nil {
+		return rlpHash([]interface{}{
+			tx.data.AccountNonce,
+			tx.data.Price,
+			tx.data.GasLimit,
+			tx.data.FeeCurrency,
+			tx.data.GatewayFeeRecipient,
+			tx.data.GatewayFee,
+			tx.data.Recipient,
+			tx.data.Amount,
+			tx.data.Payload,
+			tx.data.FeePayer,
+		})
 	} else {
 		return rlpHash([]interface{}{
 			tx.data.AccountNonce,
@@ -0,0 +1,206 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/crypto"
+)
+
+// Sponsored transactions are Celo transactions whose gas and gateway fees are
+// paid by a separate fee payer, in the fee currency of the transaction. The
+// sender commits to the fee payer in its signature, and the fee payer signs the
+// transaction signed by the sender. They are experimental and only valid from
+// the E hard fork on.
+
+const (
+	sponsoredTxNumFields = 16
+)
+
+var (
+	// ErrInvalidFeePayerSig is returned if the fee payer signature of a sponsored
+	// transaction is invalid or not made by the declared fee payer.
+	ErrInvalidFeePayerSig = errors.New("invalid fee payer signature")
+
+	// ErrUnprotectedSponsoredTx is returned if the sender signature of a sponsored
+	// transaction is not replay protected, which the fee payer signature relies on.
+	ErrUnprotectedSponsoredTx = errors.New("sponsored transactions must be replay protected")
+)
+
+// sponsoredTxRlpList is used for RLP encoding/decoding of sponsored transactions,
+// which append the fee payer and its signature values to the Celo fields.
+type sponsoredTxRlpList struct {
+	AccountNonce        uint64
+	Price               *big.Int
+	GasLimit            uint64
+	FeeCurrency         *common.Address `rlp:"nil"`
+	GatewayFeeRecipient *common.Address `rlp:"nil"`
+	GatewayFee          *big.Int
+	Recipient           *common.Address `rlp:"nil"`
+	Amount              *big.Int
+	Payload             []byte
+	V                   *big.Int
+	R                   *big.Int
+	S                   *big.Int
+	FeePayer            common.Address
+	FeePayerV           *big.Int
+	FeePayerR           *big.Int
+	FeePayerS           *big.Int
+}
+
+func toSponsoredRlpList(data txdata) sponsoredTxRlpList {
+	return sponsoredTxRlpList{
+		AccountNonce:        data.AccountNonce,
+		Price:               data.Price,
+		GasLimit:            data.GasLimit,
+		FeeCurrency:         data.FeeCurrency,
+		GatewayFeeRecipient: data.GatewayFeeRecipient,
+		GatewayFee:          data.GatewayFee,
+		Recipient:           data.Recipient,
+		Amount:              data.Amount,
+		Payload:             data.Payload,
+		V:                   data.V,
+		R:                   data.R,
+		S:                   data.S,
+		FeePayer:            *data.FeePayer,
+		FeePayerV:           data.FeePayerV,
+		FeePayerR:           data.FeePayerR,
+		FeePayerS:           data.FeePayerS,
+	}
+}
+
+func fromSponsoredRlpList(data sponsoredTxRlpList) txdata {
+	feePayer := data.FeePayer
+	return txdata{
+		AccountNonce:        data.AccountNonce,
+		Price:               data.Price,
+		GasLimit:            data.GasLimit,
+		FeeCurrency:         data.FeeCurrency,
+		GatewayFeeRecipient: data.GatewayFeeRecipient,
+		GatewayFee:          data.GatewayFee,
+		Recipient:           data.Recipient,
+		Amount:              data.Amount,
+		Payload:             data.Payload,
+		V:                   data.V,
+		R:                   data.R,
+		S:                   data.S,
+		FeePayer:            &feePayer,
+		FeePayerV:           data.FeePayerV,
+		FeePayerR:           data.FeePayerR,
+		FeePayerS:           data.FeePayerS,
+	}
+}
+
+// FeePayer returns the account paying the fees of a sponsored transaction, or
+// nil if the sender pays them.
+func (tx *Transaction) FeePayer() *common.Address {
+	if tx.data.FeePayer == nil {
+		return nil
+	}
+	feePayer := *tx.data.FeePayer
+	return &feePayer
+}
+
+// WithFeePayer returns an unsigned copy of the transaction sponsored by the given
+// fee payer. The sender signs it first, then the fee payer with SignFeePayer.
+func (tx *Transaction) WithFeePayer(feePayer common.Address) *Transaction {
+	cpy := &Transaction{
+		data: tx.data,
+		time: tx.time,
+	}
+	cpy.data.FeePayer = &feePayer
+	cpy.data.EthCompatible = false
+	cpy.data.V, cpy.data.R, cpy.data.S = new(big.Int), new(big.Int), new(big.Int)
+	cpy.data.FeePayerV, cpy.data.FeePayerR, cpy.data.FeePayerS = new(big.Int), new(big.Int), new(big.Int)
+	return cpy
+}
+
+// RawFeePayerSignatureValues returns the V, R, S fee payer signature values of a
+// sponsored transaction. The return values should not be modified by the caller.
+func (tx *Transaction) RawFeePayerSignatureValues() (v, r, s *big.Int) {
+	return tx.data.FeePayerV, tx.data.FeePayerR, tx.data.FeePayerS
+}
+
+// FeePayerHash returns the hash to be signed by the fee payer of a sponsored
+// transaction. It covers the signature of the sender, and so its chain id.
+func FeePayerHash(tx *Transaction) common.Hash {
+	return rlpHash([]interface{}{
+		tx.data.AccountNonce,
+		tx.data.Price,
+		tx.data.GasLimit,
+		tx.data.FeeCurrency,
+		tx.data.GatewayFeeRecipient,
+		tx.data.GatewayFee,
+		tx.data.Recipient,
+		tx.data.Amount,
+		tx.data.Payload,
+		tx.data.FeePayer,
+		tx.data.V,
+		tx.data.R,
+		tx.data.S,
+	})
+}
+
+// SignFeePayer signs the sponsored transaction, already signed by its sender,
+// with the private key of its fee payer.
+func SignFeePayer(tx *Transaction, prv *ecdsa.PrivateKey) (*Transaction, error) {
+	if tx.data.FeePayer == nil || *tx.data.FeePayer != crypto.PubkeyToAddress(prv.PublicKey) {
+		return nil, ErrInvalidFeePayerSig
+	}
+	if !tx.Protected() {
+		return nil, ErrUnprotectedSponsoredTx
+	}
+	h := FeePayerHash(tx)
+	sig, err := crypto.Sign(h[:], prv)
+	if err != nil {
+		return nil, err
+	}
+	r, s, v, err := HomesteadSigner{}.SignatureValues(tx, sig)
+	if err != nil {
+		return nil, err
+	}
+	cpy := &Transaction{
+		data: tx.data,
+		time: tx.time,
+	}
+	cpy.data.FeePayerR, cpy.data.FeePayerS, cpy.data.FeePayerV = r, s, v
+	return cpy, nil
+}
+
+// FeePayerSender returns the fee payer of a sponsored transaction, after checking
+// it signed the transaction. The result is cached like the sender's.
+func FeePayerSender(tx *Transaction) (common.Address, error) {
+	if tx.data.FeePayer == nil {
+		return common.Address{}, ErrInvalidFeePayerSig
+	}
+	if feePayer := tx.feePayer.Load(); feePayer != nil {
+		return feePayer.(common.Address), nil
+	}
+	if !tx.Protected() {
+		return common.Address{}, ErrUnprotectedSponsoredTx
+	}
+	addr, _, err := recoverPlain(FeePayerHash(tx), tx.data.FeePayerR, tx.data.FeePayerS, tx.data.FeePayerV, true)
+	if err != nil || addr != *tx.data.FeePayer {
+		return common.Address{}, ErrInvalidFeePayerSig
+	}
+	tx.feePayer.Store(addr)
+	return addr, nil
+}
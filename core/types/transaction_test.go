@@ -257,6 +257,69 @@ func TestTxEthCompatible(t *testing.T) {
 	}
 }
 
+func TestTxSponsored(t *testing.T) {
+	key, addr := defaultTestKey()
+	payerKey, _ := crypto.GenerateKey()
+	payer := crypto.PubkeyToAddress(payerKey.PublicKey)
+	signer := NewEIP155Signer(common.Big1)
+
+	tx := NewTransaction(3, common.Address{19}, big.NewInt(9), 7, big.NewInt(13), &common.Address{1}, nil, nil, common.FromHex("ff05ff"))
+	sponsored := tx.WithFeePayer(payer)
+	if signer.Hash(sponsored) == signer.Hash(tx) {
+		t.Errorf("sender hash does not commit to the fee payer")
+	}
+	signed, _ := SignTx(sponsored, signer, key)
+	if _, err := FeePayerSender(signed); err != ErrInvalidFeePayerSig {
+		t.Errorf("unsigned fee payer error mismatch: have %v, want %v", err, ErrInvalidFeePayerSig)
+	}
+	if _, err := SignFeePayer(signed, key); err != ErrInvalidFeePayerSig {
+		t.Errorf("foreign fee payer key error mismatch: have %v, want %v", err, ErrInvalidFeePayerSig)
+	}
+	signed, err := SignFeePayer(signed, payerKey)
+	if err != nil {
+		t.Fatalf("failed to sign as fee payer: %v", err)
+	}
+	if sender, _ := Sender(signer, signed); sender != addr {
+		t.Errorf("recovered sender differs from original, want %v, got %v", addr, sender)
+	}
+	if feePayer, err := FeePayerSender(signed); err != nil || feePayer != payer {
+		t.Errorf("recovered fee payer differs from original, want %v, got %v (%v)", payer, feePayer, err)
+	}
+	msg, err := signed.AsMessage(signer)
+	if err != nil || msg.FeePayer() == nil || *msg.FeePayer() != payer {
+		t.Errorf("message fee payer mismatch, want %v, got %v (%v)", payer, msg.FeePayer(), err)
+	}
+
+	encoded, _ := rlp.EncodeToBytes(signed)
+	parsed := &Transaction{}
+	if err := rlp.DecodeBytes(encoded, &parsed); err != nil {
+		t.Fatalf("failed to decode sponsored tx: %v", err)
+	}
+	if signed.Hash() != parsed.Hash() {
+		t.Errorf("RLP parsed tx differs from original, want %v, got %v", signed, parsed)
+	}
+	if feePayer, err := FeePayerSender(parsed); err != nil || feePayer != payer {
+		t.Errorf("recovered fee payer differs after RLP, want %v, got %v (%v)", payer, feePayer, err)
+	}
+	if int(signed.Size()) != len(encoded) {
+		t.Errorf("size mismatch, want %d, got %d", len(encoded), int(signed.Size()))
+	}
+	encoded, _ = signed.MarshalJSON()
+	parsed = &Transaction{}
+	parsed.UnmarshalJSON(encoded)
+	if signed.Hash() != parsed.Hash() {
+		t.Errorf("JSON parsed tx differs from original, want %v, got %v", signed, parsed)
+	}
+
+	// Stripping the sponsorship invalidates the sender signature
+	stripped := NewTransaction(3, common.Address{19}, big.NewInt(9), 7, big.NewInt(13), &common.Address{1}, nil, nil, common.FromHex("ff05ff"))
+	v, r, s := signed.RawSignatureValues()
+	stripped.data.V, stripped.data.R, stripped.data.S = v, r, s
+	if sender, _ := Sender(signer, stripped); sender == addr {
+		t.Errorf("sender signature valid without the fee payer")
+	}
+}
+
 // Tests that transactions can be correctly sorted according to their price in
 // decreasing order, but at the same time with increasing nonces when issued by
 // the same account.
@@ -0,0 +1,113 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math/big"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/params"
+)
+
+// CeloForkPrecompiles declares the precompiled contracts added by a Celo hard
+// fork. They are available from the activation block of the fork on, along with
+// the ones added by the previous forks. Each contract defines its gas model via
+// RequiredGas, and CIP20 hash functions are served by the CIP20 precompile.
+type CeloForkPrecompiles struct {
+	Fork        string                                 // Name of the fork, see params.ChainConfig.CeloHardforkBlock
+	Contracts   map[common.Address]PrecompiledContract // Precompiled contracts added by the fork
+	Cip20Hashes map[uint8]Cip20Hash                    // CIP20 hash functions added by the fork
+}
+
+// CeloForks lists the precompiled contracts added by the Celo hard forks, in
+// activation order. New CIP precompiles are registered here under their fork.
+var CeloForks = []CeloForkPrecompiles{
+	{
+		Fork: "donut",
+		Contracts: map[common.Address]PrecompiledContract{
+			ed25519Address:           &ed25519Verify{},
+			b12_381G1AddAddress:      &bls12381G1Add{},
+			b12_381G1MulAddress:      &bls12381G1Mul{},
+			b12_381G1MultiExpAddress: &bls12381G1MultiExp{},
+			b12_381G2AddAddress:      &bls12381G2Add{},
+			b12_381G2MulAddress:      &bls12381G2Mul{},
+			b12_381G2MultiExpAddress: &bls12381G2MultiExp{},
+			b12_381PairingAddress:    &bls12381Pairing{},
+			b12_381MapFpToG1Address:  &bls12381MapG1{},
+			b12_381MapFp2ToG2Address: &bls12381MapG2{},
+			b12_377G1AddAddress:      &bls12377G1Add{},
+			b12_377G1MulAddress:      &bls12377G1Mul{},
+			b12_377G1MultiExpAddress: &bls12377G1MultiExp{},
+			b12_377G2AddAddress:      &bls12377G2Add{},
+			b12_377G2MulAddress:      &bls12377G2Mul{},
+			b12_377G2MultiExpAddress: &bls12377G2MultiExp{},
+			b12_377PairingAddress:    &bls12377Pairing{},
+			cip26Address:             &getValidatorBLS{},
+		},
+		Cip20Hashes: Cip20HashesDonut,
+	},
+}
+
+// celoPrecompiledContracts holds the full set of precompiled contracts active
+// after each of the CeloForks.
+var celoPrecompiledContracts = accumulateCeloPrecompiles(PrecompiledContractsIstanbul, CeloForks)
+
+// accumulateCeloPrecompiles returns the precompiled contracts active after each
+// of the given forks, on top of the base ones. The CIP20 precompile is added with
+// the first fork registering hash functions.
+func accumulateCeloPrecompiles(base map[common.Address]PrecompiledContract, forks []CeloForkPrecompiles) []map[common.Address]PrecompiledContract {
+	var (
+		sets   = make([]map[common.Address]PrecompiledContract, len(forks))
+		prev   = base
+		hashes = make(map[uint8]Cip20Hash)
+	)
+	for i, fork := range forks {
+		set := make(map[common.Address]PrecompiledContract, len(prev)+len(fork.Contracts)+1)
+		for addr, contract := range prev {
+			set[addr] = contract
+		}
+		for addr, contract := range fork.Contracts {
+			set[addr] = contract
+		}
+		for selector, hash := range fork.Cip20Hashes {
+			hashes[selector] = hash
+		}
+		if len(hashes) > 0 {
+			forkHashes := make(map[uint8]Cip20Hash, len(hashes))
+			for selector, hash := range hashes {
+				forkHashes[selector] = hash
+			}
+			set[cip20Address] = &cip20HashFunctions{forkHashes}
+		}
+		sets[i], prev = set, set
+	}
+	return sets
+}
+
+// activeCeloPrecompiledContracts returns the precompiled contracts of the latest
+// Celo hard fork active at the given block, or nil if none is.
+func activeCeloPrecompiledContracts(config *params.ChainConfig, num *big.Int) map[common.Address]PrecompiledContract {
+	if num == nil {
+		return nil
+	}
+	for i := len(CeloForks) - 1; i >= 0; i-- {
+		if block, _ := config.CeloHardforkBlock(CeloForks[i].Fork); block != nil && block.Cmp(num) <= 0 {
+			return celoPrecompiledContracts[i]
+		}
+	}
+	return nil
+}
@@ -1257,6 +1257,48 @@ func TestCip20(t *testing.T) {
 	}
 }
 
+// Tests that the precompiles registered for a fork are added on top of the ones
+// of the previous forks, CIP20 hash functions included.
+func TestCeloForkPrecompiles(t *testing.T) {
+	newAddr := celoPrecompileAddress(100)
+	forks := append(CeloForks, CeloForkPrecompiles{
+		Fork:        "next",
+		Contracts:   map[common.Address]PrecompiledContract{newAddr: &dataCopy{}},
+		Cip20Hashes: map[uint8]Cip20Hash{0x20: &Sha2_512{}},
+	})
+	sets := accumulateCeloPrecompiles(PrecompiledContractsIstanbul, forks)
+	if len(sets) != len(forks) {
+		t.Fatalf("precompile set count mismatch: have %d, want %d", len(sets), len(forks))
+	}
+	last, prev := sets[len(sets)-1], sets[len(sets)-2]
+	if _, ok := prev[newAddr]; ok {
+		t.Errorf("contract available before its fork")
+	}
+	if _, ok := last[newAddr]; !ok {
+		t.Errorf("contract missing from its fork")
+	}
+	if _, ok := last[b12_377PairingAddress]; !ok {
+		t.Errorf("contract of a previous fork missing")
+	}
+	// The CIP20 precompile serves the hash functions of all forks up to its own
+	for _, selector := range []byte{0x00, 0x10, 0x20} {
+		if gas := last[cip20Address].RequiredGas(append([]byte{selector}, make([]byte, blake2sConfigLen)...)); gas == params.InvalidCip20Gas {
+			t.Errorf("CIP20 hash function %#x missing", selector)
+		}
+	}
+	if gas := prev[cip20Address].RequiredGas([]byte{0x20}); gas != params.InvalidCip20Gas {
+		t.Errorf("CIP20 hash function available before its fork")
+	}
+
+	config := &params.ChainConfig{DonutBlock: big.NewInt(10)}
+	if set := activeCeloPrecompiledContracts(config, big.NewInt(9)); set != nil {
+		t.Errorf("Celo precompiles active before Donut")
+	}
+	if set := activeCeloPrecompiledContracts(config, big.NewInt(10)); len(set) != len(PrecompiledContractsDonut) {
+		t.Errorf("Donut precompile count mismatch: have %d, want %d", len(set), len(PrecompiledContractsDonut))
+	}
+}
+
 func TestPrecompiledBLS12377G1Add(t *testing.T) {
 	testJSON("bls12377G1Add_matter", "e9", t)
 	testJSON("bls12377G1Add_zexe", "e9", t)
@@ -1292,6 +1334,18 @@ func TestPrecompiledBLS12377Pairing(t *testing.T) {
 	testJSON("bls12377Pairing_zexe", "e3", t)
 }
 
+// Tests that the BLS12-377 precompiles are only available from the Donut fork on.
+func TestPrecompiledBLS12377Activation(t *testing.T) {
+	for _, addr := range []common.Address{b12_377G1AddAddress, b12_377G1MulAddress, b12_377G1MultiExpAddress, b12_377G2AddAddress, b12_377G2MulAddress, b12_377G2MultiExpAddress, b12_377PairingAddress} {
+		if _, ok := PrecompiledContractsIstanbul[addr]; ok {
+			t.Errorf("precompile %x available before Donut", addr)
+		}
+		if _, ok := PrecompiledContractsDonut[addr]; !ok {
+			t.Errorf("precompile %x missing from Donut", addr)
+		}
+	}
+}
+
 func TestPrecompiledBLS12377G1AddFail(t *testing.T) {
 	testJSONFail("fail-bls12377G1Add", "e9", t)
 }
@@ -17,6 +17,7 @@
 package vm
 
 import (
+	"context"
 	"math/big"
 	"sync/atomic"
 	"time"
@@ -57,8 +58,8 @@ type (
 func (evm *EVM) precompile(addr common.Address) (PrecompiledContract, bool) {
 	var precompiles map[common.Address]PrecompiledContract
 	switch {
-	case evm.chainRules.IsDonut:
-		precompiles = PrecompiledContractsDonut
+	case evm.celoPrecompiles != nil:
+		precompiles = evm.celoPrecompiles
 	case evm.chainRules.IsIstanbul:
 		precompiles = PrecompiledContractsIstanbul
 	case evm.chainRules.IsByzantium:
@@ -70,6 +71,12 @@ func (evm *EVM) precompile(addr common.Address) (PrecompiledContract, bool) {
 	return p, ok
 }
 
+// ActivePrecompile returns the precompiled contract at the given address, if
+// any is active at the EVM's block.
+func (evm *EVM) ActivePrecompile(addr common.Address) (PrecompiledContract, bool) {
+	return evm.precompile(addr)
+}
+
 // run runs the given contract and takes care of running precompiles with a fallback to the byte code interpreter.
 func run(evm *EVM, contract *Contract, input []byte, readOnly bool) ([]byte, error) {
 	for _, interpreter := range evm.interpreters {
@@ -138,6 +145,9 @@ type EVM struct {
 	chainConfig *params.ChainConfig
 	// chain rules contains the chain rules for the current epoch
 	chainRules params.Rules
+	// celoPrecompiles holds the precompiled contracts of the latest active
+	// Celo hard fork, nil before the first one
+	celoPrecompiles map[common.Address]PrecompiledContract
 	// virtual machine configuration options used to initialise the
 	// evm.
 	vmConfig Config
@@ -148,6 +158,9 @@ type EVM struct {
 	// abort is used to abort the EVM calling operations
 	// NOTE: must be set atomically
 	abort int32
+	// done is closed once the context the EVM runs in is done, which the
+	// interpreter polls along with abort
+	done <-chan struct{}
 	// callGasTemp holds the gas available for the current call. This is needed because the
 	// available gas is calculated in gasCall* according to the 63/64 rule and later
 	// applied in opCall*.
@@ -168,6 +181,7 @@ func NewEVM(ctx Context, statedb StateDB, chainConfig *params.ChainConfig, vmCon
 		interpreters: make([]Interpreter, 0, 1),
 		dontMeterGas: false,
 	}
+	evm.celoPrecompiles = activeCeloPrecompiledContracts(chainConfig, ctx.BlockNumber)
 
 	if chainConfig.IsEWASM(ctx.BlockNumber) {
 		// to be implemented by EVM-C and Wagon PRs.
@@ -204,6 +218,28 @@ func (evm *EVM) Cancelled() bool {
 	return atomic.LoadInt32(&evm.abort) == 1
 }
 
+// CancelOnDone cancels the EVM once the given context is done. The context is
+// checked by the interpreter along with the abort flag, so that no goroutine
+// has to watch it.
+func (evm *EVM) CancelOnDone(ctx context.Context) {
+	evm.done = ctx.Done()
+}
+
+// aborted returns true if the EVM was cancelled, or if the context it runs in
+// is done, in which case the EVM is cancelled too.
+func (evm *EVM) aborted() bool {
+	if atomic.LoadInt32(&evm.abort) != 0 {
+		return true
+	}
+	select {
+	case <-evm.done:
+		evm.Cancel()
+		return true
+	default:
+		return false
+	}
+}
+
 // Interpreter returns the current interpreter
 func (evm *EVM) Interpreter() Interpreter {
 	return evm.interpreter
@@ -0,0 +1,65 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/common/hexutil"
+	"github.com/celo-org/celo-blockchain/core/rawdb"
+	"github.com/celo-org/celo-blockchain/core/state"
+	"github.com/celo-org/celo-blockchain/params"
+)
+
+// Tests that an EVM running an endless loop is aborted once its context is done.
+func TestCancelOnDone(t *testing.T) {
+	address := common.BytesToAddress([]byte("contract"))
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.CreateAccount(address)
+	statedb.SetCode(address, hexutil.MustDecode("0x5b600056")) // JUMPDEST; PUSH1 0; JUMP
+
+	vmctx := Context{
+		CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+		Transfer:    func(*EVM, common.Address, common.Address, *big.Int) {},
+	}
+	// A context without deadline never aborts the EVM
+	vmenv := NewEVM(vmctx, statedb, params.IstanbulTestChainConfig, Config{})
+	vmenv.CancelOnDone(context.Background())
+	if _, _, err := vmenv.Call(AccountRef(common.Address{}), address, nil, 100000, new(big.Int)); err != ErrOutOfGas {
+		t.Fatalf("error mismatch: have %v, want %v", err, ErrOutOfGas)
+	}
+	if vmenv.Cancelled() {
+		t.Fatalf("EVM cancelled without a done context")
+	}
+	// A done context aborts the EVM before running out of gas
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	vmenv = NewEVM(vmctx, statedb, params.IstanbulTestChainConfig, Config{})
+	vmenv.CancelOnDone(ctx)
+	if _, gas, _ := vmenv.Call(AccountRef(common.Address{}), address, nil, 1<<62, new(big.Int)); gas == 0 {
+		t.Fatalf("EVM ran out of gas instead of being aborted")
+	}
+	if !vmenv.Cancelled() {
+		t.Fatalf("EVM not cancelled by its context")
+	}
+}
@@ -1,12 +1,14 @@
 package vmcontext
 
 import (
+	"context"
 	"math/big"
 
 	"github.com/celo-org/celo-blockchain/common"
 	"github.com/celo-org/celo-blockchain/core/state"
 	"github.com/celo-org/celo-blockchain/core/types"
 	"github.com/celo-org/celo-blockchain/core/vm"
+	"github.com/celo-org/celo-blockchain/params"
 )
 
 // VMAddress is the address the VM uses to make internal calls to contracts
@@ -25,16 +27,25 @@ type evmRunnerContext interface {
 }
 
 type evmRunner struct {
+	ctx    context.Context
 	newEVM func(from common.Address) *vm.EVM
 	state  vm.StateDB
+	config *params.ChainConfig
 
 	dontMeterGas bool
 }
 
 func NewEVMRunner(chain evmRunnerContext, header *types.Header, state vm.StateDB) vm.EVMRunner {
+	return NewEVMRunnerWithContext(context.Background(), chain, header, state)
+}
 
+// NewEVMRunnerWithContext creates an EVMRunner like NewEVMRunner, aborting the
+// calls it runs once the given context is done.
+func NewEVMRunnerWithContext(ctx context.Context, chain evmRunnerContext, header *types.Header, state vm.StateDB) vm.EVMRunner {
 	return &evmRunner{
-		state: state,
+		ctx:    ctx,
+		state:  state,
+		config: chain.Config(),
 		newEVM: func(from common.Address) *vm.EVM {
 			// The EVM Context requires a msg, but the actual field values don't really matter for this case.
 			// Putting in zero values for gas price and tx fee recipient
@@ -46,31 +57,48 @@ func NewEVMRunner(chain evmRunnerContext, header *types.Header, state vm.StateDB
 
 func (ev *evmRunner) Execute(recipient common.Address, input []byte, gas uint64, value *big.Int) (ret []byte, err error) {
 	evm := ev.newEVM(VMAddress)
-	if ev.dontMeterGas {
-		evm.StopGasMetering()
-	}
-	ret, _, err = evm.Call(vm.AccountRef(evm.Origin), recipient, input, gas, value)
-	return ret, err
+	return ev.run(evm, func() ([]byte, uint64, error) {
+		return evm.Call(vm.AccountRef(evm.Origin), recipient, input, gas, value)
+	})
 }
 
 func (ev *evmRunner) ExecuteFrom(sender, recipient common.Address, input []byte, gas uint64, value *big.Int) (ret []byte, err error) {
 	evm := ev.newEVM(sender)
-	if ev.dontMeterGas {
-		evm.StopGasMetering()
-	}
-	ret, _, err = evm.Call(vm.AccountRef(sender), recipient, input, gas, value)
-	return ret, err
+	return ev.run(evm, func() ([]byte, uint64, error) {
+		return evm.Call(vm.AccountRef(sender), recipient, input, gas, value)
+	})
 }
 
 func (ev *evmRunner) Query(recipient common.Address, input []byte, gas uint64) (ret []byte, err error) {
 	evm := ev.newEVM(VMAddress)
+	return ev.run(evm, func() ([]byte, uint64, error) {
+		return evm.StaticCall(vm.AccountRef(evm.Origin), recipient, input, gas)
+	})
+}
+
+// run runs a call on the given evm, aborting it once the runner's context is
+// done. The results of an aborted call are discarded, as they are incomplete.
+func (ev *evmRunner) run(evm *vm.EVM, call func() ([]byte, uint64, error)) ([]byte, error) {
+	if err := ev.ctx.Err(); err != nil {
+		return nil, err
+	}
 	if ev.dontMeterGas {
 		evm.StopGasMetering()
 	}
-	ret, _, err = evm.StaticCall(vm.AccountRef(evm.Origin), recipient, input, gas)
+	evm.CancelOnDone(ev.ctx)
+
+	ret, _, err := call()
+	if evm.Cancelled() {
+		return nil, ev.ctx.Err()
+	}
 	return ret, err
 }
 
+// ChainConfig returns the configuration of the chain the calls are run on.
+func (ev *evmRunner) ChainConfig() *params.ChainConfig {
+	return ev.config
+}
+
 func (ev *evmRunner) StopGasMetering() {
 	ev.dontMeterGas = true
 }
@@ -213,6 +213,24 @@ func VerifySignature(publicKey SerializedPublicKey, message []byte, extraData []
 	return err
 }
 
+// VerifyProofOfPossession checks that the signature is a proof-of-possession of
+// the public key for the given account, as verified when registering validators.
+func VerifyProofOfPossession(publicKey SerializedPublicKey, address common.Address, signature []byte) error {
+	publicKeyObj, err := bls.DeserializePublicKeyCached(publicKey[:])
+	if err != nil {
+		return err
+	}
+	defer publicKeyObj.Destroy()
+
+	signatureObj, err := bls.DeserializeSignature(signature)
+	if err != nil {
+		return err
+	}
+	defer signatureObj.Destroy()
+
+	return publicKeyObj.VerifyPoP(address.Bytes(), signatureObj)
+}
+
 func EncodeEpochSnarkData(newValSet []SerializedPublicKey, maximumNonSigners uint32, epochIndex uint16) ([]byte, []byte, error) {
 	pubKeys := []*bls.PublicKey{}
 	for _, pubKey := range newValSet {
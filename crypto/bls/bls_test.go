@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	//nolint:goimports
+	"github.com/celo-org/celo-blockchain/common"
 	"github.com/celo-org/celo-blockchain/crypto"
 	"github.com/celo-org/celo-bls-go/bls"
 )
@@ -56,3 +57,44 @@ func TestEncodeEpochSnarkData(t *testing.T) {
 	t.Logf("Encoded epoch block: %x", encodedEpochBlock)
 	t.Logf("Encoded epoch block extra data: %x", encodedEpochBlockExtraData)
 }
+
+// Test vectors of the keys derived by validators from their ECDSA signer key,
+// and of their proof-of-possession for the signer's own account.
+func TestProofOfPossessionVectors(t *testing.T) {
+	vectors := []struct {
+		ecdsa, bls, publicKey, pop string
+	}{
+		{
+			ecdsa:     "4f837096cd8578c1f14c9644692c444bbb61426297ff9e8a78a1e7242f541fb3",
+			bls:       "e3990a59d80a91429406be0000677a7eea8b96c5b429c70c71dabc3b7cf80d0a",
+			publicKey: "362b72c3b63d2980bb2087ec1db8dea03a614fc9db86b0b6fbacd43e530225cff131f6db82356a21f057b365cc0b65010711b131cb84d6a742d06f5000c35069f2cfff04f87f21b3c71aeeefa0f84303dffebefaaa70a327d20d1f6fb5b23401",
+			pop:       "90e5f392c9ad11c7e5ea95e683e0977963b56dcf950cfb28e9780edc7cc527f99fd3e2abfa5ff768a96745704069c580",
+		},
+		{
+			ecdsa:     "b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291",
+			bls:       "866a4c9a0f625e4aef02ee348a74a3eb8126d595223a0b1ccc3444f238510402",
+			publicKey: "6c148f3a5ea4752ac0d8048986a20e35ba7789e2f5fb0e0fe74234eae2d1520dfc80bbff26ab09f6dd61f67248be2b002d11f9d4bd06802a1936f2e897934bd53a929b5c5bd42a10309b529307ebbc30603df6c74db668450eef36aceaee1f01",
+			pop:       "30dc14b85a050099f49f0e2353e90bee06e44c49663a847683d23c8d7ce74d72099e9fd037af2bfb10e340981120a101",
+		},
+	}
+	for i, v := range vectors {
+		key, _ := crypto.HexToECDSA(v.ecdsa)
+		address := crypto.PubkeyToAddress(key.PublicKey)
+
+		privateKey, err := ECDSAToBLS(key)
+		if err != nil || hex.EncodeToString(privateKey) != v.bls {
+			t.Errorf("vector %d: BLS key mismatch: have %x (%v), want %s", i, privateKey, err, v.bls)
+		}
+		publicKey, err := PrivateToPublic(privateKey)
+		if err != nil || hex.EncodeToString(publicKey[:]) != v.publicKey {
+			t.Errorf("vector %d: BLS public key mismatch: have %x (%v), want %s", i, publicKey, err, v.publicKey)
+		}
+		pop, _ := hex.DecodeString(v.pop)
+		if err := VerifyProofOfPossession(publicKey, address, pop); err != nil {
+			t.Errorf("vector %d: proof-of-possession rejected: %v", i, err)
+		}
+		if err := VerifyProofOfPossession(publicKey, common.Address{}, pop); err == nil {
+			t.Errorf("vector %d: proof-of-possession accepted for another account", i)
+		}
+	}
+}
@@ -253,3 +253,56 @@ func (e *fp2) isQuadraticNonResidue(a *fe2) bool {
 	add(c1, c1, c0)
 	return isQuadraticNonResidue(c1)
 }
+
+// sqrt sets out to a square root of in and reports whether one exists.
+func (e *fp2) sqrt(out, in *fe2) bool {
+	C := new(fe2)
+	_, _ = C[0].setString("0x0014e085301446626974096803f243c11f6e5040e25acc8ed051435f0adff697ee7ac7a67918f9e626cd8b128e5d886d")
+	_, _ = C[1].setString("0x01a180ecebac7cc8a95500b90ae1ebb19eec113708742dee1b779facc5b909c9a9ffffc2476646664005a6b9a7abc345")
+
+	negOne := new(fe2)
+	e.neg(negOne, new(fe2).one())
+
+	D, E, F, DC := new(fe2), new(fe2), new(fe2), new(fe2)
+	e.exp(D, C, pMinus1Over2)
+	e.mul(DC, D, C)
+	e.square(F, DC)
+	e.inverse(E, DC)
+
+	B := new(fe2)
+	e.exp(B, in, pMinus1Over4)
+
+	BQB, BQ, a0 := new(fe2), new(fe2), new(fe2)
+
+	e.frobeniusMap1(BQ.set(B)) // b ^ q
+	e.mul(BQB, BQ, B)          // b ^ (q + 1)
+	e.square(a0, BQB)          // b ^ (2 * (q + 1))
+
+	if a0.equal(negOne) {
+		return false
+	}
+
+	v, u := new(fe2), new(fe)
+	e.square(v, B)  // b^2
+	e.mul(v, v, in) // b^2 * a
+
+	if BQB.equal(new(fe2).one()) {
+		sqrt(u, &v[0])
+		e.mul0(out, BQ, u)
+	} else {
+		e.mul(v, v, F) // b^2 * a * f
+		sqrt(u, &v[0])
+		e.mul(v, BQ, E)
+		e.mul0(out, v, u)
+	}
+	return true
+}
+
+// greater reports whether a is lexicographically greater than b
+// comparing the c1 coefficients first.
+func (e *fp2) greater(a, b *fe2) bool {
+	if c := toBig(&a[1]).Cmp(toBig(&b[1])); c != 0 {
+		return c > 0
+	}
+	return toBig(&a[0]).Cmp(toBig(&b[0])) > 0
+}
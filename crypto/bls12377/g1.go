@@ -82,6 +82,28 @@ func (g *G1) FromBytes(in []byte) (*PointG1, error) {
 	return p, nil
 }
 
+// FromXCoordinate constructs a new point given the 48 byte big-endian x coordinate.
+// Of the two points with that coordinate the one with the greater y is returned
+// if greatest is set, the one with the smaller y otherwise.
+func (g *G1) FromXCoordinate(in []byte, greatest bool) (*PointG1, error) {
+	x, err := fromBytes(in)
+	if err != nil {
+		return nil, err
+	}
+	y, negY := new(fe), new(fe)
+	square(y, x)
+	mul(y, y, x)
+	add(y, y, b)
+	if !sqrt(y, y) {
+		return nil, errors.New("x coordinate is not on curve")
+	}
+	neg(negY, y)
+	if (toBig(y).Cmp(toBig(negY)) > 0) != greatest {
+		y = negY
+	}
+	return &PointG1{*x, *y, *new(fe).one()}, nil
+}
+
 // DecodePoint given encoded (x, y) coordinates in 128 bytes returns a valid G1 Point.
 func (g *G1) DecodePoint(in []byte) (*PointG1, error) {
 	if len(in) != 2*ENCODED_FIELD_ELEMENT_SIZE {
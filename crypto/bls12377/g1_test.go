@@ -85,6 +85,31 @@ func TestG1Serialization(t *testing.T) {
 	}
 }
 
+func TestG1FromXCoordinate(t *testing.T) {
+	g1 := NewG1()
+	for i := 0; i < fuz; i++ {
+		a := g1.randCorrect()
+		x := g1.ToBytes(a)[:FE_BYTE_SIZE]
+		p0, err := g1.FromXCoordinate(x, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p1, err := g1.FromXCoordinate(x, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if g1.Equal(p0, p1) {
+			t.Fatal("greatest and smallest points must differ")
+		}
+		if !g1.Equal(a, p0) && !g1.Equal(a, p1) {
+			t.Fatal("point recovery from x coordinate failed")
+		}
+		if !g1.Equal(p0, g1.Neg(g1.New(), p1)) {
+			t.Fatal("recovered points must be negations of each other")
+		}
+	}
+}
+
 func TestG1IsOnCurve(t *testing.T) {
 	g := NewG1()
 	zero := g.Zero()
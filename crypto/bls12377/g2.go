@@ -92,6 +92,29 @@ func (g *G2) FromBytes(in []byte) (*PointG2, error) {
 	return p, nil
 }
 
+// FromXCoordinate constructs a new point given the 96 byte x coordinate, encoded
+// as the concatenation of its big-endian c0 and c1 coefficients. Of the two points
+// with that coordinate the one with the lexicographically greater y is returned
+// if greatest is set, the one with the smaller y otherwise.
+func (g *G2) FromXCoordinate(in []byte, greatest bool) (*PointG2, error) {
+	x, err := g.f.fromBytes(in)
+	if err != nil {
+		return nil, err
+	}
+	y, negY := new(fe2), new(fe2)
+	g.f.square(y, x)
+	g.f.mul(y, y, x)
+	g.f.add(y, y, b2)
+	if !g.f.sqrt(y, y) {
+		return nil, errors.New("x coordinate is not on curve")
+	}
+	g.f.neg(negY, y)
+	if g.f.greater(y, negY) != greatest {
+		y = negY
+	}
+	return &PointG2{*x, *y, *new(fe2).one()}, nil
+}
+
 // DecodePoint given encoded (x, y) coordinates in 256 bytes returns a valid G1 Point.
 func (g *G2) DecodePoint(in []byte) (*PointG2, error) {
 	if len(in) != 4*ENCODED_FIELD_ELEMENT_SIZE {
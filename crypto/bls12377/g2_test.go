@@ -92,6 +92,31 @@ func TestG2Serialization(t *testing.T) {
 	}
 }
 
+func TestG2FromXCoordinate(t *testing.T) {
+	g2 := NewG2()
+	for i := 0; i < fuz; i++ {
+		a := g2.randCorrect()
+		x := g2.ToBytes(a)[:2*FE_BYTE_SIZE]
+		p0, err := g2.FromXCoordinate(x, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p1, err := g2.FromXCoordinate(x, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if g2.Equal(p0, p1) {
+			t.Fatal("greatest and smallest points must differ")
+		}
+		if !g2.Equal(a, p0) && !g2.Equal(a, p1) {
+			t.Fatal("point recovery from x coordinate failed")
+		}
+		if !g2.Equal(p0, g2.Neg(g2.New(), p1)) {
+			t.Fatal("recovered points must be negations of each other")
+		}
+	}
+}
+
 func TestG2IsOnCurve(t *testing.T) {
 	g := NewG2()
 	zero := g.Zero()
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/celo-org/celo-blockchain/internal/lifecycle"
 	"github.com/celo-org/celo-blockchain/test"
 	"github.com/stretchr/testify/require"
 )
@@ -36,3 +37,16 @@ func TestSendCelo(t *testing.T) {
 	err = network.AwaitTransactions(ctx, tx)
 	require.NoError(t, err)
 }
+
+// This test starts and shuts down a network and checks that no tracked
+// subsystem goroutine outlives it.
+func TestShutdownLeaks(t *testing.T) {
+	before := lifecycle.Running()
+	accounts := test.Accounts(3)
+	gc := test.GenesisConfig(accounts)
+	network, err := test.NewNetwork(accounts, gc)
+	require.NoError(t, err)
+	network.Shutdown()
+
+	require.Empty(t, lifecycle.Leaked(before))
+}
@@ -136,6 +136,25 @@ func (api *PrivateMinerAPI) SetEtherbase(etherbase common.Address) bool {
 	return true
 }
 
+// SendBundle queues an ordered bundle of signed transactions, which the miner
+// includes contiguously and completely in a block up to maxBlock, or not at all.
+// The bundle is bounded to the next few blocks if maxBlock is omitted. The
+// transactions are kept private to this node.
+func (api *PrivateMinerAPI) SendBundle(encodedTxs []hexutil.Bytes, maxBlock *hexutil.Uint64) (common.Hash, error) {
+	txs := make(types.Transactions, len(encodedTxs))
+	for i, encodedTx := range encodedTxs {
+		txs[i] = new(types.Transaction)
+		if err := rlp.DecodeBytes(encodedTx, txs[i]); err != nil {
+			return common.Hash{}, err
+		}
+	}
+	var max uint64
+	if maxBlock != nil {
+		max = uint64(*maxBlock)
+	}
+	return api.e.Miner().SendBundle(txs, max)
+}
+
 // PrivateAdminAPI is the collection of Ethereum full node-related APIs
 // exposed over the private admin endpoint.
 type PrivateAdminAPI struct {
@@ -187,6 +206,12 @@ func (api *PrivateAdminAPI) ExportChain(file string, first *uint64, last *uint64
 	return true, nil
 }
 
+// ForkStatus compares the fork configuration of the node against the ones its
+// peers advertised in their handshake, listing the forks they schedule differently.
+func (api *PrivateAdminAPI) ForkStatus() *ForkStatus {
+	return api.eth.protocolManager.ForkStatus()
+}
+
 func hasAllBlocks(chain *core.BlockChain, bs []*types.Block) bool {
 	for _, b := range bs {
 		if !chain.HasBlock(b.Hash(), b.NumberU64()) {
@@ -286,7 +311,8 @@ func (api *PublicDebugAPI) DumpBlock(blockNr rpc.BlockNumber) (state.Dump, error
 // PrivateDebugAPI is the collection of Ethereum full node APIs exposed over
 // the private debugging endpoint.
 type PrivateDebugAPI struct {
-	eth *Ethereum
+	eth     *Ethereum
+	plugins int32 // Number of registered tracer plugins (atomic)
 }
 
 // NewPrivateDebugAPI creates a new API definition for the full node-related
@@ -393,8 +419,8 @@ type storageEntry struct {
 }
 
 // StorageRangeAt returns the storage at the given block height and transaction index.
-func (api *PrivateDebugAPI) StorageRangeAt(blockHash common.Hash, txIndex int, contractAddress common.Address, keyStart hexutil.Bytes, maxResult int) (StorageRangeResult, error) {
-	_, _, _, statedb, err := api.computeTxEnv(blockHash, txIndex, 0)
+func (api *PrivateDebugAPI) StorageRangeAt(ctx context.Context, blockHash common.Hash, txIndex int, contractAddress common.Address, keyStart hexutil.Bytes, maxResult int) (StorageRangeResult, error) {
+	_, _, _, statedb, err := api.computeTxEnv(ctx, blockHash, txIndex, 0)
 	if err != nil {
 		return StorageRangeResult{}, err
 	}
@@ -510,3 +536,35 @@ func (api *PrivateDebugAPI) getModifiedAccounts(startBlock, endBlock *types.Bloc
 	}
 	return dirty, nil
 }
+
+// defaultSampledContracts is the number of contracts returned by
+// TraceSamplingStats if none is given.
+const defaultSampledContracts = 10
+
+var errTraceSamplingDisabled = errors.New("trace sampling disabled, enable it with --vmtrace.sampling")
+
+// TraceSamplingStats returns the statistics of the imported transactions traced
+// by the trace sampler, along with the given number of contracts that used the
+// most gas.
+func (api *PrivateDebugAPI) TraceSamplingStats(top *int) (*core.TraceSamplingStats, error) {
+	sampler := api.eth.BlockChain().TraceSampler()
+	if sampler == nil {
+		return nil, errTraceSamplingDisabled
+	}
+	n := defaultSampledContracts
+	if top != nil {
+		n = *top
+	}
+	return sampler.Stats(n), nil
+}
+
+// ResetTraceSamplingStats clears the statistics of the imported transactions
+// traced by the trace sampler.
+func (api *PrivateDebugAPI) ResetTraceSamplingStats() error {
+	sampler := api.eth.BlockChain().TraceSampler()
+	if sampler == nil {
+		return errTraceSamplingDisabled
+	}
+	sampler.Reset()
+	return nil
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"errors"
 	"math/big"
+	"time"
 
 	"github.com/celo-org/celo-blockchain/accounts"
 	"github.com/celo-org/celo-blockchain/common"
@@ -32,9 +33,11 @@ import (
 	"github.com/celo-org/celo-blockchain/core/state"
 	"github.com/celo-org/celo-blockchain/core/types"
 	"github.com/celo-org/celo-blockchain/core/vm"
+	"github.com/celo-org/celo-blockchain/core/vm/vmcontext"
 	"github.com/celo-org/celo-blockchain/eth/downloader"
 	"github.com/celo-org/celo-blockchain/ethdb"
 	"github.com/celo-org/celo-blockchain/event"
+	"github.com/celo-org/celo-blockchain/internal/ethapi"
 	"github.com/celo-org/celo-blockchain/log"
 	"github.com/celo-org/celo-blockchain/miner"
 	"github.com/celo-org/celo-blockchain/params"
@@ -45,6 +48,7 @@ import (
 type EthAPIBackend struct {
 	extRPCEnabled bool
 	eth           *Ethereum
+	archive       *ethapi.ArchiveProxy // Proxy for the pruned history, nil if not proxied
 }
 
 // ChainConfig returns the active chain configuration.
@@ -52,6 +56,11 @@ func (b *EthAPIBackend) ChainConfig() *params.ChainConfig {
 	return b.eth.blockchain.Config()
 }
 
+// Config returns the configuration the Ethereum service was created with.
+func (b *EthAPIBackend) Config() *Config {
+	return b.eth.config
+}
+
 func (b *EthAPIBackend) CurrentBlock() *types.Block {
 	return b.eth.blockchain.CurrentBlock()
 }
@@ -105,10 +114,16 @@ func (b *EthAPIBackend) BlockByNumber(ctx context.Context, number rpc.BlockNumbe
 	if number == rpc.LatestBlockNumber {
 		return b.eth.blockchain.CurrentBlock(), nil
 	}
+	if err := b.checkHistory(uint64(number)); err != nil {
+		return nil, err
+	}
 	return b.eth.blockchain.GetBlockByNumber(uint64(number)), nil
 }
 
 func (b *EthAPIBackend) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	if err := b.checkHistoryByHash(hash); err != nil {
+		return nil, err
+	}
 	return b.eth.blockchain.GetBlockByHash(hash), nil
 }
 
@@ -124,6 +139,9 @@ func (b *EthAPIBackend) BlockByNumberOrHash(ctx context.Context, blockNrOrHash r
 		if blockNrOrHash.RequireCanonical && b.eth.blockchain.GetCanonicalHash(header.Number.Uint64()) != hash {
 			return nil, errors.New("hash is not currently canonical")
 		}
+		if err := b.checkHistory(header.Number.Uint64()); err != nil {
+			return nil, err
+		}
 		block := b.eth.blockchain.GetBlock(hash, header.Number.Uint64())
 		if block == nil {
 			return nil, errors.New("header found, but block body is missing")
@@ -173,10 +191,16 @@ func (b *EthAPIBackend) StateAndHeaderByNumberOrHash(ctx context.Context, blockN
 }
 
 func (b *EthAPIBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
+	if err := b.checkHistoryByHash(hash); err != nil {
+		return nil, err
+	}
 	return b.eth.blockchain.GetReceiptsByHash(hash), nil
 }
 
 func (b *EthAPIBackend) GetLogs(ctx context.Context, hash common.Hash) ([][]*types.Log, error) {
+	if err := b.checkHistoryByHash(hash); err != nil {
+		return nil, err
+	}
 	receipts := b.eth.blockchain.GetReceiptsByHash(hash)
 	if receipts == nil {
 		return nil, nil
@@ -188,6 +212,25 @@ func (b *EthAPIBackend) GetLogs(ctx context.Context, hash common.Hash) ([][]*typ
 	return logs, nil
 }
 
+// checkHistory returns an error if the body and receipts of the block with the
+// given number were pruned.
+func (b *EthAPIBackend) checkHistory(number uint64) error {
+	if !b.eth.blockchain.HistoryPruned(number) {
+		return nil
+	}
+	return &ethapi.PrunedHistoryError{Number: number, Tail: b.eth.blockchain.HistoryTail(), Archive: b.eth.config.HistoryArchive}
+}
+
+// checkHistoryByHash returns an error if the body and receipts of the block
+// with the given hash were pruned.
+func (b *EthAPIBackend) checkHistoryByHash(hash common.Hash) error {
+	header := b.eth.blockchain.GetHeaderByHash(hash)
+	if header == nil {
+		return nil
+	}
+	return b.checkHistory(header.Number.Uint64())
+}
+
 func (b *EthAPIBackend) GetTd(ctx context.Context, hash common.Hash) *big.Int {
 	return b.eth.blockchain.GetTdByHash(hash)
 }
@@ -227,6 +270,10 @@ func (b *EthAPIBackend) SendTx(ctx context.Context, signedTx *types.Transaction)
 	return b.eth.txPool.AddLocal(signedTx)
 }
 
+func (b *EthAPIBackend) SendTxUntil(ctx context.Context, signedTx *types.Transaction, validUntil uint64) error {
+	return b.eth.txPool.AddLocalUntil(signedTx, validUntil)
+}
+
 func (b *EthAPIBackend) GetPoolTransactions() (types.Transactions, error) {
 	pending, err := b.eth.txPool.Pending()
 	if err != nil {
@@ -268,6 +315,10 @@ func (b *EthAPIBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.S
 	return b.eth.TxPool().SubscribeNewTxsEvent(ch)
 }
 
+func (b *EthAPIBackend) SubscribeTxPoolChangeEvent(ch chan<- core.TxPoolChangeEvent) event.Subscription {
+	return b.eth.TxPool().SubscribeTxPoolChangeEvent(ch)
+}
+
 func (b *EthAPIBackend) Downloader() *downloader.Downloader {
 	return b.eth.Downloader()
 }
@@ -295,17 +346,18 @@ func (b *EthAPIBackend) GetBlockGasLimit(ctx context.Context, blockNrOrHash rpc.
 	return blockchain_parameters.GetBlockGasLimitOrDefault(vmRunner)
 }
 
-func (b *EthAPIBackend) NewEVMRunner(header *types.Header, state vm.StateDB) vm.EVMRunner {
-	return b.eth.BlockChain().NewEVMRunner(header, state)
+func (b *EthAPIBackend) NewEVMRunner(ctx context.Context, header *types.Header, state vm.StateDB) vm.EVMRunner {
+	return vmcontext.NewEVMRunnerWithContext(ctx, b.eth.BlockChain(), header, state)
 }
 
 func (b *EthAPIBackend) GetIntrinsicGasForAlternativeFeeCurrency(ctx context.Context) uint64 {
+	schedule := b.ChainConfig().IntrinsicGasSchedule(b.eth.BlockChain().CurrentHeader().Number)
 	vmRunner, err := b.eth.BlockChain().NewEVMRunnerForCurrentBlock()
 	if err != nil {
 		log.Warn("Cannot create evmCaller to get intrinsic gas for alternative fee currency", "err", err)
-		return params.IntrinsicGasForAlternativeFeeCurrency
+		return schedule.AlternativeFeeCurrency
 	}
-	return blockchain_parameters.GetIntrinsicGasForAlternativeFeeCurrencyOrDefault(vmRunner)
+	return blockchain_parameters.GetIntrinsicGasForAlternativeFeeCurrencyOrDefault(vmRunner, schedule)
 }
 
 func (b *EthAPIBackend) ChainDb() ethdb.Database {
@@ -328,10 +380,26 @@ func (b *EthAPIBackend) RPCGasCap() uint64 {
 	return b.eth.config.RPCGasCap
 }
 
+func (b *EthAPIBackend) RPCEVMTimeout() time.Duration {
+	return b.eth.config.RPCEVMTimeout
+}
+
 func (b *EthAPIBackend) RPCTxFeeCap() float64 {
 	return b.eth.config.RPCTxFeeCap
 }
 
+func (b *EthAPIBackend) RPCEthCompatibility() bool {
+	return b.eth.config.RPCEthCompatibility
+}
+
+func (b *EthAPIBackend) RPCCallCache() (time.Duration, int) {
+	return b.eth.config.RPCCallCacheTTL, b.eth.config.RPCCallCacheSize
+}
+
+func (b *EthAPIBackend) ArchiveProxy() *ethapi.ArchiveProxy {
+	return b.archive
+}
+
 func (b *EthAPIBackend) BloomStatus() (uint64, uint64) {
 	sections, _, _ := b.eth.bloomIndexer.Sections()
 	return params.BloomBitsBlocks, sections
@@ -0,0 +1,206 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"time"
+
+	"github.com/celo-org/celo-blockchain/common"
+	istanbulBackend "github.com/celo-org/celo-blockchain/consensus/istanbul/backend"
+	"github.com/celo-org/celo-blockchain/log"
+	"github.com/celo-org/celo-blockchain/params"
+)
+
+const (
+	// diagnosticsBlockTimings is the number of recent blocks whose timings are
+	// included in a diagnostics bundle.
+	diagnosticsBlockTimings = 64
+
+	// maxDiagnosticsDuration caps the CPU profiling window of a diagnostics capture.
+	maxDiagnosticsDuration = 5 * time.Minute
+)
+
+// diagnosticsBlockTiming describes the timing of a single recent block.
+type diagnosticsBlockTiming struct {
+	Number   uint64      `json:"number"`
+	Hash     common.Hash `json:"hash"`
+	Time     uint64      `json:"timestamp"`
+	Interval uint64      `json:"interval"`
+	Txs      int         `json:"transactions"`
+	GasUsed  uint64      `json:"gasUsed"`
+}
+
+// diagnosticsChainContext is the chain related state bundled next to the
+// runtime profiles of a diagnostics capture.
+type diagnosticsChainContext struct {
+	Version      string                   `json:"version"`
+	CapturedAt   time.Time                `json:"capturedAt"`
+	Duration     string                   `json:"duration"`
+	Goroutines   int                      `json:"goroutines"`
+	Syncing      bool                     `json:"syncing"`
+	Mining       bool                     `json:"mining"`
+	Peers        int                      `json:"peers"`
+	TxPool       map[string]int           `json:"txpool"`
+	RoundState   interface{}              `json:"roundState,omitempty"`
+	RoundError   string                   `json:"roundStateError,omitempty"`
+	RecentBlocks []diagnosticsBlockTiming `json:"recentBlocks"`
+}
+
+// CaptureDiagnostics profiles the node's CPU usage for nsec seconds and writes
+// a gzipped tarball containing the CPU, heap and goroutine profiles together
+// with the current consensus round state, txpool stats and recent block
+// timings. It returns the path of the written bundle.
+func (api *PrivateDebugAPI) CaptureDiagnostics(ctx context.Context, nsec uint) (string, error) {
+	duration := time.Duration(nsec) * time.Second
+	if duration > maxDiagnosticsDuration {
+		return "", fmt.Errorf("capture duration %v exceeds maximum of %v", duration, maxDiagnosticsDuration)
+	}
+	files := make(map[string][]byte)
+
+	// Collect the CPU profile first, as it blocks for the requested duration
+	cpu := new(bytes.Buffer)
+	if err := pprof.StartCPUProfile(cpu); err != nil {
+		return "", err
+	}
+	select {
+	case <-time.After(duration):
+	case <-ctx.Done():
+	}
+	pprof.StopCPUProfile()
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	files["cpu.pprof"] = cpu.Bytes()
+
+	for _, name := range []string{"heap", "goroutine", "block", "mutex"} {
+		buf := new(bytes.Buffer)
+		if err := pprof.Lookup(name).WriteTo(buf, 0); err != nil {
+			return "", err
+		}
+		files[name+".pprof"] = buf.Bytes()
+	}
+	stacks := new(bytes.Buffer)
+	pprof.Lookup("goroutine").WriteTo(stacks, 2)
+	files["stacks.txt"] = stacks.Bytes()
+
+	chainContext, err := json.MarshalIndent(api.diagnosticsChainContext(duration), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	files["chain.json"] = chainContext
+
+	dir := api.eth.diagnosticsDir
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("diagnostics-%d.tar.gz", time.Now().Unix()))
+	if err := writeDiagnosticsBundle(path, files); err != nil {
+		return "", err
+	}
+	log.Info("Wrote diagnostics bundle", "path", path, "duration", duration)
+	return path, nil
+}
+
+// diagnosticsChainContext gathers a snapshot of the node's chain, consensus
+// and txpool state.
+func (api *PrivateDebugAPI) diagnosticsChainContext(duration time.Duration) *diagnosticsChainContext {
+	pending, queued := api.eth.TxPool().Stats()
+	summary := &diagnosticsChainContext{
+		Version:    params.VersionWithMeta,
+		CapturedAt: time.Now(),
+		Duration:   duration.String(),
+		Goroutines: runtime.NumGoroutine(),
+		Syncing:    !api.eth.Synced(),
+		Mining:     api.eth.IsMining(),
+		TxPool:     map[string]int{"pending": pending, "queued": queued},
+	}
+	if api.eth.p2pServer != nil {
+		summary.Peers = api.eth.p2pServer.PeerCount()
+	}
+	if backend, ok := api.eth.Engine().(*istanbulBackend.Backend); ok {
+		if roundState, err := backend.CurrentRoundStateSummary(); err != nil {
+			summary.RoundError = err.Error()
+		} else {
+			summary.RoundState = roundState
+		}
+	}
+	chain := api.eth.BlockChain()
+	header := chain.CurrentHeader()
+	for i := 0; i < diagnosticsBlockTimings && header != nil && header.Number.Uint64() > 0; i++ {
+		parent := chain.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+		if parent == nil {
+			break
+		}
+		timing := diagnosticsBlockTiming{
+			Number:   header.Number.Uint64(),
+			Hash:     header.Hash(),
+			Time:     header.Time,
+			Interval: header.Time - parent.Time,
+			GasUsed:  header.GasUsed,
+		}
+		if body := chain.GetBody(header.Hash()); body != nil {
+			timing.Txs = len(body.Transactions)
+		}
+		summary.RecentBlocks = append(summary.RecentBlocks, timing)
+		header = parent
+	}
+	return summary
+}
+
+// writeDiagnosticsBundle writes the given files into a gzipped tarball at path.
+func writeDiagnosticsBundle(path string, files map[string][]byte) error {
+	out, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+	now := time.Now()
+	for name, data := range files {
+		hdr := &tar.Header{
+			Name:    name,
+			Mode:    0600,
+			Size:    int64(len(data)),
+			ModTime: now,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
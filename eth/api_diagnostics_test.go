@@ -0,0 +1,80 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteDiagnosticsBundle(t *testing.T) {
+	dir, err := ioutil.TempDir("", "diagnostics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string][]byte{
+		"cpu.pprof":  []byte("cpu"),
+		"chain.json": []byte(`{"peers":1}`),
+	}
+	path := filepath.Join(dir, "bundle.tar.gz")
+	if err := writeDiagnosticsBundle(path, files); err != nil {
+		t.Fatalf("failed to write bundle: %v", err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	found := 0
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, ok := files[hdr.Name]
+		if !ok {
+			t.Fatalf("unexpected file %q in bundle", hdr.Name)
+		}
+		if !bytes.Equal(data, want) {
+			t.Errorf("file %q content mismatch: have %q, want %q", hdr.Name, data, want)
+		}
+		found++
+	}
+	if found != len(files) {
+		t.Errorf("bundle file count mismatch: have %d, want %d", found, len(files))
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/common/hexutil"
+	"github.com/celo-org/celo-blockchain/contracts"
+	"github.com/celo-org/celo-blockchain/contracts/blockchain_parameters"
+	"github.com/celo-org/celo-blockchain/contracts/governance"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/core/vm"
+	"github.com/celo-org/celo-blockchain/core/vm/vmcontext"
+	"github.com/celo-org/celo-blockchain/eth/tracers"
+	"github.com/celo-org/celo-blockchain/params"
+	"github.com/celo-org/celo-blockchain/rpc"
+)
+
+// GovernanceSimulation is the outcome of executing the transactions of a
+// governance proposal against a copy of the state.
+type GovernanceSimulation struct {
+	ProposalID  hexutil.Uint64 `json:"proposalId"`
+	Stage       string         `json:"stage"` // Stage of the proposal at the block
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	BlockHash   common.Hash    `json:"blockHash"`
+
+	Transactions []*GovernanceTxResult `json:"transactions"`
+	Failed       bool                  `json:"failed"` // A transaction failed, so the execution would revert as a whole
+
+	StateDiff *tracers.StateDiff `json:"stateDiff"`
+	Logs      []*types.Log       `json:"logs"`
+}
+
+// GovernanceTxResult is the result of executing a transaction of a proposal.
+type GovernanceTxResult struct {
+	Destination common.Address `json:"destination"`
+	Value       *hexutil.Big   `json:"value"`
+	Data        hexutil.Bytes  `json:"data"`
+	GasUsed     hexutil.Uint64 `json:"gasUsed"`
+	ReturnValue hexutil.Bytes  `json:"returnValue,omitempty"`
+	Error       string         `json:"error,omitempty"`
+}
+
+// SimulateGovernanceExecution executes the transactions of a governance proposal
+// on top of the state of the given block, as the Governance contract does when
+// the proposal is executed, and returns their state changes and emitted events.
+// The transactions are executed until one fails. Nothing is persisted.
+func (api *PrivateDebugAPI) SimulateGovernanceExecution(ctx context.Context, proposalID hexutil.Uint64, number rpc.BlockNumber) (*GovernanceSimulation, error) {
+	block, err := api.blockByNumber(number)
+	if err != nil {
+		return nil, err
+	}
+	statedb, err := api.computeStateDB(ctx, block, defaultTraceReexec)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		chain    = api.eth.blockchain
+		header   = block.Header()
+		vmRunner = chain.NewEVMRunner(header, statedb)
+		id       = new(big.Int).SetUint64(uint64(proposalID))
+	)
+	proposal, err := governance.GetProposal(vmRunner, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve proposal %d: %v", proposalID, err)
+	}
+	if proposal.Proposer == (common.Address{}) {
+		return nil, fmt.Errorf("proposal %d not found at block #%d", proposalID, block.NumberU64())
+	}
+	stage, err := governance.GetProposalStage(vmRunner, id)
+	if err != nil {
+		return nil, err
+	}
+	governanceAddress, err := contracts.GetRegisteredAddress(vmRunner, params.GovernanceRegistryId)
+	if err != nil {
+		return nil, err
+	}
+	txs := make([]*governance.ProposalTransaction, proposal.TransactionCount.Uint64())
+	for i := range txs {
+		if txs[i], err = governance.GetProposalTransaction(vmRunner, id, uint64(i)); err != nil {
+			return nil, fmt.Errorf("failed to retrieve transaction %d of proposal %d: %v", i, proposalID, err)
+		}
+	}
+	gasLimit := blockchain_parameters.GetBlockGasLimitOrDefault(vmRunner)
+
+	// The transactions are sent by the Governance contract, one after the other
+	simulation := &GovernanceSimulation{
+		ProposalID:   proposalID,
+		Stage:        stage.String(),
+		BlockNumber:  hexutil.Uint64(block.NumberU64()),
+		BlockHash:    block.Hash(),
+		Transactions: make([]*GovernanceTxResult, 0, len(txs)),
+	}
+	stateDiff := tracers.NewStateDiffTracer(statedb)
+	for i, tx := range txs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		statedb.Prepare(common.Hash{}, block.Hash(), i)
+		vmctx := vmcontext.New(governanceAddress, common.Big0, header, chain, nil)
+		evm := vm.NewEVM(vmctx, stateDiff, chain.Config(), vm.Config{})
+
+		ret, gasLeft, err := evm.Call(vm.AccountRef(governanceAddress), tx.Destination, tx.Data, gasLimit, tx.Value)
+		result := &GovernanceTxResult{
+			Destination: tx.Destination,
+			Value:       (*hexutil.Big)(tx.Value),
+			Data:        tx.Data,
+			GasUsed:     hexutil.Uint64(gasLimit - gasLeft),
+			ReturnValue: ret,
+		}
+		simulation.Transactions = append(simulation.Transactions, result)
+		if err != nil {
+			result.Error = err.Error()
+			simulation.Failed = true
+			break
+		}
+	}
+	simulation.StateDiff = stateDiff.StateDiff()
+	simulation.Logs = statedb.GetLogs(common.Hash{})
+	if simulation.Logs == nil {
+		simulation.Logs = []*types.Log{}
+	}
+	return simulation, nil
+}
@@ -0,0 +1,211 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"encoding/binary"
+	"math/big"
+	"testing"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/common/hexutil"
+	mockEngine "github.com/celo-org/celo-blockchain/consensus/consensustest"
+	"github.com/celo-org/celo-blockchain/contracts/abis"
+	"github.com/celo-org/celo-blockchain/contracts/governance"
+	"github.com/celo-org/celo-blockchain/core"
+	"github.com/celo-org/celo-blockchain/core/rawdb"
+	"github.com/celo-org/celo-blockchain/core/vm"
+	"github.com/celo-org/celo-blockchain/params"
+	"github.com/celo-org/celo-blockchain/rpc"
+)
+
+// governanceMockCode returns the code of a contract answering each of the given
+// Governance methods with a fixed output, regardless of the arguments, and
+// reverting on any other call.
+func governanceMockCode(t *testing.T, outputs map[string][]interface{}) []byte {
+	type handler struct {
+		selector []byte
+		output   []byte
+	}
+	var handlers []handler
+	for name, values := range outputs {
+		method, ok := abis.Governance.Methods[name]
+		if !ok {
+			t.Fatalf("unknown Governance method %s", name)
+		}
+		output, err := method.Outputs.Pack(values...)
+		if err != nil {
+			t.Fatalf("failed to pack %s output: %v", name, err)
+		}
+		handlers = append(handlers, handler{method.ID, output})
+	}
+	const (
+		prologueSize = 6  // PUSH1 0 CALLDATALOAD PUSH1 0xe0 SHR
+		dispatchSize = 11 // DUP1 PUSH4 selector EQ PUSH2 dest JUMPI
+		revertSize   = 4  // PUSH1 0 DUP1 REVERT
+		handlerSize  = 16 // JUMPDEST PUSH2 size PUSH2 offset PUSH1 0 CODECOPY PUSH2 size PUSH1 0 RETURN
+	)
+	var (
+		code       = []byte{0x60, 0x00, 0x35, 0x60, 0xe0, 0x1c}
+		handlersAt = prologueSize + dispatchSize*len(handlers) + revertSize
+		dataAt     = handlersAt + handlerSize*len(handlers)
+		data       []byte
+	)
+	u16 := func(n int) []byte {
+		b := make([]byte, 2)
+		binary.BigEndian.PutUint16(b, uint16(n))
+		return b
+	}
+	for i, h := range handlers {
+		code = append(code, 0x80, 0x63)
+		code = append(code, h.selector...)
+		code = append(code, 0x14, 0x61)
+		code = append(code, u16(handlersAt+i*handlerSize)...)
+		code = append(code, 0x57)
+	}
+	code = append(code, 0x60, 0x00, 0x80, 0xfd)
+	for _, h := range handlers {
+		code = append(code, 0x5b, 0x61)
+		code = append(code, u16(len(h.output))...)
+		code = append(code, 0x61)
+		code = append(code, u16(dataAt+len(data))...)
+		code = append(code, 0x60, 0x00, 0x39, 0x61)
+		code = append(code, u16(len(h.output))...)
+		code = append(code, 0x60, 0x00, 0xf3)
+		data = append(data, h.output...)
+	}
+	return append(code, data...)
+}
+
+// newGovernanceTestAPI returns a debug API on a chain whose registry resolves
+// every contract to a Governance mock holding a proposal with two transactions,
+// both calling the given destination code.
+func newGovernanceTestAPI(t *testing.T, proposer common.Address, destination common.Address, destinationCode []byte) (*PrivateDebugAPI, func()) {
+	governanceAddress := common.HexToAddress("0x0d1")
+
+	// PUSH20 governance PUSH1 0 MSTORE PUSH1 32 PUSH1 0 RETURN
+	registryCode := append([]byte{0x73}, governanceAddress.Bytes()...)
+	registryCode = append(registryCode, 0x60, 0x00, 0x52, 0x60, 0x20, 0x60, 0x00, 0xf3)
+
+	governanceCode := governanceMockCode(t, map[string][]interface{}{
+		"getProposal":            {proposer, big.NewInt(100), big.NewInt(1), big.NewInt(2), "https://proposal"},
+		"getProposalStage":       {uint8(governance.StageExecution)},
+		"getProposalTransaction": {big.NewInt(0), destination, []byte{0x01, 0x02}},
+	})
+	var (
+		db    = rawdb.NewMemoryDatabase()
+		gspec = &core.Genesis{
+			Config: params.IstanbulTestChainConfig,
+			Alloc: core.GenesisAlloc{
+				params.RegistrySmartContractAddress: {Code: registryCode, Balance: common.Big0},
+				governanceAddress:                   {Code: governanceCode, Balance: common.Big0},
+				destination:                         {Code: destinationCode, Balance: common.Big0},
+			},
+		}
+	)
+	gspec.MustCommit(db)
+	chain, err := core.NewBlockChain(db, nil, gspec.Config, mockEngine.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eth := &Ethereum{blockchain: chain}
+	eth.APIBackend = &EthAPIBackend{eth: eth}
+	return NewPrivateDebugAPI(eth), chain.Stop
+}
+
+func TestSimulateGovernanceExecution(t *testing.T) {
+	var (
+		proposer    = common.HexToAddress("0x0a1")
+		destination = common.HexToAddress("0xde5")
+	)
+	// SLOAD(1) + 1 -> SSTORE(1), then LOG0 with empty data
+	counter := hexutil.MustDecode("0x60016001540160015560006000a000")
+
+	api, stop := newGovernanceTestAPI(t, proposer, destination, counter)
+	defer stop()
+
+	sim, err := api.SimulateGovernanceExecution(context.Background(), 1, rpc.LatestBlockNumber)
+	if err != nil {
+		t.Fatalf("simulation failed: %v", err)
+	}
+	if sim.Stage != governance.StageExecution.String() {
+		t.Errorf("stage mismatch: have %s, want %s", sim.Stage, governance.StageExecution)
+	}
+	if sim.Failed {
+		t.Errorf("simulation failed: %+v", sim.Transactions)
+	}
+	if len(sim.Transactions) != 2 {
+		t.Fatalf("transaction count mismatch: have %d, want 2", len(sim.Transactions))
+	}
+	for i, tx := range sim.Transactions {
+		if tx.Destination != destination || tx.Error != "" || tx.GasUsed == 0 {
+			t.Errorf("transaction %d: unexpected result %+v", i, tx)
+		}
+	}
+	if len(sim.Logs) != 2 {
+		t.Errorf("log count mismatch: have %d, want 2", len(sim.Logs))
+	}
+	for i, log := range sim.Logs {
+		if log.Address != destination {
+			t.Errorf("log %d: address mismatch: have %x, want %x", i, log.Address, destination)
+		}
+	}
+	// Both transactions ran against the same state, so the counter reached two
+	post := sim.StateDiff.Post[destination]
+	if post == nil {
+		t.Fatalf("destination missing from state diff")
+	}
+	if have, want := post.Storage[common.BigToHash(common.Big1)], common.BigToHash(big.NewInt(2)); have != want {
+		t.Errorf("counter mismatch: have %x, want %x", have, want)
+	}
+}
+
+func TestSimulateGovernanceExecutionFailure(t *testing.T) {
+	var (
+		proposer    = common.HexToAddress("0x0a1")
+		destination = common.HexToAddress("0xde5")
+	)
+	// PUSH1 0 DUP1 REVERT
+	api, stop := newGovernanceTestAPI(t, proposer, destination, []byte{0x60, 0x00, 0x80, 0xfd})
+	defer stop()
+
+	sim, err := api.SimulateGovernanceExecution(context.Background(), 1, rpc.LatestBlockNumber)
+	if err != nil {
+		t.Fatalf("simulation failed: %v", err)
+	}
+	if !sim.Failed {
+		t.Errorf("reverting proposal not reported failed")
+	}
+	// Execution stops at the first failing transaction
+	if len(sim.Transactions) != 1 || sim.Transactions[0].Error == "" {
+		t.Errorf("unexpected transaction results: %+v", sim.Transactions)
+	}
+	if len(sim.Logs) != 0 {
+		t.Errorf("logs emitted by reverted execution: %v", sim.Logs)
+	}
+}
+
+func TestSimulateGovernanceExecutionNotFound(t *testing.T) {
+	// A zero proposer marks a proposal that does not exist
+	api, stop := newGovernanceTestAPI(t, common.Address{}, common.HexToAddress("0xde5"), nil)
+	defer stop()
+
+	if _, err := api.SimulateGovernanceExecution(context.Background(), 1, rpc.LatestBlockNumber); err == nil {
+		t.Errorf("no error for missing proposal")
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -35,6 +36,7 @@ import (
 	"github.com/celo-org/celo-blockchain/core/state"
 	"github.com/celo-org/celo-blockchain/core/types"
 	"github.com/celo-org/celo-blockchain/core/vm"
+	"github.com/celo-org/celo-blockchain/core/vm/vmcontext"
 	"github.com/celo-org/celo-blockchain/eth/tracers"
 	"github.com/celo-org/celo-blockchain/internal/ethapi"
 	"github.com/celo-org/celo-blockchain/log"
@@ -207,7 +209,7 @@ func (api *PrivateDebugAPI) traceChain(ctx context.Context, start, end *types.Bl
 				for i, tx := range task.block.Transactions() {
 					msg, _ := tx.AsMessage(signer)
 					vmctx := core.NewEVMContext(msg, task.block.Header(), api.eth.blockchain, nil)
-					vmRunner := api.eth.blockchain.NewEVMRunner(task.block.Header(), statedb)
+					vmRunner := vmcontext.NewEVMRunnerWithContext(ctx, api.eth.blockchain, task.block.Header(), task.statedb)
 
 					res, err := api.traceTx(ctx, msg, vmctx, vmRunner, task.statedb, config)
 					if err != nil {
@@ -290,7 +292,7 @@ func (api *PrivateDebugAPI) traceChain(ctx context.Context, start, end *types.Bl
 				traced += uint64(len(txs))
 			}
 			// Generate the next state snapshot fast without tracing
-			_, _, _, err := api.eth.blockchain.Processor().Process(block, statedb, vm.Config{})
+			_, _, _, err := api.eth.blockchain.Processor().Process(ctx, block, statedb, vm.Config{})
 			if err != nil {
 				failed = err
 				break
@@ -455,7 +457,7 @@ func (api *PrivateDebugAPI) traceBlock(ctx context.Context, block *types.Block,
 	if config != nil && config.Reexec != nil {
 		reexec = *config.Reexec
 	}
-	statedb, err := api.computeStateDB(parent, reexec)
+	statedb, err := api.computeStateDB(ctx, parent, reexec)
 	if err != nil {
 		return nil, err
 	}
@@ -482,7 +484,7 @@ func (api *PrivateDebugAPI) traceBlock(ctx context.Context, block *types.Block,
 			for task := range jobs {
 				msg, _ := txs[task.index].AsMessage(signer)
 				vmctx := core.NewEVMContext(msg, block.Header(), api.eth.blockchain, nil)
-				vmRunner := api.eth.blockchain.NewEVMRunner(block.Header(), statedb)
+				vmRunner := vmcontext.NewEVMRunnerWithContext(ctx, api.eth.blockchain, block.Header(), task.statedb)
 
 				res, err := api.traceTx(ctx, msg, vmctx, vmRunner, task.statedb, config)
 				if err != nil {
@@ -504,7 +506,7 @@ func (api *PrivateDebugAPI) traceBlock(ctx context.Context, block *types.Block,
 		vmctx := core.NewEVMContext(msg, block.Header(), api.eth.blockchain, nil)
 
 		vmenv := vm.NewEVM(vmctx, statedb, api.eth.blockchain.Config(), vm.Config{})
-		vmRunner := api.eth.blockchain.NewEVMRunner(block.Header(), statedb)
+		vmRunner := vmcontext.NewEVMRunnerWithContext(ctx, api.eth.blockchain, block.Header(), statedb)
 		if _, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.Gas()), vmRunner); err != nil {
 			failed = err
 			break
@@ -545,7 +547,7 @@ func (api *PrivateDebugAPI) standardTraceBlockToFile(ctx context.Context, block
 	if config != nil && config.Reexec != nil {
 		reexec = *config.Reexec
 	}
-	statedb, err := api.computeStateDB(parent, reexec)
+	statedb, err := api.computeStateDB(ctx, parent, reexec)
 	if err != nil {
 		return nil, err
 	}
@@ -599,7 +601,7 @@ func (api *PrivateDebugAPI) standardTraceBlockToFile(ctx context.Context, block
 		}
 		// Execute the transaction and flush any traces to disk
 		vmenv := vm.NewEVM(vmctx, statedb, api.eth.blockchain.Config(), vmConf)
-		vmRunner := api.eth.blockchain.NewEVMRunner(block.Header(), statedb)
+		vmRunner := vmcontext.NewEVMRunnerWithContext(ctx, api.eth.blockchain, block.Header(), statedb)
 		_, err = core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.Gas()), vmRunner)
 		if writer != nil {
 			writer.Flush()
@@ -637,7 +639,7 @@ func containsTx(block *types.Block, hash common.Hash) bool {
 // computeStateDB retrieves the state database associated with a certain block.
 // If no state is locally available for the given block, a number of blocks are
 // attempted to be reexecuted to generate the desired state.
-func (api *PrivateDebugAPI) computeStateDB(block *types.Block, reexec uint64) (*state.StateDB, error) {
+func (api *PrivateDebugAPI) computeStateDB(ctx context.Context, block *types.Block, reexec uint64) (*state.StateDB, error) {
 	// If we have the state fully available, use that
 	statedb, err := api.eth.blockchain.StateAt(block.Root())
 	if err == nil {
@@ -680,7 +682,7 @@ func (api *PrivateDebugAPI) computeStateDB(block *types.Block, reexec uint64) (*
 		if block = api.eth.blockchain.GetBlockByNumber(block.NumberU64() + 1); block == nil {
 			return nil, fmt.Errorf("block #%d not found", block.NumberU64()+1)
 		}
-		_, _, _, err := api.eth.blockchain.Processor().Process(block, statedb, vm.Config{})
+		_, _, _, err := api.eth.blockchain.Processor().Process(ctx, block, statedb, vm.Config{})
 		if err != nil {
 			return nil, fmt.Errorf("processing block %d failed: %v", block.NumberU64(), err)
 		}
@@ -711,28 +713,53 @@ func (api *PrivateDebugAPI) TraceTransaction(ctx context.Context, hash common.Ha
 	if tx == nil {
 		return nil, fmt.Errorf("transaction %#x not found", hash)
 	}
+	// Serve the trace from the cache if it was computed recently
+	cache := api.eth.traceCache
+	key := traceCacheKey(blockHash, hash, config)
+	if cache != nil {
+		if trace, ok := cache.get(key); ok {
+			return trace, nil
+		}
+	}
 	reexec := defaultTraceReexec
 	if config != nil && config.Reexec != nil {
 		reexec = *config.Reexec
 	}
-	msg, vmctx, vmRunner, statedb, err := api.computeTxEnv(blockHash, int(index), reexec)
+	msg, vmctx, vmRunner, statedb, err := api.computeTxEnv(ctx, blockHash, int(index), reexec)
 	if err != nil {
 		return nil, err
 	}
 	// Trace the transaction and return
-	return api.traceTx(ctx, msg, vmctx, vmRunner, statedb, config)
+	result, err := api.traceTx(ctx, msg, vmctx, vmRunner, statedb, config)
+	if err != nil || cache == nil {
+		return result, err
+	}
+	trace, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	cache.add(key, trace)
+	return json.RawMessage(trace), nil
 }
 
 // traceTx configures a new tracer according to the provided configuration, and
 // executes the given message in the provided environment. The return value will
 // be tracer dependent.
 func (api *PrivateDebugAPI) traceTx(ctx context.Context, message core.Message, vmctx vm.Context, vmRunner vm.EVMRunner, statedb *state.StateDB, config *TraceConfig) (interface{}, error) {
-	// Assemble the structured logger or the JavaScript tracer
+	// Assemble the structured logger, the JavaScript tracer or the native tracers
 	var (
-		tracer vm.Tracer
-		err    error
+		tracer    vm.Tracer
+		stateDiff *tracers.StateDiffTracer
+		err       error
 	)
 	switch {
+	case config != nil && config.Tracer != nil && *config.Tracer == tracers.StateDiffTracerName:
+		// The state diff tracer observes the state instead of the executed opcodes
+		stateDiff = tracers.NewStateDiffTracer(statedb)
+
+	case config != nil && config.Tracer != nil && *config.Tracer == tracers.GasProfilerName:
+		tracer = tracers.NewGasProfiler()
+
 	case config != nil && config.Tracer != nil:
 		// Define a meaningful timeout of a single transaction trace
 		timeout := defaultTraceTimeout
@@ -760,11 +787,19 @@ func (api *PrivateDebugAPI) traceTx(ctx context.Context, message core.Message, v
 		tracer = vm.NewStructLogger(config.LogConfig)
 	}
 	// Run the transaction with tracing enabled.
-	vmenv := vm.NewEVM(vmctx, statedb, api.eth.blockchain.Config(), vm.Config{Debug: true, Tracer: tracer})
+	var vmenv *vm.EVM
+	if stateDiff != nil {
+		vmenv = vm.NewEVM(vmctx, stateDiff, api.eth.blockchain.Config(), vm.Config{})
+	} else {
+		vmenv = vm.NewEVM(vmctx, statedb, api.eth.blockchain.Config(), vm.Config{Debug: true, Tracer: tracer})
+	}
 	result, err := core.ApplyMessage(vmenv, message, new(core.GasPool).AddGas(message.Gas()), vmRunner)
 	if err != nil {
 		return nil, fmt.Errorf("tracing failed: %v", err)
 	}
+	if stateDiff != nil {
+		return stateDiff.GetResult()
+	}
 	// Depending on the tracer type, format and return the output
 	switch tracer := tracer.(type) {
 	case *vm.StructLogger:
@@ -783,13 +818,16 @@ func (api *PrivateDebugAPI) traceTx(ctx context.Context, message core.Message, v
 	case *tracers.Tracer:
 		return tracer.GetResult()
 
+	case *tracers.GasProfiler:
+		return tracer.Profile(), nil
+
 	default:
 		panic(fmt.Sprintf("bad tracer type %T", tracer))
 	}
 }
 
 // computeTxEnv returns the execution environment of a certain transaction.
-func (api *PrivateDebugAPI) computeTxEnv(blockHash common.Hash, txIndex int, reexec uint64) (core.Message, vm.Context, vm.EVMRunner, *state.StateDB, error) {
+func (api *PrivateDebugAPI) computeTxEnv(ctx context.Context, blockHash common.Hash, txIndex int, reexec uint64) (core.Message, vm.Context, vm.EVMRunner, *state.StateDB, error) {
 	// Create the parent state database
 	block := api.eth.blockchain.GetBlockByHash(blockHash)
 	if block == nil {
@@ -799,7 +837,7 @@ func (api *PrivateDebugAPI) computeTxEnv(blockHash common.Hash, txIndex int, ree
 	if parent == nil {
 		return nil, vm.Context{}, nil, nil, fmt.Errorf("parent %#x not found", block.ParentHash())
 	}
-	statedb, err := api.computeStateDB(parent, reexec)
+	statedb, err := api.computeStateDB(ctx, parent, reexec)
 	if err != nil {
 		return nil, vm.Context{}, nil, nil, err
 	}
@@ -812,11 +850,14 @@ func (api *PrivateDebugAPI) computeTxEnv(blockHash common.Hash, txIndex int, ree
 	signer := types.MakeSigner(api.eth.blockchain.Config(), block.Number())
 
 	for idx, tx := range block.Transactions() {
+		if err := ctx.Err(); err != nil {
+			return nil, vm.Context{}, nil, nil, err
+		}
 		// Assemble the transaction call message and return if the requested offset
 		msg, _ := tx.AsMessage(signer)
 		context := core.NewEVMContext(msg, block.Header(), api.eth.blockchain, nil)
 		vmenv := vm.NewEVM(context, statedb, api.eth.blockchain.Config(), vm.Config{})
-		vmRunner := api.eth.blockchain.NewEVMRunner(block.Header(), statedb)
+		vmRunner := vmcontext.NewEVMRunnerWithContext(ctx, api.eth.blockchain, block.Header(), statedb)
 		if idx == txIndex {
 			return msg, context, vmRunner, statedb, nil
 		}
@@ -0,0 +1,166 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/core/vm"
+	"github.com/celo-org/celo-blockchain/crypto"
+	"github.com/celo-org/celo-blockchain/log"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// traceCache keeps the results of recent transaction traces, so that traces
+// requested over and over (e.g. by block explorers) are not recomputed. Traces
+// are kept in memory, and the ones evicted from memory spill over to disk,
+// where the least recently used are dropped in turn. Every trace lives in one
+// of the two tiers only.
+type traceCache struct {
+	lock sync.Mutex // Serializes the moves of traces between the tiers
+	dir  string
+	mem  *lru.Cache // Trace key -> JSON encoded trace
+	disk *lru.Cache // Trace keys stored on disk, nil if spillover is disabled
+}
+
+// newTraceCache creates a trace cache keeping the given number of traces in
+// memory and on disk, reloading the traces stored on disk by a previous run.
+func newTraceCache(memory, disk int, dir string) (*traceCache, error) {
+	c := &traceCache{dir: dir}
+	c.mem, _ = lru.NewWithEvict(memory, c.spill)
+	if disk <= 0 {
+		return c, nil
+	}
+	c.disk, _ = lru.NewWithEvict(disk, c.drop)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	// Reload the stored traces from the least to the most recently written
+	sort.Slice(files, func(i, j int) bool { return files[i].ModTime().Before(files[j].ModTime()) })
+	for _, file := range files {
+		name := file.Name()
+		if strings.HasSuffix(name, ".tmp") {
+			os.Remove(filepath.Join(dir, name))
+			continue
+		}
+		if !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		blob, err := hex.DecodeString(strings.TrimSuffix(name, ".json"))
+		if err != nil || len(blob) != common.HashLength {
+			continue
+		}
+		c.disk.Add(common.BytesToHash(blob), struct{}{})
+	}
+	return c, nil
+}
+
+// traceCacheKey returns the key of the trace of a transaction included in the
+// given block with the given config. Only the options shaping the result are
+// taken into account.
+func traceCacheKey(blockHash, txHash common.Hash, config *TraceConfig) common.Hash {
+	var options struct {
+		*vm.LogConfig
+		Tracer *string
+	}
+	if config != nil {
+		options.LogConfig, options.Tracer = config.LogConfig, config.Tracer
+	}
+	blob, _ := json.Marshal(options)
+	return crypto.Keccak256Hash(blockHash[:], txHash[:], blob)
+}
+
+// path returns the file a trace is stored in on disk.
+func (c *traceCache) path(key common.Hash) string {
+	return filepath.Join(c.dir, common.Bytes2Hex(key[:])+".json")
+}
+
+// get returns the cached trace with the given key, if any. Traces found on disk
+// are moved back to memory.
+func (c *traceCache) get(key common.Hash) (json.RawMessage, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if trace, ok := c.mem.Get(key); ok {
+		return trace.(json.RawMessage), true
+	}
+	if c.disk == nil || !c.disk.Contains(key) {
+		return nil, false
+	}
+	trace, err := ioutil.ReadFile(c.path(key))
+	c.disk.Remove(key)
+	if err != nil {
+		log.Warn("Failed to load cached trace", "key", key, "err", err)
+		return nil, false
+	}
+	c.mem.Add(key, json.RawMessage(trace))
+	return trace, true
+}
+
+// add caches a trace under the given key.
+func (c *traceCache) add(key common.Hash, trace json.RawMessage) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.mem.Add(key, trace)
+}
+
+// spill writes a trace evicted from memory to disk.
+func (c *traceCache) spill(key, value interface{}) {
+	if c.disk == nil {
+		return
+	}
+	path := c.path(key.(common.Hash))
+	if err := ioutil.WriteFile(path+".tmp", value.(json.RawMessage), 0600); err != nil {
+		log.Warn("Failed to store evicted trace", "key", key, "err", err)
+		return
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		log.Warn("Failed to store evicted trace", "key", key, "err", err)
+		return
+	}
+	c.disk.Add(key, struct{}{})
+}
+
+// drop deletes a trace evicted from, or moved out of, the disk tier.
+func (c *traceCache) drop(key, value interface{}) {
+	if err := os.Remove(c.path(key.(common.Hash))); err != nil && !os.IsNotExist(err) {
+		log.Warn("Failed to delete cached trace", "key", key, "err", err)
+	}
+}
+
+// close spills the traces kept in memory to disk, so they survive a restart.
+func (c *traceCache) close() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, key := range c.mem.Keys() {
+		c.mem.Remove(key) // From the least recently used, triggering a spill
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/celo-org/celo-blockchain/common"
+)
+
+// Tests that traces evicted from memory spill over to disk, are dropped from
+// disk in least recently used order, and survive a restart.
+func TestTraceCacheSpillover(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tracecache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cache, err := newTraceCache(2, 2, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := make([]common.Hash, 5)
+	for i := range keys {
+		keys[i] = traceCacheKey(common.Hash{byte(i)}, common.Hash{byte(i)}, nil)
+		cache.add(keys[i], json.RawMessage(`{"trace":`+string('0'+rune(i))+`}`))
+	}
+	// Traces 3 and 4 are in memory, 1 and 2 on disk, and 0 was dropped
+	if cache.mem.Len() != 2 || cache.disk.Len() != 2 {
+		t.Fatalf("tier sizes mismatch: have %d in memory and %d on disk, want 2 and 2", cache.mem.Len(), cache.disk.Len())
+	}
+	if _, ok := cache.get(keys[0]); ok {
+		t.Errorf("dropped trace served")
+	}
+	trace, ok := cache.get(keys[1])
+	if !ok || string(trace) != `{"trace":1}` {
+		t.Fatalf("spilled trace mismatch: have %s, %v", trace, ok)
+	}
+	if !cache.mem.Contains(keys[1]) || cache.disk.Contains(keys[1]) {
+		t.Errorf("trace served from disk not moved to memory")
+	}
+	// Memory is spilled on close and the traces are reloaded on restart
+	cache.close()
+	if cache, err = newTraceCache(2, 2, dir); err != nil {
+		t.Fatal(err)
+	}
+	if cache.disk.Len() != 2 {
+		t.Fatalf("reloaded traces mismatch: have %d, want 2", cache.disk.Len())
+	}
+	for _, i := range []int{1, 4} {
+		if trace, ok := cache.get(keys[i]); !ok || string(trace) != `{"trace":`+string('0'+rune(i))+`}` {
+			t.Errorf("trace %d not reloaded: have %s, %v", i, trace, ok)
+		}
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 0 {
+		t.Errorf("stale trace files left: %d", len(files))
+	}
+}
+
+func TestTraceCacheKey(t *testing.T) {
+	var (
+		tracer  = "callTracer"
+		timeout = "1s"
+		reexec  = uint64(10)
+		block   = common.Hash{1}
+		tx      = common.Hash{2}
+	)
+	key := traceCacheKey(block, tx, &TraceConfig{Tracer: &tracer})
+	if traceCacheKey(block, tx, &TraceConfig{Tracer: &tracer, Timeout: &timeout, Reexec: &reexec}) != key {
+		t.Errorf("key depends on options not shaping the trace")
+	}
+	if traceCacheKey(block, tx, nil) == key {
+		t.Errorf("key does not depend on the tracer")
+	}
+	if traceCacheKey(common.Hash{3}, tx, &TraceConfig{Tracer: &tracer}) == key {
+		t.Errorf("key does not depend on the block")
+	}
+}
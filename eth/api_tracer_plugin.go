@@ -0,0 +1,193 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/common/hexutil"
+	"github.com/celo-org/celo-blockchain/core"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/core/vm"
+	"github.com/celo-org/celo-blockchain/log"
+	"github.com/celo-org/celo-blockchain/rpc"
+)
+
+// maxPluginBacklog is the number of imported blocks a tracer plugin may lag
+// behind before blocks are skipped for it.
+const maxPluginBacklog = 256
+
+// pluginBacklog is the queue of imported blocks waiting to be traced for a
+// plugin, dropping the oldest ones once it is full.
+type pluginBacklog struct {
+	lock   sync.Mutex
+	blocks []*types.Block
+	limit  int
+	wake   chan struct{} // Signalled when a block is queued
+}
+
+func newPluginBacklog(limit int) *pluginBacklog {
+	return &pluginBacklog{limit: limit, wake: make(chan struct{}, 1)}
+}
+
+// push queues a block, returning the block dropped to make room for it if the
+// backlog was full.
+func (b *pluginBacklog) push(block *types.Block) (dropped *types.Block) {
+	b.lock.Lock()
+	if len(b.blocks) >= b.limit {
+		dropped, b.blocks = b.blocks[0], b.blocks[1:]
+	}
+	b.blocks = append(b.blocks, block)
+	b.lock.Unlock()
+
+	select {
+	case b.wake <- struct{}{}:
+	default:
+	}
+	return dropped
+}
+
+// pop removes and returns the oldest queued block, or nil if there is none.
+func (b *pluginBacklog) pop() *types.Block {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if len(b.blocks) == 0 {
+		return nil
+	}
+	block := b.blocks[0]
+	b.blocks = b.blocks[1:]
+	return block
+}
+
+// TracerPluginConfig holds the parameters a tracer plugin registers with.
+type TracerPluginConfig struct {
+	*vm.LogConfig
+	Name   string  // Name of the plugin, used in logs
+	Reexec *uint64 // Blocks to reexecute if the parent state is missing
+}
+
+// txTraceNotification is the execution trace of a single transaction, as
+// delivered to tracer plugins and block trace streams.
+type txTraceNotification struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	BlockHash   common.Hash    `json:"blockHash"`
+	TxHash      common.Hash    `json:"txHash"`
+	TxIndex     hexutil.Uint   `json:"txIndex"`
+	Result      interface{}    `json:"result,omitempty"` // Structured logs of the execution
+	Error       string         `json:"error,omitempty"`  // Trace failure produced by the tracer
+}
+
+// TracerPlugin registers an out-of-process tracer. Every block imported into
+// the canonical chain from then on is traced, and the plugin is sent the
+// structured execution events of each transaction in order, as one
+// notification per transaction.
+//
+// Plugins are expected to connect over IPC, as the debug namespace is only
+// exposed there by default and traces are large. A plugin too slow to keep up
+// with the chain has blocks skipped, which is logged by the node.
+func (api *PrivateDebugAPI) TracerPlugin(ctx context.Context, config *TracerPluginConfig) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	if config == nil {
+		config = new(TracerPluginConfig)
+	}
+	var (
+		rpcSub = notifier.CreateSubscription()
+		events = make(chan core.ChainEvent, 16)
+		sub    = api.eth.blockchain.SubscribeChainEvent(events)
+
+		backlog = newPluginBacklog(maxPluginBacklog)
+		failed  = make(chan struct{})
+		quit    = make(chan struct{})
+	)
+	logger := log.New("plugin", config.Name, "id", rpcSub.ID)
+	logger.Info("Tracer plugin registered", "plugins", atomic.AddInt32(&api.plugins, 1))
+
+	// Queue up imported blocks so the chain is never blocked by the plugin
+	go func() {
+		defer func() {
+			sub.Unsubscribe()
+			close(quit)
+			atomic.AddInt32(&api.plugins, -1)
+		}()
+		for {
+			select {
+			case ev := <-events:
+				if dropped := backlog.push(ev.Block); dropped != nil {
+					logger.Warn("Tracer plugin lagging, skipping block", "number", dropped.NumberU64(), "hash", dropped.Hash())
+				}
+			case <-failed:
+				logger.Warn("Tracer plugin dropped, delivery failed")
+				return
+			case <-rpcSub.Err():
+				logger.Info("Tracer plugin unregistered")
+				return
+			case <-notifier.Closed():
+				logger.Info("Tracer plugin disconnected")
+				return
+			}
+		}
+	}()
+	// Trace the queued blocks and stream the results to the plugin
+	go func() {
+		// The context of the registering call ends once it returns
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			<-quit
+			cancel()
+		}()
+
+		traceConfig := &TraceConfig{LogConfig: config.LogConfig, Reexec: config.Reexec}
+		for {
+			select {
+			case <-backlog.wake:
+			case <-quit:
+				return
+			}
+			for block := backlog.pop(); block != nil; block = backlog.pop() {
+				results, err := api.traceBlock(ctx, block, traceConfig)
+				if err != nil {
+					logger.Warn("Failed to trace block for plugin", "number", block.NumberU64(), "hash", block.Hash(), "err", err)
+					continue
+				}
+				for i, tx := range block.Transactions() {
+					trace := &txTraceNotification{
+						BlockNumber: hexutil.Uint64(block.NumberU64()),
+						BlockHash:   block.Hash(),
+						TxHash:      tx.Hash(),
+						TxIndex:     hexutil.Uint(i),
+						Result:      results[i].Result,
+						Error:       results[i].Error,
+					}
+					if err := notifier.Notify(rpcSub.ID, trace); err != nil {
+						logger.Debug("Failed to deliver trace to plugin", "err", err)
+						close(failed)
+						return
+					}
+				}
+			}
+		}
+	}()
+	return rpcSub, nil
+}
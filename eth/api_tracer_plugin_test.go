@@ -0,0 +1,178 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/celo-org/celo-blockchain/common"
+	mockEngine "github.com/celo-org/celo-blockchain/consensus/consensustest"
+	"github.com/celo-org/celo-blockchain/core"
+	"github.com/celo-org/celo-blockchain/core/rawdb"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/core/vm"
+	"github.com/celo-org/celo-blockchain/params"
+	"github.com/celo-org/celo-blockchain/rpc"
+)
+
+// newTracerPluginTest returns a debug API served over an in-process client,
+// along with blocks not yet imported into its chain, each holding a number of
+// transfers equal to its index plus one.
+func newTracerPluginTest(t *testing.T, blocks int) (*PrivateDebugAPI, *rpc.Client, []*types.Block, func()) {
+	var (
+		db     = rawdb.NewMemoryDatabase()
+		engine = mockEngine.NewFaker()
+		gspec  = &core.Genesis{
+			Config: params.IstanbulTestChainConfig,
+			Alloc:  core.GenesisAlloc{testBank: {Balance: big.NewInt(1000000)}},
+		}
+		genesis = gspec.MustCommit(db)
+		signer  = types.HomesteadSigner{}
+	)
+	chain, err := core.NewBlockChain(db, nil, gspec.Config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	generated, _ := core.GenerateChain(gspec.Config, genesis, engine, db, blocks, func(i int, block *core.BlockGen) {
+		for j := 0; j <= i; j++ {
+			tx, _ := types.SignTx(types.NewTransaction(block.TxNonce(testBank), common.Address{byte(j + 1)}, big.NewInt(1000), params.TxGas, nil, nil, nil, nil, nil), signer, testBankKey)
+			block.AddTx(tx)
+		}
+	})
+	eth := &Ethereum{blockchain: chain, engine: engine}
+	eth.APIBackend = &EthAPIBackend{eth: eth}
+	api := NewPrivateDebugAPI(eth)
+
+	server := rpc.NewServer()
+	if err := server.RegisterName("debug", api); err != nil {
+		t.Fatal(err)
+	}
+	client := rpc.DialInProc(server)
+	return api, client, generated, func() {
+		client.Close()
+		server.Stop()
+		chain.Stop()
+	}
+}
+
+// waitPlugins waits for the number of registered tracer plugins to settle at
+// the given count.
+func waitPlugins(t *testing.T, api *PrivateDebugAPI, want int32) {
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if atomic.LoadInt32(&api.plugins) == want {
+			return
+		}
+	}
+	t.Fatalf("registered plugins mismatch: have %d, want %d", atomic.LoadInt32(&api.plugins), want)
+}
+
+// Tests that a tracer plugin is sent one trace per transaction of the imported
+// blocks, in order, and that unsubscribing releases the chain subscription.
+func TestTracerPlugin(t *testing.T) {
+	api, client, blocks, stop := newTracerPluginTest(t, 3)
+	defer stop()
+
+	traces := make(chan *txTraceNotification, 16)
+	sub, err := client.Subscribe(context.Background(), "debug", traces, "tracerPlugin", &TracerPluginConfig{Name: "test"})
+	if err != nil {
+		t.Fatalf("failed to register plugin: %v", err)
+	}
+	waitPlugins(t, api, 1)
+
+	if _, err := api.eth.blockchain.InsertChain(blocks); err != nil {
+		t.Fatal(err)
+	}
+	for _, block := range blocks {
+		for i, tx := range block.Transactions() {
+			select {
+			case trace := <-traces:
+				if uint64(trace.BlockNumber) != block.NumberU64() || trace.BlockHash != block.Hash() {
+					t.Fatalf("block %d, tx %d: trace of block %d %x", block.NumberU64(), i, trace.BlockNumber, trace.BlockHash)
+				}
+				if int(trace.TxIndex) != i || trace.TxHash != tx.Hash() {
+					t.Fatalf("block %d, tx %d: trace of tx %d %x", block.NumberU64(), i, trace.TxIndex, trace.TxHash)
+				}
+				if trace.Error != "" || trace.Result == nil {
+					t.Errorf("block %d, tx %d: trace failed: %s", block.NumberU64(), i, trace.Error)
+				}
+			case err := <-sub.Err():
+				t.Fatalf("subscription failed: %v", err)
+			case <-time.After(5 * time.Second):
+				t.Fatalf("block %d, tx %d: trace not delivered", block.NumberU64(), i)
+			}
+		}
+	}
+	select {
+	case trace := <-traces:
+		t.Fatalf("unexpected trace of block %d, tx %d", trace.BlockNumber, trace.TxIndex)
+	case <-time.After(100 * time.Millisecond):
+	}
+	// Unsubscribing drops the plugin
+	sub.Unsubscribe()
+	waitPlugins(t, api, 0)
+}
+
+// Tests that a plugin whose connection goes away is dropped.
+func TestTracerPluginDisconnect(t *testing.T) {
+	api, client, _, stop := newTracerPluginTest(t, 0)
+	defer stop()
+
+	traces := make(chan *txTraceNotification)
+	if _, err := client.Subscribe(context.Background(), "debug", traces, "tracerPlugin", nil); err != nil {
+		t.Fatalf("failed to register plugin: %v", err)
+	}
+	waitPlugins(t, api, 1)
+
+	client.Close()
+	waitPlugins(t, api, 0)
+}
+
+// Tests that a full backlog drops its oldest blocks to make room.
+func TestPluginBacklogOverflow(t *testing.T) {
+	backlog := newPluginBacklog(2)
+
+	blocks := make([]*types.Block, 4)
+	for i := range blocks {
+		blocks[i] = types.NewBlockWithHeader(&types.Header{Number: big.NewInt(int64(i))})
+	}
+	for i, block := range blocks {
+		dropped := backlog.push(block)
+		switch {
+		case i < 2 && dropped != nil:
+			t.Errorf("block %d: dropped block %d from non-full backlog", i, dropped.NumberU64())
+		case i >= 2 && dropped != blocks[i-2]:
+			t.Errorf("block %d: dropped block mismatch: have %v, want %d", i, dropped, i-2)
+		}
+	}
+	select {
+	case <-backlog.wake:
+	default:
+		t.Errorf("queued blocks not signalled")
+	}
+	for i := 2; i < 4; i++ {
+		if block := backlog.pop(); block != blocks[i] {
+			t.Errorf("popped block mismatch: have %v, want %d", block, i)
+		}
+	}
+	if block := backlog.pop(); block != nil {
+		t.Errorf("popped block %d from empty backlog", block.NumberU64())
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/core/vm"
+	"github.com/celo-org/celo-blockchain/eth/tracers"
+	"github.com/celo-org/celo-blockchain/rpc"
+)
+
+// maxGasProfileBlocks is the maximum number of blocks profiled by a single call.
+const maxGasProfileBlocks = 1024
+
+// ProfileGas re-executes the blocks from start to end, both included, and
+// returns the gas and wall time spent per opcode and precompile by all their
+// transactions. See tracers.GasProfiler for how they are attributed.
+func (api *PrivateDebugAPI) ProfileGas(ctx context.Context, start, end rpc.BlockNumber, reexec *uint64) (*tracers.GasProfile, error) {
+	from, err := api.blockByNumber(start)
+	if err != nil {
+		return nil, err
+	}
+	to, err := api.blockByNumber(end)
+	if err != nil {
+		return nil, err
+	}
+	if from.NumberU64() == 0 {
+		return nil, errGenesisExecution
+	}
+	if from.NumberU64() > to.NumberU64() {
+		return nil, fmt.Errorf("start block #%d after end block #%d", from.NumberU64(), to.NumberU64())
+	}
+	if n := to.NumberU64() - from.NumberU64() + 1; n > maxGasProfileBlocks {
+		return nil, fmt.Errorf("block range of %d exceeds maximum of %d", n, maxGasProfileBlocks)
+	}
+	parent := api.eth.blockchain.GetBlock(from.ParentHash(), from.NumberU64()-1)
+	if parent == nil {
+		return nil, fmt.Errorf("parent %#x not found", from.ParentHash())
+	}
+	reexecBlocks := defaultTraceReexec
+	if reexec != nil {
+		reexecBlocks = *reexec
+	}
+	statedb, err := api.computeStateDB(ctx, parent, reexecBlocks)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		profiler = tracers.NewGasProfiler()
+		config   = vm.Config{Debug: true, Tracer: profiler}
+		chain    = api.eth.blockchain
+	)
+	for number := from.NumberU64(); number <= to.NumberU64(); number++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		block := chain.GetBlockByNumber(number)
+		if block == nil {
+			return nil, fmt.Errorf("block #%d not found", number)
+		}
+		if _, _, _, err := chain.Processor().Process(ctx, block, statedb, config); err != nil {
+			return nil, fmt.Errorf("processing block %d failed: %v", number, err)
+		}
+		// Commit the state so that the next block starts from it
+		root, err := statedb.Commit(chain.Config().IsEIP158(block.Number()))
+		if err != nil {
+			return nil, err
+		}
+		if err := statedb.Reset(root); err != nil {
+			return nil, fmt.Errorf("state reset after block %d failed: %v", number, err)
+		}
+	}
+	return profiler.Profile(), nil
+}
+
+// blockByNumber retrieves a canonical block, resolving the latest block.
+func (api *PrivateDebugAPI) blockByNumber(number rpc.BlockNumber) (*types.Block, error) {
+	var block *types.Block
+	if number == rpc.LatestBlockNumber || number == rpc.PendingBlockNumber {
+		block = api.eth.blockchain.CurrentBlock()
+	} else {
+		block = api.eth.blockchain.GetBlockByNumber(uint64(number))
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block #%d not found", number)
+	}
+	return block, nil
+}
@@ -0,0 +1,148 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/common/hexutil"
+	"github.com/celo-org/celo-blockchain/core"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/core/vm/vmcontext"
+	"github.com/celo-org/celo-blockchain/log"
+	"github.com/celo-org/celo-blockchain/rpc"
+)
+
+// TraceBlockByNumberStream traces the transactions of the block like
+// TraceBlockByNumber, but streams the result of each transaction as soon as it
+// is traced instead of returning them all at once.
+func (api *PrivateDebugAPI) TraceBlockByNumberStream(ctx context.Context, number rpc.BlockNumber, config *TraceConfig) (*rpc.Subscription, error) {
+	// Fetch the block that we want to trace
+	var block *types.Block
+
+	switch number {
+	case rpc.PendingBlockNumber:
+		block = api.eth.miner.PendingBlock()
+	case rpc.LatestBlockNumber:
+		block = api.eth.blockchain.CurrentBlock()
+	default:
+		block = api.eth.blockchain.GetBlockByNumber(uint64(number))
+	}
+	// Trace the block if it was found
+	if block == nil {
+		return nil, fmt.Errorf("block #%d not found", number)
+	}
+	return api.traceBlockStream(ctx, block, config)
+}
+
+// TraceBlockByHashStream traces the transactions of the block like
+// TraceBlockByHash, but streams the result of each transaction as soon as it
+// is traced instead of returning them all at once.
+func (api *PrivateDebugAPI) TraceBlockByHashStream(ctx context.Context, hash common.Hash, config *TraceConfig) (*rpc.Subscription, error) {
+	block := api.eth.blockchain.GetBlockByHash(hash)
+	if block == nil {
+		return nil, fmt.Errorf("block %#x not found", hash)
+	}
+	return api.traceBlockStream(ctx, block, config)
+}
+
+// traceBlockStream traces the transactions of the block one after the other and
+// sends one notification per transaction, in order. Contrary to traceBlock no
+// results are retained, so memory use does not grow with the size of the block,
+// and a slow client slows down tracing. The stream ends after the notification
+// of the last transaction, or at the first transaction that fails to execute.
+func (api *PrivateDebugAPI) traceBlockStream(ctx context.Context, block *types.Block, config *TraceConfig) (*rpc.Subscription, error) {
+	// Streaming requires a connection supporting notifications
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	// Create the parent state database
+	if err := api.eth.engine.VerifyHeader(api.eth.blockchain, block.Header(), true); err != nil {
+		return nil, err
+	}
+	parent := api.eth.blockchain.GetBlock(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil, fmt.Errorf("parent %#x not found", block.ParentHash())
+	}
+	reexec := defaultTraceReexec
+	if config != nil && config.Reexec != nil {
+		reexec = *config.Reexec
+	}
+	statedb, err := api.computeStateDB(ctx, parent, reexec)
+	if err != nil {
+		return nil, err
+	}
+	sub := notifier.CreateSubscription()
+
+	go func() {
+		// The context of the subscribing call ends once it returns
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			select {
+			case <-sub.Err():
+			case <-notifier.Closed():
+			case <-ctx.Done():
+			}
+			cancel()
+		}()
+
+		var (
+			begin  = time.Now()
+			signer = types.MakeSigner(api.eth.blockchain.Config(), block.Number())
+			txs    = block.Transactions()
+		)
+		for i, tx := range txs {
+			if ctx.Err() != nil {
+				log.Warn("Block trace stream aborted", "number", block.NumberU64(), "hash", block.Hash(), "transactions", i, "elapsed", time.Since(begin))
+				return
+			}
+			msg, _ := tx.AsMessage(signer)
+			vmctx := core.NewEVMContext(msg, block.Header(), api.eth.blockchain, nil)
+			vmRunner := vmcontext.NewEVMRunnerWithContext(ctx, api.eth.blockchain, block.Header(), statedb)
+
+			trace := &txTraceNotification{
+				BlockNumber: hexutil.Uint64(block.NumberU64()),
+				BlockHash:   block.Hash(),
+				TxHash:      tx.Hash(),
+				TxIndex:     hexutil.Uint(i),
+			}
+			// Tracing executes the transaction on top of the state, advancing it
+			res, traceErr := api.traceTx(ctx, msg, vmctx, vmRunner, statedb, config)
+			if traceErr != nil {
+				trace.Error = traceErr.Error()
+			} else {
+				trace.Result = res
+			}
+			if err := notifier.Notify(sub.ID, trace); err != nil {
+				return
+			}
+			if traceErr != nil {
+				log.Warn("Block trace stream failed", "number", block.NumberU64(), "hash", block.Hash(), "tx", tx.Hash(), "err", traceErr)
+				return
+			}
+			// Finalize the state so any modifications are written to the trie
+			// Only delete empty objects if EIP158/161 (a.k.a Spurious Dragon) is in effect
+			statedb.Finalise(api.eth.blockchain.Config().IsEIP158(block.Number()))
+		}
+	}()
+	return sub, nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/common/hexutil"
+	"github.com/celo-org/celo-blockchain/core"
+	"github.com/celo-org/celo-blockchain/core/rawdb"
+	"github.com/celo-org/celo-blockchain/core/state"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/crypto"
+	"github.com/celo-org/celo-blockchain/rpc"
+)
+
+var errGenesisExecution = errors.New("genesis is not executable")
+
+// Witness is the state required to execute a block without access to the
+// local state: the trie nodes on the paths of the accounts and storage slots
+// the block accesses and the code of the contracts it runs, all of them keyed
+// by their hash.
+type Witness struct {
+	Nodes []hexutil.Bytes `json:"nodes"`
+}
+
+// WitnessExecutionResult is the outcome of executing a block with a witness.
+type WitnessExecutionResult struct {
+	StateRoot    common.Hash    `json:"stateRoot"`
+	ReceiptsRoot common.Hash    `json:"receiptsRoot"`
+	GasUsed      hexutil.Uint64 `json:"gasUsed"`
+	Valid        bool           `json:"valid"`           // Whether the results match the block
+	Error        string         `json:"error,omitempty"` // Mismatch with the block, if any
+}
+
+// ExecuteWithWitness re-executes the given block using only the state supplied
+// by the witness instead of the local state, and checks the results against
+// the block. An error is returned if the witness lacks any of the state
+// accessed by the block.
+func (api *PrivateDebugAPI) ExecuteWithWitness(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, witness Witness) (*WitnessExecutionResult, error) {
+	block, err := api.eth.APIBackend.BlockByNumberOrHash(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		if hash, ok := blockNrOrHash.Hash(); ok {
+			return nil, fmt.Errorf("block %s not found", hash.Hex())
+		}
+		number, _ := blockNrOrHash.Number()
+		return nil, fmt.Errorf("block #%d not found", number)
+	}
+	if block.NumberU64() == 0 {
+		return nil, errGenesisExecution
+	}
+	parent := api.eth.blockchain.GetHeader(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil, fmt.Errorf("parent %x not found", block.ParentHash())
+	}
+	return executeWithWitness(ctx, api.eth.blockchain, block, parent, witness)
+}
+
+// executeWithWitness executes the block on top of the parent state built from
+// the witness alone.
+func executeWithWitness(ctx context.Context, chain *core.BlockChain, block *types.Block, parent *types.Header, witness Witness) (*WitnessExecutionResult, error) {
+	db := rawdb.NewMemoryDatabase()
+	for _, node := range witness.Nodes {
+		if err := db.Put(crypto.Keccak256(node), node); err != nil {
+			return nil, err
+		}
+	}
+	statedb, err := state.New(parent.Root, state.NewDatabase(db), nil)
+	if err != nil {
+		return nil, fmt.Errorf("incomplete witness: %v", err)
+	}
+	receipts, _, usedGas, err := chain.Processor().Process(ctx, block, statedb, *chain.GetVMConfig())
+	if dbErr := statedb.Error(); dbErr != nil {
+		return nil, fmt.Errorf("incomplete witness: %v", dbErr)
+	}
+	result := &WitnessExecutionResult{
+		ReceiptsRoot: types.DeriveSha(receipts),
+		GasUsed:      hexutil.Uint64(usedGas),
+	}
+	if err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+	// Hashing the post state touches the trie nodes of the collapsed paths
+	result.StateRoot = statedb.IntermediateRoot(chain.Config().IsEIP158(block.Number()))
+	if dbErr := statedb.Error(); dbErr != nil {
+		return nil, fmt.Errorf("incomplete witness: %v", dbErr)
+	}
+	if err := chain.Validator().ValidateState(block, statedb, receipts, usedGas); err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+	result.Valid = true
+	return result, nil
+}
@@ -0,0 +1,119 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/common/hexutil"
+	mockEngine "github.com/celo-org/celo-blockchain/consensus/consensustest"
+	"github.com/celo-org/celo-blockchain/core"
+	"github.com/celo-org/celo-blockchain/core/rawdb"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/core/vm"
+	"github.com/celo-org/celo-blockchain/crypto"
+	"github.com/celo-org/celo-blockchain/ethdb"
+	"github.com/celo-org/celo-blockchain/params"
+	"github.com/celo-org/celo-blockchain/rpc"
+)
+
+// fullWitness returns all the trie nodes and codes stored in the database,
+// a superset of the witness of any block.
+func fullWitness(t *testing.T, db ethdb.Database) Witness {
+	var witness Witness
+	it := db.NewIterator(nil, nil)
+	defer it.Release()
+	for it.Next() {
+		if len(it.Key()) == common.HashLength && bytes.Equal(crypto.Keccak256(it.Value()), it.Key()) {
+			witness.Nodes = append(witness.Nodes, common.CopyBytes(it.Value()))
+		}
+	}
+	if err := it.Error(); err != nil {
+		t.Fatal(err)
+	}
+	return witness
+}
+
+func TestExecuteWithWitness(t *testing.T) {
+	var (
+		db     = rawdb.NewMemoryDatabase()
+		engine = mockEngine.NewFaker()
+		gspec  = &core.Genesis{
+			Config: params.IstanbulTestChainConfig,
+			Alloc:  core.GenesisAlloc{testBank: {Balance: big.NewInt(1000000)}},
+		}
+		genesis = gspec.MustCommit(db)
+		signer  = types.HomesteadSigner{}
+	)
+	chain, err := core.NewBlockChain(db, nil, gspec.Config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chain.Stop()
+
+	blocks, _ := core.GenerateChain(gspec.Config, genesis, engine, db, 2, func(i int, block *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(block.TxNonce(testBank), common.Address{1}, big.NewInt(1000), params.TxGas, nil, nil, nil, nil, nil), signer, testBankKey)
+		block.AddTx(tx)
+	})
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatal(err)
+	}
+	eth := &Ethereum{blockchain: chain}
+	eth.APIBackend = &EthAPIBackend{eth: eth}
+	api := NewPrivateDebugAPI(eth)
+
+	// A witness holding the parent state executes the block
+	witness := fullWitness(t, db)
+	target := rpc.BlockNumberOrHashWithHash(blocks[1].Hash(), false)
+	result, err := api.ExecuteWithWitness(context.Background(), target, witness)
+	if err != nil {
+		t.Fatalf("execution failed: %v", err)
+	}
+	if !result.Valid {
+		t.Fatalf("block found invalid: %s", result.Error)
+	}
+	if result.StateRoot != blocks[1].Root() {
+		t.Errorf("state root mismatch: have %x, want %x", result.StateRoot, blocks[1].Root())
+	}
+	if result.GasUsed != hexutil.Uint64(blocks[1].GasUsed()) {
+		t.Errorf("gas used mismatch: have %d, want %d", result.GasUsed, blocks[1].GasUsed())
+	}
+
+	// A witness lacking the accessed state is rejected
+	if _, err := api.ExecuteWithWitness(context.Background(), target, Witness{}); err == nil {
+		t.Error("no error for empty witness")
+	}
+
+	// A block not matching the execution results is reported invalid
+	header := blocks[1].Header()
+	header.Root = common.Hash{1}
+	result, err = executeWithWitness(context.Background(), chain, blocks[1].WithHeader(header), blocks[0].Header(), witness)
+	if err != nil {
+		t.Fatalf("execution failed: %v", err)
+	}
+	if result.Valid || result.Error == "" {
+		t.Errorf("tampered block found valid")
+	}
+
+	if _, err := api.ExecuteWithWitness(context.Background(), rpc.BlockNumberOrHashWithNumber(0), witness); err != errGenesisExecution {
+		t.Errorf("wrong error for genesis execution: %v", err)
+	}
+}
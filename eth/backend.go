@@ -88,6 +88,15 @@ type Ethereum struct {
 
 	p2pServer *p2p.Server
 
+	diagnosticsDir string // Directory receiving debug_captureDiagnostics bundles
+
+	traceCache *traceCache // Results of recent transaction traces, nil if disabled
+
+	// Subsystems stopped ahead of the service itself, see ShutdownSteps
+	stopConsensusOnce sync.Once
+	stopMinerOnce     sync.Once
+	stopTxPoolOnce    sync.Once
+
 	lock sync.RWMutex // Protects the variadic fields (e.g. gas price, validator and txFeeRecipient)
 }
 
@@ -117,7 +126,7 @@ func New(stack *node.Node, config *Config) (*Ethereum, error) {
 		config.GatewayFee = new(big.Int).Set(DefaultConfig.GatewayFee)
 	}
 	// Assemble the Ethereum object
-	chainDb, err := stack.OpenDatabaseWithFreezer("chaindata", config.DatabaseCache, config.DatabaseHandles, config.DatabaseFreezer, "eth/db/chaindata/")
+	chainDb, err := stack.OpenDatabaseWithRemoteFreezer("chaindata", config.DatabaseCache, config.DatabaseHandles, config.DatabaseFreezer, config.DatabaseFreezerRemote, config.DatabaseFreezerCache, "eth/db/chaindata/")
 	if err != nil {
 		return nil, err
 	}
@@ -143,6 +152,13 @@ func New(stack *node.Node, config *Config) (*Ethereum, error) {
 		bloomRequests:     make(chan chan *bloombits.Retrieval),
 		bloomIndexer:      NewBloomIndexer(chainDb, params.BloomBitsBlocks, params.BloomConfirms, chainConfig.FullHeaderChainAvailable),
 		p2pServer:         stack.Server(),
+		diagnosticsDir:    stack.ResolvePath("diagnostics"),
+	}
+
+	if config.TraceCache > 0 {
+		if eth.traceCache, err = newTraceCache(config.TraceCache, config.TraceCacheDisk, stack.ResolvePath(config.TraceCacheJournal)); err != nil {
+			return nil, err
+		}
 	}
 
 	bcVersion := rawdb.ReadDatabaseVersion(chainDb)
@@ -175,6 +191,8 @@ func New(stack *node.Node, config *Config) (*Ethereum, error) {
 			TrieDirtyDisabled:   config.NoPruning,
 			TrieTimeLimit:       config.TrieTimeout,
 			SnapshotLimit:       config.SnapshotCache,
+			StatePreheat:        config.Preheat,
+			HistoryBlocks:       config.HistoryBlocks,
 		}
 	)
 	eth.blockchain, err = core.NewBlockChain(chainDb, cacheConfig, chainConfig, eth.engine, vmConfig, eth.shouldPreserve, &config.TxLookupLimit)
@@ -187,11 +205,22 @@ func New(stack *node.Node, config *Config) (*Ethereum, error) {
 		eth.blockchain.SetHead(compat.RewindTo)
 		rawdb.WriteChainConfig(chainDb, genesisHash, chainConfig)
 	}
+	if config.ForkDryRun != "" {
+		if err := eth.blockchain.SetForkDryRun(config.ForkDryRun, config.ForkDryRunBlocks); err != nil {
+			return nil, err
+		}
+	}
+	if config.TraceSampling > 0 {
+		eth.blockchain.SetTraceSampler(core.NewTraceSampler(config.TraceSampling))
+	}
 	eth.bloomIndexer.Start(eth.blockchain)
 
 	if config.TxPool.Journal != "" {
 		config.TxPool.Journal = stack.ResolvePath(config.TxPool.Journal)
 	}
+	if config.TxPool.RemoteJournal != "" {
+		config.TxPool.RemoteJournal = stack.ResolvePath(config.TxPool.RemoteJournal)
+	}
 
 	eth.txPool = core.NewTxPool(config.TxPool, chainConfig, eth.blockchain)
 
@@ -218,7 +247,10 @@ func New(stack *node.Node, config *Config) (*Ethereum, error) {
 	eth.miner = miner.New(eth, &config.Miner, chainConfig, eth.EventMux(), eth.engine, chainDb)
 	eth.miner.SetExtra(makeExtraData(config.Miner.ExtraData))
 
-	eth.APIBackend = &EthAPIBackend{stack.Config().ExtRPCEnabled(), eth}
+	eth.APIBackend = &EthAPIBackend{stack.Config().ExtRPCEnabled(), eth, nil}
+	if config.HistoryProxy && config.HistoryArchive != "" {
+		eth.APIBackend.archive = ethapi.NewArchiveProxy(config.HistoryArchive)
+	}
 
 	eth.dialCandidates, err = eth.setupDiscovery(&stack.Config().P2P)
 	if err != nil {
@@ -572,17 +604,60 @@ func (s *Ethereum) Start() error {
 // Ethereum protocol.
 func (s *Ethereum) Stop() error {
 	// Stop all the peer-related stuff first.
-	s.stopAnnounce()
+	s.stopConsensus()
 	s.protocolManager.Stop()
 
 	// Then stop everything else.
 	s.bloomIndexer.Close()
 	close(s.closeBloomHandler)
-	s.txPool.Stop()
-	s.miner.Stop()
+	s.stopTxPool()
+	s.stopMiner()
 	s.blockchain.Stop()
 	s.engine.Close()
+	s.APIBackend.archive.Close()
+	if s.traceCache != nil {
+		s.traceCache.close()
+	}
 	s.chainDb.Close()
 	s.eventMux.Stop()
 	return nil
 }
+
+// ShutdownSteps implements node.StagedStopper. Consensus is stopped first so
+// that no more blocks are agreed on, then the miner so that the transaction
+// pool is no longer drained, and finally the transaction pool itself. The rest
+// of the service is stopped by Stop.
+func (s *Ethereum) ShutdownSteps() []node.ShutdownStep {
+	return []node.ShutdownStep{
+		{Stage: node.ShutdownConsensus, Name: "consensus", Stop: s.stopConsensus},
+		{Stage: node.ShutdownMiner, Name: "miner", Stop: s.stopMiner},
+		{Stage: node.ShutdownTxPool, Name: "txpool", Stop: s.stopTxPool},
+	}
+}
+
+// stopConsensus stops announcing and validating.
+func (s *Ethereum) stopConsensus() error {
+	s.stopConsensusOnce.Do(func() {
+		engine, ok := s.engine.(consensus.Istanbul)
+		if !ok {
+			return
+		}
+		s.stopAnnounce()
+		if err := engine.StopValidating(); err != nil && err != istanbul.ErrStoppedEngine {
+			log.Warn("Error in stopping validating", "err", err)
+		}
+	})
+	return nil
+}
+
+// stopMiner terminates the miner.
+func (s *Ethereum) stopMiner() error {
+	s.stopMinerOnce.Do(s.miner.Close)
+	return nil
+}
+
+// stopTxPool terminates the transaction pool.
+func (s *Ethereum) stopTxPool() error {
+	s.stopTxPoolOnce.Do(s.txPool.Stop)
+	return nil
+}
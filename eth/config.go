@@ -42,11 +42,18 @@ var DefaultConfig = Config{
 	TrieDirtyCache:          256,
 	TrieTimeout:             60 * time.Minute,
 	SnapshotCache:           102,
+	TraceCacheJournal:       "tracecache",
 	GatewayFee:              big.NewInt(0),
 
-	TxPool:      core.DefaultTxPoolConfig,
-	RPCGasCap:   25000000,
-	RPCTxFeeCap: 500, // 500 celo
+	TxPool:        core.DefaultTxPoolConfig,
+	RPCGasCap:     25000000,
+	RPCEVMTimeout: 50 * time.Second,
+	RPCTxFeeCap:   500, // 500 celo
+
+	RPCCallCacheTTL:  3 * time.Second,
+	RPCCallCacheSize: 4096,
+
+	ForkDryRunBlocks: 1000,
 
 	Istanbul: *istanbul.DefaultConfig,
 }
@@ -68,9 +75,15 @@ type Config struct {
 
 	NoPruning  bool // Whether to disable pruning and flush everything to disk
 	NoPrefetch bool // Whether to disable prefetching and only load state on demand
+	Preheat    bool // Whether to load the state of the hot core contracts into the caches on startup
 
 	TxLookupLimit uint64 `toml:",omitempty"` // The maximum number of blocks from head whose tx indices are reserved.
 
+	// History pruning options
+	HistoryBlocks  uint64 `toml:",omitempty"` // Number of recent blocks whose bodies and receipts are retained (0 = all)
+	HistoryArchive string `toml:",omitempty"` // Archive node pointed to in the errors for pruned data
+	HistoryProxy   bool   `toml:",omitempty"` // Whether to proxy the RPC requests for pruned data to the archive node
+
 	// Whitelist of required block number -> hash values to accept
 	Whitelist map[uint64]common.Hash `toml:"-"`
 
@@ -99,6 +112,9 @@ type Config struct {
 	DatabaseCache      int
 	DatabaseFreezer    string
 
+	DatabaseFreezerRemote string `toml:",omitempty"` // Object store location sealed ancient files are offloaded to
+	DatabaseFreezerCache  int    `toml:",omitempty"` // Number of sealed ancient files per table kept locally when offloading
+
 	TrieCleanCache          int
 	TrieCleanCacheJournal   string        `toml:",omitempty"` // Disk journal directory for trie cache to survive node restarts
 	TrieCleanCacheRejournal time.Duration `toml:",omitempty"` // Time interval to regenerate the journal for clean cache
@@ -106,6 +122,10 @@ type Config struct {
 	TrieTimeout             time.Duration
 	SnapshotCache           int
 
+	TraceCache        int    `toml:",omitempty"` // Number of transaction traces cached in memory (0 = disabled)
+	TraceCacheDisk    int    `toml:",omitempty"` // Number of traces evicted from memory kept on disk
+	TraceCacheJournal string `toml:",omitempty"` // Disk directory of the traces evicted from memory
+
 	// Mining options
 	Miner miner.Config
 
@@ -115,6 +135,9 @@ type Config struct {
 	// Enables tracking of SHA3 preimages in the VM
 	EnablePreimageRecording bool
 
+	// Fraction of the imported transactions traced for execution statistics
+	TraceSampling float64 `toml:",omitempty"`
+
 	// Istanbul options
 	Istanbul istanbul.Config
 
@@ -130,16 +153,39 @@ type Config struct {
 	// RPCGasCap is the global gas cap for eth-call variants.
 	RPCGasCap uint64 `toml:",omitempty"`
 
+	// RPCEVMTimeout is the global timeout for eth-call variants, after which
+	// the execution is aborted (0 = no timeout).
+	RPCEVMTimeout time.Duration `toml:",omitempty"`
+
 	// RPCTxFeeCap is the global transaction fee(price * gaslimit) cap for
 	// send-transction variants. The unit is ether.
 	RPCTxFeeCap float64 `toml:",omitempty"`
 
+	// RPCEthCompatibility enables the EIP-1559 fee fields expected by Ethereum
+	// tooling, mapping the base fee to the gas price minimum.
+	RPCEthCompatibility bool `toml:",omitempty"`
+
+	// RPCCallCacheTTL is how long the result of an eth_call is reused for the
+	// identical calls on the same block (0 = no caching).
+	RPCCallCacheTTL time.Duration `toml:",omitempty"`
+
+	// RPCCallCacheSize is the maximum number of eth_call results cached.
+	RPCCallCacheSize int `toml:",omitempty"`
+
 	// Checkpoint is a hardcoded checkpoint which can be nil.
 	Checkpoint *params.TrustedCheckpoint `toml:",omitempty"`
 
 	// CheckpointOracle is the configuration for checkpoint oracle.
 	CheckpointOracle *params.CheckpointOracleConfig `toml:",omitempty"`
 
+	// ForkDryRun is the name of a hard fork whose rules are applied in shadow to
+	// the blocks preceding its activation, to report any divergence ("" = off).
+	ForkDryRun string `toml:",omitempty"`
+
+	// ForkDryRunBlocks is the number of blocks executed in shadow by the hard
+	// fork dry run.
+	ForkDryRunBlocks uint64 `toml:",omitempty"`
+
 	// E block override (TODO: remove after the fork)
 	OverrideEHardfork *big.Int `toml:",omitempty"`
 }
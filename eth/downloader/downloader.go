@@ -167,6 +167,12 @@ type Downloader struct {
 	bodyFetchHook    func([]*types.Header) // Method to call upon starting a block body fetch
 	receiptFetchHook func([]*types.Header) // Method to call upon starting a receipt fetch
 	chainInsertHook  func([]*fetchResult)  // Method to call upon inserting a chain of blocks (possibly in multiple invocations)
+
+	// Statistics of the current sync cycle, protected by syncStatsLock
+	syncStatsHeaders     uint64    // Highest header number processed
+	syncStatsCycleOrigin uint64    // Origin block number of the cycle
+	syncStatsCycleStates uint64    // Number of state entries processed when the cycle started
+	syncStatsCycleStart  time.Time // Time the cycle started
 }
 
 // LightChain encapsulates functions required to synchronise a light chain.
@@ -488,6 +494,10 @@ func (d *Downloader) syncWithPeer(p *peerConnection, hash common.Hash, td *big.I
 	}
 	log.Debug(fmt.Sprintf("After the check origin is %d height is %d", origin, height))
 	d.syncStatsChainHeight = height
+	d.syncStatsHeaders = origin
+	d.syncStatsCycleOrigin = origin
+	d.syncStatsCycleStates = d.syncStatsState.processed
+	d.syncStatsCycleStart = time.Now()
 	d.syncStatsLock.Unlock()
 
 	// Ensure our origin point is below any fast sync pivot point
@@ -1628,6 +1638,10 @@ func (d *Downloader) processHeaders(origin uint64, pivot uint64, td *big.Int) er
 						rollback = 1
 					}
 				}
+				d.syncStatsLock.Lock()
+				d.syncStatsHeaders = chunk[len(chunk)-1].Number.Uint64()
+				d.syncStatsLock.Unlock()
+
 				// Unless we're doing light chains, schedule the headers for associated content retrieval
 				if mode.SyncFullBlockChain() {
 					// If we've reached the allowed number of pending headers, stall a bit
@@ -1726,22 +1740,16 @@ func max(a uint64, b uint64) uint64 {
 	return a
 }
 
+// computePivot returns the fast sync pivot for the given chain height: the
+// last block of the latest epoch completed fsMinFullBlocks before the height.
+// Epoch blocks are always seal verified during header import, so the pivot is
+// backed by the aggregated seal of the epoch's validator set.
 func computePivot(height uint64, epochSize uint64) uint64 {
 	if height <= fsMinFullBlocks {
 		return 0
 	}
 	target := height - fsMinFullBlocks
-	targetEpoch := istanbul.GetEpochNumber(target, epochSize)
-
-	// if target is on first epoch start on genesis
-	if targetEpoch <= 1 {
-		return 0
-	}
-
-	// else start on first block of the epoch
-	pivot, _ := istanbul.GetEpochFirstBlockNumber(targetEpoch, epochSize)
-	return pivot
-
+	return istanbul.GetEpochLastBlockNumber(target/epochSize, epochSize)
 }
 
 func (d *Downloader) calcPivot(height uint64) uint64 {
@@ -1760,7 +1768,7 @@ func (d *Downloader) calcPivot(height uint64) uint64 {
 func (d *Downloader) processFastSyncContent(latest *types.Header) error {
 	// Start syncing state of the reported head block. This should get us most of
 	// the state of the pivot block.
-	sync := d.syncState(latest.Root)
+	sync := d.syncState(latest.Root, latest.Number.Uint64())
 	defer sync.Cancel()
 	closeOnErr := func(s *stateSync) {
 		if err := s.Wait(); err != nil && err != errCancelStateFetch && err != errCanceled {
@@ -1822,7 +1830,7 @@ func (d *Downloader) processFastSyncContent(latest *types.Header) error {
 			if oldPivot != P {
 				sync.Cancel()
 
-				sync = d.syncState(P.Header.Root)
+				sync = d.syncState(P.Header.Root, P.Header.Number.Uint64())
 				defer sync.Cancel()
 				go closeOnErr(sync)
 				oldPivot = P
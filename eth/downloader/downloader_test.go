@@ -32,6 +32,7 @@ import (
 	"github.com/celo-org/celo-blockchain/core/types"
 	"github.com/celo-org/celo-blockchain/ethdb"
 	"github.com/celo-org/celo-blockchain/event"
+	"github.com/celo-org/celo-blockchain/log"
 	"github.com/celo-org/celo-blockchain/params"
 	"github.com/celo-org/celo-blockchain/trie"
 )
@@ -1224,6 +1225,68 @@ func checkProgress(t *testing.T, d *Downloader, stage string, want ethereum.Sync
 	}
 }
 
+// Tests that the status reports the stages of the sync mode and the one
+// holding the sync back.
+func TestSyncStatus66Full(t *testing.T)  { testSyncStatus(t, 66, FullSync) }
+func TestSyncStatus66Fast(t *testing.T)  { testSyncStatus(t, 66, FastSync) }
+func TestSyncStatus66Light(t *testing.T) { testSyncStatus(t, 66, LightSync) }
+
+func testSyncStatus(t *testing.T, protocol int, mode SyncMode) {
+	t.Parallel()
+
+	tester := newTester()
+	defer tester.terminate()
+	chain := testChainBase.shorten(blockCacheItems - 15)
+	tester.newPeer("peer", protocol, chain)
+
+	// Block imports trail the headers, capture the status on the first one
+	var importing *SyncStatus
+	tester.downloader.chainInsertHook = func(results []*fetchResult) {
+		if importing == nil {
+			status := tester.downloader.Status()
+			importing = &status
+		}
+	}
+	if err := tester.sync("peer", nil, mode); err != nil {
+		t.Fatalf("failed to synchronise blocks: %v", err)
+	}
+	if mode == FullSync {
+		if importing == nil {
+			t.Fatalf("no block imported")
+		}
+		if importing.Stage != StageBodies {
+			t.Errorf("stage mismatch while importing: have %v, want %v", importing.Stage, StageBodies)
+		}
+	}
+
+	wantStages := map[SyncMode][]SyncStage{
+		FullSync:  {StageHeaders, StageBodies},
+		FastSync:  {StageHeaders, StageBodies, StageReceipts, StageStateHeal},
+		LightSync: {StageHeaders},
+	}[mode]
+	status := tester.downloader.Status()
+	if status.Mode != mode {
+		t.Errorf("mode mismatch: have %v, want %v", status.Mode, mode)
+	}
+	if len(status.Stages) != len(wantStages) {
+		t.Fatalf("stage count mismatch: have %d, want %d", len(status.Stages), len(wantStages))
+	}
+	for i, stage := range status.Stages {
+		if stage.Stage != wantStages[i] {
+			t.Errorf("stage %d mismatch: have %v, want %v", i, stage.Stage, wantStages[i])
+		}
+		if stage.Current != stage.Highest {
+			t.Errorf("stage %v incomplete: have %d, want %d", stage.Stage, stage.Current, stage.Highest)
+		}
+		if stage.Stage != StageStateHeal && stage.Highest != uint64(chain.len()-1) {
+			t.Errorf("stage %v highest mismatch: have %d, want %d", stage.Stage, stage.Highest, chain.len()-1)
+		}
+	}
+	if status.ETA != 0 {
+		t.Errorf("completed sync has an ETA: %v", status.ETA)
+	}
+}
+
 // Tests that synchronisation progress (origin block number and highest block
 // number) is tracked and updated correctly in case of a fork (or manual head
 // revertal).
@@ -1672,10 +1735,11 @@ func TestPivot(t *testing.T) {
 		{0, 0, 0},
 		{172, 17280, 0},
 		{17280, 17280, 0},
-		{17280*10 + 1000, 17280, 17280*10 + 1},
-		{17280*10 + 10, 17280, 17280*9 + 1},
-		{17280 * 10, 17280, 17280*9 + 1},
-		{17280*10 - 1000, 17280, 17280*9 + 1},
+		{17280 + 64, 17280, 17280},
+		{17280*10 + 1000, 17280, 17280 * 10},
+		{17280*10 + 10, 17280, 17280 * 9},
+		{17280 * 10, 17280, 17280 * 9},
+		{17280*10 - 1000, 17280, 17280 * 9},
 	}
 	for _, tt := range testCases {
 		if res := computePivot(tt.height, tt.epoch); res != tt.expected {
@@ -1683,3 +1747,33 @@ func TestPivot(t *testing.T) {
 		}
 	}
 }
+
+// recentStateTestPeer is a sync peer advertising the number of recent states it serves.
+type recentStateTestPeer struct {
+	Peer
+	recent uint64
+}
+
+func (p *recentStateTestPeer) ServeRecentState() uint64 { return p.recent }
+
+// Tests that the state of old blocks is not requested from the peers which pruned it.
+func TestPeerServesState(t *testing.T) {
+	testCases := []struct {
+		peer   Peer
+		number uint64
+		height uint64
+		serves bool
+	}{
+		{&recentStateTestPeer{recent: 124}, 1000, 1100, true},
+		{&recentStateTestPeer{recent: 124}, 1000, 1123, true},
+		{&recentStateTestPeer{recent: 124}, 1000, 1124, false},
+		{&recentStateTestPeer{recent: 0}, 1000, 100000, true},
+		{&lightPeerWrapper{}, 1000, 100000, true},
+	}
+	for i, tt := range testCases {
+		p := newPeerConnection("peer", 66, tt.peer, log.New())
+		if serves := p.ServesState(tt.number, tt.height); serves != tt.serves {
+			t.Errorf("test %d: serves mismatch: have %v, want %v", i, serves, tt.serves)
+		}
+	}
+}
@@ -67,6 +67,8 @@ type peerConnection struct {
 
 	lacking map[common.Hash]struct{} // Set of hashes not to request (didn't have previously)
 
+	stateRecent uint64 // Number of recent blocks whose state the peer serves, 0 for all
+
 	peer Peer
 
 	version int        // Eth protocol version number to switch strategies
@@ -89,6 +91,12 @@ type Peer interface {
 	RequestNodeData([]common.Hash) error
 }
 
+// recentStatePeer is implemented by the peers advertising how many recent states
+// they serve, so the state of older blocks is not requested from them.
+type recentStatePeer interface {
+	ServeRecentState() uint64
+}
+
 // lightPeerWrapper wraps a LightPeer struct, stubbing out the Peer-only methods.
 type lightPeerWrapper struct {
 	peer LightPeer
@@ -113,13 +121,17 @@ func (w *lightPeerWrapper) RequestNodeData([]common.Hash) error {
 
 // newPeerConnection creates a new downloader peer.
 func newPeerConnection(id string, version int, peer Peer, logger log.Logger) *peerConnection {
-	return &peerConnection{
+	p := &peerConnection{
 		id:      id,
 		lacking: make(map[common.Hash]struct{}),
 		peer:    peer,
 		version: version,
 		log:     logger,
 	}
+	if peer, ok := peer.(recentStatePeer); ok {
+		p.stateRecent = peer.ServeRecentState()
+	}
+	return p
 }
 
 // Reset clears the internal state of a peer entity.
@@ -488,6 +500,12 @@ func (ps *peerSet) ReceiptIdlePeers() ([]*peerConnection, int) {
 	return ps.idlePeers(64, 66, idle, throughput)
 }
 
+// ServesState returns whether the peer still serves the state of the given block,
+// assuming it is synced up to the given height.
+func (p *peerConnection) ServesState(number uint64, height uint64) bool {
+	return p.stateRecent == 0 || number+p.stateRecent > height
+}
+
 // NodeDataIdlePeers retrieves a flat list of all the currently node-data-idle
 // peers within the active peer set, ordered by their reputation.
 func (ps *peerSet) NodeDataIdlePeers() ([]*peerConnection, int) {
@@ -58,10 +58,11 @@ type stateSyncStats struct {
 	pending    uint64 // Number of still pending state entries
 }
 
-// syncState starts downloading state with the given root hash.
-func (d *Downloader) syncState(root common.Hash) *stateSync {
+// syncState starts downloading state with the given root hash, of the block with
+// the given number.
+func (d *Downloader) syncState(root common.Hash, number uint64) *stateSync {
 	// Create the state sync
-	s := newStateSync(d, root)
+	s := newStateSync(d, root, number)
 	select {
 	case d.stateSyncStart <- s:
 		// If we tell the statesync to restart with a new root, we also need
@@ -266,7 +267,8 @@ type stateSync struct {
 	done       chan struct{}  // Channel to signal termination completion
 	err        error          // Any error hit during sync (set before completion)
 
-	root common.Hash
+	root   common.Hash
+	number uint64 // Number of the block whose state is synced
 }
 
 // stateTask represents a single trie node download task, containing a set of
@@ -277,7 +279,7 @@ type stateTask struct {
 
 // newStateSync creates a new state trie download scheduler. This method does not
 // yet start the sync. The user needs to call run to initiate.
-func newStateSync(d *Downloader, root common.Hash) *stateSync {
+func newStateSync(d *Downloader, root common.Hash, number uint64) *stateSync {
 	return &stateSync{
 		d:       d,
 		sched:   state.NewStateSync(root, d.stateDB, d.stateBloom),
@@ -288,6 +290,7 @@ func newStateSync(d *Downloader, root common.Hash) *stateSync {
 		done:    make(chan struct{}),
 		started: make(chan struct{}),
 		root:    root,
+		number:  number,
 	}
 }
 
@@ -407,7 +410,16 @@ func (s *stateSync) commit(force bool) error {
 func (s *stateSync) assignTasks() {
 	// Iterate over all idle peers and try to assign them state fetches
 	peers, _ := s.d.peers.NodeDataIdlePeers()
+
+	s.d.syncStatsLock.RLock()
+	height := s.d.syncStatsChainHeight
+	s.d.syncStatsLock.RUnlock()
+
 	for _, p := range peers {
+		// Skip the peers which pruned the state being synced
+		if !p.ServesState(s.number, height) {
+			continue
+		}
 		// Assign a batch of fetches proportional to the estimated latency/bandwidth
 		cap := p.NodeDataCapacity(s.d.requestRTT())
 		req := &stateReq{peer: p, timeout: s.d.requestTTL()}
@@ -0,0 +1,136 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package downloader
+
+import "time"
+
+// SyncStage is a stage of a synchronisation cycle. The stages of a cycle run
+// concurrently, each one trailing the previous one.
+type SyncStage int
+
+const (
+	StageHeaders     SyncStage = iota // Downloading headers
+	StageBodies                       // Downloading block bodies (full and fast sync)
+	StageReceipts                     // Downloading receipts (fast sync)
+	StageStateHeal                    // Downloading the state at the pivot block (fast sync)
+	StageEpochProofs                  // Downloading the epoch headers proving validator set changes (lightest sync)
+)
+
+// String implements the stringer interface.
+func (stage SyncStage) String() string {
+	switch stage {
+	case StageHeaders:
+		return "headers"
+	case StageBodies:
+		return "bodies"
+	case StageReceipts:
+		return "receipts"
+	case StageStateHeal:
+		return "stateHeal"
+	case StageEpochProofs:
+		return "epochProofs"
+	default:
+		return "unknown"
+	}
+}
+
+// StageProgress is the progress of a sync stage. It is measured in block numbers,
+// except for the state heal stage which counts state entries.
+type StageProgress struct {
+	Stage   SyncStage
+	Current uint64
+	Highest uint64
+}
+
+// SyncStatus details the progress of the current synchronisation cycle.
+type SyncStatus struct {
+	Mode   SyncMode
+	Stage  SyncStage       // Stage holding the sync back
+	Stages []StageProgress // Progress of all the stages of the sync mode
+	ETA    time.Duration   // Estimated time for the stage to complete, zero if unknown
+}
+
+// Status retrieves the stages of the current synchronisation cycle and their
+// progress. The reported stage is the one furthest behind, as the others can't
+// get ahead of it: the state download once it started, otherwise the block
+// stage with the lowest block number.
+func (d *Downloader) Status() SyncStatus {
+	d.syncStatsLock.RLock()
+	defer d.syncStatsLock.RUnlock()
+
+	mode := d.getMode()
+	status := SyncStatus{Mode: mode}
+	height := d.syncStatsChainHeight
+
+	switch mode {
+	case FullSync, FastSync:
+		headers := d.syncStatsHeaders
+		status.Stages = append(status.Stages, StageProgress{StageHeaders, headers, height})
+		if mode == FullSync {
+			status.Stages = append(status.Stages, StageProgress{StageBodies, d.blockchain.CurrentBlock().NumberU64(), height})
+			break
+		}
+		// Fast sync commits bodies and receipts together, use the retrievals still
+		// pending to tell them apart.
+		committed := d.blockchain.CurrentFastBlock().NumberU64()
+		status.Stages = append(status.Stages,
+			StageProgress{StageBodies, trailing(headers, d.queue.PendingBlocks(), committed), height},
+			StageProgress{StageReceipts, trailing(headers, d.queue.PendingReceipts(), committed), height},
+		)
+		state := d.syncStatsState
+		status.Stages = append(status.Stages, StageProgress{StageStateHeal, state.processed, state.processed + state.pending})
+	case LightSync:
+		status.Stages = append(status.Stages, StageProgress{StageHeaders, d.lightchain.CurrentHeader().Number.Uint64(), height})
+	case LightestSync:
+		status.Stages = append(status.Stages, StageProgress{StageEpochProofs, d.lightchain.CurrentHeader().Number.Uint64(), height})
+	}
+
+	// Find the stage holding the sync back
+	current := status.Stages[0]
+	for _, stage := range status.Stages[1:] {
+		switch {
+		case stage.Stage == StageStateHeal:
+			if stage.Current < stage.Highest {
+				current = stage
+			}
+		case stage.Current < current.Current:
+			current = stage
+		}
+	}
+	status.Stage = current.Stage
+
+	// Extrapolate the time left from the stage's progress during the cycle
+	start := d.syncStatsCycleOrigin
+	if current.Stage == StageStateHeal {
+		start = d.syncStatsCycleStates
+	}
+	if !d.syncStatsCycleStart.IsZero() && current.Current > start && current.Highest > current.Current {
+		elapsed := time.Since(d.syncStatsCycleStart)
+		status.ETA = time.Duration(float64(elapsed) * float64(current.Highest-current.Current) / float64(current.Current-start))
+	}
+	return status
+}
+
+// trailing returns the block number a content retrieval reached, given the
+// scheduled headers and the retrievals still pending, but at least the block
+// number already committed.
+func trailing(headers uint64, pending int, committed uint64) uint64 {
+	if headers < committed || uint64(pending) >= headers-committed {
+		return committed
+	}
+	return headers - uint64(pending)
+}
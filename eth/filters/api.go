@@ -28,6 +28,7 @@ import (
 	ethereum "github.com/celo-org/celo-blockchain"
 	"github.com/celo-org/celo-blockchain/common"
 	"github.com/celo-org/celo-blockchain/common/hexutil"
+	"github.com/celo-org/celo-blockchain/core"
 	"github.com/celo-org/celo-blockchain/core/types"
 	"github.com/celo-org/celo-blockchain/ethdb"
 	"github.com/celo-org/celo-blockchain/rpc"
@@ -165,6 +166,54 @@ func (api *PublicFilterAPI) NewPendingTransactions(ctx context.Context) (*rpc.Su
 	return rpcSub, nil
 }
 
+// txPoolChange is the notification of a change to the transaction pool contents.
+type txPoolChange struct {
+	Kind     core.TxPoolChangeKind   `json:"kind"`
+	Hash     common.Hash             `json:"hash"`
+	Replaced *common.Hash            `json:"replaced,omitempty"`
+	Reason   core.TxPoolRemoveReason `json:"reason,omitempty"`
+}
+
+// Txpool creates a subscription that is triggered each time a transaction is
+// added to, replaced in or removed from the transaction pool. Removals carry the
+// reason the transaction left the pool, e.g. being included in a block or priced out.
+func (api *PublicFilterAPI) Txpool(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		changes := make(chan core.TxPoolChangeEvent, 128)
+		changesSub := api.backend.SubscribeTxPoolChangeEvent(changes)
+		defer changesSub.Unsubscribe()
+
+		for {
+			select {
+			case ev := <-changes:
+				for _, change := range ev.Changes {
+					n := &txPoolChange{Kind: change.Kind, Hash: change.Tx.Hash(), Reason: change.Reason}
+					if change.Replaced != nil {
+						hash := change.Replaced.Hash()
+						n.Replaced = &hash
+					}
+					notifier.Notify(rpcSub.ID, n)
+				}
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			case <-changesSub.Err():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
 // NewBlockFilter creates a filter that fetches blocks that are imported into the chain.
 // It is part of the filter package since polling goes with eth_getFilterChanges.
 //
@@ -201,22 +250,51 @@ func (api *PublicFilterAPI) NewBlockFilter() rpc.ID {
 }
 
 // NewHeads send a notification each time a new (header) block is appended to the chain.
-func (api *PublicFilterAPI) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
+// If a cursor of a previous subscription is given, the headers appended since
+// are sent first.
+func (api *PublicFilterAPI) NewHeads(ctx context.Context, cursor *Cursor) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
 		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
 	}
-
-	rpcSub := notifier.CreateSubscription()
+	var (
+		rpcSub     = notifier.CreateSubscription()
+		headers    = make(chan *types.Header)
+		headersSub = api.events.SubscribeNewHeads(headers)
+	)
+	backfill, err := api.backfill(ctx, cursor, nil)
+	if err != nil {
+		headersSub.Unsubscribe()
+		return nil, err
+	}
 
 	go func() {
-		headers := make(chan *types.Header)
-		headersSub := api.events.SubscribeNewHeads(headers)
-
+		var (
+			queued    []*types.Header
+			delivered map[common.Hash]bool
+		)
 		for {
 			select {
 			case h := <-headers:
-				notifier.Notify(rpcSub.ID, h)
+				if backfill != nil {
+					queued = append(queued, h)
+					continue
+				}
+				if !delivered[h.Hash()] {
+					notifier.Notify(rpcSub.ID, h)
+				}
+			case res := <-backfill:
+				delivered = make(map[common.Hash]bool)
+				for _, h := range res.headers {
+					notifier.Notify(rpcSub.ID, h)
+					delivered[h.Hash()] = true
+				}
+				for _, h := range queued {
+					if !delivered[h.Hash()] {
+						notifier.Notify(rpcSub.ID, h)
+					}
+				}
+				backfill, queued = nil, nil
 			case <-rpcSub.Err():
 				headersSub.Unsubscribe()
 				return
@@ -231,11 +309,16 @@ func (api *PublicFilterAPI) NewHeads(ctx context.Context) (*rpc.Subscription, er
 }
 
 // Logs creates a subscription that fires for all new log that match the given filter criteria.
-func (api *PublicFilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc.Subscription, error) {
+// If a cursor of a previous subscription is given, the matching logs emitted
+// since are sent first, preceded by the delivered logs that were reorged out.
+func (api *PublicFilterAPI) Logs(ctx context.Context, crit FilterCriteria, cursor *Cursor) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
 		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
 	}
+	if cursor != nil && crit.ToBlock != nil && crit.ToBlock.Int64() == rpc.PendingBlockNumber.Int64() {
+		return nil, errResumePending
+	}
 
 	var (
 		rpcSub      = notifier.CreateSubscription()
@@ -246,15 +329,43 @@ func (api *PublicFilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc
 	if err != nil {
 		return nil, err
 	}
+	backfill, err := api.backfill(ctx, cursor, &crit)
+	if err != nil {
+		logsSub.Unsubscribe()
+		return nil, err
+	}
 
 	go func() {
-
+		var (
+			queued    []*types.Log
+			delivered map[common.Hash]bool
+		)
 		for {
 			select {
 			case logs := <-matchedLogs:
+				if backfill != nil {
+					queued = append(queued, logs...)
+					continue
+				}
 				for _, log := range logs {
+					if log.Removed || !delivered[log.BlockHash] {
+						notifier.Notify(rpcSub.ID, &log)
+					}
+				}
+			case res := <-backfill:
+				delivered = make(map[common.Hash]bool)
+				for _, h := range res.headers {
+					delivered[h.Hash()] = true
+				}
+				for _, log := range res.logs {
 					notifier.Notify(rpcSub.ID, &log)
 				}
+				for _, log := range queued {
+					if log.Removed || !delivered[log.BlockHash] {
+						notifier.Notify(rpcSub.ID, &log)
+					}
+				}
+				backfill, queued = nil, nil
 			case <-rpcSub.Err(): // client send an unsubscribe request
 				logsSub.Unsubscribe()
 				return
@@ -318,9 +429,11 @@ func (api *PublicFilterAPI) NewFilter(crit FilterCriteria) (rpc.ID, error) {
 }
 
 // GetLogs returns logs matching the given argument that are stored within the state.
+// If the range reaches history pruned by the node, the request is proxied to the
+// archive node if configured, its logs being returned with their provenance.
 //
 // https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_getlogs
-func (api *PublicFilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) ([]*types.Log, error) {
+func (api *PublicFilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) (interface{}, error) {
 	var filter *Filter
 	if crit.BlockHash != nil {
 		// Block filter requested, construct a single-shot filter
@@ -341,7 +454,7 @@ func (api *PublicFilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) ([
 	// Run the filter and return all the logs
 	logs, err := filter.Logs(ctx)
 	if err != nil {
-		return nil, err
+		return api.backend.ArchiveProxy().Forward(ctx, err, "eth_getLogs", crit)
 	}
 	return returnLogs(logs), err
 }
@@ -453,6 +566,27 @@ func returnLogs(logs []*types.Log) []*types.Log {
 	return logs
 }
 
+// MarshalJSON encodes the criteria in the format accepted by UnmarshalJSON.
+func (args FilterCriteria) MarshalJSON() ([]byte, error) {
+	type output struct {
+		BlockHash *common.Hash     `json:"blockHash,omitempty"`
+		FromBlock *rpc.BlockNumber `json:"fromBlock,omitempty"`
+		ToBlock   *rpc.BlockNumber `json:"toBlock,omitempty"`
+		Addresses []common.Address `json:"address,omitempty"`
+		Topics    [][]common.Hash  `json:"topics,omitempty"`
+	}
+	enc := output{BlockHash: args.BlockHash, Addresses: args.Addresses, Topics: args.Topics}
+	if args.FromBlock != nil {
+		from := rpc.BlockNumber(args.FromBlock.Int64())
+		enc.FromBlock = &from
+	}
+	if args.ToBlock != nil {
+		to := rpc.BlockNumber(args.ToBlock.Int64())
+		enc.ToBlock = &to
+	}
+	return json.Marshal(&enc)
+}
+
 // UnmarshalJSON sets *args fields with given data.
 func (args *FilterCriteria) UnmarshalJSON(data []byte) error {
 	type input struct {
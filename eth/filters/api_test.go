@@ -19,6 +19,8 @@ package filters
 import (
 	"encoding/json"
 	"fmt"
+	"math/big"
+	"reflect"
 	"testing"
 
 	"github.com/celo-org/celo-blockchain/common"
@@ -183,3 +185,27 @@ func TestUnmarshalJSONNewFilterArgs(t *testing.T) {
 		t.Fatalf("expected 0 topics, got %d topics", len(test7.Topics[2]))
 	}
 }
+
+func TestMarshalJSONFilterArgs(t *testing.T) {
+	var (
+		hash  = common.HexToHash("0x01")
+		addr  = common.HexToAddress("0x02")
+		topic = common.HexToHash("0x03")
+	)
+	for i, crit := range []FilterCriteria{
+		{BlockHash: &hash, Addresses: []common.Address{addr}, Topics: [][]common.Hash{nil, {topic}}},
+		{FromBlock: big.NewInt(1), ToBlock: big.NewInt(rpc.LatestBlockNumber.Int64()), Addresses: []common.Address{}},
+	} {
+		blob, err := json.Marshal(crit)
+		if err != nil {
+			t.Fatalf("test %d: failed to marshal: %v", i, err)
+		}
+		var dec FilterCriteria
+		if err := json.Unmarshal(blob, &dec); err != nil {
+			t.Fatalf("test %d: failed to unmarshal %s: %v", i, blob, err)
+		}
+		if !reflect.DeepEqual(dec, crit) {
+			t.Errorf("test %d: criteria mismatch: have %+v, want %+v", i, dec, crit)
+		}
+	}
+}
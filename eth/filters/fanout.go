@@ -0,0 +1,264 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"sync"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/log"
+	"github.com/celo-org/celo-blockchain/metrics"
+	"github.com/celo-org/celo-blockchain/rpc"
+)
+
+// subQueueSize is the number of events buffered for a subscriber before its
+// drop policy kicks in.
+const subQueueSize = 1024
+
+var (
+	subscriptionGauge = metrics.NewRegisteredGauge("eth/filters/subscriptions", nil)
+	deliveredMeter    = metrics.NewRegisteredMeter("eth/filters/delivered", nil)
+	droppedMeter      = metrics.NewRegisteredMeter("eth/filters/dropped", nil)
+)
+
+// dropPolicy decides which events a subscriber loses once its queue is full.
+type dropPolicy int
+
+const (
+	// dropNewest discards incoming events, the subscriber keeps receiving a
+	// contiguous prefix of the events and can resume from its last cursor.
+	dropNewest dropPolicy = iota
+	// dropOldest discards the oldest queued events, the subscriber only misses
+	// the events which went stale in the meantime.
+	dropOldest
+)
+
+func (p dropPolicy) String() string {
+	if p == dropOldest {
+		return "dropOldest"
+	}
+	return "dropNewest"
+}
+
+// policyOf returns the drop policy of a subscription type. Logs must not have
+// gaps, while only the most recent heads and pending transactions matter.
+func policyOf(typ Type) dropPolicy {
+	switch typ {
+	case BlocksSubscription, PendingTransactionsSubscription:
+		return dropOldest
+	default:
+		return dropNewest
+	}
+}
+
+// subQueue buffers the events of a single subscriber, decoupling the event loop
+// from the subscriber's channel. A slow subscriber thus only loses its own
+// events instead of stalling the delivery to everyone else and, through the
+// event feeds, block import.
+type subQueue struct {
+	lock   sync.Mutex
+	events []interface{}
+	limit  int
+	policy dropPolicy
+
+	wake chan struct{} // Signals queued events to the delivery loop
+	quit chan struct{} // Closed to stop the delivery loop
+	done chan struct{} // Closed when the delivery loop returned
+}
+
+func newSubQueue(limit int, policy dropPolicy) *subQueue {
+	return &subQueue{
+		limit:  limit,
+		policy: policy,
+		wake:   make(chan struct{}, 1),
+		quit:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// push queues an event without blocking, it reports whether the queue had to
+// drop an event.
+func (q *subQueue) push(ev interface{}) bool {
+	q.lock.Lock()
+	dropped := len(q.events) >= q.limit
+	if dropped {
+		droppedMeter.Mark(1)
+		if q.policy == dropNewest {
+			q.lock.Unlock()
+			return true
+		}
+		q.events[0] = nil
+		q.events = q.events[1:]
+	}
+	q.events = append(q.events, ev)
+	q.lock.Unlock()
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return dropped
+}
+
+// pop removes the oldest queued event, if any.
+func (q *subQueue) pop() (interface{}, bool) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if len(q.events) == 0 {
+		return nil, false
+	}
+	ev := q.events[0]
+	q.events[0] = nil
+	q.events = q.events[1:]
+	return ev, true
+}
+
+// enqueue queues an event for the subscriber, warning about the first event
+// dropped since the subscriber last kept up.
+func (f *subscription) enqueue(ev interface{}) {
+	if !f.queue.push(ev) {
+		f.lagging = false
+		return
+	}
+	if !f.lagging {
+		f.lagging = true
+		log.Warn("Subscriber too slow, dropping events", "id", f.id, "type", f.typ, "policy", f.queue.policy)
+	}
+}
+
+// stop terminates the delivery loop and waits for it to return. No events are
+// sent to the subscriber afterwards.
+func (q *subQueue) stop() {
+	close(q.quit)
+	<-q.done
+}
+
+// deliver forwards the queued events to the subscriber until the queue is
+// stopped.
+func (f *subscription) deliver() {
+	defer close(f.queue.done)
+
+	for {
+		ev, ok := f.queue.pop()
+		if !ok {
+			select {
+			case <-f.queue.wake:
+				continue
+			case <-f.queue.quit:
+				return
+			}
+		}
+		switch ev := ev.(type) {
+		case []*types.Log:
+			select {
+			case f.logs <- ev:
+			case <-f.queue.quit:
+				return
+			}
+		case []common.Hash:
+			select {
+			case f.hashes <- ev:
+			case <-f.queue.quit:
+				return
+			}
+		case *types.Header:
+			select {
+			case f.headers <- ev:
+			case <-f.queue.quit:
+				return
+			}
+		}
+		deliveredMeter.Mark(1)
+	}
+}
+
+// logShards is the number of shards the log matcher spreads the subscriptions
+// filtering on contract addresses over.
+const logShards = 16
+
+// logMatcher indexes log subscriptions by the contract addresses they filter
+// on, so that a batch of logs is only matched against the subscriptions which
+// may be interested in it. Subscriptions without address criteria match any
+// log and are checked for every batch.
+type logMatcher struct {
+	shards   [logShards]map[common.Address]map[rpc.ID]*subscription
+	wildcard map[rpc.ID]*subscription
+}
+
+func newLogMatcher() *logMatcher {
+	m := &logMatcher{wildcard: make(map[rpc.ID]*subscription)}
+	for i := range m.shards {
+		m.shards[i] = make(map[common.Address]map[rpc.ID]*subscription)
+	}
+	return m
+}
+
+func (m *logMatcher) shard(addr common.Address) map[common.Address]map[rpc.ID]*subscription {
+	return m.shards[addr[len(addr)-1]%logShards]
+}
+
+// add indexes the subscription under all of its addresses.
+func (m *logMatcher) add(f *subscription) {
+	if len(f.logsCrit.Addresses) == 0 {
+		m.wildcard[f.id] = f
+		return
+	}
+	for _, addr := range f.logsCrit.Addresses {
+		shard := m.shard(addr)
+		if shard[addr] == nil {
+			shard[addr] = make(map[rpc.ID]*subscription)
+		}
+		shard[addr][f.id] = f
+	}
+}
+
+// remove drops the subscription from the index.
+func (m *logMatcher) remove(f *subscription) {
+	if len(f.logsCrit.Addresses) == 0 {
+		delete(m.wildcard, f.id)
+		return
+	}
+	for _, addr := range f.logsCrit.Addresses {
+		shard := m.shard(addr)
+		delete(shard[addr], f.id)
+		if len(shard[addr]) == 0 {
+			delete(shard, addr)
+		}
+	}
+}
+
+// candidates returns the subscriptions which may match any of the logs.
+func (m *logMatcher) candidates(logs []*types.Log) []*subscription {
+	var (
+		result = make([]*subscription, 0, len(m.wildcard))
+		seen   = make(map[rpc.ID]struct{})
+	)
+	for _, f := range m.wildcard {
+		result = append(result, f)
+	}
+	for _, l := range logs {
+		for id, f := range m.shard(l.Address)[l.Address] {
+			if _, ok := seen[id]; !ok {
+				seen[id] = struct{}{}
+				result = append(result, f)
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,155 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"math/big"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	ethereum "github.com/celo-org/celo-blockchain"
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/core"
+	"github.com/celo-org/celo-blockchain/core/rawdb"
+	"github.com/celo-org/celo-blockchain/core/types"
+)
+
+func TestSubQueueDropPolicy(t *testing.T) {
+	tests := []struct {
+		policy dropPolicy
+		want   []interface{}
+	}{
+		{dropNewest, []interface{}{0, 1, 2}},
+		{dropOldest, []interface{}{2, 3, 4}},
+	}
+	for _, tt := range tests {
+		q := newSubQueue(3, tt.policy)
+		for i := 0; i < 5; i++ {
+			if dropped := q.push(i); dropped != (i >= 3) {
+				t.Errorf("%v: push %d dropped %v", tt.policy, i, dropped)
+			}
+		}
+		var have []interface{}
+		for ev, ok := q.pop(); ok; ev, ok = q.pop() {
+			have = append(have, ev)
+		}
+		if !reflect.DeepEqual(have, tt.want) {
+			t.Errorf("%v: queued events mismatch: have %v, want %v", tt.policy, have, tt.want)
+		}
+	}
+}
+
+func TestLogMatcher(t *testing.T) {
+	var (
+		addr1 = common.HexToAddress("0x1111111111111111111111111111111111111111")
+		addr2 = common.HexToAddress("0x2222222222222222222222222222222222222222")
+		addr3 = common.HexToAddress("0x3333333333333333333333333333333333333333")
+
+		wildcard = &subscription{id: "any"}
+		sub1     = &subscription{id: "sub1", logsCrit: ethereum.FilterQuery{Addresses: []common.Address{addr1}}}
+		sub12    = &subscription{id: "sub12", logsCrit: ethereum.FilterQuery{Addresses: []common.Address{addr1, addr2}}}
+	)
+	m := newLogMatcher()
+	m.add(wildcard)
+	m.add(sub1)
+	m.add(sub12)
+
+	candidates := func(addrs ...common.Address) []string {
+		var logs []*types.Log
+		for _, addr := range addrs {
+			logs = append(logs, &types.Log{Address: addr})
+		}
+		var ids []string
+		for _, f := range m.candidates(logs) {
+			ids = append(ids, string(f.id))
+		}
+		sort.Strings(ids)
+		return ids
+	}
+	if have, want := candidates(addr1, addr2), []string{"any", "sub1", "sub12"}; !reflect.DeepEqual(have, want) {
+		t.Errorf("candidates mismatch: have %v, want %v", have, want)
+	}
+	if have, want := candidates(addr2), []string{"any", "sub12"}; !reflect.DeepEqual(have, want) {
+		t.Errorf("candidates mismatch: have %v, want %v", have, want)
+	}
+	if have, want := candidates(addr3), []string{"any"}; !reflect.DeepEqual(have, want) {
+		t.Errorf("candidates mismatch: have %v, want %v", have, want)
+	}
+
+	m.remove(sub12)
+	m.remove(wildcard)
+	if have, want := candidates(addr1, addr2), []string{"sub1"}; !reflect.DeepEqual(have, want) {
+		t.Errorf("candidates mismatch after removal: have %v, want %v", have, want)
+	}
+	for _, shard := range m.shards {
+		if _, ok := shard[addr2]; ok {
+			t.Errorf("empty address entry left in shard")
+		}
+	}
+}
+
+// TestSlowSubscriber tests that a subscriber which stops reading neither stalls
+// the event feeds nor the delivery to the other subscribers.
+func TestSlowSubscriber(t *testing.T) {
+	t.Parallel()
+
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		backend = &testBackend{db: db}
+		es      = NewEventSystem(backend, false)
+		events  = 2 * subQueueSize
+	)
+	slow := es.SubscribeNewHeads(make(chan *types.Header))
+	defer slow.Unsubscribe()
+
+	fastCh := make(chan *types.Header)
+	fast := es.SubscribeNewHeads(fastCh)
+	defer fast.Unsubscribe()
+
+	// Heads are dropped oldest first, so the fast subscriber may miss some if
+	// the feed outpaces it, but always receives the last one.
+	received := make(chan struct{})
+	go func() {
+		for header := range fastCh {
+			if header.Number.Int64() == int64(events-1) {
+				break
+			}
+		}
+		close(received)
+	}()
+
+	sent := make(chan struct{})
+	go func() {
+		for i := 0; i < events; i++ {
+			header := &types.Header{Number: big.NewInt(int64(i))}
+			backend.chainFeed.Send(core.ChainEvent{Block: types.NewBlockWithHeader(header)})
+		}
+		close(sent)
+	}()
+	select {
+	case <-sent:
+	case <-time.After(5 * time.Second):
+		t.Fatal("chain feed stalled by slow subscriber")
+	}
+	select {
+	case <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("fast subscriber stalled by slow subscriber")
+	}
+}
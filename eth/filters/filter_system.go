@@ -78,6 +78,9 @@ type subscription struct {
 	headers   chan *types.Header
 	installed chan struct{} // closed when the filter is installed
 	err       chan error    // closed when the filter is uninstalled
+
+	queue   *subQueue // events pending delivery, owned by the event loop
+	lagging bool      // whether the subscriber dropped events since it last kept up
 }
 
 // EventSystem creates subscriptions, processes events and broadcasts them to the
@@ -155,20 +158,9 @@ func (sub *Subscription) Err() <-chan error {
 // Unsubscribe uninstalls the subscription from the event broadcast loop.
 func (sub *Subscription) Unsubscribe() {
 	sub.unsubOnce.Do(func() {
-	uninstallLoop:
-		for {
-			// write uninstall request and consume logs/hashes. This prevents
-			// the eventLoop broadcast method to deadlock when writing to the
-			// filter event channel while the subscription loop is waiting for
-			// this method to return (and thus not reading these events).
-			select {
-			case sub.es.uninstall <- sub.f:
-				break uninstallLoop
-			case <-sub.f.logs:
-			case <-sub.f.hashes:
-			case <-sub.f.headers:
-			}
-		}
+		// The event loop never blocks on subscribers, queued events still
+		// pending delivery are discarded.
+		sub.es.uninstall <- sub.f
 
 		// wait for filter to be uninstalled in work loop before returning
 		// this ensures that the manager won't use the event channel which
@@ -306,60 +298,124 @@ func (es *EventSystem) SubscribePendingTxs(hashes chan []common.Hash) *Subscript
 	return es.subscribe(sub)
 }
 
-type filterIndex map[Type]map[rpc.ID]*subscription
+// filterIndex holds the installed subscriptions by type. Log subscriptions are
+// additionally indexed by the addresses they filter on.
+type filterIndex struct {
+	subs        map[Type]map[rpc.ID]*subscription
+	logs        *logMatcher
+	pendingLogs *logMatcher
+}
+
+func newFilterIndex() *filterIndex {
+	index := &filterIndex{
+		subs:        make(map[Type]map[rpc.ID]*subscription),
+		logs:        newLogMatcher(),
+		pendingLogs: newLogMatcher(),
+	}
+	for i := UnknownSubscription; i < LastIndexSubscription; i++ {
+		index.subs[i] = make(map[rpc.ID]*subscription)
+	}
+	return index
+}
+
+// install adds the subscription to the index and starts delivering its events.
+func (index *filterIndex) install(f *subscription) {
+	switch f.typ {
+	case MinedAndPendingLogsSubscription:
+		// the type are logs and pending logs subscriptions
+		index.subs[LogsSubscription][f.id] = f
+		index.subs[PendingLogsSubscription][f.id] = f
+		index.logs.add(f)
+		index.pendingLogs.add(f)
+	case LogsSubscription:
+		index.subs[f.typ][f.id] = f
+		index.logs.add(f)
+	case PendingLogsSubscription:
+		index.subs[f.typ][f.id] = f
+		index.pendingLogs.add(f)
+	default:
+		index.subs[f.typ][f.id] = f
+	}
+	f.queue = newSubQueue(subQueueSize, policyOf(f.typ))
+	go f.deliver()
+	subscriptionGauge.Inc(1)
+}
 
-func (es *EventSystem) handleLogs(filters filterIndex, ev []*types.Log) {
+// uninstall removes the subscription from the index and waits for its event
+// delivery to stop.
+func (index *filterIndex) uninstall(f *subscription) {
+	switch f.typ {
+	case MinedAndPendingLogsSubscription:
+		// the type are logs and pending logs subscriptions
+		delete(index.subs[LogsSubscription], f.id)
+		delete(index.subs[PendingLogsSubscription], f.id)
+		index.logs.remove(f)
+		index.pendingLogs.remove(f)
+	case LogsSubscription:
+		delete(index.subs[f.typ], f.id)
+		index.logs.remove(f)
+	case PendingLogsSubscription:
+		delete(index.subs[f.typ], f.id)
+		index.pendingLogs.remove(f)
+	default:
+		delete(index.subs[f.typ], f.id)
+	}
+	f.queue.stop()
+	subscriptionGauge.Dec(1)
+}
+
+func (es *EventSystem) handleLogs(filters *filterIndex, ev []*types.Log) {
 	if len(ev) == 0 {
 		return
 	}
-	for _, f := range filters[LogsSubscription] {
+	for _, f := range filters.logs.candidates(ev) {
 		matchedLogs := filterLogs(ev, f.logsCrit.FromBlock, f.logsCrit.ToBlock, f.logsCrit.Addresses, f.logsCrit.Topics)
 		if len(matchedLogs) > 0 {
-			f.logs <- matchedLogs
+			f.enqueue(matchedLogs)
 		}
 	}
 }
 
-func (es *EventSystem) handlePendingLogs(filters filterIndex, ev []*types.Log) {
+func (es *EventSystem) handlePendingLogs(filters *filterIndex, ev []*types.Log) {
 	if len(ev) == 0 {
 		return
 	}
-	for _, f := range filters[PendingLogsSubscription] {
+	for _, f := range filters.pendingLogs.candidates(ev) {
 		matchedLogs := filterLogs(ev, nil, f.logsCrit.ToBlock, f.logsCrit.Addresses, f.logsCrit.Topics)
 		if len(matchedLogs) > 0 {
-			f.logs <- matchedLogs
+			f.enqueue(matchedLogs)
 		}
 	}
 }
 
-func (es *EventSystem) handleRemovedLogs(filters filterIndex, ev core.RemovedLogsEvent) {
-	for _, f := range filters[LogsSubscription] {
+func (es *EventSystem) handleRemovedLogs(filters *filterIndex, ev core.RemovedLogsEvent) {
+	for _, f := range filters.logs.candidates(ev.Logs) {
 		matchedLogs := filterLogs(ev.Logs, f.logsCrit.FromBlock, f.logsCrit.ToBlock, f.logsCrit.Addresses, f.logsCrit.Topics)
 		if len(matchedLogs) > 0 {
-			f.logs <- matchedLogs
+			f.enqueue(matchedLogs)
 		}
 	}
 }
 
-func (es *EventSystem) handleTxsEvent(filters filterIndex, ev core.NewTxsEvent) {
+func (es *EventSystem) handleTxsEvent(filters *filterIndex, ev core.NewTxsEvent) {
 	hashes := make([]common.Hash, 0, len(ev.Txs))
 	for _, tx := range ev.Txs {
 		hashes = append(hashes, tx.Hash())
 	}
-	for _, f := range filters[PendingTransactionsSubscription] {
-		f.hashes <- hashes
+	for _, f := range filters.subs[PendingTransactionsSubscription] {
+		f.enqueue(hashes)
 	}
 }
 
-func (es *EventSystem) handleChainEvent(filters filterIndex, ev core.ChainEvent) {
-	for _, f := range filters[BlocksSubscription] {
-		f.headers <- ev.Block.Header()
+func (es *EventSystem) handleChainEvent(filters *filterIndex, ev core.ChainEvent) {
+	for _, f := range filters.subs[BlocksSubscription] {
+		f.enqueue(ev.Block.Header())
 	}
-	if es.lightMode && len(filters[LogsSubscription]) > 0 {
+	if es.lightMode && len(filters.subs[LogsSubscription]) > 0 {
 		es.lightFilterNewHead(ev.Block.Header(), func(header *types.Header, remove bool) {
-			for _, f := range filters[LogsSubscription] {
+			for _, f := range filters.subs[LogsSubscription] {
 				if matchedLogs := es.lightFilterLogs(header, f.logsCrit.Addresses, f.logsCrit.Topics, remove); len(matchedLogs) > 0 {
-					f.logs <- matchedLogs
+					f.enqueue(matchedLogs)
 				}
 			}
 		})
@@ -450,10 +506,16 @@ func (es *EventSystem) eventLoop() {
 		es.chainSub.Unsubscribe()
 	}()
 
-	index := make(filterIndex)
-	for i := UnknownSubscription; i < LastIndexSubscription; i++ {
-		index[i] = make(map[rpc.ID]*subscription)
-	}
+	index := newFilterIndex()
+	defer func() {
+		// Stop delivering to the subscriptions left installed. Uninstalling
+		// also removes them from the maps not yet iterated.
+		for _, subs := range index.subs {
+			for _, f := range subs {
+				index.uninstall(f)
+			}
+		}
+	}()
 
 	for {
 		select {
@@ -469,23 +531,11 @@ func (es *EventSystem) eventLoop() {
 			es.handleChainEvent(index, ev)
 
 		case f := <-es.install:
-			if f.typ == MinedAndPendingLogsSubscription {
-				// the type are logs and pending logs subscriptions
-				index[LogsSubscription][f.id] = f
-				index[PendingLogsSubscription][f.id] = f
-			} else {
-				index[f.typ][f.id] = f
-			}
+			index.install(f)
 			close(f.installed)
 
 		case f := <-es.uninstall:
-			if f.typ == MinedAndPendingLogsSubscription {
-				// the type are logs and pending logs subscriptions
-				delete(index[LogsSubscription], f.id)
-				delete(index[PendingLogsSubscription], f.id)
-			} else {
-				delete(index[f.typ], f.id)
-			}
+			index.uninstall(f)
 			close(f.err)
 
 		// System stopped
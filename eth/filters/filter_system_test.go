@@ -18,15 +18,18 @@ package filters
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"math/big"
 	"math/rand"
+	"net/http/httptest"
 	"reflect"
 	"testing"
 	"time"
 
 	ethereum "github.com/celo-org/celo-blockchain"
 	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/common/hexutil"
 	mockEngine "github.com/celo-org/celo-blockchain/consensus/consensustest"
 	"github.com/celo-org/celo-blockchain/core"
 	"github.com/celo-org/celo-blockchain/core/bloombits"
@@ -34,6 +37,7 @@ import (
 	"github.com/celo-org/celo-blockchain/core/types"
 	"github.com/celo-org/celo-blockchain/ethdb"
 	"github.com/celo-org/celo-blockchain/event"
+	"github.com/celo-org/celo-blockchain/internal/ethapi"
 	"github.com/celo-org/celo-blockchain/params"
 	"github.com/celo-org/celo-blockchain/rpc"
 )
@@ -42,10 +46,14 @@ type testBackend struct {
 	db              ethdb.Database
 	sections        uint64
 	txFeed          event.Feed
+	txPoolFeed      event.Feed
 	logsFeed        event.Feed
 	rmLogsFeed      event.Feed
 	pendingLogsFeed event.Feed
 	chainFeed       event.Feed
+
+	historyTail uint64               // First block whose logs are available
+	archive     *ethapi.ArchiveProxy // Proxy for the logs of the earlier blocks
 }
 
 func (b *testBackend) ChainDb() ethdb.Database {
@@ -91,6 +99,9 @@ func (b *testBackend) GetLogs(ctx context.Context, hash common.Hash) ([][]*types
 	if number == nil {
 		return nil, nil
 	}
+	if *number < b.historyTail {
+		return nil, &ethapi.PrunedHistoryError{Number: *number, Tail: b.historyTail, Archive: "archive"}
+	}
 	receipts := rawdb.ReadReceipts(b.db, hash, *number, params.IstanbulTestChainConfig)
 
 	logs := make([][]*types.Log, len(receipts))
@@ -104,6 +115,10 @@ func (b *testBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Sub
 	return b.txFeed.Subscribe(ch)
 }
 
+func (b *testBackend) SubscribeTxPoolChangeEvent(ch chan<- core.TxPoolChangeEvent) event.Subscription {
+	return b.txPoolFeed.Subscribe(ch)
+}
+
 func (b *testBackend) SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription {
 	return b.rmLogsFeed.Subscribe(ch)
 }
@@ -124,6 +139,10 @@ func (b *testBackend) BloomStatus() (uint64, uint64) {
 	return params.BloomBitsBlocks, b.sections
 }
 
+func (b *testBackend) ArchiveProxy() *ethapi.ArchiveProxy {
+	return b.archive
+}
+
 func (b *testBackend) ServiceFilter(ctx context.Context, session *bloombits.MatcherSession) {
 	requests := make(chan chan *bloombits.Retrieval)
 
@@ -600,6 +619,111 @@ func TestPendingLogsSubscription(t *testing.T) {
 	}
 }
 
+// TestResumeSubscription tests that subscriptions resumed from a cursor first
+// deliver the events missed since, including the removal of delivered logs that
+// were reorged out.
+func TestResumeSubscription(t *testing.T) {
+	t.Parallel()
+
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		backend = &testBackend{db: db}
+		api     = NewPublicFilterAPI(backend, false)
+		genesis = new(core.Genesis).MustCommit(db)
+		addr    = common.HexToAddress("0x1111111111111111111111111111111111111111")
+	)
+	addLogs := func(i int, gen *core.BlockGen) {
+		receipt := types.NewReceipt(nil, false, 0)
+		receipt.Logs = []*types.Log{{Address: addr}, {Address: addr}}
+		gen.AddUncheckedReceipt(receipt)
+	}
+	chain, receipts := core.GenerateChain(params.IstanbulTestChainConfig, genesis, mockEngine.NewFaker(), db, 5, addLogs)
+	for i, block := range chain {
+		rawdb.WriteBlock(db, block)
+		rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		rawdb.WriteHeadBlockHash(db, block.Hash())
+		rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), receipts[i])
+	}
+	// A sibling of block 3 that is no longer canonical
+	side, sideReceipts := core.GenerateChain(params.IstanbulTestChainConfig, chain[1], mockEngine.NewFaker(), db, 1, func(i int, gen *core.BlockGen) {
+		gen.SetExtra([]byte("side"))
+		addLogs(i, gen)
+	})
+	rawdb.WriteBlock(db, side[0])
+	rawdb.WriteReceipts(db, side[0].Hash(), side[0].NumberU64(), sideReceipts[0])
+
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("eth", api); err != nil {
+		t.Fatal(err)
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	// Heads are resumed after the cursor block
+	heads := make(chan *types.Header)
+	sub, err := client.EthSubscribe(context.Background(), heads, "newHeads", &Cursor{BlockHash: chain[1].Hash(), BlockNumber: 2})
+	if err != nil {
+		t.Fatalf("failed to resume heads subscription: %v", err)
+	}
+	for _, block := range chain[2:] {
+		select {
+		case head := <-heads:
+			if head.Hash() != block.Hash() {
+				t.Errorf("head %d mismatch: have %x, want %x", block.NumberU64(), head.Hash(), block.Hash())
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for head %d", block.NumberU64())
+		}
+	}
+	sub.Unsubscribe()
+
+	type want struct {
+		block   common.Hash
+		index   uint
+		removed bool
+	}
+	tests := []struct {
+		cursor *Cursor
+		want   []want
+	}{
+		// Logs are resumed within the cursor block
+		{
+			cursor: &Cursor{BlockHash: chain[3].Hash(), BlockNumber: 4, LogIndex: new(hexutil.Uint)},
+			want:   []want{{chain[3].Hash(), 1, false}, {chain[4].Hash(), 0, false}, {chain[4].Hash(), 1, false}},
+		},
+		// Logs delivered from a reorged out block are removed first
+		{
+			cursor: &Cursor{BlockHash: side[0].Hash(), BlockNumber: 3, LogIndex: new(hexutil.Uint)},
+			want: []want{
+				{side[0].Hash(), 0, true},
+				{chain[2].Hash(), 0, false}, {chain[2].Hash(), 1, false},
+				{chain[3].Hash(), 0, false}, {chain[3].Hash(), 1, false},
+				{chain[4].Hash(), 0, false}, {chain[4].Hash(), 1, false},
+			},
+		},
+	}
+	for i, tt := range tests {
+		logs := make(chan types.Log)
+		crit := map[string]interface{}{"address": addr}
+		sub, err := client.EthSubscribe(context.Background(), logs, "logs", crit, tt.cursor)
+		if err != nil {
+			t.Fatalf("test %d: failed to resume logs subscription: %v", i, err)
+		}
+		for j, w := range tt.want {
+			select {
+			case l := <-logs:
+				if l.BlockHash != w.block || l.Index != w.index || l.Removed != w.removed {
+					t.Errorf("test %d: log %d mismatch: have %x/%d/%v, want %x/%d/%v", i, j, l.BlockHash, l.Index, l.Removed, w.block, w.index, w.removed)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("test %d: timed out waiting for log %d", i, j)
+			}
+		}
+		sub.Unsubscribe()
+	}
+}
+
 func flattenLogs(pl [][]*types.Log) []*types.Log {
 	var logs []*types.Log
 	for _, l := range pl {
@@ -607,3 +731,59 @@ func flattenLogs(pl [][]*types.Log) []*types.Log {
 	}
 	return logs
 }
+
+// archiveService serves the logs of an archive node, one per requested block.
+type archiveService struct{}
+
+func (archiveService) GetLogs(crit FilterCriteria) ([]*types.Log, error) {
+	return []*types.Log{{BlockHash: *crit.BlockHash, Topics: []common.Hash{}}}, nil
+}
+
+// TestArchiveProxy tests that requests for the logs of pruned blocks are proxied
+// to the archive node, with the provenance of the logs attached to them.
+func TestArchiveProxy(t *testing.T) {
+	t.Parallel()
+
+	archive := rpc.NewServer()
+	defer archive.Stop()
+	if err := archive.RegisterName("eth", archiveService{}); err != nil {
+		t.Fatal(err)
+	}
+	httpsrv := httptest.NewServer(archive)
+	defer httpsrv.Close()
+
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		backend = &testBackend{db: db, historyTail: 10}
+		api     = NewPublicFilterAPI(backend, false)
+		header  = &types.Header{Number: big.NewInt(5)}
+		crit    = FilterCriteria{BlockHash: new(common.Hash)}
+	)
+	rawdb.WriteHeader(db, header)
+	*crit.BlockHash = header.Hash()
+
+	// Without a proxy, the pruned history error is returned
+	if _, err := api.GetLogs(context.Background(), crit); err == nil {
+		t.Fatal("expected pruned history error")
+	}
+	backend.archive = ethapi.NewArchiveProxy(httpsrv.URL)
+	defer backend.archive.Close()
+
+	result, err := api.GetLogs(context.Background(), crit)
+	if err != nil {
+		t.Fatalf("failed to proxy logs: %v", err)
+	}
+	var logs []struct {
+		BlockHash  common.Hash        `json:"blockHash"`
+		Provenance *ethapi.Provenance `json:"provenance"`
+	}
+	if err := json.Unmarshal(result.(json.RawMessage), &logs); err != nil {
+		t.Fatalf("invalid proxied logs: %v", err)
+	}
+	if len(logs) != 1 || logs[0].BlockHash != header.Hash() {
+		t.Fatalf("proxied logs mismatch: %+v", logs)
+	}
+	if p := logs[0].Provenance; p == nil || p.Source != "archive" || p.HistoryTail != 10 {
+		t.Errorf("provenance mismatch: %+v", p)
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"context"
+	"errors"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/common/hexutil"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/log"
+	"github.com/celo-org/celo-blockchain/rpc"
+)
+
+// maxResumeBlocks is the maximum number of blocks a resumed subscription may
+// backfill. Older cursors are rejected.
+const maxResumeBlocks = 10000
+
+var (
+	errCursorTooOld      = errors.New("subscription cursor exceeds the backfill limit")
+	errCursorUnreachable = errors.New("subscription cursor not connected to the canonical chain")
+	errResumePending     = errors.New("pending logs subscriptions cannot be resumed")
+)
+
+// Cursor identifies the last event delivered by a subscription. A client that
+// reconnects presents it to resume the subscription, and the events it missed
+// in the meantime are delivered first. Heads are identified by their block,
+// logs by their block and index within it.
+type Cursor struct {
+	BlockHash   common.Hash    `json:"blockHash"`
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	LogIndex    *hexutil.Uint  `json:"logIndex"` // Resume after the whole block if nil
+}
+
+// resumption describes where a resumed subscription continues.
+type resumption struct {
+	cursor   *Cursor
+	from     uint64          // First canonical block to backfill
+	orphaned []*types.Header // Previously delivered blocks no longer canonical, newest first
+}
+
+// resume determines where a subscription continues from the given cursor. If
+// the cursor's block was reorged out, the subscription continues after the last
+// common ancestor with the canonical chain. A block unknown to this node, e.g.
+// one seen on another node of a fleet, is treated as replaced at its height.
+func (api *PublicFilterAPI) resume(ctx context.Context, cursor *Cursor) (*resumption, error) {
+	head, err := api.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	r := &resumption{cursor: cursor, from: uint64(cursor.BlockNumber)}
+
+	header, err := api.backend.HeaderByHash(ctx, cursor.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+	for header != nil {
+		canonical, err := api.backend.HeaderByNumber(ctx, rpc.BlockNumber(header.Number.Int64()))
+		if err != nil {
+			return nil, err
+		}
+		if canonical != nil && canonical.Hash() == header.Hash() {
+			r.from = header.Number.Uint64() + 1
+			if len(r.orphaned) == 0 && cursor.LogIndex != nil {
+				r.from-- // the rest of the block is still to be delivered
+			}
+			break
+		}
+		if len(r.orphaned) >= maxResumeBlocks {
+			return nil, errCursorTooOld
+		}
+		r.orphaned = append(r.orphaned, header)
+		if header, err = api.backend.HeaderByHash(ctx, header.ParentHash); err != nil {
+			return nil, err
+		}
+		if header == nil {
+			return nil, errCursorUnreachable
+		}
+	}
+	if head.Number.Uint64()+1 > r.from+maxResumeBlocks {
+		return nil, errCursorTooOld
+	}
+	return r, nil
+}
+
+// backfillResult is the outcome of backfilling a resumed subscription.
+type backfillResult struct {
+	headers []*types.Header // Canonical headers appended since the cursor
+	logs    []*types.Log    // Matching logs since the cursor, if logs were requested
+}
+
+// backfill resolves the cursor of a resumed subscription and retrieves the
+// events missed since in the background. Live events must be subscribed to
+// before calling it for the backfill to leave no gap. A nil channel is returned
+// if there is no cursor.
+func (api *PublicFilterAPI) backfill(ctx context.Context, cursor *Cursor, crit *FilterCriteria) (<-chan *backfillResult, error) {
+	if cursor == nil {
+		return nil, nil
+	}
+	r, err := api.resume(ctx, cursor)
+	if err != nil {
+		return nil, err
+	}
+	done := make(chan *backfillResult, 1)
+	go func() {
+		// The context of the subscribing call ends once it returns
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		res := &backfillResult{headers: r.headers(ctx, api.backend)}
+		if crit != nil && len(res.headers) > 0 {
+			res.logs = r.logs(ctx, api.backend, *crit, res.headers[len(res.headers)-1].Number.Uint64())
+		} else if crit != nil {
+			res.logs = r.logs(ctx, api.backend, *crit, 0) // only removed logs, if any
+		}
+		done <- res
+	}()
+	return done, nil
+}
+
+// headers retrieves the canonical headers from the resumption point up to the
+// current head.
+func (r *resumption) headers(ctx context.Context, backend Backend) []*types.Header {
+	var headers []*types.Header
+	for number := r.from; ; number++ {
+		header, err := backend.HeaderByNumber(ctx, rpc.BlockNumber(number))
+		if err != nil {
+			log.Warn("Failed to backfill resumed subscription", "number", number, "err", err)
+		}
+		if header == nil {
+			return headers
+		}
+		headers = append(headers, header)
+	}
+}
+
+// logs retrieves the logs matching the criteria that were missed since the
+// cursor. Previously delivered logs of orphaned blocks come first, flagged as
+// removed, followed by the logs of the canonical blocks up to the given one.
+func (r *resumption) logs(ctx context.Context, backend Backend, crit FilterCriteria, to uint64) []*types.Log {
+	var logs []*types.Log
+	for i, header := range r.orphaned {
+		blockLogs, err := backend.GetLogs(ctx, header.Hash())
+		if err != nil {
+			log.Warn("Failed to backfill removed logs", "number", header.Number, "hash", header.Hash(), "err", err)
+			continue
+		}
+		var unfiltered []*types.Log
+		for _, txLogs := range blockLogs {
+			unfiltered = append(unfiltered, txLogs...)
+		}
+		matched := filterLogs(unfiltered, nil, nil, crit.Addresses, crit.Topics)
+		for j := len(matched) - 1; j >= 0; j-- {
+			if i == 0 && r.cursor.LogIndex != nil && matched[j].Index > uint(*r.cursor.LogIndex) {
+				continue // never delivered
+			}
+			removed := *matched[j]
+			removed.Removed = true
+			logs = append(logs, &removed)
+		}
+	}
+	if to < r.from || to == 0 {
+		return logs
+	}
+	filter := NewRangeFilter(backend, int64(r.from), int64(to), crit.Addresses, crit.Topics)
+	matched, err := filter.Logs(ctx)
+	if err != nil {
+		log.Warn("Failed to backfill resumed subscription", "from", r.from, "to", to, "err", err)
+	}
+	for _, l := range matched {
+		if l.BlockHash == r.cursor.BlockHash && r.cursor.LogIndex != nil && l.Index <= uint(*r.cursor.LogIndex) {
+			continue // already delivered
+		}
+		logs = append(logs, l)
+	}
+	return logs
+}
@@ -23,7 +23,11 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		DiscoveryURLs           []string
 		NoPruning               bool
 		NoPrefetch              bool
+		Preheat                 bool
 		TxLookupLimit           uint64                 `toml:",omitempty"`
+		HistoryBlocks           uint64                 `toml:",omitempty"`
+		HistoryArchive          string                 `toml:",omitempty"`
+		HistoryProxy            bool                   `toml:",omitempty"`
 		Whitelist               map[uint64]common.Hash `toml:"-"`
 		LightServ               int                    `toml:",omitempty"`
 		LightIngress            int                    `toml:",omitempty"`
@@ -41,23 +45,35 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		DatabaseHandles         int                    `toml:"-"`
 		DatabaseCache           int
 		DatabaseFreezer         string
+		DatabaseFreezerRemote   string `toml:",omitempty"`
+		DatabaseFreezerCache    int    `toml:",omitempty"`
 		TrieCleanCache          int
 		TrieCleanCacheJournal   string        `toml:",omitempty"`
 		TrieCleanCacheRejournal time.Duration `toml:",omitempty"`
 		TrieDirtyCache          int
 		TrieTimeout             time.Duration
 		SnapshotCache           int
+		TraceCache              int    `toml:",omitempty"`
+		TraceCacheDisk          int    `toml:",omitempty"`
+		TraceCacheJournal       string `toml:",omitempty"`
 		Miner                   miner.Config
 		TxPool                  core.TxPoolConfig
 		EnablePreimageRecording bool
+		TraceSampling           float64 `toml:",omitempty"`
 		Istanbul                istanbul.Config
 		DocRoot                 string `toml:"-"`
 		EWASMInterpreter        string
 		EVMInterpreter          string
 		RPCGasCap               uint64                         `toml:",omitempty"`
+		RPCEVMTimeout           time.Duration                  `toml:",omitempty"`
 		RPCTxFeeCap             float64                        `toml:",omitempty"`
+		RPCEthCompatibility     bool                           `toml:",omitempty"`
+		RPCCallCacheTTL         time.Duration                  `toml:",omitempty"`
+		RPCCallCacheSize        int                            `toml:",omitempty"`
 		Checkpoint              *params.TrustedCheckpoint      `toml:",omitempty"`
 		CheckpointOracle        *params.CheckpointOracleConfig `toml:",omitempty"`
+		ForkDryRun              string                         `toml:",omitempty"`
+		ForkDryRunBlocks        uint64                         `toml:",omitempty"`
 		OverrideEHardfork       *big.Int                       `toml:",omitempty"`
 	}
 	var enc Config
@@ -67,7 +83,11 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.DiscoveryURLs = c.DiscoveryURLs
 	enc.NoPruning = c.NoPruning
 	enc.NoPrefetch = c.NoPrefetch
+	enc.Preheat = c.Preheat
 	enc.TxLookupLimit = c.TxLookupLimit
+	enc.HistoryBlocks = c.HistoryBlocks
+	enc.HistoryArchive = c.HistoryArchive
+	enc.HistoryProxy = c.HistoryProxy
 	enc.Whitelist = c.Whitelist
 	enc.LightServ = c.LightServ
 	enc.LightIngress = c.LightIngress
@@ -85,23 +105,35 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.DatabaseHandles = c.DatabaseHandles
 	enc.DatabaseCache = c.DatabaseCache
 	enc.DatabaseFreezer = c.DatabaseFreezer
+	enc.DatabaseFreezerRemote = c.DatabaseFreezerRemote
+	enc.DatabaseFreezerCache = c.DatabaseFreezerCache
 	enc.TrieCleanCache = c.TrieCleanCache
 	enc.TrieCleanCacheJournal = c.TrieCleanCacheJournal
 	enc.TrieCleanCacheRejournal = c.TrieCleanCacheRejournal
 	enc.TrieDirtyCache = c.TrieDirtyCache
 	enc.TrieTimeout = c.TrieTimeout
 	enc.SnapshotCache = c.SnapshotCache
+	enc.TraceCache = c.TraceCache
+	enc.TraceCacheDisk = c.TraceCacheDisk
+	enc.TraceCacheJournal = c.TraceCacheJournal
 	enc.Miner = c.Miner
 	enc.TxPool = c.TxPool
 	enc.EnablePreimageRecording = c.EnablePreimageRecording
+	enc.TraceSampling = c.TraceSampling
 	enc.Istanbul = c.Istanbul
 	enc.DocRoot = c.DocRoot
 	enc.EWASMInterpreter = c.EWASMInterpreter
 	enc.EVMInterpreter = c.EVMInterpreter
 	enc.RPCGasCap = c.RPCGasCap
+	enc.RPCEVMTimeout = c.RPCEVMTimeout
 	enc.RPCTxFeeCap = c.RPCTxFeeCap
+	enc.RPCEthCompatibility = c.RPCEthCompatibility
+	enc.RPCCallCacheTTL = c.RPCCallCacheTTL
+	enc.RPCCallCacheSize = c.RPCCallCacheSize
 	enc.Checkpoint = c.Checkpoint
 	enc.CheckpointOracle = c.CheckpointOracle
+	enc.ForkDryRun = c.ForkDryRun
+	enc.ForkDryRunBlocks = c.ForkDryRunBlocks
 	enc.OverrideEHardfork = c.OverrideEHardfork
 	return &enc, nil
 }
@@ -115,7 +147,11 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		DiscoveryURLs           []string
 		NoPruning               *bool
 		NoPrefetch              *bool
+		Preheat                 *bool
 		TxLookupLimit           *uint64                `toml:",omitempty"`
+		HistoryBlocks           *uint64                `toml:",omitempty"`
+		HistoryArchive          *string                `toml:",omitempty"`
+		HistoryProxy            *bool                  `toml:",omitempty"`
 		Whitelist               map[uint64]common.Hash `toml:"-"`
 		LightServ               *int                   `toml:",omitempty"`
 		LightIngress            *int                   `toml:",omitempty"`
@@ -133,23 +169,35 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		DatabaseHandles         *int                   `toml:"-"`
 		DatabaseCache           *int
 		DatabaseFreezer         *string
+		DatabaseFreezerRemote   *string `toml:",omitempty"`
+		DatabaseFreezerCache    *int    `toml:",omitempty"`
 		TrieCleanCache          *int
 		TrieCleanCacheJournal   *string        `toml:",omitempty"`
 		TrieCleanCacheRejournal *time.Duration `toml:",omitempty"`
 		TrieDirtyCache          *int
 		TrieTimeout             *time.Duration
 		SnapshotCache           *int
+		TraceCache              *int    `toml:",omitempty"`
+		TraceCacheDisk          *int    `toml:",omitempty"`
+		TraceCacheJournal       *string `toml:",omitempty"`
 		Miner                   *miner.Config
 		TxPool                  *core.TxPoolConfig
 		EnablePreimageRecording *bool
+		TraceSampling           *float64 `toml:",omitempty"`
 		Istanbul                *istanbul.Config
 		DocRoot                 *string `toml:"-"`
 		EWASMInterpreter        *string
 		EVMInterpreter          *string
 		RPCGasCap               *uint64                        `toml:",omitempty"`
+		RPCEVMTimeout           *time.Duration                 `toml:",omitempty"`
 		RPCTxFeeCap             *float64                       `toml:",omitempty"`
+		RPCEthCompatibility     *bool                          `toml:",omitempty"`
+		RPCCallCacheTTL         *time.Duration                 `toml:",omitempty"`
+		RPCCallCacheSize        *int                           `toml:",omitempty"`
 		Checkpoint              *params.TrustedCheckpoint      `toml:",omitempty"`
 		CheckpointOracle        *params.CheckpointOracleConfig `toml:",omitempty"`
+		ForkDryRun              *string                        `toml:",omitempty"`
+		ForkDryRunBlocks        *uint64                        `toml:",omitempty"`
 		OverrideEhardfork       *big.Int                       `toml:",omitempty"`
 	}
 	var dec Config
@@ -174,9 +222,21 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.NoPrefetch != nil {
 		c.NoPrefetch = *dec.NoPrefetch
 	}
+	if dec.Preheat != nil {
+		c.Preheat = *dec.Preheat
+	}
 	if dec.TxLookupLimit != nil {
 		c.TxLookupLimit = *dec.TxLookupLimit
 	}
+	if dec.HistoryBlocks != nil {
+		c.HistoryBlocks = *dec.HistoryBlocks
+	}
+	if dec.HistoryArchive != nil {
+		c.HistoryArchive = *dec.HistoryArchive
+	}
+	if dec.HistoryProxy != nil {
+		c.HistoryProxy = *dec.HistoryProxy
+	}
 	if dec.Whitelist != nil {
 		c.Whitelist = dec.Whitelist
 	}
@@ -228,6 +288,12 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.DatabaseFreezer != nil {
 		c.DatabaseFreezer = *dec.DatabaseFreezer
 	}
+	if dec.DatabaseFreezerRemote != nil {
+		c.DatabaseFreezerRemote = *dec.DatabaseFreezerRemote
+	}
+	if dec.DatabaseFreezerCache != nil {
+		c.DatabaseFreezerCache = *dec.DatabaseFreezerCache
+	}
 	if dec.TrieCleanCache != nil {
 		c.TrieCleanCache = *dec.TrieCleanCache
 	}
@@ -246,6 +312,15 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.SnapshotCache != nil {
 		c.SnapshotCache = *dec.SnapshotCache
 	}
+	if dec.TraceCache != nil {
+		c.TraceCache = *dec.TraceCache
+	}
+	if dec.TraceCacheDisk != nil {
+		c.TraceCacheDisk = *dec.TraceCacheDisk
+	}
+	if dec.TraceCacheJournal != nil {
+		c.TraceCacheJournal = *dec.TraceCacheJournal
+	}
 	if dec.Miner != nil {
 		c.Miner = *dec.Miner
 	}
@@ -255,6 +330,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.EnablePreimageRecording != nil {
 		c.EnablePreimageRecording = *dec.EnablePreimageRecording
 	}
+	if dec.TraceSampling != nil {
+		c.TraceSampling = *dec.TraceSampling
+	}
 	if dec.Istanbul != nil {
 		c.Istanbul = *dec.Istanbul
 	}
@@ -270,15 +348,33 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.RPCGasCap != nil {
 		c.RPCGasCap = *dec.RPCGasCap
 	}
+	if dec.RPCEVMTimeout != nil {
+		c.RPCEVMTimeout = *dec.RPCEVMTimeout
+	}
 	if dec.RPCTxFeeCap != nil {
 		c.RPCTxFeeCap = *dec.RPCTxFeeCap
 	}
+	if dec.RPCEthCompatibility != nil {
+		c.RPCEthCompatibility = *dec.RPCEthCompatibility
+	}
+	if dec.RPCCallCacheTTL != nil {
+		c.RPCCallCacheTTL = *dec.RPCCallCacheTTL
+	}
+	if dec.RPCCallCacheSize != nil {
+		c.RPCCallCacheSize = *dec.RPCCallCacheSize
+	}
 	if dec.Checkpoint != nil {
 		c.Checkpoint = dec.Checkpoint
 	}
 	if dec.CheckpointOracle != nil {
 		c.CheckpointOracle = dec.CheckpointOracle
 	}
+	if dec.ForkDryRun != nil {
+		c.ForkDryRun = *dec.ForkDryRun
+	}
+	if dec.ForkDryRunBlocks != nil {
+		c.ForkDryRunBlocks = *dec.ForkDryRunBlocks
+	}
 	if dec.OverrideEhardfork != nil {
 		c.OverrideEHardfork = dec.OverrideEhardfork
 	}
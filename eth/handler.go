@@ -22,11 +22,13 @@ import (
 	"fmt"
 	"math"
 	"math/big"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/common/hexutil"
 	"github.com/celo-org/celo-blockchain/consensus"
 	"github.com/celo-org/celo-blockchain/consensus/istanbul"
 	"github.com/celo-org/celo-blockchain/core"
@@ -52,6 +54,9 @@ const (
 	// txChanSize is the size of channel listening to NewTxsEvent.
 	// The number is referenced from the size of tx pool.
 	txChanSize = 4096
+
+	// committedBlockChanSize is the size of channel listening to NewCommittedBlockEvent.
+	committedBlockChanSize = 16
 )
 
 var (
@@ -65,6 +70,9 @@ func errResp(code errCode, format string, v ...interface{}) error {
 type ProtocolManager struct {
 	networkID  uint64
 	forkFilter forkid.Filter // Fork ID filter, constant across the lifetime of the node
+	forkConfig forkid.Config // Fork configuration, constant across the lifetime of the node
+
+	stateRecent uint64 // Number of recent blocks whose state is served, 0 for all (archive)
 
 	fastSync  uint32 // Flag whether fast sync is enabled (gets disabled if we already have blocks)
 	acceptTxs uint32 // Flag whether we're considered synchronised (enables transaction processing)
@@ -87,6 +95,9 @@ type ProtocolManager struct {
 	txsSub        event.Subscription
 	minedBlockSub *event.TypeMuxSubscription
 
+	committedBlockCh  chan core.NewCommittedBlockEvent
+	committedBlockSub event.Subscription
+
 	whitelist map[uint64]common.Hash
 
 	// channels for fetcher, syncer, txsyncLoop
@@ -115,6 +126,7 @@ func NewProtocolManager(config *params.ChainConfig, checkpoint *params.TrustedCh
 	manager := &ProtocolManager{
 		networkID:   networkID,
 		forkFilter:  forkid.NewFilter(blockchain),
+		forkConfig:  forkid.NewConfig(config),
 		eventMux:    mux,
 		txpool:      txpool,
 		blockchain:  blockchain,
@@ -127,6 +139,11 @@ func NewProtocolManager(config *params.ChainConfig, checkpoint *params.TrustedCh
 		server:      server,
 		proxyServer: proxyServer,
 	}
+	// Only advertise the recent states, unless running an archive node. Like the
+	// les server, keep a margin for the peers lagging behind.
+	if !blockchain.ArchiveMode() {
+		manager.stateRecent = core.TriesInMemory - 4
+	}
 
 	if handler, ok := manager.engine.(consensus.Handler); ok {
 		handler.SetBroadcaster(manager)
@@ -297,6 +314,14 @@ func (pm *ProtocolManager) Start(maxPeers int) {
 	pm.minedBlockSub = pm.eventMux.Subscribe(core.NewMinedBlockEvent{})
 	go pm.minedBroadcastLoop()
 
+	// broadcast committed blocks, if the engine announces them
+	if announcer, ok := pm.engine.(committedBlockAnnouncer); ok {
+		pm.wg.Add(1)
+		pm.committedBlockCh = make(chan core.NewCommittedBlockEvent, committedBlockChanSize)
+		pm.committedBlockSub = announcer.SubscribeCommittedBlockEvent(pm.committedBlockCh)
+		go pm.committedBroadcastLoop()
+	}
+
 	// start sync handlers
 	pm.wg.Add(2)
 	go pm.chainSync.loop()
@@ -306,6 +331,9 @@ func (pm *ProtocolManager) Start(maxPeers int) {
 func (pm *ProtocolManager) Stop() {
 	pm.txsSub.Unsubscribe()        // quits txBroadcastLoop
 	pm.minedBlockSub.Unsubscribe() // quits blockBroadcastLoop
+	if pm.committedBlockSub != nil {
+		pm.committedBlockSub.Unsubscribe() // quits committedBroadcastLoop
+	}
 
 	// Quit chainSync and txsync64.
 	// After this is done, no new peers will be accepted.
@@ -348,10 +376,17 @@ func (pm *ProtocolManager) handle(p *peer) error {
 		number  = head.Number.Uint64()
 		td      = pm.blockchain.GetTd(hash, number)
 	)
-	if err := p.Handshake(pm.networkID, td, hash, genesis.Hash(), forkid.NewID(pm.blockchain), pm.forkFilter); err != nil {
+	if err := p.Handshake(pm.networkID, td, hash, genesis.Hash(), forkid.NewID(pm.blockchain), pm.forkFilter, pm.forkConfig, pm.stateRecent); err != nil {
 		p.Log().Info("Ethereum handshake failed", "err", err)
 		return err
 	}
+	if remote := p.ForkConfig(); remote != nil && remote.Checksum != pm.forkConfig.Checksum {
+		var forks []string
+		for _, mismatch := range pm.forkConfig.Compare(remote) {
+			forks = append(forks, mismatch.Name)
+		}
+		p.Log().Warn("Peer fork configuration differs, see admin_forkStatus", "forks", forks)
+	}
 	forcePeer := false
 	if handler, ok := pm.engine.(consensus.Handler); ok {
 		isValidator, err := handler.Handshake(p)
@@ -975,6 +1010,41 @@ func (pm *ProtocolManager) minedBroadcastLoop() {
 	}
 }
 
+// committedBlockAnnouncer is implemented by the consensus engines notifying the
+// blocks reaching commit quorum, before they are imported.
+type committedBlockAnnouncer interface {
+	SubscribeCommittedBlockEvent(ch chan<- core.NewCommittedBlockEvent) event.Subscription
+}
+
+// committedBroadcastLoop announces the hashes of committed blocks to the connected
+// peers without waiting for them to be imported. The blocks themselves are only
+// propagated once imported, as mined blocks.
+func (pm *ProtocolManager) committedBroadcastLoop() {
+	defer pm.wg.Done()
+
+	for {
+		select {
+		case ev := <-pm.committedBlockCh:
+			pm.announceCommittedBlock(ev.Block)
+
+		case <-pm.committedBlockSub.Err():
+			return
+		}
+	}
+}
+
+// announceCommittedBlock announces the hash of a block that reached commit quorum
+// to the peers not knowing about it yet. The block is not marked as known by them,
+// leaving its full propagation to the post-import broadcast.
+func (pm *ProtocolManager) announceCommittedBlock(block *types.Block) {
+	hash := block.Hash()
+	peers := pm.peers.PeersWithoutBlock(hash)
+	for _, peer := range peers {
+		peer.AsyncSendCommittedBlockHash(block)
+	}
+	log.Trace("Announced committed block", "hash", hash, "recipients", len(peers))
+}
+
 // txBroadcastLoop announces new transactions to connected peers.
 func (pm *ProtocolManager) txBroadcastLoop() {
 	defer pm.wg.Done()
@@ -1018,6 +1088,66 @@ func (pm *ProtocolManager) NodeInfo() *NodeInfo {
 	}
 }
 
+// ForkStatus is the fork configuration of the host node, compared against the
+// ones advertised by its peers.
+type ForkStatus struct {
+	Checksum hexutil.Bytes             `json:"checksum"`
+	Forks    map[string]hexutil.Uint64 `json:"forks"` // Activation blocks of the scheduled forks
+	Peers    []*PeerForkStatus         `json:"peers"`
+}
+
+// PeerForkStatus is the fork configuration advertised by a peer, compared against
+// the one of the host node.
+type PeerForkStatus struct {
+	ID         string          `json:"id"`
+	Name       string          `json:"name"`
+	Version    int             `json:"version"`
+	Known      bool            `json:"known"` // Whether the peer advertised its fork configuration, from celo/67 on
+	Checksum   hexutil.Bytes   `json:"checksum,omitempty"`
+	Mismatches []*ForkMismatch `json:"mismatches,omitempty"`
+}
+
+// ForkMismatch is a fork scheduled differently by the host node and a peer.
+type ForkMismatch struct {
+	Name   string          `json:"name"`
+	Local  *hexutil.Uint64 `json:"local"`  // Nil if not scheduled by the host node
+	Remote *hexutil.Uint64 `json:"remote"` // Nil if not scheduled by the peer
+}
+
+// ForkStatus compares the fork configuration of the host node against the ones
+// advertised by its peers.
+func (pm *ProtocolManager) ForkStatus() *ForkStatus {
+	status := &ForkStatus{
+		Checksum: pm.forkConfig.Checksum[:],
+		Forks:    make(map[string]hexutil.Uint64, len(pm.forkConfig.Forks)),
+		Peers:    make([]*PeerForkStatus, 0),
+	}
+	for _, fork := range pm.forkConfig.Forks {
+		status.Forks[fork.Name] = hexutil.Uint64(fork.Block)
+	}
+	for _, p := range pm.peers.Peers() {
+		peerStatus := &PeerForkStatus{
+			ID:      p.ID().String(),
+			Name:    p.Name(),
+			Version: p.version,
+		}
+		if remote := p.ForkConfig(); remote != nil {
+			peerStatus.Known = true
+			peerStatus.Checksum = remote.Checksum[:]
+			for _, mismatch := range pm.forkConfig.Compare(remote) {
+				peerStatus.Mismatches = append(peerStatus.Mismatches, &ForkMismatch{
+					Name:   mismatch.Name,
+					Local:  (*hexutil.Uint64)(mismatch.Local),
+					Remote: (*hexutil.Uint64)(mismatch.Remote),
+				})
+			}
+		}
+		status.Peers = append(status.Peers, peerStatus)
+	}
+	sort.Slice(status.Peers, func(i, j int) bool { return status.Peers[i].ID < status.Peers[j].ID })
+	return status
+}
+
 func (pm *ProtocolManager) FindPeers(targets map[enode.ID]bool, purpose p2p.PurposeFlag) map[enode.ID]consensus.Peer {
 	m := make(map[enode.ID]consensus.Peer)
 	for _, p := range pm.peers.Peers() {
@@ -624,6 +624,48 @@ func testBroadcastBlock(t *testing.T, totalPeers, broadcastExpected int) {
 
 }
 
+// Tests that blocks reaching commit quorum are only announced by hash, and are
+// not marked as known so that they are still propagated once imported.
+func TestAnnounceCommittedBlock(t *testing.T) {
+	var (
+		engine  = mockEngine.NewFaker()
+		db      = rawdb.NewMemoryDatabase()
+		config  = &params.ChainConfig{}
+		gspec   = &core.Genesis{Config: config}
+		genesis = gspec.MustCommit(db)
+	)
+	blockchain, err := core.NewBlockChain(db, nil, config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create new blockchain: %v", err)
+	}
+	pm, err := NewProtocolManager(config, nil, downloader.FullSync, DefaultConfig.NetworkId, new(event.TypeMux), new(testTxPool), engine, blockchain, db, 1, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to start test protocol manager: %v", err)
+	}
+	pm.Start(1000)
+	defer pm.Stop()
+
+	var peers []*testPeer
+	for i := 0; i < 3; i++ {
+		peer, _ := newTestPeer(fmt.Sprintf("peer %d", i), istanbul.Celo64, pm, true)
+		defer peer.close()
+
+		peers = append(peers, peer)
+	}
+	chain, _ := core.GenerateChain(gspec.Config, genesis, mockEngine.NewFaker(), db, 1, func(i int, gen *core.BlockGen) {})
+	block := chain[0]
+	pm.announceCommittedBlock(block)
+
+	for i, peer := range peers {
+		if err := p2p.ExpectMsg(peer.app, NewBlockHashesMsg, newBlockHashesData{{Hash: block.Hash(), Number: block.NumberU64()}}); err != nil {
+			t.Fatalf("peer %d: announcement mismatch: %v", i, err)
+		}
+	}
+	if have := len(pm.peers.PeersWithoutBlock(block.Hash())); have != len(peers) {
+		t.Fatalf("peers without block mismatch: have %d, want %d", have, len(peers))
+	}
+}
+
 // Tests that a propagated malformed block (uncles or transactions don't match
 // with the hashes in the header) gets discarded and not broadcast forward.
 func TestBroadcastMalformedBlock(t *testing.T) {
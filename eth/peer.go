@@ -94,13 +94,16 @@ type peer struct {
 	version  int         // Protocol version negotiated
 	syncDrop *time.Timer // Timed connection dropper if sync progress isn't validated in time
 
-	head common.Hash
-	td   *big.Int
-	lock sync.RWMutex
+	head        common.Hash
+	td          *big.Int
+	forkConfig  *forkid.Config // Fork configuration advertised by the peer, nil before celo/67
+	stateRecent uint64         // Number of recent blocks whose state the peer serves, 0 for all or before celo/67
+	lock        sync.RWMutex
 
-	knownBlocks     mapset.Set        // Set of block hashes known to be known by this peer
-	queuedBlocks    chan *propEvent   // Queue of blocks to broadcast to the peer
-	queuedBlockAnns chan *types.Block // Queue of blocks to announce to the peer
+	knownBlocks         mapset.Set        // Set of block hashes known to be known by this peer
+	queuedBlocks        chan *propEvent   // Queue of blocks to broadcast to the peer
+	queuedBlockAnns     chan *types.Block // Queue of blocks to announce to the peer
+	queuedCommittedAnns chan *types.Block // Queue of committed, not yet imported blocks to announce to the peer
 
 	knownTxs    mapset.Set                           // Set of transaction hashes known to be known by this peer
 	txBroadcast chan []common.Hash                   // Channel used to queue transaction propagation requests
@@ -112,18 +115,19 @@ type peer struct {
 
 func newPeer(version int, p *p2p.Peer, rw p2p.MsgReadWriter, getPooledTx func(hash common.Hash) *types.Transaction) *peer {
 	return &peer{
-		Peer:            p,
-		rw:              rw,
-		version:         version,
-		id:              fmt.Sprintf("%x", p.ID().Bytes()[:8]),
-		knownTxs:        mapset.NewSet(),
-		knownBlocks:     mapset.NewSet(),
-		queuedBlocks:    make(chan *propEvent, maxQueuedBlocks),
-		queuedBlockAnns: make(chan *types.Block, maxQueuedBlockAnns),
-		txBroadcast:     make(chan []common.Hash),
-		txAnnounce:      make(chan []common.Hash),
-		getPooledTx:     getPooledTx,
-		term:            make(chan struct{}),
+		Peer:                p,
+		rw:                  rw,
+		version:             version,
+		id:                  fmt.Sprintf("%x", p.ID().Bytes()[:8]),
+		knownTxs:            mapset.NewSet(),
+		knownBlocks:         mapset.NewSet(),
+		queuedBlocks:        make(chan *propEvent, maxQueuedBlocks),
+		queuedBlockAnns:     make(chan *types.Block, maxQueuedBlockAnns),
+		queuedCommittedAnns: make(chan *types.Block, maxQueuedBlockAnns),
+		txBroadcast:         make(chan []common.Hash),
+		txAnnounce:          make(chan []common.Hash),
+		getPooledTx:         getPooledTx,
+		term:                make(chan struct{}),
 	}
 }
 
@@ -147,6 +151,13 @@ func (p *peer) broadcastBlocks(removePeer func(string)) {
 			}
 			p.Log().Trace("Announced block", "number", block.Number(), "hash", block.Hash())
 
+		case block := <-p.queuedCommittedAnns:
+			if err := p.sendNewBlockHashes([]common.Hash{block.Hash()}, []uint64{block.NumberU64()}); err != nil {
+				removePeer(p.id)
+				return
+			}
+			p.Log().Trace("Announced committed block", "number", block.Number(), "hash", block.Hash())
+
 		case <-p.term:
 			return
 		}
@@ -305,6 +316,18 @@ func (p *peer) Head() (hash common.Hash, td *big.Int) {
 	return hash, new(big.Int).Set(p.td)
 }
 
+// ForkConfig retrieves the fork configuration advertised by the peer in its
+// handshake, or nil if its protocol version does not carry it.
+func (p *peer) ForkConfig() *forkid.Config {
+	return p.forkConfig
+}
+
+// ServeRecentState retrieves the number of recent blocks whose state the peer
+// advertised to serve, where zero stands for all of them.
+func (p *peer) ServeRecentState() uint64 {
+	return p.stateRecent
+}
+
 // SetHead updates the head hash and total difficulty of the peer.
 func (p *peer) SetHead(hash common.Hash, td *big.Int) {
 	p.lock.Lock()
@@ -445,6 +468,12 @@ func (p *peer) SendNewBlockHashes(hashes []common.Hash, numbers []uint64) error
 	for _, hash := range hashes {
 		p.knownBlocks.Add(hash)
 	}
+	return p.sendNewBlockHashes(hashes, numbers)
+}
+
+// sendNewBlockHashes announces the availability of a number of blocks without
+// marking them as known, so that they are still propagated to the peer later.
+func (p *peer) sendNewBlockHashes(hashes []common.Hash, numbers []uint64) error {
 	request := make(newBlockHashesData, len(hashes))
 	for i := 0; i < len(hashes); i++ {
 		request[i].Hash = hashes[i]
@@ -469,6 +498,18 @@ func (p *peer) AsyncSendNewBlockHash(block *types.Block) {
 	}
 }
 
+// AsyncSendCommittedBlockHash queues the announcement of a block that reached
+// commit quorum but is not imported yet. Unlike AsyncSendNewBlockHash the block
+// is not marked as known, so that it is propagated in full once imported. If the
+// peer's announcement queue is full, the event is silently dropped.
+func (p *peer) AsyncSendCommittedBlockHash(block *types.Block) {
+	select {
+	case p.queuedCommittedAnns <- block:
+	default:
+		p.Log().Debug("Dropping committed block announcement", "number", block.NumberU64(), "hash", block.Hash())
+	}
+}
+
 // SendNewBlock propagates an entire block to a remote peer.
 func (p *peer) SendNewBlock(block *types.Block, td *big.Int) error {
 	// Mark all the block hash as known, but ensure we don't overflow our limits
@@ -565,14 +606,16 @@ func (p *peer) RequestTxs(hashes []common.Hash) error {
 }
 
 // Handshake executes the eth protocol handshake, negotiating version number,
-// network IDs, difficulties, head and genesis blocks.
-func (p *peer) Handshake(network uint64, td *big.Int, head common.Hash, genesis common.Hash, forkID forkid.ID, forkFilter forkid.Filter) error {
+// network IDs, difficulties, head and genesis blocks, and from celo/67 on
+// exchanging the fork configurations and the number of recent states served.
+func (p *peer) Handshake(network uint64, td *big.Int, head common.Hash, genesis common.Hash, forkID forkid.ID, forkFilter forkid.Filter, forkConfig forkid.Config, stateRecent uint64) error {
 	// Send out own handshake in a new thread
 	errc := make(chan error, 2)
 
 	var (
 		status63 statusData63 // safe to read after two values have been received from errc
 		status   statusData   // safe to read after two values have been received from errc
+		status67 statusData67 // safe to read after two values have been received from errc
 	)
 	go func() {
 		switch {
@@ -584,6 +627,17 @@ func (p *peer) Handshake(network uint64, td *big.Int, head common.Hash, genesis
 				CurrentBlock:    head,
 				GenesisBlock:    genesis,
 			})
+		case p.version >= istanbul.Celo67:
+			errc <- p2p.Send(p.rw, StatusMsg, &statusData67{
+				ProtocolVersion: uint32(p.version),
+				NetworkID:       network,
+				TD:              td,
+				Head:            head,
+				Genesis:         genesis,
+				ForkID:          forkID,
+				ForkConfig:      forkConfig,
+				StateRecent:     stateRecent,
+			})
 		case p.version >= istanbul.Celo65:
 			errc <- p2p.Send(p.rw, StatusMsg, &statusData{
 				ProtocolVersion: uint32(p.version),
@@ -601,6 +655,8 @@ func (p *peer) Handshake(network uint64, td *big.Int, head common.Hash, genesis
 		switch {
 		case p.version == istanbul.Celo64:
 			errc <- p.readStatusLegacy(network, &status63, genesis)
+		case p.version >= istanbul.Celo67:
+			errc <- p.readStatus67(network, &status67, genesis, forkFilter)
 		case p.version >= istanbul.Celo65:
 			errc <- p.readStatus(network, &status, genesis, forkFilter)
 		default:
@@ -622,6 +678,9 @@ func (p *peer) Handshake(network uint64, td *big.Int, head common.Hash, genesis
 	switch {
 	case p.version == istanbul.Celo64:
 		p.td, p.head = status63.TD, status63.CurrentBlock
+	case p.version >= istanbul.Celo67:
+		p.td, p.head = status67.TD, status67.Head
+		p.forkConfig, p.stateRecent = &status67.ForkConfig, status67.StateRecent
 	case p.version >= istanbul.Celo65:
 		p.td, p.head = status.TD, status.Head
 	default:
@@ -666,6 +725,35 @@ func (p *peer) readStatus(network uint64, status *statusData, genesis common.Has
 	if err := msg.Decode(&status); err != nil {
 		return errResp(ErrDecode, "msg %v: %v", msg, err)
 	}
+	return p.checkStatus(network, status, genesis, forkFilter)
+}
+
+// readStatus67 reads the status message of celo/67 and later, which extends the
+// one of celo/65 with the fork configuration of the peer.
+func (p *peer) readStatus67(network uint64, status *statusData67, genesis common.Hash, forkFilter forkid.Filter) error {
+	msg, err := p.ReadMsg()
+	if err != nil {
+		return err
+	}
+	if msg.Code != StatusMsg {
+		return errResp(ErrNoStatusMsg, "first msg has code %x (!= %x)", msg.Code, StatusMsg)
+	}
+	// Decode the handshake and make sure everything matches
+	if err := msg.Decode(&status); err != nil {
+		return errResp(ErrDecode, "msg %v: %v", msg, err)
+	}
+	return p.checkStatus(network, &statusData{
+		ProtocolVersion: status.ProtocolVersion,
+		NetworkID:       status.NetworkID,
+		TD:              status.TD,
+		Head:            status.Head,
+		Genesis:         status.Genesis,
+		ForkID:          status.ForkID,
+	}, genesis, forkFilter)
+}
+
+// checkStatus makes sure the status of the peer matches the local one.
+func (p *peer) checkStatus(network uint64, status *statusData, genesis common.Hash, forkFilter forkid.Filter) error {
 	if status.NetworkID != network {
 		return errResp(ErrNetworkIDMismatch, "%d (!= %d)", status.NetworkID, network)
 	}
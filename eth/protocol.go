@@ -126,6 +126,21 @@ type statusData struct {
 	ForkID          forkid.ID
 }
 
+// statusData67 is the network packet for the status message for celo/67 and later.
+type statusData67 struct {
+	ProtocolVersion uint32
+	NetworkID       uint64
+	TD              *big.Int
+	Head            common.Hash
+	Genesis         common.Hash
+	ForkID          forkid.ID
+	ForkConfig      forkid.Config
+	StateRecent     uint64 // Number of recent blocks whose state is served, 0 for all (archive)
+
+	// Ignore additional fields (for forward compatibility).
+	Rest []rlp.RawValue `rlp:"tail"`
+}
+
 // newBlockHashesData is the network packet for the block announcements.
 type newBlockHashesData []struct {
 	Hash   common.Hash // Hash of one particular block being announced
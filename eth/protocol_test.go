@@ -19,12 +19,14 @@ package eth
 import (
 	"fmt"
 	"math/big"
+	"reflect"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/common/hexutil"
 	mockEngine "github.com/celo-org/celo-blockchain/consensus/consensustest"
 	"github.com/celo-org/celo-blockchain/core"
 	"github.com/celo-org/celo-blockchain/core/forkid"
@@ -248,10 +250,118 @@ func TestForkIDSplit(t *testing.T) {
 	}
 }
 
+func TestForkStatus(t *testing.T) {
+	var (
+		engine = mockEngine.NewFaker()
+
+		configLocal  = &params.ChainConfig{HomesteadBlock: big.NewInt(1), ChurritoBlock: big.NewInt(10), DonutBlock: big.NewInt(20)}
+		configRemote = &params.ChainConfig{HomesteadBlock: big.NewInt(1), ChurritoBlock: big.NewInt(10), DonutBlock: big.NewInt(30), EBlock: big.NewInt(40)}
+		dbLocal      = rawdb.NewMemoryDatabase()
+		dbRemote     = rawdb.NewMemoryDatabase()
+
+		genesisLocal  = core.DeveloperGenesisBlock().MustCommit(dbLocal)
+		genesisRemote = core.DeveloperGenesisBlock().MustCommit(dbRemote)
+
+		chainLocal, _  = core.NewBlockChain(dbLocal, nil, configLocal, engine, vm.Config{}, nil, nil)
+		chainRemote, _ = core.NewBlockChain(dbRemote, nil, configRemote, engine, vm.Config{}, nil, nil)
+
+		ethLocal, _  = NewProtocolManager(configLocal, nil, downloader.FullSync, 1, new(event.TypeMux), &testTxPool{pool: make(map[common.Hash]*types.Transaction)}, engine, chainLocal, dbLocal, 1, nil, nil, nil)
+		ethRemote, _ = NewProtocolManager(configRemote, nil, downloader.FullSync, 1, new(event.TypeMux), &testTxPool{pool: make(map[common.Hash]*types.Transaction)}, engine, chainRemote, dbRemote, 1, nil, nil, nil)
+	)
+	if genesisLocal.Hash() != genesisRemote.Hash() {
+		t.Fatalf("genesis mismatch: %x != %x", genesisLocal.Hash(), genesisRemote.Hash())
+	}
+	ethLocal.Start(1000)
+	ethRemote.Start(1000)
+	defer ethLocal.Stop()
+	defer ethRemote.Stop()
+
+	// The next fork matches, so the peers connect despite the later forks differing
+	p2pLocal, p2pRemote := p2p.MsgPipe()
+	peerLocal := newPeer(67, p2p.NewPeer(enode.ID{1}, "local", nil), p2pLocal, nil)
+	peerRemote := newPeer(67, p2p.NewPeer(enode.ID{2}, "remote", nil), p2pRemote, nil)
+	defer p2pLocal.Close()
+	defer p2pRemote.Close()
+
+	go ethLocal.handle(peerRemote)
+	go ethRemote.handle(peerLocal)
+
+	var status *ForkStatus
+	for start := time.Now(); time.Since(start) < time.Second; time.Sleep(10 * time.Millisecond) {
+		if status = ethLocal.ForkStatus(); len(status.Peers) > 0 {
+			break
+		}
+	}
+	if len(status.Peers) != 1 {
+		t.Fatalf("peer count mismatch: have %d, want 1", len(status.Peers))
+	}
+	if status.Forks["donut"] != 20 {
+		t.Errorf("local donut block mismatch: have %d, want 20", status.Forks["donut"])
+	}
+	peer := status.Peers[0]
+	if !peer.Known || peer.Name != "remote" {
+		t.Fatalf("peer status mismatch: have %+v", peer)
+	}
+	local, remote := hexutil.Uint64(20), hexutil.Uint64(30)
+	later := hexutil.Uint64(40)
+	want := []*ForkMismatch{
+		{Name: "donut", Local: &local, Remote: &remote},
+		{Name: "e", Remote: &later},
+	}
+	if !reflect.DeepEqual(peer.Mismatches, want) {
+		t.Errorf("fork mismatches differ: have %+v, want %+v", peer.Mismatches, want)
+	}
+}
+
+// Tests that the number of recent states served is exchanged in the celo/67 handshake.
+func TestServeRecentState(t *testing.T) {
+	pm, _ := newTestProtocolManagerMust(t, downloader.FullSync, 0, nil, nil)
+	defer pm.Stop()
+
+	if pm.stateRecent != core.TriesInMemory-4 {
+		t.Fatalf("advertised recent states mismatch: have %d, want %d", pm.stateRecent, core.TriesInMemory-4)
+	}
+	var (
+		genesis = pm.blockchain.Genesis()
+		head    = pm.blockchain.CurrentHeader()
+		td      = pm.blockchain.GetTd(head.Hash(), head.Number.Uint64())
+	)
+	p, _ := newTestPeer("peer", 67, pm, false)
+	defer p.close()
+
+	status := &statusData67{
+		ProtocolVersion: 67,
+		NetworkID:       DefaultConfig.NetworkId,
+		TD:              td,
+		Head:            head.Hash(),
+		Genesis:         genesis.Hash(),
+		ForkID:          forkid.NewID(pm.blockchain),
+		ForkConfig:      pm.forkConfig,
+		StateRecent:     pm.stateRecent,
+	}
+	if err := p2p.ExpectMsg(p.app, StatusMsg, status); err != nil {
+		t.Fatalf("status recv: %v", err)
+	}
+	status.StateRecent = 1000
+	if err := p2p.Send(p.app, StatusMsg, status); err != nil {
+		t.Fatalf("status send: %v", err)
+	}
+
+	for start := time.Now(); pm.peers.Peer(p.id) == nil; time.Sleep(10 * time.Millisecond) {
+		if time.Since(start) > time.Second {
+			t.Fatalf("peer not registered")
+		}
+	}
+	if recent := pm.peers.Peer(p.id).ServeRecentState(); recent != 1000 {
+		t.Errorf("peer recent states mismatch: have %d, want 1000", recent)
+	}
+}
+
 // This test checks that received transactions are added to the local pool.
 func TestRecvTransactions64(t *testing.T) { testRecvTransactions(t, 64) }
 func TestRecvTransactions65(t *testing.T) { testRecvTransactions(t, 65) }
 func TestRecvTransactions66(t *testing.T) { testRecvTransactions(t, 66) }
+func TestRecvTransactions67(t *testing.T) { testRecvTransactions(t, 67) }
 
 func testRecvTransactions(t *testing.T, protocol int) {
 	txAdded := make(chan []*types.Transaction)
@@ -281,6 +391,7 @@ func testRecvTransactions(t *testing.T, protocol int) {
 func TestSendTransactions64(t *testing.T) { testSendTransactions(t, 64) }
 func TestSendTransactions65(t *testing.T) { testSendTransactions(t, 65) }
 func TestSendTransactions66(t *testing.T) { testSendTransactions(t, 66) }
+func TestSendTransactions67(t *testing.T) { testSendTransactions(t, 67) }
 
 func testSendTransactions(t *testing.T, protocol int) {
 	pm, _ := newTestProtocolManagerMust(t, downloader.FullSync, 0, nil, nil)
@@ -330,7 +441,7 @@ func testSendTransactions(t *testing.T, protocol int) {
 						callback(tx.Hash())
 					}
 				}
-			case 66:
+			case 66, 67:
 				msg, err := p.app.ReadMsg()
 				if err != nil {
 					t.Errorf("%v: read error: %v", p.Peer, err)
@@ -0,0 +1,238 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/common/hexutil"
+	"github.com/celo-org/celo-blockchain/core/vm"
+)
+
+// GasProfilerName is the name the native gas profiling tracer is selected by.
+const GasProfilerName = "gasProfiler"
+
+// GasProfileEntry aggregates the executions of an opcode or precompile.
+type GasProfileEntry struct {
+	Count uint64         `json:"count"`
+	Gas   uint64         `json:"gas"`
+	Time  hexutil.Uint64 `json:"timeNs"` // Wall time in nanoseconds
+}
+
+// GasProfile is the result of the gas profiler. Opcodes are keyed by name,
+// precompiles by address.
+type GasProfile struct {
+	Transactions uint64                              `json:"transactions"`
+	Opcodes      map[string]*GasProfileEntry         `json:"opcodes"`
+	Precompiles  map[common.Address]*GasProfileEntry `json:"precompiles"`
+}
+
+// pendingCall is a call or create executed by a frame, settled once execution
+// returns to the frame.
+type pendingCall struct {
+	op         vm.OpCode
+	gas        uint64 // Gas of the frame before the call
+	precompile *common.Address
+	required   uint64 // Gas charged by the precompile
+}
+
+// profiledFrame tracks the gas of a call frame.
+type profiledFrame struct {
+	pending *pendingCall
+
+	started  bool
+	failed   bool      // Whether the frame halted exceptionally
+	startGas uint64    // Gas available to the frame
+	lastGas  uint64    // Gas before the last executed opcode
+	lastCost uint64    // Cost of the last executed opcode
+	lastOp   vm.OpCode // Last executed opcode
+}
+
+// remaining returns the gas the frame returned to its caller.
+func (f *profiledFrame) remaining() uint64 {
+	if f.failed {
+		// Exceptional halts consume all the gas
+		return 0
+	}
+	switch f.lastOp {
+	case vm.STOP, vm.RETURN, vm.REVERT, vm.SELFDESTRUCT:
+		if f.lastGas >= f.lastCost {
+			return f.lastGas - f.lastCost
+		}
+	}
+	return 0
+}
+
+// GasProfiler is a native tracer aggregating the gas and wall time spent per
+// opcode and precompile, across any number of transactions. The gas of the
+// call and create opcodes excludes the gas used by their callee, so that the
+// profile reflects what every opcode costs by itself. The time of an opcode is
+// the time until the next one starts, calls to precompiles are attributed to
+// the precompile.
+type GasProfiler struct {
+	profile GasProfile
+	frames  []profiledFrame // Indexed by call depth
+
+	last     *GasProfileEntry // Entry the time since lastTime is attributed to
+	lastTime time.Time
+}
+
+// NewGasProfiler creates an empty gas profiler.
+func NewGasProfiler() *GasProfiler {
+	return &GasProfiler{
+		profile: GasProfile{
+			Opcodes:     make(map[string]*GasProfileEntry),
+			Precompiles: make(map[common.Address]*GasProfileEntry),
+		},
+	}
+}
+
+// Profile returns the aggregated profile.
+func (p *GasProfiler) Profile() *GasProfile {
+	return &p.profile
+}
+
+func (p *GasProfiler) opcode(op vm.OpCode) *GasProfileEntry {
+	entry := p.profile.Opcodes[op.String()]
+	if entry == nil {
+		entry = new(GasProfileEntry)
+		p.profile.Opcodes[op.String()] = entry
+	}
+	return entry
+}
+
+func (p *GasProfiler) precompile(addr common.Address) *GasProfileEntry {
+	entry := p.profile.Precompiles[addr]
+	if entry == nil {
+		entry = new(GasProfileEntry)
+		p.profile.Precompiles[addr] = entry
+	}
+	return entry
+}
+
+// clock attributes the time since the previous step to its entry, and starts
+// timing the given one.
+func (p *GasProfiler) clock(next *GasProfileEntry) {
+	now := time.Now()
+	if p.last != nil {
+		p.last.Time += hexutil.Uint64(now.Sub(p.lastTime))
+	}
+	p.last, p.lastTime = next, now
+}
+
+// CaptureStart implements vm.Tracer, it is called for every transaction.
+func (p *GasProfiler) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	p.profile.Transactions++
+	p.frames = p.frames[:0]
+	p.last = nil
+	return nil
+}
+
+// CaptureState implements vm.Tracer.
+func (p *GasProfiler) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, rStack *vm.ReturnStack, rData []byte, contract *vm.Contract, depth int, err error) error {
+	for len(p.frames) <= depth+1 {
+		p.frames = append(p.frames, profiledFrame{})
+	}
+	frame := &p.frames[depth]
+	if frame.pending != nil {
+		p.settle(depth, gas)
+	}
+	if !frame.started {
+		frame.started, frame.startGas = true, gas
+	}
+	frame.lastGas, frame.lastCost, frame.lastOp = gas, cost, op
+	if err != nil {
+		// Errors of unexecuted opcodes halt the frame
+		frame.failed = true
+	}
+
+	entry := p.opcode(op)
+	entry.Count++
+	timed := entry
+
+	switch op {
+	case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL, vm.CREATE, vm.CREATE2:
+		if err != nil {
+			// The call failed before being made
+			entry.Gas += cost
+			break
+		}
+		call := &pendingCall{op: op, gas: gas}
+		if op != vm.CREATE && op != vm.CREATE2 {
+			addr := common.Address(stack.Back(1).Bytes20())
+			if precompile, ok := env.ActivePrecompile(addr); ok {
+				inOffset, inSize := stack.Back(2), stack.Back(3)
+				if op == vm.CALL || op == vm.CALLCODE {
+					inOffset, inSize = stack.Back(3), stack.Back(4)
+				}
+				call.precompile = &addr
+				call.required = precompile.RequiredGas(memory.GetCopy(int64(inOffset.Uint64()), int64(inSize.Uint64())))
+				timed = p.precompile(addr)
+			}
+		}
+		frame.pending = call
+		p.frames[depth+1] = profiledFrame{}
+	default:
+		entry.Gas += cost
+	}
+	p.clock(timed)
+	return nil
+}
+
+// settle accounts for the call made by the frame at the given depth, once
+// execution returned to it with the given gas left.
+func (p *GasProfiler) settle(depth int, gas uint64) {
+	call := p.frames[depth].pending
+	p.frames[depth].pending = nil
+
+	var consumed, callee uint64
+	if call.gas > gas {
+		consumed = call.gas - gas
+	}
+	if call.precompile != nil {
+		entry := p.precompile(*call.precompile)
+		entry.Count++
+		if consumed >= call.required {
+			callee = call.required
+			entry.Gas += callee
+		}
+	} else if child := &p.frames[depth+1]; child.started {
+		if remaining := child.remaining(); child.startGas > remaining {
+			callee = child.startGas - remaining
+		}
+	}
+	if consumed > callee {
+		p.opcode(call.op).Gas += consumed - callee
+	}
+}
+
+// CaptureFault implements vm.Tracer.
+func (p *GasProfiler) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, rStack *vm.ReturnStack, contract *vm.Contract, depth int, err error) error {
+	// Reverts return the remaining gas, unlike the other errors
+	if depth < len(p.frames) && err != vm.ErrExecutionReverted {
+		p.frames[depth].failed = true
+	}
+	return nil
+}
+
+// CaptureEnd implements vm.Tracer.
+func (p *GasProfiler) CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) error {
+	p.clock(nil)
+	return nil
+}
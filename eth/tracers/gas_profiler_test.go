@@ -0,0 +1,95 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/common/hexutil"
+	"github.com/celo-org/celo-blockchain/core"
+	"github.com/celo-org/celo-blockchain/core/rawdb"
+	"github.com/celo-org/celo-blockchain/core/vm"
+	"github.com/celo-org/celo-blockchain/core/vm/vmcontext"
+	"github.com/celo-org/celo-blockchain/params"
+	"github.com/celo-org/celo-blockchain/tests"
+)
+
+func TestGasProfiler(t *testing.T) {
+	var (
+		origin   = common.HexToAddress("0x00000000000000000000000000000000000000aa")
+		caller   = common.HexToAddress("0x00000000000000000000000000000000000000bb")
+		callee   = common.HexToAddress("0x00000000000000000000000000000000000000cc")
+		identity = common.BytesToAddress([]byte{4})
+	)
+	alloc := core.GenesisAlloc{
+		// STATICCALL the identity precompile with 32 bytes, then the callee
+		caller: {
+			Code:    hexutil.MustDecode("0x6020600060206000600461fffffa50600060006000600060cc61fffffa5000"),
+			Balance: new(big.Int),
+		},
+		// PUSH1 1, PUSH1 2, ADD, POP, STOP
+		callee: {
+			Code:    hexutil.MustDecode("0x60016002015000"),
+			Balance: new(big.Int),
+		},
+	}
+	_, statedb := tests.MakePreState(rawdb.NewMemoryDatabase(), alloc, false)
+	context := vm.Context{
+		CanTransfer: vmcontext.CanTransfer,
+		Transfer:    vmcontext.TobinTransfer,
+		Origin:      origin,
+		BlockNumber: big.NewInt(1),
+		Time:        big.NewInt(1),
+		GasPrice:    big.NewInt(1),
+	}
+	profiler := NewGasProfiler()
+	evm := vm.NewEVM(context, statedb, params.IstanbulTestChainConfig, vm.Config{Debug: true, Tracer: profiler})
+
+	const gas = 100000
+	_, left, err := evm.Call(vm.AccountRef(origin), caller, nil, gas, new(big.Int))
+	if err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	profile := profiler.Profile()
+	if profile.Transactions != 1 {
+		t.Errorf("transaction count mismatch: have %d, want 1", profile.Transactions)
+	}
+	// The calls cost 700 each, the first one also expands memory by a word
+	if call := profile.Opcodes["STATICCALL"]; call == nil || call.Count != 2 || call.Gas != 2*700+3 {
+		t.Errorf("STATICCALL profile mismatch: have %+v", call)
+	}
+	if add := profile.Opcodes["ADD"]; add == nil || add.Count != 1 || add.Gas != 3 {
+		t.Errorf("ADD profile mismatch: have %+v", add)
+	}
+	// The identity precompile costs 15 plus 3 per word
+	if p := profile.Precompiles[identity]; p == nil || p.Count != 1 || p.Gas != 18 {
+		t.Errorf("identity precompile profile mismatch: have %+v", p)
+	}
+	// All the gas used is accounted for exactly once
+	var total uint64
+	for _, entry := range profile.Opcodes {
+		total += entry.Gas
+	}
+	for _, entry := range profile.Precompiles {
+		total += entry.Gas
+	}
+	if total != gas-left {
+		t.Errorf("profiled gas mismatch: have %d, want %d", total, gas-left)
+	}
+}
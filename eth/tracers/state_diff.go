@@ -0,0 +1,249 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"sort"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/common/hexutil"
+	"github.com/celo-org/celo-blockchain/core/vm"
+)
+
+// StateDiffTracerName is the name the native state diff tracer is selected by.
+const StateDiffTracerName = "stateDiffTracer"
+
+// StateDiffAccount is the state of an account, or the part of it that changed.
+type StateDiffAccount struct {
+	Balance *hexutil.Big                `json:"balance,omitempty"`
+	Nonce   *hexutil.Uint64             `json:"nonce,omitempty"`
+	Code    hexutil.Bytes               `json:"code,omitempty"`
+	Storage map[common.Hash]common.Hash `json:"storage,omitempty"`
+}
+
+// StateDiff is the result of the state diff tracer. Pre holds the state of every
+// account and storage slot accessed before execution, Post the values that were
+// changed by it. Accounts missing from Pre did not exist before, the ones listed
+// in Deleted were destroyed.
+type StateDiff struct {
+	Pre     map[common.Address]*StateDiffAccount `json:"pre"`
+	Post    map[common.Address]*StateDiffAccount `json:"post"`
+	Deleted []common.Address                     `json:"deleted,omitempty"`
+}
+
+// prestate is the state of an account when it was first accessed.
+type prestate struct {
+	exists  bool
+	balance *big.Int
+	nonce   uint64
+	code    []byte
+	storage map[common.Hash]common.Hash
+}
+
+// StateDiffTracer is a native tracer recording the state changes of a transaction.
+// Instead of following the executed opcodes, it wraps the state database the
+// transaction is executed against, so it also observes the changes made outside
+// of the transaction's own call frames, like the fee currency debits and credits
+// and system calls. The EVM should be run without a vm.Tracer.
+type StateDiffTracer struct {
+	vm.StateDB
+	accounts map[common.Address]*prestate
+}
+
+// NewStateDiffTracer creates a state diff tracer wrapping the given state, which
+// has to be used in its place for execution.
+func NewStateDiffTracer(statedb vm.StateDB) *StateDiffTracer {
+	return &StateDiffTracer{
+		StateDB:  statedb,
+		accounts: make(map[common.Address]*prestate),
+	}
+}
+
+// lookupAccount records the state of the account if it is accessed for the first time.
+func (t *StateDiffTracer) lookupAccount(addr common.Address) *prestate {
+	if account, ok := t.accounts[addr]; ok {
+		return account
+	}
+	account := &prestate{
+		exists:  t.StateDB.Exist(addr),
+		balance: new(big.Int).Set(t.StateDB.GetBalance(addr)),
+		nonce:   t.StateDB.GetNonce(addr),
+		code:    common.CopyBytes(t.StateDB.GetCode(addr)),
+		storage: make(map[common.Hash]common.Hash),
+	}
+	t.accounts[addr] = account
+	return account
+}
+
+// lookupStorage records the value of the storage slot if it is accessed for the first time.
+func (t *StateDiffTracer) lookupStorage(addr common.Address, key common.Hash) {
+	account := t.lookupAccount(addr)
+	if _, ok := account.storage[key]; !ok {
+		account.storage[key] = t.StateDB.GetState(addr, key)
+	}
+}
+
+func (t *StateDiffTracer) CreateAccount(addr common.Address) {
+	t.lookupAccount(addr)
+	t.StateDB.CreateAccount(addr)
+}
+
+func (t *StateDiffTracer) SubBalance(addr common.Address, amount *big.Int) {
+	t.lookupAccount(addr)
+	t.StateDB.SubBalance(addr, amount)
+}
+
+func (t *StateDiffTracer) AddBalance(addr common.Address, amount *big.Int) {
+	t.lookupAccount(addr)
+	t.StateDB.AddBalance(addr, amount)
+}
+
+func (t *StateDiffTracer) GetBalance(addr common.Address) *big.Int {
+	t.lookupAccount(addr)
+	return t.StateDB.GetBalance(addr)
+}
+
+func (t *StateDiffTracer) GetNonce(addr common.Address) uint64 {
+	t.lookupAccount(addr)
+	return t.StateDB.GetNonce(addr)
+}
+
+func (t *StateDiffTracer) SetNonce(addr common.Address, nonce uint64) {
+	t.lookupAccount(addr)
+	t.StateDB.SetNonce(addr, nonce)
+}
+
+func (t *StateDiffTracer) GetCodeHash(addr common.Address) common.Hash {
+	t.lookupAccount(addr)
+	return t.StateDB.GetCodeHash(addr)
+}
+
+func (t *StateDiffTracer) GetCode(addr common.Address) []byte {
+	t.lookupAccount(addr)
+	return t.StateDB.GetCode(addr)
+}
+
+func (t *StateDiffTracer) SetCode(addr common.Address, code []byte) {
+	t.lookupAccount(addr)
+	t.StateDB.SetCode(addr, code)
+}
+
+func (t *StateDiffTracer) GetCodeSize(addr common.Address) int {
+	t.lookupAccount(addr)
+	return t.StateDB.GetCodeSize(addr)
+}
+
+func (t *StateDiffTracer) GetCommittedState(addr common.Address, key common.Hash) common.Hash {
+	t.lookupStorage(addr, key)
+	return t.StateDB.GetCommittedState(addr, key)
+}
+
+func (t *StateDiffTracer) GetState(addr common.Address, key common.Hash) common.Hash {
+	t.lookupStorage(addr, key)
+	return t.StateDB.GetState(addr, key)
+}
+
+func (t *StateDiffTracer) SetState(addr common.Address, key common.Hash, value common.Hash) {
+	t.lookupStorage(addr, key)
+	t.StateDB.SetState(addr, key, value)
+}
+
+func (t *StateDiffTracer) Suicide(addr common.Address) bool {
+	t.lookupAccount(addr)
+	return t.StateDB.Suicide(addr)
+}
+
+func (t *StateDiffTracer) Exist(addr common.Address) bool {
+	t.lookupAccount(addr)
+	return t.StateDB.Exist(addr)
+}
+
+func (t *StateDiffTracer) Empty(addr common.Address) bool {
+	t.lookupAccount(addr)
+	return t.StateDB.Empty(addr)
+}
+
+// StateDiff assembles the state changes made since the tracer was created.
+func (t *StateDiffTracer) StateDiff() *StateDiff {
+	diff := &StateDiff{
+		Pre:  make(map[common.Address]*StateDiffAccount),
+		Post: make(map[common.Address]*StateDiffAccount),
+	}
+	for addr, pre := range t.accounts {
+		if pre.exists {
+			nonce := hexutil.Uint64(pre.nonce)
+			account := &StateDiffAccount{
+				Balance: (*hexutil.Big)(pre.balance),
+				Nonce:   &nonce,
+				Code:    pre.code,
+			}
+			for key, value := range pre.storage {
+				if account.Storage == nil {
+					account.Storage = make(map[common.Hash]common.Hash)
+				}
+				account.Storage[key] = value
+			}
+			diff.Pre[addr] = account
+		}
+		if t.StateDB.HasSuicided(addr) || !t.StateDB.Exist(addr) {
+			if pre.exists {
+				diff.Deleted = append(diff.Deleted, addr)
+			}
+			continue
+		}
+		// Empty accounts touched by the transaction are removed when it is finalised
+		if !pre.exists && t.StateDB.Empty(addr) {
+			continue
+		}
+		var (
+			post    = new(StateDiffAccount)
+			changed bool
+		)
+		if balance := t.StateDB.GetBalance(addr); !pre.exists || balance.Cmp(pre.balance) != 0 {
+			post.Balance, changed = (*hexutil.Big)(new(big.Int).Set(balance)), true
+		}
+		if nonce := t.StateDB.GetNonce(addr); !pre.exists || nonce != pre.nonce {
+			post.Nonce, changed = (*hexutil.Uint64)(&nonce), true
+		}
+		if code := t.StateDB.GetCode(addr); !bytes.Equal(code, pre.code) {
+			post.Code, changed = common.CopyBytes(code), true
+		}
+		for key, value := range pre.storage {
+			if current := t.StateDB.GetState(addr, key); current != value {
+				if post.Storage == nil {
+					post.Storage = make(map[common.Hash]common.Hash)
+				}
+				post.Storage[key], changed = current, true
+			}
+		}
+		if changed {
+			diff.Post[addr] = post
+		}
+	}
+	sort.Slice(diff.Deleted, func(i, j int) bool {
+		return bytes.Compare(diff.Deleted[i][:], diff.Deleted[j][:]) < 0
+	})
+	return diff
+}
+
+// GetResult returns the json encoded state diff.
+func (t *StateDiffTracer) GetResult() (json.RawMessage, error) {
+	return json.Marshal(t.StateDiff())
+}
@@ -163,6 +163,82 @@ func TestPrestateTracerCreate2(t *testing.T) {
 	}
 }
 
+func TestStateDiffTracer(t *testing.T) {
+	celoMock := testutil.NewCeloMock()
+
+	unsignedTx := types.NewTransaction(1, common.HexToAddress("0x00000000000000000000000000000000deadbeef"), new(big.Int), 5000000, big.NewInt(1), nil, nil, nil, []byte{})
+
+	privateKeyECDSA, err := ecdsa.GenerateKey(crypto.S256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	signer := types.NewEIP155Signer(big.NewInt(1))
+	tx, err := types.SignTx(unsignedTx, signer, privateKeyECDSA)
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	origin, _ := signer.Sender(tx)
+	context := vm.Context{
+		CanTransfer:          vmcontext.CanTransfer,
+		Transfer:             vmcontext.TobinTransfer,
+		Origin:               origin,
+		Coinbase:             common.Address{},
+		BlockNumber:          new(big.Int).SetUint64(8000000),
+		Time:                 new(big.Int).SetUint64(5),
+		GasPrice:             big.NewInt(1),
+		GetRegisteredAddress: vmcontext.GetRegisteredAddress,
+	}
+	alloc := core.GenesisAlloc{}
+	alloc[common.HexToAddress("0x00000000000000000000000000000000deadbeef")] = core.GenesisAccount{
+		Nonce:   1,
+		Code:    hexutil.MustDecode("0x63deadbeef60005263cafebabe6004601c6000F560005260206000F3"),
+		Balance: big.NewInt(1),
+	}
+	alloc[origin] = core.GenesisAccount{
+		Nonce:   1,
+		Code:    []byte{},
+		Balance: big.NewInt(500000000000000),
+	}
+	_, statedb := tests.MakePreState(rawdb.NewMemoryDatabase(), alloc, false)
+
+	// Create the tracer and run the EVM against the state it wraps
+	tracer := NewStateDiffTracer(statedb)
+	evm := vm.NewEVM(context, tracer, params.MainnetChainConfig, vm.Config{})
+
+	msg, err := tx.AsMessage(signer)
+	if err != nil {
+		t.Fatalf("failed to prepare transaction for tracing: %v", err)
+	}
+	st := core.NewStateTransition(evm, msg, new(core.GasPool).AddGas(tx.Gas()), celoMock.Runner)
+	if _, err = st.TransitionDb(); err != nil {
+		t.Fatalf("failed to execute transaction: %v", err)
+	}
+	diff := tracer.StateDiff()
+
+	// The init code is invalid, so the contract is never created
+	created := common.HexToAddress("0x60f3f640a8508fc6a86d45df051962668e1e8ac7")
+	if _, has := diff.Pre[created]; has {
+		t.Fatalf("Expected %x to be missing from the prestate", created)
+	}
+	if _, has := diff.Post[created]; has {
+		t.Fatalf("Expected %x to be missing from the poststate", created)
+	}
+	creator := common.HexToAddress("0x00000000000000000000000000000000deadbeef")
+	if post := diff.Post[creator]; post == nil || post.Nonce == nil || uint64(*post.Nonce) != 2 || post.Balance != nil || post.Code != nil {
+		t.Fatalf("Expected only the nonce of the creator to change, have %+v", post)
+	}
+	pre, post := diff.Pre[origin], diff.Post[origin]
+	if pre == nil || post == nil {
+		t.Fatalf("Expected sender %x in the prestate and poststate", origin)
+	}
+	if uint64(*pre.Nonce) != 1 || post.Nonce == nil || uint64(*post.Nonce) != 2 {
+		t.Fatalf("Expected sender nonce to change from 1 to 2, have %v and %v", pre.Nonce, post.Nonce)
+	}
+	if post.Balance == nil || post.Balance.ToInt().Cmp(pre.Balance.ToInt()) >= 0 {
+		t.Fatalf("Expected sender to pay for gas")
+	}
+}
+
 // TODO(kevjue/asaj): Figure out how to get the tracer tests to work with the new txn structure
 // Iterates over all the input-output datasets in the tracer test harness and
 // runs the JavaScript tracers against them.
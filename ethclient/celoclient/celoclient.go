@@ -0,0 +1,279 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package celoclient provides a client for the Celo specific RPC API, complementing
+// the ethclient package.
+package celoclient
+
+import (
+	"context"
+	"math/big"
+
+	ethereum "github.com/celo-org/celo-blockchain"
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/common/hexutil"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/params"
+	"github.com/celo-org/celo-blockchain/rpc"
+)
+
+// Client is a wrapper around rpc.Client that implements Celo specific functionality.
+type Client struct {
+	c *rpc.Client
+}
+
+// New creates a client that uses the given RPC client.
+func New(c *rpc.Client) *Client {
+	return &Client{c}
+}
+
+// ChainConfig returns the chain configuration of the network, including the
+// chain ID, the fork blocks and the Istanbul parameters.
+func (c *Client) ChainConfig(ctx context.Context) (*params.ChainConfig, error) {
+	var config *params.ChainConfig
+	if err := c.c.CallContext(ctx, &config, "eth_chainConfig"); err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return nil, ethereum.NotFound
+	}
+	return config, nil
+}
+
+// FeeEstimate is the expected fee of a transaction in its fee currency.
+type FeeEstimate struct {
+	FeeCurrency             *common.Address `json:"feeCurrency"` // Nil for CELO
+	Gas                     hexutil.Uint64  `json:"gas"`
+	IntrinsicFeeCurrencyGas hexutil.Uint64  `json:"intrinsicFeeCurrencyGas"` // Part of Gas charged for paying in a non-native currency
+	GasPrice                *hexutil.Big    `json:"gasPrice"`
+	GatewayFee              *hexutil.Big    `json:"gatewayFee"`
+	Fee                     *hexutil.Big    `json:"fee"`       // Total fee in the fee currency
+	FeeInCelo               *hexutil.Big    `json:"feeInCelo"` // Total fee converted to CELO at the current rate
+}
+
+// EstimateFee returns the full expected fee of the given transaction in its fee
+// currency, using the gas price minimum of the currency if no gas price is set.
+func (c *Client) EstimateFee(ctx context.Context, msg ethereum.CallMsg) (*FeeEstimate, error) {
+	var estimate *FeeEstimate
+	if err := c.c.CallContext(ctx, &estimate, "celo_estimateFee", toCallArg(msg)); err != nil {
+		return nil, err
+	}
+	if estimate == nil {
+		return nil, ethereum.NotFound
+	}
+	return estimate, nil
+}
+
+// BuildTransaction returns an unsigned transaction from the given message, filling
+// the nonce, gas price and gas limit in when not set. The nonce is taken from the
+// pending state, and the gas price is suggested in the fee currency of the message.
+func (c *Client) BuildTransaction(ctx context.Context, msg ethereum.CallMsg) (*types.Transaction, error) {
+	var nonce hexutil.Uint64
+	if err := c.c.CallContext(ctx, &nonce, "eth_getTransactionCount", msg.From, "pending"); err != nil {
+		return nil, err
+	}
+	if msg.GasPrice == nil {
+		var gasPrice hexutil.Big
+		if err := c.c.CallContext(ctx, &gasPrice, "eth_gasPrice", msg.FeeCurrency); err != nil {
+			return nil, err
+		}
+		msg.GasPrice = (*big.Int)(&gasPrice)
+	}
+	if msg.Gas == 0 {
+		var gas hexutil.Uint64
+		if err := c.c.CallContext(ctx, &gas, "eth_estimateGas", toCallArg(msg)); err != nil {
+			return nil, err
+		}
+		msg.Gas = uint64(gas)
+	}
+	value := msg.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+	switch {
+	case msg.EthCompatible && msg.To == nil:
+		return types.NewContractCreationEthCompatible(uint64(nonce), value, msg.Gas, msg.GasPrice, msg.Data), nil
+	case msg.EthCompatible:
+		return types.NewTransactionEthCompatible(uint64(nonce), *msg.To, value, msg.Gas, msg.GasPrice, msg.Data), nil
+	case msg.To == nil:
+		return types.NewContractCreation(uint64(nonce), value, msg.Gas, msg.GasPrice, msg.FeeCurrency, msg.GatewayFeeRecipient, msg.GatewayFee, msg.Data), nil
+	default:
+		return types.NewTransaction(uint64(nonce), *msg.To, value, msg.Gas, msg.GasPrice, msg.FeeCurrency, msg.GatewayFeeRecipient, msg.GatewayFee, msg.Data), nil
+	}
+}
+
+// EpochRewardPayment is an amount paid to a single recipient.
+type EpochRewardPayment struct {
+	Recipient common.Address `json:"recipient"`
+	Amount    *hexutil.Big   `json:"amount"`
+}
+
+// EpochRewardsRate is an exchange rate, where Numerator stable token units are
+// worth Denominator CELO units.
+type EpochRewardsRate struct {
+	Numerator   *hexutil.Big `json:"numerator"`
+	Denominator *hexutil.Big `json:"denominator"`
+}
+
+// EpochRewards are the rewards distributed at the end of an epoch. Validator
+// payments are denominated in the stable token, everything else in CELO.
+type EpochRewards struct {
+	Epoch       hexutil.Uint64 `json:"epoch"`
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	BlockHash   common.Hash    `json:"blockHash"`
+	Frozen      bool           `json:"frozen"` // Distribution was frozen, nothing was paid
+
+	TargetValidatorReward        *hexutil.Big          `json:"targetValidatorReward"`
+	ValidatorPayments            []*EpochRewardPayment `json:"validatorPayments"`
+	TotalValidatorPayments       *hexutil.Big          `json:"totalValidatorPayments"`
+	TotalValidatorPaymentsInCelo *hexutil.Big          `json:"totalValidatorPaymentsInCelo"`
+	StableToken                  common.Address        `json:"stableToken"`
+	ExchangeRate                 *EpochRewardsRate     `json:"exchangeRate"`
+
+	TargetVoterRewards *hexutil.Big `json:"targetVoterRewards"`
+	VoterRewards       *hexutil.Big `json:"voterRewards"`
+
+	CommunityFund    *EpochRewardPayment `json:"communityFund"`
+	CarbonOffsetting *EpochRewardPayment `json:"carbonOffsetting"`
+}
+
+// EpochRewards returns the rewards distributed at the end of the given epoch.
+// The node needs the state of the block preceding the last block of the epoch.
+func (c *Client) EpochRewards(ctx context.Context, epoch uint64) (*EpochRewards, error) {
+	var rewards *EpochRewards
+	if err := c.c.CallContext(ctx, &rewards, "celo_getEpochRewards", hexutil.Uint64(epoch)); err != nil {
+		return nil, err
+	}
+	if rewards == nil {
+		return nil, ethereum.NotFound
+	}
+	return rewards, nil
+}
+
+// BlockRandomness is the randomness revealed and committed to in a block, along
+// with the randomness of the Random contract after it.
+type BlockRandomness struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	BlockHash   common.Hash    `json:"blockHash"`
+	Proposer    common.Address `json:"proposer"`
+	Revealed    common.Hash    `json:"revealed"`
+	Committed   common.Hash    `json:"committed"`
+	Randomness  common.Hash    `json:"randomness"`
+}
+
+// Randomness returns the randomness of the given block. If number is nil, the
+// latest known block is used.
+func (c *Client) Randomness(ctx context.Context, number *big.Int) (*BlockRandomness, error) {
+	var randomness *BlockRandomness
+	if err := c.c.CallContext(ctx, &randomness, "celo_getRandomness", toBlockNumArg(number)); err != nil {
+		return nil, err
+	}
+	if randomness == nil {
+		return nil, ethereum.NotFound
+	}
+	return randomness, nil
+}
+
+// SubscribeRandomness subscribes to the randomness of the blocks appended to the
+// canonical chain.
+func (c *Client) SubscribeRandomness(ctx context.Context, ch chan<- *BlockRandomness) (ethereum.Subscription, error) {
+	return c.c.Subscribe(ctx, "celo", ch, "randomness")
+}
+
+// Kinds of changes to the transaction pool contents, as reported by
+// SubscribeTxPoolChanges.
+const (
+	TxPoolAdd     = "add"
+	TxPoolReplace = "replace"
+	TxPoolRemove  = "remove"
+)
+
+// TxPoolChange is a change to the transaction pool contents.
+type TxPoolChange struct {
+	Kind     string       `json:"kind"`
+	Hash     common.Hash  `json:"hash"`     // Transaction added, removed or the replacement
+	Replaced *common.Hash `json:"replaced"` // Transaction replaced, for replacements
+	Reason   string       `json:"reason"`   // Reason of the removal, for removals
+}
+
+// SubscribeTxPoolChanges subscribes to the transactions added to, replaced in
+// and removed from the transaction pool of the node.
+func (c *Client) SubscribeTxPoolChanges(ctx context.Context, ch chan<- *TxPoolChange) (ethereum.Subscription, error) {
+	return c.c.EthSubscribe(ctx, ch, "txpool")
+}
+
+// PendingHardfork is a hard fork scheduled by an approved governance proposal,
+// which is not activated yet.
+type PendingHardfork struct {
+	ProposalID      *hexutil.Big `json:"proposalId"`
+	Stage           string       `json:"stage"`
+	DescriptionURL  string       `json:"descriptionUrl"`
+	Name            string       `json:"name"`
+	Block           *hexutil.Big `json:"block"`           // Activation block proposed
+	Known           bool         `json:"known"`           // Whether the node implements the fork
+	ConfiguredBlock *hexutil.Big `json:"configuredBlock"` // Activation block configured in the node, nil if none
+}
+
+// PendingHardforks returns the hard forks scheduled by approved governance
+// proposals which are not activated yet, as last looked up by the node.
+func (c *Client) PendingHardforks(ctx context.Context) ([]*PendingHardfork, error) {
+	var pending []*PendingHardfork
+	err := c.c.CallContext(ctx, &pending, "celo_pendingHardforks")
+	return pending, err
+}
+
+func toBlockNumArg(number *big.Int) string {
+	if number == nil {
+		return "latest"
+	}
+	pending := big.NewInt(-1)
+	if number.Cmp(pending) == 0 {
+		return "pending"
+	}
+	return hexutil.EncodeBig(number)
+}
+
+func toCallArg(msg ethereum.CallMsg) interface{} {
+	arg := map[string]interface{}{
+		"from": msg.From,
+		"to":   msg.To,
+	}
+	if len(msg.Data) > 0 {
+		arg["data"] = hexutil.Bytes(msg.Data)
+	}
+	if msg.Value != nil {
+		arg["value"] = (*hexutil.Big)(msg.Value)
+	}
+	if msg.Gas != 0 {
+		arg["gas"] = hexutil.Uint64(msg.Gas)
+	}
+	if msg.GasPrice != nil {
+		arg["gasPrice"] = (*hexutil.Big)(msg.GasPrice)
+	}
+	if msg.FeeCurrency != nil {
+		arg["feeCurrency"] = msg.FeeCurrency
+	}
+	if msg.GatewayFeeRecipient != nil {
+		arg["gatewayFeeRecipient"] = msg.GatewayFeeRecipient
+	}
+	if msg.GatewayFee != nil {
+		arg["gatewayFee"] = (*hexutil.Big)(msg.GatewayFee)
+	}
+	if msg.EthCompatible {
+		arg["ethCompatible"] = true
+	}
+	return arg
+}
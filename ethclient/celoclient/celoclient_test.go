@@ -0,0 +1,258 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package celoclient
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	ethereum "github.com/celo-org/celo-blockchain"
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/common/hexutil"
+	"github.com/celo-org/celo-blockchain/params"
+	"github.com/celo-org/celo-blockchain/rpc"
+	. "github.com/onsi/gomega"
+)
+
+var (
+	testSender      = common.HexToAddress("0x01")
+	testRecipient   = common.HexToAddress("0x02")
+	testFeeCurrency = common.HexToAddress("0x03")
+)
+
+// testCallArgs mirrors the call arguments decoded by the node.
+type testCallArgs struct {
+	From                *common.Address `json:"from"`
+	To                  *common.Address `json:"to"`
+	Gas                 *hexutil.Uint64 `json:"gas"`
+	GasPrice            *hexutil.Big    `json:"gasPrice"`
+	FeeCurrency         *common.Address `json:"feeCurrency"`
+	GatewayFeeRecipient *common.Address `json:"gatewayFeeRecipient"`
+	GatewayFee          *hexutil.Big    `json:"gatewayFee"`
+	Value               *hexutil.Big    `json:"value"`
+	Data                *hexutil.Bytes  `json:"data"`
+}
+
+type testEthService struct{}
+
+func (s *testEthService) GetTransactionCount(address common.Address, blockNr string) hexutil.Uint64 {
+	return 7
+}
+
+func (s *testEthService) GasPrice(feeCurrency *common.Address) *hexutil.Big {
+	if feeCurrency != nil {
+		return (*hexutil.Big)(big.NewInt(2000))
+	}
+	return (*hexutil.Big)(big.NewInt(1000))
+}
+
+func (s *testEthService) EstimateGas(args testCallArgs) hexutil.Uint64 {
+	if args.FeeCurrency != nil {
+		return 71000
+	}
+	return 21000
+}
+
+func (s *testEthService) ChainConfig() *params.ChainConfig {
+	return params.IstanbulTestChainConfig
+}
+
+func (s *testEthService) Txpool(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, _ := rpc.NotifierFromContext(ctx)
+	sub := notifier.CreateSubscription()
+	go func() {
+		replaced := common.HexToHash("0x0a")
+		notifier.Notify(sub.ID, &TxPoolChange{Kind: TxPoolReplace, Hash: common.HexToHash("0x0b"), Replaced: &replaced})
+	}()
+	return sub, nil
+}
+
+type testCeloService struct{}
+
+func (s *testCeloService) EstimateFee(args testCallArgs) *FeeEstimate {
+	return &FeeEstimate{
+		FeeCurrency: args.FeeCurrency,
+		Gas:         71000,
+		GasPrice:    (*hexutil.Big)(big.NewInt(2000)),
+		GatewayFee:  args.GatewayFee,
+		Fee:         (*hexutil.Big)(big.NewInt(71000*2000 + 10)),
+	}
+}
+
+func (s *testCeloService) GetEpochRewards(epoch hexutil.Uint64) *EpochRewards {
+	if epoch == 0 {
+		return nil
+	}
+	return &EpochRewards{Epoch: epoch, VoterRewards: (*hexutil.Big)(big.NewInt(100))}
+}
+
+func (s *testCeloService) GetRandomness(number *rpc.BlockNumber) *BlockRandomness {
+	return &BlockRandomness{BlockNumber: hexutil.Uint64(*number), Randomness: common.HexToHash("0xff")}
+}
+
+func (s *testCeloService) Randomness(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, _ := rpc.NotifierFromContext(ctx)
+	sub := notifier.CreateSubscription()
+	go func() {
+		notifier.Notify(sub.ID, &BlockRandomness{BlockNumber: 42})
+	}()
+	return sub, nil
+}
+
+func (s *testCeloService) PendingHardforks() []*PendingHardfork {
+	return []*PendingHardfork{{Name: "e", Block: (*hexutil.Big)(big.NewInt(1000)), Known: true}}
+}
+
+func newTestClient(t *testing.T) *Client {
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", new(testEthService)); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.RegisterName("celo", new(testCeloService)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Stop)
+	return New(rpc.DialInProc(server))
+}
+
+func TestChainConfig(t *testing.T) {
+	g := NewGomegaWithT(t)
+	client := newTestClient(t)
+
+	config, err := client.ChainConfig(context.Background())
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(config.ChainID).To(Equal(params.IstanbulTestChainConfig.ChainID))
+	g.Expect(config.DonutBlock).To(BeNil())
+	g.Expect(config.Istanbul).To(Equal(params.IstanbulTestChainConfig.Istanbul))
+}
+
+func TestEstimateFee(t *testing.T) {
+	g := NewGomegaWithT(t)
+	client := newTestClient(t)
+
+	estimate, err := client.EstimateFee(context.Background(), ethereum.CallMsg{
+		From:        testSender,
+		To:          &testRecipient,
+		FeeCurrency: &testFeeCurrency,
+		GatewayFee:  big.NewInt(10),
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(*estimate.FeeCurrency).To(Equal(testFeeCurrency))
+	g.Expect(estimate.GatewayFee.ToInt()).To(Equal(big.NewInt(10)))
+	g.Expect(estimate.Fee.ToInt()).To(Equal(big.NewInt(71000*2000 + 10)))
+}
+
+func TestBuildTransaction(t *testing.T) {
+	g := NewGomegaWithT(t)
+	client := newTestClient(t)
+
+	tx, err := client.BuildTransaction(context.Background(), ethereum.CallMsg{
+		From:                testSender,
+		To:                  &testRecipient,
+		Value:               big.NewInt(5),
+		FeeCurrency:         &testFeeCurrency,
+		GatewayFeeRecipient: &testSender,
+		GatewayFee:          big.NewInt(10),
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(tx.Nonce()).To(BeEquivalentTo(7))
+	g.Expect(tx.GasPrice()).To(Equal(big.NewInt(2000)))
+	g.Expect(tx.Gas()).To(BeEquivalentTo(71000))
+	g.Expect(*tx.To()).To(Equal(testRecipient))
+	g.Expect(tx.Value()).To(Equal(big.NewInt(5)))
+	g.Expect(*tx.FeeCurrency()).To(Equal(testFeeCurrency))
+	g.Expect(*tx.GatewayFeeRecipient()).To(Equal(testSender))
+	g.Expect(tx.GatewayFee()).To(Equal(big.NewInt(10)))
+
+	// Fields set in the message are kept
+	tx, err = client.BuildTransaction(context.Background(), ethereum.CallMsg{
+		From:          testSender,
+		Gas:           100000,
+		GasPrice:      big.NewInt(3000),
+		EthCompatible: true,
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(tx.GasPrice()).To(Equal(big.NewInt(3000)))
+	g.Expect(tx.Gas()).To(BeEquivalentTo(100000))
+	g.Expect(tx.To()).To(BeNil())
+	g.Expect(tx.EthCompatible()).To(BeTrue())
+}
+
+func TestEpochRewards(t *testing.T) {
+	g := NewGomegaWithT(t)
+	client := newTestClient(t)
+
+	rewards, err := client.EpochRewards(context.Background(), 3)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(rewards.Epoch).To(BeEquivalentTo(3))
+	g.Expect(rewards.VoterRewards.ToInt()).To(Equal(big.NewInt(100)))
+
+	_, err = client.EpochRewards(context.Background(), 0)
+	g.Expect(err).To(Equal(ethereum.NotFound))
+}
+
+func TestRandomness(t *testing.T) {
+	g := NewGomegaWithT(t)
+	client := newTestClient(t)
+
+	randomness, err := client.Randomness(context.Background(), big.NewInt(12))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(randomness.BlockNumber).To(BeEquivalentTo(12))
+	g.Expect(randomness.Randomness).To(Equal(common.HexToHash("0xff")))
+}
+
+func TestPendingHardforks(t *testing.T) {
+	g := NewGomegaWithT(t)
+	client := newTestClient(t)
+
+	pending, err := client.PendingHardforks(context.Background())
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(pending).To(HaveLen(1))
+	g.Expect(pending[0].Name).To(Equal("e"))
+	g.Expect(pending[0].Block.ToInt()).To(Equal(big.NewInt(1000)))
+	g.Expect(pending[0].ConfiguredBlock).To(BeNil())
+}
+
+func TestSubscriptions(t *testing.T) {
+	g := NewGomegaWithT(t)
+	client := newTestClient(t)
+
+	randomnessCh := make(chan *BlockRandomness)
+	sub, err := client.SubscribeRandomness(context.Background(), randomnessCh)
+	g.Expect(err).ToNot(HaveOccurred())
+	defer sub.Unsubscribe()
+	select {
+	case randomness := <-randomnessCh:
+		g.Expect(randomness.BlockNumber).To(BeEquivalentTo(42))
+	case <-time.After(time.Second):
+		t.Fatal("randomness not received")
+	}
+
+	changeCh := make(chan *TxPoolChange)
+	sub, err = client.SubscribeTxPoolChanges(context.Background(), changeCh)
+	g.Expect(err).ToNot(HaveOccurred())
+	defer sub.Unsubscribe()
+	select {
+	case change := <-changeCh:
+		g.Expect(change.Kind).To(Equal(TxPoolReplace))
+		g.Expect(*change.Replaced).To(Equal(common.HexToHash("0x0a")))
+	case <-time.After(time.Second):
+		t.Fatal("transaction pool change not received")
+	}
+}
@@ -605,5 +605,17 @@ func toCallArg(msg ethereum.CallMsg) interface{} {
 	if msg.GasPrice != nil {
 		arg["gasPrice"] = (*hexutil.Big)(msg.GasPrice)
 	}
+	if msg.FeeCurrency != nil {
+		arg["feeCurrency"] = msg.FeeCurrency
+	}
+	if msg.GatewayFeeRecipient != nil {
+		arg["gatewayFeeRecipient"] = msg.GatewayFeeRecipient
+	}
+	if msg.GatewayFee != nil {
+		arg["gatewayFee"] = (*hexutil.Big)(msg.GatewayFee)
+	}
+	if msg.EthCompatible {
+		arg["ethCompatible"] = true
+	}
 	return arg
 }
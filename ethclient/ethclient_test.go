@@ -27,6 +27,7 @@ import (
 
 	ethereum "github.com/celo-org/celo-blockchain"
 	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/common/hexutil"
 	mockEngine "github.com/celo-org/celo-blockchain/consensus/consensustest"
 	"github.com/celo-org/celo-blockchain/core"
 	"github.com/celo-org/celo-blockchain/core/rawdb"
@@ -178,6 +179,69 @@ func TestToFilterArg(t *testing.T) {
 	}
 }
 
+func TestToCallArg(t *testing.T) {
+	to := common.HexToAddress("0xD36722ADeC3EdCB29c8e7b5a47f352D701393462")
+	feeCurrency := common.HexToAddress("0x765DE816845861e75A25fCA122bb6898B8B1282a")
+	gatewayFeeRecipient := common.HexToAddress("0x01")
+
+	for _, testCase := range []struct {
+		name   string
+		input  ethereum.CallMsg
+		output interface{}
+	}{
+		{
+			"native currency",
+			ethereum.CallMsg{
+				To:    &to,
+				Value: big.NewInt(1),
+			},
+			map[string]interface{}{
+				"from":  common.Address{},
+				"to":    &to,
+				"value": (*hexutil.Big)(big.NewInt(1)),
+			},
+		},
+		{
+			"with fee currency and gateway fee",
+			ethereum.CallMsg{
+				To:                  &to,
+				Gas:                 21000,
+				GasPrice:            big.NewInt(2),
+				FeeCurrency:         &feeCurrency,
+				GatewayFeeRecipient: &gatewayFeeRecipient,
+				GatewayFee:          big.NewInt(3),
+			},
+			map[string]interface{}{
+				"from":                common.Address{},
+				"to":                  &to,
+				"gas":                 hexutil.Uint64(21000),
+				"gasPrice":            (*hexutil.Big)(big.NewInt(2)),
+				"feeCurrency":         &feeCurrency,
+				"gatewayFeeRecipient": &gatewayFeeRecipient,
+				"gatewayFee":          (*hexutil.Big)(big.NewInt(3)),
+			},
+		},
+		{
+			"eth compatible",
+			ethereum.CallMsg{
+				To:            &to,
+				EthCompatible: true,
+			},
+			map[string]interface{}{
+				"from":          common.Address{},
+				"to":            &to,
+				"ethCompatible": true,
+			},
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			if output := toCallArg(testCase.input); !reflect.DeepEqual(testCase.output, output) {
+				t.Fatalf("expected call arg %v but got %v", testCase.output, output)
+			}
+		})
+	}
+}
+
 var (
 	testKey, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
 	testAddr    = crypto.PubkeyToAddress(testKey.PublicKey)
@@ -353,3 +417,21 @@ func TestChainID(t *testing.T) {
 		t.Fatalf("ChainID returned wrong number: %+v", id)
 	}
 }
+
+func TestChainConfig(t *testing.T) {
+	backend, _ := newTestBackend(t)
+	client, _ := backend.Attach()
+	defer backend.Close()
+	defer client.Close()
+
+	var config *params.ChainConfig
+	if err := client.CallContext(context.Background(), &config, "eth_chainConfig"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config == nil || config.ChainID.Cmp(params.TestChainConfig.ChainID) != 0 {
+		t.Fatalf("chain config has wrong chain ID: %+v", config)
+	}
+	if config.Istanbul == nil || config.Istanbul.Epoch != params.TestChainConfig.Istanbul.Epoch {
+		t.Fatalf("chain config has wrong istanbul parameters: %+v", config.Istanbul)
+	}
+}
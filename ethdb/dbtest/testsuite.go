@@ -313,6 +313,51 @@ func TestDatabaseSuite(t *testing.T, New func() ethdb.KeyValueStore) {
 		}
 	})
 
+	t.Run("Snapshot", func(t *testing.T) {
+		db := New()
+		defer db.Close()
+
+		initial := map[string]string{"k1": "v1", "k2": "v2", "k3": "v3"}
+		for k, v := range initial {
+			if err := db.Put([]byte(k), []byte(v)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		snapshot, err := db.NewSnapshot()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer snapshot.Release()
+
+		// Mutations after the snapshot must not be visible through it
+		if err := db.Put([]byte("k1"), []byte("v1-new")); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Delete([]byte("k2")); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Put([]byte("k4"), []byte("v4")); err != nil {
+			t.Fatal(err)
+		}
+		for k, v := range initial {
+			got, err := snapshot.Get([]byte(k))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, []byte(v)) {
+				t.Fatalf("value mismatch for %s: got %s, want %s", k, got, v)
+			}
+		}
+		if has, err := snapshot.Has([]byte("k4")); err != nil || has {
+			t.Fatalf("key added after snapshot visible: %v, %v", has, err)
+		}
+		if got, want := iterateKeys(snapshot.NewIterator(nil, nil)), []string{"k1", "k2", "k3"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got: %s; want: %s", got, want)
+		}
+		if got, want := iterateKeys(snapshot.NewIterator([]byte("k"), []byte("2"))), []string{"k2", "k3"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got: %s; want: %s", got, want)
+		}
+	})
 }
 
 func iterateKeys(it ethdb.Iterator) []string {
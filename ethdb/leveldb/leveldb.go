@@ -75,6 +75,16 @@ type Database struct {
 	nonlevel0CompGauge metrics.Gauge // Gauge for tracking the number of table compaction in non0 level
 	seekCompGauge      metrics.Gauge // Gauge for tracking the number of table compaction caused by read opt
 
+	getTimer     metrics.Timer // Timer for measuring the latency of key lookups
+	hasTimer     metrics.Timer // Timer for measuring the latency of key existence checks
+	putTimer     metrics.Timer // Timer for measuring the latency of single key writes
+	deleteTimer  metrics.Timer // Timer for measuring the latency of single key deletions
+	batchTimer   metrics.Timer // Timer for measuring the latency of batch commits
+	compactTimer metrics.Timer // Timer for measuring the latency of manual range compactions
+
+	namespace string   // Metrics namespace used when lazily registering per-table counters
+	tables    sync.Map // Per-table (first key byte) access counters, lazily populated
+
 	quitLock sync.Mutex      // Mutex protecting the quit channel access
 	quitChan chan chan error // Quit channel to stop the metrics collection before closing the database
 
@@ -110,10 +120,11 @@ func New(file string, cache int, handles int, namespace string) (*Database, erro
 	}
 	// Assemble the wrapper with all the registered metrics
 	ldb := &Database{
-		fn:       file,
-		db:       db,
-		log:      logger,
-		quitChan: make(chan chan error),
+		fn:        file,
+		db:        db,
+		log:       logger,
+		quitChan:  make(chan chan error),
+		namespace: namespace,
 	}
 	ldb.compTimeMeter = metrics.NewRegisteredMeter(namespace+"compact/time", nil)
 	ldb.compReadMeter = metrics.NewRegisteredMeter(namespace+"compact/input", nil)
@@ -127,6 +138,12 @@ func New(file string, cache int, handles int, namespace string) (*Database, erro
 	ldb.level0CompGauge = metrics.NewRegisteredGauge(namespace+"compact/level0", nil)
 	ldb.nonlevel0CompGauge = metrics.NewRegisteredGauge(namespace+"compact/nonlevel0", nil)
 	ldb.seekCompGauge = metrics.NewRegisteredGauge(namespace+"compact/seek", nil)
+	ldb.getTimer = metrics.NewRegisteredTimer(namespace+"op/get", nil)
+	ldb.hasTimer = metrics.NewRegisteredTimer(namespace+"op/has", nil)
+	ldb.putTimer = metrics.NewRegisteredTimer(namespace+"op/put", nil)
+	ldb.deleteTimer = metrics.NewRegisteredTimer(namespace+"op/delete", nil)
+	ldb.batchTimer = metrics.NewRegisteredTimer(namespace+"op/batch", nil)
+	ldb.compactTimer = metrics.NewRegisteredTimer(namespace+"op/compact", nil)
 
 	// Start up the metrics gathering and return
 	go ldb.meter(metricsGatheringInterval)
@@ -152,11 +169,15 @@ func (db *Database) Close() error {
 
 // Has retrieves if a key is present in the key-value store.
 func (db *Database) Has(key []byte) (bool, error) {
+	defer updateTimer(db.hasTimer, time.Now())
+	db.markRead(key)
 	return db.db.Has(key, nil)
 }
 
 // Get retrieves the given key if it's present in the key-value store.
 func (db *Database) Get(key []byte) ([]byte, error) {
+	defer updateTimer(db.getTimer, time.Now())
+	db.markRead(key)
 	dat, err := db.db.Get(key, nil)
 	if err != nil {
 		return nil, err
@@ -166,11 +187,15 @@ func (db *Database) Get(key []byte) ([]byte, error) {
 
 // Put inserts the given value into the key-value store.
 func (db *Database) Put(key []byte, value []byte) error {
+	defer updateTimer(db.putTimer, time.Now())
+	db.markWrite(key)
 	return db.db.Put(key, value, nil)
 }
 
 // Delete removes the key from the key-value store.
 func (db *Database) Delete(key []byte) error {
+	defer updateTimer(db.deleteTimer, time.Now())
+	db.markWrite(key)
 	return db.db.Delete(key, nil)
 }
 
@@ -178,8 +203,9 @@ func (db *Database) Delete(key []byte) error {
 // database until a final write is called.
 func (db *Database) NewBatch() ethdb.Batch {
 	return &batch{
-		db: db.db,
-		b:  new(leveldb.Batch),
+		db:     db.db,
+		parent: db,
+		b:      new(leveldb.Batch),
 	}
 }
 
@@ -190,6 +216,17 @@ func (db *Database) NewIterator(prefix []byte, start []byte) ethdb.Iterator {
 	return db.db.NewIterator(bytesPrefixRange(prefix, start), nil)
 }
 
+// NewSnapshot creates a database snapshot based on the current state.
+// The created snapshot will not be affected by all following mutations
+// happened on the database.
+func (db *Database) NewSnapshot() (ethdb.Snapshot, error) {
+	snap, err := db.db.GetSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot{db: snap}, nil
+}
+
 // Stat returns a particular internal stat of the database.
 func (db *Database) Stat(property string) (string, error) {
 	return db.db.GetProperty(property)
@@ -203,6 +240,7 @@ func (db *Database) Stat(property string) (string, error) {
 // is treated as a key after all keys in the data store. If both is nil then it
 // will compact entire data store.
 func (db *Database) Compact(start []byte, limit []byte) error {
+	defer updateTimer(db.compactTimer, time.Now())
 	return db.db.CompactRange(util.Range{Start: start, Limit: limit})
 }
 
@@ -211,6 +249,63 @@ func (db *Database) Path() string {
 	return db.fn
 }
 
+// tableMeters tracks the accesses to a single database table, identified by
+// the first byte of the keys stored in it.
+type tableMeters struct {
+	reads  metrics.Meter
+	writes metrics.Meter
+}
+
+// tableLabel returns the metrics label of the table the given key belongs to.
+func tableLabel(key []byte) string {
+	if len(key) == 0 {
+		return "none"
+	}
+	if c := key[0]; (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') {
+		return string(c)
+	}
+	return fmt.Sprintf("0x%02x", key[0])
+}
+
+// table retrieves the access meters of the table the given key belongs to,
+// registering them on first use.
+func (db *Database) table(key []byte) *tableMeters {
+	var prefix byte
+	if len(key) > 0 {
+		prefix = key[0]
+	}
+	if meters, ok := db.tables.Load(prefix); ok {
+		return meters.(*tableMeters)
+	}
+	label := tableLabel(key)
+	meters, _ := db.tables.LoadOrStore(prefix, &tableMeters{
+		reads:  metrics.GetOrRegisterMeter(db.namespace+"table/"+label+"/read", nil),
+		writes: metrics.GetOrRegisterMeter(db.namespace+"table/"+label+"/write", nil),
+	})
+	return meters.(*tableMeters)
+}
+
+// updateTimer records the time elapsed since start, if the timer is set.
+func updateTimer(timer metrics.Timer, start time.Time) {
+	if timer != nil {
+		timer.UpdateSince(start)
+	}
+}
+
+// markRead counts a read access to the table of the given key.
+func (db *Database) markRead(key []byte) {
+	if metrics.Enabled {
+		db.table(key).reads.Mark(1)
+	}
+}
+
+// markWrite counts a write access to the table of the given key.
+func (db *Database) markWrite(key []byte) {
+	if metrics.Enabled {
+		db.table(key).writes.Mark(1)
+	}
+}
+
 // meter periodically retrieves internal leveldb counters and reports them to
 // the metrics subsystem.
 //
@@ -417,13 +512,15 @@ func (db *Database) meter(refresh time.Duration) {
 // batch is a write-only leveldb batch that commits changes to its host database
 // when Write is called. A batch cannot be used concurrently.
 type batch struct {
-	db   *leveldb.DB
-	b    *leveldb.Batch
-	size int
+	db     *leveldb.DB
+	parent *Database
+	b      *leveldb.Batch
+	size   int
 }
 
 // Put inserts the given value into the batch for later committing.
 func (b *batch) Put(key, value []byte) error {
+	b.parent.markWrite(key)
 	b.b.Put(key, value)
 	b.size += len(value)
 	return nil
@@ -431,6 +528,7 @@ func (b *batch) Put(key, value []byte) error {
 
 // Delete inserts the a key removal into the batch for later committing.
 func (b *batch) Delete(key []byte) error {
+	b.parent.markWrite(key)
 	b.b.Delete(key)
 	b.size += len(key)
 	return nil
@@ -443,6 +541,7 @@ func (b *batch) ValueSize() int {
 
 // Write flushes any accumulated data to disk.
 func (b *batch) Write() error {
+	defer updateTimer(b.parent.batchTimer, time.Now())
 	return b.db.Write(b.b, nil)
 }
 
@@ -481,6 +580,36 @@ func (r *replayer) Delete(key []byte) {
 	r.failure = r.writer.Delete(key)
 }
 
+// snapshot wraps a leveldb snapshot for implementing the Snapshot interface.
+type snapshot struct {
+	db *leveldb.Snapshot
+}
+
+// Has retrieves if a key is present in the snapshot backing by a key-value
+// data store.
+func (snap *snapshot) Has(key []byte) (bool, error) {
+	return snap.db.Has(key, nil)
+}
+
+// Get retrieves the given key if it's present in the snapshot backing by
+// key-value data store.
+func (snap *snapshot) Get(key []byte) ([]byte, error) {
+	return snap.db.Get(key, nil)
+}
+
+// NewIterator creates a binary-alphabetical iterator over a subset of the
+// snapshot content with a particular key prefix, starting at a particular
+// initial key (or after, if it does not exist).
+func (snap *snapshot) NewIterator(prefix []byte, start []byte) ethdb.Iterator {
+	return snap.db.NewIterator(bytesPrefixRange(prefix, start), nil)
+}
+
+// Release releases associated resources. Release should always succeed and can
+// be called multiple times without causing error.
+func (snap *snapshot) Release() {
+	snap.db.Release()
+}
+
 // bytesPrefixRange returns key range that satisfy
 // - the given prefix, and
 // - the given seek position
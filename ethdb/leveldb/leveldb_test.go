@@ -38,3 +38,21 @@ func TestLevelDB(t *testing.T) {
 		})
 	})
 }
+
+func TestTableLabel(t *testing.T) {
+	tests := []struct {
+		key   []byte
+		label string
+	}{
+		{nil, "none"},
+		{[]byte("h\x00\x01"), "h"},
+		{[]byte("LastBlock"), "L"},
+		{[]byte{0x00, 0x01}, "0x00"},
+		{[]byte{0xff}, "0xff"},
+	}
+	for _, tt := range tests {
+		if have := tableLabel(tt.key); have != tt.label {
+			t.Errorf("key %x: label mismatch: have %s, want %s", tt.key, have, tt.label)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package objectstore implements remote storage of immutable ancient chain
+// segments on S3-compatible object stores.
+package objectstore
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+)
+
+// errInvalidURL is returned if the object store location cannot be parsed.
+var errInvalidURL = errors.New("invalid object store url, expected s3://bucket[/prefix][?endpoint=...&region=...&pathstyle=true]")
+
+// S3Store stores named blobs in a bucket of an S3-compatible object store. The
+// credentials are resolved through the standard AWS credential chain (e.g. the
+// AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY environment variables).
+type S3Store struct {
+	bucket string
+	prefix string
+
+	client     *s3.S3
+	uploader   *s3manager.Uploader
+	downloader *s3manager.Downloader
+}
+
+// NewS3 creates an object store client from a location formatted as
+// s3://bucket/prefix, optionally carrying the endpoint, region and pathstyle
+// query parameters for non-AWS providers (e.g. MinIO or Ceph).
+func NewS3(location string) (*S3Store, error) {
+	u, err := url.Parse(location)
+	if err != nil || u.Scheme != "s3" || u.Host == "" {
+		return nil, errInvalidURL
+	}
+	cfg := aws.NewConfig()
+	query := u.Query()
+	if region := query.Get("region"); region != "" {
+		cfg = cfg.WithRegion(region)
+	} else {
+		cfg = cfg.WithRegion("us-east-1")
+	}
+	if endpoint := query.Get("endpoint"); endpoint != "" {
+		cfg = cfg.WithEndpoint(endpoint)
+	}
+	if pathStyle := query.Get("pathstyle"); pathStyle != "" {
+		enabled, err := strconv.ParseBool(pathStyle)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pathstyle value %q: %v", pathStyle, err)
+		}
+		cfg = cfg.WithS3ForcePathStyle(enabled)
+	}
+	sess, err := session.NewSession(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &S3Store{
+		bucket:     u.Host,
+		prefix:     strings.Trim(u.Path, "/"),
+		client:     s3.New(sess),
+		uploader:   s3manager.NewUploader(sess),
+		downloader: s3manager.NewDownloader(sess),
+	}, nil
+}
+
+// key returns the object key of the named blob.
+func (s *S3Store) key(name string) string {
+	return path.Join(s.prefix, name)
+}
+
+// Has reports whether the named blob exists in the bucket.
+func (s *S3Store) Has(name string) (bool, error) {
+	_, err := s.client.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key(name)),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.RequestFailure); ok && aerr.StatusCode() == 404 {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Upload stores the local file at path in the bucket under name.
+func (s *S3Store) Upload(name string, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = s.uploader.Upload(&s3manager.UploadInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key(name)),
+		Body:   f,
+	})
+	return err
+}
+
+// Download retrieves the named blob from the bucket into a local file at path.
+func (s *S3Store) Download(name string, path string) error {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := s.downloader.Download(f, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key(name)),
+	}); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Delete removes the named blob from the bucket.
+func (s *S3Store) Delete(name string) error {
+	_, err := s.client.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key(name)),
+	})
+	return err
+}
@@ -82,6 +82,9 @@ const (
 	statusUpdateInterval = 13
 	// valSetInterval is the frequency in blocks to send the validator set
 	valSetInterval = 11
+	// statsSchemaVersion is the version of the reported node stats. Version 2
+	// adds the validator health stats, older servers ignore the new fields.
+	statsSchemaVersion = 2
 
 	actionBlock    = "block"
 	actionHello    = "hello"
@@ -104,7 +107,7 @@ type backend interface {
 	Downloader() *downloader.Downloader
 	AccountManager() *accounts.Manager
 	StateAndHeaderByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, error)
-	NewEVMRunner(header *types.Header, state vm.StateDB) vm.EVMRunner
+	NewEVMRunner(ctx context.Context, header *types.Header, state vm.StateDB) vm.EVMRunner
 }
 
 // fullNodeBackend encompasses the functionality necessary for a full node
@@ -454,6 +457,7 @@ func (s *Service) login(conn *connWrapper, sendCh chan *StatsPayload) error {
 			OsVer:    runtime.GOARCH,
 			Client:   "0.1.1",
 			History:  true,
+			Schema:   statsSchemaVersion,
 		},
 	}
 
@@ -765,6 +769,7 @@ type nodeInfo struct {
 	OsVer    string `json:"os_v"`
 	Client   string `json:"client"`
 	History  bool   `json:"canUpdateHistory"`
+	Schema   int    `json:"schema"`
 }
 
 // authMsg is the authentication infos needed to login to a monitoring server.
@@ -997,7 +1002,7 @@ func (s *Service) assembleBlockStats(block *types.Block) *blockStats {
 	}
 	td = s.backend.GetTd(context.Background(), header.Hash())
 	stateDB, _, _ = s.backend.StateAndHeaderByNumberOrHash(context.Background(), rpc.BlockNumberOrHashWithHash(header.Hash(), true))
-	vmRunner = s.backend.NewEVMRunner(header, stateDB)
+	vmRunner = s.backend.NewEVMRunner(context.Background(), header, stateDB)
 
 	// Assemble and return the block stats
 	author, _ := s.engine.Author(header)
@@ -1052,7 +1057,7 @@ func (s *Service) assembleValidatorSet(block *types.Block, state vm.StateDB) val
 		valsElected    []common.Address
 	)
 
-	vmRunner := s.backend.NewEVMRunner(block.Header(), state)
+	vmRunner := s.backend.NewEVMRunner(context.Background(), block.Header(), state)
 
 	// Add set of registered validators
 	valsRegisteredMap, _ := validators.RetrieveRegisteredValidators(vmRunner)
@@ -1176,6 +1181,33 @@ type nodeStats struct {
 	Peers    int  `json:"peers"`
 	GasPrice int  `json:"gasPrice"`
 	Uptime   int  `json:"uptime"`
+
+	// Validator health stats, since schema version 2
+	Schema        int    `json:"schema"`
+	Epoch         uint64 `json:"epoch"`
+	Proposed      int64  `json:"proposed"`      // Blocks proposed while elected
+	RoundChanges  int64  `json:"roundChanges"`  // Round changes seen to agree on blocks
+	Proxied       bool   `json:"proxied"`       // Whether the validator runs behind proxies
+	PeeredProxies int    `json:"peeredProxies"` // Proxies peered with the proxied validator
+}
+
+// validatorHealth is the part of the istanbul backend reporting on the health
+// of the local validator.
+type validatorHealth interface {
+	ProposedBlocks() int64
+	MissedRounds() int64
+	IsProxiedValidator() bool
+	PeeredProxies() int
+}
+
+// setValidatorHealth fills in the validator health stats at the given epoch.
+func (stats *nodeStats) setValidatorHealth(epoch uint64, health validatorHealth) {
+	stats.Schema = statsSchemaVersion
+	stats.Epoch = epoch
+	stats.Proposed = health.ProposedBlocks()
+	stats.RoundChanges = health.MissedRounds()
+	stats.Proxied = health.IsProxiedValidator()
+	stats.PeeredProxies = health.PeeredProxies()
 }
 
 // reportStats retrieves various stats about the node at the networking and
@@ -1189,6 +1221,7 @@ func (s *Service) reportStats(conn *connWrapper) error {
 		elected          bool
 		syncing          bool
 		gasprice         int
+		epoch            uint64
 	)
 	// check if backend is a full node
 	fullBackend, ok := s.backend.(fullNodeBackend)
@@ -1208,6 +1241,8 @@ func (s *Service) reportStats(conn *connWrapper) error {
 			}
 		}
 
+		epoch = istanbul.GetEpochNumber(block.NumberU64(), s.istanbulBackend.EpochSize())
+
 		sync := fullBackend.Downloader().Progress()
 		syncing = fullBackend.CurrentHeader().Number.Uint64() >= sync.HighestBlock
 
@@ -1220,19 +1255,22 @@ func (s *Service) reportStats(conn *connWrapper) error {
 	// Assemble the node stats and send it to the server
 	log.Trace("Sending node details to ethstats")
 
+	nodeStats := &nodeStats{
+		Active:   true,
+		Mining:   mining,
+		Elected:  elected,
+		Proxy:    proxy,
+		Peers:    s.server.PeerCount(),
+		GasPrice: gasprice,
+		Syncing:  syncing,
+		Uptime:   100,
+	}
+	nodeStats.setValidatorHealth(epoch, s.istanbulBackend)
+
 	stats := map[string]interface{}{
 		"id":      s.istanbulBackend.ValidatorAddress().String(),
 		"address": validatorAddress,
-		"stats": &nodeStats{
-			Active:   true,
-			Mining:   mining,
-			Elected:  elected,
-			Proxy:    proxy,
-			Peers:    s.server.PeerCount(),
-			GasPrice: gasprice,
-			Syncing:  syncing,
-			Uptime:   100,
-		},
+		"stats":   nodeStats,
 	}
 
 	return s.sendStats(conn, actionStats, stats)
@@ -0,0 +1,88 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethstats
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	istanbulBackend "github.com/celo-org/celo-blockchain/consensus/istanbul/backend"
+)
+
+// The istanbul backend reports the validator health
+var _ validatorHealth = (*istanbulBackend.Backend)(nil)
+
+type testValidatorHealth struct {
+	proposed, missed int64
+	proxied          bool
+	peered           int
+}
+
+func (h *testValidatorHealth) ProposedBlocks() int64    { return h.proposed }
+func (h *testValidatorHealth) MissedRounds() int64      { return h.missed }
+func (h *testValidatorHealth) IsProxiedValidator() bool { return h.proxied }
+func (h *testValidatorHealth) PeeredProxies() int       { return h.peered }
+
+// Tests that the node stats report the validator health under the current
+// schema version, next to the fields of the first version.
+func TestNodeStatsJSON(t *testing.T) {
+	tests := []struct {
+		health *testValidatorHealth
+		want   map[string]interface{}
+	}{
+		{
+			health: &testValidatorHealth{proposed: 12, missed: 3},
+			want:   map[string]interface{}{"proposed": 12.0, "roundChanges": 3.0, "proxied": false, "peeredProxies": 0.0},
+		},
+		{
+			health: &testValidatorHealth{proposed: 7, missed: 1, proxied: true, peered: 2},
+			want:   map[string]interface{}{"proposed": 7.0, "roundChanges": 1.0, "proxied": true, "peeredProxies": 2.0},
+		},
+	}
+	for i, tt := range tests {
+		stats := &nodeStats{Active: true, Mining: true, Elected: true, Peers: 5, GasPrice: 1000, Uptime: 100}
+		stats.setValidatorHealth(42, tt.health)
+
+		blob, err := json.Marshal(stats)
+		if err != nil {
+			t.Fatalf("test %d: failed to encode stats: %v", i, err)
+		}
+		var fields map[string]interface{}
+		if err := json.Unmarshal(blob, &fields); err != nil {
+			t.Fatalf("test %d: failed to decode stats: %v", i, err)
+		}
+		want := map[string]interface{}{
+			"active":   true,
+			"syncing":  false,
+			"mining":   true,
+			"proxy":    false,
+			"elected":  true,
+			"peers":    5.0,
+			"gasPrice": 1000.0,
+			"uptime":   100.0,
+			"schema":   float64(statsSchemaVersion),
+			"epoch":    42.0,
+		}
+		for field, value := range tt.want {
+			want[field] = value
+		}
+		if !reflect.DeepEqual(fields, want) {
+			t.Errorf("test %d: stats mismatch:\nhave %v\nwant %v", i, fields, want)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package exporter implements a service streaming the blocks, receipts, logs
+// and epoch changes of the canonical chain to Kafka or NATS.
+package exporter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/common/hexutil"
+	"github.com/celo-org/celo-blockchain/consensus/istanbul"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/internal/follower"
+	"github.com/celo-org/celo-blockchain/log"
+	"github.com/celo-org/celo-blockchain/metrics"
+	"github.com/celo-org/celo-blockchain/node"
+)
+
+var (
+	publishedMeter = metrics.NewRegisteredMeter("exporter/published", nil)
+	failureMeter   = metrics.NewRegisteredMeter("exporter/failures", nil)
+	offsetGauge    = metrics.NewRegisteredGauge("exporter/offset", nil)
+)
+
+// Config are the configuration parameters of the chain event exporter.
+type Config struct {
+	URL        string // Sink the events are published to, nats://host:port (JetStream) or kafka+http(s)://proxy (Confluent REST proxy)
+	Prefix     string // Prefix of the topics, or NATS subjects, the events are published to
+	OffsetFile string // File the last exported block is persisted to, relative to the data directory
+}
+
+// DefaultConfig contains the default exporter settings.
+var DefaultConfig = Config{
+	Prefix:     "celo",
+	OffsetFile: "exporter.offset",
+}
+
+// backend encompasses the functionality needed to export the chain
+type backend interface {
+	follower.Backend
+	GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error)
+}
+
+// validatorSource is the part of the consensus engine reporting the epochs.
+type validatorSource interface {
+	EpochSize() uint64
+	GetValidators(blockNumber *big.Int, headerHash common.Hash) []istanbul.Validator
+}
+
+// Offset is the last block exported. It is only advanced once all the events
+// of the block were acknowledged by the sink, so that the export resumes from
+// it after a restart, delivering every event at least once.
+type Offset = follower.Cursor
+
+// blockEvent is published for every canonical block.
+type blockEvent struct {
+	Header       *types.Header        `json:"header"`
+	Hash         common.Hash          `json:"hash"`
+	Transactions []*types.Transaction `json:"transactions"`
+}
+
+// epochEvent is published for the last block of every epoch.
+type epochEvent struct {
+	Epoch      hexutil.Uint64   `json:"epoch"`
+	Number     hexutil.Uint64   `json:"number"`
+	Hash       common.Hash      `json:"hash"`
+	Validators []common.Address `json:"validators"` // Validators elected for the next epoch
+}
+
+// reorgEvent is published when exported blocks leave the canonical chain. The
+// events of the new canonical blocks follow.
+type reorgEvent struct {
+	Number  hexutil.Uint64 `json:"number"` // Common ancestor of the old and new chains
+	Hash    common.Hash    `json:"hash"`
+	Removed []common.Hash  `json:"removed"` // Exported blocks removed, highest first
+}
+
+// Service publishes the events of every canonical block to a sink, in order.
+type Service struct {
+	config   *Config
+	backend  backend
+	engine   validatorSource
+	sink     Sink
+	path     string // Resolved offset file
+	follower *follower.Follower
+
+	offset *Offset // Last exported block, nil until the first export
+}
+
+// New creates a chain exporter and registers it in the node.
+func New(stack *node.Node, backend backend, engine validatorSource, config *Config) error {
+	sink, err := NewSink(config.URL)
+	if err != nil {
+		return err
+	}
+	offsetFile := config.OffsetFile
+	if offsetFile == "" {
+		offsetFile = DefaultConfig.OffsetFile
+	}
+	stack.RegisterLifecycle(newService(config, backend, engine, sink, stack.ResolvePath(offsetFile)))
+	return nil
+}
+
+func newService(config *Config, backend backend, engine validatorSource, sink Sink, path string) *Service {
+	s := &Service{
+		config:  config,
+		backend: backend,
+		engine:  engine,
+		sink:    sink,
+		path:    path,
+	}
+	s.follower = follower.New(backend, s, follower.Config{
+		Failures: failureMeter,
+		Logger:   log.New("service", "exporter"),
+	})
+	return s
+}
+
+// Start implements node.Lifecycle, resuming the export from the stored offset,
+// or from the current head if there is none.
+func (s *Service) Start() error {
+	offset, err := readOffset(s.path)
+	if err != nil {
+		return err
+	}
+	if offset == nil {
+		head := s.backend.CurrentHeader()
+		if head.Number.Uint64() > 0 {
+			offset = &Offset{Number: head.Number.Uint64() - 1, Hash: head.ParentHash}
+		}
+	}
+	s.offset = offset
+	s.follower.Start()
+
+	log.Info("Chain exporter started", "prefix", s.config.Prefix, "offset", offset)
+	return nil
+}
+
+// Stop implements node.Lifecycle, terminating the exporter.
+func (s *Service) Stop() error {
+	s.follower.Stop()
+	s.sink.Close()
+	log.Info("Chain exporter stopped")
+	return nil
+}
+
+// Head implements follower.Handler, returning the offset.
+func (s *Service) Head() (*follower.Cursor, error) {
+	return s.offset, nil
+}
+
+// Handle implements follower.Handler, publishing the events of the blocks and
+// advancing the offset after each of them.
+func (s *Service) Handle(ctx context.Context, blocks []*types.Block) error {
+	for _, block := range blocks {
+		msgs, err := s.blockMessages(ctx, block)
+		if err != nil {
+			return err
+		}
+		if err := s.sink.Publish(msgs); err != nil {
+			return err
+		}
+		publishedMeter.Mark(int64(len(msgs)))
+		if err := s.setOffset(&Offset{Number: block.NumberU64(), Hash: block.Hash()}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Rewind implements follower.Handler, publishing the exported blocks that left
+// the canonical chain and moving the offset back to their ancestor.
+func (s *Service) Rewind(ctx context.Context, ancestor *follower.Cursor, removed []common.Hash) error {
+	if ancestor == nil {
+		return fmt.Errorf("exported block %x not found in the canonical chain", removed[len(removed)-1])
+	}
+	value, err := json.Marshal(&reorgEvent{Number: hexutil.Uint64(ancestor.Number), Hash: ancestor.Hash, Removed: removed})
+	if err != nil {
+		return err
+	}
+	if err := s.sink.Publish([]Message{{Topic: s.topic("reorgs"), Key: ancestor.Hash.Bytes(), Value: value}}); err != nil {
+		return err
+	}
+	publishedMeter.Mark(1)
+	return s.setOffset(ancestor)
+}
+
+// blockMessages assembles the events of a block: the block itself, its
+// receipts, its logs and, for the last block of an epoch, the epoch change.
+func (s *Service) blockMessages(ctx context.Context, block *types.Block) ([]Message, error) {
+	receipts, err := s.backend.GetReceipts(ctx, block.Hash())
+	if err != nil {
+		return nil, err
+	}
+	var msgs []Message
+	add := func(topic string, key []byte, event interface{}) error {
+		value, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		msgs = append(msgs, Message{Topic: s.topic(topic), Key: key, Value: value})
+		return nil
+	}
+	hash := block.Hash()
+	if err := add("blocks", hash.Bytes(), &blockEvent{Header: block.Header(), Hash: hash, Transactions: block.Transactions()}); err != nil {
+		return nil, err
+	}
+	for _, receipt := range receipts {
+		if err := add("receipts", receipt.TxHash.Bytes(), receipt); err != nil {
+			return nil, err
+		}
+		for _, l := range receipt.Logs {
+			if err := add("logs", hash.Bytes(), l); err != nil {
+				return nil, err
+			}
+		}
+	}
+	number := block.NumberU64()
+	if epochSize := s.engine.EpochSize(); number > 0 && istanbul.IsLastBlockOfEpoch(number, epochSize) {
+		event := &epochEvent{
+			Epoch:  hexutil.Uint64(istanbul.GetEpochNumber(number, epochSize)),
+			Number: hexutil.Uint64(number),
+			Hash:   hash,
+		}
+		for _, validator := range s.engine.GetValidators(block.Number(), hash) {
+			event.Validators = append(event.Validators, validator.Address())
+		}
+		if err := add("epochs", hash.Bytes(), event); err != nil {
+			return nil, err
+		}
+	}
+	return msgs, nil
+}
+
+// topic returns the full name of the topic events of the given kind go to.
+func (s *Service) topic(kind string) string {
+	if s.config.Prefix == "" {
+		return kind
+	}
+	return s.config.Prefix + "." + kind
+}
+
+// setOffset persists the last exported block.
+func (s *Service) setOffset(offset *Offset) error {
+	if err := writeOffset(s.path, offset); err != nil {
+		return err
+	}
+	s.offset = offset
+	offsetGauge.Update(int64(offset.Number))
+	return nil
+}
+
+// readOffset loads the offset stored in the given file, if any.
+func readOffset(path string) (*Offset, error) {
+	blob, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	offset := new(Offset)
+	if err := json.Unmarshal(blob, offset); err != nil {
+		return nil, fmt.Errorf("invalid export offset file %s: %v", path, err)
+	}
+	return offset, nil
+}
+
+// writeOffset atomically replaces the offset stored in the given file.
+func writeOffset(path string, offset *Offset) error {
+	blob, err := json.Marshal(offset)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, blob, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
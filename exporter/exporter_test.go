@@ -0,0 +1,160 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package exporter
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/consensus/istanbul"
+	"github.com/celo-org/celo-blockchain/consensus/istanbul/validator"
+	"github.com/celo-org/celo-blockchain/core/types"
+	blscrypto "github.com/celo-org/celo-blockchain/crypto/bls"
+	"github.com/celo-org/celo-blockchain/internal/follower/followertest"
+)
+
+// testEngine elects the same validator every epoch.
+type testEngine struct{}
+
+func (testEngine) EpochSize() uint64 { return 2 }
+
+func (testEngine) GetValidators(blockNumber *big.Int, headerHash common.Hash) []istanbul.Validator {
+	return []istanbul.Validator{validator.New(common.Address{0xaa}, blscrypto.SerializedPublicKey{})}
+}
+
+// testSink records the published messages, failing the given number of
+// batches first.
+type testSink struct {
+	fails int
+	msgs  []Message
+}
+
+func (s *testSink) Publish(msgs []Message) error {
+	if s.fails > 0 {
+		s.fails--
+		return errors.New("unavailable")
+	}
+	s.msgs = append(s.msgs, msgs...)
+	return nil
+}
+
+func (s *testSink) Close() error { return nil }
+
+// topics returns the topics of the published messages, in order.
+func (s *testSink) topics() []string {
+	var topics []string
+	for _, msg := range s.msgs {
+		topics = append(topics, msg.Topic)
+	}
+	return topics
+}
+
+func checkTopics(t *testing.T, have, want []string) {
+	t.Helper()
+	if len(have) != len(want) {
+		t.Fatalf("published topics mismatch: have %v, want %v", have, want)
+	}
+	for i := range have {
+		if have[i] != want[i] {
+			t.Fatalf("published topics mismatch: have %v, want %v", have, want)
+		}
+	}
+}
+
+func TestExport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "exporter-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	backend := followertest.NewChain()
+	backend.Extend(0, 4, 0)
+	backend.Receipts[backend.Canonical[1].Hash()] = types.Receipts{{
+		TxHash: common.Hash{1},
+		Logs:   []*types.Log{{Address: common.Address{2}}},
+	}}
+	var (
+		sink    = &testSink{fails: 1}
+		path    = filepath.Join(dir, "offset")
+		service = newService(&Config{Prefix: "celo"}, backend, testEngine{}, sink, path)
+	)
+	service.offset = &Offset{Number: 0, Hash: backend.Canonical[0].Hash()}
+
+	// Failed batches are not acknowledged and are published again
+	if err := service.follower.Follow(); err == nil {
+		t.Fatal("no error for failed publication")
+	}
+	if service.offset.Number != 0 || len(sink.msgs) != 0 {
+		t.Fatalf("offset advanced by failed publication: %v", service.offset)
+	}
+	if err := service.follower.Follow(); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	checkTopics(t, sink.topics(), []string{
+		"celo.blocks", "celo.receipts", "celo.logs",
+		"celo.blocks", "celo.epochs",
+		"celo.blocks",
+		"celo.blocks", "celo.epochs",
+	})
+	var epoch epochEvent
+	if err := json.Unmarshal(sink.msgs[4].Value, &epoch); err != nil {
+		t.Fatal(err)
+	}
+	if epoch.Epoch != 1 || epoch.Number != 2 || len(epoch.Validators) != 1 || epoch.Validators[0] != (common.Address{0xaa}) {
+		t.Errorf("epoch event mismatch: have %+v", epoch)
+	}
+	// The offset is persisted for the export to resume after restarts
+	stored, err := readOffset(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Offset{Number: 4, Hash: backend.Canonical[4].Hash()}); stored == nil || *stored != want {
+		t.Fatalf("stored offset mismatch: have %v, want %v", stored, &want)
+	}
+
+	// Exported blocks leaving the canonical chain are reported before the new ones
+	removed := []common.Hash{backend.Canonical[4].Hash(), backend.Canonical[3].Hash()}
+	backend.Extend(2, 3, 1)
+
+	sink.msgs = nil
+	if err := service.follower.Follow(); err != nil {
+		t.Fatalf("export after reorg failed: %v", err)
+	}
+	checkTopics(t, sink.topics(), []string{
+		"celo.reorgs",
+		"celo.blocks",
+		"celo.blocks", "celo.epochs",
+		"celo.blocks",
+	})
+	var reorg reorgEvent
+	if err := json.Unmarshal(sink.msgs[0].Value, &reorg); err != nil {
+		t.Fatal(err)
+	}
+	if reorg.Number != 2 || reorg.Hash != backend.Canonical[2].Hash() || len(reorg.Removed) != 2 || reorg.Removed[0] != removed[0] || reorg.Removed[1] != removed[1] {
+		t.Errorf("reorg event mismatch: have %+v", reorg)
+	}
+	if service.offset.Number != 5 || service.offset.Hash != backend.Canonical[5].Hash() {
+		t.Errorf("offset mismatch after reorg: have %v", service.offset)
+	}
+}
@@ -0,0 +1,392 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package exporter
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// sinkTimeout is the time allowed to a sink to acknowledge a batch.
+	sinkTimeout = 30 * time.Second
+
+	// natsDefaultPort is the port of NATS servers missing from the URL.
+	natsDefaultPort = "4222"
+)
+
+// Message is a single record published to a sink.
+type Message struct {
+	Topic string // Kafka topic or NATS subject
+	Key   []byte // Partitioning key, the hash of the block or transaction
+	Value []byte // JSON encoded event
+}
+
+// Sink is a streaming platform the chain events are published to. A batch is
+// only acknowledged once the platform accepted all of its messages, on errors
+// the whole batch is published again.
+type Sink interface {
+	Publish(msgs []Message) error
+	Close() error
+}
+
+// NewSink creates the sink for the given URL. NATS servers are selected by the
+// nats scheme, the subjects having to be bound to JetStream streams. Kafka
+// clusters are only reachable through a Confluent REST proxy, selected by the
+// kafka+http and kafka+https schemes, the native protocol is not supported.
+func NewSink(rawurl string) (Sink, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "nats":
+		return newNATSSink(u), nil
+	case "kafka+http", "kafka+https":
+		return newKafkaSink(u), nil
+	default:
+		return nil, fmt.Errorf("unsupported export sink %q", u.Scheme)
+	}
+}
+
+// natsSink publishes to the JetStream streams of a NATS server using its text
+// protocol. Every message is published with a reply subject on which the stream
+// acknowledges having persisted it, and carries an ID by which JetStream drops
+// duplicates of messages published again after a failure.
+type natsSink struct {
+	addr string
+	user *url.Userinfo
+
+	conn  net.Conn
+	r     *bufio.Reader
+	inbox string // Subject prefix the acknowledgements are delivered to
+}
+
+// natsAck is the acknowledgement of a JetStream publication.
+type natsAck struct {
+	Stream    string `json:"stream"`
+	Sequence  uint64 `json:"seq"`
+	Duplicate bool   `json:"duplicate"`
+	Error     *struct {
+		Code        int    `json:"code"`
+		Description string `json:"description"`
+	} `json:"error"`
+}
+
+func newNATSSink(u *url.URL) *natsSink {
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), natsDefaultPort)
+	}
+	return &natsSink{addr: addr, user: u.User}
+}
+
+// connect dials the server, completes the handshake and subscribes to the
+// acknowledgements.
+func (s *natsSink) connect() error {
+	conn, err := net.DialTimeout("tcp", s.addr, sinkTimeout)
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(sinkTimeout))
+	r := bufio.NewReader(conn)
+
+	line, err := r.ReadString('\n')
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		conn.Close()
+		return fmt.Errorf("unexpected NATS greeting %q", strings.TrimSpace(line))
+	}
+	options := map[string]interface{}{
+		"verbose":       false,
+		"pedantic":      false,
+		"name":          "celo-exporter",
+		"headers":       true,
+		"no_responders": true,
+	}
+	if s.user != nil {
+		options["user"] = s.user.Username()
+		if pass, ok := s.user.Password(); ok {
+			options["pass"] = pass
+		}
+	}
+	var nonce [8]byte
+	rand.Read(nonce[:])
+	inbox := "_INBOX." + hex.EncodeToString(nonce[:])
+
+	blob, _ := json.Marshal(options)
+	if _, err := fmt.Fprintf(conn, "CONNECT %s\r\nSUB %s.* %s\r\n", blob, inbox, natsAckSID); err != nil {
+		conn.Close()
+		return err
+	}
+	s.conn, s.r, s.inbox = conn, r, inbox
+	return nil
+}
+
+// natsAckSID is the subscription ID of the acknowledgements.
+const natsAckSID = "1"
+
+// Publish implements Sink.
+func (s *natsSink) Publish(msgs []Message) error {
+	if s.conn == nil {
+		if err := s.connect(); err != nil {
+			return err
+		}
+	}
+	if err := s.publish(msgs); err != nil {
+		// The server state is unknown, start over on a new connection
+		s.Close()
+		return err
+	}
+	return nil
+}
+
+func (s *natsSink) publish(msgs []Message) error {
+	s.conn.SetDeadline(time.Now().Add(sinkTimeout))
+
+	w := bufio.NewWriter(s.conn)
+	for i, msg := range msgs {
+		header := fmt.Sprintf("NATS/1.0\r\nNats-Msg-Id: %s\r\n\r\n", natsMsgID(msg))
+		fmt.Fprintf(w, "HPUB %s %s.%d %d %d\r\n", msg.Topic, s.inbox, i, len(header), len(header)+len(msg.Value))
+		w.WriteString(header)
+		w.Write(msg.Value)
+		w.WriteString("\r\n")
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	acked := make([]bool, len(msgs))
+	for pending := len(msgs); pending > 0; {
+		line, err := s.r.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "MSG", "HMSG":
+			index, status, payload, err := s.readAck(fields)
+			if err != nil {
+				return err
+			}
+			if index < 0 || index >= len(msgs) || acked[index] {
+				continue // Late acknowledgement of a previous batch
+			}
+			if status != "" {
+				return fmt.Errorf("NATS publication to %s failed: %s", msgs[index].Topic, status)
+			}
+			var ack natsAck
+			if err := json.Unmarshal(payload, &ack); err != nil {
+				return fmt.Errorf("invalid JetStream acknowledgement: %v", err)
+			}
+			if ack.Error != nil {
+				return fmt.Errorf("JetStream rejected message to %s: %s (%d)", msgs[index].Topic, ack.Error.Description, ack.Error.Code)
+			}
+			acked[index] = true
+			pending--
+		case "PING":
+			if _, err := io.WriteString(s.conn, "PONG\r\n"); err != nil {
+				return err
+			}
+		case "-ERR":
+			return fmt.Errorf("NATS error: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+		// INFO updates, PONG and +OK are of no interest
+	}
+	return nil
+}
+
+// readAck reads the payload of a message delivered to the acknowledgement
+// inbox, returning the index of the acknowledged message in its batch and the
+// status carried by the headers, set if the publication had no stream.
+func (s *natsSink) readAck(fields []string) (int, string, []byte, error) {
+	// MSG <subject> <sid> [reply] <size>, HMSG <subject> <sid> [reply] <hdrsize> <size>
+	headers := fields[0] == "HMSG"
+	args := 4
+	if headers {
+		args++
+	}
+	if len(fields) != args && len(fields) != args+1 {
+		return 0, "", nil, fmt.Errorf("malformed NATS message %q", strings.Join(fields, " "))
+	}
+	size, err := strconv.Atoi(fields[len(fields)-1])
+	if err != nil {
+		return 0, "", nil, err
+	}
+	hdrsize := 0
+	if headers {
+		if hdrsize, err = strconv.Atoi(fields[len(fields)-2]); err != nil || hdrsize > size {
+			return 0, "", nil, fmt.Errorf("malformed NATS message %q", strings.Join(fields, " "))
+		}
+	}
+	payload := make([]byte, size+2)
+	if _, err := io.ReadFull(s.r, payload); err != nil {
+		return 0, "", nil, err
+	}
+	index, err := strconv.Atoi(strings.TrimPrefix(fields[1], s.inbox+"."))
+	if err != nil || !strings.HasPrefix(fields[1], s.inbox+".") {
+		return -1, "", nil, nil
+	}
+	var status string
+	if headers {
+		// The status follows the version on the first header line, e.g. 503
+		// if no stream is bound to the subject
+		version := strings.SplitN(string(payload[:hdrsize]), "\r\n", 2)[0]
+		status = strings.TrimSpace(strings.TrimPrefix(version, "NATS/1.0"))
+	}
+	return index, status, payload[hdrsize:size], nil
+}
+
+// natsMsgID derives the JetStream deduplication ID of a message from its
+// contents, so that the copies published again after a failure are dropped.
+func natsMsgID(msg Message) string {
+	h := sha256.New()
+	h.Write([]byte(msg.Topic))
+	h.Write([]byte{0})
+	h.Write(msg.Key)
+	h.Write([]byte{0})
+	h.Write(msg.Value)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Close implements Sink.
+func (s *natsSink) Close() error {
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn, s.r = nil, nil
+	return err
+}
+
+// kafkaRecord is a record of the Kafka REST proxy binary embedded format.
+type kafkaRecord struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value"`
+}
+
+// kafkaResponse is the reply of the Kafka REST proxy to produce requests.
+type kafkaResponse struct {
+	Offsets []struct {
+		Partition *int   `json:"partition"`
+		Offset    *int64 `json:"offset"`
+		ErrorCode *int   `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+}
+
+// kafkaSink publishes to a Kafka cluster through its REST proxy (v2 API). The
+// proxy replies once the records are acknowledged by the brokers.
+type kafkaSink struct {
+	endpoint string
+	client   *http.Client
+}
+
+func newKafkaSink(u *url.URL) *kafkaSink {
+	endpoint := *u
+	endpoint.Scheme = strings.TrimPrefix(u.Scheme, "kafka+")
+	return &kafkaSink{
+		endpoint: strings.TrimSuffix(endpoint.String(), "/"),
+		client:   &http.Client{Timeout: sinkTimeout},
+	}
+}
+
+// Publish implements Sink, producing the records of every topic in a single
+// request.
+func (s *kafkaSink) Publish(msgs []Message) error {
+	var (
+		topics  []string
+		records = make(map[string][]kafkaRecord)
+	)
+	for _, msg := range msgs {
+		if _, ok := records[msg.Topic]; !ok {
+			topics = append(topics, msg.Topic)
+		}
+		records[msg.Topic] = append(records[msg.Topic], kafkaRecord{Key: msg.Key, Value: msg.Value})
+	}
+	for _, topic := range topics {
+		if err := s.produce(topic, records[topic]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *kafkaSink) produce(topic string, records []kafkaRecord) error {
+	body, err := json.Marshal(map[string]interface{}{"records": records})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, s.endpoint+"/topics/"+url.PathEscape(topic), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/vnd.kafka.binary.v2+json")
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	blob, err := ioutil.ReadAll(io.LimitReader(res.Body, 1024*1024))
+	if err != nil {
+		return err
+	}
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("kafka proxy returned %s: %s", res.Status, bytes.TrimSpace(blob))
+	}
+	var reply kafkaResponse
+	if err := json.Unmarshal(blob, &reply); err != nil {
+		return err
+	}
+	if len(reply.Offsets) != len(records) {
+		return fmt.Errorf("kafka proxy acknowledged %d of %d records", len(reply.Offsets), len(records))
+	}
+	for _, offset := range reply.Offsets {
+		if offset.ErrorCode != nil || offset.Offset == nil {
+			return errors.New("kafka record rejected: " + offset.Error)
+		}
+	}
+	return nil
+}
+
+// Close implements Sink.
+func (s *kafkaSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,224 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package exporter
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// natsStream is the state of the JetStream stream emulated by serveNATS,
+// surviving reconnections.
+type natsStream struct {
+	seq  int
+	seen map[string]int // Sequence numbers by message ID
+}
+
+// serveNATS accepts a single connection and records the messages published to
+// JetStream, rejecting the ones to the reject subject and replying with no
+// responders to the ones to the unbound subject. It returns false once the
+// listener is closed.
+func serveNATS(t *testing.T, listener net.Listener, stream *natsStream, reject string, unbound string, published chan<- Message) bool {
+	conn, err := listener.Accept()
+	if err != nil {
+		return false
+	}
+	defer conn.Close()
+
+	io.WriteString(conn, "INFO {\"server_id\":\"test\",\"headers\":true}\r\n")
+	r := bufio.NewReader(conn)
+	var (
+		inbox string
+		sid   string
+	)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return true
+		}
+		fields := strings.Fields(line)
+		switch fields[0] {
+		case "CONNECT":
+			var options struct {
+				Headers bool `json:"headers"`
+			}
+			json.Unmarshal([]byte(strings.TrimPrefix(line, "CONNECT ")), &options)
+			if !options.Headers {
+				t.Errorf("headers not enabled")
+			}
+		case "SUB":
+			inbox, sid = fields[1], fields[2]
+		case "HPUB":
+			hdrsize, _ := strconv.Atoi(fields[3])
+			size, _ := strconv.Atoi(fields[4])
+			payload := make([]byte, size+2)
+			if _, err := io.ReadFull(r, payload); err != nil {
+				return true
+			}
+			if !strings.HasPrefix(fields[2], strings.TrimSuffix(inbox, "*")) {
+				t.Errorf("reply subject %s outside of inbox %s", fields[2], inbox)
+			}
+			var ack string
+			switch fields[1] {
+			case reject:
+				ack = `{"error":{"code":503,"description":"insufficient resources"}}`
+			case unbound:
+				fmt.Fprintf(conn, "HMSG %s %s 16 16\r\nNATS/1.0 503\r\n\r\n\r\n", fields[2], sid)
+				continue
+			default:
+				// Deduplicate by message ID as JetStream does
+				id := strings.TrimSpace(strings.SplitN(string(payload[:hdrsize]), "Nats-Msg-Id:", 2)[1])
+				if prev, ok := stream.seen[id]; ok {
+					ack = fmt.Sprintf(`{"stream":"CELO","seq":%d,"duplicate":true}`, prev)
+					break
+				}
+				stream.seq++
+				stream.seen[id] = stream.seq
+				published <- Message{Topic: fields[1], Value: payload[hdrsize:size]}
+				ack = fmt.Sprintf(`{"stream":"CELO","seq":%d}`, stream.seq)
+			}
+			fmt.Fprintf(conn, "MSG %s %s %d\r\n%s\r\n", fields[2], sid, len(ack), ack)
+		default:
+			t.Errorf("unexpected NATS command %q", line)
+			return true
+		}
+	}
+}
+
+func TestNATSSink(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	published := make(chan Message, 10)
+	go func() {
+		stream := &natsStream{seen: make(map[string]int)}
+		for serveNATS(t, listener, stream, "celo.rejected", "celo.unbound", published) {
+		}
+	}()
+	sink, err := NewSink("nats://" + listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	msgs := []Message{
+		{Topic: "celo.blocks", Value: []byte(`{"number":1}`)},
+		{Topic: "celo.logs", Value: []byte("line\r\nbreak")},
+	}
+	if err := sink.Publish(msgs); err != nil {
+		t.Fatalf("publish failed: %v", err)
+	}
+	for i, want := range msgs {
+		have := <-published
+		if have.Topic != want.Topic || string(have.Value) != string(want.Value) {
+			t.Errorf("message %d mismatch: have %s %q, want %s %q", i, have.Topic, have.Value, want.Topic, want.Value)
+		}
+	}
+	// Messages rejected by the stream, or not bound to any, fail the batch,
+	// which is sent again on a new connection
+	if err := sink.Publish([]Message{{Topic: "celo.rejected", Value: []byte("{}")}}); err == nil {
+		t.Fatal("no error for rejected message")
+	}
+	if err := sink.Publish([]Message{{Topic: "celo.unbound", Value: []byte("{}")}}); err == nil {
+		t.Fatal("no error for message without stream")
+	}
+	next := Message{Topic: "celo.blocks", Value: []byte(`{"number":2}`)}
+	if err := sink.Publish([]Message{next}); err != nil {
+		t.Fatalf("publish after error failed: %v", err)
+	}
+	if have := <-published; string(have.Value) != string(next.Value) {
+		t.Errorf("message mismatch after reconnect: have %s %q", have.Topic, have.Value)
+	}
+	// Messages published again are acknowledged but deduplicated by the stream
+	if err := sink.Publish(msgs); err != nil {
+		t.Fatalf("republish failed: %v", err)
+	}
+	select {
+	case have := <-published:
+		t.Errorf("duplicate message stored: %s %q", have.Topic, have.Value)
+	default:
+	}
+}
+
+func TestKafkaSink(t *testing.T) {
+	produced := make(map[string][]kafkaRecord)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/vnd.kafka.binary.v2+json" {
+			t.Errorf("content type mismatch: have %s", ct)
+		}
+		topic := strings.TrimPrefix(r.URL.Path, "/proxy/topics/")
+		var req struct {
+			Records []kafkaRecord `json:"records"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("invalid produce request: %v", err)
+		}
+		if topic == "celo.rejected" {
+			fmt.Fprint(w, `{"offsets":[{"error_code":40403,"error":"not authorized"}]}`)
+			return
+		}
+		produced[topic] = append(produced[topic], req.Records...)
+
+		var offsets []string
+		for i := range req.Records {
+			offsets = append(offsets, fmt.Sprintf(`{"partition":0,"offset":%d}`, i))
+		}
+		fmt.Fprintf(w, `{"offsets":[%s]}`, strings.Join(offsets, ","))
+	}))
+	defer server.Close()
+
+	sink, err := NewSink("kafka+" + server.URL + "/proxy/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	msgs := []Message{
+		{Topic: "celo.blocks", Key: []byte{1}, Value: []byte(`{"number":1}`)},
+		{Topic: "celo.logs", Key: []byte{1}, Value: []byte(`{"index":0}`)},
+		{Topic: "celo.logs", Key: []byte{1}, Value: []byte(`{"index":1}`)},
+	}
+	if err := sink.Publish(msgs); err != nil {
+		t.Fatalf("publish failed: %v", err)
+	}
+	if len(produced["celo.blocks"]) != 1 || len(produced["celo.logs"]) != 2 {
+		t.Fatalf("produced records mismatch: have %v", produced)
+	}
+	if have := string(produced["celo.logs"][1].Value); have != `{"index":1}` {
+		t.Errorf("record value mismatch: have %s", have)
+	}
+	if err := sink.Publish([]Message{{Topic: "celo.rejected", Value: []byte("{}")}}); err == nil {
+		t.Error("no error for rejected record")
+	}
+}
+
+func TestUnsupportedSink(t *testing.T) {
+	if _, err := NewSink("amqp://localhost"); err == nil {
+		t.Error("no error for unsupported sink")
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -110,8 +111,13 @@ func createNode(t *testing.T, gqlEnabled bool) *node.Node {
 }
 
 func createGQLService(t *testing.T, stack *node.Node, endpoint string) {
-	// create backend
-	ethBackend, err := eth.New(stack, &eth.DefaultConfig)
+	// create backend, keeping the Istanbul databases out of the package directory
+	ethConf := eth.DefaultConfig
+	dir := t.TempDir()
+	ethConf.Istanbul.RoundStateDBPath = filepath.Join(dir, "roundstates")
+	ethConf.Istanbul.ValidatorEnodeDBPath = filepath.Join(dir, "validatorenodes")
+	ethConf.Istanbul.VersionCertificateDBPath = filepath.Join(dir, "versioncertificates")
+	ethBackend, err := eth.New(stack, &ethConf)
 	if err != nil {
 		t.Fatalf("could not create eth backend: %v", err)
 	}
@@ -0,0 +1,177 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package indexer
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/crypto"
+	"github.com/celo-org/celo-blockchain/internal/follower"
+	"github.com/celo-org/celo-blockchain/internal/follower/followertest"
+)
+
+var (
+	testKey, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddress = crypto.PubkeyToAddress(testKey.PublicKey)
+)
+
+// testStore keeps the index in memory.
+type testStore struct {
+	blocks     map[uint64]*blockData
+	failInsert bool
+}
+
+func (s *testStore) head() (*follower.Cursor, error) {
+	var head *follower.Cursor
+	for number, block := range s.blocks {
+		if head == nil || number > head.Number {
+			head = &follower.Cursor{Number: number, Hash: block.Hash}
+		}
+	}
+	return head, nil
+}
+
+func (s *testStore) insert(blocks []*blockData) error {
+	if s.failInsert {
+		return errors.New("database unavailable")
+	}
+	for _, block := range blocks {
+		if _, ok := s.blocks[block.Number]; ok {
+			return errors.New("duplicate block")
+		}
+		s.blocks[block.Number] = block
+	}
+	return nil
+}
+
+func (s *testStore) truncate(from uint64) error {
+	for number := range s.blocks {
+		if number >= from {
+			delete(s.blocks, number)
+		}
+	}
+	return nil
+}
+
+func (s *testStore) close() error { return nil }
+
+func TestIndex(t *testing.T) {
+	backend := followertest.NewChain()
+	backend.Extend(0, 3, 0)
+
+	// Replace block 1 with one holding a token transfer
+	var (
+		config   = backend.ChainConfig()
+		signer   = types.MakeSigner(config, common.Big1)
+		token    = common.Address{0x70}
+		to       = common.Address{0x01}
+		tx, _    = types.SignTx(types.NewTransaction(0, token, new(big.Int), 50000, big.NewInt(1), nil, nil, nil, nil), signer, testKey)
+		transfer = &types.Log{
+			Address: token,
+			Topics:  []common.Hash{transferTopic, common.BytesToHash(testAddress[:]), common.BytesToHash(to[:])},
+			Data:    common.LeftPadBytes([]byte{42}, 32),
+			TxHash:  tx.Hash(),
+		}
+	)
+	block := types.NewBlock(&types.Header{ParentHash: backend.Canonical[0].Hash(), Number: common.Big1}, []*types.Transaction{tx}, nil, nil)
+	backend.Add(block)
+	backend.Extend(1, 2, 0)
+	backend.Receipts[block.Hash()] = types.Receipts{{Status: types.ReceiptStatusSuccessful, GasUsed: 30000, TxHash: tx.Hash(), Logs: []*types.Log{transfer}}}
+
+	var (
+		db      = &testStore{blocks: make(map[uint64]*blockData), failInsert: true}
+		open    = func() (store, error) { return db, nil }
+		service = newService(&Config{}, backend, open)
+	)
+	// Failed writes do not advance the index
+	if err := service.follower.Follow(); err == nil {
+		t.Fatal("no error for failed write")
+	}
+	if head := service.follower.Head(); head != nil {
+		t.Fatalf("head advanced by failed write: %v", head)
+	}
+	db.failInsert = false
+	if err := service.follower.Follow(); err != nil {
+		t.Fatalf("indexing failed: %v", err)
+	}
+	if len(db.blocks) != 4 {
+		t.Fatalf("indexed block count mismatch: have %d, want 4", len(db.blocks))
+	}
+	indexed := db.blocks[1]
+	if len(indexed.Transactions) != 1 || indexed.Transactions[0].From != testAddress || indexed.Transactions[0].GasUsed != 30000 {
+		t.Errorf("indexed transaction mismatch: have %+v", indexed.Transactions)
+	}
+	if len(indexed.Transfers) != 1 {
+		t.Fatalf("indexed transfer count mismatch: have %d, want 1", len(indexed.Transfers))
+	}
+	if tr := indexed.Transfers[0]; tr.Token != token || tr.From != testAddress || tr.To != to || tr.Value.Uint64() != 42 {
+		t.Errorf("indexed transfer mismatch: have %+v", tr)
+	}
+
+	// Blocks reorganised away are replaced, resuming from the stored index
+	backend.Extend(1, 3, 1)
+	service = newService(&Config{}, backend, open)
+	if err := service.follower.Follow(); err != nil {
+		t.Fatalf("indexing after reorg failed: %v", err)
+	}
+	if len(db.blocks) != 5 {
+		t.Fatalf("indexed block count mismatch after reorg: have %d, want 5", len(db.blocks))
+	}
+	for number, block := range db.blocks {
+		if block.Hash != backend.Canonical[number].Hash() {
+			t.Errorf("indexed block #%d not canonical", number)
+		}
+	}
+}
+
+func TestRows(t *testing.T) {
+	var (
+		to    = common.Address{0x02}
+		block = &blockData{Number: 7, Hash: common.Hash{0xab}}
+		tx    = &txData{To: &to, Value: big.NewInt(1000), GasPrice: big.NewInt(1)}
+	)
+	block.Transactions = []*txData{tx}
+
+	row := blockRow(block)
+	if len(row) != 7 || row[0] != int64(7) || row[6] != 1 {
+		t.Errorf("block row mismatch: have %v", row)
+	}
+	row = txRow(block, tx)
+	if len(row) != 17 {
+		t.Fatalf("transaction row length mismatch: have %d, want 17", len(row))
+	}
+	// Numerics are passed as text, missing optional values as NULL
+	if row[5] != "1000" || row[8] != "1" {
+		t.Errorf("numeric values mismatch: have %v and %v", row[5], row[8])
+	}
+	for _, i := range []int{9, 10, 11, 12, 16} {
+		if row[i] != nil {
+			t.Errorf("column %d not NULL: %v", i, row[i])
+		}
+	}
+	if input, ok := row[13].([]byte); !ok || input == nil {
+		t.Errorf("missing input not encoded as empty bytes: %v", row[13])
+	}
+	row = logRow(block, &types.Log{Topics: []common.Hash{transferTopic}})
+	if len(row) != 9 || row[5] != nil || row[7] != nil {
+		t.Errorf("log row mismatch: have %v", row)
+	}
+}
@@ -0,0 +1,271 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package indexer
+
+import (
+	"context"
+	"database/sql"
+	"math/big"
+	"time"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/internal/follower"
+	_ "github.com/lib/pq" // PostgreSQL driver
+)
+
+// schema creates the tables of the index. The rows of a block reference it,
+// so that deleting the blocks of a reorg deletes everything they contained.
+const schema = `
+CREATE TABLE IF NOT EXISTS blocks (
+	number      bigint PRIMARY KEY,
+	hash        bytea NOT NULL UNIQUE,
+	parent_hash bytea NOT NULL,
+	coinbase    bytea NOT NULL,
+	timestamp   bigint NOT NULL,
+	gas_used    bigint NOT NULL,
+	tx_count    integer NOT NULL
+);
+CREATE TABLE IF NOT EXISTS transactions (
+	hash                  bytea PRIMARY KEY,
+	block_number          bigint NOT NULL REFERENCES blocks (number) ON DELETE CASCADE,
+	tx_index              integer NOT NULL,
+	sender                bytea NOT NULL,
+	recipient             bytea,
+	value                 numeric NOT NULL,
+	nonce                 bigint NOT NULL,
+	gas                   bigint NOT NULL,
+	gas_price             numeric NOT NULL,
+	fee_currency          bytea,
+	fee_payer             bytea,
+	gateway_fee_recipient bytea,
+	gateway_fee           numeric,
+	input                 bytea NOT NULL,
+	status                smallint NOT NULL,
+	gas_used              bigint NOT NULL,
+	contract_address      bytea
+);
+CREATE INDEX IF NOT EXISTS transactions_block ON transactions (block_number);
+CREATE INDEX IF NOT EXISTS transactions_sender ON transactions (sender);
+CREATE INDEX IF NOT EXISTS transactions_recipient ON transactions (recipient);
+CREATE TABLE IF NOT EXISTS logs (
+	block_number bigint NOT NULL REFERENCES blocks (number) ON DELETE CASCADE,
+	log_index    integer NOT NULL,
+	tx_hash      bytea NOT NULL,
+	address      bytea NOT NULL,
+	topic0       bytea,
+	topic1       bytea,
+	topic2       bytea,
+	topic3       bytea,
+	data         bytea NOT NULL,
+	PRIMARY KEY (block_number, log_index)
+);
+CREATE INDEX IF NOT EXISTS logs_address ON logs (address, block_number);
+CREATE INDEX IF NOT EXISTS logs_topic0 ON logs (topic0, block_number);
+CREATE TABLE IF NOT EXISTS token_transfers (
+	block_number bigint NOT NULL REFERENCES blocks (number) ON DELETE CASCADE,
+	log_index    integer NOT NULL,
+	tx_hash      bytea NOT NULL,
+	token        bytea NOT NULL,
+	sender       bytea NOT NULL,
+	recipient    bytea NOT NULL,
+	value        numeric NOT NULL,
+	PRIMARY KEY (block_number, log_index)
+);
+CREATE INDEX IF NOT EXISTS token_transfers_token ON token_transfers (token, block_number);
+CREATE INDEX IF NOT EXISTS token_transfers_sender ON token_transfers (sender);
+CREATE INDEX IF NOT EXISTS token_transfers_recipient ON token_transfers (recipient);
+`
+
+// pgTimeout is the time allowed to a database operation to complete.
+const pgTimeout = time.Minute
+
+// Statements writing the rows of a block, in the column order of the schema.
+const (
+	insertBlockSQL    = "INSERT INTO blocks VALUES ($1, $2, $3, $4, $5, $6, $7)"
+	insertTxSQL       = "INSERT INTO transactions VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)"
+	insertLogSQL      = "INSERT INTO logs VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)"
+	insertTransferSQL = "INSERT INTO token_transfers VALUES ($1, $2, $3, $4, $5, $6, $7)"
+)
+
+// store is the database the index is written to.
+type store interface {
+	// head returns the highest indexed block, nil if none.
+	head() (*follower.Cursor, error)
+	// insert atomically writes the blocks.
+	insert(blocks []*blockData) error
+	// truncate deletes the blocks from the given number on.
+	truncate(from uint64) error
+	close() error
+}
+
+// pgStore is the PostgreSQL store.
+type pgStore struct {
+	db *sql.DB
+}
+
+// openPostgres connects to the database and creates the missing tables.
+func openPostgres(url string) (*pgStore, error) {
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		return nil, err
+	}
+	// The indexer writes sequentially, a single connection is all it needs
+	db.SetMaxOpenConns(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), pgTimeout)
+	defer cancel()
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &pgStore{db: db}, nil
+}
+
+func (s *pgStore) head() (*follower.Cursor, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), pgTimeout)
+	defer cancel()
+
+	var (
+		number int64
+		hash   []byte
+	)
+	err := s.db.QueryRowContext(ctx, "SELECT number, hash FROM blocks ORDER BY number DESC LIMIT 1").Scan(&number, &hash)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &follower.Cursor{Number: uint64(number), Hash: common.BytesToHash(hash)}, nil
+}
+
+func (s *pgStore) insert(blocks []*blockData) error {
+	ctx, cancel := context.WithTimeout(context.Background(), pgTimeout)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := insertBlocks(ctx, tx, blocks); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *pgStore) truncate(from uint64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), pgTimeout)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, "DELETE FROM blocks WHERE number >= $1", int64(from))
+	return err
+}
+
+func (s *pgStore) close() error {
+	return s.db.Close()
+}
+
+// sqlExecer is the part of a database transaction executing statements.
+type sqlExecer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// insertBlocks writes the rows of the blocks with parameterised statements.
+func insertBlocks(ctx context.Context, db sqlExecer, blocks []*blockData) error {
+	for _, b := range blocks {
+		if _, err := db.ExecContext(ctx, insertBlockSQL, blockRow(b)...); err != nil {
+			return err
+		}
+		for _, tx := range b.Transactions {
+			if _, err := db.ExecContext(ctx, insertTxSQL, txRow(b, tx)...); err != nil {
+				return err
+			}
+		}
+		for _, log := range b.Logs {
+			if _, err := db.ExecContext(ctx, insertLogSQL, logRow(b, log)...); err != nil {
+				return err
+			}
+		}
+		for _, t := range b.Transfers {
+			if _, err := db.ExecContext(ctx, insertTransferSQL, transferRow(b, t)...); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func blockRow(b *blockData) []interface{} {
+	return []interface{}{
+		int64(b.Number), b.Hash[:], b.ParentHash[:], b.Coinbase[:],
+		int64(b.Time), int64(b.GasUsed), len(b.Transactions),
+	}
+}
+
+func txRow(b *blockData, tx *txData) []interface{} {
+	return []interface{}{
+		tx.Hash[:], int64(b.Number), tx.Index, tx.From[:], sqlAddress(tx.To),
+		sqlBig(tx.Value), int64(tx.Nonce), int64(tx.Gas), sqlBig(tx.GasPrice),
+		sqlAddress(tx.FeeCurrency), sqlAddress(tx.FeePayer), sqlAddress(tx.GatewayFeeRecipient), sqlBig(tx.GatewayFee),
+		sqlBytes(tx.Input), int64(tx.Status), int64(tx.GasUsed), sqlAddress(tx.ContractAddress),
+	}
+}
+
+func logRow(b *blockData, log *types.Log) []interface{} {
+	row := []interface{}{int64(b.Number), int64(log.Index), log.TxHash[:], log.Address[:]}
+	for i := 0; i < 4; i++ {
+		if i < len(log.Topics) {
+			row = append(row, log.Topics[i][:])
+		} else {
+			row = append(row, nil)
+		}
+	}
+	return append(row, sqlBytes(log.Data))
+}
+
+func transferRow(b *blockData, t *transferData) []interface{} {
+	return []interface{}{
+		int64(b.Number), int64(t.LogIndex), t.TxHash[:],
+		t.Token[:], t.From[:], t.To[:], sqlBig(t.Value),
+	}
+}
+
+// sqlBig encodes a number as the text of a numeric column, nil as NULL.
+func sqlBig(n *big.Int) interface{} {
+	if n == nil {
+		return nil
+	}
+	return n.String()
+}
+
+// sqlBytes encodes a non-nullable bytea, nil as empty.
+func sqlBytes(b []byte) []byte {
+	if b == nil {
+		return []byte{}
+	}
+	return b
+}
+
+// sqlAddress encodes an optional address, nil as NULL.
+func sqlAddress(addr *common.Address) interface{} {
+	if addr == nil {
+		return nil
+	}
+	return addr[:]
+}
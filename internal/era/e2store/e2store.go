@@ -0,0 +1,114 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package e2store implements the e2store container format: a flat sequence
+// of type-length-value entries.
+package e2store
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	// headerSize is the size of an entry header: type (2 bytes), length
+	// (4 bytes, little endian) and 2 reserved zero bytes.
+	headerSize = 8
+
+	// valueSizeLimit caps the value of a single entry to avoid allocating
+	// unbounded memory when reading corrupted files.
+	valueSizeLimit = 1024 * 1024 * 50
+)
+
+// errReserved is returned if the reserved bytes of an entry header are non-zero.
+var errReserved = errors.New("reserved bytes are non-zero")
+
+// Entry is a single type-length-value item of an e2store file.
+type Entry struct {
+	Type  uint16
+	Value []byte
+}
+
+// Writer appends entries to an underlying writer.
+type Writer struct {
+	w io.Writer
+}
+
+// NewWriter creates an entry writer on top of w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// Write appends an entry of the given type and returns the number of bytes
+// written, including the header.
+func (w *Writer) Write(typ uint16, value []byte) (int, error) {
+	var header [headerSize]byte
+	binary.LittleEndian.PutUint16(header[:2], typ)
+	binary.LittleEndian.PutUint32(header[2:6], uint32(len(value)))
+
+	n, err := w.w.Write(header[:])
+	if err != nil {
+		return n, err
+	}
+	m, err := w.w.Write(value)
+	return n + m, err
+}
+
+// Reader reads entries from an underlying random access reader.
+type Reader struct {
+	r io.ReaderAt
+}
+
+// NewReader creates an entry reader on top of r.
+func NewReader(r io.ReaderAt) *Reader {
+	return &Reader{r: r}
+}
+
+// ReadAt reads the entry starting at the given offset and returns it together
+// with its total length, including the header.
+func (r *Reader) ReadAt(off int64) (*Entry, int64, error) {
+	typ, length, err := r.ReadMetadataAt(off)
+	if err != nil {
+		return nil, 0, err
+	}
+	value := make([]byte, length)
+	if _, err := r.r.ReadAt(value, off+headerSize); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, 0, err
+	}
+	return &Entry{Type: typ, Value: value}, headerSize + int64(length), nil
+}
+
+// ReadMetadataAt reads the type and value length of the entry starting at the
+// given offset.
+func (r *Reader) ReadMetadataAt(off int64) (uint16, uint32, error) {
+	var header [headerSize]byte
+	if _, err := r.r.ReadAt(header[:], off); err != nil {
+		return 0, 0, err
+	}
+	if header[6] != 0 || header[7] != 0 {
+		return 0, 0, errReserved
+	}
+	length := binary.LittleEndian.Uint32(header[2:6])
+	if length > valueSizeLimit {
+		return 0, 0, fmt.Errorf("entry value too large: %d bytes", length)
+	}
+	return binary.LittleEndian.Uint16(header[:2]), length, nil
+}
@@ -0,0 +1,63 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package e2store
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestEntryRoundtrip(t *testing.T) {
+	entries := []Entry{
+		{Type: 0x3265, Value: nil},
+		{Type: 0x03, Value: []byte("header")},
+		{Type: 0x04, Value: bytes.Repeat([]byte{0xaa}, 1000)},
+	}
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	for _, entry := range entries {
+		if n, err := w.Write(entry.Type, entry.Value); err != nil {
+			t.Fatal(err)
+		} else if n != headerSize+len(entry.Value) {
+			t.Fatalf("written size mismatch: have %d, want %d", n, headerSize+len(entry.Value))
+		}
+	}
+	var (
+		r   = NewReader(bytes.NewReader(buf.Bytes()))
+		off int64
+	)
+	for i, want := range entries {
+		have, n, err := r.ReadAt(off)
+		if err != nil {
+			t.Fatalf("entry %d: %v", i, err)
+		}
+		if have.Type != want.Type || !bytes.Equal(have.Value, want.Value) {
+			t.Fatalf("entry %d mismatch: have %x/%x, want %x/%x", i, have.Type, have.Value, want.Type, want.Value)
+		}
+		off += n
+	}
+	if _, _, err := r.ReadAt(off); err != io.EOF {
+		t.Fatalf("read past end: have %v, want %v", err, io.EOF)
+	}
+	// Corrupt the reserved bytes of the first entry
+	blob := buf.Bytes()
+	blob[7] = 1
+	if _, _, err := NewReader(bytes.NewReader(blob)).ReadAt(0); err != errReserved {
+		t.Fatalf("reserved bytes check: have %v, want %v", err, errReserved)
+	}
+}
@@ -0,0 +1,398 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package era implements era1-style history archives: e2store files holding a
+// contiguous range of blocks with their receipts and total difficulties, which
+// are named after an accumulator committing to their contents, so they can be
+// distributed over untrusted channels (torrents, CDNs) and verified locally.
+//
+// An archive is laid out as
+//
+//	Version | (CompressedHeader | CompressedBody | CompressedReceipts | TotalDifficulty)* | Accumulator | BlockIndex
+//
+// where headers, bodies (including the Celo randomness and epoch SNARK data
+// that seal epochs) and receipts are snappy compressed RLP, and the block index
+// maps block numbers to the offset of their header entries.
+package era
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/internal/era/e2store"
+	"github.com/celo-org/celo-blockchain/rlp"
+	"github.com/golang/snappy"
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	TypeVersion            uint16 = 0x3265
+	TypeCompressedHeader   uint16 = 0x03
+	TypeCompressedBody     uint16 = 0x04
+	TypeCompressedReceipts uint16 = 0x05
+	TypeTotalDifficulty    uint16 = 0x06
+	TypeAccumulator        uint16 = 0x07
+	TypeBlockIndex         uint16 = 0x3266
+
+	// MaxEra1Size is the maximum number of blocks held by a single archive.
+	MaxEra1Size = 8192
+)
+
+var (
+	errEmptyArchive = errors.New("archive contains no blocks")
+	errTooManyBlock = fmt.Errorf("archive exceeds %d blocks", MaxEra1Size)
+)
+
+// Filename returns the content addressed name of the archive with the given
+// index for a network.
+func Filename(network string, epoch int, root common.Hash) string {
+	return fmt.Sprintf("%s-%05d-%s.era1", network, epoch, root.Hex()[2:10])
+}
+
+// ReadDir returns the sorted list of archive files of a network in dir.
+func ReadDir(dir, network string) ([]string, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, network+"-") && filepath.Ext(name) == ".era1" {
+			files = append(files, name)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// accumulator computes the commitment over the hashes and total difficulties
+// of a range of blocks.
+type accumulator struct {
+	hasher hash.Hash
+}
+
+func newAccumulator() *accumulator {
+	return &accumulator{hasher: sha3.NewLegacyKeccak256()}
+}
+
+// add commits a block to the accumulator.
+func (a *accumulator) add(hash common.Hash, td *big.Int) {
+	a.hasher.Write(hash[:])
+	a.hasher.Write(common.BigToHash(td).Bytes())
+}
+
+// root returns the accumulator commitment over all added blocks.
+func (a *accumulator) root() common.Hash {
+	return common.BytesToHash(a.hasher.Sum(nil))
+}
+
+// Builder writes an archive incrementally, one block at a time.
+type Builder struct {
+	w       *e2store.Writer
+	written uint64
+
+	start   *uint64
+	offsets []uint64
+	acc     *accumulator
+}
+
+// NewBuilder creates an archive builder writing to w.
+func NewBuilder(w io.Writer) *Builder {
+	return &Builder{w: e2store.NewWriter(w), acc: newAccumulator()}
+}
+
+// write appends a single entry, tracking the written length.
+func (b *Builder) write(typ uint16, value []byte) error {
+	n, err := b.w.Write(typ, value)
+	b.written += uint64(n)
+	return err
+}
+
+// writeCompressed appends the snappy compressed RLP encoding of val.
+func (b *Builder) writeCompressed(typ uint16, val interface{}) error {
+	blob, err := rlp.EncodeToBytes(val)
+	if err != nil {
+		return err
+	}
+	return b.write(typ, snappy.Encode(nil, blob))
+}
+
+// Add appends a block with its receipts and total difficulty to the archive.
+// Blocks must be added in ascending, contiguous order.
+func (b *Builder) Add(block *types.Block, receipts types.Receipts, td *big.Int) error {
+	if len(b.offsets) >= MaxEra1Size {
+		return errTooManyBlock
+	}
+	if b.start == nil {
+		if err := b.write(TypeVersion, nil); err != nil {
+			return err
+		}
+		number := block.NumberU64()
+		b.start = &number
+	} else if want := *b.start + uint64(len(b.offsets)); block.NumberU64() != want {
+		return fmt.Errorf("non-contiguous block: have #%d, want #%d", block.NumberU64(), want)
+	}
+	b.offsets = append(b.offsets, b.written)
+
+	if err := b.writeCompressed(TypeCompressedHeader, block.Header()); err != nil {
+		return err
+	}
+	if err := b.writeCompressed(TypeCompressedBody, block.Body()); err != nil {
+		return err
+	}
+	if err := b.writeCompressed(TypeCompressedReceipts, receipts); err != nil {
+		return err
+	}
+	if err := b.write(TypeTotalDifficulty, common.BigToHash(td).Bytes()); err != nil {
+		return err
+	}
+	b.acc.add(block.Hash(), td)
+	return nil
+}
+
+// Finalize writes the accumulator and the block index, returning the
+// accumulator root the archive should be named after.
+func (b *Builder) Finalize() (common.Hash, error) {
+	if b.start == nil {
+		return common.Hash{}, errEmptyArchive
+	}
+	root := b.acc.root()
+	if err := b.write(TypeAccumulator, root.Bytes()); err != nil {
+		return common.Hash{}, err
+	}
+	// Offsets in the index are relative to the start of the index entry
+	var (
+		count = uint64(len(b.offsets))
+		index = make([]byte, 16+8*count)
+		base  = int64(b.written)
+	)
+	binary.LittleEndian.PutUint64(index, *b.start)
+	for i, offset := range b.offsets {
+		binary.LittleEndian.PutUint64(index[8+i*8:], uint64(int64(offset)-base))
+	}
+	binary.LittleEndian.PutUint64(index[8+count*8:], count)
+	if err := b.write(TypeBlockIndex, index); err != nil {
+		return common.Hash{}, err
+	}
+	return root, nil
+}
+
+// ReadAtCloser is the file interface an archive is read from.
+type ReadAtCloser interface {
+	io.ReaderAt
+	io.Closer
+}
+
+// Era is a read-only view of an archive.
+type Era struct {
+	f ReadAtCloser
+	s *e2store.Reader
+
+	start   uint64
+	count   uint64
+	offsets []int64 // Absolute offsets of the header entries
+	indexAt int64   // Offset of the block index entry
+}
+
+// Open opens the archive at path.
+func Open(path string) (*Era, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	e, err := From(f, info.Size())
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return e, nil
+}
+
+// From opens an archive of the given size from an already open file.
+func From(f ReadAtCloser, size int64) (*Era, error) {
+	// The block count is the trailing 8 bytes of the file, which sizes the index
+	if size < 8 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	var tail [8]byte
+	if _, err := f.ReadAt(tail[:], size-8); err != nil {
+		return nil, err
+	}
+	count := binary.LittleEndian.Uint64(tail[:])
+	if count == 0 || count > MaxEra1Size {
+		return nil, fmt.Errorf("invalid archive block count %d", count)
+	}
+	var (
+		s       = e2store.NewReader(f)
+		indexAt = size - int64(16+8*count) - 8
+	)
+	entry, _, err := s.ReadAt(indexAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read block index: %v", err)
+	}
+	if entry.Type != TypeBlockIndex {
+		return nil, fmt.Errorf("unexpected entry type %#x, want block index", entry.Type)
+	}
+	e := &Era{
+		f:       f,
+		s:       s,
+		start:   binary.LittleEndian.Uint64(entry.Value),
+		count:   count,
+		offsets: make([]int64, count),
+		indexAt: indexAt,
+	}
+	for i := range e.offsets {
+		e.offsets[i] = indexAt + int64(binary.LittleEndian.Uint64(entry.Value[8+i*8:]))
+	}
+	return e, nil
+}
+
+// Close closes the underlying file.
+func (e *Era) Close() error {
+	return e.f.Close()
+}
+
+// Start returns the number of the first block in the archive.
+func (e *Era) Start() uint64 {
+	return e.start
+}
+
+// Count returns the number of blocks in the archive.
+func (e *Era) Count() uint64 {
+	return e.count
+}
+
+// readTuple reads the entries stored for the given block number.
+func (e *Era) readTuple(number uint64) ([]*e2store.Entry, error) {
+	if number < e.start || number >= e.start+e.count {
+		return nil, fmt.Errorf("block #%d out of archive range [%d, %d)", number, e.start, e.start+e.count)
+	}
+	var (
+		off     = e.offsets[number-e.start]
+		entries = make([]*e2store.Entry, 0, 4)
+	)
+	for _, typ := range []uint16{TypeCompressedHeader, TypeCompressedBody, TypeCompressedReceipts, TypeTotalDifficulty} {
+		entry, n, err := e.s.ReadAt(off)
+		if err != nil {
+			return nil, err
+		}
+		if entry.Type != typ {
+			return nil, fmt.Errorf("block #%d: unexpected entry type %#x, want %#x", number, entry.Type, typ)
+		}
+		entries = append(entries, entry)
+		off += n
+	}
+	return entries, nil
+}
+
+// decodeCompressed decompresses and decodes the value of an entry into val.
+func decodeCompressed(entry *e2store.Entry, val interface{}) error {
+	blob, err := snappy.Decode(nil, entry.Value)
+	if err != nil {
+		return err
+	}
+	return rlp.DecodeBytes(blob, val)
+}
+
+// GetBlockByNumber returns the block, its receipts and total difficulty.
+func (e *Era) GetBlockByNumber(number uint64) (*types.Block, types.Receipts, *big.Int, error) {
+	entries, err := e.readTuple(number)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var (
+		header   types.Header
+		body     types.Body
+		receipts types.Receipts
+	)
+	if err := decodeCompressed(entries[0], &header); err != nil {
+		return nil, nil, nil, fmt.Errorf("block #%d: invalid header: %v", number, err)
+	}
+	if err := decodeCompressed(entries[1], &body); err != nil {
+		return nil, nil, nil, fmt.Errorf("block #%d: invalid body: %v", number, err)
+	}
+	if err := decodeCompressed(entries[2], &receipts); err != nil {
+		return nil, nil, nil, fmt.Errorf("block #%d: invalid receipts: %v", number, err)
+	}
+	block := types.NewBlockWithHeader(&header).WithBody(body.Transactions, body.Randomness, body.EpochSnarkData)
+	return block, receipts, new(big.Int).SetBytes(entries[3].Value), nil
+}
+
+// Accumulator returns the accumulator root stored in the archive, which is
+// the entry right before the block index.
+func (e *Era) Accumulator() (common.Hash, error) {
+	entry, _, err := e.s.ReadAt(e.indexAt - 8 - common.HashLength)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if entry.Type != TypeAccumulator || len(entry.Value) != common.HashLength {
+		return common.Hash{}, fmt.Errorf("invalid accumulator entry type %#x", entry.Type)
+	}
+	return common.BytesToHash(entry.Value), nil
+}
+
+// Verify checks the internal consistency of the archive: every block must
+// match its transaction and receipt roots, link to its predecessor, and the
+// accumulator must commit to exactly the contained blocks. It returns the
+// verified accumulator root.
+func (e *Era) Verify() (common.Hash, error) {
+	var (
+		acc    = newAccumulator()
+		parent common.Hash
+	)
+	for number := e.start; number < e.start+e.count; number++ {
+		block, receipts, td, err := e.GetBlockByNumber(number)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		if number > e.start && block.ParentHash() != parent {
+			return common.Hash{}, fmt.Errorf("block #%d: parent hash mismatch", number)
+		}
+		if hash := types.DeriveSha(block.Transactions()); hash != block.TxHash() {
+			return common.Hash{}, fmt.Errorf("block #%d: transaction root mismatch", number)
+		}
+		if hash := types.DeriveSha(receipts); hash != block.ReceiptHash() {
+			return common.Hash{}, fmt.Errorf("block #%d: receipt root mismatch", number)
+		}
+		acc.add(block.Hash(), td)
+		parent = block.Hash()
+	}
+	want, err := e.Accumulator()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if root := acc.root(); root != want {
+		return common.Hash{}, fmt.Errorf("accumulator mismatch: have %x, want %x", root, want)
+	}
+	return want, nil
+}
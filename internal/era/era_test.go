@@ -0,0 +1,150 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package era
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/core/types"
+)
+
+// makeChain creates a linked chain of blocks, each with a single transaction
+// and receipt.
+func makeChain(start uint64, n int) ([]*types.Block, []types.Receipts) {
+	var (
+		blocks   []*types.Block
+		receipts []types.Receipts
+		parent   common.Hash
+	)
+	for i := 0; i < n; i++ {
+		number := start + uint64(i)
+		tx := types.NewTransaction(number, common.Address{0x01}, big.NewInt(1), 21000, big.NewInt(1), nil, nil, nil, nil)
+		receipt := &types.Receipt{Status: types.ReceiptStatusSuccessful, CumulativeGasUsed: 21000, Logs: []*types.Log{}}
+		header := &types.Header{ParentHash: parent, Number: new(big.Int).SetUint64(number), GasUsed: 21000}
+
+		block := types.NewBlock(header, []*types.Transaction{tx}, []*types.Receipt{receipt}, &types.Randomness{Revealed: common.Hash{byte(i)}})
+		blocks = append(blocks, block)
+		receipts = append(receipts, types.Receipts{receipt})
+		parent = block.Hash()
+	}
+	return blocks, receipts
+}
+
+func TestArchiveRoundtrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "era")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	blocks, receipts := makeChain(100, 16)
+
+	var buf bytes.Buffer
+	builder := NewBuilder(&buf)
+	for i, block := range blocks {
+		if err := builder.Add(block, receipts[i], big.NewInt(int64(block.NumberU64()+1))); err != nil {
+			t.Fatalf("failed to add block #%d: %v", block.NumberU64(), err)
+		}
+	}
+	if err := builder.Add(blocks[0], receipts[0], common.Big1); err == nil {
+		t.Fatalf("non-contiguous block accepted")
+	}
+	root, err := builder.Finalize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, Filename("test", 0, root))
+	if err := ioutil.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	e, err := Open(path)
+	if err != nil {
+		t.Fatalf("failed to open archive: %v", err)
+	}
+	defer e.Close()
+
+	if e.Start() != 100 || e.Count() != 16 {
+		t.Fatalf("range mismatch: have [%d, +%d), want [100, +16)", e.Start(), e.Count())
+	}
+	if have, err := e.Verify(); err != nil || have != root {
+		t.Fatalf("verification failed: have %x, want %x (%v)", have, root, err)
+	}
+	for i, want := range blocks {
+		block, rs, td, err := e.GetBlockByNumber(want.NumberU64())
+		if err != nil {
+			t.Fatalf("failed to read block #%d: %v", want.NumberU64(), err)
+		}
+		if block.Hash() != want.Hash() {
+			t.Fatalf("block #%d hash mismatch: have %x, want %x", want.NumberU64(), block.Hash(), want.Hash())
+		}
+		if block.Randomness().Revealed != want.Randomness().Revealed {
+			t.Fatalf("block #%d randomness mismatch", want.NumberU64())
+		}
+		if types.DeriveSha(rs) != types.DeriveSha(receipts[i]) {
+			t.Fatalf("block #%d receipts mismatch", want.NumberU64())
+		}
+		if td.Uint64() != want.NumberU64()+1 {
+			t.Fatalf("block #%d td mismatch: have %v, want %d", want.NumberU64(), td, want.NumberU64()+1)
+		}
+	}
+	if _, _, _, err := e.GetBlockByNumber(116); err == nil {
+		t.Fatalf("out of range block returned")
+	}
+	files, err := ReadDir(dir, "test")
+	if err != nil || len(files) != 1 || files[0] != filepath.Base(path) {
+		t.Fatalf("archive listing mismatch: %v, %v", files, err)
+	}
+}
+
+func TestArchiveTampering(t *testing.T) {
+	blocks, receipts := makeChain(0, 4)
+
+	var buf bytes.Buffer
+	builder := NewBuilder(&buf)
+	for i, block := range blocks {
+		// Drop the receipts of a block, which breaks its receipt root
+		rs := receipts[i]
+		if i == 2 {
+			rs = types.Receipts{}
+		}
+		if err := builder.Add(block, rs, common.Big1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := builder.Finalize(); err != nil {
+		t.Fatal(err)
+	}
+	e, err := From(nopCloser{bytes.NewReader(buf.Bytes())}, int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Verify(); err == nil {
+		t.Fatalf("tampered archive verified")
+	}
+}
+
+type nopCloser struct {
+	*bytes.Reader
+}
+
+func (nopCloser) Close() error { return nil }
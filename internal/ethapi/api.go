@@ -19,9 +19,12 @@ package ethapi
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -31,8 +34,11 @@ import (
 	"github.com/celo-org/celo-blockchain/common"
 	"github.com/celo-org/celo-blockchain/common/hexutil"
 	"github.com/celo-org/celo-blockchain/common/math"
+	"github.com/celo-org/celo-blockchain/contracts/blockchain_parameters"
 	"github.com/celo-org/celo-blockchain/contracts/currency"
+	gpm "github.com/celo-org/celo-blockchain/contracts/gasprice_minimum"
 	"github.com/celo-org/celo-blockchain/core"
+	"github.com/celo-org/celo-blockchain/core/rawdb"
 	"github.com/celo-org/celo-blockchain/core/types"
 	"github.com/celo-org/celo-blockchain/core/vm"
 	"github.com/celo-org/celo-blockchain/crypto"
@@ -73,6 +79,10 @@ func (s *PublicEthereumAPI) ProtocolVersion() hexutil.Uint {
 // - highestBlock:  block number of the highest block header this node has received from peers
 // - pulledStates:  number of state entries processed until now
 // - knownStates:   number of known state entries that still need to be pulled
+// - syncMode:      sync mode of the node (full, fast, light or lightest)
+// - stage:         sync stage holding the sync back (headers, bodies, receipts, stateHeal or epochProofs)
+// - stages:        current and highest block number reached by every stage of the sync mode, or state entries for stateHeal
+// - eta:           estimated number of seconds for the current stage to complete, omitted if unknown
 func (s *PublicEthereumAPI) Syncing() (interface{}, error) {
 	progress := s.b.Downloader().Progress()
 
@@ -80,14 +90,29 @@ func (s *PublicEthereumAPI) Syncing() (interface{}, error) {
 	if progress.CurrentBlock >= progress.HighestBlock {
 		return false, nil
 	}
+	status := s.b.Downloader().Status()
+	stages := make(map[string]interface{}, len(status.Stages))
+	for _, stage := range status.Stages {
+		stages[stage.Stage.String()] = map[string]interface{}{
+			"current": hexutil.Uint64(stage.Current),
+			"highest": hexutil.Uint64(stage.Highest),
+		}
+	}
 	// Otherwise gather the block sync stats
-	return map[string]interface{}{
+	fields := map[string]interface{}{
 		"startingBlock": hexutil.Uint64(progress.StartingBlock),
 		"currentBlock":  hexutil.Uint64(progress.CurrentBlock),
 		"highestBlock":  hexutil.Uint64(progress.HighestBlock),
 		"pulledStates":  hexutil.Uint64(progress.PulledStates),
 		"knownStates":   hexutil.Uint64(progress.KnownStates),
-	}, nil
+		"syncMode":      status.Mode.String(),
+		"stage":         status.Stage.String(),
+		"stages":        stages,
+	}
+	if status.ETA > 0 {
+		fields["eta"] = hexutil.Uint64(status.ETA / time.Second)
+	}
+	return fields, nil
 }
 
 // PublicTxPoolAPI offers and API for the transaction pool. It only operates on data that is non confidential.
@@ -499,12 +524,17 @@ func (s *PrivateAccountAPI) SignAndSendTransaction(ctx context.Context, args Sen
 // PublicBlockChainAPI provides an API to access the Ethereum blockchain.
 // It offers only methods that operate on public data that is freely available to anyone.
 type PublicBlockChainAPI struct {
-	b Backend
+	b     Backend
+	calls *callCache
 }
 
 // NewPublicBlockChainAPI creates a new Ethereum blockchain API.
 func NewPublicBlockChainAPI(b Backend) *PublicBlockChainAPI {
-	return &PublicBlockChainAPI{b}
+	api := &PublicBlockChainAPI{b: b}
+	if ttl, size := b.RPCCallCache(); ttl > 0 && size > 0 {
+		api.calls = newCallCache(ttl, size)
+	}
+	return api
 }
 
 // ChainId returns the chainID value for transaction replay protection.
@@ -512,6 +542,13 @@ func (s *PublicBlockChainAPI) ChainId() *hexutil.Big {
 	return (*hexutil.Big)(s.b.ChainConfig().ChainID)
 }
 
+// ChainConfig returns the chain configuration of the network, including the
+// chain ID, the fork blocks and the Istanbul parameters, so tooling need not
+// hardcode them per network.
+func (s *PublicBlockChainAPI) ChainConfig() *params.ChainConfig {
+	return s.b.ChainConfig()
+}
+
 // BlockNumber returns the block number of the chain head.
 func (s *PublicBlockChainAPI) BlockNumber() hexutil.Uint64 {
 	header, _ := s.b.HeaderByNumber(context.Background(), rpc.LatestBlockNumber) // latest header should always be available
@@ -639,6 +676,9 @@ func (s *PublicBlockChainAPI) GetBlockByNumber(ctx context.Context, number rpc.B
 		}
 		return response, err
 	}
+	if err != nil {
+		return s.forwardBlock(ctx, err, "eth_getBlockByNumber", number, fullTx)
+	}
 	return nil, err
 }
 
@@ -649,9 +689,30 @@ func (s *PublicBlockChainAPI) GetBlockByHash(ctx context.Context, hash common.Ha
 	if block != nil {
 		return s.rpcMarshalBlock(ctx, block, true, fullTx)
 	}
+	if err != nil {
+		return s.forwardBlock(ctx, err, "eth_getBlockByHash", hash, fullTx)
+	}
 	return nil, err
 }
 
+// forwardBlock requests a block pruned by the node from the archive node, if
+// proxied, returning it with its provenance.
+func (s *PublicBlockChainAPI) forwardBlock(ctx context.Context, err error, method string, args ...interface{}) (map[string]interface{}, error) {
+	result, err := s.b.ArchiveProxy().Forward(ctx, err, method, args...)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(result, &fields); err != nil || fields == nil {
+		return nil, err
+	}
+	block := make(map[string]interface{}, len(fields))
+	for name, value := range fields {
+		block[name] = value
+	}
+	return block, nil
+}
+
 // GetUncleByBlockNumberAndIndex returns the uncle block for the given block hash and index. When fullTx is true
 // all transactions in the block are returned in full detail, otherwise only the transaction hash is returned.
 func (s *PublicBlockChainAPI) GetUncleByBlockNumberAndIndex(ctx context.Context, blockNr rpc.BlockNumber, index hexutil.Uint) (map[string]interface{}, error) {
@@ -826,13 +887,13 @@ func DoCall(ctx context.Context, b Backend, args CallArgs, blockNrOrHash rpc.Blo
 	// Setup the gas pool (also for unmetered requests) and apply the message.
 	gp := new(core.GasPool).AddGas(math.MaxUint64)
 
-	result, err := core.ApplyMessageWithoutGasPriceMinimum(evm, msg, gp, b.NewEVMRunner(header, state))
+	result, err := core.ApplyMessageWithoutGasPriceMinimum(evm, msg, gp, b.NewEVMRunner(ctx, header, state))
 	if err := vmError(); err != nil {
 		return nil, err
 	}
 	// If the timer caused an abort, return an appropriate error message
 	if evm.Cancelled() {
-		return nil, fmt.Errorf("execution aborted (timeout = %v)", timeout)
+		return nil, &timeoutError{timeout}
 	}
 	if err != nil {
 		return result, fmt.Errorf("err: %w (supplied gas %d)", err, msg.Gas())
@@ -870,6 +931,21 @@ func (e *revertError) ErrorData() interface{} {
 	return e.reason
 }
 
+// timeoutError is an API error returned when an EVM execution is aborted for
+// exceeding its time allowance.
+type timeoutError struct {
+	timeout time.Duration
+}
+
+func (e *timeoutError) Error() string {
+	return fmt.Sprintf("execution aborted (timeout = %v)", e.timeout)
+}
+
+// ErrorCode returns the JSON error code for an aborted execution.
+func (e *timeoutError) ErrorCode() int {
+	return -32010
+}
+
 // Call executes the given transaction on the state for the given block number.
 //
 // Additionally, the caller can specify a batch of contract for fields overriding.
@@ -881,17 +957,38 @@ func (s *PublicBlockChainAPI) Call(ctx context.Context, args CallArgs, blockNrOr
 	if overrides != nil {
 		accounts = *overrides
 	}
-	result, err := DoCall(ctx, s.b, args, blockNrOrHash, accounts, vm.Config{}, 50*time.Second, s.b.RPCGasCap())
-	if err != nil {
-		return nil, err
+	call := func(blockNrOrHash rpc.BlockNumberOrHash) (hexutil.Bytes, bool, error) {
+		result, err := DoCall(ctx, s.b, args, blockNrOrHash, accounts, vm.Config{}, s.b.RPCEVMTimeout(), s.b.RPCGasCap())
+		if err != nil {
+			return nil, false, err
+		}
+		// If the result contains a revert reason, try to unpack and return it.
+		if len(result.Revert()) > 0 {
+			return nil, true, newRevertError(result)
+		}
+		return result.Return(), true, result.Err
 	}
-	// If the result contains a revert reason, try to unpack and return it.
-	if len(result.Revert()) > 0 {
-		return nil, newRevertError(result)
+	// Memoize calls on any block but the pending one, keyed by its hash
+	if number, ok := blockNrOrHash.Number(); s.calls == nil || (ok && number == rpc.PendingBlockNumber) {
+		ret, _, err := call(blockNrOrHash)
+		return ret, err
 	}
-	return result.Return(), result.Err
+	header, err := s.b.HeaderByNumberOrHash(ctx, blockNrOrHash)
+	if err != nil || header == nil {
+		ret, _, err := call(blockNrOrHash)
+		return ret, err
+	}
+	// Pin the block, as the one resolved by number may change meanwhile
+	hash := header.Hash()
+	return s.calls.do(ctx, callKey(hash, args, accounts), func() (hexutil.Bytes, bool, error) {
+		return call(rpc.BlockNumberOrHashWithHash(hash, false))
+	})
 }
 
+// estimateGasErrorRatio is the amount of overestimation DoEstimateGas accepts in
+// exchange for fewer executions of the call.
+const estimateGasErrorRatio = 0.015
+
 func DoEstimateGas(ctx context.Context, b Backend, args CallArgs, blockNrOrHash rpc.BlockNumberOrHash, gasCap uint64) (hexutil.Uint64, error) {
 	// Binary search the gas requirement, as it may be higher than the amount used
 	var (
@@ -920,11 +1017,14 @@ func DoEstimateGas(ctx context.Context, b Backend, args CallArgs, blockNrOrHash
 	// also fail with gas limit B, which may not be the case if the gas price is non-zero,
 	// depending on the account's balance.
 	args.GasPrice = nil
+	// Each execution of the call gets the full time allowance
+	timeout := b.RPCEVMTimeout()
+
 	// Create a helper to check if a gas allowance results in an executable transaction
 	executable := func(gas uint64) (bool, *core.ExecutionResult, error) {
 		args.Gas = (*hexutil.Uint64)(&gas)
 
-		result, err := DoCall(ctx, b, args, blockNrOrHash, nil, vm.Config{}, 0, gasCap)
+		result, err := DoCall(ctx, b, args, blockNrOrHash, nil, vm.Config{}, timeout, gasCap)
 		if err != nil {
 			if errors.Is(err, core.ErrIntrinsicGas) {
 				return true, nil, nil // Special case, raise gas limit
@@ -933,9 +1033,50 @@ func DoEstimateGas(ctx context.Context, b Backend, args CallArgs, blockNrOrHash
 		}
 		return result.Failed(), result, nil
 	}
-	// Execute the binary search and hone in on an executable gas limit
+	// Execute at the highest allowance first: if the call fails there, it fails
+	// at any lower allowance as well
+	failed, result, err := executable(hi)
+	if err != nil {
+		return 0, err
+	}
+	if failed {
+		if result != nil && result.Err != vm.ErrOutOfGas {
+			if len(result.Revert()) > 0 {
+				return 0, newRevertError(result)
+			}
+			return 0, result.Err
+		}
+		// Otherwise, the specified gas cap is too low
+		return 0, fmt.Errorf("gas required exceeds allowance (%d)", cap)
+	}
+	// The gas consumed before the refund is a lower bound of the requirement. It
+	// is usually enough once the call stipend and the 1/64th of the gas withheld
+	// by every nested call are added back, so try that before bisecting.
+	lo = result.UsedGas + result.RefundedGas - 1
+	optimistic := (result.UsedGas + result.RefundedGas + params.CallStipend) * 64 / 63
+	if optimistic < hi {
+		failed, _, err := executable(optimistic)
+		if err != nil {
+			return 0, err
+		}
+		if failed {
+			lo = optimistic
+		} else {
+			hi = optimistic
+		}
+	}
+	// Execute the binary search and hone in on an executable gas limit, until
+	// within the accepted overestimation
 	for lo+1 < hi {
+		if float64(hi-lo)/float64(hi) < estimateGasErrorRatio {
+			break
+		}
 		mid := (hi + lo) / 2
+		if mid > lo*2 {
+			// Most transactions need little more than the gas they consume, so
+			// skew the search towards the lower end
+			mid = lo * 2
+		}
 		failed, _, err := executable(mid)
 
 		// If the error is not nil(consensus error), it means the provided message
@@ -950,23 +1091,6 @@ func DoEstimateGas(ctx context.Context, b Backend, args CallArgs, blockNrOrHash
 			hi = mid
 		}
 	}
-	// Reject the transaction as invalid if it still fails at the highest allowance
-	if hi == cap {
-		failed, result, err := executable(hi)
-		if err != nil {
-			return 0, err
-		}
-		if failed {
-			if result != nil && result.Err != vm.ErrOutOfGas {
-				if len(result.Revert()) > 0 {
-					return 0, newRevertError(result)
-				}
-				return 0, result.Err
-			}
-			// Otherwise, the specified gas cap is too low
-			return 0, fmt.Errorf("gas required exceeds allowance (%d)", cap)
-		}
-	}
 	return hexutil.Uint64(hi), nil
 }
 
@@ -977,6 +1101,83 @@ func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (h
 	return DoEstimateGas(ctx, s.b, args, blockNrOrHash, s.b.RPCGasCap())
 }
 
+// PublicCeloAPI provides an API to access Celo specific information.
+type PublicCeloAPI struct {
+	b Backend
+}
+
+// NewPublicCeloAPI creates a new Celo protocol API.
+func NewPublicCeloAPI(b Backend) *PublicCeloAPI {
+	return &PublicCeloAPI{b}
+}
+
+// FeeEstimate is the expected fee of a transaction in its fee currency.
+type FeeEstimate struct {
+	FeeCurrency             *common.Address `json:"feeCurrency"` // Nil for CELO
+	Gas                     hexutil.Uint64  `json:"gas"`
+	IntrinsicFeeCurrencyGas hexutil.Uint64  `json:"intrinsicFeeCurrencyGas"` // Part of Gas charged for paying in a non-native currency
+	GasPrice                *hexutil.Big    `json:"gasPrice"`                // Gas price minimum unless given
+	GatewayFee              *hexutil.Big    `json:"gatewayFee"`
+	Fee                     *hexutil.Big    `json:"fee"`       // Total fee in the fee currency
+	FeeInCelo               *hexutil.Big    `json:"feeInCelo"` // Total fee converted to CELO at the current rate
+}
+
+// EstimateFee returns the full expected fee of the given transaction in its fee
+// currency: the gas estimate against the pending block, times the gas price or
+// the gas price minimum of the fee currency if unspecified, plus the gateway fee.
+// As when executed, the gateway fee only counts if a recipient is specified.
+func (s *PublicCeloAPI) EstimateFee(ctx context.Context, args CallArgs) (*FeeEstimate, error) {
+	gasPrice := args.GasPrice
+	gas, err := DoEstimateGas(ctx, s.b, args, rpc.BlockNumberOrHashWithNumber(rpc.PendingBlockNumber), s.b.RPCGasCap())
+	if err != nil {
+		return nil, err
+	}
+	state, header, err := s.b.StateAndHeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	vmRunner := s.b.NewEVMRunner(ctx, header, state)
+	if gasPrice == nil {
+		minimum, err := gpm.GetGasPriceMinimum(vmRunner, args.FeeCurrency)
+		if err != nil {
+			return nil, err
+		}
+		gasPrice = (*hexutil.Big)(minimum)
+	}
+	feeCurrency, err := currency.NewManager(vmRunner).GetCurrency(args.FeeCurrency)
+	if err != nil {
+		return nil, err
+	}
+	gatewayFee := new(big.Int)
+	if args.GatewayFeeRecipient != nil {
+		gatewayFee.Set(args.GatewayFee.ToInt())
+	}
+	estimate := &FeeEstimate{
+		FeeCurrency: args.FeeCurrency,
+		Gas:         gas,
+		GasPrice:    gasPrice,
+		GatewayFee:  (*hexutil.Big)(gatewayFee),
+	}
+	if args.FeeCurrency != nil {
+		estimate.IntrinsicFeeCurrencyGas = hexutil.Uint64(blockchain_parameters.GetIntrinsicGasForAlternativeFeeCurrencyOrDefault(vmRunner, s.b.ChainConfig().IntrinsicGasSchedule(header.Number)))
+	}
+	fee := types.Fee(gasPrice.ToInt(), uint64(gas), gatewayFee)
+	estimate.Fee = (*hexutil.Big)(fee)
+	estimate.FeeInCelo = (*hexutil.Big)(feeCurrency.ToCELO(fee))
+	return estimate, nil
+}
+
+// SendRawTransactionUntil adds the signed transaction to the transaction pool like
+// eth_sendRawTransaction, bounded by the given block: the pool drops it and stops
+// propagating it if it is not included by then. The bound is local to this node.
+func (s *PublicCeloAPI) SendRawTransactionUntil(ctx context.Context, encodedTx hexutil.Bytes, validUntil hexutil.Uint64) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := rlp.DecodeBytes(encodedTx, tx); err != nil {
+		return common.Hash{}, err
+	}
+	return SubmitTransactionUntil(ctx, s.b, tx, uint64(validUntil))
+}
+
 // ExecutionResult groups all structured logs emitted by the EVM
 // while replaying a transaction in debug mode as well as transaction
 // execution status, the amount of gas used and the return value
@@ -1101,16 +1302,17 @@ func RPCMarshalBlock(block *types.Block, inclTx bool, fullTx bool) (map[string]i
 	return fields, nil
 }
 
-// rpcMarshalHeader uses the generalized output filler, then adds the total difficulty field, which requires
-// a `PublicBlockchainAPI`.
+// rpcMarshalHeader uses the generalized output filler, then adds the total difficulty and base fee fields,
+// which require a `PublicBlockchainAPI`.
 func (s *PublicBlockChainAPI) rpcMarshalHeader(ctx context.Context, header *types.Header) map[string]interface{} {
 	fields := RPCMarshalHeader(header)
 	fields["totalDifficulty"] = (*hexutil.Big)(s.b.GetTd(ctx, header.Hash()))
+	addBaseFee(ctx, s.b, header, fields)
 	return fields
 }
 
-// rpcMarshalBlock uses the generalized output filler, then adds the total difficulty field, which requires
-// a `PublicBlockchainAPI`.
+// rpcMarshalBlock uses the generalized output filler, then adds the total difficulty and base fee fields,
+// which require a `PublicBlockchainAPI`.
 func (s *PublicBlockChainAPI) rpcMarshalBlock(ctx context.Context, b *types.Block, inclTx bool, fullTx bool) (map[string]interface{}, error) {
 	fields, err := RPCMarshalBlock(b, inclTx, fullTx)
 	if err != nil {
@@ -1119,6 +1321,7 @@ func (s *PublicBlockChainAPI) rpcMarshalBlock(ctx context.Context, b *types.Bloc
 	if inclTx {
 		fields["totalDifficulty"] = (*hexutil.Big)(s.b.GetTd(ctx, b.Hash()))
 	}
+	addBaseFee(ctx, s.b, b.Header(), fields)
 	return fields, err
 }
 
@@ -1141,6 +1344,10 @@ type RPCTransaction struct {
 	V                   *hexutil.Big    `json:"v"`
 	R                   *hexutil.Big    `json:"r"`
 	S                   *hexutil.Big    `json:"s"`
+	FeePayer            *common.Address `json:"feePayer,omitempty"` // Set for sponsored transactions
+	FeePayerV           *hexutil.Big    `json:"feePayerV,omitempty"`
+	FeePayerR           *hexutil.Big    `json:"feePayerR,omitempty"`
+	FeePayerS           *hexutil.Big    `json:"feePayerS,omitempty"`
 	EthCompatible       bool            `json:"ethCompatible"`
 }
 
@@ -1171,6 +1378,11 @@ func newRPCTransaction(tx *types.Transaction, blockHash common.Hash, blockNumber
 		S:                   (*hexutil.Big)(s),
 		EthCompatible:       tx.EthCompatible(),
 	}
+	if feePayer := tx.FeePayer(); feePayer != nil {
+		v, r, s := tx.RawFeePayerSignatureValues()
+		result.FeePayer = feePayer
+		result.FeePayerV, result.FeePayerR, result.FeePayerS = (*hexutil.Big)(v), (*hexutil.Big)(r), (*hexutil.Big)(s)
+	}
 	if blockHash != (common.Hash{}) {
 		result.BlockHash = &blockHash
 		result.BlockNumber = (*hexutil.Big)(new(big.Int).SetUint64(blockNumber))
@@ -1495,6 +1707,50 @@ func (args *SendTxArgs) setDefaults(ctx context.Context, b Backend) error {
 	return nil
 }
 
+// estimateGas estimates the gas of the transaction against the pending block.
+func (args *SendTxArgs) estimateGas(ctx context.Context, b Backend) (hexutil.Uint64, error) {
+	if err := args.checkEthCompatibility(); err != nil {
+		return 0, err
+	}
+	// For backwards-compatibility reason, we try both input and data
+	// but input is preferred.
+	input := args.Input
+	if input == nil {
+		input = args.Data
+	}
+	callArgs := CallArgs{
+		From:                &args.From,
+		To:                  args.To,
+		FeeCurrency:         args.FeeCurrency,
+		GatewayFeeRecipient: args.GatewayFeeRecipient,
+		Value:               args.Value,
+		Data:                input,
+		EthCompatible:       args.EthCompatible,
+	}
+	if args.GatewayFee != nil {
+		callArgs.GatewayFee = *args.GatewayFee
+	}
+	pendingBlockNr := rpc.BlockNumberOrHashWithNumber(rpc.PendingBlockNumber)
+	return DoEstimateGas(ctx, b, callArgs, pendingBlockNr, b.RPCGasCap())
+}
+
+// checkGasPriceMinimum returns an error if the gas price is below the gas price
+// minimum of the fee currency, as the transaction would not be mined.
+func (args *SendTxArgs) checkGasPriceMinimum(ctx context.Context, b Backend) error {
+	state, header, err := b.StateAndHeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if err != nil {
+		return err
+	}
+	minimum, err := gpm.GetGasPriceMinimum(b.NewEVMRunner(ctx, header, state), args.FeeCurrency)
+	if err != nil {
+		return err
+	}
+	if args.GasPrice.ToInt().Cmp(minimum) < 0 {
+		return fmt.Errorf("%w: have %v, minimum %v", core.ErrGasPriceDoesNotExceedMinimum, args.GasPrice.ToInt(), minimum)
+	}
+	return nil
+}
+
 func (args *SendTxArgs) checkEthCompatibility() error {
 	// Reject if Celo-only fields set when EthCompatible is true
 	if args.EthCompatible && !(args.FeeCurrency == nil && args.GatewayFeeRecipient == nil && (args.GatewayFee == nil || args.GatewayFee.ToInt().Sign() == 0)) {
@@ -1524,12 +1780,24 @@ func (args *SendTxArgs) toTransaction() *types.Transaction {
 
 // SubmitTransaction is a helper function that submits tx to txPool and logs a message.
 func SubmitTransaction(ctx context.Context, b Backend, tx *types.Transaction) (common.Hash, error) {
+	return submitTransaction(ctx, b, tx, b.SendTx)
+}
+
+// SubmitTransactionUntil is a helper function that submits tx to txPool, to be
+// dropped if not included by the validUntil block, and logs a message.
+func SubmitTransactionUntil(ctx context.Context, b Backend, tx *types.Transaction, validUntil uint64) (common.Hash, error) {
+	return submitTransaction(ctx, b, tx, func(ctx context.Context, tx *types.Transaction) error {
+		return b.SendTxUntil(ctx, tx, validUntil)
+	})
+}
+
+func submitTransaction(ctx context.Context, b Backend, tx *types.Transaction, send func(context.Context, *types.Transaction) error) (common.Hash, error) {
 	// If the transaction fee cap is already specified, ensure the
 	// fee of the given transaction is _reasonable_.
 	if err := checkFeeFromCeloTx(ctx, b, tx); err != nil {
 		return common.Hash{}, err
 	}
-	if err := b.SendTx(ctx, tx); err != nil {
+	if err := send(ctx, tx); err != nil {
 		return common.Hash{}, err
 	}
 	if tx.To() == nil {
@@ -1579,12 +1847,25 @@ func (s *PublicTransactionPoolAPI) SendTransaction(ctx context.Context, args Sen
 }
 
 // FillTransaction fills the defaults (nonce, gas, gasPrice) on a given unsigned transaction,
-// and returns it to the caller for further processing (signing + broadcast)
+// and returns it to the caller for further processing (signing + broadcast). Contrary to
+// SendTransaction, the gas is estimated against the pending block, including the intrinsic
+// gas of paying in a fee currency, and gas prices below the minimum of the fee currency
+// are rejected.
 func (s *PublicTransactionPoolAPI) FillTransaction(ctx context.Context, args SendTxArgs) (*SignTransactionResult, error) {
+	if args.Gas == nil {
+		gas, err := args.estimateGas(ctx, s.b)
+		if err != nil {
+			return nil, err
+		}
+		args.Gas = &gas
+	}
 	// Set some sanity defaults and terminate on failure
 	if err := args.setDefaults(ctx, s.b); err != nil {
 		return nil, err
 	}
+	if err := args.checkGasPriceMinimum(ctx, s.b); err != nil {
+		return nil, err
+	}
 	// Assemble the transaction and obtain rlp
 	tx := args.toTransaction()
 	data, err := rlp.EncodeToBytes(tx)
@@ -1819,6 +2100,41 @@ func (api *PrivateDebugAPI) ChaindbCompact() error {
 	return nil
 }
 
+// ChaindbBackup writes a consistent backup of the chain database to the given
+// absolute file path while the node keeps running. The backup can be restored
+// into a stopped node with the `geth db restore` command.
+func (api *PrivateDebugAPI) ChaindbBackup(file string) (*rawdb.BackupStats, error) {
+	if !filepath.IsAbs(file) {
+		return nil, fmt.Errorf("backup path %q is not absolute", file)
+	}
+	if _, err := os.Stat(file); err == nil {
+		return nil, fmt.Errorf("backup file %s already exists", file)
+	}
+	// Write into a temporary file first to never leave a partial backup behind
+	tmp := file + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("Backing up chain database", "file", file)
+	stats, err := rawdb.ExportBackup(out, api.b.ChainDb())
+	if err == nil {
+		err = out.Sync()
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+	if err := os.Rename(tmp, file); err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+	return stats, nil
+}
+
 // SetHead rewinds the head of the blockchain to a previous block.
 func (api *PrivateDebugAPI) SetHead(number hexutil.Uint64) {
 	api.b.SetHead(uint64(number))
@@ -0,0 +1,502 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/celo-org/celo-blockchain/accounts"
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/common/hexutil"
+	mockEngine "github.com/celo-org/celo-blockchain/consensus/consensustest"
+	"github.com/celo-org/celo-blockchain/contracts/abis"
+	"github.com/celo-org/celo-blockchain/contracts/testutil"
+	"github.com/celo-org/celo-blockchain/core"
+	"github.com/celo-org/celo-blockchain/core/rawdb"
+	"github.com/celo-org/celo-blockchain/core/state"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/core/vm"
+	"github.com/celo-org/celo-blockchain/core/vm/vmcontext"
+	"github.com/celo-org/celo-blockchain/params"
+	"github.com/celo-org/celo-blockchain/rpc"
+)
+
+var (
+	loopAddr   = common.HexToAddress("0x1000") // Loops until out of gas
+	refundAddr = common.HexToAddress("0x2000") // Clears three storage slots
+	workerAddr = common.HexToAddress("0x3000") // Sets three fresh storage slots
+	outerAddr  = common.HexToAddress("0x3001") // Forwards all gas to middleAddr
+	middleAddr = common.HexToAddress("0x3002") // Forwards all gas to workerAddr
+	revertAddr = common.HexToAddress("0x4000") // Reverts with 0xdeadbeef
+	testGasCap = uint64(10000000)
+
+	// Whitelisted fee currency of the mocked system calls, worth half a CELO
+	testFeeCurrency        = common.HexToAddress("0xfee")
+	testFeeCurrencyGas     = uint64(30000) // Intrinsic gas for paying in testFeeCurrency
+	testFeeCurrencyBalance = big.NewInt(1000000000000)
+	testGenesis            = core.GenesisAlloc{
+		// JUMPDEST PUSH1 0 JUMP
+		loopAddr: {Code: common.FromHex("0x5b600056"), Balance: common.Big0},
+		refundAddr: {
+			// SSTORE(1, 0) SSTORE(2, 0) SSTORE(3, 0)
+			Code:    common.FromHex("0x600060015560006002556000600355"),
+			Storage: map[common.Hash]common.Hash{{31: 1}: {31: 1}, {31: 2}: {31: 1}, {31: 3}: {31: 1}},
+			Balance: common.Big0,
+		},
+		// SSTORE(1, 1) SSTORE(2, 1) SSTORE(3, 1)
+		workerAddr: {Code: common.FromHex("0x600160015560016002556001600355"), Balance: common.Big0},
+		outerAddr:  {Code: forwarderCode(middleAddr), Balance: common.Big0},
+		middleAddr: {Code: forwarderCode(workerAddr), Balance: common.Big0},
+		// MSTORE(0, 0xdeadbeef) REVERT(28, 4)
+		revertAddr: {Code: common.FromHex("0x63deadbeef6000526004601cfd"), Balance: common.Big0},
+	}
+)
+
+// forwarderCode returns the code of a contract calling target with all its
+// gas, and reverting if the call fails.
+func forwarderCode(target common.Address) []byte {
+	code := common.FromHex("0x6000600060006000600073")
+	code = append(code, target.Bytes()...)
+	// GAS CALL PUSH1 0x29 JUMPI REVERT(0, 0) JUMPDEST STOP
+	return append(code, common.FromHex("0x5af160295760006000fd5b00")...)
+}
+
+// testBackend is an API backend over a local chain, implementing the methods
+// needed to execute calls and serve blocks. Other methods panic.
+type testBackend struct {
+	Backend
+	chain   *core.BlockChain
+	gasCap  uint64
+	timeout time.Duration
+
+	ethCompat       bool
+	gasPrice        *big.Int                            // Suggested gas price
+	gasPriceMinimum func(header *types.Header) *big.Int // Mocks the system calls if set, by the state's header
+
+	callCacheTTL  time.Duration
+	callCacheSize int
+}
+
+func newTestBackend(t *testing.T, blocks int) *testBackend {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		gspec   = core.Genesis{Config: params.IstanbulTestChainConfig, Alloc: testGenesis}
+		genesis = gspec.MustCommit(db)
+	)
+	chain, err := core.NewBlockChain(db, nil, gspec.Config, mockEngine.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	t.Cleanup(chain.Stop)
+
+	generated, _ := core.GenerateChain(gspec.Config, genesis, mockEngine.NewFaker(), db, blocks, nil)
+	if _, err := chain.InsertChain(generated); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	return &testBackend{chain: chain, gasCap: testGasCap}
+}
+
+func (b *testBackend) ChainConfig() *params.ChainConfig { return b.chain.Config() }
+func (b *testBackend) RPCGasCap() uint64                { return b.gasCap }
+func (b *testBackend) RPCEVMTimeout() time.Duration     { return b.timeout }
+func (b *testBackend) CurrentHeader() *types.Header     { return b.chain.CurrentHeader() }
+func (b *testBackend) CurrentBlock() *types.Block       { return b.chain.CurrentBlock() }
+func (b *testBackend) RPCEthCompatibility() bool        { return b.ethCompat }
+
+func (b *testBackend) RPCCallCache() (time.Duration, int) {
+	return b.callCacheTTL, b.callCacheSize
+}
+
+func (b *testBackend) AccountManager() *accounts.Manager {
+	return accounts.NewManager(&accounts.Config{})
+}
+
+func (b *testBackend) SuggestPrice(ctx context.Context, currencyAddress *common.Address) (*big.Int, error) {
+	return b.gasPrice, nil
+}
+
+func (b *testBackend) GetPoolNonce(ctx context.Context, addr common.Address) (uint64, error) {
+	state, err := b.chain.State()
+	if err != nil {
+		return 0, err
+	}
+	return state.GetNonce(addr), nil
+}
+
+func (b *testBackend) GatewayFeeRecipient() common.Address { return common.Address{} }
+
+func (b *testBackend) GetTd(ctx context.Context, hash common.Hash) *big.Int {
+	return b.chain.GetTdByHash(hash)
+}
+
+func (b *testBackend) GetBlockGasLimit(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) uint64 {
+	return params.DefaultGasLimit
+}
+
+func (b *testBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error) {
+	if number < 0 {
+		return b.chain.CurrentHeader(), nil
+	}
+	return b.chain.GetHeaderByNumber(uint64(number)), nil
+}
+
+func (b *testBackend) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	return b.chain.GetHeaderByHash(hash), nil
+}
+
+func (b *testBackend) BlockByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Block, error) {
+	if number < 0 {
+		return b.chain.CurrentBlock(), nil
+	}
+	return b.chain.GetBlockByNumber(uint64(number)), nil
+}
+
+func (b *testBackend) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	return b.chain.GetBlockByHash(hash), nil
+}
+
+func (b *testBackend) HeaderByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*types.Header, error) {
+	if number, ok := blockNrOrHash.Number(); ok {
+		return b.HeaderByNumber(ctx, number)
+	}
+	hash, _ := blockNrOrHash.Hash()
+	return b.HeaderByHash(ctx, hash)
+}
+
+func (b *testBackend) StateAndHeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*state.StateDB, *types.Header, error) {
+	header, _ := b.HeaderByNumber(ctx, number)
+	if header == nil {
+		return nil, nil, errors.New("header not found")
+	}
+	statedb, err := b.chain.StateAt(header.Root)
+	return statedb, header, err
+}
+
+func (b *testBackend) StateAndHeaderByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, error) {
+	header, _ := b.HeaderByNumberOrHash(ctx, blockNrOrHash)
+	if header == nil {
+		return nil, nil, errors.New("header not found")
+	}
+	statedb, err := b.chain.StateAt(header.Root)
+	return statedb, header, err
+}
+
+func (b *testBackend) GetEVM(ctx context.Context, msg core.Message, state *state.StateDB, header *types.Header) (*vm.EVM, func() error, error) {
+	context := core.NewEVMContext(msg, header, b.chain, nil)
+	return vm.NewEVM(context, state, b.chain.Config(), vm.Config{}), func() error { return nil }, nil
+}
+
+func (b *testBackend) NewEVMRunner(ctx context.Context, header *types.Header, state vm.StateDB) vm.EVMRunner {
+	if b.gasPriceMinimum != nil {
+		runner := testutil.NewMockEVMRunner()
+		registry := testutil.NewRegistryMock()
+		runner.RegisterContract(params.RegistrySmartContractAddress, registry)
+		registry.AddContract(params.GoldTokenRegistryId, common.HexToAddress("0xce10"))
+		registry.AddContract(params.GasPriceMinimumRegistryId, common.HexToAddress("0xd0"))
+		runner.RegisterContract(common.HexToAddress("0xd0"), testutil.NewSingleMethodContract(params.GasPriceMinimumRegistryId, "getGasPriceMinimum",
+			func(currency common.Address) *big.Int { return b.gasPriceMinimum(header) },
+		))
+		registry.AddContract(params.BlockchainParametersRegistryId, common.HexToAddress("0xd1"))
+		blockchainParameters := testutil.NewBlockchainParametersMock()
+		blockchainParameters.IntrinsicGasForAlternativeFeeCurrencyValue = new(big.Int).SetUint64(testFeeCurrencyGas)
+		runner.RegisterContract(common.HexToAddress("0xd1"), blockchainParameters)
+		registry.AddContract(params.FeeCurrencyWhitelistRegistryId, common.HexToAddress("0xd2"))
+		runner.RegisterContract(common.HexToAddress("0xd2"), testutil.NewSingleMethodContract(params.FeeCurrencyWhitelistRegistryId, "getWhitelist",
+			func() []common.Address { return []common.Address{testFeeCurrency} },
+		))
+		registry.AddContract(params.SortedOraclesRegistryId, common.HexToAddress("0xd3"))
+		runner.RegisterContract(common.HexToAddress("0xd3"), testutil.NewSingleMethodContract(params.SortedOraclesRegistryId, "medianRate",
+			func(currency common.Address) (*big.Int, *big.Int) { return big.NewInt(2), big.NewInt(1) },
+		))
+		token := testutil.NewContractMock(abis.ERC20, testTokenMock{})
+		runner.RegisterContract(testFeeCurrency, &token)
+		return runner
+	}
+	return vmcontext.NewEVMRunnerWithContext(ctx, b.chain, header, state)
+}
+
+// testTokenMock mocks the fee currency, in which every account holds
+// testFeeCurrencyBalance.
+type testTokenMock struct{}
+
+func (testTokenMock) BalanceOf(account common.Address) *big.Int { return testFeeCurrencyBalance }
+
+var latest = rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+
+// callArgs returns the arguments of a call to the given contract.
+func callArgs(to common.Address) CallArgs {
+	return CallArgs{To: &to}
+}
+
+// minimumGas returns the lowest gas allowance the call succeeds with.
+func minimumGas(t *testing.T, b Backend, args CallArgs) uint64 {
+	lo, hi := params.TxGas-1, testGasCap
+	for lo+1 < hi {
+		mid := (lo + hi) / 2
+		args.Gas = (*hexutil.Uint64)(&mid)
+		result, err := DoCall(context.Background(), b, args, latest, nil, vm.Config{}, 0, testGasCap)
+		if err != nil && !errors.Is(err, core.ErrIntrinsicGas) {
+			t.Fatalf("call failed with %d gas: %v", mid, err)
+		}
+		if err != nil || result.Failed() {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return hi
+}
+
+// Tests that gas estimates are enough for the calls, including those needing
+// more gas than they use, and overestimate them by no more than the accepted
+// error ratio.
+func TestEstimateGas(t *testing.T) {
+	b := newTestBackend(t, 1)
+
+	tests := []struct {
+		name     string
+		to       common.Address
+		overhead bool // Whether the call needs more gas than it uses
+	}{
+		{"transfer", common.HexToAddress("0xabcd"), false},
+		{"storage writes", workerAddr, false},
+		{"refunds", refundAddr, true},
+		{"63/64 of the gas forwarded", outerAddr, true},
+	}
+	for _, tt := range tests {
+		args := callArgs(tt.to)
+		estimate, err := DoEstimateGas(context.Background(), b, args, latest, testGasCap)
+		if err != nil {
+			t.Errorf("%s: failed to estimate gas: %v", tt.name, err)
+			continue
+		}
+		minimum := minimumGas(t, b, args)
+		if uint64(estimate) < minimum {
+			t.Errorf("%s: estimate %d below the required %d", tt.name, estimate, minimum)
+		}
+		if excess := float64(uint64(estimate)-minimum) / float64(estimate); excess > estimateGasErrorRatio {
+			t.Errorf("%s: estimate %d exceeds the required %d by %.2f%%", tt.name, estimate, minimum, excess*100)
+		}
+		args.Gas = (*hexutil.Uint64)(&minimum)
+		result, err := DoCall(context.Background(), b, args, latest, nil, vm.Config{}, 0, testGasCap)
+		if err != nil || result.Failed() {
+			t.Fatalf("%s: call failed with the required gas: %v", tt.name, err)
+		}
+		if overhead := result.UsedGas < minimum; overhead != tt.overhead {
+			t.Errorf("%s: call uses %d of the %d gas required", tt.name, result.UsedGas, minimum)
+		}
+	}
+}
+
+// Tests that calls reverting at the gas cap fail the estimate with the revert
+// data, instead of the gas cap being reported too low.
+func TestEstimateGasRevert(t *testing.T) {
+	b := newTestBackend(t, 1)
+
+	_, err := DoEstimateGas(context.Background(), b, callArgs(revertAddr), latest, testGasCap)
+	var revert *revertError
+	if !errors.As(err, &revert) {
+		t.Fatalf("error mismatch: have %v, want revert error", err)
+	}
+	if revert.ErrorCode() != 3 {
+		t.Errorf("error code mismatch: have %d, want %d", revert.ErrorCode(), 3)
+	}
+	if have := revert.ErrorData(); have != "0xdeadbeef" {
+		t.Errorf("revert data mismatch: have %v, want %v", have, "0xdeadbeef")
+	}
+}
+
+// Tests that calls running out of gas at the gas cap fail the estimate with
+// the gas cap.
+func TestEstimateGasCapTooLow(t *testing.T) {
+	b := newTestBackend(t, 1)
+
+	args := callArgs(workerAddr)
+	gas := hexutil.Uint64(50000)
+	args.Gas = &gas
+	_, err := DoEstimateGas(context.Background(), b, args, latest, testGasCap)
+	if err == nil || !strings.Contains(err.Error(), "gas required exceeds allowance (50000)") {
+		t.Fatalf("error mismatch: have %v, want gas required exceeds allowance", err)
+	}
+	// Capping the gas below the requirement fails alike
+	_, err = DoEstimateGas(context.Background(), b, callArgs(workerAddr), latest, 30000)
+	if err == nil || !strings.Contains(err.Error(), "gas required exceeds allowance (30000)") {
+		t.Fatalf("error mismatch: have %v, want gas required exceeds allowance", err)
+	}
+}
+
+// Tests that calls running past the EVM timeout are aborted with a dedicated
+// error code.
+func TestCallTimeout(t *testing.T) {
+	b := newTestBackend(t, 1)
+	b.gasCap, b.timeout = 0, 50*time.Millisecond
+	api := NewPublicBlockChainAPI(b)
+
+	args := callArgs(loopAddr)
+	gas := hexutil.Uint64(1 << 40)
+	args.Gas = &gas
+	start := time.Now()
+	_, err := api.Call(context.Background(), args, latest, nil)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("call not aborted in time: %v", elapsed)
+	}
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) {
+		t.Fatalf("error mismatch: have %v, want timeout error", err)
+	}
+	if rpcErr.ErrorCode() != -32010 {
+		t.Errorf("error code mismatch: have %d, want %d", rpcErr.ErrorCode(), -32010)
+	}
+	if !strings.Contains(err.Error(), "execution aborted (timeout = 50ms)") {
+		t.Errorf("error message mismatch: %v", err)
+	}
+	// Calls finishing in time are unaffected
+	if _, err := api.Call(context.Background(), callArgs(workerAddr), latest, nil); err != nil {
+		t.Errorf("call failed: %v", err)
+	}
+}
+
+// Tests that gas estimation aborts executions running past the EVM timeout,
+// while estimating calls finishing in time.
+func TestEstimateGasTimeout(t *testing.T) {
+	b := newTestBackend(t, 1)
+	b.gasCap, b.timeout = 0, 50*time.Millisecond
+
+	args := callArgs(loopAddr)
+	gas := hexutil.Uint64(1 << 40)
+	args.Gas = &gas
+	_, err := DoEstimateGas(context.Background(), b, args, latest, b.RPCGasCap())
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != -32010 {
+		t.Fatalf("error mismatch: have %v, want timeout error", err)
+	}
+	if _, err := DoEstimateGas(context.Background(), b, callArgs(outerAddr), latest, b.RPCGasCap()); err != nil {
+		t.Errorf("failed to estimate gas: %v", err)
+	}
+}
+
+// Tests that fee estimates price the estimated gas, including the intrinsic gas
+// of the fee currency, at the given gas price or the gas price minimum, and add
+// the gateway fee only if a recipient is specified.
+func TestEstimateFee(t *testing.T) {
+	var (
+		to        = common.Address{0x1}
+		recipient = common.Address{0x2}
+		currency  = testFeeCurrency
+		price     = hexutil.Big(*big.NewInt(5000))
+		gateway   = hexutil.Big(*big.NewInt(7000))
+	)
+	tests := []struct {
+		args           CallArgs
+		feeCurrencyGas uint64
+		gasPrice       int64
+		gatewayFee     int64
+		rate           int64 // Fee currency units per CELO
+	}{
+		// Gas price minimum at the head, in CELO
+		{args: CallArgs{To: &to}, gasPrice: 1004, rate: 1},
+		// Fee currency, converted to CELO at the median rate
+		{args: CallArgs{To: &to, FeeCurrency: &currency}, feeCurrencyGas: testFeeCurrencyGas, gasPrice: 1004, rate: 2},
+		// Explicit gas price
+		{args: CallArgs{To: &to, GasPrice: &price}, gasPrice: 5000, rate: 1},
+		{args: CallArgs{To: &to, FeeCurrency: &currency, GasPrice: &price}, feeCurrencyGas: testFeeCurrencyGas, gasPrice: 5000, rate: 2},
+		// Gateway fee, ignored without recipient
+		{args: CallArgs{To: &to, FeeCurrency: &currency, GatewayFee: gateway}, feeCurrencyGas: testFeeCurrencyGas, gasPrice: 1004, rate: 2},
+		{args: CallArgs{To: &to, FeeCurrency: &currency, GatewayFeeRecipient: &recipient, GatewayFee: gateway}, feeCurrencyGas: testFeeCurrencyGas, gasPrice: 1004, gatewayFee: 7000, rate: 2},
+	}
+	api := NewPublicCeloAPI(newEthCompatBackend(t, false))
+	for i, tt := range tests {
+		estimate, err := api.EstimateFee(context.Background(), tt.args)
+		if err != nil {
+			t.Fatalf("test %d: failed to estimate fee: %v", i, err)
+		}
+		// The estimate may exceed the intrinsic gas by the accepted error
+		minGas := params.TxGas + tt.feeCurrencyGas
+		if gas := uint64(estimate.Gas); gas < minGas || float64(gas-minGas)/float64(gas) >= estimateGasErrorRatio {
+			t.Errorf("test %d: gas mismatch: have %d, want %d", i, gas, minGas)
+		}
+		if uint64(estimate.IntrinsicFeeCurrencyGas) != tt.feeCurrencyGas {
+			t.Errorf("test %d: fee currency gas mismatch: have %d, want %d", i, estimate.IntrinsicFeeCurrencyGas, tt.feeCurrencyGas)
+		}
+		if estimate.GasPrice.ToInt().Int64() != tt.gasPrice {
+			t.Errorf("test %d: gas price mismatch: have %v, want %d", i, estimate.GasPrice, tt.gasPrice)
+		}
+		if estimate.GatewayFee.ToInt().Int64() != tt.gatewayFee {
+			t.Errorf("test %d: gateway fee mismatch: have %v, want %d", i, estimate.GatewayFee, tt.gatewayFee)
+		}
+		fee := tt.gasPrice*int64(estimate.Gas) + tt.gatewayFee
+		if estimate.Fee.ToInt().Int64() != fee {
+			t.Errorf("test %d: fee mismatch: have %v, want %d", i, estimate.Fee, fee)
+		}
+		if estimate.FeeInCelo.ToInt().Int64() != fee/tt.rate {
+			t.Errorf("test %d: fee in CELO mismatch: have %v, want %d", i, estimate.FeeInCelo, fee/tt.rate)
+		}
+	}
+}
+
+// Tests that filled transactions get their gas estimated, including the
+// intrinsic gas of the fee currency, and are rejected if priced below the gas
+// price minimum.
+func TestFillTransaction(t *testing.T) {
+	var (
+		to       = common.Address{0x1}
+		currency = testFeeCurrency
+		price    = func(price int64) *hexutil.Big { return (*hexutil.Big)(big.NewInt(price)) }
+	)
+	tests := []struct {
+		args     SendTxArgs
+		minGas   uint64
+		gasPrice int64
+		err      error
+	}{
+		// Suggested gas price
+		{args: SendTxArgs{To: &to}, minGas: params.TxGas, gasPrice: 5000},
+		{args: SendTxArgs{To: &to, FeeCurrency: &currency}, minGas: params.TxGas + testFeeCurrencyGas, gasPrice: 5000},
+		// Explicit gas price, down to the gas price minimum at the head
+		{args: SendTxArgs{To: &to, GasPrice: price(1004)}, minGas: params.TxGas, gasPrice: 1004},
+		{args: SendTxArgs{To: &to, FeeCurrency: &currency, GasPrice: price(1004)}, minGas: params.TxGas + testFeeCurrencyGas, gasPrice: 1004},
+		{args: SendTxArgs{To: &to, GasPrice: price(1003)}, err: core.ErrGasPriceDoesNotExceedMinimum},
+		{args: SendTxArgs{To: &to, FeeCurrency: &currency, GasPrice: price(1003)}, err: core.ErrGasPriceDoesNotExceedMinimum},
+	}
+	b := newEthCompatBackend(t, false)
+	b.gasPrice = big.NewInt(5000)
+	api := NewPublicTransactionPoolAPI(b, new(AddrLocker))
+	for i, tt := range tests {
+		result, err := api.FillTransaction(context.Background(), tt.args)
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+		if err != nil {
+			continue
+		}
+		// The estimate may exceed the intrinsic gas by the accepted error
+		if gas := result.Tx.Gas(); gas < tt.minGas || float64(gas-tt.minGas)/float64(gas) >= estimateGasErrorRatio {
+			t.Errorf("test %d: gas mismatch: have %d, want %d", i, gas, tt.minGas)
+		}
+		if result.Tx.GasPrice().Int64() != tt.gasPrice {
+			t.Errorf("test %d: gas price mismatch: have %v, want %d", i, result.Tx.GasPrice(), tt.gasPrice)
+		}
+		if feeCurrency := result.Tx.FeeCurrency(); (feeCurrency == nil) != (tt.args.FeeCurrency == nil) {
+			t.Errorf("test %d: fee currency mismatch: have %v, want %v", i, feeCurrency, tt.args.FeeCurrency)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/celo-org/celo-blockchain/common/hexutil"
+	"github.com/celo-org/celo-blockchain/log"
+	"github.com/celo-org/celo-blockchain/metrics"
+	"github.com/celo-org/celo-blockchain/rpc"
+)
+
+var (
+	archiveRequestMeter = metrics.NewRegisteredMeter("rpc/archive/requests", nil)
+	archiveFailureMeter = metrics.NewRegisteredMeter("rpc/archive/failures", nil)
+)
+
+// PrunedHistoryError is returned for the bodies and receipts of blocks older
+// than the history window of the node, pointing to an archive node if known.
+type PrunedHistoryError struct {
+	Number  uint64 // Number of the pruned block requested
+	Tail    uint64 // First block whose history is available
+	Archive string // Archive node to query instead, if any
+}
+
+func (e *PrunedHistoryError) Error() string {
+	if e.Archive != "" {
+		return fmt.Sprintf("block #%d pruned, history available from #%d, query the archive node at %s", e.Number, e.Tail, e.Archive)
+	}
+	return fmt.Sprintf("block #%d pruned, history available from #%d", e.Number, e.Tail)
+}
+
+// ErrorCode returns the JSON-RPC error code of pruned history.
+func (e *PrunedHistoryError) ErrorCode() int { return 4444 }
+
+// ErrorData returns the history tail and the archive node to query instead.
+func (e *PrunedHistoryError) ErrorData() interface{} {
+	data := map[string]interface{}{"historyTail": hexutil.Uint64(e.Tail)}
+	if e.Archive != "" {
+		data["archive"] = e.Archive
+	}
+	return data
+}
+
+// Provenance is attached to the results that were not served by the node
+// itself, but obtained from an archive node.
+type Provenance struct {
+	Source      string         `json:"source"`
+	Archive     string         `json:"archive"`
+	HistoryTail hexutil.Uint64 `json:"historyTail"`
+}
+
+// ArchiveProxy forwards the requests for history pruned by the node to an
+// archive node. A nil proxy forwards nothing.
+type ArchiveProxy struct {
+	url string
+
+	lock   sync.Mutex
+	client *rpc.Client // Connection to the archive node, dialed on first use
+}
+
+// NewArchiveProxy creates a proxy to the archive node at the given HTTP,
+// WebSocket or IPC endpoint.
+func NewArchiveProxy(url string) *ArchiveProxy {
+	return &ArchiveProxy{url: url}
+}
+
+// Close disconnects from the archive node.
+func (p *ArchiveProxy) Close() {
+	if p == nil {
+		return
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.client != nil {
+		p.client.Close()
+		p.client = nil
+	}
+}
+
+// dial returns the connection to the archive node.
+func (p *ArchiveProxy) dial(ctx context.Context) (*rpc.Client, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.client == nil {
+		client, err := rpc.DialContext(ctx, p.url)
+		if err != nil {
+			return nil, err
+		}
+		p.client = client
+	}
+	return p.client, nil
+}
+
+// Forward calls the given method on the archive node if err reports history
+// pruned by the node, returning the result with its provenance attached to it,
+// or to each of its elements if it is a list. Other errors, and pruned history
+// if the archive node cannot be queried, are returned as is.
+func (p *ArchiveProxy) Forward(ctx context.Context, err error, method string, args ...interface{}) (json.RawMessage, error) {
+	var pruned *PrunedHistoryError
+	if p == nil || !errors.As(err, &pruned) {
+		return nil, err
+	}
+	archiveRequestMeter.Mark(1)
+
+	client, dialErr := p.dial(ctx)
+	if dialErr != nil {
+		archiveFailureMeter.Mark(1)
+		log.Warn("Failed to connect to archive node", "url", p.url, "err", dialErr)
+		return nil, err
+	}
+	var result json.RawMessage
+	if callErr := client.CallContext(ctx, &result, method, args...); callErr != nil {
+		archiveFailureMeter.Mark(1)
+		log.Debug("Archive node request failed", "method", method, "err", callErr)
+		return nil, err
+	}
+	provenance := &Provenance{Source: "archive", Archive: pruned.Archive, HistoryTail: hexutil.Uint64(pruned.Tail)}
+	return withProvenance(result, provenance)
+}
+
+// withProvenance attaches the provenance to a JSON object, or to each object
+// of a JSON list. Other values are returned as is.
+func withProvenance(result json.RawMessage, provenance *Provenance) (json.RawMessage, error) {
+	var list []json.RawMessage
+	if err := json.Unmarshal(result, &list); err == nil {
+		for i, elem := range list {
+			if list[i], err = withProvenance(elem, provenance); err != nil {
+				return nil, err
+			}
+		}
+		return json.Marshal(list)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(result, &fields); err != nil || fields == nil {
+		return result, nil
+	}
+	blob, err := json.Marshal(provenance)
+	if err != nil {
+		return nil, err
+	}
+	fields["provenance"] = blob
+	return json.Marshal(fields)
+}
@@ -20,6 +20,7 @@ package ethapi
 import (
 	"context"
 	"math/big"
+	"time"
 
 	"github.com/celo-org/celo-blockchain/accounts"
 	"github.com/celo-org/celo-blockchain/common"
@@ -46,8 +47,12 @@ type Backend interface {
 	ChainDb() ethdb.Database
 	AccountManager() *accounts.Manager
 	ExtRPCEnabled() bool
-	RPCGasCap() uint64    // global gas cap for eth_call over rpc: DoS protection
-	RPCTxFeeCap() float64 // global tx fee cap for all transaction related APIs
+	RPCGasCap() uint64                  // global gas cap for eth_call over rpc: DoS protection
+	RPCEVMTimeout() time.Duration       // global timeout for eth_call over rpc: DoS protection
+	RPCTxFeeCap() float64               // global tx fee cap for all transaction related APIs
+	RPCEthCompatibility() bool          // whether EIP-1559 fee fields are served for Ethereum tooling
+	RPCCallCache() (time.Duration, int) // TTL and size of the eth_call result cache, disabled if either is zero
+	ArchiveProxy() *ArchiveProxy        // proxy for the requests on pruned history, nil if not proxied
 
 	// Blockchain API
 	SetHead(number uint64)
@@ -70,6 +75,7 @@ type Backend interface {
 
 	// Transaction pool API
 	SendTx(ctx context.Context, signedTx *types.Transaction) error
+	SendTxUntil(ctx context.Context, signedTx *types.Transaction, validUntil uint64) error
 	GetTransaction(ctx context.Context, txHash common.Hash) (*types.Transaction, common.Hash, uint64, uint64, error)
 	GetPoolTransactions() (types.Transactions, error)
 	GetPoolTransaction(txHash common.Hash) *types.Transaction
@@ -77,6 +83,7 @@ type Backend interface {
 	Stats() (pending int, queued int)
 	TxPoolContent() (map[common.Address]types.Transactions, map[common.Address]types.Transactions)
 	SubscribeNewTxsEvent(chan<- core.NewTxsEvent) event.Subscription
+	SubscribeTxPoolChangeEvent(chan<- core.TxPoolChangeEvent) event.Subscription
 
 	// Filter API
 	BloomStatus() (uint64, uint64)
@@ -92,13 +99,13 @@ type Backend interface {
 	GatewayFee() *big.Int
 	GetIntrinsicGasForAlternativeFeeCurrency(ctx context.Context) uint64
 	GetBlockGasLimit(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) uint64
-	NewEVMRunner(*types.Header, vm.StateDB) vm.EVMRunner
+	NewEVMRunner(context.Context, *types.Header, vm.StateDB) vm.EVMRunner // Runs system calls, aborting them once the context is done
 	Engine() consensus.Engine
 }
 
 func GetAPIs(apiBackend Backend) []rpc.API {
 	nonceLock := new(AddrLocker)
-	return []rpc.API{
+	apis := []rpc.API{
 		{
 			Namespace: "eth",
 			Version:   "1.0",
@@ -114,6 +121,11 @@ func GetAPIs(apiBackend Backend) []rpc.API {
 			Version:   "1.0",
 			Service:   NewPublicTransactionPoolAPI(apiBackend, nonceLock),
 			Public:    true,
+		}, {
+			Namespace: "celo",
+			Version:   "1.0",
+			Service:   NewPublicCeloAPI(apiBackend),
+			Public:    true,
 		}, {
 			Namespace: "txpool",
 			Version:   "1.0",
@@ -138,6 +150,20 @@ func GetAPIs(apiBackend Backend) []rpc.API {
 			Version:   "1.0",
 			Service:   NewPrivateAccountAPI(apiBackend, nonceLock),
 			Public:    false,
+		}, {
+			Namespace: "sidecar",
+			Version:   "1.0",
+			Service:   NewPrivateSidecarAPI(apiBackend, nonceLock),
+			Public:    false,
 		},
 	}
+	if apiBackend.RPCEthCompatibility() {
+		apis = append(apis, rpc.API{
+			Namespace: "eth",
+			Version:   "1.0",
+			Service:   NewPublicEthCompatAPI(apiBackend),
+			Public:    true,
+		})
+	}
+	return apis
 }
@@ -0,0 +1,135 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/common/hexutil"
+	"github.com/celo-org/celo-blockchain/crypto"
+	"github.com/celo-org/celo-blockchain/metrics"
+)
+
+var (
+	callCacheHitMeter  = metrics.NewRegisteredMeter("rpc/callcache/hits", nil)
+	callCacheMissMeter = metrics.NewRegisteredMeter("rpc/callcache/misses", nil)
+)
+
+// callResult is the outcome of a call, which may still be executing.
+type callResult struct {
+	ret     hexutil.Bytes
+	err     error
+	keep    bool // Whether the outcome is deterministic, hence memoized
+	expires time.Time
+	done    chan struct{} // Closed once the call is executed
+}
+
+// callCache memoizes the results of identical calls arriving in bursts, so
+// that only one of them executes in the EVM.
+//
+// Results are keyed by the call parameters and the hash of the block, so that
+// the opcodes reading the state or the block context (SLOAD, BALANCE, NUMBER,
+// TIMESTAMP, BLOCKHASH...) are guaranteed to see the same values. Calls on the
+// pending block, whose state changes with every pooled transaction, are never
+// memoized. Only deterministic outcomes are kept: returned data and reverts, but
+// not timeouts or failures to load the state.
+type callCache struct {
+	ttl   time.Duration // How long the result of a call is reused for
+	limit int           // Maximum number of call results kept
+
+	lock    sync.Mutex
+	results map[common.Hash]*callResult
+	now     func() time.Time
+}
+
+// newCallCache creates a call cache keeping at most limit results for ttl each.
+func newCallCache(ttl time.Duration, limit int) *callCache {
+	return &callCache{
+		ttl:     ttl,
+		limit:   limit,
+		results: make(map[common.Hash]*callResult),
+		now:     time.Now,
+	}
+}
+
+// callKey returns the key of a call with the given parameters on a block.
+func callKey(block common.Hash, args CallArgs, overrides map[common.Address]account) common.Hash {
+	// Marshalling cannot fail, as the parameters were unmarshalled from JSON
+	blob, _ := json.Marshal(struct {
+		Args      CallArgs
+		Overrides map[common.Address]account
+	}{args, overrides})
+	return crypto.Keccak256Hash(block[:], blob)
+}
+
+// do returns the memoized result of the call with the given key, executing it
+// unless identical calls are pending or completed recently. The call reports
+// whether its outcome is deterministic, failures such as timeouts not being
+// memoized.
+func (c *callCache) do(ctx context.Context, key common.Hash, call func() (hexutil.Bytes, bool, error)) (hexutil.Bytes, error) {
+	c.lock.Lock()
+	now := c.now()
+	if res, ok := c.results[key]; ok && (res.expires.IsZero() || now.Before(res.expires)) {
+		c.lock.Unlock()
+		callCacheHitMeter.Mark(1)
+
+		select {
+		case <-res.done:
+			if res.keep {
+				return res.ret, res.err
+			}
+			// The call failed for its own reasons, such as being cancelled
+			ret, _, err := call()
+			return ret, err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if len(c.results) >= c.limit {
+		for k, res := range c.results {
+			if !res.expires.IsZero() && !now.Before(res.expires) {
+				delete(c.results, k)
+			}
+		}
+	}
+	res := &callResult{done: make(chan struct{})}
+	cached := len(c.results) < c.limit
+	if cached {
+		c.results[key] = res
+	}
+	c.lock.Unlock()
+	callCacheMissMeter.Mark(1)
+
+	res.ret, res.keep, res.err = call()
+
+	c.lock.Lock()
+	if cached {
+		if res.keep {
+			res.expires = c.now().Add(c.ttl)
+		} else {
+			delete(c.results, key)
+		}
+	}
+	c.lock.Unlock()
+	close(res.done)
+
+	return res.ret, res.err
+}
@@ -0,0 +1,253 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/common/hexutil"
+	"github.com/celo-org/celo-blockchain/rpc"
+)
+
+// countingCall returns a call returning the given result, counting its
+// executions.
+func countingCall(executions *int32, ret hexutil.Bytes) func() (hexutil.Bytes, bool, error) {
+	return func() (hexutil.Bytes, bool, error) {
+		atomic.AddInt32(executions, 1)
+		return ret, true, nil
+	}
+}
+
+// Tests that results are reused within the TTL and executed again after it.
+func TestCallCacheExpiry(t *testing.T) {
+	var (
+		cache      = newCallCache(time.Second, 16)
+		now        = time.Unix(1000, 0)
+		key        = common.Hash{1}
+		executions int32
+	)
+	cache.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		ret, err := cache.do(context.Background(), key, countingCall(&executions, hexutil.Bytes{1}))
+		if err != nil || len(ret) != 1 || ret[0] != 1 {
+			t.Fatalf("call %d: result mismatch: have %x, %v", i, ret, err)
+		}
+		now = now.Add(400 * time.Millisecond)
+	}
+	if executions != 1 {
+		t.Fatalf("executions within the TTL mismatch: have %d, want 1", executions)
+	}
+	// The last call came 1.2s after the first one, past the TTL
+	if _, err := cache.do(context.Background(), key, countingCall(&executions, hexutil.Bytes{2})); err != nil {
+		t.Fatal(err)
+	}
+	if executions != 2 {
+		t.Fatalf("executions after the TTL mismatch: have %d, want 2", executions)
+	}
+	// Reverts are deterministic, hence reused as well
+	revert := errors.New("execution reverted")
+	for i := 0; i < 2; i++ {
+		_, err := cache.do(context.Background(), common.Hash{2}, func() (hexutil.Bytes, bool, error) {
+			atomic.AddInt32(&executions, 1)
+			return nil, true, revert
+		})
+		if err != revert {
+			t.Fatalf("call %d: error mismatch: have %v, want %v", i, err, revert)
+		}
+	}
+	if executions != 3 {
+		t.Fatalf("executions of reverting call mismatch: have %d, want 3", executions)
+	}
+}
+
+// Tests that identical calls arriving while one executes wait for its result
+// instead of executing.
+func TestCallCacheDeduplication(t *testing.T) {
+	var (
+		cache      = newCallCache(time.Minute, 16)
+		key        = common.Hash{1}
+		executions int32
+		release    = make(chan struct{})
+		started    = make(chan struct{})
+	)
+	go cache.do(context.Background(), key, func() (hexutil.Bytes, bool, error) {
+		atomic.AddInt32(&executions, 1)
+		close(started)
+		<-release
+		return hexutil.Bytes{42}, true, nil
+	})
+	<-started
+
+	var (
+		wg      sync.WaitGroup
+		results = make([]hexutil.Bytes, 8)
+	)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = cache.do(context.Background(), key, countingCall(&executions, hexutil.Bytes{0}))
+		}(i)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if executions != 1 {
+		t.Errorf("execution count mismatch: have %d, want 1", executions)
+	}
+	for i, ret := range results {
+		if len(ret) != 1 || ret[0] != 42 {
+			t.Errorf("caller %d: result mismatch: have %x, want 2a", i, ret)
+		}
+	}
+}
+
+// Tests that the failure of a call for its own reasons, such as its caller
+// timing out or going away, is neither reused nor handed to the callers
+// waiting on it, which execute the call themselves.
+func TestCallCacheFailedCaller(t *testing.T) {
+	var (
+		cache      = newCallCache(time.Minute, 16)
+		key        = common.Hash{1}
+		executions int32
+		release    = make(chan struct{})
+		started    = make(chan struct{})
+		failed     = make(chan error)
+	)
+	go func() {
+		_, err := cache.do(context.Background(), key, func() (hexutil.Bytes, bool, error) {
+			atomic.AddInt32(&executions, 1)
+			close(started)
+			<-release
+			return nil, false, context.Canceled
+		})
+		failed <- err
+	}()
+	<-started
+
+	waiter := make(chan hexutil.Bytes)
+	go func() {
+		ret, err := cache.do(context.Background(), key, countingCall(&executions, hexutil.Bytes{7}))
+		if err != nil {
+			t.Errorf("waiting caller failed: %v", err)
+		}
+		waiter <- ret
+	}()
+	// A waiting caller going away returns right away
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := cache.do(ctx, key, countingCall(&executions, nil)); err != context.Canceled {
+		t.Errorf("cancelled waiter error mismatch: have %v, want %v", err, context.Canceled)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	if err := <-failed; err != context.Canceled {
+		t.Errorf("first caller error mismatch: have %v, want %v", err, context.Canceled)
+	}
+	if ret := <-waiter; len(ret) != 1 || ret[0] != 7 {
+		t.Errorf("waiting caller result mismatch: have %x, want 07", ret)
+	}
+	if executions != 2 {
+		t.Errorf("execution count mismatch: have %d, want 2", executions)
+	}
+	// The failure is not memoized either
+	if _, err := cache.do(context.Background(), key, countingCall(&executions, hexutil.Bytes{8})); err != nil {
+		t.Fatal(err)
+	}
+	if executions != 3 {
+		t.Errorf("execution count after failure mismatch: have %d, want 3", executions)
+	}
+}
+
+// Tests that calls on the pending block bypass the cache, while overrides
+// and blocks are part of the key.
+func TestCallCacheKeys(t *testing.T) {
+	b := newTestBackend(t, 2)
+	b.callCacheTTL, b.callCacheSize = time.Minute, 16
+	api := NewPublicBlockChainAPI(b)
+
+	args := callArgs(workerAddr)
+	for i := 0; i < 2; i++ {
+		if _, err := api.Call(context.Background(), args, rpc.BlockNumberOrHashWithNumber(rpc.PendingBlockNumber), nil); err != nil {
+			t.Fatalf("pending call failed: %v", err)
+		}
+	}
+	if n := len(api.calls.results); n != 0 {
+		t.Fatalf("pending calls cached: have %d results", n)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := api.Call(context.Background(), args, latest, nil); err != nil {
+			t.Fatalf("latest call failed: %v", err)
+		}
+	}
+	if n := len(api.calls.results); n != 1 {
+		t.Fatalf("cached result count mismatch: have %d, want 1", n)
+	}
+	// The latest block resolves to its hash, sharing the key
+	hash := b.chain.CurrentBlock().Hash()
+	if _, err := api.Call(context.Background(), args, rpc.BlockNumberOrHashWithHash(hash, false), nil); err != nil {
+		t.Fatalf("call by hash failed: %v", err)
+	}
+	if n := len(api.calls.results); n != 1 {
+		t.Fatalf("cached result count mismatch after call by hash: have %d, want 1", n)
+	}
+	// Overriding the code changes the outcome, hence the key
+	code := hexutil.Bytes(common.FromHex("0x60ff60005260206000f3")) // RETURN(MSTORE(0, 0xff))
+	overrides := map[common.Address]account{workerAddr: {Code: &code}}
+	ret, err := api.Call(context.Background(), args, latest, &overrides)
+	if err != nil {
+		t.Fatalf("call with overrides failed: %v", err)
+	}
+	if len(ret) != 32 || ret[31] != 0xff {
+		t.Errorf("overridden result mismatch: have %x", ret)
+	}
+	if n := len(api.calls.results); n != 2 {
+		t.Fatalf("cached result count mismatch after overrides: have %d, want 2", n)
+	}
+	if callKey(hash, args, nil) == callKey(hash, args, overrides) {
+		t.Errorf("overrides not part of the key")
+	}
+	if callKey(hash, args, nil) == callKey(b.chain.CurrentBlock().ParentHash(), args, nil) {
+		t.Errorf("block not part of the key")
+	}
+}
+
+// Tests that the cache can be disabled.
+func TestCallCacheDisabled(t *testing.T) {
+	b := newTestBackend(t, 1)
+	b.callCacheTTL, b.callCacheSize = 0, 16
+	api := NewPublicBlockChainAPI(b)
+	if api.calls != nil {
+		t.Fatalf("cache enabled without TTL")
+	}
+	if _, err := api.Call(context.Background(), callArgs(workerAddr), latest, nil); err != nil {
+		t.Fatalf("uncached call failed: %v", err)
+	}
+	b.callCacheTTL, b.callCacheSize = time.Minute, 0
+	if NewPublicBlockChainAPI(b).calls != nil {
+		t.Fatalf("cache enabled without size")
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/common/hexutil"
+	"github.com/celo-org/celo-blockchain/contracts"
+	"github.com/celo-org/celo-blockchain/contracts/currency"
+	"github.com/celo-org/celo-blockchain/contracts/locked_gold"
+	"github.com/celo-org/celo-blockchain/core/vm"
+	"github.com/celo-org/celo-blockchain/crypto"
+	"github.com/celo-org/celo-blockchain/params"
+	"github.com/celo-org/celo-blockchain/rpc"
+)
+
+// maxMappingSlot is the highest storage slot searched for the mapping a core
+// contract getter reads from.
+const maxMappingSlot = 256
+
+// CoreContractProof is the merkle proof of a core contract storage entry
+// holding a value of an account.
+type CoreContractProof struct {
+	Contract   common.Address `json:"contract"`
+	Account    common.Address `json:"account"`
+	StorageKey common.Hash    `json:"storageKey"`
+	Value      *hexutil.Big   `json:"value"` // As returned by the contract, may differ from the stored value
+	Proof      *AccountResult `json:"proof"`
+}
+
+// storageReadRecorder records the storage keys of an account read through it.
+type storageReadRecorder struct {
+	vm.StateDB
+	address common.Address
+	reads   map[common.Hash]bool
+}
+
+func (r *storageReadRecorder) GetState(addr common.Address, key common.Hash) common.Hash {
+	if addr == r.address {
+		r.reads[key] = true
+	}
+	return r.StateDB.GetState(addr, key)
+}
+
+// mappingStorageKey returns the storage key of the entry of an address keyed
+// mapping declared at the given slot, as laid out by solidity.
+func mappingStorageKey(account common.Address, slot uint64) common.Hash {
+	return crypto.Keccak256Hash(
+		common.LeftPadBytes(account.Bytes(), 32),
+		common.LeftPadBytes(new(big.Int).SetUint64(slot).Bytes(), 32),
+	)
+}
+
+// GetStableTokenBalanceProof returns the merkle proof of the balance of the account
+// in the given stable token. The stored balance is denominated in units, the value
+// returned by balanceOf is adjusted for the token's inflation factor.
+func (s *PublicCeloAPI) GetStableTokenBalanceProof(ctx context.Context, token, account common.Address, blockNrOrHash rpc.BlockNumberOrHash) (*CoreContractProof, error) {
+	return s.coreContractProof(ctx, account, blockNrOrHash, func(vmRunner vm.EVMRunner) (common.Address, *big.Int, error) {
+		balance, err := currency.GetBalanceOf(vmRunner, account, token)
+		return token, balance, err
+	})
+}
+
+// GetLockedGoldProof returns the merkle proof of the nonvoting locked gold of the
+// account in the LockedGold contract.
+func (s *PublicCeloAPI) GetLockedGoldProof(ctx context.Context, account common.Address, blockNrOrHash rpc.BlockNumberOrHash) (*CoreContractProof, error) {
+	return s.coreContractProof(ctx, account, blockNrOrHash, func(vmRunner vm.EVMRunner) (common.Address, *big.Int, error) {
+		lockedGold, err := contracts.GetRegisteredAddress(vmRunner, params.LockedGoldRegistryId)
+		if err != nil {
+			return common.Address{}, nil, err
+		}
+		nonvoting, err := locked_gold.GetAccountNonvotingLockedGold(vmRunner, account)
+		return lockedGold, nonvoting, err
+	})
+}
+
+// coreContractProof calls a core contract getter of an account value and proves
+// the storage entry it was read from. Instead of depending on the layout of each
+// contract version, the entry is found among the storage keys the getter reads
+// as the key of an address keyed mapping.
+func (s *PublicCeloAPI) coreContractProof(ctx context.Context, account common.Address, blockNrOrHash rpc.BlockNumberOrHash, getter func(vm.EVMRunner) (common.Address, *big.Int, error)) (*CoreContractProof, error) {
+	state, header, err := s.b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	// Resolve the contract before recording reads from its storage
+	contract, _, err := getter(s.b.NewEVMRunner(ctx, header, state.Copy()))
+	if err != nil {
+		return nil, err
+	}
+	recorder := &storageReadRecorder{StateDB: state.Copy(), address: contract, reads: make(map[common.Hash]bool)}
+	_, value, err := getter(s.b.NewEVMRunner(ctx, header, recorder))
+	if err != nil {
+		return nil, err
+	}
+	var key *common.Hash
+	for slot := uint64(0); slot <= maxMappingSlot && key == nil; slot++ {
+		if k := mappingStorageKey(account, slot); recorder.reads[k] {
+			key = &k
+		}
+	}
+	if key == nil {
+		return nil, fmt.Errorf("storage of account %s not found in contract %s", account.Hex(), contract.Hex())
+	}
+	proof, err := NewPublicBlockChainAPI(s.b).GetProof(ctx, contract, []string{key.Hex()}, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	return &CoreContractProof{
+		Contract:   contract,
+		Account:    account,
+		StorageKey: *key,
+		Value:      (*hexutil.Big)(value),
+		Proof:      proof,
+	}, nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/celo-org/celo-blockchain/common/hexutil"
+	gpm "github.com/celo-org/celo-blockchain/contracts/gasprice_minimum"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/log"
+	"github.com/celo-org/celo-blockchain/rpc"
+)
+
+// PublicEthCompatAPI provides the EIP-1559 fee endpoints expected by Ethereum
+// tooling, mapping the base fee to the gas price minimum. It is only served if
+// enabled, as Celo transactions still pay a single gas price.
+type PublicEthCompatAPI struct {
+	b Backend
+}
+
+// NewPublicEthCompatAPI creates a new EIP-1559 compatibility API.
+func NewPublicEthCompatAPI(b Backend) *PublicEthCompatAPI {
+	return &PublicEthCompatAPI{b}
+}
+
+// MaxPriorityFeePerGas returns a suggestion for the fee paid on top of the base
+// fee, being the headroom of the suggested gas price over the gas price minimum.
+func (s *PublicEthCompatAPI) MaxPriorityFeePerGas(ctx context.Context) (*hexutil.Big, error) {
+	price, err := s.b.SuggestPrice(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	state, header, err := s.b.StateAndHeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	minimum, err := gpm.GetGasPriceMinimum(s.b.NewEVMRunner(ctx, header, state), nil)
+	if err != nil {
+		return nil, err
+	}
+	tip := new(big.Int).Sub(price, minimum)
+	if tip.Sign() < 0 {
+		tip.SetUint64(0)
+	}
+	return (*hexutil.Big)(tip), nil
+}
+
+// baseFee returns the gas price minimum in CELO the transactions of the block
+// with the given header paid against, which is read from the parent state.
+func baseFee(ctx context.Context, b Backend, header *types.Header) (*big.Int, error) {
+	state, parent, err := b.StateAndHeaderByNumberOrHash(ctx, rpc.BlockNumberOrHashWithHash(header.ParentHash, false))
+	if err != nil {
+		return nil, err
+	}
+	return gpm.GetGasPriceMinimum(b.NewEVMRunner(ctx, parent, state), nil)
+}
+
+// addBaseFee sets the baseFeePerGas field of a marshalled header or block if
+// EIP-1559 compatibility is enabled. The field is left out if the parent state
+// is unavailable, e.g. for the genesis block or pruned history.
+func addBaseFee(ctx context.Context, b Backend, header *types.Header, fields map[string]interface{}) {
+	if !b.RPCEthCompatibility() {
+		return
+	}
+	fee, err := baseFee(ctx, b, header)
+	if err != nil {
+		log.Debug("Failed to look up base fee", "number", header.Number, "hash", header.Hash(), "err", err)
+		return
+	}
+	fields["baseFeePerGas"] = (*hexutil.Big)(fee)
+}
@@ -0,0 +1,136 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/celo-org/celo-blockchain/common/hexutil"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/rpc"
+)
+
+// newEthCompatBackend returns a test backend whose gas price minimum at each
+// block is 1000 plus the block number.
+func newEthCompatBackend(t *testing.T, ethCompat bool) *testBackend {
+	b := newTestBackend(t, 4)
+	b.ethCompat = ethCompat
+	b.gasPriceMinimum = func(header *types.Header) *big.Int {
+		return new(big.Int).Add(big.NewInt(1000), header.Number)
+	}
+	return b
+}
+
+// Tests that the base fee is only served with EIP-1559 compatibility enabled,
+// as the gas price minimum of the parent state.
+func TestBaseFeePerGas(t *testing.T) {
+	for _, ethCompat := range []bool{false, true} {
+		var (
+			b   = newEthCompatBackend(t, ethCompat)
+			api = NewPublicBlockChainAPI(b)
+			ctx = context.Background()
+		)
+		for number := uint64(0); number <= 4; number++ {
+			block := b.chain.GetBlockByNumber(number)
+
+			header, err := api.GetHeaderByNumber(ctx, rpc.BlockNumber(number))
+			if err != nil {
+				t.Fatalf("ethcompat %v, block %d: failed to get header: %v", ethCompat, number, err)
+			}
+			byNumber, err := api.GetBlockByNumber(ctx, rpc.BlockNumber(number), false)
+			if err != nil {
+				t.Fatalf("ethcompat %v, block %d: failed to get block: %v", ethCompat, number, err)
+			}
+			byHash, err := api.GetBlockByHash(ctx, block.Hash(), true)
+			if err != nil {
+				t.Fatalf("ethcompat %v, block %d: failed to get block by hash: %v", ethCompat, number, err)
+			}
+			responses := []map[string]interface{}{header, api.GetHeaderByHash(ctx, block.Hash()), byNumber, byHash}
+			for i, fields := range responses {
+				fee, ok := fields["baseFeePerGas"]
+				switch {
+				case !ethCompat || number == 0:
+					// Disabled, or the genesis block which has no parent state
+					if ok {
+						t.Errorf("ethcompat %v, block %d, response %d: unexpected base fee %v", ethCompat, number, i, fee)
+					}
+				case !ok:
+					t.Errorf("ethcompat %v, block %d, response %d: base fee missing", ethCompat, number, i)
+				default:
+					if want := big.NewInt(int64(1000 + number - 1)); fee.(*hexutil.Big).ToInt().Cmp(want) != 0 {
+						t.Errorf("ethcompat %v, block %d, response %d: base fee mismatch: have %v, want %v", ethCompat, number, i, fee, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestMaxPriorityFeePerGas(t *testing.T) {
+	tests := []struct {
+		price *big.Int
+		tip   *big.Int
+	}{
+		{big.NewInt(5000), big.NewInt(5000 - 1004)}, // Headroom over the minimum at the head
+		{big.NewInt(1004), big.NewInt(0)},
+		{big.NewInt(500), big.NewInt(0)}, // Never negative
+	}
+	b := newEthCompatBackend(t, true)
+	api := NewPublicEthCompatAPI(b)
+	for i, tt := range tests {
+		b.gasPrice = tt.price
+		tip, err := api.MaxPriorityFeePerGas(context.Background())
+		if err != nil {
+			t.Fatalf("test %d: failed to get priority fee: %v", i, err)
+		}
+		if tip.ToInt().Cmp(tt.tip) != 0 {
+			t.Errorf("test %d: priority fee mismatch: have %v, want %v", i, tip, tt.tip)
+		}
+	}
+}
+
+// Tests that eth_maxPriorityFeePerGas is only served with EIP-1559
+// compatibility enabled.
+func TestEthCompatAPI(t *testing.T) {
+	for _, ethCompat := range []bool{false, true} {
+		b := newEthCompatBackend(t, ethCompat)
+		b.gasPrice = big.NewInt(5000)
+
+		server := rpc.NewServer()
+		for _, api := range GetAPIs(b) {
+			if err := server.RegisterName(api.Namespace, api.Service); err != nil {
+				t.Fatalf("failed to register %s API: %v", api.Namespace, err)
+			}
+		}
+		client := rpc.DialInProc(server)
+
+		var tip hexutil.Big
+		err := client.Call(&tip, "eth_maxPriorityFeePerGas")
+		switch {
+		case !ethCompat && err == nil:
+			t.Errorf("eth_maxPriorityFeePerGas served without ethcompat")
+		case ethCompat && err != nil:
+			t.Errorf("eth_maxPriorityFeePerGas failed: %v", err)
+		case ethCompat && tip.ToInt().Int64() != 5000-1004:
+			t.Errorf("priority fee mismatch: have %v, want %v", tip.ToInt(), 5000-1004)
+		}
+		client.Close()
+		server.Stop()
+	}
+}
@@ -0,0 +1,373 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/celo-org/celo-blockchain/accounts"
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/common/hexutil"
+	"github.com/celo-org/celo-blockchain/core"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/rpc"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// sidecarTxRate and sidecarTxBurst limit the transactions sent per second
+	// by every account through the sidecar API.
+	sidecarTxRate  = 10
+	sidecarTxBurst = 20
+
+	// defaultConfirmations is the depth confirmation subscriptions wait for
+	// unless specified.
+	defaultConfirmations = 1
+
+	// maxConfirmations bounds the depth of confirmation subscriptions.
+	maxConfirmations = 1024
+)
+
+var (
+	errSidecarRateLimited = errors.New("sending rate limit exceeded")
+	errUnknownSidecarTx   = errors.New("transaction not sent through the sidecar API or already mined")
+)
+
+// sidecarAccount tracks the transactions an account sent through the sidecar
+// API which are not mined yet.
+type sidecarAccount struct {
+	next    uint64                        // Nonce after the highest one assigned
+	pending map[uint64]*types.Transaction // Transactions sent, by nonce
+	limiter *rate.Limiter
+}
+
+// nonceManager assigns the nonces of the transactions sent through the sidecar
+// API. Unlike the pool nonce, it accounts for the transactions sent but not
+// yet pooled, and reuses the nonces of the ones dropped by the pool, so that
+// no gap stalls the transactions of an account.
+type nonceManager struct {
+	lock     sync.Mutex
+	accounts map[common.Address]*sidecarAccount
+}
+
+func newNonceManager() *nonceManager {
+	return &nonceManager{accounts: make(map[common.Address]*sidecarAccount)}
+}
+
+func (m *nonceManager) account(addr common.Address) *sidecarAccount {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	acc, ok := m.accounts[addr]
+	if !ok {
+		acc = &sidecarAccount{
+			pending: make(map[uint64]*types.Transaction),
+			limiter: rate.NewLimiter(sidecarTxRate, sidecarTxBurst),
+		}
+		m.accounts[addr] = acc
+	}
+	return acc
+}
+
+// nonce returns the nonce of the next transaction of the account, which must
+// be locked by the caller.
+func (m *nonceManager) nonce(ctx context.Context, b Backend, addr common.Address) (uint64, error) {
+	acc := m.account(addr)
+
+	poolNonce, err := b.GetPoolNonce(ctx, addr)
+	if err != nil {
+		return 0, err
+	}
+	state, _, err := b.StateAndHeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if err != nil {
+		return 0, err
+	}
+	stateNonce := state.GetNonce(addr)
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for nonce := range acc.pending {
+		if nonce < stateNonce {
+			delete(acc.pending, nonce)
+		}
+	}
+	// Fill the first gap left by a transaction dropped by the pool
+	for nonce := poolNonce; nonce < acc.next; nonce++ {
+		if tx, ok := acc.pending[nonce]; ok && b.GetPoolTransaction(tx.Hash()) == nil {
+			return nonce, nil
+		}
+	}
+	if poolNonce > acc.next {
+		return poolNonce, nil
+	}
+	return acc.next, nil
+}
+
+// sent records a transaction sent by the account.
+func (m *nonceManager) sent(addr common.Address, tx *types.Transaction) {
+	acc := m.account(addr)
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	acc.pending[tx.Nonce()] = tx
+	if tx.Nonce() >= acc.next {
+		acc.next = tx.Nonce() + 1
+	}
+}
+
+// find returns a pending transaction sent through the sidecar API.
+func (m *nonceManager) find(hash common.Hash) (common.Address, *types.Transaction, bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for addr, acc := range m.accounts {
+		for _, tx := range acc.pending {
+			if tx.Hash() == hash {
+				return addr, tx, true
+			}
+		}
+	}
+	return common.Address{}, nil, false
+}
+
+// TxConfirmation is the status of a transaction notified to confirmation
+// subscriptions.
+type TxConfirmation struct {
+	TxHash        common.Hash     `json:"transactionHash"`
+	BlockHash     *common.Hash    `json:"blockHash"`   // Nil while pending
+	BlockNumber   *hexutil.Uint64 `json:"blockNumber"` // Nil while pending
+	Status        *hexutil.Uint64 `json:"status"`      // Receipt status, nil while pending
+	Confirmations hexutil.Uint64  `json:"confirmations"`
+	Final         bool            `json:"final"` // Whether the requested depth is reached, ending the subscription
+}
+
+// PrivateSidecarAPI provides the services running alongside a validator, such
+// as the attestation service and the oracles, with robust transaction sending:
+// nonces are managed by the node across concurrent senders and dropped
+// transactions, gas prices respect the minimum of the fee currency, sending is
+// rate limited per account and confirmations are pushed to subscribers.
+type PrivateSidecarAPI struct {
+	b         Backend
+	nonceLock *AddrLocker
+	nonces    *nonceManager
+}
+
+// NewPrivateSidecarAPI creates a new sidecar API.
+func NewPrivateSidecarAPI(b Backend, nonceLock *AddrLocker) *PrivateSidecarAPI {
+	return &PrivateSidecarAPI{b: b, nonceLock: nonceLock, nonces: newNonceManager()}
+}
+
+// SendTransaction signs the transaction with an unlocked account of the node
+// and submits it. The nonce is assigned by the node unless specified, the gas
+// price defaults to the suggested price in the fee currency and is rejected
+// below its gas price minimum, and the gas is estimated unless specified.
+func (api *PrivateSidecarAPI) SendTransaction(ctx context.Context, args SendTxArgs) (common.Hash, error) {
+	if !api.nonces.account(args.From).limiter.Allow() {
+		return common.Hash{}, errSidecarRateLimited
+	}
+	api.nonceLock.LockAddr(args.From)
+	defer api.nonceLock.UnlockAddr(args.From)
+
+	if args.Nonce == nil {
+		nonce, err := api.nonces.nonce(ctx, api.b, args.From)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		args.Nonce = (*hexutil.Uint64)(&nonce)
+	}
+	if args.Gas == nil {
+		gas, err := args.estimateGas(ctx, api.b)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		args.Gas = &gas
+	}
+	if err := args.setDefaults(ctx, api.b); err != nil {
+		return common.Hash{}, err
+	}
+	if err := args.checkGasPriceMinimum(ctx, api.b); err != nil {
+		return common.Hash{}, err
+	}
+	return api.send(ctx, args.From, args.toTransaction())
+}
+
+// ReplaceTransaction resubmits a pending transaction sent through this API
+// with the same nonce and fee currency, and a gas price raised to at least the
+// given one, the price bump required by the pool and the suggested price. It
+// returns the hash of the replacement.
+func (api *PrivateSidecarAPI) ReplaceTransaction(ctx context.Context, hash common.Hash, gasPrice *hexutil.Big) (common.Hash, error) {
+	from, tx, ok := api.nonces.find(hash)
+	if !ok {
+		return common.Hash{}, errUnknownSidecarTx
+	}
+	if !api.nonces.account(from).limiter.Allow() {
+		return common.Hash{}, errSidecarRateLimited
+	}
+	api.nonceLock.LockAddr(from)
+	defer api.nonceLock.UnlockAddr(from)
+
+	// The pool only replaces transactions paying the bump in the same currency
+	price := new(big.Int).Mul(tx.GasPrice(), big.NewInt(100+int64(core.DefaultTxPoolConfig.PriceBump)))
+	price.Div(price, big.NewInt(100))
+	if gasPrice != nil && gasPrice.ToInt().Cmp(price) > 0 {
+		price.Set(gasPrice.ToInt())
+	}
+	suggested, err := api.b.SuggestPrice(ctx, tx.FeeCurrency())
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if suggested.Cmp(price) > 0 {
+		price = suggested
+	}
+	var (
+		nonce = hexutil.Uint64(tx.Nonce())
+		gas   = hexutil.Uint64(tx.Gas())
+		data  = hexutil.Bytes(tx.Data())
+	)
+	replacement := (&SendTxArgs{
+		From:                from,
+		To:                  tx.To(),
+		Gas:                 &gas,
+		GasPrice:            (*hexutil.Big)(price),
+		FeeCurrency:         tx.FeeCurrency(),
+		GatewayFeeRecipient: tx.GatewayFeeRecipient(),
+		GatewayFee:          (*hexutil.Big)(tx.GatewayFee()),
+		Value:               (*hexutil.Big)(tx.Value()),
+		Nonce:               &nonce,
+		EthCompatible:       tx.EthCompatible(),
+		Input:               &data,
+	}).toTransaction()
+	return api.send(ctx, from, replacement)
+}
+
+// send signs and submits the transaction, recording it once pooled.
+func (api *PrivateSidecarAPI) send(ctx context.Context, from common.Address, tx *types.Transaction) (common.Hash, error) {
+	account := accounts.Account{Address: from}
+	wallet, err := api.b.AccountManager().Find(account)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	signed, err := wallet.SignTx(account, tx, api.b.ChainConfig().ChainID)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	hash, err := SubmitTransaction(ctx, api.b, signed)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	api.nonces.sent(from, signed)
+	return hash, nil
+}
+
+// Confirmations creates a subscription notifying the status of a transaction
+// at every new head, until it is mined at the requested depth. A transaction
+// reorganised out of the chain is notified as pending again.
+func (api *PrivateSidecarAPI) Confirmations(ctx context.Context, hash common.Hash, depth *hexutil.Uint64) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	target := uint64(defaultConfirmations)
+	if depth != nil {
+		target = uint64(*depth)
+	}
+	if target == 0 || target > maxConfirmations {
+		return nil, fmt.Errorf("confirmation depth must be between 1 and %d", maxConfirmations)
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		heads := make(chan core.ChainHeadEvent, 16)
+		headSub := api.b.SubscribeChainHeadEvent(heads)
+		defer headSub.Unsubscribe()
+
+		var last *TxConfirmation
+		check := func(head *types.Header) bool {
+			status, err := api.confirmation(ctx, hash, head, target)
+			if err != nil {
+				return false
+			}
+			if last == nil || !sameConfirmation(status, last) {
+				notifier.Notify(rpcSub.ID, status)
+				last = status
+			}
+			return status.Final
+		}
+		if check(api.b.CurrentHeader()) {
+			return
+		}
+		for {
+			select {
+			case ev := <-heads:
+				if check(ev.Block.Header()) {
+					return
+				}
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
+
+// confirmation looks up the status of a transaction as of the given head.
+func (api *PrivateSidecarAPI) confirmation(ctx context.Context, hash common.Hash, head *types.Header, target uint64) (*TxConfirmation, error) {
+	status := &TxConfirmation{TxHash: hash}
+	tx, blockHash, blockNumber, index, err := api.b.GetTransaction(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if tx == nil || blockNumber > head.Number.Uint64() {
+		return status, nil
+	}
+	receipts, err := api.b.GetReceipts(ctx, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(receipts)) <= index {
+		return nil, fmt.Errorf("receipt of transaction %x not found", hash)
+	}
+	var (
+		number   = hexutil.Uint64(blockNumber)
+		receipt  = hexutil.Uint64(receipts[index].Status)
+		confirms = head.Number.Uint64() - blockNumber + 1
+	)
+	status.BlockHash, status.BlockNumber, status.Status = &blockHash, &number, &receipt
+	status.Confirmations = hexutil.Uint64(confirms)
+	status.Final = confirms >= target
+	return status, nil
+}
+
+// sameConfirmation returns whether two statuses report the same inclusion and
+// depth.
+func sameConfirmation(a, b *TxConfirmation) bool {
+	if (a.BlockHash == nil) != (b.BlockHash == nil) {
+		return false
+	}
+	if a.BlockHash != nil && *a.BlockHash != *b.BlockHash {
+		return false
+	}
+	return a.Confirmations == b.Confirmations && a.Final == b.Final
+}
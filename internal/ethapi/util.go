@@ -44,7 +44,7 @@ func newCurrencyManager(ctx context.Context, b Backend) (*currency.CurrencyManag
 		return nil, err
 	}
 
-	vmRunner := b.NewEVMRunner(header, stateDb)
+	vmRunner := b.NewEVMRunner(ctx, header, stateDb)
 	return currency.NewManager(vmRunner), nil
 }
 
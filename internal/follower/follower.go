@@ -0,0 +1,305 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package follower implements following the canonical chain block by block on
+// behalf of services mirroring it elsewhere, resuming from the last block they
+// handled and rewinding them when handled blocks are reorganised away.
+package follower
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/core"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/event"
+	"github.com/celo-org/celo-blockchain/log"
+	"github.com/celo-org/celo-blockchain/metrics"
+	"github.com/celo-org/celo-blockchain/rpc"
+)
+
+const (
+	// chainHeadChanSize is the size of channel listening to ChainHeadEvent.
+	chainHeadChanSize = 10
+
+	// retryInterval is the initial delay before following the chain again
+	// after a failure, doubled on every failure up to maxRetryInterval.
+	retryInterval    = time.Second
+	maxRetryInterval = time.Minute
+)
+
+var (
+	errMissingBlock = errors.New("block not found")
+	errStopped      = errors.New("follower stopped")
+)
+
+// Backend encompasses the functionality needed to follow the chain
+type Backend interface {
+	SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription
+	CurrentHeader() *types.Header
+	HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error)
+	HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error)
+	BlockByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Block, error)
+}
+
+// Cursor identifies a handled block.
+type Cursor struct {
+	Number uint64      `json:"number"`
+	Hash   common.Hash `json:"hash"`
+}
+
+// String implements fmt.Stringer.
+func (c *Cursor) String() string {
+	if c == nil {
+		return "none"
+	}
+	return fmt.Sprintf("#%d [%x]", c.Number, c.Hash.Bytes()[:4])
+}
+
+// Handler processes the blocks of the canonical chain in order. Its methods
+// are never called concurrently.
+type Handler interface {
+	// Head returns the last block handled, nil if none. It is called before
+	// following the chain and again after every failure, the state of the
+	// handler being unknown.
+	Head() (*Cursor, error)
+
+	// Handle processes consecutive canonical blocks following the head.
+	Handle(ctx context.Context, blocks []*types.Block) error
+
+	// Rewind reverts the handled blocks that left the canonical chain, given
+	// highest first. The ancestor is the highest handled block that is still
+	// canonical, nil if there is none or it can't be found.
+	Rewind(ctx context.Context, ancestor *Cursor, removed []common.Hash) error
+}
+
+// Config are the configuration parameters of a chain follower.
+type Config struct {
+	Start     uint64        // First block handled if none was handled before
+	BatchSize int           // Maximum number of blocks handled at once, one if unset
+	Failures  metrics.Meter // Meter marked on every failure, optional
+	Logger    log.Logger    // Logger reporting the failures and reorgs, the root logger if unset
+}
+
+// Follower feeds the canonical blocks to a handler as they are imported,
+// catching up on the blocks missed while it was not running and rewinding the
+// handler when handled blocks are reorganised away. Failures are retried with
+// exponential backoff.
+type Follower struct {
+	config  Config
+	backend Backend
+	handler Handler
+	log     log.Logger
+
+	head   *Cursor // Last handled block, nil if none
+	loaded bool    // Whether the head was loaded from the handler
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates a chain follower feeding the given handler.
+func New(backend Backend, handler Handler, config Config) *Follower {
+	if config.BatchSize <= 0 {
+		config.BatchSize = 1
+	}
+	logger := config.Logger
+	if logger == nil {
+		logger = log.Root()
+	}
+	return &Follower{
+		config:  config,
+		backend: backend,
+		handler: handler,
+		log:     logger,
+		quit:    make(chan struct{}),
+	}
+}
+
+// Start starts following the chain in the background.
+func (f *Follower) Start() {
+	var (
+		headCh  = make(chan core.ChainHeadEvent, chainHeadChanSize)
+		headSub = f.backend.SubscribeChainHeadEvent(headCh)
+	)
+	f.wg.Add(1)
+	go f.loop(headCh, headSub)
+}
+
+// Stop terminates the follower, waiting for the handler to return.
+func (f *Follower) Stop() {
+	close(f.quit)
+	f.wg.Wait()
+}
+
+// Head returns the last block handled, nil if none.
+func (f *Follower) Head() *Cursor {
+	return f.head
+}
+
+func (f *Follower) loop(headCh chan core.ChainHeadEvent, headSub event.Subscription) {
+	defer f.wg.Done()
+	defer headSub.Unsubscribe()
+
+	var (
+		retry = time.NewTimer(0)
+		delay = retryInterval
+	)
+	defer retry.Stop()
+
+	for {
+		select {
+		case <-headCh:
+		case <-retry.C:
+		case <-headSub.Err():
+			return
+		case <-f.quit:
+			return
+		}
+		if err := f.Follow(); err != nil {
+			if err == errStopped {
+				return
+			}
+			if f.config.Failures != nil {
+				f.config.Failures.Mark(1)
+			}
+			f.log.Warn("Failed to follow chain", "head", f.head, "retry", delay, "err", err)
+			retry.Reset(delay)
+			if delay *= 2; delay > maxRetryInterval {
+				delay = maxRetryInterval
+			}
+			continue
+		}
+		delay = retryInterval
+	}
+}
+
+// Follow handles the blocks after the last handled one, up to the current
+// head. On failure, the head is loaded from the handler again on the next call.
+func (f *Follower) Follow() error {
+	if !f.loaded {
+		head, err := f.handler.Head()
+		if err != nil {
+			return err
+		}
+		f.head, f.loaded = head, true
+	}
+	if err := f.follow(context.Background()); err != nil {
+		f.loaded = false
+		return err
+	}
+	return nil
+}
+
+func (f *Follower) follow(ctx context.Context) error {
+	if err := f.rewind(ctx); err != nil {
+		return err
+	}
+	head := f.backend.CurrentHeader().Number.Uint64()
+
+	next := f.config.Start
+	if f.head != nil {
+		next = f.head.Number + 1
+	}
+	for next <= head {
+		select {
+		case <-f.quit:
+			return errStopped
+		default:
+		}
+		var (
+			batch  []*types.Block
+			parent common.Hash
+		)
+		if f.head != nil {
+			parent = f.head.Hash
+		}
+		for ; next <= head && len(batch) < f.config.BatchSize; next++ {
+			block, err := f.backend.BlockByNumber(ctx, rpc.BlockNumber(next))
+			if err != nil {
+				return err
+			}
+			if block == nil {
+				return errMissingBlock
+			}
+			if parent != (common.Hash{}) && block.ParentHash() != parent {
+				// The chain was reorganised meanwhile, resume from the ancestor
+				break
+			}
+			batch = append(batch, block)
+			parent = block.Hash()
+		}
+		if len(batch) == 0 {
+			return f.follow(ctx)
+		}
+		if err := f.handler.Handle(ctx, batch); err != nil {
+			return err
+		}
+		last := batch[len(batch)-1]
+		f.head = &Cursor{Number: last.NumberU64(), Hash: last.Hash()}
+		next = f.head.Number + 1
+	}
+	return nil
+}
+
+// rewind walks back from the head to the canonical chain, rewinding the
+// handler if handled blocks were reorganised away.
+func (f *Follower) rewind(ctx context.Context) error {
+	if f.head == nil {
+		return nil
+	}
+	var (
+		number   = f.head.Number
+		hash     = f.head.Hash
+		ancestor *Cursor
+		removed  []common.Hash
+	)
+	for {
+		canonical, err := f.backend.HeaderByNumber(ctx, rpc.BlockNumber(number))
+		if err != nil {
+			return err
+		}
+		if canonical != nil && canonical.Hash() == hash {
+			ancestor = &Cursor{Number: number, Hash: hash}
+			break
+		}
+		removed = append(removed, hash)
+		if number == 0 {
+			break // Not even the genesis is canonical
+		}
+		header, err := f.backend.HeaderByHash(ctx, hash)
+		if err != nil {
+			return err
+		}
+		if header == nil {
+			break // Handled on another chain, the ancestor is unknown
+		}
+		hash, number = header.ParentHash, number-1
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+	if err := f.handler.Rewind(ctx, ancestor, removed); err != nil {
+		return err
+	}
+	f.log.Info("Handled blocks reorganised", "ancestor", ancestor, "removed", len(removed))
+	f.head = ancestor
+	return nil
+}
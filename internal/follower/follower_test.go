@@ -0,0 +1,161 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package follower
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/internal/follower/followertest"
+)
+
+// testHandler records the handled blocks, failing the given number of
+// batches first.
+type testHandler struct {
+	handled []*types.Block
+	batches []int
+	heads   int // Number of times the head was loaded
+
+	rewound  bool
+	ancestor *Cursor
+	removed  []common.Hash
+
+	fails int
+}
+
+func (h *testHandler) Head() (*Cursor, error) {
+	h.heads++
+	if len(h.handled) == 0 {
+		return nil, nil
+	}
+	last := h.handled[len(h.handled)-1]
+	return &Cursor{Number: last.NumberU64(), Hash: last.Hash()}, nil
+}
+
+func (h *testHandler) Handle(ctx context.Context, blocks []*types.Block) error {
+	if h.fails > 0 {
+		h.fails--
+		return errors.New("unavailable")
+	}
+	h.handled = append(h.handled, blocks...)
+	h.batches = append(h.batches, len(blocks))
+	return nil
+}
+
+func (h *testHandler) Rewind(ctx context.Context, ancestor *Cursor, removed []common.Hash) error {
+	h.rewound, h.ancestor, h.removed = true, ancestor, removed
+
+	keep := 0
+	if ancestor != nil {
+		for keep < len(h.handled) && h.handled[keep].NumberU64() <= ancestor.Number {
+			keep++
+		}
+	}
+	h.handled = h.handled[:keep]
+	return nil
+}
+
+// checkCanonical checks that the handled blocks are the canonical ones from
+// the given number on.
+func checkCanonical(t *testing.T, chain *followertest.Chain, h *testHandler, from uint64) {
+	t.Helper()
+	if want := len(chain.Canonical) - int(from); len(h.handled) != want {
+		t.Fatalf("handled block count mismatch: have %d, want %d", len(h.handled), want)
+	}
+	for i, block := range h.handled {
+		if want := chain.Canonical[from+uint64(i)]; block.Hash() != want.Hash() {
+			t.Errorf("handled block %d not canonical", block.NumberU64())
+		}
+	}
+}
+
+func TestFollow(t *testing.T) {
+	chain := followertest.NewChain()
+	chain.Extend(0, 7, 0)
+
+	handler := &testHandler{fails: 1}
+	f := New(chain, handler, Config{Start: 1, BatchSize: 3})
+
+	// Failed batches are handled again, reloading the head
+	if err := f.Follow(); err == nil {
+		t.Fatal("no error for failed batch")
+	}
+	if f.Head() != nil || len(handler.handled) != 0 {
+		t.Fatalf("head advanced by failed batch: %v", f.Head())
+	}
+	if err := f.Follow(); err != nil {
+		t.Fatalf("follow failed: %v", err)
+	}
+	if handler.heads != 2 {
+		t.Errorf("head load count mismatch: have %d, want 2", handler.heads)
+	}
+	checkCanonical(t, chain, handler, 1)
+	if len(handler.batches) != 3 || handler.batches[0] != 3 || handler.batches[2] != 1 {
+		t.Errorf("batches mismatch: have %v, want [3 3 1]", handler.batches)
+	}
+
+	// Handled blocks leaving the canonical chain are rewound, highest first
+	removed := []common.Hash{chain.Canonical[7].Hash(), chain.Canonical[6].Hash(), chain.Canonical[5].Hash()}
+	ancestor := chain.Canonical[4].Hash()
+	chain.Extend(4, 4, 1)
+
+	if err := f.Follow(); err != nil {
+		t.Fatalf("follow after reorg failed: %v", err)
+	}
+	if !handler.rewound || handler.ancestor == nil || handler.ancestor.Number != 4 || handler.ancestor.Hash != ancestor {
+		t.Fatalf("rewind ancestor mismatch: have %v", handler.ancestor)
+	}
+	if len(handler.removed) != len(removed) {
+		t.Fatalf("removed blocks mismatch: have %d, want %d", len(handler.removed), len(removed))
+	}
+	for i := range removed {
+		if handler.removed[i] != removed[i] {
+			t.Errorf("removed block %d mismatch: have %x, want %x", i, handler.removed[i], removed[i])
+		}
+	}
+	checkCanonical(t, chain, handler, 1)
+	if head := f.Head(); head.Number != 8 || head.Hash != chain.Canonical[8].Hash() {
+		t.Errorf("head mismatch after reorg: have %v", head)
+	}
+}
+
+// Tests that handled blocks whose ancestor can't be found rewind the handler
+// completely.
+func TestFollowUnknownAncestor(t *testing.T) {
+	chain := followertest.NewChain()
+	chain.Extend(0, 3, 0)
+
+	handler := &testHandler{}
+	f := New(chain, handler, Config{BatchSize: 10})
+	if err := f.Follow(); err != nil {
+		t.Fatalf("follow failed: %v", err)
+	}
+	forgotten := chain.Canonical[3].Hash()
+	chain.Extend(2, 2, 1)
+	chain.Forget(forgotten)
+
+	if err := f.Follow(); err != nil {
+		t.Fatalf("follow after reorg failed: %v", err)
+	}
+	if !handler.rewound || handler.ancestor != nil || len(handler.removed) != 1 || handler.removed[0] != forgotten {
+		t.Fatalf("rewind mismatch: have ancestor %v, removed %x", handler.ancestor, handler.removed)
+	}
+	checkCanonical(t, chain, handler, 0)
+}
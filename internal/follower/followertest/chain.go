@@ -0,0 +1,109 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package followertest implements an in-memory chain backing the tests of the
+// chain follower and of the services built on it.
+package followertest
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/core"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/event"
+	"github.com/celo-org/celo-blockchain/params"
+	"github.com/celo-org/celo-blockchain/rpc"
+)
+
+// Chain is a chain whose canonical blocks can be replaced. The headers of the
+// replaced blocks remain retrievable by hash.
+type Chain struct {
+	Canonical []*types.Block
+	Receipts  map[common.Hash]types.Receipts
+
+	headers map[common.Hash]*types.Header
+	feed    event.Feed
+}
+
+// NewChain creates a chain holding only an empty genesis block.
+func NewChain() *Chain {
+	c := &Chain{
+		Receipts: make(map[common.Hash]types.Receipts),
+		headers:  make(map[common.Hash]*types.Header),
+	}
+	c.Add(types.NewBlockWithHeader(&types.Header{Number: new(big.Int)}))
+	return c
+}
+
+// Add makes the block canonical, dropping the canonical blocks above it.
+func (c *Chain) Add(block *types.Block) {
+	c.Canonical = append(c.Canonical[:block.NumberU64()], block)
+	c.headers[block.Hash()] = block.Header()
+}
+
+// Extend appends n empty blocks to the canonical chain after the given number,
+// the extra data distinguishing them from the blocks replaced.
+func (c *Chain) Extend(after uint64, n int, extra byte) {
+	for i := 0; i < n; i++ {
+		parent := c.Canonical[after+uint64(i)]
+		c.Add(types.NewBlockWithHeader(&types.Header{
+			ParentHash: parent.Hash(),
+			Number:     new(big.Int).Add(parent.Number(), common.Big1),
+			Extra:      []byte{extra},
+		}))
+	}
+}
+
+// Forget drops the header of a replaced block, as if it was never seen.
+func (c *Chain) Forget(hash common.Hash) {
+	delete(c.headers, hash)
+}
+
+func (c *Chain) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription {
+	return c.feed.Subscribe(ch)
+}
+
+func (c *Chain) CurrentHeader() *types.Header {
+	return c.Canonical[len(c.Canonical)-1].Header()
+}
+
+func (c *Chain) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error) {
+	if int(number) >= len(c.Canonical) {
+		return nil, nil
+	}
+	return c.Canonical[number].Header(), nil
+}
+
+func (c *Chain) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	return c.headers[hash], nil
+}
+
+func (c *Chain) BlockByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Block, error) {
+	if int(number) >= len(c.Canonical) {
+		return nil, nil
+	}
+	return c.Canonical[number], nil
+}
+
+func (c *Chain) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
+	return c.Receipts[hash], nil
+}
+
+func (c *Chain) ChainConfig() *params.ChainConfig {
+	return params.IstanbulTestChainConfig
+}
@@ -0,0 +1,105 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package lifecycle tracks the long running goroutines spawned by subsystems,
+// so that shutting a subsystem down can wait for all of them to exit and tests
+// can assert that starting and stopping it leaves none behind.
+package lifecycle
+
+import (
+	"sort"
+	"sync"
+)
+
+// registry holds the number of running goroutines of every manager, keyed by
+// "<manager>/<goroutine>".
+var registry = struct {
+	sync.Mutex
+	running map[string]int
+}{running: make(map[string]int)}
+
+// Manager tracks the goroutines of a subsystem. The subsystem remains in
+// charge of signalling them to exit, the manager allows waiting for them.
+type Manager struct {
+	name string
+	wg   sync.WaitGroup
+}
+
+// NewManager creates a manager whose goroutines are reported under the given
+// subsystem name.
+func NewManager(name string) *Manager {
+	return &Manager{name: name}
+}
+
+// Go runs fn in a new goroutine tracked under the given name. It must not be
+// called concurrently with Wait.
+func (m *Manager) Go(name string, fn func()) {
+	key := m.name + "/" + name
+	m.wg.Add(1)
+	track(key, 1)
+	go func() {
+		defer m.wg.Done()
+		defer track(key, -1)
+		fn()
+	}()
+}
+
+// Wait blocks until all goroutines started through the manager have exited.
+func (m *Manager) Wait() {
+	m.wg.Wait()
+}
+
+func track(key string, delta int) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.running[key] += delta
+	if registry.running[key] == 0 {
+		delete(registry.running, key)
+	}
+}
+
+// Running returns the names of all tracked goroutines that are still running,
+// with one entry per goroutine, in sorted order.
+func Running() []string {
+	registry.Lock()
+	defer registry.Unlock()
+	var names []string
+	for key, n := range registry.running {
+		for i := 0; i < n; i++ {
+			names = append(names, key)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Leaked returns the tracked goroutines that are running now but were not in
+// the given result of an earlier call to Running.
+func Leaked(before []string) []string {
+	seen := make(map[string]int)
+	for _, name := range before {
+		seen[name]++
+	}
+	var leaked []string
+	for _, name := range Running() {
+		if seen[name] > 0 {
+			seen[name]--
+			continue
+		}
+		leaked = append(leaked, name)
+	}
+	return leaked
+}
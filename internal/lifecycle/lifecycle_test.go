@@ -0,0 +1,42 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package lifecycle
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestManager(t *testing.T) {
+	before := Running()
+	m := NewManager("test")
+	quit := make(chan struct{})
+	for i := 0; i < 2; i++ {
+		m.Go("loop", func() { <-quit })
+	}
+	m.Go("other", func() { <-quit })
+
+	want := []string{"test/loop", "test/loop", "test/other"}
+	if leaked := Leaked(before); !reflect.DeepEqual(leaked, want) {
+		t.Fatalf("running goroutines mismatch: have %v, want %v", leaked, want)
+	}
+	close(quit)
+	m.Wait()
+	if leaked := Leaked(before); len(leaked) != 0 {
+		t.Errorf("goroutines still tracked after wait: %v", leaked)
+	}
+}
@@ -20,6 +20,7 @@ package web3ext
 var Modules = map[string]string{
 	"accounting": AccountingJs,
 	"admin":      AdminJs,
+	"celo":       CeloJs,
 	"chequebook": ChequebookJs,
 	"debug":      DebugJs,
 	"eth":        EthJs,
@@ -29,12 +30,70 @@ var Modules = map[string]string{
 	"personal":   PersonalJs,
 	"rpc":        RpcJs,
 	"shh":        ShhJs,
+	"sidecar":    SidecarJs,
 	"swarmfs":    SwarmfsJs,
 	"txpool":     TxpoolJs,
 	"les":        LESJs,
 	"lespay":     LESPayJs,
 }
 
+const CeloJs = `
+web3._extend({
+	property: 'celo',
+	methods:
+	[
+		new web3._extend.Method({
+			name: 'estimateFee',
+			call: 'celo_estimateFee',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputCallFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'epochStats',
+			call: 'celo_epochStats',
+			params: 1,
+			inputFormatter: [web3._extend.utils.fromDecimal]
+		}),
+		new web3._extend.Method({
+			name: 'getEpochRewards',
+			call: 'celo_getEpochRewards',
+			params: 1,
+			inputFormatter: [web3._extend.utils.fromDecimal]
+		}),
+		new web3._extend.Method({
+			name: 'getLockedGoldProof',
+			call: 'celo_getLockedGoldProof',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getRandomness',
+			call: 'celo_getRandomness',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getStableTokenBalanceProof',
+			call: 'celo_getStableTokenBalanceProof',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'sendRawTransactionUntil',
+			call: 'celo_sendRawTransactionUntil',
+			params: 2,
+			inputFormatter: [null, web3._extend.utils.fromDecimal]
+		}),
+	],
+	properties: [
+		new web3._extend.Property({
+			name: 'pendingHardforks',
+			getter: 'celo_pendingHardforks'
+		}),
+	]
+});
+`
+
 const ChequebookJs = `
 web3._extend({
 	property: 'chequebook',
@@ -112,6 +171,12 @@ web3._extend({
 			params: 4,
 			inputFormatter: [null, null, null, null]
 		}),
+		new web3._extend.Method({
+			name: 'setHTTPPolicy',
+			call: 'admin_setHTTPPolicy',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
 		new web3._extend.Method({
 			name: 'stopRPC',
 			call: 'admin_stopRPC'
@@ -126,6 +191,31 @@ web3._extend({
 			name: 'stopWS',
 			call: 'admin_stopWS'
 		}),
+		new web3._extend.Method({
+			name: 'addAPIKey',
+			call: 'admin_addAPIKey',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'removeAPIKey',
+			call: 'admin_removeAPIKey',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'listAPIKeys',
+			call: 'admin_listAPIKeys'
+		}),
+		new web3._extend.Method({
+			name: 'issueAPIToken',
+			call: 'admin_issueAPIToken',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
+		new web3._extend.Method({
+			name: 'drain',
+			call: 'admin_drain',
+			params: 1
+		}),
 	],
 	properties: [
 		new web3._extend.Property({
@@ -144,6 +234,10 @@ web3._extend({
 			name: 'peers',
 			getter: 'admin_peers'
 		}),
+		new web3._extend.Property({
+			name: 'forkStatus',
+			getter: 'admin_forkStatus'
+		}),
 	]
 });
 `
@@ -193,6 +287,11 @@ web3._extend({
 			name: 'chaindbCompact',
 			call: 'debug_chaindbCompact',
 		}),
+		new web3._extend.Method({
+			name: 'chaindbBackup',
+			call: 'debug_chaindbBackup',
+			params: 1,
+		}),
 		new web3._extend.Method({
 			name: 'verbosity',
 			call: 'debug_verbosity',
@@ -239,6 +338,11 @@ web3._extend({
 			call: 'debug_cpuProfile',
 			params: 2
 		}),
+		new web3._extend.Method({
+			name: 'captureDiagnostics',
+			call: 'debug_captureDiagnostics',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'startCPUProfile',
 			call: 'debug_startCPUProfile',
@@ -363,6 +467,35 @@ web3._extend({
 			call: 'debug_storageRangeAt',
 			params: 5,
 		}),
+		new web3._extend.Method({
+			name: 'profileGas',
+			call: 'debug_profileGas',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter, null]
+		}),
+		new web3._extend.Method({
+			name: 'simulateGovernanceExecution',
+			call: 'debug_simulateGovernanceExecution',
+			params: 2,
+			inputFormatter: [web3._extend.utils.fromDecimal, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'traceSamplingStats',
+			call: 'debug_traceSamplingStats',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'resetTraceSamplingStats',
+			call: 'debug_resetTraceSamplingStats',
+			params: 0,
+		}),
+		new web3._extend.Method({
+			name: 'executeWithWitness',
+			call: 'debug_executeWithWitness',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, null]
+		}),
 		new web3._extend.Method({
 			name: 'getModifiedAccountsByNumber',
 			call: 'debug_getModifiedAccountsByNumber',
@@ -394,6 +527,11 @@ web3._extend({
 			call: 'eth_chainId',
 			params: 0
 		}),
+		new web3._extend.Method({
+			name: 'chainConfig',
+			call: 'eth_chainConfig',
+			params: 0
+		}),
 		new web3._extend.Method({
 			name: 'sign',
 			call: 'eth_sign',
@@ -479,6 +617,12 @@ web3._extend({
 			call: 'eth_txFeeRecipient',
 			params: 0
 		}),
+		new web3._extend.Method({
+			name: 'maxPriorityFeePerGas',
+			call: 'eth_maxPriorityFeePerGas',
+			params: 0,
+			outputFormatter: web3._extend.formatters.outputBigNumberFormatter
+		}),
 	],
 	properties: [
 		new web3._extend.Property({
@@ -507,6 +651,12 @@ web3._extend({
 			params: 1,
 			inputFormatter: [web3._extend.formatters.inputAddressFormatter]
 		}),
+		new web3._extend.Method({
+			name: 'sendBundle',
+			call: 'miner_sendBundle',
+			params: 2,
+			inputFormatter: [null, web3._extend.utils.fromDecimal]
+		}),
 		new web3._extend.Method({
 			name: 'setExtra',
 			call: 'miner_setExtra',
@@ -643,6 +793,26 @@ web3._extend({
 });
 `
 
+const SidecarJs = `
+web3._extend({
+	property: 'sidecar',
+	methods: [
+		new web3._extend.Method({
+			name: 'sendTransaction',
+			call: 'sidecar_sendTransaction',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputTransactionFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'replaceTransaction',
+			call: 'sidecar_replaceTransaction',
+			params: 2,
+			inputFormatter: [null, web3._extend.utils.fromDecimal]
+		}),
+	]
+});
+`
+
 const SwarmfsJs = `
 web3._extend({
 	property: 'swarmfs',
@@ -749,6 +919,24 @@ web3._extend({
 			params: 1,
 			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
 		}),
+		new web3._extend.Method({
+			name: 'getBlockAttestation',
+			call: 'istanbul_getBlockAttestation',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'decodeExtra',
+			call: 'istanbul_decodeExtra',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'verifyPlumoProof',
+			call: 'istanbul_verifyPlumoProof',
+			params: 4,
+			inputFormatter: [null, null, web3._extend.utils.fromDecimal, web3._extend.utils.fromDecimal]
+		}),
 		new web3._extend.Method({
 			name: 'getValidators',
 			call: 'istanbul_getValidators',
@@ -809,6 +997,14 @@ web3._extend({
 			name: 'valEnodeTableInfo',
 			getter: 'istanbul_getValEnodeTable',
 		}),
+		new web3._extend.Property({
+			name: 'validatorLinks',
+			getter: 'istanbul_getValidatorLinks',
+		}),
+		new web3._extend.Property({
+			name: 'observedMessages',
+			getter: 'istanbul_observedMessages',
+		}),
 		new web3._extend.Property({
 			name: 'versionCertificateTableInfo',
 			getter: 'istanbul_getVersionCertificateTableInfo',
@@ -817,6 +1013,10 @@ web3._extend({
 			name: 'currentRoundState',
 			getter: 'istanbul_getCurrentRoundState',
 		}),
+		new web3._extend.Property({
+			name: 'epochSignatureStatus',
+			getter: 'istanbul_epochSignatureStatus',
+		}),
 		new web3._extend.Property({
 			name: 'proxies',
 			getter: 'istanbul_getProxiesInfo',
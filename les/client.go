@@ -106,13 +106,14 @@ func New(stack *node.Node, config *eth.Config) (*LightEthereum, error) {
 	}
 	log.Info("Initialised chain configuration", "config", chainConfig)
 
+	iConfig := indexerConfig(chainConfig, light.DefaultClientIndexerConfig)
 	peers := newServerPeerSet()
 	leth := &LightEthereum{
 		lesCommons: lesCommons{
 			genesis:     genesisHash,
 			config:      config,
 			chainConfig: chainConfig,
-			iConfig:     light.DefaultClientIndexerConfig,
+			iConfig:     iConfig,
 			chainDb:     chainDb,
 			closeCh:     make(chan struct{}),
 		},
@@ -139,12 +140,12 @@ func New(stack *node.Node, config *eth.Config) (*LightEthereum, error) {
 	leth.retriever = newRetrieveManager(peers, leth.reqDist, leth.serverPool.getTimeout)
 	leth.relay = newLesTxRelay(peers, leth.retriever)
 
-	leth.odr = NewLesOdr(chainDb, light.DefaultClientIndexerConfig, leth.retriever)
+	leth.odr = NewLesOdr(chainDb, iConfig, leth.retriever)
 	// If the full chain is not available then indexing each block header isn't possible.
 	if fullChainAvailable {
-		leth.bloomIndexer = eth.NewBloomIndexer(chainDb, params.BloomBitsBlocksClient, params.HelperTrieConfirmations, fullChainAvailable)
-		leth.chtIndexer = light.NewChtIndexer(chainDb, leth.odr, params.CHTFrequency, params.HelperTrieConfirmations, config.LightNoPrune, fullChainAvailable)
-		leth.bloomTrieIndexer = light.NewBloomTrieIndexer(chainDb, leth.odr, params.BloomBitsBlocksClient, params.BloomTrieFrequency, config.LightNoPrune, fullChainAvailable)
+		leth.bloomIndexer = eth.NewBloomIndexer(chainDb, iConfig.BloomSize, iConfig.BloomConfirms, fullChainAvailable)
+		leth.chtIndexer = light.NewChtIndexer(chainDb, leth.odr, iConfig.ChtSize, iConfig.ChtConfirms, config.LightNoPrune, fullChainAvailable)
+		leth.bloomTrieIndexer = light.NewBloomTrieIndexer(chainDb, leth.odr, iConfig.BloomSize, iConfig.BloomTrieSize, config.LightNoPrune, fullChainAvailable)
 	}
 	leth.odr.SetIndexers(leth.chtIndexer, leth.bloomTrieIndexer, leth.bloomIndexer)
 
@@ -55,6 +55,15 @@ type chainReader interface {
 	CurrentHeader() *types.Header
 }
 
+// indexerConfig returns the helper trie indexer config of base for the given
+// chain. Istanbul chains align their CHT and bloom trie sections to epochs.
+func indexerConfig(chainConfig *params.ChainConfig, base *light.IndexerConfig) *light.IndexerConfig {
+	if chainConfig.Istanbul == nil {
+		return base
+	}
+	return light.EpochAlignedIndexerConfig(base, chainConfig.Istanbul.Epoch, params.BloomBitsBlocks)
+}
+
 // lesCommons contains fields needed by both server and client.
 type lesCommons struct {
 	genesis                      common.Hash
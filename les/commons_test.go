@@ -0,0 +1,56 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package les
+
+import (
+	"testing"
+
+	"github.com/celo-org/celo-blockchain/consensus/istanbul"
+	"github.com/celo-org/celo-blockchain/light"
+	"github.com/celo-org/celo-blockchain/params"
+)
+
+// Tests that servers and clients of Celo networks agree on epoch aligned helper
+// trie sections that the server bloom bits sections fit into.
+func TestIndexerConfigEpochAlignment(t *testing.T) {
+	networks := map[string]*params.ChainConfig{
+		"mainnet":   params.MainnetChainConfig,
+		"baklava":   params.BaklavaChainConfig,
+		"alfajores": params.AlfajoresChainConfig,
+		"test":      params.IstanbulTestChainConfig,
+	}
+	for name, chainConfig := range networks {
+		server := indexerConfig(chainConfig, light.DefaultServerIndexerConfig)
+		client := indexerConfig(chainConfig, light.DefaultClientIndexerConfig)
+
+		if server.ChtSize != client.ChtSize || server.BloomTrieSize != client.BloomTrieSize {
+			t.Errorf("%s: server sections %d/%d, client sections %d/%d", name, server.ChtSize, server.BloomTrieSize, client.ChtSize, client.BloomTrieSize)
+		}
+		if client.BloomSize != client.BloomTrieSize {
+			t.Errorf("%s: client bloom section %d does not span bloom trie section %d", name, client.BloomSize, client.BloomTrieSize)
+		}
+		if server.BloomSize != params.BloomBitsBlocks || server.BloomTrieSize%server.BloomSize != 0 {
+			t.Errorf("%s: bloom trie section %d does not fit server bloom sections of %d", name, server.BloomTrieSize, server.BloomSize)
+		}
+		if !istanbul.IsLastBlockOfEpoch(server.ChtSize, chainConfig.Istanbul.Epoch) {
+			t.Errorf("%s: section 1 starts at block %d, not an epoch block", name, server.ChtSize)
+		}
+	}
+	if config := indexerConfig(&params.ChainConfig{}, light.DefaultServerIndexerConfig); config != light.DefaultServerIndexerConfig {
+		t.Errorf("non Istanbul chain config aligned: %+v", config)
+	}
+}
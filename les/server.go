@@ -31,7 +31,6 @@ import (
 	"github.com/celo-org/celo-blockchain/p2p/discv5"
 	"github.com/celo-org/celo-blockchain/p2p/enode"
 	"github.com/celo-org/celo-blockchain/p2p/enr"
-	"github.com/celo-org/celo-blockchain/params"
 	"github.com/celo-org/celo-blockchain/rpc"
 )
 
@@ -71,16 +70,17 @@ func NewLesServer(node *node.Node, e *eth.Ethereum, config *eth.Config) (*LesSer
 	if threads < 4 {
 		threads = 4
 	}
+	iConfig := indexerConfig(e.BlockChain().Config(), light.DefaultServerIndexerConfig)
 	srv := &LesServer{
 		lesCommons: lesCommons{
 			genesis:          e.BlockChain().Genesis().Hash(),
 			config:           config,
 			chainConfig:      e.BlockChain().Config(),
-			iConfig:          light.DefaultServerIndexerConfig,
+			iConfig:          iConfig,
 			chainDb:          e.ChainDb(),
 			chainReader:      e.BlockChain(),
-			chtIndexer:       light.NewChtIndexer(e.ChainDb(), nil, params.CHTFrequency, params.HelperTrieProcessConfirmations, true, true),
-			bloomTrieIndexer: light.NewBloomTrieIndexer(e.ChainDb(), nil, params.BloomBitsBlocks, params.BloomTrieFrequency, true, true),
+			chtIndexer:       light.NewChtIndexer(e.ChainDb(), nil, iConfig.ChtSize, iConfig.ChtConfirms, true, true),
+			bloomTrieIndexer: light.NewBloomTrieIndexer(e.ChainDb(), nil, iConfig.BloomSize, iConfig.BloomTrieSize, true, true),
 			closeCh:          make(chan struct{}),
 		},
 		archiveMode:  e.ArchiveMode(),
@@ -99,6 +99,38 @@ var (
 	}
 )
 
+// EpochAlignedIndexerConfig returns a copy of config with CHT and bloom trie
+// sections spanning a whole number of Istanbul epochs. Section i then starts
+// with the last block of an epoch and holds the same epoch blocks on every
+// node, so light clients can prove epoch headers against a single section.
+//
+// The section size is the smallest multiple of both the epoch size and the
+// server side bloom bits section size (bloomSize) not below config.ChtSize.
+// Client configs, whose bloom bits sections span a full bloom trie section,
+// have their bloom section size resized along with it. Servers and clients
+// must use the same epoch and bloomSize to agree on the section layout.
+func EpochAlignedIndexerConfig(config *IndexerConfig, epoch, bloomSize uint64) *IndexerConfig {
+	aligned := *config
+	if epoch == 0 {
+		return &aligned
+	}
+	step := epoch / gcd(epoch, bloomSize) * bloomSize
+	size := (config.ChtSize + step - 1) / step * step
+	if config.BloomSize == config.BloomTrieSize {
+		aligned.BloomSize = size
+	}
+	aligned.ChtSize, aligned.BloomTrieSize = size, size
+	return &aligned
+}
+
+// gcd returns the greatest common divisor of a and b.
+func gcd(a, b uint64) uint64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
 var (
 	errNoTrustedCht       = errors.New("no trusted canonical hash trie")
 	errNoTrustedBloomTrie = errors.New("no trusted bloom trie")
@@ -0,0 +1,126 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package light
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	mockEngine "github.com/celo-org/celo-blockchain/consensus/consensustest"
+	"github.com/celo-org/celo-blockchain/consensus/istanbul"
+	"github.com/celo-org/celo-blockchain/core"
+	"github.com/celo-org/celo-blockchain/core/rawdb"
+	"github.com/celo-org/celo-blockchain/core/vm"
+	"github.com/celo-org/celo-blockchain/params"
+	"github.com/celo-org/celo-blockchain/rlp"
+	"github.com/celo-org/celo-blockchain/trie"
+)
+
+func TestEpochAlignedIndexerConfig(t *testing.T) {
+	tests := []struct {
+		config    *IndexerConfig
+		epoch     uint64
+		bloomSize uint64
+		size      uint64
+		clientBlm uint64
+	}{
+		// Mainnet epochs only share a factor of 2^7 with the bloom sections
+		{DefaultServerIndexerConfig, 17280, params.BloomBitsBlocks, 552960, params.BloomBitsBlocks},
+		{DefaultClientIndexerConfig, 17280, params.BloomBitsBlocks, 552960, 552960},
+		// Power of two epochs keep the default section size
+		{DefaultServerIndexerConfig, 4096, params.BloomBitsBlocks, params.CHTFrequency, params.BloomBitsBlocks},
+		{DefaultClientIndexerConfig, 4096, params.BloomBitsBlocks, params.CHTFrequency, params.BloomBitsBlocksClient},
+		// Small epochs round the section size up
+		{TestServerIndexerConfig, 20, 16, 160, 16},
+		{TestClientIndexerConfig, 20, 16, 160, 160},
+		// No epochs leave the config untouched
+		{DefaultServerIndexerConfig, 0, params.BloomBitsBlocks, params.CHTFrequency, params.BloomBitsBlocks},
+	}
+	for i, tt := range tests {
+		aligned := EpochAlignedIndexerConfig(tt.config, tt.epoch, tt.bloomSize)
+		if aligned.ChtSize != tt.size || aligned.BloomTrieSize != tt.size {
+			t.Errorf("test %d: section sizes mismatch: have cht %d bloomtrie %d, want %d", i, aligned.ChtSize, aligned.BloomTrieSize, tt.size)
+		}
+		if aligned.BloomSize != tt.clientBlm {
+			t.Errorf("test %d: bloom section size mismatch: have %d, want %d", i, aligned.BloomSize, tt.clientBlm)
+		}
+		if aligned.ChtConfirms != tt.config.ChtConfirms || aligned.BloomTrieConfirms != tt.config.BloomTrieConfirms {
+			t.Errorf("test %d: confirmations changed", i)
+		}
+		if aligned.BloomTrieSize%tt.bloomSize != 0 {
+			t.Errorf("test %d: bloom trie section %d not a multiple of bloom section %d", i, aligned.BloomTrieSize, tt.bloomSize)
+		}
+		if tt.epoch != 0 && !istanbul.IsLastBlockOfEpoch(aligned.ChtSize, tt.epoch) {
+			t.Errorf("test %d: section 1 starts at block %d, not an epoch block", i, aligned.ChtSize)
+		}
+	}
+	// The shared configs must not be modified
+	if DefaultClientIndexerConfig.ChtSize != params.CHTFrequency || DefaultClientIndexerConfig.BloomSize != params.BloomBitsBlocksClient {
+		t.Fatalf("default client config modified: %+v", DefaultClientIndexerConfig)
+	}
+}
+
+// Tests that the CHT indexer builds epoch aligned sections whose trie proves
+// the epoch blocks of the section.
+func TestEpochAlignedCht(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		gspec   = core.Genesis{Config: params.IstanbulTestChainConfig}
+		genesis = gspec.MustCommit(db)
+		config  = EpochAlignedIndexerConfig(TestServerIndexerConfig, 20, TestServerIndexerConfig.BloomSize)
+	)
+	blockchain, _ := core.NewBlockChain(db, nil, params.IstanbulTestChainConfig, mockEngine.NewFullFaker(), vm.Config{}, nil, nil)
+	defer blockchain.Stop()
+
+	indexer := NewChtIndexer(db, nil, config.ChtSize, config.ChtConfirms, true, true)
+	defer indexer.Close()
+	indexer.Start(blockchain)
+
+	blocks, _ := core.GenerateChain(params.IstanbulTestChainConfig, genesis, mockEngine.NewFaker(), db, int(config.ChtSize+config.ChtConfirms), nil)
+	if _, err := blockchain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+		if sections, _, _ := indexer.Sections(); sections >= 1 {
+			break
+		}
+		if time.Since(start) > 5*time.Second {
+			t.Fatalf("section not indexed")
+		}
+	}
+	head := blockchain.GetHeaderByNumber(config.ChtSize - 1).Hash()
+	if have := indexer.SectionHead(0); have != head {
+		t.Fatalf("section head mismatch: have %x, want %x", have, head)
+	}
+	root := GetChtRoot(db, 0, head)
+	cht, err := trie.New(root, trie.NewDatabase(rawdb.NewTable(db, ChtTablePrefix)))
+	if err != nil {
+		t.Fatalf("failed to open CHT %x: %v", root, err)
+	}
+	for number := uint64(0); number < config.ChtSize; number += 20 {
+		var key [8]byte
+		binary.BigEndian.PutUint64(key[:], number)
+		var node ChtNode
+		if err := rlp.DecodeBytes(cht.Get(key[:]), &node); err != nil {
+			t.Fatalf("epoch block %d: missing CHT entry: %v", number, err)
+		}
+		if want := blockchain.GetHeaderByNumber(number).Hash(); node.Hash != want {
+			t.Errorf("epoch block %d: hash mismatch: have %x, want %x", number, node.Hash, want)
+		}
+	}
+}
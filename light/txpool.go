@@ -378,6 +378,14 @@ func (pool *TxPool) validateTx(ctx context.Context, tx *types.Transaction) error
 	if from, err = types.Sender(pool.signer, tx); err != nil {
 		return core.ErrInvalidSender
 	}
+	if tx.FeePayer() != nil {
+		if !pool.eHardfork {
+			return core.ErrSponsoredTransactionsNotSupported
+		}
+		if _, err := types.FeePayerSender(tx); err != nil {
+			return core.ErrInvalidFeePayer
+		}
+	}
 	// Last but not least check for nonce errors
 	currentState := pool.currentState(ctx)
 	if n := currentState.GetNonce(from); n > tx.Nonce() {
@@ -391,19 +399,21 @@ func (pool *TxPool) validateTx(ctx context.Context, tx *types.Transaction) error
 		return core.ErrNegativeValue
 	}
 
-	vmRunner := pool.chain.NewEVMRunner(pool.chain.CurrentHeader(), currentState)
+	header := pool.chain.CurrentHeader()
+	vmRunner := pool.chain.NewEVMRunner(header, currentState)
 	// Transactor should have enough funds to cover the costs
 	err = core.ValidateTransactorBalanceCoversTx(tx, from, currentState, vmRunner, pool.eHardfork)
 	if err != nil {
 		return err
 	}
 
+	schedule := pool.config.IntrinsicGasSchedule(new(big.Int).Add(header.Number, big.NewInt(1)))
 	gasForAlternativeCurrency := uint64(0)
 	// If the fee currency is nil, do not retrieve the intrinsic gas adjustment from the chain state, as it will not be used.
 	if tx.FeeCurrency() != nil {
-		gasForAlternativeCurrency = blockchain_parameters.GetIntrinsicGasForAlternativeFeeCurrencyOrDefault(vmRunner)
+		gasForAlternativeCurrency = blockchain_parameters.GetIntrinsicGasForAlternativeFeeCurrencyOrDefault(vmRunner, schedule)
 	}
-	gas, err := core.IntrinsicGas(tx.Data(), tx.To() == nil, tx.FeeCurrency(), gasForAlternativeCurrency, pool.istanbul)
+	gas, err := core.IntrinsicGas(tx.Data(), tx.To() == nil, tx.FeeCurrency(), tx.GatewayFeeRecipient(), gasForAlternativeCurrency, schedule, pool.istanbul)
 	if err != nil {
 		return err
 	}
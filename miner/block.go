@@ -32,6 +32,7 @@ import (
 	"github.com/celo-org/celo-blockchain/core/rawdb"
 	"github.com/celo-org/celo-blockchain/core/state"
 	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/core/vm/vmcontext"
 	"github.com/celo-org/celo-blockchain/log"
 	"github.com/celo-org/celo-blockchain/params"
 )
@@ -156,6 +157,9 @@ func prepareBlock(w *worker) (*blockState, error) {
 
 // selectAndApplyTransactions selects and applies transactions to the in flight block state.
 func (b *blockState) selectAndApplyTransactions(ctx context.Context, w *worker) error {
+	// Bundles go first, so that their transactions are contiguous
+	b.commitBundles(ctx, w)
+
 	// Fill the block with all available pending transactions.
 	pending, err := w.eth.TxPool().Pending()
 
@@ -241,7 +245,7 @@ loop:
 		// Start executing the transaction
 		b.state.Prepare(tx.Hash(), common.Hash{}, b.tcount)
 
-		logs, err := b.commitTransaction(w, tx, txFeeRecipient)
+		logs, err := b.commitTransaction(ctx, w, tx, txFeeRecipient)
 		switch err {
 		case core.ErrGasLimitReached:
 			// Pop the current out-of-gas transaction without shifting in the next from the account
@@ -297,11 +301,11 @@ loop:
 }
 
 // commitTransaction attempts to appply a single transaction. If the transaction fails, it's modifications are reverted.
-func (b *blockState) commitTransaction(w *worker, tx *types.Transaction, txFeeRecipient common.Address) ([]*types.Log, error) {
+func (b *blockState) commitTransaction(ctx context.Context, w *worker, tx *types.Transaction, txFeeRecipient common.Address) ([]*types.Log, error) {
 	snap := b.state.Snapshot()
-	vmRunner := w.chain.NewEVMRunner(b.header, b.state)
+	vmRunner := vmcontext.NewEVMRunnerWithContext(ctx, w.chain, b.header, b.state)
 
-	receipt, err := core.ApplyTransaction(w.chainConfig, w.chain, &txFeeRecipient, b.gasPool, b.state, b.header, tx, &b.header.GasUsed, *w.chain.GetVMConfig(), vmRunner)
+	receipt, err := core.ApplyTransaction(ctx, w.chainConfig, w.chain, &txFeeRecipient, b.gasPool, b.state, b.header, tx, &b.header.GasUsed, *w.chain.GetVMConfig(), vmRunner)
 	if err != nil {
 		b.state.RevertToSnapshot(snap)
 		return nil, err
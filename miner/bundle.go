@@ -0,0 +1,208 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/crypto"
+	"github.com/celo-org/celo-blockchain/log"
+)
+
+const (
+	// maxBundleTxs is the maximum number of transactions in a bundle.
+	maxBundleTxs = 32
+
+	// maxBundles is the maximum number of bundles waiting for inclusion.
+	maxBundles = 256
+
+	// defaultBundleLifetime is the number of blocks a bundle submitted without
+	// a max block is considered for inclusion.
+	defaultBundleLifetime = 16
+)
+
+var (
+	errEmptyBundle      = errors.New("empty bundle")
+	errBundleTooLarge   = errors.New("bundle too large")
+	errBundleKnown      = errors.New("bundle already known")
+	errBundleExpired    = errors.New("bundle max block passed")
+	errBundlesFull      = errors.New("too many pending bundles")
+	errBundleTxReverted = errors.New("bundle transaction reverted")
+)
+
+// txBundle is an ordered list of transactions to be included contiguously and
+// completely in a block, or not at all.
+type txBundle struct {
+	hash     common.Hash
+	txs      types.Transactions
+	maxBlock uint64 // Last block the bundle may be included in
+}
+
+// bundleHash identifies a bundle by the hashes of its transactions.
+func bundleHash(txs types.Transactions) common.Hash {
+	hashes := make([]byte, 0, len(txs)*common.HashLength)
+	for _, tx := range txs {
+		hashes = append(hashes, tx.Hash().Bytes()...)
+	}
+	return crypto.Keccak256Hash(hashes)
+}
+
+// bundlePool holds the bundles waiting for inclusion, in submission order.
+type bundlePool struct {
+	mu      sync.Mutex
+	bundles []*txBundle
+}
+
+// add queues a bundle for inclusion.
+func (p *bundlePool) add(bundle *txBundle) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, other := range p.bundles {
+		if other.hash == bundle.hash {
+			return errBundleKnown
+		}
+	}
+	if len(p.bundles) >= maxBundles {
+		return errBundlesFull
+	}
+	p.bundles = append(p.bundles, bundle)
+	return nil
+}
+
+// pending returns the bundles which may be included in the given block, dropping
+// the expired ones.
+func (p *bundlePool) pending(number uint64) []*txBundle {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	kept := p.bundles[:0]
+	for _, bundle := range p.bundles {
+		if bundle.maxBlock < number {
+			log.Debug("Dropping expired bundle", "hash", bundle.hash, "maxBlock", bundle.maxBlock)
+			continue
+		}
+		kept = append(kept, bundle)
+	}
+	p.bundles = kept
+	return append([]*txBundle(nil), kept...)
+}
+
+// remove drops a bundle which can no longer be included.
+func (p *bundlePool) remove(hash common.Hash) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i, bundle := range p.bundles {
+		if bundle.hash == hash {
+			p.bundles = append(p.bundles[:i], p.bundles[i+1:]...)
+			return
+		}
+	}
+}
+
+// sendBundle validates and queues a bundle for inclusion up to the given block,
+// or for defaultBundleLifetime blocks if zero.
+func (w *worker) sendBundle(txs types.Transactions, maxBlock uint64) (common.Hash, error) {
+	if len(txs) == 0 {
+		return common.Hash{}, errEmptyBundle
+	}
+	if len(txs) > maxBundleTxs {
+		return common.Hash{}, errBundleTooLarge
+	}
+	next := w.chain.CurrentBlock().NumberU64() + 1
+	if maxBlock == 0 {
+		maxBlock = next + defaultBundleLifetime - 1
+	}
+	if maxBlock < next {
+		return common.Hash{}, errBundleExpired
+	}
+	signer := types.NewEIP155Signer(w.chainConfig.ChainID)
+	for _, tx := range txs {
+		if _, err := types.Sender(signer, tx); err != nil {
+			return common.Hash{}, err
+		}
+	}
+	bundle := &txBundle{hash: bundleHash(txs), txs: txs, maxBlock: maxBlock}
+	if err := w.bundles.add(bundle); err != nil {
+		return common.Hash{}, err
+	}
+	log.Debug("Queued transaction bundle", "hash", bundle.hash, "txs", len(txs), "maxBlock", maxBlock)
+	return bundle.hash, nil
+}
+
+// commitBundles commits the pending bundles to the block, in submission order.
+// Bundles with a transaction already included or replaced are dropped.
+func (b *blockState) commitBundles(ctx context.Context, w *worker) {
+	for _, bundle := range w.bundles.pending(b.header.Number.Uint64()) {
+		if b.bundleStale(bundle) {
+			log.Debug("Dropping stale bundle", "hash", bundle.hash)
+			w.bundles.remove(bundle.hash)
+			continue
+		}
+		if err := b.commitBundle(ctx, w, bundle); err != nil {
+			log.Trace("Bundle not included", "hash", bundle.hash, "err", err)
+		}
+	}
+}
+
+// bundleStale returns whether a transaction of the bundle has a nonce already
+// used by its sender.
+func (b *blockState) bundleStale(bundle *txBundle) bool {
+	for _, tx := range bundle.txs {
+		from, _ := types.Sender(b.signer, tx)
+		if b.state.GetNonce(from) > tx.Nonce() {
+			return true
+		}
+	}
+	return false
+}
+
+// commitBundle applies all the transactions of the bundle, or restores the block
+// state if any fails or reverts. The state is copied rather than snapshotted, as
+// applying a transaction finalises the state and discards its snapshots.
+func (b *blockState) commitBundle(ctx context.Context, w *worker, bundle *txBundle) error {
+	var (
+		state   = b.state.Copy()
+		gasPool = *b.gasPool
+		gasUsed = b.header.GasUsed
+		tcount  = b.tcount
+		txs     = len(b.txs)
+	)
+	for _, tx := range bundle.txs {
+		b.state.Prepare(tx.Hash(), common.Hash{}, b.tcount)
+
+		_, err := b.commitTransaction(ctx, w, tx, b.txFeeRecipient)
+		if err == nil && b.receipts[len(b.receipts)-1].Status == types.ReceiptStatusFailed {
+			err = errBundleTxReverted
+		}
+		if err != nil {
+			b.state = state
+			*b.gasPool = gasPool
+			b.header.GasUsed = gasUsed
+			b.tcount = tcount
+			b.txs, b.receipts = b.txs[:txs], b.receipts[:txs]
+			return err
+		}
+		b.tcount++
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/celo-org/celo-blockchain/common"
+	mockEngine "github.com/celo-org/celo-blockchain/consensus/consensustest"
+	"github.com/celo-org/celo-blockchain/core/rawdb"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/params"
+)
+
+func bundleTx(nonce uint64, to common.Address, value *big.Int, key *ecdsa.PrivateKey) *types.Transaction {
+	signer := types.NewEIP155Signer(params.IstanbulTestChainConfig.ChainID)
+	tx, _ := types.SignTx(types.NewTransaction(nonce, to, value, params.TxGas, nil, nil, nil, nil, nil), signer, key)
+	return tx
+}
+
+// Tests that bundles are included contiguously and completely, or not at all.
+func TestCommitBundles(t *testing.T) {
+	w, _ := newTestWorker(t, params.IstanbulTestChainConfig, mockEngine.NewFaker(), rawdb.NewMemoryDatabase(), 1, false)
+	defer w.close()
+
+	if _, err := w.sendBundle(nil, 0); err != errEmptyBundle {
+		t.Fatalf("empty bundle error mismatch: have %v, want %v", err, errEmptyBundle)
+	}
+	if _, err := w.sendBundle(types.Transactions{bundleTx(0, testUserAddress, big.NewInt(1), testBankKey)}, w.chain.CurrentBlock().NumberU64()); err != errBundleExpired {
+		t.Fatalf("expired bundle error mismatch: have %v, want %v", err, errBundleExpired)
+	}
+	complete := types.Transactions{
+		bundleTx(0, testUserAddress, big.NewInt(1000), testBankKey),
+		bundleTx(1, testUserAddress, big.NewInt(1000), testBankKey),
+	}
+	// The second transaction of this bundle can't be paid for
+	failing := types.Transactions{
+		bundleTx(2, testUserAddress, big.NewInt(1000), testBankKey),
+		bundleTx(0, testBankAddress, testBankFunds, testUserKey),
+	}
+	for _, txs := range []types.Transactions{failing, complete} {
+		if _, err := w.sendBundle(txs, 0); err != nil {
+			t.Fatalf("failed to send bundle: %v", err)
+		}
+	}
+	if _, err := w.sendBundle(complete, 0); err != errBundleKnown {
+		t.Fatalf("known bundle error mismatch: have %v, want %v", err, errBundleKnown)
+	}
+
+	b, err := prepareBlock(w)
+	if err != nil {
+		t.Fatalf("failed to prepare block: %v", err)
+	}
+	if err := b.selectAndApplyTransactions(context.Background(), w); err != nil {
+		t.Fatalf("failed to apply transactions: %v", err)
+	}
+	if len(b.txs) != len(complete) || b.tcount != len(complete) {
+		t.Fatalf("included transactions mismatch: have %d, want %d", len(b.txs), len(complete))
+	}
+	for i, tx := range complete {
+		if b.txs[i].Hash() != tx.Hash() {
+			t.Errorf("transaction %d mismatch: have %x, want %x", i, b.txs[i].Hash(), tx.Hash())
+		}
+	}
+	if nonce := b.state.GetNonce(testBankAddress); nonce != 2 {
+		t.Errorf("sender nonce mismatch: have %d, want %d", nonce, 2)
+	}
+	if gas := b.header.GasUsed; gas != 2*params.TxGas {
+		t.Errorf("gas used mismatch: have %d, want %d", gas, 2*params.TxGas)
+	}
+	// Bundles with transactions already included are dropped, and failing ones
+	// are rolled back even if their first transactions applied
+	b.commitBundles(context.Background(), w)
+	if len(b.txs) != len(complete) || b.state.GetNonce(testBankAddress) != 2 || b.header.GasUsed != 2*params.TxGas {
+		t.Errorf("failing bundle not rolled back: %d txs, nonce %d, gas used %d", len(b.txs), b.state.GetNonce(testBankAddress), b.header.GasUsed)
+	}
+	if pending := w.bundles.pending(b.header.Number.Uint64()); len(pending) != 1 || pending[0].hash != bundleHash(failing) {
+		t.Errorf("pending bundles mismatch: have %d, want the failing one", len(pending))
+	}
+}
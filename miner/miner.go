@@ -31,6 +31,7 @@ import (
 	"github.com/celo-org/celo-blockchain/eth/downloader"
 	"github.com/celo-org/celo-blockchain/ethdb"
 	"github.com/celo-org/celo-blockchain/event"
+	"github.com/celo-org/celo-blockchain/internal/lifecycle"
 	"github.com/celo-org/celo-blockchain/log"
 	"github.com/celo-org/celo-blockchain/params"
 )
@@ -45,6 +46,7 @@ type Backend interface {
 type Config struct {
 	Validator common.Address `toml:",omitempty"` // Public address for block signing and randomness (default = first account)
 	ExtraData hexutil.Bytes  `toml:",omitempty"` // Block extra data set by the miner
+	OnDemand  bool           `toml:",omitempty"` // Only create blocks when there are pending transactions
 }
 
 // Miner creates blocks and searches for proof-of-work values.
@@ -56,23 +58,25 @@ type Miner struct {
 	engine    consensus.Engine
 	db        ethdb.Database // Needed for randomness
 
-	exitCh  chan struct{}
-	startCh chan struct{}
-	stopCh  chan struct{}
+	exitCh   chan struct{}
+	startCh  chan struct{}
+	stopCh   chan struct{}
+	routines *lifecycle.Manager
 }
 
 func New(eth Backend, config *Config, chainConfig *params.ChainConfig, mux *event.TypeMux, engine consensus.Engine, db ethdb.Database) *Miner {
 	miner := &Miner{
-		eth:     eth,
-		mux:     mux,
-		engine:  engine,
-		exitCh:  make(chan struct{}),
-		startCh: make(chan struct{}),
-		stopCh:  make(chan struct{}),
-		worker:  newWorker(config, chainConfig, engine, eth, mux, db),
-		db:      db,
+		eth:      eth,
+		mux:      mux,
+		engine:   engine,
+		exitCh:   make(chan struct{}),
+		startCh:  make(chan struct{}),
+		stopCh:   make(chan struct{}),
+		worker:   newWorker(config, chainConfig, engine, eth, mux, db),
+		db:       db,
+		routines: lifecycle.NewManager("miner"),
 	}
-	go miner.update()
+	miner.routines.Go("update", miner.update)
 
 	return miner
 }
@@ -170,6 +174,7 @@ func (miner *Miner) update() {
 			shouldStart = false
 			miner.worker.stop()
 		case <-miner.exitCh:
+			miner.worker.stop()
 			miner.worker.close()
 			return
 		}
@@ -186,8 +191,11 @@ func (miner *Miner) Stop() {
 	miner.stopCh <- struct{}{}
 }
 
+// Close stops mining and terminates the miner, blocking until all its
+// goroutines have exited. The miner cannot be used after being closed.
 func (miner *Miner) Close() {
 	close(miner.exitCh)
+	miner.routines.Wait()
 }
 
 func (miner *Miner) Mining() bool {
@@ -227,6 +235,13 @@ func (miner *Miner) SetTxFeeRecipient(addr common.Address) {
 	miner.worker.setTxFeeRecipient(addr)
 }
 
+// SendBundle queues an ordered bundle of transactions, to be included contiguously
+// and completely in a block up to maxBlock, or not at all. A zero maxBlock bounds
+// the bundle to the next few blocks. The transactions are not propagated.
+func (miner *Miner) SendBundle(txs types.Transactions, maxBlock uint64) (common.Hash, error) {
+	return miner.worker.sendBundle(txs, maxBlock)
+}
+
 // SubscribePendingLogs starts delivering logs from pending transactions
 // to the given channel.
 func (miner *Miner) SubscribePendingLogs(ch chan<- []*types.Log) event.Subscription {
@@ -18,17 +18,20 @@ package miner
 
 import (
 	"context"
+	"math/big"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/celo-org/celo-blockchain/common"
 	"github.com/celo-org/celo-blockchain/consensus"
+	"github.com/celo-org/celo-blockchain/consensus/istanbul"
 	"github.com/celo-org/celo-blockchain/core"
 	"github.com/celo-org/celo-blockchain/core/state"
 	"github.com/celo-org/celo-blockchain/core/types"
 	"github.com/celo-org/celo-blockchain/ethdb"
 	"github.com/celo-org/celo-blockchain/event"
+	"github.com/celo-org/celo-blockchain/internal/lifecycle"
 	"github.com/celo-org/celo-blockchain/log"
 	"github.com/celo-org/celo-blockchain/metrics"
 	"github.com/celo-org/celo-blockchain/params"
@@ -41,6 +44,9 @@ const (
 
 	// chainHeadChanSize is the size of channel listening to ChainHeadEvent.
 	chainHeadChanSize = 10
+
+	// newRoundChanSize is the size of channel listening to NewRoundEvent.
+	newRoundChanSize = 10
 )
 
 // callBackEngine is a subset of the consensus.Istanbul interface. It is used over consensus.Istanbul to enable sealing
@@ -53,6 +59,13 @@ type callBackEngine interface {
 		onNewConsensusBlock func(block *types.Block, receipts []*types.Receipt, logs []*types.Log, state *state.StateDB)) error
 }
 
+// roundEngine is implemented by consensus engines notifying the round changes
+// of their sequences, letting the worker abort the construction of blocks it
+// can no longer propose.
+type roundEngine interface {
+	SubscribeNewRoundEvent(ch chan<- istanbul.NewRoundEvent) event.Subscription
+}
+
 // task contains all information for consensus engine sealing and result submitting.
 type task struct {
 	receipts  []*types.Receipt
@@ -79,11 +92,15 @@ type worker struct {
 	txsSub       event.Subscription
 	chainHeadCh  chan core.ChainHeadEvent
 	chainHeadSub event.Subscription
+	newRoundCh   chan istanbul.NewRoundEvent
+	newRoundSub  event.Subscription // Nil if the engine does not notify round changes
 
 	// Channels
 	startCh chan struct{}
 	exitCh  chan struct{}
 
+	routines *lifecycle.Manager
+
 	mu             sync.RWMutex // The lock used to protect the validator, txFeeRecipient and extra fields
 	validator      common.Address
 	txFeeRecipient common.Address
@@ -104,6 +121,8 @@ type worker struct {
 	// Needed for randomness
 	db ethdb.Database
 
+	bundles bundlePool // Transaction bundles waiting for inclusion
+
 	blockConstructGauge metrics.Gauge
 }
 
@@ -119,6 +138,7 @@ func newWorker(config *Config, chainConfig *params.ChainConfig, engine consensus
 		chainHeadCh:         make(chan core.ChainHeadEvent, chainHeadChanSize),
 		exitCh:              make(chan struct{}),
 		startCh:             make(chan struct{}, 1),
+		routines:            lifecycle.NewManager("miner/worker"),
 		db:                  db,
 		blockConstructGauge: metrics.NewRegisteredGauge("miner/worker/block_construct", nil),
 	}
@@ -126,8 +146,13 @@ func newWorker(config *Config, chainConfig *params.ChainConfig, engine consensus
 	worker.txsSub = eth.TxPool().SubscribeNewTxsEvent(worker.txsCh)
 	// Subscribe events for blockchain
 	worker.chainHeadSub = eth.BlockChain().SubscribeChainHeadEvent(worker.chainHeadCh)
+	// Subscribe round changes for consensus
+	if engine, ok := engine.(roundEngine); ok {
+		worker.newRoundCh = make(chan istanbul.NewRoundEvent, newRoundChanSize)
+		worker.newRoundSub = engine.SubscribeNewRoundEvent(worker.newRoundCh)
+	}
 
-	go worker.mainLoop()
+	worker.routines.Go("mainLoop", worker.mainLoop)
 
 	return worker
 }
@@ -139,6 +164,13 @@ func (w *worker) setValidator(addr common.Address) {
 	w.validator = addr
 }
 
+// validatorAddress returns the validator address.
+func (w *worker) validatorAddress() common.Address {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.validator
+}
+
 // setTxFeeRecipient sets the address to receive tx fees, stored in header.Coinbase
 func (w *worker) setTxFeeRecipient(addr common.Address) {
 	w.mu.Lock()
@@ -180,7 +212,7 @@ func (w *worker) start() {
 	if cbEngine, ok := w.engine.(callBackEngine); ok {
 		cbEngine.SetCallBacks(w.chain.HasBadBlock,
 			func(block *types.Block, state *state.StateDB) (types.Receipts, []*types.Log, uint64, error) {
-				return w.chain.Processor().Process(block, state, *w.chain.GetVMConfig())
+				return w.chain.Processor().Process(context.Background(), block, state, *w.chain.GetVMConfig())
 			},
 			w.chain.Validator().ValidateState,
 			func(block *types.Block, receipts []*types.Receipt, logs []*types.Log, state *state.StateDB) {
@@ -221,10 +253,12 @@ func (w *worker) isRunning() bool {
 	return atomic.LoadInt32(&w.running) == 1
 }
 
-// close terminates all background threads maintained by the worker.
+// close terminates all background threads maintained by the worker, blocking
+// until they have exited.
 // Note the worker does not support being closed multiple times.
 func (w *worker) close() {
 	close(w.exitCh)
+	w.routines.Wait()
 }
 
 // constructAndSubmitNewBlock constructs a new block and if the worker is running, submits
@@ -270,6 +304,10 @@ func (w *worker) constructAndSubmitNewBlock(ctx context.Context) {
 	// And we subtract the time we spent sleeping, since we want the time spent actually building the block.
 	w.blockConstructGauge.Update(time.Since(start).Nanoseconds() - delay.Nanoseconds())
 
+	// The construction may have been aborted while finalizing
+	if ctx.Err() != nil {
+		return
+	}
 	if w.isRunning() {
 		if w.fullTaskHook != nil {
 			w.fullTaskHook()
@@ -340,10 +378,30 @@ func (w *worker) constructPendingStateBlock(ctx context.Context, txsCh chan core
 
 }
 
+// waitForTransactions blocks until there are pending transactions to include in
+// a block. It returns false if the context is cancelled first.
+func (w *worker) waitForTransactions(ctx context.Context, txsCh chan core.NewTxsEvent) bool {
+	for {
+		if pending, _ := w.eth.TxPool().Stats(); pending > 0 {
+			return true
+		}
+		select {
+		case <-txsCh:
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
 // mainLoop is a standalone goroutine to create tasks and submit to the engine.
 func (w *worker) mainLoop() {
 	defer w.chainHeadSub.Unsubscribe()
 	defer w.txsSub.Unsubscribe()
+	var newRoundErr <-chan error
+	if w.newRoundSub != nil {
+		defer w.newRoundSub.Unsubscribe()
+		newRoundErr = w.newRoundSub.Err()
+	}
 	// Context and cancel function for the currently executing block construction
 	// Cancel needs to be called in each exit path to make the linter happy
 	// because go struggles with analyzing lexical scoping.
@@ -351,9 +409,21 @@ func (w *worker) mainLoop() {
 	var cancel context.CancelFunc
 	var wg sync.WaitGroup
 
+	// Sequence and latest round of the block constructed as a validator, and
+	// whether its construction was aborted after a lost round
+	var (
+		taskSeq   *big.Int
+		taskRound *big.Int
+		taskDone  chan struct{}
+		aborted   bool
+	)
+
 	txsCh := make(chan core.NewTxsEvent, txChanSize)
 
-	generateNewBlock := func() {
+	// generateNewBlock cancels the block construction in progress and starts a
+	// new one, after signaling the start of a new sequence to the engine if
+	// newWork is set.
+	generateNewBlock := func(newWork bool) {
 		if cancel != nil {
 			cancel()
 		}
@@ -363,13 +433,23 @@ func (w *worker) mainLoop() {
 
 		if w.isRunning() {
 			// engine.NewWork posts the FinalCommitted Event to IBFT to signal the start of the next round
-			if h, ok := w.engine.(consensus.Handler); ok {
+			if h, ok := w.engine.(consensus.Handler); ok && newWork {
 				h.NewWork()
 			}
+			if newWork {
+				taskSeq = new(big.Int).Add(w.chain.CurrentBlock().Number(), common.Big1)
+				taskRound = new(big.Int)
+			}
+			done := make(chan struct{})
+			taskDone, aborted = done, false
 
 			go func() {
+				defer close(done)
+				defer wg.Done()
+				if w.config.OnDemand && !w.waitForTransactions(taskCtx, txsCh) {
+					return
+				}
 				w.constructAndSubmitNewBlock(taskCtx)
-				wg.Done()
 			}()
 		} else {
 			go func() {
@@ -382,16 +462,37 @@ func (w *worker) mainLoop() {
 	for {
 		select {
 		case <-w.startCh:
-			generateNewBlock()
+			generateNewBlock(true)
 
 		case <-w.chainHeadCh:
-			generateNewBlock()
+			generateNewBlock(true)
+
+		case ev := <-w.newRoundCh:
+			// Only round changes of the sequence being constructed matter, in order
+			if !w.isRunning() || taskSeq == nil || ev.Sequence.Cmp(taskSeq) != 0 || ev.Round.Cmp(taskRound) <= 0 {
+				break
+			}
+			taskRound = ev.Round
+			if ev.Proposer != w.validatorAddress() {
+				// Another validator proposes the round, stop constructing the block
+				select {
+				case <-taskDone:
+				default:
+					log.Debug("Aborting block construction after round change", "number", taskSeq, "round", ev.Round, "proposer", ev.Proposer)
+					cancel()
+					aborted = true
+				}
+			} else if aborted {
+				// The validator proposes the round, resume constructing the block
+				log.Debug("Resuming block construction after round change", "number", taskSeq, "round", ev.Round)
+				generateNewBlock(false)
+			}
 
 		case ev := <-w.txsCh:
-			// Drain tx sub channel as a validator,
-			// otherwise pass it to the full node loop
+			// Drain tx sub channel as a validator, unless blocks are only
+			// created on demand, otherwise pass it to the full node loop
 			// if the full node loop's channel is full, just drop the transaction
-			if !w.isRunning() {
+			if !w.isRunning() || w.config.OnDemand {
 				select {
 				case txsCh <- ev:
 				default:
@@ -402,16 +503,25 @@ func (w *worker) mainLoop() {
 			if cancel != nil {
 				cancel()
 			}
+			wg.Wait()
 			return
 		case <-w.chainHeadSub.Err():
 			if cancel != nil {
 				cancel()
 			}
+			wg.Wait()
 			return
 		case <-w.txsSub.Err():
 			if cancel != nil {
 				cancel()
 			}
+			wg.Wait()
+			return
+		case <-newRoundErr:
+			if cancel != nil {
+				cancel()
+			}
+			wg.Wait()
 			return
 		}
 	}
@@ -41,6 +41,7 @@ import (
 	"github.com/celo-org/celo-blockchain/crypto/ecies"
 	"github.com/celo-org/celo-blockchain/ethdb"
 	"github.com/celo-org/celo-blockchain/event"
+	"github.com/celo-org/celo-blockchain/internal/lifecycle"
 	"github.com/celo-org/celo-blockchain/params"
 )
 
@@ -381,6 +382,92 @@ func TestRegenerateMiningBlockIstanbul(t *testing.T) {
 	}
 }
 
+// Tests that on demand, blocks are only created once there are pending transactions.
+func TestOnDemandWork(t *testing.T) {
+	chainConfig := params.IstanbulTestChainConfig
+	engine := mockEngine.NewFaker()
+	b := newTestWorkerBackend(t, chainConfig, engine, rawdb.NewMemoryDatabase(), 0)
+	w := newWorker(&Config{OnDemand: true}, chainConfig, engine, b, new(event.TypeMux), b.db)
+	w.setTxFeeRecipient(testBankAddress)
+	w.setValidator(testBankAddress)
+	defer w.close()
+
+	taskCh := make(chan *task, 1)
+	w.newTaskHook = func(task *task) { taskCh <- task }
+	w.skipSealHook = func(task *task) bool { return true }
+	w.start()
+
+	select {
+	case task := <-taskCh:
+		t.Fatalf("block %d created without pending transactions", task.block.NumberU64())
+	case <-time.After(500 * time.Millisecond):
+	}
+	b.txPool.AddLocal(b.newRandomTx(false))
+	select {
+	case task := <-taskCh:
+		if len(task.receipts) != 1 {
+			t.Errorf("receipt number mismatch: have %d, want 1", len(task.receipts))
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("no block created for pending transaction")
+	}
+}
+
+// roundMockEngine is a mock engine notifying round changes.
+type roundMockEngine struct {
+	*consensustest.MockEngine
+	newRoundFeed event.Feed
+}
+
+func (e *roundMockEngine) SubscribeNewRoundEvent(ch chan<- istanbul.NewRoundEvent) event.Subscription {
+	return e.newRoundFeed.Subscribe(ch)
+}
+
+// Tests that the construction of a block is aborted when a round change selects
+// another proposer, and resumed when the validator proposes a later round.
+func TestRoundChangeAbortsWork(t *testing.T) {
+	chainConfig := params.IstanbulTestChainConfig
+	engine := &roundMockEngine{MockEngine: mockEngine.NewFaker()}
+	b := newTestWorkerBackend(t, chainConfig, engine.MockEngine, rawdb.NewMemoryDatabase(), 0)
+	w := newWorker(&Config{OnDemand: true}, chainConfig, engine, b, new(event.TypeMux), b.db)
+	w.setTxFeeRecipient(testBankAddress)
+	w.setValidator(testBankAddress)
+	defer w.close()
+
+	taskCh := make(chan *task, 1)
+	w.newTaskHook = func(task *task) { taskCh <- task }
+	w.skipSealHook = func(task *task) bool { return true }
+	w.start()
+
+	// Lose the round while waiting for transactions to include
+	time.Sleep(100 * time.Millisecond)
+	engine.newRoundFeed.Send(istanbul.NewRoundEvent{Sequence: big.NewInt(1), Round: big.NewInt(1), Proposer: testUserAddress})
+	time.Sleep(100 * time.Millisecond)
+	b.txPool.AddLocal(b.newRandomTx(false))
+	select {
+	case task := <-taskCh:
+		t.Fatalf("block %d created after the round was lost", task.block.NumberU64())
+	case <-time.After(500 * time.Millisecond):
+	}
+	// Rounds of other sequences are ignored
+	engine.newRoundFeed.Send(istanbul.NewRoundEvent{Sequence: big.NewInt(2), Round: big.NewInt(2), Proposer: testBankAddress})
+	select {
+	case task := <-taskCh:
+		t.Fatalf("block %d created for a round of another sequence", task.block.NumberU64())
+	case <-time.After(500 * time.Millisecond):
+	}
+	// Win a later round
+	engine.newRoundFeed.Send(istanbul.NewRoundEvent{Sequence: big.NewInt(1), Round: big.NewInt(2), Proposer: testBankAddress})
+	select {
+	case task := <-taskCh:
+		if task.block.NumberU64() != 1 || len(task.receipts) != 1 {
+			t.Errorf("block mismatch: have number %d with %d receipts, want 1 with 1", task.block.NumberU64(), len(task.receipts))
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("no block created after the round was won")
+	}
+}
+
 func TestNoTxChDeadLockValidator(t *testing.T)    { testNoTxChDeadlock(t, true) }
 func TestNoTxChDeadLockNonValidator(t *testing.T) { testNoTxChDeadlock(t, false) }
 
@@ -403,3 +490,24 @@ func testNoTxChDeadlock(t *testing.T, validating bool) {
 		t.Error("Deadlock in mainLoop's select statement")
 	}
 }
+
+// TestMinerCloseLeaks starts and stops mining repeatedly and checks that closing
+// the miner leaves none of its goroutines behind.
+func TestMinerCloseLeaks(t *testing.T) {
+	before := lifecycle.Running()
+	engine := mockEngine.NewFaker()
+	db := rawdb.NewMemoryDatabase()
+	b := newTestWorkerBackend(t, params.IstanbulTestChainConfig, engine, db, 0)
+	defer b.chain.Stop()
+	defer b.txPool.Stop()
+
+	miner := New(b, testConfig, params.IstanbulTestChainConfig, new(event.TypeMux), engine, db)
+	for i := 0; i < 3; i++ {
+		miner.Start(testBankAddress, testBankAddress)
+		miner.Stop()
+	}
+	miner.Close()
+	if leaked := lifecycle.Leaked(before); len(leaked) != 0 {
+		t.Errorf("goroutines leaked after closing the miner: %v", leaked)
+	}
+}
@@ -59,6 +59,20 @@ func (msg *CallMsg) GetTo() *Address {
 	return nil
 }
 
+func (msg *CallMsg) GetFeeCurrency() *Address {
+	if feeCurrency := msg.msg.FeeCurrency; feeCurrency != nil {
+		return &Address{*feeCurrency}
+	}
+	return nil
+}
+func (msg *CallMsg) GetGatewayFeeRecipient() *Address {
+	if recipient := msg.msg.GatewayFeeRecipient; recipient != nil {
+		return &Address{*recipient}
+	}
+	return nil
+}
+func (msg *CallMsg) GetGatewayFee() *BigInt { return &BigInt{msg.msg.GatewayFee} }
+
 func (msg *CallMsg) SetFrom(address *Address)  { msg.msg.From = address.address }
 func (msg *CallMsg) SetGas(gas int64)          { msg.msg.Gas = uint64(gas) }
 func (msg *CallMsg) SetGasPrice(price *BigInt) { msg.msg.GasPrice = price.bigint }
@@ -72,6 +86,22 @@ func (msg *CallMsg) SetTo(address *Address) {
 	msg.msg.To = &address.address
 }
 
+func (msg *CallMsg) SetFeeCurrency(address *Address) {
+	if address == nil {
+		msg.msg.FeeCurrency = nil
+		return
+	}
+	msg.msg.FeeCurrency = &address.address
+}
+func (msg *CallMsg) SetGatewayFeeRecipient(address *Address) {
+	if address == nil {
+		msg.msg.GatewayFeeRecipient = nil
+		return
+	}
+	msg.msg.GatewayFeeRecipient = &address.address
+}
+func (msg *CallMsg) SetGatewayFee(fee *BigInt) { msg.msg.GatewayFee = fee.bigint }
+
 // SyncProgress gives progress indications when the node is synchronising with
 // the Ethereum network.
 type SyncProgress struct {
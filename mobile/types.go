@@ -22,6 +22,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/big"
 
 	"github.com/celo-org/celo-blockchain/common"
 	"github.com/celo-org/celo-blockchain/core/types"
@@ -176,14 +177,25 @@ func NewContractCreation(nonce int64, amount *BigInt, gasLimit int64, gasPrice *
 	return &Transaction{types.NewContractCreation(uint64(nonce), amount.bigint, uint64(gasLimit), gasPrice.bigint, nil, nil, nil, common.CopyBytes(data))}
 }
 
-// NewTransaction creates a new transaction with the given properties.
+// NewTransaction creates a new transaction with the given properties. A nil fee
+// currency pays the fees in the native currency, and a nil gateway fee recipient
+// pays no gateway fee.
 func NewTransaction(nonce int64, to *Address, amount *BigInt, gasLimit int64, gasPrice *BigInt, feeCurrency, gatewayFeeRecipient *Address, gatewayFee *BigInt, data []byte) *Transaction {
-	convertedFeeCurrency := common.BytesToAddress(feeCurrency.GetBytes())
-	convertedGatewayFeeRecipient := common.BytesToAddress(gatewayFeeRecipient.GetBytes())
+	var convertedFeeCurrency, convertedGatewayFeeRecipient *common.Address
+	if feeCurrency != nil {
+		convertedFeeCurrency = &feeCurrency.address
+	}
+	if gatewayFeeRecipient != nil {
+		convertedGatewayFeeRecipient = &gatewayFeeRecipient.address
+	}
+	var convertedGatewayFee *big.Int
+	if gatewayFee != nil {
+		convertedGatewayFee = gatewayFee.bigint
+	}
 	if to == nil {
-		return &Transaction{types.NewContractCreation(uint64(nonce), amount.bigint, uint64(gasLimit), gasPrice.bigint, &convertedFeeCurrency, &convertedGatewayFeeRecipient, gatewayFee.bigint, common.CopyBytes(data))}
+		return &Transaction{types.NewContractCreation(uint64(nonce), amount.bigint, uint64(gasLimit), gasPrice.bigint, convertedFeeCurrency, convertedGatewayFeeRecipient, convertedGatewayFee, common.CopyBytes(data))}
 	}
-	return &Transaction{types.NewTransaction(uint64(nonce), to.address, amount.bigint, uint64(gasLimit), gasPrice.bigint, &convertedFeeCurrency, &convertedGatewayFeeRecipient, gatewayFee.bigint, common.CopyBytes(data))}
+	return &Transaction{types.NewTransaction(uint64(nonce), to.address, amount.bigint, uint64(gasLimit), gasPrice.bigint, convertedFeeCurrency, convertedGatewayFeeRecipient, convertedGatewayFee, common.CopyBytes(data))}
 }
 
 // NewTransactionFromRLP parses a transaction from an RLP data dump.
@@ -225,6 +237,29 @@ func (tx *Transaction) GetGasPrice() *BigInt { return &BigInt{tx.tx.GasPrice()}
 func (tx *Transaction) GetValue() *BigInt    { return &BigInt{tx.tx.Value()} }
 func (tx *Transaction) GetNonce() int64      { return int64(tx.tx.Nonce()) }
 
+// GetFeeCurrency returns the currency the fees are paid in, or nil for the native currency.
+func (tx *Transaction) GetFeeCurrency() *Address {
+	if feeCurrency := tx.tx.FeeCurrency(); feeCurrency != nil {
+		return &Address{*feeCurrency}
+	}
+	return nil
+}
+
+// GetGatewayFeeRecipient returns the recipient of the gateway fee, or nil for no gateway fee.
+func (tx *Transaction) GetGatewayFeeRecipient() *Address {
+	if recipient := tx.tx.GatewayFeeRecipient(); recipient != nil {
+		return &Address{*recipient}
+	}
+	return nil
+}
+
+func (tx *Transaction) GetGatewayFee() *BigInt {
+	if gatewayFee := tx.tx.GatewayFee(); gatewayFee != nil {
+		return &BigInt{gatewayFee}
+	}
+	return NewBigInt(0)
+}
+
 func (tx *Transaction) GetHash() *Hash   { return &Hash{tx.tx.Hash()} }
 func (tx *Transaction) GetCost() *BigInt { return &BigInt{tx.tx.Cost()} }
 
@@ -0,0 +1,59 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package geth
+
+import (
+	"testing"
+)
+
+func TestNewTransactionCeloFields(t *testing.T) {
+	to, _ := NewAddressFromHex("0x1000000000000000000000000000000000000001")
+	feeCurrency, _ := NewAddressFromHex("0x1000000000000000000000000000000000000002")
+	gateway, _ := NewAddressFromHex("0x1000000000000000000000000000000000000003")
+
+	// Fees in the native currency, without a gateway fee
+	tx := NewTransaction(1, to, NewBigInt(1), 21000, NewBigInt(1), nil, nil, nil, nil)
+	if tx.GetFeeCurrency() != nil || tx.GetGatewayFeeRecipient() != nil || tx.GetGatewayFee().GetInt64() != 0 {
+		t.Errorf("native transaction has Celo fields: fee currency %v, gateway %v, gateway fee %v", tx.GetFeeCurrency(), tx.GetGatewayFeeRecipient(), tx.GetGatewayFee())
+	}
+	tx = NewTransaction(1, to, NewBigInt(1), 21000, NewBigInt(1), feeCurrency, gateway, NewBigInt(5), nil)
+	if have := tx.GetFeeCurrency(); have == nil || have.GetHex() != feeCurrency.GetHex() {
+		t.Errorf("fee currency mismatch: have %v, want %v", have, feeCurrency)
+	}
+	if have := tx.GetGatewayFeeRecipient(); have == nil || have.GetHex() != gateway.GetHex() {
+		t.Errorf("gateway fee recipient mismatch: have %v, want %v", have, gateway)
+	}
+	if have := tx.GetGatewayFee().GetInt64(); have != 5 {
+		t.Errorf("gateway fee mismatch: have %d, want 5", have)
+	}
+	// The Celo fields survive the encoding
+	enc, err := tx.EncodeRLP()
+	if err != nil {
+		t.Fatalf("failed to encode transaction: %v", err)
+	}
+	dec, err := NewTransactionFromRLP(enc)
+	if err != nil {
+		t.Fatalf("failed to decode transaction: %v", err)
+	}
+	if dec.GetHash().GetHex() != tx.GetHash().GetHex() {
+		t.Errorf("decoded transaction hash mismatch: have %v, want %v", dec.GetHash(), tx.GetHash())
+	}
+	// Contract creations carry the Celo fields too
+	if creation := NewTransaction(1, nil, NewBigInt(0), 100000, NewBigInt(1), feeCurrency, nil, nil, []byte{0x00}); creation.GetTo() != nil || creation.GetFeeCurrency() == nil {
+		t.Errorf("contract creation mismatch: to %v, fee currency %v", creation.GetTo(), creation.GetFeeCurrency())
+	}
+}
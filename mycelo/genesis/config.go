@@ -27,6 +27,7 @@ type Config struct {
 	Istanbul         params.IstanbulConfig `json:"istanbul"`
 	Hardforks        HardforkConfig        `json:"hardforks"`
 	GenesisTimestamp uint64                `json:"genesisTimestamp"`
+	PinCoreContracts bool                  `json:"pinCoreContracts"` // Pin the registered core contract addresses in the chain config
 
 	SortedOracles              SortedOraclesParameters
 	GasPriceMinimum            GasPriceMinimumParameters
@@ -72,13 +72,17 @@ func GenerateGenesis(accounts *env.AccountsConfig, cfg *Config, contractsBuildPa
 		return nil, err
 	}
 
-	genesisAlloc, err := generateGenesisState(accounts, cfg, contractsBuildPath)
+	genesisAlloc, registered, err := generateGenesisState(accounts, cfg, contractsBuildPath)
 	if err != nil {
 		return nil, err
 	}
 
+	chainConfig := cfg.ChainConfig()
+	if cfg.PinCoreContracts {
+		chainConfig.CoreContracts = registered
+	}
 	return &core.Genesis{
-		Config:    cfg.ChainConfig(),
+		Config:    chainConfig,
 		ExtraData: extraData,
 		Coinbase:  accounts.AdminAccount().Address,
 		Timestamp: cfg.GenesisTimestamp,
@@ -31,15 +31,21 @@ type deployContext struct {
 	runtimeConfig *runtime.Config
 	truffleReader contract.TruffleReader
 	logger        log.Logger
+
+	registered map[string]common.Address // Addresses added to the registry, by name
 }
 
 // Helper function to reduce boilerplate, limited to this package on purpose
 // Like big.NewInt() except it takes uint64 instead of int64
 func newBigInt(x uint64) *big.Int { return new(big.Int).SetUint64(x) }
 
-func generateGenesisState(accounts *env.AccountsConfig, cfg *Config, buildPath string) (core.GenesisAlloc, error) {
+func generateGenesisState(accounts *env.AccountsConfig, cfg *Config, buildPath string) (core.GenesisAlloc, map[string]common.Address, error) {
 	deployment := newDeployment(cfg, accounts, buildPath)
-	return deployment.deploy()
+	alloc, err := deployment.deploy()
+	if err != nil {
+		return nil, nil, err
+	}
+	return alloc, deployment.registered, nil
 }
 
 // NewDeployment generates a new deployment
@@ -56,6 +62,7 @@ func newDeployment(genesisConfig *Config, accounts *env.AccountsConfig, buildPat
 		logger:        logger,
 		statedb:       statedb,
 		truffleReader: contract.NewTruffleReader(buildPath),
+		registered:    make(map[string]common.Address),
 		runtimeConfig: &runtime.Config{
 			ChainConfig: genesisConfig.ChainConfig(),
 			Origin:      adminAddress,
@@ -259,10 +266,15 @@ func (ctx *deployContext) deployCoreContract(name string, initialize func(contra
 
 	proxyAddress := env.MustProxyAddressFor(name)
 	ctx.logger.Info("Add entry to registry", "name", name, "address", proxyAddress)
-	if err := ctx.contract("Registry").SimpleCall("setAddressFor", name, proxyAddress); err != nil {
+	return ctx.register(name, proxyAddress)
+}
+
+// register adds the contract address to the registry
+func (ctx *deployContext) register(name string, address common.Address) error {
+	if err := ctx.contract("Registry").SimpleCall("setAddressFor", name, address); err != nil {
 		return err
 	}
-
+	ctx.registered[name] = address
 	return nil
 }
 
@@ -294,11 +306,7 @@ func (ctx *deployContext) deployTransferWhitelist() error {
 	}
 
 	logger.Info("Add to Registry")
-	if err := ctx.contract("Registry").SimpleCall("setAddressFor", name, contract.Address); err != nil {
-		return err
-	}
-
-	return nil
+	return ctx.register(name, contract.Address)
 }
 
 func (ctx *deployContext) deployMultiSig(name string, params MultiSigParameters) (common.Address, error) {
@@ -0,0 +1,168 @@
+package genesis
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+
+	"github.com/celo-org/celo-blockchain/core"
+	"github.com/celo-org/celo-blockchain/mycelo/env"
+	"github.com/celo-org/celo-blockchain/params"
+	"gopkg.in/yaml.v2"
+)
+
+// Spec is a declarative description of a network's genesis, usually read from
+// a YAML file. Everything not specified falls back to the common defaults.
+type Spec struct {
+	ChainID   *big.Int              `json:"chainId"`   // chainId identifies the network and is used for replay protection
+	Timestamp *uint64               `json:"timestamp"` // Genesis timestamp, defaults to the current time
+	Accounts  env.AccountsConfig    `json:"accounts"`  // Accounts of the initial validators, groups and developers
+	Istanbul  params.IstanbulConfig `json:"istanbul"`  // Consensus parameters
+
+	// Config overrides parameters of the default genesis configuration, using
+	// the same layout as mycelo's genesis-config.json.
+	Config json.RawMessage `json:"config"`
+}
+
+// defaultSpec returns the spec values used for any field left unspecified.
+func defaultSpec() *Spec {
+	return &Spec{
+		Accounts: env.AccountsConfig{
+			NumValidators:      1,
+			ValidatorsPerGroup: 1,
+		},
+		Istanbul: params.IstanbulConfig{
+			Epoch:          17280,
+			ProposerPolicy: 2,
+			LookbackWindow: 12,
+			BlockPeriod:    5,
+			RequestTimeout: 3000,
+		},
+	}
+}
+
+// DeveloperSpec returns the spec of a single validator developer chain, with
+// the given number of funded developer accounts and block period.
+func DeveloperSpec(mnemonic string, developerAccounts int, blockPeriod uint64) *Spec {
+	spec := defaultSpec()
+	spec.ChainID = new(big.Int).Set(params.DeveloperChainConfig.ChainID)
+	spec.Accounts.Mnemonic = mnemonic
+	spec.Accounts.NumDeveloperAccounts = developerAccounts
+	spec.Istanbul.BlockPeriod = blockPeriod
+	return spec
+}
+
+// LoadSpec reads a genesis spec from a YAML file.
+func LoadSpec(filepath string) (*Spec, error) {
+	blob, err := ioutil.ReadFile(filepath)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSpec(blob)
+}
+
+// ParseSpec parses a YAML genesis spec, filling in defaults and validating
+// the result.
+func ParseSpec(blob []byte) (*Spec, error) {
+	// Round trip through JSON, so the custom JSON decoders of the config types
+	// (big integers, fixed point fractions, durations) apply to YAML too.
+	var raw interface{}
+	if err := yaml.Unmarshal(blob, &raw); err != nil {
+		return nil, err
+	}
+	raw, err := yamlToJSON(raw)
+	if err != nil {
+		return nil, err
+	}
+	enc, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	spec := defaultSpec()
+	if err := json.Unmarshal(enc, spec); err != nil {
+		return nil, err
+	}
+	if err := spec.validate(); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// validate checks that the spec is complete enough to generate a genesis.
+func (s *Spec) validate() error {
+	switch {
+	case s.ChainID == nil || s.ChainID.Sign() <= 0:
+		return errors.New("missing or invalid chainId")
+	case s.Accounts.Mnemonic == "":
+		return errors.New("missing accounts mnemonic")
+	case s.Accounts.NumValidators <= 0:
+		return errors.New("at least one validator is required")
+	case s.Accounts.ValidatorsPerGroup <= 0:
+		return errors.New("validatorsPerGroup must be positive")
+	case s.Istanbul.Epoch == 0:
+		return errors.New("istanbul epoch must be positive")
+	}
+	return nil
+}
+
+// GenesisConfig builds the genesis configuration described by the spec,
+// funding the developer accounts and applying the config overrides on top of
+// the common defaults.
+func (s *Spec) GenesisConfig() (*Config, error) {
+	cfg := CreateCommonGenesisConfig(s.ChainID, s.Accounts.AdminAccount().Address, s.Istanbul)
+	if s.Timestamp != nil {
+		cfg.GenesisTimestamp = *s.Timestamp
+	}
+	if s.Accounts.NumDeveloperAccounts > 0 {
+		FundAccounts(cfg, s.Accounts.DeveloperAccounts())
+	}
+	if len(s.Config) > 0 && string(s.Config) != "null" {
+		if err := json.Unmarshal(s.Config, cfg); err != nil {
+			return nil, fmt.Errorf("invalid config overrides: %v", err)
+		}
+	}
+	return cfg, nil
+}
+
+// Generate creates the genesis block described by the spec, deploying the core
+// contracts from the truffle build artifacts in contractsBuildPath.
+func (s *Spec) Generate(contractsBuildPath string) (*core.Genesis, error) {
+	cfg, err := s.GenesisConfig()
+	if err != nil {
+		return nil, err
+	}
+	return GenerateGenesis(&s.Accounts, cfg, contractsBuildPath)
+}
+
+// yamlToJSON converts the generic maps produced by the YAML decoder into maps
+// with string keys, which the JSON encoder can handle.
+func yamlToJSON(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			str, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid non-string key %v", key)
+			}
+			conv, err := yamlToJSON(val)
+			if err != nil {
+				return nil, err
+			}
+			m[str] = conv
+		}
+		return m, nil
+	case []interface{}:
+		for i, val := range v {
+			conv, err := yamlToJSON(val)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = conv
+		}
+		return v, nil
+	}
+	return v, nil
+}
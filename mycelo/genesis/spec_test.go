@@ -0,0 +1,84 @@
+package genesis
+
+import (
+	"math/big"
+	"testing"
+)
+
+const testSpec = `
+chainId: 1101
+timestamp: 1600000000
+accounts:
+  mnemonic: "miss fire behind decide egg buyer honey seven advance uniform profit renew"
+  validators: 3
+  validatorsPerGroup: 2
+  developerAccounts: 2
+istanbul:
+  epoch: 720
+  blockperiod: 1
+config:
+  blockchain:
+    blockGasLimit: 20000000
+  election:
+    maxElectableValidators: 50
+    maxVotesPerAccount: "100"
+  goldToken:
+    initialBalances:
+      - account: "0x0000000000000000000000000000000000000001"
+        amount: "1000000000000000000000000"
+`
+
+func TestParseSpec(t *testing.T) {
+	spec, err := ParseSpec([]byte(testSpec))
+	if err != nil {
+		t.Fatalf("failed to parse spec: %v", err)
+	}
+	if spec.ChainID.Uint64() != 1101 {
+		t.Errorf("chain id mismatch: have %v, want 1101", spec.ChainID)
+	}
+	if spec.Accounts.NumValidators != 3 || spec.Accounts.NumValidatorGroups() != 2 {
+		t.Errorf("validator mismatch: have %d in %d groups, want 3 in 2", spec.Accounts.NumValidators, spec.Accounts.NumValidatorGroups())
+	}
+	// Unspecified consensus parameters keep their defaults
+	if spec.Istanbul.Epoch != 720 || spec.Istanbul.BlockPeriod != 1 || spec.Istanbul.RequestTimeout != 3000 {
+		t.Errorf("istanbul config mismatch: %+v", spec.Istanbul)
+	}
+	cfg, err := spec.GenesisConfig()
+	if err != nil {
+		t.Fatalf("failed to build genesis config: %v", err)
+	}
+	if cfg.GenesisTimestamp != 1600000000 {
+		t.Errorf("timestamp mismatch: have %d, want 1600000000", cfg.GenesisTimestamp)
+	}
+	if cfg.Blockchain.BlockGasLimit != 20000000 {
+		t.Errorf("gas limit mismatch: have %d, want 20000000", cfg.Blockchain.BlockGasLimit)
+	}
+	if cfg.Election.MaxElectableValidators != 50 || cfg.Election.MaxVotesPerAccount.Uint64() != 100 {
+		t.Errorf("election parameters mismatch: %+v", cfg.Election)
+	}
+	// Overrides are merged into, not replacing, the defaults
+	if base := BaseConfig(); cfg.Election.MinElectableValidators != base.Election.MinElectableValidators {
+		t.Errorf("default overwritten: have %d, want %d", cfg.Election.MinElectableValidators, base.Election.MinElectableValidators)
+	}
+	if len(cfg.StableToken.InitialBalances) != 2 {
+		t.Errorf("developer accounts not funded: have %d balances, want 2", len(cfg.StableToken.InitialBalances))
+	}
+	want, _ := new(big.Int).SetString("1000000000000000000000000", 10)
+	if balances := cfg.GoldToken.InitialBalances; len(balances) != 1 || balances[0].Amount.Cmp(want) != 0 {
+		t.Errorf("gold balances mismatch: %v", balances)
+	}
+}
+
+func TestParseSpecInvalid(t *testing.T) {
+	for i, blob := range []string{
+		`accounts: {mnemonic: "abc"}`,
+		`chainId: 1`,
+		`{chainId: 1, accounts: {mnemonic: "abc", validators: 0}}`,
+		`{chainId: 1, accounts: {mnemonic: "abc"}, istanbul: {epoch: 0}}`,
+		`chainId: [`,
+	} {
+		if _, err := ParseSpec([]byte(blob)); err == nil {
+			t.Errorf("test %d: invalid spec accepted", i)
+		}
+	}
+}
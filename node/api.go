@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/celo-org/celo-blockchain/common/hexutil"
 	"github.com/celo-org/celo-blockchain/crypto"
@@ -74,6 +75,9 @@ func (api *privateAdminAPI) AddPeer(url string) (bool, error) {
 	if err != nil {
 		return false, fmt.Errorf("invalid enode: %v", err)
 	}
+	if err := api.node.peers.add(staticPeers, node); err != nil {
+		return false, fmt.Errorf("failed to persist peer: %v", err)
+	}
 	server.AddPeer(node, p2p.ExplicitStaticPurpose)
 	return true, nil
 }
@@ -90,6 +94,9 @@ func (api *privateAdminAPI) RemovePeer(url string) (bool, error) {
 	if err != nil {
 		return false, fmt.Errorf("invalid enode: %v", err)
 	}
+	if err := api.node.peers.remove(staticPeers, node); err != nil {
+		return false, fmt.Errorf("failed to persist peer: %v", err)
+	}
 	server.RemovePeer(node, p2p.ExplicitStaticPurpose)
 	return true, nil
 }
@@ -105,6 +112,9 @@ func (api *privateAdminAPI) AddTrustedPeer(url string) (bool, error) {
 	if err != nil {
 		return false, fmt.Errorf("invalid enode: %v", err)
 	}
+	if err := api.node.peers.add(trustedPeers, node); err != nil {
+		return false, fmt.Errorf("failed to persist peer: %v", err)
+	}
 	server.AddTrustedPeer(node, p2p.ExplicitTrustedPurpose)
 	return true, nil
 }
@@ -121,6 +131,9 @@ func (api *privateAdminAPI) RemoveTrustedPeer(url string) (bool, error) {
 	if err != nil {
 		return false, fmt.Errorf("invalid enode: %v", err)
 	}
+	if err := api.node.peers.remove(trustedPeers, node); err != nil {
+		return false, fmt.Errorf("failed to persist peer: %v", err)
+	}
 	server.RemoveTrustedPeer(node, p2p.ExplicitTrustedPurpose)
 	return true, nil
 }
@@ -185,6 +198,7 @@ func (api *privateAdminAPI) StartRPC(host *string, port *int, cors *string, apis
 		CorsAllowedOrigins: api.node.config.HTTPCors,
 		Vhosts:             api.node.config.HTTPVirtualHosts,
 		Modules:            api.node.config.HTTPModules,
+		Auth:               api.node.apiKeys,
 	}
 	if cors != nil {
 		config.CorsAllowedOrigins = nil
@@ -217,6 +231,29 @@ func (api *privateAdminAPI) StartRPC(host *string, port *int, cors *string, apis
 	return true, nil
 }
 
+// SetHTTPPolicy replaces the comma separated CORS origins and virtual hosts
+// allowed by the HTTP server, leaving the omitted ones unchanged.
+func (api *privateAdminAPI) SetHTTPPolicy(cors *string, vhosts *string) (bool, error) {
+	api.node.lock.Lock()
+	defer api.node.lock.Unlock()
+
+	allowedOrigins, allowedHosts := api.node.config.HTTPCors, api.node.config.HTTPVirtualHosts
+	if cors != nil {
+		allowedOrigins = nil
+		for _, origin := range strings.Split(*cors, ",") {
+			allowedOrigins = append(allowedOrigins, strings.TrimSpace(origin))
+		}
+	}
+	if vhosts != nil {
+		allowedHosts = nil
+		for _, vhost := range strings.Split(*vhosts, ",") {
+			allowedHosts = append(allowedHosts, strings.TrimSpace(vhost))
+		}
+	}
+	api.node.setHTTPPolicy(allowedOrigins, allowedHosts)
+	return true, nil
+}
+
 // StopRPC shuts down the HTTP server.
 func (api *privateAdminAPI) StopRPC() (bool, error) {
 	api.node.http.stop()
@@ -244,6 +281,8 @@ func (api *privateAdminAPI) StartWS(host *string, port *int, allowedOrigins *str
 	config := wsConfig{
 		Modules: api.node.config.WSModules,
 		Origins: api.node.config.WSOrigins,
+		Limits:  api.node.config.wsLimits(),
+		Auth:    api.node.apiKeys,
 		// ExposeAll: api.node.config.WSExposeAll,
 	}
 	if apis != nil {
@@ -281,6 +320,55 @@ func (api *privateAdminAPI) StopWS() (bool, error) {
 	return true, nil
 }
 
+// AddAPIKey generates an API key authenticating HTTP and WebSocket RPC clients
+// with the given access. The key is returned once and cannot be retrieved later.
+func (api *privateAdminAPI) AddAPIKey(config APIKeyConfig) (*APIKey, error) {
+	if api.node.apiKeys == nil {
+		return nil, errRPCAuthDisabled
+	}
+	return api.node.apiKeys.add(config)
+}
+
+// RemoveAPIKey revokes an API key, refusing the clients using it from then on.
+func (api *privateAdminAPI) RemoveAPIKey(id string) (bool, error) {
+	if api.node.apiKeys == nil {
+		return false, errRPCAuthDisabled
+	}
+	return api.node.apiKeys.remove(id)
+}
+
+// ListAPIKeys returns the API keys and the access they grant.
+func (api *privateAdminAPI) ListAPIKeys() ([]APIKeyInfo, error) {
+	if api.node.apiKeys == nil {
+		return nil, errRPCAuthDisabled
+	}
+	return api.node.apiKeys.list(), nil
+}
+
+// IssueAPIToken returns a token authenticating as the given API key for ttl
+// seconds, an hour by default, so that the key itself needn't be handed out.
+func (api *privateAdminAPI) IssueAPIToken(id string, ttl *uint64) (string, error) {
+	if api.node.apiKeys == nil {
+		return "", errRPCAuthDisabled
+	}
+	lifetime := defaultAPITokenTTL
+	if ttl != nil {
+		lifetime = time.Duration(*ttl) * time.Second
+	}
+	return api.node.apiKeys.issueToken(id, lifetime)
+}
+
+// Drain stops accepting new HTTP and WebSocket requests, waits up to nsec
+// seconds for the requests being served to finish and then fails the readiness
+// probe, so the node can be withdrawn and restarted without failing client
+// requests.
+func (api *privateAdminAPI) Drain(nsec uint) (bool, error) {
+	if err := api.node.Drain(time.Duration(nsec) * time.Second); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // DiscoverTableInfo gives the content of all buckets and ips in the p2p
 // discover table
 func (api *privateAdminAPI) DiscoverTableInfo() (*discover.TableInfo, error) {
@@ -19,12 +19,16 @@ package node
 import (
 	"bytes"
 	"io"
+	"io/ioutil"
 	"net"
 	"net/http"
 	"net/url"
+	"os"
 	"strings"
 	"testing"
 
+	"github.com/celo-org/celo-blockchain/crypto"
+	"github.com/celo-org/celo-blockchain/p2p/enode"
 	"github.com/celo-org/celo-blockchain/rpc"
 	"github.com/stretchr/testify/assert"
 )
@@ -348,3 +352,61 @@ func not(ok bool) string {
 	}
 	return "not "
 }
+
+// This test checks that the peers modified through the admin API are applied
+// again when the node is restarted with peer persistence enabled.
+func TestPersistPeers(t *testing.T) {
+	datadir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("failed to create temporary data directory: %v", err)
+	}
+	defer os.RemoveAll(datadir)
+
+	var peers []*enode.Node
+	for i := 0; i < 4; i++ {
+		key, _ := crypto.GenerateKey()
+		peers = append(peers, enode.NewV4(&key.PublicKey, net.IP{127, 0, 0, 1}, 30303+i, 30303+i))
+	}
+	newNode := func() *Node {
+		config := testNodeConfig()
+		config.DataDir = datadir
+		config.PersistPeers = true
+		config.P2P.StaticNodes = peers[:2]
+		stack, err := New(config)
+		if err != nil {
+			t.Fatalf("failed to create node: %v", err)
+		}
+		return stack
+	}
+
+	stack := newNode()
+	if err := stack.Start(); err != nil {
+		t.Fatalf("failed to start node: %v", err)
+	}
+	api := &privateAdminAPI{stack}
+	if _, err := api.AddPeer(peers[2].URLv4()); err != nil {
+		t.Fatalf("failed to add peer: %v", err)
+	}
+	if _, err := api.RemovePeer(peers[0].URLv4()); err != nil {
+		t.Fatalf("failed to remove peer: %v", err)
+	}
+	if _, err := api.AddTrustedPeer(peers[3].URLv4()); err != nil {
+		t.Fatalf("failed to add trusted peer: %v", err)
+	}
+	stack.Close()
+
+	stack = newNode()
+	defer stack.Close()
+	checkNodes := func(list string, have, want []*enode.Node) {
+		if len(have) != len(want) {
+			t.Fatalf("%s peer count mismatch: have %d, want %d", list, len(have), len(want))
+		}
+		for i := range want {
+			if have[i].ID() != want[i].ID() {
+				t.Errorf("%s peer %d mismatch: have %v, want %v", list, i, have[i], want[i])
+			}
+		}
+	}
+	checkNodes("static", stack.server.Config.StaticNodes, peers[1:3])
+	checkNodes("trusted", stack.server.Config.TrustedNodes, peers[3:])
+}
@@ -25,6 +25,7 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/celo-org/celo-blockchain/accounts"
 	"github.com/celo-org/celo-blockchain/accounts/external"
@@ -43,6 +44,9 @@ const (
 	datadirDefaultKeyStore     = "keystore"           // Path within the datadir to the keystore
 	datadirStaticNodes         = "static-nodes.json"  // Path within the datadir to the static node list
 	datadirTrustedNodes        = "trusted-nodes.json" // Path within the datadir to the trusted node list
+	datadirPersistedPeers      = "admin-peers.json"   // Path within the datadir to the peers modified through the admin API
+	datadirAPIKeys             = "rpc-keys.json"      // Path within the datadir to the RPC API keys
+	datadirJWTSecret           = "rpc-jwtsecret"      // Path within the datadir to the secret signing RPC tokens
 	datadirNodeDatabase        = "nodes"              // Path within the datadir to store the node infos
 	datadirProxiedNodeDatabase = "proxied-nodes"
 )
@@ -70,6 +74,10 @@ type Config struct {
 	// in memory.
 	DataDir string
 
+	// DBEngine selects the key-value store backing the node's databases ("leveldb"
+	// or "pebble"). If empty, the engine of any pre-existing database is used.
+	DBEngine string `toml:",omitempty"`
+
 	// Specifies if this node is a proxy
 	Proxy bool
 
@@ -77,6 +85,11 @@ type Config struct {
 	P2P      p2p.Config
 	ProxyP2P p2p.Config
 
+	// PersistPeers saves the static and trusted peers added or removed through the
+	// admin API in the data directory, and applies them again when the node is
+	// restarted.
+	PersistPeers bool `toml:",omitempty"`
+
 	// KeyStoreDir is the file system folder that contains private keys. The directory can
 	// be specified as a relative path, in which case it is resolved relative to the
 	// current directory.
@@ -141,6 +154,25 @@ type Config struct {
 	// interface.
 	HTTPTimeouts rpc.HTTPTimeouts
 
+	// RPCSlowQueryThreshold is the duration above which RPC method calls are logged
+	// as slow queries, on all interfaces. Zero disables the slow query log.
+	RPCSlowQueryThreshold time.Duration `toml:",omitempty"`
+
+	// RPCAuth requires the HTTP and WebSocket RPC clients to authenticate with an
+	// API key, or a token issued for one. Keys are managed through the admin API
+	// and stored in the data directory.
+	RPCAuth bool `toml:",omitempty"`
+
+	// RPCJWTSecret is the path of the file holding the hex encoded secret signing
+	// the tokens issued for API keys. If empty, a secret is generated in the data
+	// directory.
+	RPCJWTSecret string `toml:",omitempty"`
+
+	// ShutdownTimeout is the time every subsystem is given to stop when the node
+	// shuts down, after which it is abandoned and the shutdown moves on. Zero
+	// waits for the subsystems indefinitely.
+	ShutdownTimeout time.Duration `toml:",omitempty"`
+
 	// WSHost is the host interface on which to start the websocket RPC server. If
 	// this field is empty, no websocket API endpoint will be started.
 	WSHost string
@@ -167,6 +199,19 @@ type Config struct {
 	// private APIs to untrusted users is a major security risk.
 	WSExposeAll bool `toml:",omitempty"`
 
+	// WSMaxSubscriptions is the maximum number of concurrent subscriptions of a
+	// websocket connection. Zero means unlimited.
+	WSMaxSubscriptions int `toml:",omitempty"`
+
+	// WSMaxQueuedWrites is the maximum number of outbound messages waiting to be
+	// written to a websocket connection. Connections exceeding it are closed.
+	// Zero means unlimited.
+	WSMaxQueuedWrites int `toml:",omitempty"`
+
+	// WSMaxMessageSize is the maximum size in bytes of a message received over a
+	// websocket connection. Zero uses the default request size limit.
+	WSMaxMessageSize int64 `toml:",omitempty"`
+
 	// GraphQLCors is the Cross-Origin Resource Sharing header to send to requesting
 	// clients. Please be aware that CORS is a browser enforced security, it's fully
 	// useless for custom HTTP clients.
@@ -266,6 +311,33 @@ func (c *Config) WSEndpoint() string {
 	return fmt.Sprintf("%s:%d", c.WSHost, c.WSPort)
 }
 
+// wsLimits returns the resource limits of websocket connections.
+func (c *Config) wsLimits() rpc.WebsocketLimits {
+	return rpc.WebsocketLimits{
+		MaxSubscriptions: c.WSMaxSubscriptions,
+		MaxQueuedWrites:  c.WSMaxQueuedWrites,
+		MaxMessageSize:   c.WSMaxMessageSize,
+	}
+}
+
+// apiKeysPath returns the path to the RPC API keys, empty if they are only kept
+// in memory.
+func (c *Config) apiKeysPath() string {
+	if c.DataDir == "" {
+		return "" // ephemeral
+	}
+	return c.ResolvePath(datadirAPIKeys)
+}
+
+// jwtSecretPath returns the path to the secret signing RPC tokens, empty if it is
+// only kept in memory.
+func (c *Config) jwtSecretPath() string {
+	if c.RPCJWTSecret != "" || c.DataDir == "" {
+		return c.RPCJWTSecret
+	}
+	return c.ResolvePath(datadirJWTSecret)
+}
+
 // DefaultWSEndpoint returns the websocket endpoint used by default.
 func DefaultWSEndpoint() string {
 	config := &Config{WSHost: DefaultWSHost, WSPort: DefaultWSPort}
@@ -29,3 +29,4 @@ type Lifecycle interface {
 	// are all terminated.
 	Stop() error
 }
+
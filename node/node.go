@@ -25,10 +25,13 @@ import (
 	"reflect"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/celo-org/celo-blockchain/accounts"
 	"github.com/celo-org/celo-blockchain/core/rawdb"
 	"github.com/celo-org/celo-blockchain/ethdb"
+	"github.com/celo-org/celo-blockchain/ethdb/objectstore"
 	"github.com/celo-org/celo-blockchain/event"
 	"github.com/celo-org/celo-blockchain/log"
 	"github.com/celo-org/celo-blockchain/p2p"
@@ -47,8 +50,11 @@ type Node struct {
 	stop          chan struct{}     // Channel to wait for termination notifications
 	server        *p2p.Server       // Currently running P2P networking layer
 	proxyServer   *p2p.Server
-	startStopLock sync.Mutex // Start/Stop are protected by an additional lock
-	state         int        // Tracks state of node lifecycle
+	peers         *peerStore   // Static and trusted peers modified through the admin API, nil if not persisted
+	apiKeys       *apiKeyStore // API keys authenticating HTTP and WebSocket clients, nil if not required
+	startStopLock sync.Mutex   // Start/Stop are protected by an additional lock
+	state         int          // Tracks state of node lifecycle
+	unready       int32        // Set once the node is drained, fails the readiness probe
 
 	lock          sync.Mutex
 	lifecycles    []Lifecycle // All registered backends, services, and auxiliary services that have a lifecycle
@@ -96,6 +102,10 @@ func New(conf *Config) (*Node, error) {
 		return nil, errors.New(`Config.Name cannot end in ".ipc"`)
 	}
 
+	if conf.RPCSlowQueryThreshold > 0 {
+		rpc.SetSlowQueryThreshold(conf.RPCSlowQueryThreshold)
+	}
+
 	node := &Node{
 		config:        conf,
 		inprocHandler: rpc.NewServer(),
@@ -132,6 +142,18 @@ func New(conf *Config) (*Node, error) {
 	if node.server.Config.TrustedNodes == nil {
 		node.server.Config.TrustedNodes = node.config.TrustedNodes()
 	}
+	if conf.PersistPeers && conf.DataDir != "" {
+		if node.peers, err = newPeerStore(node.config.ResolvePath(datadirPersistedPeers)); err != nil {
+			return nil, err
+		}
+		node.server.Config.StaticNodes = node.peers.apply(staticPeers, node.server.Config.StaticNodes)
+		node.server.Config.TrustedNodes = node.peers.apply(trustedPeers, node.server.Config.TrustedNodes)
+	}
+	if conf.RPCAuth {
+		if node.apiKeys, err = newAPIKeyStore(node.config.apiKeysPath(), node.config.jwtSecretPath()); err != nil {
+			return nil, err
+		}
+	}
 	if node.server.Config.NodeDatabase == "" {
 		node.server.Config.NodeDatabase = node.config.NodeDB()
 	}
@@ -156,6 +178,7 @@ func New(conf *Config) (*Node, error) {
 	node.http = newHTTPServer(node.log, conf.HTTPTimeouts)
 	node.ws = newHTTPServer(node.log, rpc.DefaultHTTPTimeouts)
 	node.ipc = newIPCServer(node.log, conf.IPCEndpoint())
+	node.RegisterHandler("Readiness probe", readinessPath, http.HandlerFunc(node.serveReadiness))
 
 	return node, nil
 }
@@ -229,14 +252,50 @@ func (n *Node) Close() error {
 	}
 }
 
+// Drain prepares the node for being shut down without failing client requests.
+// It stops accepting new HTTP and WebSocket requests and subscriptions, waits up
+// to the given timeout for the requests being served and finally fails the
+// readiness probe, which load balancers and service discoveries polling it use
+// to withdraw the node. IPC and in-process connections are not affected, so the
+// node can still be managed.
+func (n *Node) Drain(timeout time.Duration) error {
+	n.lock.Lock()
+	running := n.state == runningState
+	n.lock.Unlock()
+	if !running {
+		return ErrNodeStopped
+	}
+
+	n.log.Info("Draining node", "timeout", timeout)
+	deadline := time.Now().Add(timeout)
+	inflight := n.http.drain(timeout)
+	inflight += n.ws.drain(time.Until(deadline))
+
+	atomic.StoreInt32(&n.unready, 1)
+
+	if inflight > 0 {
+		n.log.Warn("Node drained with requests in flight", "requests", inflight)
+		return fmt.Errorf("%d requests still in flight after %v", inflight, timeout)
+	}
+	n.log.Info("Node drained")
+	return nil
+}
+
+// serveReadiness answers the readiness probe, failing once the node is drained.
+func (n *Node) serveReadiness(w http.ResponseWriter, r *http.Request) {
+	if atomic.LoadInt32(&n.unready) != 0 {
+		http.Error(w, "drained", http.StatusServiceUnavailable)
+		return
+	}
+	w.Write([]byte("OK"))
+}
+
 // doClose releases resources acquired by New(), collecting errors.
 func (n *Node) doClose(errs []error) error {
-	// Close databases. This needs the lock because it needs to
-	// synchronize with OpenDatabase*.
 	n.lock.Lock()
 	n.state = closedState
-	errs = append(errs, n.closeDatabases()...)
 	n.lock.Unlock()
+	errs = append(errs, n.closeDatabases()...)
 
 	if err := n.accman.Close(); err != nil {
 		errs = append(errs, err)
@@ -301,25 +360,54 @@ func containsLifecycle(lfs []Lifecycle, l Lifecycle) bool {
 // stopServices terminates running services, RPC and p2p networking.
 // It is the inverse of Start.
 func (n *Node) stopServices(running []Lifecycle) error {
-	n.stopRPC()
+	// Gather the shutdown steps of the running lifecycles in reverse order,
+	// remembering which lifecycle every step belongs to.
+	var (
+		steps  []ShutdownStep
+		owners []Lifecycle
+	)
+	for i := len(running) - 1; i >= 0; i-- {
+		lifecycle := running[i]
+		if staged, ok := lifecycle.(StagedStopper); ok {
+			for _, step := range staged.ShutdownSteps() {
+				steps = append(steps, step)
+				owners = append(owners, lifecycle)
+			}
+		}
+		steps = append(steps, ShutdownStep{Stage: ShutdownServices, Name: fmt.Sprintf("%T", lifecycle), Stop: lifecycle.Stop})
+		owners = append(owners, lifecycle)
+	}
+	steps = append(steps,
+		ShutdownStep{Stage: ShutdownRPC, Name: "rpc", Stop: func() error { n.stopRPC(); return nil }},
+		ShutdownStep{Stage: ShutdownP2P, Name: "p2p", Stop: n.stopNetworking},
+	)
 
-	// Stop running lifecycles in reverse order.
 	failure := &StopError{Services: make(map[reflect.Type]error)}
-	for i := len(running) - 1; i >= 0; i-- {
-		if err := running[i].Stop(); err != nil {
-			failure.Services[reflect.TypeOf(running[i])] = err
+	for i, err := range n.runShutdown(steps) {
+		if err == nil {
+			continue
+		}
+		if i >= len(owners) {
+			failure.Server = err
+			continue
+		}
+		typ := reflect.TypeOf(owners[i])
+		if _, ok := failure.Services[typ]; !ok {
+			failure.Services[typ] = err
 		}
 	}
+	if len(failure.Services) > 0 || failure.Server != nil {
+		return failure
+	}
+	return nil
+}
 
-	// Stop p2p networking.
+// stopNetworking stops the peer-to-peer servers.
+func (n *Node) stopNetworking() error {
 	n.server.Stop()
 	if n.proxyServer != nil {
 		n.proxyServer.Stop()
 	}
-
-	if len(failure.Services) > 0 {
-		return failure
-	}
 	return nil
 }
 
@@ -373,6 +461,7 @@ func (n *Node) startRPC() error {
 			CorsAllowedOrigins: n.config.HTTPCors,
 			Vhosts:             n.config.HTTPVirtualHosts,
 			Modules:            n.config.HTTPModules,
+			Auth:               n.apiKeys,
 		}
 		if err := n.http.setListenAddr(n.config.HTTPHost, n.config.HTTPPort); err != nil {
 			return err
@@ -388,6 +477,8 @@ func (n *Node) startRPC() error {
 		config := wsConfig{
 			Modules: n.config.WSModules,
 			Origins: n.config.WSOrigins,
+			Limits:  n.config.wsLimits(),
+			Auth:    n.apiKeys,
 		}
 		if err := server.setListenAddr(n.config.WSHost, n.config.WSPort); err != nil {
 			return err
@@ -566,6 +657,22 @@ func (n *Node) WSEndpoint() string {
 	return "ws://" + n.ws.listenAddr()
 }
 
+// SetHTTPPolicy replaces the CORS origins and virtual hosts allowed by the HTTP
+// RPC server, without restarting it. The policy also applies when the server is
+// started later on through the admin API.
+func (n *Node) SetHTTPPolicy(cors, vhosts []string) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.setHTTPPolicy(cors, vhosts)
+}
+
+// setHTTPPolicy replaces the HTTP RPC policy, the caller must hold n.lock.
+func (n *Node) setHTTPPolicy(cors, vhosts []string) {
+	n.config.HTTPCors, n.config.HTTPVirtualHosts = cors, vhosts
+	n.http.setPolicy(cors, vhosts)
+}
+
 // EventMux retrieves the event multiplexer used by all the network services in
 // the current protocol stack.
 func (n *Node) EventMux() *event.TypeMux {
@@ -587,7 +694,7 @@ func (n *Node) OpenDatabase(name string, cache, handles int, namespace string) (
 	if n.config.DataDir == "" {
 		db = rawdb.NewMemoryDatabase()
 	} else {
-		db, err = rawdb.NewLevelDBDatabase(n.ResolvePath(name), cache, handles, namespace)
+		db, err = rawdb.NewEngineDatabase(n.config.DBEngine, n.ResolvePath(name), cache, handles, namespace)
 	}
 
 	if err == nil {
@@ -602,6 +709,14 @@ func (n *Node) OpenDatabase(name string, cache, handles int, namespace string) (
 // database to immutable append-only files. If the node is an ephemeral one, a
 // memory database is returned.
 func (n *Node) OpenDatabaseWithFreezer(name string, cache, handles int, freezer, namespace string) (ethdb.Database, error) {
+	return n.OpenDatabaseWithRemoteFreezer(name, cache, handles, freezer, "", 0, namespace)
+}
+
+// OpenDatabaseWithRemoteFreezer is like OpenDatabaseWithFreezer, but offloads
+// sealed ancient data files to the object store at remote (e.g. s3://bucket/prefix),
+// keeping only cacheFiles of them per freezer table on local disk. An empty
+// remote keeps all ancient data local.
+func (n *Node) OpenDatabaseWithRemoteFreezer(name string, cache, handles int, freezer, remote string, cacheFiles int, namespace string) (ethdb.Database, error) {
 	n.lock.Lock()
 	defer n.lock.Unlock()
 	if n.state == closedState {
@@ -620,7 +735,13 @@ func (n *Node) OpenDatabaseWithFreezer(name string, cache, handles int, freezer,
 		case !filepath.IsAbs(freezer):
 			freezer = n.ResolvePath(freezer)
 		}
-		db, err = rawdb.NewLevelDBDatabaseWithFreezer(root, cache, handles, freezer, namespace)
+		var store rawdb.AncientRemote
+		if remote != "" {
+			if store, err = objectstore.NewS3(remote); err != nil {
+				return nil, err
+			}
+		}
+		db, err = rawdb.NewEngineDatabaseWithRemoteFreezer(n.config.DBEngine, root, cache, handles, freezer, store, cacheFiles, namespace)
 	}
 
 	if err == nil {
@@ -656,11 +777,20 @@ func (n *Node) wrapDatabase(db ethdb.Database) ethdb.Database {
 	return wrapper
 }
 
-// closeDatabases closes all open databases.
+// closeDatabases closes all open databases. The databases are taken under the
+// lock to synchronize with OpenDatabase*, but closed without it so that a
+// database failing to close in time does not block the node.
 func (n *Node) closeDatabases() (errors []error) {
+	var steps []ShutdownStep
+	n.lock.Lock()
 	for db := range n.databases {
 		delete(n.databases, db)
-		if err := db.Database.Close(); err != nil {
+		steps = append(steps, ShutdownStep{Stage: ShutdownDatabase, Name: "database", Stop: db.Database.Close})
+	}
+	n.lock.Unlock()
+
+	for _, err := range n.runShutdown(steps) {
+		if err != nil {
 			errors = append(errors, err)
 		}
 	}
@@ -27,6 +27,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/celo-org/celo-blockchain/crypto"
 	"github.com/celo-org/celo-blockchain/ethdb"
@@ -389,6 +390,80 @@ func TestLifecycleTerminationGuarantee(t *testing.T) {
 	stack.server.PrivateKey = testNodeKey
 }
 
+// Tests that the shutdown steps of lifecycles are run ordered by stage, before
+// the lifecycles are stopped.
+func TestShutdownOrdering(t *testing.T) {
+	stack, err := New(testNodeConfig())
+	if err != nil {
+		t.Fatalf("failed to create protocol stack: %v", err)
+	}
+	var stopped []string
+	step := func(stage ShutdownStage, name string) ShutdownStep {
+		return ShutdownStep{Stage: stage, Name: name, Stop: func() error {
+			stopped = append(stopped, name)
+			return nil
+		}}
+	}
+	a := &StagedService{
+		InstrumentedService: InstrumentedService{stopHook: func() { stopped = append(stopped, "A") }},
+		steps:               []ShutdownStep{step(ShutdownTxPool, "A/txpool"), step(ShutdownConsensus, "A/consensus")},
+	}
+	b := &StagedService{
+		InstrumentedService: InstrumentedService{stopHook: func() { stopped = append(stopped, "B") }},
+		steps:               []ShutdownStep{step(ShutdownMiner, "B/miner")},
+	}
+	stack.RegisterLifecycle(a)
+	stack.RegisterLifecycle(b)
+	if err := stack.Start(); err != nil {
+		t.Fatalf("failed to start protocol stack: %v", err)
+	}
+	if err := stack.Close(); err != nil {
+		t.Fatalf("failed to close protocol stack: %v", err)
+	}
+	want := []string{"A/consensus", "B/miner", "A/txpool", "B", "A"}
+	if !reflect.DeepEqual(stopped, want) {
+		t.Fatalf("shutdown order mismatch: have %v, want %v", stopped, want)
+	}
+}
+
+// Tests that a subsystem failing to stop in time is abandoned and does not
+// prevent the others from being stopped.
+func TestShutdownTimeout(t *testing.T) {
+	config := testNodeConfig()
+	config.ShutdownTimeout = 50 * time.Millisecond
+	stack, err := New(config)
+	if err != nil {
+		t.Fatalf("failed to create protocol stack: %v", err)
+	}
+	hang := make(chan struct{})
+	defer close(hang)
+
+	stuck := &StagedService{steps: []ShutdownStep{{
+		Stage: ShutdownConsensus,
+		Name:  "stuck",
+		Stop:  func() error { <-hang; return nil },
+	}}}
+	stopped := false
+	other := &InstrumentedService{stopHook: func() { stopped = true }}
+	stack.RegisterLifecycle(stuck)
+	stack.RegisterLifecycle(other)
+	if err := stack.Start(); err != nil {
+		t.Fatalf("failed to start protocol stack: %v", err)
+	}
+
+	err = stack.Close()
+	if err, ok := err.(*StopError); !ok {
+		t.Fatalf("termination failure mismatch: have %v, want StopError", err)
+	} else if have := err.Services[reflect.TypeOf(stuck)]; have != ErrShutdownTimeout {
+		t.Fatalf("stuck termination failure mismatch: have %v, want %v", have, ErrShutdownTimeout)
+	} else if len(err.Services) != 1 {
+		t.Fatalf("failure count mismatch: have %d, want %d", len(err.Services), 1)
+	}
+	if !stopped {
+		t.Fatalf("service not terminated after a stuck shutdown step")
+	}
+}
+
 // Tests whether a handler can be successfully mounted on the canonical HTTP server
 // on the given path
 func TestRegisterHandler_Successful(t *testing.T) {
@@ -486,6 +561,32 @@ func TestWebsocketHTTPOnSeparatePort_WSRequest(t *testing.T) {
 
 }
 
+// Tests that a drained node refuses new requests and fails the readiness probe.
+func TestNodeDrain(t *testing.T) {
+	node := createNode(t, 0, 0)
+	if err := node.Start(); err != nil {
+		t.Fatalf("could not start node: %v", err)
+	}
+	defer node.Close()
+
+	ready := node.HTTPEndpoint() + readinessPath
+	if resp, err := http.Get(ready); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("node not ready before draining: %v %v", resp, err)
+	}
+	if err := node.Drain(time.Second); err != nil {
+		t.Fatalf("drain failed: %v", err)
+	}
+	if resp, err := http.Get(ready); err != nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("node still ready after draining: %v %v", resp, err)
+	}
+	if checkRPC(node.HTTPEndpoint()) {
+		t.Error("http request served after draining")
+	}
+	if checkRPC(node.WSEndpoint()) {
+		t.Error("websocket connection accepted after draining")
+	}
+}
+
 func createNode(t *testing.T, httpPort, wsPort int) *Node {
 	conf := &Config{
 		HTTPHost: "127.0.0.1",
@@ -0,0 +1,167 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/p2p/enode"
+)
+
+// peerList selects the static or the trusted peers of the peer store.
+type peerList int
+
+const (
+	staticPeers peerList = iota
+	trustedPeers
+)
+
+// persistedPeers is the on-disk format of the peer store. Peers are stored as
+// enode URLs, the removed lists hold the peers removed at runtime, so that they
+// are not added back from the node configuration on restart.
+type persistedPeers struct {
+	Static         []string `json:"static,omitempty"`
+	RemovedStatic  []string `json:"removedStatic,omitempty"`
+	Trusted        []string `json:"trusted,omitempty"`
+	RemovedTrusted []string `json:"removedTrusted,omitempty"`
+}
+
+// lists returns the added and removed peers of the given list.
+func (p *persistedPeers) lists(list peerList) (added, removed *[]string) {
+	if list == trustedPeers {
+		return &p.Trusted, &p.RemovedTrusted
+	}
+	return &p.Static, &p.RemovedStatic
+}
+
+// peerStore persists the static and trusted peer modifications made through the
+// admin API. A nil peer store persists nothing.
+type peerStore struct {
+	path  string
+	lock  sync.Mutex
+	peers persistedPeers
+}
+
+// newPeerStore loads the peer store saved at the given path, if any.
+func newPeerStore(path string) (*peerStore, error) {
+	s := &peerStore{path: path}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return s, nil
+	}
+	if err := common.LoadJSON(path, &s.peers); err != nil {
+		return nil, fmt.Errorf("can't load persisted peers: %v", err)
+	}
+	for _, list := range []peerList{staticPeers, trustedPeers} {
+		added, removed := s.peers.lists(list)
+		for _, url := range append(append([]string{}, *added...), *removed...) {
+			if _, err := enode.Parse(enode.ValidSchemes, url); err != nil {
+				return nil, fmt.Errorf("persisted peer %s: %v", url, err)
+			}
+		}
+	}
+	return s, nil
+}
+
+// apply returns the configured nodes of the given list with the persisted
+// modifications applied.
+func (s *peerStore) apply(list peerList, nodes []*enode.Node) []*enode.Node {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	added, removed := s.peers.lists(list)
+	var result []*enode.Node
+	for _, node := range nodes {
+		if indexOf(*added, node.ID()) < 0 && indexOf(*removed, node.ID()) < 0 {
+			result = append(result, node)
+		}
+	}
+	for _, url := range *added {
+		result = append(result, enode.MustParse(url))
+	}
+	return result
+}
+
+// add records a peer added to the given list.
+func (s *peerStore) add(list peerList, node *enode.Node) error {
+	return s.update(list, node, true)
+}
+
+// remove records a peer removed from the given list.
+func (s *peerStore) remove(list peerList, node *enode.Node) error {
+	return s.update(list, node, false)
+}
+
+func (s *peerStore) update(list peerList, node *enode.Node, add bool) error {
+	if s == nil {
+		return nil
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	peers := s.peers
+	added, removed := peers.lists(list)
+	*added, *removed = without(*added, node.ID()), without(*removed, node.ID())
+	if add {
+		*added = append(*added, node.URLv4())
+	} else {
+		*removed = append(*removed, node.URLv4())
+	}
+	if err := s.save(&peers); err != nil {
+		return err
+	}
+	s.peers = peers
+	return nil
+}
+
+// save atomically replaces the peer store file.
+func (s *peerStore) save(peers *persistedPeers) error {
+	blob, err := json.MarshalIndent(peers, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, blob, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// indexOf returns the index of the URL of the given node, or -1.
+func indexOf(urls []string, id enode.ID) int {
+	for i, url := range urls {
+		if enode.MustParse(url).ID() == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// without returns a copy of the URLs without the given node.
+func without(urls []string, id enode.ID) []string {
+	result := make([]string, 0, len(urls))
+	for _, url := range urls {
+		if enode.MustParse(url).ID() != id {
+			result = append(result, url)
+		}
+	}
+	return result
+}
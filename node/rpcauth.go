@@ -0,0 +1,489 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/log"
+	"github.com/celo-org/celo-blockchain/rpc"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// jwtSecretLength is the length in bytes of a generated token secret.
+	jwtSecretLength = 32
+
+	// apiKeyLength is the length in bytes of a generated API key.
+	apiKeyLength = 32
+
+	// defaultAPITokenTTL is the lifetime of the tokens issued without one.
+	defaultAPITokenTTL = time.Hour
+)
+
+var (
+	errAPIKeyMissing   = errors.New("missing API key")
+	errAPIKeyInvalid   = errors.New("invalid API key")
+	errAPIKeyUnknown   = errors.New("unknown API key")
+	errAPITokenInvalid = errors.New("invalid API token")
+	errAPITokenExpired = errors.New("expired API token")
+	errRPCAuthDisabled = errors.New("RPC authentication is disabled")
+
+	// jwtHeader is the encoded header of the issued tokens, which are HS256 JWTs.
+	jwtHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+)
+
+// apiKeyError is an error returned to the RPC calls an API key doesn't allow.
+type apiKeyError struct {
+	code    int
+	message string
+}
+
+func (e *apiKeyError) Error() string  { return e.message }
+func (e *apiKeyError) ErrorCode() int { return e.code }
+
+// APIKeyConfig is the access granted to an API key.
+type APIKeyConfig struct {
+	// Methods are the methods the key may call, either by name, by namespace
+	// such as "eth_*", or "*". An empty list allows all the methods served by
+	// the endpoint.
+	Methods []string `json:"methods,omitempty"`
+
+	// Origins are the browser origins the key may be used from. An empty list
+	// allows any origin, as well as clients that are not browsers.
+	Origins []string `json:"origins,omitempty"`
+
+	// Rate is the number of calls per second the key may make, zero for no limit.
+	// Burst is the number of calls above the rate allowed at once, at least one
+	// and by default the rate rounded up.
+	Rate  float64 `json:"rate,omitempty"`
+	Burst int     `json:"burst,omitempty"`
+}
+
+// APIKeyInfo describes an API key, without its secret.
+type APIKeyInfo struct {
+	ID string `json:"id"`
+	APIKeyConfig
+}
+
+// APIKey is a newly generated API key. The key itself is not stored by the node
+// and cannot be retrieved later.
+type APIKey struct {
+	ID  string `json:"id"`
+	Key string `json:"key"`
+}
+
+// persistedAPIKey is the on-disk format of an API key, which holds the hash of
+// the key instead of the key.
+type persistedAPIKey struct {
+	APIKeyInfo
+	Hash common.Hash `json:"hash"`
+}
+
+// apiKey is an API key in use.
+type apiKey struct {
+	persistedAPIKey
+	limiter *rate.Limiter // Limiter of the calls, nil if unlimited
+}
+
+// apiKeyClaims are the claims of the tokens issued for API keys.
+type apiKeyClaims struct {
+	Subject   string `json:"sub"`
+	IssuedAt  int64  `json:"iat"`
+	NotBefore int64  `json:"nbf,omitempty"`
+	Expires   int64  `json:"exp"`
+}
+
+// apiKeyStore authenticates the HTTP and WebSocket RPC clients with the API keys
+// managed through the admin API, or with tokens issued for them, and authorizes
+// their calls according to the access granted to the keys.
+type apiKeyStore struct {
+	path   string // Path of the keys file, empty if the keys are kept in memory
+	secret []byte // Secret signing the tokens
+	now    func() time.Time
+
+	lock   sync.RWMutex
+	keys   map[string]*apiKey      // API keys by ID
+	hashes map[common.Hash]*apiKey // API keys by hash of the key
+}
+
+// newAPIKeyStore loads the API keys saved at the given path, if any, and the
+// token secret at secretPath, generating it if the file doesn't exist. Empty
+// paths keep the keys, or the secret, in memory.
+func newAPIKeyStore(path, secretPath string) (*apiKeyStore, error) {
+	secret, err := loadJWTSecret(secretPath)
+	if err != nil {
+		return nil, err
+	}
+	s := &apiKeyStore{
+		path:   path,
+		secret: secret,
+		now:    time.Now,
+		keys:   make(map[string]*apiKey),
+		hashes: make(map[common.Hash]*apiKey),
+	}
+	if path == "" {
+		log.Warn("RPC API keys are not persisted without a data directory")
+		return s, nil
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return s, nil
+	}
+	var keys []persistedAPIKey
+	if err := common.LoadJSON(path, &keys); err != nil {
+		return nil, fmt.Errorf("can't load API keys: %v", err)
+	}
+	for _, key := range keys {
+		if err := key.validate(); err != nil {
+			return nil, fmt.Errorf("API key %s: %v", key.ID, err)
+		}
+		s.insert(key)
+	}
+	return s, nil
+}
+
+// loadJWTSecret reads the hex encoded secret at the given path, generating it if
+// the file doesn't exist. An empty path generates a secret kept in memory.
+func loadJWTSecret(path string) ([]byte, error) {
+	if path != "" {
+		blob, err := ioutil.ReadFile(path)
+		if err == nil {
+			secret, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(string(blob)), "0x"))
+			if err != nil {
+				return nil, fmt.Errorf("invalid RPC token secret %s: %v", path, err)
+			}
+			if len(secret) < jwtSecretLength {
+				return nil, fmt.Errorf("RPC token secret %s is shorter than %d bytes", path, jwtSecretLength)
+			}
+			return secret, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	secret := make([]byte, jwtSecretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	if path != "" {
+		if err := ioutil.WriteFile(path, []byte(hex.EncodeToString(secret)), 0600); err != nil {
+			return nil, err
+		}
+		log.Info("Generated RPC token secret", "path", path)
+	}
+	return secret, nil
+}
+
+// validate checks the access granted to an API key.
+func (c *APIKeyConfig) validate() error {
+	for _, method := range c.Methods {
+		if strings.Contains(method, "*") && method != "*" && (!strings.HasSuffix(method, "_*") || strings.Count(method, "*") > 1) {
+			return fmt.Errorf("invalid method pattern %q", method)
+		}
+	}
+	if c.Rate < 0 || math.IsNaN(c.Rate) || math.IsInf(c.Rate, 0) {
+		return fmt.Errorf("invalid rate %v", c.Rate)
+	}
+	if c.Burst < 0 {
+		return fmt.Errorf("invalid burst %d", c.Burst)
+	}
+	return nil
+}
+
+// allows reports whether the API key may call the given method.
+func (k *apiKey) allows(method string) bool {
+	if len(k.Methods) == 0 {
+		return true
+	}
+	for _, allowed := range k.Methods {
+		switch {
+		case allowed == "*" || allowed == method:
+			return true
+		case strings.HasSuffix(allowed, "_*") && strings.HasPrefix(method, allowed[:len(allowed)-1]):
+			return true
+		}
+	}
+	return false
+}
+
+// allowsOrigin reports whether the API key may be used from the given origin.
+func (k *apiKey) allowsOrigin(origin string) bool {
+	if len(k.Origins) == 0 {
+		return true
+	}
+	for _, allowed := range k.Origins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// insert adds an API key to the store, the caller must hold the lock.
+func (s *apiKeyStore) insert(key persistedAPIKey) {
+	k := &apiKey{persistedAPIKey: key}
+	if key.Rate > 0 {
+		burst := key.Burst
+		if burst == 0 {
+			burst = int(math.Ceil(key.Rate))
+		}
+		k.limiter = rate.NewLimiter(rate.Limit(key.Rate), burst)
+	}
+	s.keys[key.ID] = k
+	s.hashes[key.Hash] = k
+}
+
+// add generates an API key with the given access.
+func (s *apiKeyStore) add(config APIKeyConfig) (*APIKey, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	var id [8]byte
+	secret := make([]byte, apiKeyLength)
+	if _, err := rand.Read(id[:]); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	key := &APIKey{ID: hex.EncodeToString(id[:]), Key: hex.EncodeToString(secret)}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.insert(persistedAPIKey{
+		APIKeyInfo: APIKeyInfo{ID: key.ID, APIKeyConfig: config},
+		Hash:       sha256.Sum256([]byte(key.Key)),
+	})
+	if err := s.save(); err != nil {
+		s.delete(key.ID)
+		return nil, err
+	}
+	return key, nil
+}
+
+// remove deletes an API key, reporting whether it existed. Clients using the key
+// are refused from then on, including the open WebSocket connections.
+func (s *apiKeyStore) remove(id string) (bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	key := s.keys[id]
+	if key == nil {
+		return false, nil
+	}
+	s.delete(id)
+	if err := s.save(); err != nil {
+		s.insert(key.persistedAPIKey)
+		return false, err
+	}
+	return true, nil
+}
+
+// delete removes an API key from the store, the caller must hold the lock.
+func (s *apiKeyStore) delete(id string) {
+	if key := s.keys[id]; key != nil {
+		delete(s.hashes, key.Hash)
+		delete(s.keys, id)
+	}
+}
+
+// list returns the API keys sorted by ID.
+func (s *apiKeyStore) list() []APIKeyInfo {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	keys := make([]APIKeyInfo, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, key.APIKeyInfo)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+	return keys
+}
+
+// save atomically replaces the keys file, the caller must hold the lock.
+func (s *apiKeyStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+	keys := make([]persistedAPIKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, key.persistedAPIKey)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+
+	blob, err := json.MarshalIndent(keys, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, blob, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// issueToken returns a token authenticating as the given API key until it
+// expires.
+func (s *apiKeyStore) issueToken(id string, ttl time.Duration) (string, error) {
+	s.lock.RLock()
+	_, ok := s.keys[id]
+	s.lock.RUnlock()
+	if !ok {
+		return "", errAPIKeyUnknown
+	}
+	now := s.now()
+	claims, err := json.Marshal(apiKeyClaims{Subject: id, IssuedAt: now.Unix(), Expires: now.Add(ttl).Unix()})
+	if err != nil {
+		return "", err
+	}
+	payload := jwtHeader + "." + base64.RawURLEncoding.EncodeToString(claims)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(s.sign(payload)), nil
+}
+
+// sign returns the HS256 signature of a token.
+func (s *apiKeyStore) sign(payload string) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+// verifyToken returns the ID of the API key a token was issued for.
+func (s *apiKeyStore) verifyToken(token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", errAPITokenInvalid
+	}
+	header, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "", errAPITokenInvalid
+	}
+	var alg struct {
+		Alg string `json:"alg"`
+	}
+	if err := json.Unmarshal(header, &alg); err != nil || alg.Alg != "HS256" {
+		return "", errAPITokenInvalid
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(sig, s.sign(parts[0]+"."+parts[1])) {
+		return "", errAPITokenInvalid
+	}
+	blob, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", errAPITokenInvalid
+	}
+	var claims apiKeyClaims
+	if err := json.Unmarshal(blob, &claims); err != nil {
+		return "", errAPITokenInvalid
+	}
+	now := s.now().Unix()
+	if now >= claims.Expires || now < claims.NotBefore {
+		return "", errAPITokenExpired
+	}
+	return claims.Subject, nil
+}
+
+// authenticate returns the ID of the API key an HTTP request is made with. The
+// key, or a token issued for it, is taken from the Authorization bearer, the
+// X-API-Key header or the apikey query parameter, the latter being the only one
+// browsers can set when opening a WebSocket connection.
+func (s *apiKeyStore) authenticate(r *http.Request) (string, error) {
+	credential := r.Header.Get("X-API-Key")
+	if auth := r.Header.Get("Authorization"); credential == "" && strings.HasPrefix(auth, "Bearer ") {
+		credential = strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+	}
+	if credential == "" {
+		credential = r.URL.Query().Get("apikey")
+	}
+	if credential == "" {
+		return "", errAPIKeyMissing
+	}
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	var key *apiKey
+	if strings.Count(credential, ".") == 2 {
+		id, err := s.verifyToken(credential)
+		if err != nil {
+			return "", err
+		}
+		if key = s.keys[id]; key == nil {
+			return "", errAPIKeyUnknown
+		}
+	} else if key = s.hashes[sha256.Sum256([]byte(credential))]; key == nil {
+		return "", errAPIKeyInvalid
+	}
+	if origin := r.Header.Get("Origin"); !key.allowsOrigin(origin) {
+		return "", fmt.Errorf("origin %q not allowed for API key", origin)
+	}
+	return key.ID, nil
+}
+
+// authorizer returns the authorizer of the calls made with the given API key,
+// enforcing the access granted to the key when the call is made.
+func (s *apiKeyStore) authorizer(id string) rpc.CallAuthorizer {
+	return func(method string) error {
+		s.lock.RLock()
+		key := s.keys[id]
+		s.lock.RUnlock()
+
+		switch {
+		case key == nil:
+			return &apiKeyError{-32004, errAPIKeyUnknown.Error()}
+		case !key.allows(method):
+			return &apiKeyError{-32004, fmt.Sprintf("method %s not allowed for API key", method)}
+		case key.limiter != nil && !key.limiter.Allow():
+			return &apiKeyError{-32005, "API key rate limit exceeded"}
+		}
+		return nil
+	}
+}
+
+// handler returns an HTTP handler refusing the requests that are not made with a
+// valid API key, and authorizing the calls of the others. Health checks, which
+// carry no call, are let through.
+func (s *apiKeyStore) handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && r.ContentLength == 0 && r.URL.RawQuery == "" && !isWebsocket(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		id, err := s.authenticate(r)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="rpc"`)
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(rpc.WithCallAuthorizer(r.Context(), s.authorizer(id))))
+	})
+}
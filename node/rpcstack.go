@@ -22,29 +22,44 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"mime"
 	"net"
 	"net/http"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/celo-org/celo-blockchain/log"
 	"github.com/celo-org/celo-blockchain/rpc"
 	"github.com/rs/cors"
 )
 
+const (
+	// readinessPath is where the readiness probe is served. It is not affected by
+	// draining.
+	readinessPath = "/ready"
+
+	// drainPollInterval is how often in-flight requests are checked while draining.
+	drainPollInterval = 50 * time.Millisecond
+)
+
 // httpConfig is the JSON-RPC/HTTP configuration.
 type httpConfig struct {
 	Modules            []string
 	CorsAllowedOrigins []string
 	Vhosts             []string
+	Auth               *apiKeyStore // Authenticates the clients, nil if not required
 }
 
 // wsConfig is the JSON-RPC/Websocket configuration
 type wsConfig struct {
 	Origins []string
 	Modules []string
+	Limits  rpc.WebsocketLimits
+	Auth    *apiKeyStore // Authenticates the clients, nil if not required
 }
 
 type rpcHandler struct {
@@ -75,6 +90,10 @@ type httpServer struct {
 	port     int
 
 	handlerNames map[string]string
+
+	// Draining state, see drain.
+	draining int32 // set when new requests are refused
+	inflight int64 // number of requests being served
 }
 
 func newHTTPServer(log log.Logger, timeouts rpc.HTTPTimeouts) *httpServer {
@@ -170,8 +189,21 @@ func (h *httpServer) start() error {
 }
 
 func (h *httpServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != readinessPath {
+		if atomic.LoadInt32(&h.draining) != 0 {
+			w.Header().Set("Connection", "close")
+			http.Error(w, "server is draining", http.StatusServiceUnavailable)
+			return
+		}
+		// WebSocket connections are long lived, only plain requests are waited
+		// for when draining.
+		if !isWebsocket(r) {
+			atomic.AddInt64(&h.inflight, 1)
+			defer atomic.AddInt64(&h.inflight, -1)
+		}
+	}
 	rpc := h.httpHandler.Load().(*rpcHandler)
-	if r.RequestURI == "/" {
+	if r.URL.Path == "/" {
 		// Serve JSON-RPC on the root path.
 		ws := h.wsHandler.Load().(*rpcHandler)
 		if ws != nil && isWebsocket(r) {
@@ -222,6 +254,34 @@ func (h *httpServer) doStop() {
 	// Clear out everything to allow re-configuring it later.
 	h.host, h.port, h.endpoint = "", 0, ""
 	h.server, h.listener = nil, nil
+	atomic.StoreInt32(&h.draining, 0)
+}
+
+// drain makes the server refuse new requests and WebSocket subscriptions, and
+// waits until the requests being served are done or the timeout expires. The
+// readiness probe keeps being served. It returns the number of requests still
+// in flight.
+func (h *httpServer) drain(timeout time.Duration) int64 {
+	h.mu.Lock()
+	if h.listener == nil {
+		h.mu.Unlock()
+		return 0 // not running
+	}
+	atomic.StoreInt32(&h.draining, 1)
+	h.server.SetKeepAlivesEnabled(false)
+	if ws := h.wsHandler.Load().(*rpcHandler); ws != nil {
+		ws.server.Drain()
+	}
+	h.mu.Unlock()
+
+	deadline := time.Now().Add(timeout)
+	for {
+		inflight := atomic.LoadInt64(&h.inflight)
+		if inflight == 0 || time.Now().After(deadline) {
+			return inflight
+		}
+		time.Sleep(drainPollInterval)
+	}
 }
 
 // enableRPC turns on JSON-RPC over HTTP on the server.
@@ -240,12 +300,39 @@ func (h *httpServer) enableRPC(apis []rpc.API, config httpConfig) error {
 	}
 	h.httpConfig = config
 	h.httpHandler.Store(&rpcHandler{
-		Handler: NewHTTPHandlerStack(srv, config.CorsAllowedOrigins, config.Vhosts),
+		Handler: newRPCHandlerStack(srv, config),
 		server:  srv,
 	})
 	return nil
 }
 
+// newRPCHandlerStack wraps the JSON-RPC server in the HTTP handlers applying the
+// given configuration.
+func newRPCHandlerStack(srv *rpc.Server, config httpConfig) http.Handler {
+	var handler http.Handler = srv
+	if config.Auth != nil {
+		handler = config.Auth.handler(srv)
+	}
+	return NewHTTPHandlerStack(handler, config.CorsAllowedOrigins, config.Vhosts)
+}
+
+// setPolicy replaces the CORS origins and virtual hosts allowed by JSON-RPC over
+// HTTP. Requests in progress are served under the previous policy, while the
+// JSON-RPC server, hence the WebSocket connections, are left untouched.
+func (h *httpServer) setPolicy(cors, vhosts []string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.httpConfig.CorsAllowedOrigins, h.httpConfig.Vhosts = cors, vhosts
+	if handler := h.httpHandler.Load().(*rpcHandler); handler != nil {
+		h.httpHandler.Store(&rpcHandler{
+			Handler: newRPCHandlerStack(handler.server, h.httpConfig),
+			server:  handler.server,
+		})
+		h.log.Info("HTTP policy updated", "cors", strings.Join(cors, ","), "vhosts", strings.Join(vhosts, ","))
+	}
+}
+
 // disableRPC stops the HTTP RPC handler. This is internal, the caller must hold h.mu.
 func (h *httpServer) disableRPC() bool {
 	handler := h.httpHandler.Load().(*rpcHandler)
@@ -270,9 +357,13 @@ func (h *httpServer) enableWS(apis []rpc.API, config wsConfig) error {
 	if err := RegisterApisFromWhitelist(apis, config.Modules, srv, false); err != nil {
 		return err
 	}
+	handler := srv.WebsocketHandlerWithLimits(config.Origins, config.Limits)
+	if config.Auth != nil {
+		handler = config.Auth.handler(handler)
+	}
 	h.wsConfig = config
 	h.wsHandler.Store(&rpcHandler{
-		Handler: srv.WebsocketHandler(config.Origins),
+		Handler: handler,
 		server:  srv,
 	})
 	return nil
@@ -406,9 +497,26 @@ func (w *gzipResponseWriter) Write(b []byte) (int, error) {
 	return w.Writer.Write(b)
 }
 
+// acceptsGzip reports whether the client accepts gzip encoded responses,
+// honouring encodings explicitly refused with a zero quality value.
+func acceptsGzip(r *http.Request) bool {
+	for _, encoding := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, err := mime.ParseMediaType(encoding)
+		if err != nil || (name != "gzip" && name != "*") {
+			continue
+		}
+		if q, err := strconv.ParseFloat(params["q"], 64); err == nil && q == 0 {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
 func newGzipHandler(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if !acceptsGzip(r) {
 			next.ServeHTTP(w, r)
 			return
 		}
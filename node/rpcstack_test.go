@@ -18,8 +18,10 @@ package node
 
 import (
 	"bytes"
+	"context"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/celo-org/celo-blockchain/internal/testlog"
 	"github.com/celo-org/celo-blockchain/log"
@@ -52,6 +54,19 @@ func TestVhosts(t *testing.T) {
 	assert.Equal(t, resp2.StatusCode, http.StatusForbidden)
 }
 
+// TestGzipHandler makes sure responses are compressed when the client accepts it.
+func TestGzipHandler(t *testing.T) {
+	srv := createAndStartServer(t, httpConfig{}, false, wsConfig{})
+	defer srv.stop()
+
+	resp := testRequest(t, "Accept-Encoding", "gzip", "", srv)
+	assert.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", resp.Header.Get("Vary"))
+
+	resp2 := testRequest(t, "Accept-Encoding", "deflate, gzip;q=0", "", srv)
+	assert.Equal(t, "", resp2.Header.Get("Content-Encoding"))
+}
+
 // TestWebsocketOrigins makes sure the websocket origins are properly handled on the websocket server.
 func TestWebsocketOrigins(t *testing.T) {
 	srv := createAndStartServer(t, httpConfig{}, true, wsConfig{Origins: []string{"test"}})
@@ -108,3 +123,102 @@ func testRequest(t *testing.T, key, value, host string, srv *httpServer) *http.R
 	}
 	return resp
 }
+
+// TestAPIKeyAuth makes sure HTTP and WebSocket clients are authenticated with
+// API keys or tokens, and only make the calls their key allows.
+func TestAPIKeyAuth(t *testing.T) {
+	auth, err := newAPIKeyStore("", "")
+	assert.NoError(t, err)
+	srv := createAndStartServer(t, httpConfig{Auth: auth}, true, wsConfig{Auth: auth})
+	defer srv.stop()
+
+	allowed, err := auth.add(APIKeyConfig{Methods: []string{"rpc_*"}, Rate: 1, Burst: 2})
+	assert.NoError(t, err)
+	forbidden, err := auth.add(APIKeyConfig{Methods: []string{"eth_chainId"}})
+	assert.NoError(t, err)
+	_, err = auth.add(APIKeyConfig{Methods: []string{"eth*"}})
+	assert.Error(t, err)
+
+	call := func(c *rpc.Client) error {
+		var modules map[string]string
+		return c.Call(&modules, "rpc_modules")
+	}
+	dial := func(header, value string) *rpc.Client {
+		c, err := rpc.DialHTTP("http://" + srv.listenAddr())
+		assert.NoError(t, err)
+		if header != "" {
+			c.SetHeader(header, value)
+		}
+		return c
+	}
+
+	// Unauthenticated requests are refused, health checks are not
+	assert.Error(t, call(dial("", "")))
+	assert.Error(t, call(dial("X-API-Key", "bad")))
+	resp, err := http.Get("http://" + srv.listenAddr())
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Calls are authorized by method, then rate limited
+	err = call(dial("X-API-Key", forbidden.Key))
+	if assert.Error(t, err) {
+		assert.Equal(t, -32004, err.(rpc.Error).ErrorCode())
+	}
+	c := dial("Authorization", "Bearer "+allowed.Key)
+	assert.NoError(t, call(c))
+	assert.NoError(t, call(c))
+	err = call(c)
+	if assert.Error(t, err) {
+		assert.Equal(t, -32005, err.(rpc.Error).ErrorCode())
+	}
+
+	// Tokens authenticate as their key until they expire
+	token, err := auth.issueToken(forbidden.ID, time.Minute)
+	assert.NoError(t, err)
+	err = call(dial("Authorization", "Bearer "+token))
+	if assert.Error(t, err) {
+		assert.Equal(t, -32004, err.(rpc.Error).ErrorCode())
+	}
+	auth.now = func() time.Time { return time.Now().Add(time.Hour) }
+	err = call(dial("Authorization", "Bearer "+token))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "401")
+	}
+	auth.now = time.Now
+
+	// WebSocket connections take the key from the query, and stop being served
+	// once the key is removed
+	_, err = rpc.DialWebsocket(context.Background(), "ws://"+srv.listenAddr(), "")
+	assert.Error(t, err)
+	ws, err := rpc.DialWebsocket(context.Background(), "ws://"+srv.listenAddr()+"?apikey="+forbidden.Key, "")
+	assert.NoError(t, err)
+	defer ws.Close()
+	removed, err := auth.remove(forbidden.ID)
+	assert.NoError(t, err)
+	assert.True(t, removed)
+	err = call(ws)
+	if assert.Error(t, err) {
+		assert.Equal(t, errAPIKeyUnknown.Error(), err.Error())
+	}
+}
+
+// TestSetPolicy makes sure CORS origins and vhosts can be replaced while the
+// http server is running.
+func TestSetPolicy(t *testing.T) {
+	srv := createAndStartServer(t, httpConfig{CorsAllowedOrigins: []string{"test.com"}, Vhosts: []string{"test"}}, false, wsConfig{})
+	defer srv.stop()
+
+	resp := testRequest(t, "origin", "other.com", "test", srv)
+	assert.Equal(t, "", resp.Header.Get("Access-Control-Allow-Origin"))
+	resp = testRequest(t, "", "", "other", srv)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	srv.setPolicy([]string{"other.com"}, []string{"other"})
+
+	resp = testRequest(t, "origin", "other.com", "other", srv)
+	assert.Equal(t, "other.com", resp.Header.Get("Access-Control-Allow-Origin"))
+	resp = testRequest(t, "origin", "test.com", "other", srv)
+	assert.Equal(t, "", resp.Header.Get("Access-Control-Allow-Origin"))
+	resp = testRequest(t, "", "", "test", srv)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
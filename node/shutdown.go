@@ -0,0 +1,127 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ErrShutdownTimeout is returned for shutdown steps that did not complete
+// within the shutdown timeout and were abandoned.
+var ErrShutdownTimeout = errors.New("shutdown timed out")
+
+// ShutdownStage orders the shutdown of the node subsystems. Subsystems are
+// stopped before the ones they depend on: consensus first, as it drives the
+// miner, which in turn feeds from the transaction pool. The databases, used by
+// everything else, are closed last.
+type ShutdownStage int
+
+const (
+	ShutdownConsensus ShutdownStage = iota // Consensus engine and announcements
+	ShutdownMiner                          // Block production
+	ShutdownTxPool                         // Transaction pool
+	ShutdownRPC                            // RPC endpoints
+	ShutdownServices                       // Remaining lifecycles, see Lifecycle.Stop
+	ShutdownP2P                            // Peer-to-peer networking
+	ShutdownDatabase                       // Databases
+)
+
+func (s ShutdownStage) String() string {
+	switch s {
+	case ShutdownConsensus:
+		return "consensus"
+	case ShutdownMiner:
+		return "miner"
+	case ShutdownTxPool:
+		return "txpool"
+	case ShutdownRPC:
+		return "rpc"
+	case ShutdownServices:
+		return "services"
+	case ShutdownP2P:
+		return "p2p"
+	case ShutdownDatabase:
+		return "database"
+	default:
+		return fmt.Sprintf("stage %d", int(s))
+	}
+}
+
+// ShutdownStep stops a subsystem during the given shutdown stage.
+type ShutdownStep struct {
+	Stage ShutdownStage
+	Name  string
+	Stop  func() error
+
+	// Timeout overrides the node's shutdown timeout for this step if non-zero.
+	Timeout time.Duration
+}
+
+// StagedStopper is implemented by lifecycles running subsystems which must be
+// stopped in a specific shutdown stage. The returned steps are run in their
+// stages before the lifecycle's Stop method, which is run in the services stage
+// and must cope with the subsystems being stopped already.
+type StagedStopper interface {
+	ShutdownSteps() []ShutdownStep
+}
+
+// runShutdown runs the steps ordered by stage, steps of the same stage are run
+// in the given order. Steps exceeding their timeout are abandoned so that the
+// remaining subsystems still get stopped. The returned errors are indexed like
+// the steps.
+func (n *Node) runShutdown(steps []ShutdownStep) []error {
+	order := make([]int, len(steps))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return steps[order[i]].Stage < steps[order[j]].Stage })
+
+	errs := make([]error, len(steps))
+	for _, i := range order {
+		errs[i] = n.runShutdownStep(steps[i])
+	}
+	return errs
+}
+
+// runShutdownStep runs a single shutdown step, giving up on it once it exceeds
+// its timeout. An abandoned step keeps running in the background.
+func (n *Node) runShutdownStep(step ShutdownStep) error {
+	timeout := step.Timeout
+	if timeout == 0 {
+		timeout = n.config.ShutdownTimeout
+	}
+	if timeout <= 0 {
+		return step.Stop()
+	}
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() { done <- step.Stop() }()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		n.log.Debug("Stopped subsystem", "stage", step.Stage, "name", step.Name, "elapsed", time.Since(start))
+		return err
+	case <-timer.C:
+		n.log.Error("Subsystem failed to stop in time, forcing shutdown", "stage", step.Stage, "name", step.Name, "timeout", timeout)
+		return ErrShutdownTimeout
+	}
+}
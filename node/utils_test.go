@@ -63,6 +63,17 @@ func (s *InstrumentedService) Stop() error {
 	return s.stop
 }
 
+// StagedService is an instrumented lifecycle stopping subsystems in the given
+// shutdown steps.
+type StagedService struct {
+	InstrumentedService
+	steps []ShutdownStep
+}
+
+func (s *StagedService) ShutdownSteps() []ShutdownStep {
+	return s.steps
+}
+
 type FullService struct{}
 
 func NewFullService(stack *Node) (*FullService, error) {
@@ -85,7 +85,9 @@ var (
 // Two types of peer connections can be created:
 //
 //  - static dials are pre-configured connections. The dialer attempts
-//    keep these nodes connected at all times.
+//    keep these nodes connected at all times. Prioritized static dials, such
+//    as the ones to the other elected validators, are launched right away,
+//    regardless of the number of active dials.
 //
 //  - dynamic dials are created from node discovery results. The dialer
 //    continuously reads candidate nodes from its input iterator and attempts
@@ -99,7 +101,7 @@ type dialScheduler struct {
 	ctx         context.Context
 	nodesIn     chan *enode.Node
 	doneCh      chan *dialTask
-	addStaticCh chan *enode.Node
+	addStaticCh chan staticDial
 	remStaticCh chan *enode.Node
 	addPeerCh   chan *conn
 	remPeerCh   chan *conn
@@ -113,7 +115,7 @@ type dialScheduler struct {
 	// The static map tracks all static dial tasks. The subset of usable static dial tasks
 	// (i.e. those passing checkDial) is kept in staticPool. The scheduler prefers
 	// launching static tasks from the pool over launching dynamic dials from the
-	// iterator.
+	// iterator, and prioritized static tasks over the others.
 	static     map[enode.ID]*dialTask
 	staticPool []*dialTask
 
@@ -129,6 +131,12 @@ type dialScheduler struct {
 
 type dialSetupFunc func(net.Conn, connFlag, *enode.Node) error
 
+// staticDial is a static dial candidate, along with its priority.
+type staticDial struct {
+	node     *enode.Node
+	priority bool
+}
+
 type dialConfig struct {
 	self           enode.ID         // our own ID
 	maxDialPeers   int              // maximum number of dialed peers
@@ -169,7 +177,7 @@ func newDialScheduler(config dialConfig, it enode.Iterator, setupFunc dialSetupF
 		peers:       make(map[enode.ID]connFlag),
 		doneCh:      make(chan *dialTask),
 		nodesIn:     make(chan *enode.Node),
-		addStaticCh: make(chan *enode.Node),
+		addStaticCh: make(chan staticDial),
 		remStaticCh: make(chan *enode.Node),
 		addPeerCh:   make(chan *conn),
 		remPeerCh:   make(chan *conn),
@@ -191,7 +199,16 @@ func (d *dialScheduler) stop() {
 // addStatic adds a static dial candidate.
 func (d *dialScheduler) addStatic(n *enode.Node) {
 	select {
-	case d.addStaticCh <- n:
+	case d.addStaticCh <- staticDial{node: n}:
+	case <-d.ctx.Done():
+	}
+}
+
+// addPriorityStatic adds a static dial candidate dialed before the others. Adding
+// an existing candidate again updates its priority.
+func (d *dialScheduler) addPriorityStatic(n *enode.Node) {
+	select {
+	case d.addStaticCh <- staticDial{node: n, priority: true}:
 	case <-d.ctx.Done():
 	}
 }
@@ -274,16 +291,18 @@ loop:
 			delete(d.peers, c.node.ID())
 			d.updateStaticPool(c.node.ID())
 
-		case node := <-d.addStaticCh:
-			id := node.ID()
-			_, exists := d.static[id]
-			d.log.Trace("Adding static node", "id", id, "ip", node.IP(), "added", !exists)
+		case sd := <-d.addStaticCh:
+			id := sd.node.ID()
+			task, exists := d.static[id]
+			d.log.Trace("Adding static node", "id", id, "ip", sd.node.IP(), "priority", sd.priority, "added", !exists)
 			if exists {
+				task.priority = sd.priority
 				continue loop
 			}
-			task := newDialTask(node, staticDialedConn)
+			task = newDialTask(sd.node, staticDialedConn)
+			task.priority = sd.priority
 			d.static[id] = task
-			if d.checkDial(node) == nil {
+			if d.checkDial(sd.node) == nil {
 				d.addToStaticPool(task)
 			}
 
@@ -412,14 +431,25 @@ func (d *dialScheduler) checkDial(n *enode.Node) error {
 	return nil
 }
 
-// startStaticDials starts static dials nodes in the static pool, subject to the maxActiveDials limit
+// startStaticDials starts the prioritized static dials in the static pool, then the
+// others subject to the maxActiveDials limit.
 func (d *dialScheduler) startStaticDials() (started int) {
+	for idx := 0; idx < len(d.staticPool); {
+		if task := d.staticPool[idx]; task.priority {
+			d.startDial(task)
+			d.removeFromStaticPool(idx)
+			started++
+			continue // The last task of the pool was moved to idx
+		}
+		idx++
+	}
 	limit := d.maxActiveDials - len(d.dialing)
-	for started = 0; started < limit && len(d.staticPool) > 0; started++ {
+	for i := 0; i < limit && len(d.staticPool) > 0; i++ {
 		idx := d.rand.Intn(len(d.staticPool))
 		task := d.staticPool[idx]
 		d.startDial(task)
 		d.removeFromStaticPool(idx)
+		started++
 	}
 	return started
 }
@@ -467,6 +497,7 @@ func (d *dialScheduler) startDial(task *dialTask) {
 // A dialTask generated for each node that is dialed.
 type dialTask struct {
 	staticPoolIndex int
+	priority        bool // Static dial launched before the others
 	flags           connFlag
 	// These fields are private to the task and should not be
 	// accessed by dialScheduler while the task is running.
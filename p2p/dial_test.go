@@ -224,6 +224,55 @@ func TestDialSchedStaticDial(t *testing.T) {
 	})
 }
 
+// This test checks that prioritized static dials are launched before the others,
+// regardless of the active dial limit.
+func TestDialSchedPriorityStaticDial(t *testing.T) {
+	t.Parallel()
+
+	config := dialConfig{
+		maxActiveDials: 1,
+		maxDialPeers:   2,
+	}
+	runDialTest(t, config, []dialTestRound{
+		// Only the prioritized static nodes are dialed, beyond the active dial limit.
+		{
+			update: func(d *dialScheduler) {
+				d.addPriorityStatic(newNode(uintID(0x02), "127.0.0.2:30303"))
+				d.addPriorityStatic(newNode(uintID(0x03), "127.0.0.3:30303"))
+				d.addStatic(newNode(uintID(0x01), "127.0.0.1:30303"))
+			},
+			discovered: []*enode.Node{
+				newNode(uintID(0x04), "127.0.0.4:30303"),
+			},
+			wantNewDials: []*enode.Node{
+				newNode(uintID(0x02), "127.0.0.2:30303"),
+				newNode(uintID(0x03), "127.0.0.3:30303"),
+			},
+		},
+		// The prioritized dials complete, freeing the active dial slot for 0x01.
+		{
+			succeeded: []enode.ID{
+				uintID(0x02),
+				uintID(0x03),
+			},
+			wantNewDials: []*enode.Node{
+				newNode(uintID(0x01), "127.0.0.1:30303"),
+			},
+		},
+		// 0x02 is demoted and 0x05 prioritized, the latter is dialed right away
+		// even though the dial to 0x01 is still running.
+		{
+			update: func(d *dialScheduler) {
+				d.addStatic(newNode(uintID(0x02), "127.0.0.2:30303"))
+				d.addPriorityStatic(newNode(uintID(0x05), "127.0.0.5:30303"))
+			},
+			wantNewDials: []*enode.Node{
+				newNode(uintID(0x05), "127.0.0.5:30303"),
+			},
+		},
+	})
+}
+
 // This test checks that removing static nodes stops connecting to them.
 func TestDialSchedRemoveStatic(t *testing.T) {
 	t.Parallel()
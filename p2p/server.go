@@ -808,6 +808,16 @@ func (srv *Server) run() {
 		static[n.ID()] = ExplicitStaticPurpose
 	}
 
+	// Validators are dialed before other static nodes, to keep the consensus
+	// connections up when dial slots are scarce
+	dialStatic := func(n *enode.Node, purpose PurposeFlag) {
+		if purpose.IsSet(ValidatorPurpose) {
+			srv.dialsched.addPriorityStatic(n)
+		} else {
+			srv.dialsched.addStatic(n)
+		}
+	}
+
 	addStatic := func(n *enode.Node, purpose PurposeFlag) {
 		newPurpose := static[n.ID()].Add(purpose)
 		static[n.ID()] = newPurpose
@@ -817,7 +827,7 @@ func (srv *Server) run() {
 			p.AddPurpose(purpose)
 		}
 
-		srv.dialsched.addStatic(n)
+		dialStatic(n, newPurpose)
 	}
 
 	removeStatic := func(n *enode.Node, purpose PurposeFlag, done chan<- struct{}) {
@@ -845,6 +855,7 @@ func (srv *Server) run() {
 			}
 		} else {
 			static[n.ID()] = newPurpose
+			dialStatic(n, newPurpose)
 			if p, ok := peers[n.ID()]; ok {
 				p.RemovePurpose(purpose)
 			}
@@ -20,6 +20,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"math/big"
+	"strings"
 
 	"github.com/celo-org/celo-blockchain/common"
 	"github.com/celo-org/celo-blockchain/crypto"
@@ -129,19 +130,19 @@ var (
 		ProposerPolicy: 0,
 		RequestTimeout: 1000,
 		BlockPeriod:    1,
-	}, true, false}
+	}, nil, nil, true, false}
 
 	IstanbulTestChainConfig = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, big.NewInt(0), nil, nil, &IstanbulConfig{
 		Epoch:          300,
 		ProposerPolicy: 0,
 		RequestTimeout: 1000,
 		BlockPeriod:    1,
-	}, true, false}
+	}, nil, nil, true, false}
 
 	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, big.NewInt(0), nil, nil, &IstanbulConfig{
 		Epoch:          30000,
 		ProposerPolicy: 0,
-	}, true, true}
+	}, nil, nil, true, true}
 	TestRules = TestChainConfig.Rules(new(big.Int))
 )
 
@@ -220,6 +221,14 @@ type ChainConfig struct {
 
 	Istanbul *IstanbulConfig `json:"istanbul,omitempty"`
 
+	// Celo specific intrinsic gas schedules, sorted by activation block. Blocks
+	// before the first schedule use DefaultIntrinsicGasSchedule.
+	IntrinsicGasSchedules []*IntrinsicGasSchedule `json:"intrinsicGasSchedules,omitempty"`
+
+	// Core contract addresses pinned at genesis, by registry name. They resolve
+	// the contracts the Registry has no address for yet.
+	CoreContracts map[string]common.Address `json:"coreContracts,omitempty"`
+
 	// This does not belong here but passing it to every function is not possible since that breaks
 	// some implemented interfaces and introduces churn across the geth codebase.
 	FullHeaderChainAvailable bool // False for lightest Sync mode, true otherwise
@@ -247,6 +256,18 @@ func (c *IstanbulConfig) String() string {
 	return "istanbul"
 }
 
+// IntrinsicGasSchedule holds the Celo specific intrinsic gas costs of transactions,
+// charged on top of the Ethereum ones from its activation block on.
+type IntrinsicGasSchedule struct {
+	Block *big.Int `json:"block"` // Activation block of the schedule
+
+	// Cost of paying for gas in a non-native currency, used if the
+	// BlockchainParameters contract does not set it
+	AlternativeFeeCurrency uint64 `json:"alternativeFeeCurrency"`
+	// Cost of paying a gateway fee to a gateway fee recipient
+	GatewayFee uint64 `json:"gatewayFee"`
+}
+
 // String implements the fmt.Stringer interface.
 func (c *ChainConfig) String() string {
 	var engine interface{}
@@ -345,6 +366,73 @@ func (c *ChainConfig) IsEHardfork(num *big.Int) bool {
 	return isForked(c.EBlock, num)
 }
 
+// CeloHardforkBlock returns the activation block of the Celo hard fork with the
+// given name (nil if not scheduled), and whether this version knows of the fork.
+func (c *ChainConfig) CeloHardforkBlock(name string) (*big.Int, bool) {
+	switch strings.ToLower(name) {
+	case "churrito":
+		return c.ChurritoBlock, true
+	case "donut":
+		return c.DonutBlock, true
+	case "e":
+		return c.EBlock, true
+	default:
+		return nil, false
+	}
+}
+
+// WithCeloHardfork returns a copy of the chain config with the Celo hard fork of
+// the given name activated at the given block, along with the forks preceding
+// it not activated by then, and whether this version knows of the fork.
+func (c *ChainConfig) WithCeloHardfork(name string, block *big.Int) (*ChainConfig, bool) {
+	config := *c
+	for _, fork := range []struct {
+		name  string
+		block **big.Int
+	}{
+		{"churrito", &config.ChurritoBlock},
+		{"donut", &config.DonutBlock},
+		{"e", &config.EBlock},
+	} {
+		if *fork.block == nil || (*fork.block).Cmp(block) > 0 {
+			*fork.block = new(big.Int).Set(block)
+		}
+		if fork.name == strings.ToLower(name) {
+			return &config, true
+		}
+	}
+	return nil, false
+}
+
+// IntrinsicGasSchedule returns the Celo specific intrinsic gas schedule active at
+// the given block.
+func (c *ChainConfig) IntrinsicGasSchedule(num *big.Int) *IntrinsicGasSchedule {
+	for i := len(c.IntrinsicGasSchedules) - 1; i >= 0; i-- {
+		if isForked(c.IntrinsicGasSchedules[i].Block, num) {
+			return c.IntrinsicGasSchedules[i]
+		}
+	}
+	return DefaultIntrinsicGasSchedule
+}
+
+// PinnedCoreContract returns the address pinned at genesis for the core contract
+// with the given registry id, if any.
+func (c *ChainConfig) PinnedCoreContract(registryId common.Hash) (common.Address, bool) {
+	for name, address := range c.CoreContracts {
+		if makeRegistryId(name) == registryId {
+			return address, true
+		}
+	}
+	return common.Address{}, false
+}
+
+// CoreContractAddress returns the address a core contract is deployed to with
+// CREATE2 by the given deployer, using the registry id of its name as salt. It
+// allows custom networks to pin their core contracts before deploying them.
+func CoreContractAddress(deployer common.Address, name string, initCodeHash common.Hash) common.Address {
+	return crypto.CreateAddress2(deployer, makeRegistryId(name), initCodeHash[:])
+}
+
 // CheckCompatible checks whether scheduled fork transitions have been imported
 // with a mismatching chain configuration.
 func (c *ChainConfig) CheckCompatible(newcfg *ChainConfig, height uint64) *ConfigCompatError {
@@ -403,6 +491,20 @@ func (c *ChainConfig) CheckConfigForkOrder() error {
 			lastFork = cur
 		}
 	}
+	for i, schedule := range c.IntrinsicGasSchedules {
+		if schedule == nil || schedule.Block == nil {
+			return fmt.Errorf("intrinsic gas schedule %d has no activation block", i)
+		}
+		if i > 0 && c.IntrinsicGasSchedules[i-1].Block.Cmp(schedule.Block) >= 0 {
+			return fmt.Errorf("unsupported intrinsic gas schedule ordering: schedule %d enabled at %v, but schedule %d enabled at %v",
+				i-1, c.IntrinsicGasSchedules[i-1].Block, i, schedule.Block)
+		}
+	}
+	for name, address := range c.CoreContracts {
+		if name == "" || address == (common.Address{}) {
+			return fmt.Errorf("invalid core contract address pinned for %q: %x", name, address)
+		}
+	}
 	return nil
 }
 
@@ -452,9 +554,35 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int) *Confi
 	if isForkIncompatible(c.EBlock, newcfg.EBlock, head) {
 		return newCompatError("E fork block", c.EBlock, newcfg.EBlock)
 	}
+	if stored, scheduled, ok := intrinsicGasScheduleMismatch(c.IntrinsicGasSchedules, newcfg.IntrinsicGasSchedules, head); ok {
+		return newCompatError("intrinsic gas schedule", stored, scheduled)
+	}
 	return nil
 }
 
+// intrinsicGasScheduleMismatch returns the activation blocks of the first intrinsic
+// gas schedules differing between two configurations, if any is active at the given
+// head block. A nil block means the schedule is missing from the configuration.
+func intrinsicGasScheduleMismatch(x, y []*IntrinsicGasSchedule, head *big.Int) (*big.Int, *big.Int, bool) {
+	for i := 0; i < len(x) || i < len(y); i++ {
+		var xs, ys *IntrinsicGasSchedule
+		var xb, yb *big.Int
+		if i < len(x) {
+			xs, xb = x[i], x[i].Block
+		}
+		if i < len(y) {
+			ys, yb = y[i], y[i].Block
+		}
+		if !isForked(xb, head) && !isForked(yb, head) {
+			return nil, nil, false
+		}
+		if xs == nil || ys == nil || !configNumEqual(xb, yb) || xs.AlternativeFeeCurrency != ys.AlternativeFeeCurrency || xs.GatewayFee != ys.GatewayFee {
+			return xb, yb, true
+		}
+	}
+	return nil, nil, false
+}
+
 // isForkIncompatible returns true if a fork scheduled at s1 cannot be rescheduled to
 // block s2 because head is already past the fork.
 func isForkIncompatible(s1, s2, head *big.Int) bool {
@@ -20,6 +20,9 @@ import (
 	"math/big"
 	"reflect"
 	"testing"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/crypto"
 )
 
 func TestCheckCompatible(t *testing.T) {
@@ -70,6 +73,34 @@ func TestCheckCompatible(t *testing.T) {
 				RewindTo:     9,
 			},
 		},
+		{
+			stored:  &ChainConfig{IntrinsicGasSchedules: []*IntrinsicGasSchedule{{Block: big.NewInt(10), GatewayFee: 1000}}},
+			new:     &ChainConfig{IntrinsicGasSchedules: []*IntrinsicGasSchedule{{Block: big.NewInt(10), GatewayFee: 1000}, {Block: big.NewInt(30)}}},
+			head:    20,
+			wantErr: nil,
+		},
+		{
+			stored: &ChainConfig{IntrinsicGasSchedules: []*IntrinsicGasSchedule{{Block: big.NewInt(10), GatewayFee: 1000}}},
+			new:    &ChainConfig{IntrinsicGasSchedules: []*IntrinsicGasSchedule{{Block: big.NewInt(10), GatewayFee: 2000}}},
+			head:   20,
+			wantErr: &ConfigCompatError{
+				What:         "intrinsic gas schedule",
+				StoredConfig: big.NewInt(10),
+				NewConfig:    big.NewInt(10),
+				RewindTo:     9,
+			},
+		},
+		{
+			stored: &ChainConfig{},
+			new:    &ChainConfig{IntrinsicGasSchedules: []*IntrinsicGasSchedule{{Block: big.NewInt(15)}}},
+			head:   20,
+			wantErr: &ConfigCompatError{
+				What:         "intrinsic gas schedule",
+				StoredConfig: nil,
+				NewConfig:    big.NewInt(15),
+				RewindTo:     14,
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -79,3 +110,75 @@ func TestCheckCompatible(t *testing.T) {
 		}
 	}
 }
+
+func TestIntrinsicGasSchedule(t *testing.T) {
+	first := &IntrinsicGasSchedule{Block: big.NewInt(10), AlternativeFeeCurrency: 60000}
+	second := &IntrinsicGasSchedule{Block: big.NewInt(20), AlternativeFeeCurrency: 60000, GatewayFee: 1000}
+	config := &ChainConfig{IntrinsicGasSchedules: []*IntrinsicGasSchedule{first, second}}
+
+	tests := []struct {
+		head uint64
+		want *IntrinsicGasSchedule
+	}{
+		{0, DefaultIntrinsicGasSchedule},
+		{9, DefaultIntrinsicGasSchedule},
+		{10, first},
+		{19, first},
+		{20, second},
+		{1000, second},
+	}
+	for _, test := range tests {
+		if have := config.IntrinsicGasSchedule(new(big.Int).SetUint64(test.head)); have != test.want {
+			t.Errorf("head %d: schedule mismatch: have %+v, want %+v", test.head, have, test.want)
+		}
+	}
+	if err := config.CheckConfigForkOrder(); err != nil {
+		t.Errorf("ordered schedules rejected: %v", err)
+	}
+	config.IntrinsicGasSchedules = []*IntrinsicGasSchedule{second, first}
+	if err := config.CheckConfigForkOrder(); err == nil {
+		t.Errorf("unordered schedules accepted")
+	}
+}
+
+func TestWithCeloHardfork(t *testing.T) {
+	config := &ChainConfig{ChurritoBlock: big.NewInt(0), DonutBlock: big.NewInt(200)}
+
+	forked, known := config.WithCeloHardfork("E", big.NewInt(100))
+	if !known {
+		t.Fatalf("known hard fork reported unknown")
+	}
+	if forked.ChurritoBlock.Uint64() != 0 || forked.DonutBlock.Uint64() != 100 || forked.EBlock.Uint64() != 100 {
+		t.Errorf("fork blocks mismatch: have %v %v %v, want 0 100 100", forked.ChurritoBlock, forked.DonutBlock, forked.EBlock)
+	}
+	if config.DonutBlock.Uint64() != 200 || config.EBlock != nil {
+		t.Errorf("original config modified")
+	}
+	if _, known := config.WithCeloHardfork("f", big.NewInt(100)); known {
+		t.Errorf("unknown hard fork reported known")
+	}
+}
+
+func TestPinnedCoreContract(t *testing.T) {
+	deployer := common.HexToAddress("0xce10")
+	initCodeHash := common.HexToHash("0x01")
+	accounts := CoreContractAddress(deployer, "Accounts", initCodeHash)
+	if accounts != crypto.CreateAddress2(deployer, AccountsRegistryId, initCodeHash[:]) {
+		t.Errorf("core contract address not derived from its registry id")
+	}
+	config := &ChainConfig{CoreContracts: map[string]common.Address{"Accounts": accounts}}
+
+	if address, ok := config.PinnedCoreContract(AccountsRegistryId); !ok || address != accounts {
+		t.Errorf("pinned address mismatch: have %x (%v), want %x", address, ok, accounts)
+	}
+	if _, ok := config.PinnedCoreContract(ElectionRegistryId); ok {
+		t.Errorf("address reported pinned for a contract not pinned")
+	}
+	if err := config.CheckConfigForkOrder(); err != nil {
+		t.Errorf("pinned addresses rejected: %v", err)
+	}
+	config.CoreContracts["Election"] = common.Address{}
+	if err := config.CheckConfigForkOrder(); err == nil {
+		t.Errorf("empty pinned address accepted")
+	}
+}
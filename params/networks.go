@@ -0,0 +1,78 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import "github.com/celo-org/celo-blockchain/common"
+
+// Network is a pre-configured Celo network the node can join by name. The
+// genesis block of the network is the one with the given hash, and its DNS
+// discovery tree is looked up by KnownDNSNetwork.
+type Network struct {
+	Name        string
+	NetworkId   uint64
+	GenesisHash common.Hash
+	ChainConfig *ChainConfig
+	Bootnodes   []string
+	DataDir     string // Subdirectory of the default data directory, if any
+}
+
+// Networks are the pre-configured Celo networks, mainnet first.
+var Networks = []*Network{
+	{
+		Name:        "mainnet",
+		NetworkId:   MainnetNetworkId,
+		GenesisHash: MainnetGenesisHash,
+		ChainConfig: MainnetChainConfig,
+		Bootnodes:   MainnetBootnodes,
+	},
+	{
+		Name:        "alfajores",
+		NetworkId:   AlfajoresNetworkId,
+		GenesisHash: AlfajoresGenesisHash,
+		ChainConfig: AlfajoresChainConfig,
+		Bootnodes:   AlfajoresBootnodes,
+		DataDir:     "alfajores",
+	},
+	{
+		Name:        "baklava",
+		NetworkId:   BaklavaNetworkId,
+		GenesisHash: BaklavaGenesisHash,
+		ChainConfig: BaklavaChainConfig,
+		Bootnodes:   BaklavaBootnodes,
+		DataDir:     "baklava",
+	},
+}
+
+// NetworkByName returns the pre-configured network with the given name, or nil
+// if there is none.
+func NetworkByName(name string) *Network {
+	for _, network := range Networks {
+		if network.Name == name {
+			return network
+		}
+	}
+	return nil
+}
+
+// NetworkNames returns the names of the pre-configured networks.
+func NetworkNames() []string {
+	names := make([]string, len(Networks))
+	for i, network := range Networks {
+		names[i] = network.Name
+	}
+	return names
+}
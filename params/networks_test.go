@@ -0,0 +1,39 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import "testing"
+
+func TestNetworkByName(t *testing.T) {
+	seen := make(map[uint64]bool)
+	for _, name := range NetworkNames() {
+		network := NetworkByName(name)
+		if network == nil || network.Name != name {
+			t.Fatalf("network %q not found", name)
+		}
+		if seen[network.NetworkId] {
+			t.Errorf("network %q: duplicate network id %d", name, network.NetworkId)
+		}
+		seen[network.NetworkId] = true
+		if network.ChainConfig.ChainID.Uint64() != network.NetworkId {
+			t.Errorf("network %q: chain id %v mismatches network id %d", name, network.ChainConfig.ChainID, network.NetworkId)
+		}
+	}
+	if NetworkByName("nosuchnetwork") != nil {
+		t.Errorf("unknown network found")
+	}
+}
@@ -17,6 +17,8 @@
 package params
 
 import (
+	"math/big"
+
 	"github.com/celo-org/celo-blockchain/common"
 	"github.com/celo-org/celo-blockchain/common/hexutil"
 	"github.com/celo-org/celo-blockchain/common/math"
@@ -188,8 +190,11 @@ var (
 
 	// Celo registered contract IDs.
 	// The names are taken from celo-monorepo/packages/protocol/lib/registry-utils.ts
+	AccountsRegistryId             = makeRegistryId("Accounts")
 	AttestationsRegistryId         = makeRegistryId("Attestations")
 	BlockchainParametersRegistryId = makeRegistryId("BlockchainParameters")
+	DoubleSigningSlasherRegistryId = makeRegistryId("DoubleSigningSlasher")
+	DowntimeSlasherRegistryId      = makeRegistryId("DowntimeSlasher")
 	ElectionRegistryId             = makeRegistryId("Election")
 	EpochRewardsRegistryId         = makeRegistryId("EpochRewards")
 	FeeCurrencyWhitelistRegistryId = makeRegistryId("FeeCurrencyWhitelist")
@@ -216,6 +221,13 @@ var (
 	Fixidity1 = math.BigPow(10, 24)
 )
 
+// DefaultIntrinsicGasSchedule is the Celo specific intrinsic gas schedule of the
+// chains not configuring any.
+var DefaultIntrinsicGasSchedule = &IntrinsicGasSchedule{
+	Block:                  big.NewInt(0),
+	AlternativeFeeCurrency: IntrinsicGasForAlternativeFeeCurrency,
+}
+
 func makeRegistryId(contractName string) [32]byte {
 	hash := crypto.Keccak256([]byte(contractName))
 	var id [32]byte
@@ -247,18 +259,25 @@ const (
 	MaxGasForGetElectableValidators                uint64 = 100 * thousand
 	MaxGasForGetEligibleValidatorGroupsVoteTotals  uint64 = 1 * million
 	MaxGasForGetGasPriceMinimum                    uint64 = 2 * million
+	MaxGasForGetGroupsVotedFor                     uint64 = 200 * thousand
+	MaxGasForGetLockedGold                         uint64 = 100 * thousand
 	MaxGasForGetGroupEpochRewards                  uint64 = 500 * thousand
 	MaxGasForGetMembershipInLastEpoch              uint64 = 1 * million
+	MaxGasForGetMembershipHistory                  uint64 = 200 * thousand
 	MaxGasForGetOrComputeTobinTax                  uint64 = 1 * million
 	MaxGasForGetRegisteredValidators               uint64 = 2 * million
 	MaxGasForGetValidator                          uint64 = 100 * thousand
+	MaxGasForGetVotesForGroup                      uint64 = 100 * thousand
 	MaxGasForGetWhiteList                          uint64 = 200 * thousand
 	MaxGasForGetTransferWhitelist                  uint64 = 2 * million
 	MaxGasForIncreaseSupply                        uint64 = 50 * thousand
+	MaxGasForIsBitmapSetForInterval                uint64 = 100 * thousand
 	MaxGasForIsFrozen                              uint64 = 20 * thousand
 	MaxGasForMedianRate                            uint64 = 100 * thousand
 	MaxGasForReadBlockchainParameter               uint64 = 40 * thousand // ad-hoc measurement is ~26k
+	MaxGasForReadSlashingParameter                 uint64 = 40 * thousand
 	MaxGasForRevealAndCommit                       uint64 = 2 * million
+	MaxGasForSignerToAccount                       uint64 = 100 * thousand
 	MaxGasForUpdateGasPriceMinimum                 uint64 = 2 * million
 	MaxGasForUpdateTargetVotingYield               uint64 = 2 * million
 	MaxGasForUpdateValidatorScore                  uint64 = 1 * million
@@ -267,4 +286,6 @@ const (
 	MaxGasToReadErc20Balance                       uint64 = 100 * thousand
 	MaxGasForIsReserveLow                          uint64 = 1 * million
 	MaxGasForGetCarbonOffsettingPartner            uint64 = 20 * thousand
+	MaxGasForGetDequeue                            uint64 = 1 * million
+	MaxGasForReadProposal                          uint64 = 200 * thousand
 )
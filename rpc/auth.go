@@ -0,0 +1,40 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import "context"
+
+// CallAuthorizer decides whether the given method may be called, returning the
+// error sent back to the caller if not. Errors implementing Error keep their
+// error code.
+type CallAuthorizer func(method string) error
+
+type authorizerKey struct{}
+
+// WithCallAuthorizer returns a copy of ctx carrying the given authorizer. When
+// it is the context of an HTTP request served by the server, every method call
+// and subscription made by the request, or over the WebSocket connection it
+// upgrades to, is authorized first.
+func WithCallAuthorizer(ctx context.Context, auth CallAuthorizer) context.Context {
+	return context.WithValue(ctx, authorizerKey{}, auth)
+}
+
+// callAuthorizerFrom returns the authorizer carried by ctx, if any.
+func callAuthorizerFrom(ctx context.Context) CallAuthorizer {
+	auth, _ := ctx.Value(authorizerKey{}).(CallAuthorizer)
+	return auth
+}
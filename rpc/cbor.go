@@ -0,0 +1,184 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const cborContentType = "application/cbor"
+
+// CBOR major types, see RFC 8949.
+const (
+	cborUnsigned byte = 0
+	cborNegative byte = 1
+	cborText     byte = 3
+	cborArray    byte = 4
+	cborMap      byte = 5
+)
+
+// CBOR simple values and the float64 marker.
+const (
+	cborFalse   byte = 0xf4
+	cborTrue    byte = 0xf5
+	cborNull    byte = 0xf6
+	cborFloat64 byte = 0xfb
+)
+
+// acceptsCBOR reports whether the client asked for CBOR encoded responses.
+func acceptsCBOR(r *http.Request) bool {
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mt, params, err := mime.ParseMediaType(accept)
+		if err != nil || mt != cborContentType {
+			continue
+		}
+		if q, err := strconv.ParseFloat(params["q"], 64); err == nil && q == 0 {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// jsonToCBOR converts JSON-RPC responses to CBOR. Every JSON value is mapped to
+// the equivalent CBOR data item, object keys keep their order. Integers are
+// encoded as CBOR integers, other numbers as 64 bit floats.
+func jsonToCBOR(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var out bytes.Buffer
+	for {
+		err := encodeCBORValue(&out, dec)
+		if err == io.EOF {
+			return out.Bytes(), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func encodeCBORValue(out *bytes.Buffer, dec *json.Decoder) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch v := tok.(type) {
+	case json.Delim:
+		var (
+			items bytes.Buffer
+			n     uint64
+		)
+		for dec.More() {
+			if v == '{' {
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				encodeCBORText(&items, key.(string))
+			}
+			if err := encodeCBORValue(&items, dec); err != nil {
+				return err
+			}
+			n++
+		}
+		// Consume the closing delimiter
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+		if v == '{' {
+			encodeCBORHead(out, cborMap, n)
+		} else {
+			encodeCBORHead(out, cborArray, n)
+		}
+		out.Write(items.Bytes())
+	case string:
+		encodeCBORText(out, v)
+	case json.Number:
+		return encodeCBORNumber(out, v)
+	case bool:
+		if v {
+			out.WriteByte(cborTrue)
+		} else {
+			out.WriteByte(cborFalse)
+		}
+	case nil:
+		out.WriteByte(cborNull)
+	default:
+		return fmt.Errorf("unexpected JSON token %v", tok)
+	}
+	return nil
+}
+
+func encodeCBORNumber(out *bytes.Buffer, n json.Number) error {
+	if i, err := strconv.ParseInt(string(n), 10, 64); err == nil {
+		if i < 0 {
+			encodeCBORHead(out, cborNegative, uint64(-1-i))
+		} else {
+			encodeCBORHead(out, cborUnsigned, uint64(i))
+		}
+		return nil
+	}
+	if u, err := strconv.ParseUint(string(n), 10, 64); err == nil {
+		encodeCBORHead(out, cborUnsigned, u)
+		return nil
+	}
+	f, err := strconv.ParseFloat(string(n), 64)
+	if err != nil {
+		return err
+	}
+	out.WriteByte(cborFloat64)
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], math.Float64bits(f))
+	out.Write(buf[:])
+	return nil
+}
+
+func encodeCBORText(out *bytes.Buffer, s string) {
+	encodeCBORHead(out, cborText, uint64(len(s)))
+	out.WriteString(s)
+}
+
+// encodeCBORHead writes the initial byte of a data item with its argument.
+func encodeCBORHead(out *bytes.Buffer, major byte, arg uint64) {
+	major <<= 5
+	switch {
+	case arg < 24:
+		out.WriteByte(major | byte(arg))
+	case arg <= math.MaxUint8:
+		out.Write([]byte{major | 24, byte(arg)})
+	case arg <= math.MaxUint16:
+		out.WriteByte(major | 25)
+		binary.Write(out, binary.BigEndian, uint16(arg))
+	case arg <= math.MaxUint32:
+		out.WriteByte(major | 26)
+		binary.Write(out, binary.BigEndian, uint32(arg))
+	default:
+		out.WriteByte(major | 27)
+		binary.Write(out, binary.BigEndian, arg)
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Test vectors from RFC 8949, appendix A.
+func TestJSONToCBOR(t *testing.T) {
+	tests := []struct {
+		json string
+		cbor string
+	}{
+		{`0`, "00"},
+		{`23`, "17"},
+		{`24`, "1818"},
+		{`1000`, "1903e8"},
+		{`1000000`, "1a000f4240"},
+		{`1000000000000`, "1b000000e8d4a51000"},
+		{`18446744073709551615`, "1bffffffffffffffff"},
+		{`-1`, "20"},
+		{`-1000`, "3903e7"},
+		{`1.1`, "fb3ff199999999999a"},
+		{`false`, "f4"},
+		{`true`, "f5"},
+		{`null`, "f6"},
+		{`""`, "60"},
+		{`"IETF"`, "6449455446"},
+		{`[]`, "80"},
+		{`[1, 2, 3]`, "83010203"},
+		{`[1, [2, 3], [4, 5]]`, "8301820203820405"},
+		{`{}`, "a0"},
+		{`{"a": 1, "b": [2, 3]}`, "a26161016162820203"},
+		{`["a", {"b": "c"}]`, "826161a161626163"},
+	}
+	for _, test := range tests {
+		have, err := jsonToCBOR([]byte(test.json))
+		if err != nil {
+			t.Errorf("%s: %v", test.json, err)
+			continue
+		}
+		if hex.EncodeToString(have) != test.cbor {
+			t.Errorf("%s: have %x, want %s", test.json, have, test.cbor)
+		}
+	}
+}
+
+func TestAcceptsCBOR(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   bool
+	}{
+		{"", false},
+		{"application/json", false},
+		{"application/cbor", true},
+		{"application/json;q=0.5, application/cbor", true},
+		{"application/cbor;q=0", false},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest(http.MethodPost, "http://url.com", nil)
+		r.Header.Set("Accept", test.accept)
+		if have := acceptsCBOR(r); have != test.want {
+			t.Errorf("%q: have %v, want %v", test.accept, have, test.want)
+		}
+	}
+}
+
+func TestHTTPCBORResponse(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	call := func(accept string) (string, []byte) {
+		body := `[{"jsonrpc":"2.0","id":1,"method":"test_echo","params":["x",3,{"S":"y"}]},{"jsonrpc":"2.0","id":2,"method":"test_echo","params":[]}]`
+		request, _ := http.NewRequest(http.MethodPost, ts.URL, strings.NewReader(body))
+		request.Header.Set("Content-Type", contentType)
+		if accept != "" {
+			request.Header.Set("Accept", accept)
+		}
+		resp, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		data, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("failed to read response: %v", err)
+		}
+		return resp.Header.Get("Content-Type"), data
+	}
+	_, jsonResponse := call("")
+	want, err := jsonToCBOR(jsonResponse)
+	if err != nil {
+		t.Fatalf("invalid JSON response: %v", err)
+	}
+	mt, cborResponse := call(cborContentType)
+	if mt != cborContentType {
+		t.Errorf("content type mismatch: have %q, want %q", mt, cborContentType)
+	}
+	if !bytes.Equal(cborResponse, want) {
+		t.Errorf("response mismatch:\nhave %x\nwant %x", cborResponse, want)
+	}
+}
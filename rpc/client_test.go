@@ -310,6 +310,24 @@ func TestClientSubscribe(t *testing.T) {
 	}
 }
 
+// Tests that a draining server refuses new subscriptions but still serves calls.
+func TestClientSubscribeDraining(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	client := DialInProc(server)
+	defer client.Close()
+
+	server.Drain()
+	nc := make(chan int)
+	if _, err := client.Subscribe(context.Background(), "nftest", nc, "someSubscription", 1, 0); err == nil || err.Error() != ErrServerDraining.Error() {
+		t.Fatalf("wrong error subscribing to draining server: %v", err)
+	}
+	var resp echoResult
+	if err := client.Call(&resp, "test_echo", "hello", 10, &echoArgs{"world"}); err != nil {
+		t.Fatal("call failed on draining server:", err)
+	}
+}
+
 // In this test, the connection drops while Subscribe is waiting for a response.
 func TestClientSubscribeClose(t *testing.T) {
 	server := newTestServer()
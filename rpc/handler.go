@@ -19,12 +19,15 @@ package rpc
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"reflect"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/celo-org/celo-blockchain/common"
 	"github.com/celo-org/celo-blockchain/log"
 )
 
@@ -61,9 +64,12 @@ type handler struct {
 	conn           jsonWriter                     // where responses will be sent
 	log            log.Logger
 	allowSubscribe bool
+	authorize      CallAuthorizer // authorizes method calls, nil if all are allowed
 
-	subLock    sync.Mutex
-	serverSubs map[ID]*Subscription
+	subLock      sync.Mutex
+	serverSubs   map[ID]*Subscription
+	maxSubs      int // maximum number of server subscriptions, zero if unlimited
+	reservedSubs int // subscribe calls in progress counting towards maxSubs
 }
 
 type callProc struct {
@@ -88,6 +94,14 @@ func newHandler(connCtx context.Context, conn jsonWriter, idgen func() ID, reg *
 	if conn.remoteAddr() != "" {
 		h.log = h.log.New("conn", conn.remoteAddr())
 	}
+	if l, ok := conn.(interface{ subscriptionLimit() int }); ok {
+		h.maxSubs = l.subscriptionLimit()
+	}
+	if h.authorize = callAuthorizerFrom(connCtx); h.authorize == nil {
+		if a, ok := conn.(interface{ callAuthorizer() CallAuthorizer }); ok {
+			h.authorize = a.callAuthorizer()
+		}
+	}
 	h.unsubscribeCb = newCallback(reflect.Value{}, reflect.ValueOf(h.unsubscribe))
 	return h
 }
@@ -197,6 +211,7 @@ func (h *handler) addSubscriptions(nn []*Notifier) {
 	h.subLock.Lock()
 	defer h.subLock.Unlock()
 
+	h.reservedSubs -= len(nn)
 	for _, n := range nn {
 		if sub := n.takeSubscription(); sub != nil {
 			h.serverSubs[sub.ID] = sub
@@ -317,6 +332,11 @@ func (h *handler) handleCallMsg(ctx *callProc, msg *jsonrpcMessage) *jsonrpcMess
 
 // handleCall processes method calls.
 func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage {
+	if h.authorize != nil && !msg.isUnsubscribe() {
+		if err := h.authorize(msg.Method); err != nil {
+			return msg.errorResponse(err)
+		}
+	}
 	if msg.isSubscribe() {
 		return h.handleSubscribe(cp, msg)
 	}
@@ -347,6 +367,16 @@ func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage
 		}
 		rpcServingTimer.UpdateSince(start)
 		newRPCServingTimer(msg.Method, answer.Error == nil).UpdateSince(start)
+		newRPCSizeHistogram(msg.Method, true).Update(int64(len(msg.Params)))
+		newRPCSizeHistogram(msg.Method, false).Update(int64(len(answer.Result)))
+
+		if elapsed := time.Since(start); isSlowQuery(elapsed) {
+			ctx := []interface{}{"method", msg.Method, "params", paramsDigest(msg.Params), "size", len(msg.Params), "elapsed", common.PrettyDuration(elapsed)}
+			if answer.Error != nil {
+				ctx = append(ctx, "err", answer.Error.Message)
+			}
+			h.log.Warn("Slow RPC call", ctx...)
+		}
 	}
 	return answer
 }
@@ -356,6 +386,9 @@ func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage) *jsonrpcMes
 	if !h.allowSubscribe {
 		return msg.errorResponse(ErrNotificationsUnsupported)
 	}
+	if atomic.LoadInt32(&h.reg.draining) != 0 {
+		return msg.errorResponse(ErrServerDraining)
+	}
 
 	// Subscription method name is first argument.
 	name, err := parseSubscriptionName(msg.Params)
@@ -376,6 +409,12 @@ func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage) *jsonrpcMes
 	}
 	args = args[1:]
 
+	// Reserve a slot for the subscription, it's released in addSubscriptions
+	// once the subscription is installed or the call failed.
+	if !h.reserveSubscription() {
+		return msg.errorResponse(&limitExceededError{fmt.Sprintf("subscription limit of %d reached", h.maxSubs)})
+	}
+
 	// Install notifier in context so the subscription handler can find it.
 	n := &Notifier{h: h, namespace: namespace}
 	cp.notifiers = append(cp.notifiers, n)
@@ -384,6 +423,19 @@ func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage) *jsonrpcMes
 	return h.runMethod(ctx, msg, callb, args)
 }
 
+// reserveSubscription reports whether the connection can take another
+// subscription, and reserves it if so.
+func (h *handler) reserveSubscription() bool {
+	h.subLock.Lock()
+	defer h.subLock.Unlock()
+
+	if h.maxSubs > 0 && len(h.serverSubs)+h.reservedSubs >= h.maxSubs {
+		return false
+	}
+	h.reservedSubs++
+	return true
+}
+
 // runMethod runs the Go callback for an RPC method.
 func (h *handler) runMethod(ctx context.Context, msg *jsonrpcMessage, callb *callback, args []reflect.Value) *jsonrpcMessage {
 	result, err := callb.call(ctx, msg.Method, args)
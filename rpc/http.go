@@ -212,7 +212,7 @@ type httpServerConn struct {
 	r *http.Request
 }
 
-func newHTTPServerConn(r *http.Request, w http.ResponseWriter) ServerCodec {
+func newHTTPServerConn(r *http.Request, w io.Writer) ServerCodec {
 	body := io.LimitReader(r.Body, maxRequestContentLength)
 	conn := &httpServerConn{Reader: body, Writer: w, r: r}
 	return NewCodec(conn)
@@ -254,6 +254,23 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		ctx = context.WithValue(ctx, "Origin", origin)
 	}
 
+	// Clients may ask for a CBOR encoded response instead of JSON, which is
+	// converted once the whole response is available.
+	if acceptsCBOR(r) {
+		var response bytes.Buffer
+		codec := newHTTPServerConn(r, &response)
+		s.serveSingleRequest(ctx, codec)
+		codec.close()
+
+		data, err := jsonToCBOR(response.Bytes())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("content-type", cborContentType)
+		w.Write(data)
+		return
+	}
 	w.Header().Set("content-type", contentType)
 	codec := newHTTPServerConn(r, w)
 	defer codec.close()
@@ -17,7 +17,11 @@
 package rpc
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"sync/atomic"
+	"time"
 
 	"github.com/celo-org/celo-blockchain/metrics"
 )
@@ -37,3 +41,37 @@ func newRPCServingTimer(method string, valid bool) metrics.Timer {
 	m := fmt.Sprintf("rpc/duration/%s/%s", method, flag)
 	return metrics.GetOrRegisterTimer(m, nil)
 }
+
+// newRPCSizeHistogram returns the histogram of the request or response payload
+// sizes of the method, in bytes.
+func newRPCSizeHistogram(method string, request bool) metrics.Histogram {
+	kind := "response"
+	if request {
+		kind = "request"
+	}
+	m := fmt.Sprintf("rpc/size/%s/%s", method, kind)
+	return metrics.GetOrRegisterHistogram(m, nil, metrics.NewExpDecaySample(1028, 0.015))
+}
+
+// slowQueryThreshold is the duration above which calls are logged as slow
+// queries, in nanoseconds. Zero disables the slow query log.
+var slowQueryThreshold int64
+
+// SetSlowQueryThreshold sets the duration above which method calls are logged
+// as slow queries by all servers. Zero disables the slow query log.
+func SetSlowQueryThreshold(threshold time.Duration) {
+	atomic.StoreInt64(&slowQueryThreshold, int64(threshold))
+}
+
+// isSlowQuery reports whether a call that took the given time is a slow query.
+func isSlowQuery(elapsed time.Duration) bool {
+	threshold := atomic.LoadInt64(&slowQueryThreshold)
+	return threshold > 0 && int64(elapsed) >= threshold
+}
+
+// paramsDigest returns a short digest of call parameters, which identifies
+// repeated queries in the slow query log without logging their content.
+func paramsDigest(params []byte) string {
+	digest := sha256.Sum256(params)
+	return hex.EncodeToString(digest[:8])
+}
@@ -128,6 +128,12 @@ func (s *Server) Stop() {
 	}
 }
 
+// Drain makes the server refuse new subscriptions. Method calls and existing
+// subscriptions are still served until the server is stopped.
+func (s *Server) Drain() {
+	atomic.StoreInt32(&s.services.draining, 1)
+}
+
 // RPCService gives meta information about the server.
 // e.g. gives information about the loaded modules.
 type RPCService struct {
@@ -26,6 +26,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/celo-org/celo-blockchain/log"
 )
 
 func TestServerRegisterName(t *testing.T) {
@@ -150,3 +152,50 @@ func TestServerShortLivedConn(t *testing.T) {
 		}
 	}
 }
+
+func TestServerSlowQueryLog(t *testing.T) {
+	var (
+		logged  = make(chan *log.Record, 1)
+		handler = log.Root().GetHandler()
+	)
+	log.Root().SetHandler(log.FuncHandler(func(r *log.Record) error {
+		if r.Msg == "Slow RPC call" {
+			select {
+			case logged <- r:
+			default:
+			}
+		}
+		return nil
+	}))
+	defer log.Root().SetHandler(handler)
+
+	server := newTestServer()
+	defer server.Stop()
+	client := DialInProc(server)
+	defer client.Close()
+
+	// Calls faster than the threshold are not logged
+	SetSlowQueryThreshold(time.Hour)
+	defer SetSlowQueryThreshold(0)
+	if err := client.Call(nil, "test_sleep", 0); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case r := <-logged:
+		t.Fatalf("fast call logged: %v", r.Ctx)
+	default:
+	}
+
+	SetSlowQueryThreshold(10 * time.Millisecond)
+	if err := client.Call(nil, "test_sleep", 20*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case r := <-logged:
+		if r.Ctx[1] != "test_sleep" {
+			t.Fatalf("wrong method logged: %v", r.Ctx)
+		}
+	default:
+		t.Fatal("slow call not logged")
+	}
+}
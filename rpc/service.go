@@ -39,6 +39,7 @@ var (
 type serviceRegistry struct {
 	mu       sync.Mutex
 	services map[string]service
+	draining int32 // set by Server.Drain, refuses new subscriptions
 }
 
 // service represents a registered object.
@@ -36,6 +36,8 @@ var (
 	ErrNotificationsUnsupported = errors.New("notifications not supported")
 	// ErrNotificationNotFound is returned when the notification for the given id is not found
 	ErrSubscriptionNotFound = errors.New("subscription not found")
+	// ErrServerDraining is returned for new subscriptions once the server is draining
+	ErrServerDraining = errors.New("server is draining")
 )
 
 var globalGen = randomIDGenerator()
@@ -122,3 +122,19 @@ func TestBlockNumberOrHash_UnmarshalJSON(t *testing.T) {
 		}
 	}
 }
+
+func TestBlockNumberMarshalText(t *testing.T) {
+	for _, bn := range []BlockNumber{EarliestBlockNumber, LatestBlockNumber, PendingBlockNumber, 1, 0x1234} {
+		blob, err := json.Marshal(bn)
+		if err != nil {
+			t.Fatalf("failed to marshal %d: %v", bn, err)
+		}
+		var dec BlockNumber
+		if err := json.Unmarshal(blob, &dec); err != nil {
+			t.Fatalf("failed to unmarshal %s: %v", blob, err)
+		}
+		if dec != bn {
+			t.Errorf("block number mismatch: have %d, want %d", dec, bn)
+		}
+	}
+}
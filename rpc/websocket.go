@@ -19,12 +19,14 @@ package rpc
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"os"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/celo-org/celo-blockchain/log"
@@ -41,11 +43,30 @@ const (
 
 var wsBufferPool = new(sync.Pool)
 
+var errWriteQueueFull = errors.New("websocket write queue full")
+
+// WebsocketLimits bounds the resources a single WebSocket connection may use.
+// Zero values disable the corresponding limit.
+type WebsocketLimits struct {
+	MaxSubscriptions int   // Maximum number of concurrent subscriptions
+	MaxQueuedWrites  int   // Maximum number of outbound messages waiting to be written
+	MaxMessageSize   int64 // Maximum size of an inbound message, defaults to maxRequestContentLength
+}
+
 // WebsocketHandler returns a handler that serves JSON-RPC to WebSocket connections.
 //
 // allowedOrigins should be a comma-separated list of allowed origin URLs.
 // To allow connections with any origin, pass "*".
 func (s *Server) WebsocketHandler(allowedOrigins []string) http.Handler {
+	return s.WebsocketHandlerWithLimits(allowedOrigins, WebsocketLimits{})
+}
+
+// WebsocketHandlerWithLimits is like WebsocketHandler, but applies the given
+// limits to every connection. Subscriptions beyond the limit are refused with
+// an error, while connections exceeding the message size or falling too far
+// behind on their outbound messages are closed with a close frame stating the
+// reason.
+func (s *Server) WebsocketHandlerWithLimits(allowedOrigins []string, limits WebsocketLimits) http.Handler {
 	var upgrader = websocket.Upgrader{
 		ReadBufferSize:  wsReadBuffer,
 		WriteBufferSize: wsWriteBuffer,
@@ -58,7 +79,8 @@ func (s *Server) WebsocketHandler(allowedOrigins []string) http.Handler {
 			log.Debug("WebSocket upgrade failed", "err", err)
 			return
 		}
-		codec := newWebsocketCodec(conn)
+		codec := newWebsocketCodecWithLimits(conn, limits).(*websocketCodec)
+		codec.authorize = callAuthorizerFrom(r.Context())
 		s.ServeCodec(codec, 0)
 	})
 }
@@ -173,17 +195,30 @@ func wsClientHeaders(endpoint, origin string) (string, http.Header, error) {
 
 type websocketCodec struct {
 	*jsonCodec
-	conn *websocket.Conn
+	conn   *websocket.Conn
+	limits WebsocketLimits
+	queued int32 // Number of writes in progress or waiting for the connection
+
+	authorize CallAuthorizer // Authorizer of the upgraded HTTP request, if any
 
 	wg        sync.WaitGroup
 	pingReset chan struct{}
 }
 
 func newWebsocketCodec(conn *websocket.Conn) ServerCodec {
-	conn.SetReadLimit(maxRequestContentLength)
+	return newWebsocketCodecWithLimits(conn, WebsocketLimits{})
+}
+
+func newWebsocketCodecWithLimits(conn *websocket.Conn, limits WebsocketLimits) ServerCodec {
+	if limits.MaxMessageSize > 0 {
+		conn.SetReadLimit(limits.MaxMessageSize)
+	} else {
+		conn.SetReadLimit(maxRequestContentLength)
+	}
 	wc := &websocketCodec{
 		jsonCodec: NewFuncCodec(conn, conn.WriteJSON, conn.ReadJSON).(*jsonCodec),
 		conn:      conn,
+		limits:    limits,
 		pingReset: make(chan struct{}, 1),
 	}
 	wc.wg.Add(1)
@@ -196,7 +231,22 @@ func (wc *websocketCodec) close() {
 	wc.wg.Wait()
 }
 
+func (wc *websocketCodec) subscriptionLimit() int {
+	return wc.limits.MaxSubscriptions
+}
+
+func (wc *websocketCodec) callAuthorizer() CallAuthorizer {
+	return wc.authorize
+}
+
 func (wc *websocketCodec) writeJSON(ctx context.Context, v interface{}) error {
+	queued := atomic.AddInt32(&wc.queued, 1)
+	defer atomic.AddInt32(&wc.queued, -1)
+
+	if limit := wc.limits.MaxQueuedWrites; limit > 0 && int(queued) > limit {
+		wc.closeWithReason(websocket.ClosePolicyViolation, "outbound queue full")
+		return errWriteQueueFull
+	}
 	err := wc.jsonCodec.writeJSON(ctx, v)
 	if err == nil {
 		// Notify pingLoop to delay the next idle ping.
@@ -208,6 +258,15 @@ func (wc *websocketCodec) writeJSON(ctx context.Context, v interface{}) error {
 	return err
 }
 
+// closeWithReason sends a close frame with the given code and reason to the
+// peer, then closes the connection.
+func (wc *websocketCodec) closeWithReason(code int, reason string) {
+	log.Debug("Closing WebSocket connection", "conn", wc.remoteAddr(), "reason", reason)
+	msg := websocket.FormatCloseMessage(code, reason)
+	wc.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(wsPingWriteTimeout))
+	wc.jsonCodec.close()
+}
+
 // pingLoop sends periodic ping frames when the connection is idle.
 func (wc *websocketCodec) pingLoop() {
 	var timer = time.NewTimer(wsPingInterval)
@@ -23,6 +23,7 @@ import (
 	"net/http/httptest"
 	"reflect"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -113,6 +114,110 @@ func TestWebsocketLargeCall(t *testing.T) {
 	}
 }
 
+// This test checks that subscriptions beyond the connection's limit are refused.
+func TestWebsocketSubscriptionLimit(t *testing.T) {
+	t.Parallel()
+
+	var (
+		srv     = newTestServer()
+		httpsrv = httptest.NewServer(srv.WebsocketHandlerWithLimits([]string{"*"}, WebsocketLimits{MaxSubscriptions: 2}))
+		wsURL   = "ws:" + strings.TrimPrefix(httpsrv.URL, "http:")
+	)
+	defer srv.Stop()
+	defer httpsrv.Close()
+
+	client, err := DialWebsocket(context.Background(), wsURL, "")
+	if err != nil {
+		t.Fatalf("can't dial: %v", err)
+	}
+	defer client.Close()
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Subscribe(context.Background(), "nftest", make(chan int, 1), "someSubscription", 1, i); err != nil {
+			t.Fatalf("subscription %d failed: %v", i, err)
+		}
+	}
+	_, err = client.Subscribe(context.Background(), "nftest", make(chan int, 1), "someSubscription", 1, 2)
+	if err == nil {
+		t.Fatal("no error for subscription beyond the limit")
+	}
+	if rpcErr, ok := err.(Error); !ok || rpcErr.ErrorCode() != -32005 {
+		t.Fatalf("wrong error for subscription beyond the limit: %v", err)
+	}
+}
+
+// This test checks that the connection is closed when a message exceeds the
+// configured size limit.
+func TestWebsocketMessageSizeLimit(t *testing.T) {
+	t.Parallel()
+
+	var (
+		srv     = newTestServer()
+		httpsrv = httptest.NewServer(srv.WebsocketHandlerWithLimits([]string{"*"}, WebsocketLimits{MaxMessageSize: 128}))
+		wsURL   = "ws:" + strings.TrimPrefix(httpsrv.URL, "http:")
+	)
+	defer srv.Stop()
+	defer httpsrv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("can't dial: %v", err)
+	}
+	defer conn.Close()
+
+	call := `{"jsonrpc":"2.0","id":1,"method":"test_echo","params":["` + strings.Repeat("x", 128) + `",1]}`
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(call)); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, _, err = conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseMessageTooBig) {
+		t.Fatalf("wrong error for too large message: %v", err)
+	}
+}
+
+// This test checks that the connection is closed with a policy violation once
+// the outbound messages exceed the write queue limit.
+func TestWebsocketWriteQueueLimit(t *testing.T) {
+	t.Parallel()
+
+	codecs := make(chan *websocketCodec, 1)
+	httpsrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := new(websocket.Upgrader).Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("server WS upgrade error: %v", err)
+			return
+		}
+		codecs <- newWebsocketCodecWithLimits(conn, WebsocketLimits{MaxQueuedWrites: 1}).(*websocketCodec)
+	}))
+	defer httpsrv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws:"+strings.TrimPrefix(httpsrv.URL, "http:"), nil)
+	if err != nil {
+		t.Fatalf("can't dial: %v", err)
+	}
+	defer conn.Close()
+	wc := <-codecs
+	defer wc.close()
+
+	// Stall the connection with a pending write, the next one exceeds the limit.
+	wc.encMu.Lock()
+	go wc.writeJSON(context.Background(), "pending")
+	for atomic.LoadInt32(&wc.queued) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if err := wc.writeJSON(context.Background(), "overflow"); err != errWriteQueueFull {
+		t.Errorf("wrong error for write beyond the queue limit: %v", err)
+	}
+	wc.encMu.Unlock()
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, _, err = conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.ClosePolicyViolation) {
+		t.Fatalf("wrong error for connection exceeding the queue limit: %v", err)
+	}
+}
+
 // This test checks that client handles WebSocket ping frames correctly.
 func TestClientWebsocketPing(t *testing.T) {
 	t.Parallel()
@@ -0,0 +1,163 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package slasher
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/consensus/istanbul"
+	"github.com/celo-org/celo-blockchain/core/types"
+)
+
+var errSameHeader = errors.New("headers are identical")
+
+// DoubleSigningEvidence proves that a validator signed two different blocks at
+// the same height.
+type DoubleSigningEvidence struct {
+	Signer  common.Address // Signer of both blocks
+	Index   uint64         // Index of the signer in the validator set of the blocks
+	HeaderA *types.Header
+	HeaderB *types.Header
+}
+
+// DowntimeInterval is a range of blocks, within a single epoch, a validator did
+// not sign.
+type DowntimeInterval struct {
+	Start       uint64 // First block of the interval
+	End         uint64 // Last block of the interval, inclusive
+	SignerIndex uint64 // Index of the signer in the validator set of the epoch
+}
+
+// DowntimeEvidence proves that a validator missed signing consecutive blocks.
+// The blocks are split into intervals at epoch boundaries, as the index of the
+// signer in the validator set may change from one epoch to the next.
+type DowntimeEvidence struct {
+	Signer    common.Address
+	Intervals []DowntimeInterval
+}
+
+// Start returns the first block the validator did not sign.
+func (e *DowntimeEvidence) Start() uint64 {
+	return e.Intervals[0].Start
+}
+
+// End returns the last block the validator did not sign.
+func (e *DowntimeEvidence) End() uint64 {
+	return e.Intervals[len(e.Intervals)-1].End
+}
+
+// doubleSigningEvidence returns the evidence of the validators having signed both
+// headers, which have to be at the same height and sealed by the given validators.
+func doubleSigningEvidence(a, b *types.Header, validators []istanbul.Validator) ([]*DoubleSigningEvidence, error) {
+	if a.Hash() == b.Hash() {
+		return nil, errSameHeader
+	}
+	extraA, err := types.ExtractIstanbulExtra(a)
+	if err != nil {
+		return nil, err
+	}
+	extraB, err := types.ExtractIstanbulExtra(b)
+	if err != nil {
+		return nil, err
+	}
+	if extraA.AggregatedSeal.Bitmap == nil || extraB.AggregatedSeal.Bitmap == nil {
+		return nil, nil
+	}
+	both := new(big.Int).And(extraA.AggregatedSeal.Bitmap, extraB.AggregatedSeal.Bitmap)
+
+	var evidence []*DoubleSigningEvidence
+	for i, validator := range validators {
+		if both.Bit(i) == 1 {
+			evidence = append(evidence, &DoubleSigningEvidence{
+				Signer:  validator.Address(),
+				Index:   uint64(i),
+				HeaderA: a,
+				HeaderB: b,
+			})
+		}
+	}
+	return evidence, nil
+}
+
+// downtimeTracker follows the signatures of the canonical blocks, keeping the
+// streak of blocks missed by each validator of the current set.
+type downtimeTracker struct {
+	epochSize uint64
+	last      common.Hash // Hash of the last block observed
+	streaks   map[common.Address]*DowntimeEvidence
+}
+
+func newDowntimeTracker(epochSize uint64) *downtimeTracker {
+	return &downtimeTracker{
+		epochSize: epochSize,
+		streaks:   make(map[common.Address]*DowntimeEvidence),
+	}
+}
+
+// reset forgets all the streaks, as they can't be proven to be consecutive.
+func (t *downtimeTracker) reset() {
+	t.last = common.Hash{}
+	t.streaks = make(map[common.Address]*DowntimeEvidence)
+}
+
+// observe records the signers of a block, as found in the parent seal bitmap of
+// its child, and returns the streaks reaching the slashable window. Reported
+// streaks start over, so a validator remaining down is reported once per window.
+func (t *downtimeTracker) observe(number uint64, hash, parentHash common.Hash, bitmap *big.Int, validators []istanbul.Validator, window uint64) []*DowntimeEvidence {
+	// Streaks only span blocks observed one after the other on the same chain
+	if t.last != (common.Hash{}) && t.last != parentHash {
+		t.reset()
+	}
+	t.last = hash
+
+	current := make(map[common.Address]struct{}, len(validators))
+	for _, validator := range validators {
+		current[validator.Address()] = struct{}{}
+	}
+	for signer := range t.streaks {
+		if _, ok := current[signer]; !ok {
+			delete(t.streaks, signer)
+		}
+	}
+	epoch := istanbul.GetEpochNumber(number, t.epochSize)
+
+	var evidence []*DowntimeEvidence
+	for i, validator := range validators {
+		signer := validator.Address()
+		if bitmap != nil && bitmap.Bit(i) == 1 {
+			delete(t.streaks, signer)
+			continue
+		}
+		streak := t.streaks[signer]
+		if streak == nil {
+			streak = &DowntimeEvidence{Signer: signer}
+			t.streaks[signer] = streak
+		}
+		if n := len(streak.Intervals); n > 0 && istanbul.GetEpochNumber(streak.Intervals[n-1].Start, t.epochSize) == epoch && streak.Intervals[n-1].SignerIndex == uint64(i) {
+			streak.Intervals[n-1].End = number
+		} else {
+			streak.Intervals = append(streak.Intervals, DowntimeInterval{Start: number, End: number, SignerIndex: uint64(i)})
+		}
+		if window > 0 && streak.End()-streak.Start()+1 >= window {
+			evidence = append(evidence, streak)
+			delete(t.streaks, signer)
+		}
+	}
+	return evidence
+}
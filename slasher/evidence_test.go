@@ -0,0 +1,121 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package slasher
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/consensus/istanbul"
+	"github.com/celo-org/celo-blockchain/consensus/istanbul/validator"
+	"github.com/celo-org/celo-blockchain/core/types"
+	blscrypto "github.com/celo-org/celo-blockchain/crypto/bls"
+	"github.com/celo-org/celo-blockchain/rlp"
+	. "github.com/onsi/gomega"
+)
+
+func newTestValidators(n int) []istanbul.Validator {
+	validators := make([]istanbul.Validator, n)
+	for i := range validators {
+		validators[i] = validator.New(common.BigToAddress(big.NewInt(int64(i+1))), blscrypto.SerializedPublicKey{})
+	}
+	return validators
+}
+
+func newTestHeader(t *testing.T, number uint64, round int64, bitmap int64) *types.Header {
+	extra, err := rlp.EncodeToBytes(&types.IstanbulExtra{
+		RemovedValidators:    big.NewInt(0),
+		AggregatedSeal:       types.IstanbulAggregatedSeal{Bitmap: big.NewInt(bitmap), Round: big.NewInt(round)},
+		ParentAggregatedSeal: types.IstanbulAggregatedSeal{Bitmap: big.NewInt(0), Round: big.NewInt(0)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The seals are not part of the hash, so the rounds also change the timestamp
+	return &types.Header{
+		Number: new(big.Int).SetUint64(number),
+		Time:   uint64(round),
+		Extra:  append(make([]byte, types.IstanbulExtraVanity), extra...),
+	}
+}
+
+func TestDoubleSigningEvidence(t *testing.T) {
+	g := NewGomegaWithT(t)
+	validators := newTestValidators(4)
+
+	a := newTestHeader(t, 5, 0, 0x7) // signed by 0, 1, 2
+	b := newTestHeader(t, 5, 1, 0xe) // signed by 1, 2, 3
+	evidence, err := doubleSigningEvidence(a, b, validators)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(evidence).To(HaveLen(2))
+	g.Expect(evidence[0].Signer).To(Equal(validators[1].Address()))
+	g.Expect(evidence[0].Index).To(BeEquivalentTo(1))
+	g.Expect(evidence[1].Signer).To(Equal(validators[2].Address()))
+	g.Expect(evidence[1].Index).To(BeEquivalentTo(2))
+	g.Expect(evidence[1].HeaderA).To(Equal(a))
+	g.Expect(evidence[1].HeaderB).To(Equal(b))
+
+	_, err = doubleSigningEvidence(a, a, validators)
+	g.Expect(err).To(Equal(errSameHeader))
+}
+
+// observeRange feeds the tracker with consecutive blocks, and returns the evidence
+// found along the way.
+func observeRange(tracker *downtimeTracker, from, to uint64, bitmap int64, validators []istanbul.Validator, window uint64) []*DowntimeEvidence {
+	var evidence []*DowntimeEvidence
+	for number := from; number <= to; number++ {
+		hash, parent := common.BigToHash(new(big.Int).SetUint64(number)), common.BigToHash(new(big.Int).SetUint64(number-1))
+		evidence = append(evidence, tracker.observe(number, hash, parent, big.NewInt(bitmap), validators, window)...)
+	}
+	return evidence
+}
+
+func TestDowntimeTracker(t *testing.T) {
+	g := NewGomegaWithT(t)
+	validators := newTestValidators(3)
+	tracker := newDowntimeTracker(10)
+
+	// Validator 2 misses blocks 5 to 14, crossing the epoch boundary at block 10
+	g.Expect(observeRange(tracker, 1, 4, 0x7, validators, 10)).To(BeEmpty())
+	g.Expect(observeRange(tracker, 5, 13, 0x3, validators, 10)).To(BeEmpty())
+	evidence := observeRange(tracker, 14, 14, 0x3, validators, 10)
+	g.Expect(evidence).To(HaveLen(1))
+	g.Expect(evidence[0].Signer).To(Equal(validators[2].Address()))
+	g.Expect(evidence[0].Intervals).To(Equal([]DowntimeInterval{
+		{Start: 5, End: 10, SignerIndex: 2},
+		{Start: 11, End: 14, SignerIndex: 2},
+	}))
+
+	// The streak starts over after being reported
+	g.Expect(observeRange(tracker, 15, 20, 0x3, validators, 10)).To(BeEmpty())
+	g.Expect(tracker.streaks[validators[2].Address()].Start()).To(BeEquivalentTo(15))
+
+	// Signing ends the streak
+	g.Expect(observeRange(tracker, 21, 21, 0x7, validators, 10)).To(BeEmpty())
+	g.Expect(tracker.streaks).To(BeEmpty())
+
+	// Validators leaving the set are forgotten
+	g.Expect(observeRange(tracker, 22, 25, 0x1, validators, 10)).To(BeEmpty())
+	g.Expect(tracker.streaks).To(HaveLen(2))
+	g.Expect(observeRange(tracker, 26, 26, 0x1, validators[:2], 10)).To(BeEmpty())
+	g.Expect(tracker.streaks).To(HaveLen(1))
+
+	// Blocks not following the last one observed reset the streaks
+	g.Expect(observeRange(tracker, 40, 40, 0x1, validators[:2], 10)).To(BeEmpty())
+	g.Expect(tracker.streaks[validators[1].Address()].Start()).To(BeEquivalentTo(40))
+}
@@ -0,0 +1,270 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package slasher
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/celo-org/celo-blockchain/accounts"
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/consensus/istanbul"
+	"github.com/celo-org/celo-blockchain/contracts"
+	"github.com/celo-org/celo-blockchain/contracts/abis"
+	celoaccounts "github.com/celo-org/celo-blockchain/contracts/accounts"
+	"github.com/celo-org/celo-blockchain/contracts/election"
+	"github.com/celo-org/celo-blockchain/contracts/locked_gold"
+	"github.com/celo-org/celo-blockchain/contracts/slashing"
+	"github.com/celo-org/celo-blockchain/contracts/validators"
+	"github.com/celo-org/celo-blockchain/core"
+	"github.com/celo-org/celo-blockchain/core/state"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/core/vm"
+	"github.com/celo-org/celo-blockchain/log"
+	"github.com/celo-org/celo-blockchain/params"
+	"github.com/celo-org/celo-blockchain/rlp"
+	"github.com/celo-org/celo-blockchain/rpc"
+)
+
+// call is a transaction to be sent to a slashing contract.
+type call struct {
+	to   common.Address
+	data []byte
+	gas  uint64 // Gas limit, set by simulating the call
+}
+
+// slashingTarget are the arguments common to the slash methods of the slashing
+// contracts, identifying the validator and group to slash and how to revoke
+// their votes.
+type slashingTarget struct {
+	account      common.Address
+	group        common.Address
+	historyIndex *big.Int
+	decrements   []election.VoteDecrements // Of the validator and of the group
+}
+
+// reportDowntime reports the validator for not signing blocks, storing the
+// signature bitmaps of the intervals first if needed.
+func (s *Service) reportDowntime(ctx context.Context, evidence *DowntimeEvidence) error {
+	state, header, err := s.backend.StateAndHeaderByNumberOrHash(ctx, rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber))
+	if err != nil {
+		return err
+	}
+	vmRunner := s.backend.NewEVMRunner(ctx, header, state)
+	to, err := contracts.GetRegisteredAddress(vmRunner, params.DowntimeSlasherRegistryId)
+	if err != nil {
+		return err
+	}
+	incentives, err := slashing.GetDowntimeSlashingIncentives(vmRunner)
+	if err != nil {
+		return err
+	}
+	var (
+		calls                  []*call
+		startBlocks, endBlocks []*big.Int
+		signerIndices          []*big.Int
+	)
+	for _, interval := range evidence.Intervals {
+		start, end := new(big.Int).SetUint64(interval.Start), new(big.Int).SetUint64(interval.End)
+		isSet, err := slashing.IsBitmapSetForInterval(vmRunner, interval.Start, interval.End)
+		if err != nil {
+			return err
+		}
+		if !isSet {
+			data, err := abis.DowntimeSlasher.Pack("setBitmapForInterval", start, end)
+			if err != nil {
+				return err
+			}
+			calls = append(calls, &call{to: to, data: data})
+		}
+		startBlocks = append(startBlocks, start)
+		endBlocks = append(endBlocks, end)
+		signerIndices = append(signerIndices, new(big.Int).SetUint64(interval.SignerIndex))
+	}
+	target, err := s.slashingTarget(vmRunner, evidence.Signer, evidence.Start(), incentives.Penalty)
+	if err != nil {
+		return err
+	}
+	data, err := abis.DowntimeSlasher.Pack("slash", startBlocks, endBlocks, signerIndices, target.historyIndex,
+		target.decrements[0].Lessers, target.decrements[0].Greaters, target.decrements[0].Indices,
+		target.decrements[1].Lessers, target.decrements[1].Greaters, target.decrements[1].Indices)
+	if err != nil {
+		return err
+	}
+	calls = append(calls, &call{to: to, data: data})
+
+	return s.submit(ctx, state, header, calls, "offence", "downtime", "validator", target.account, "group", target.group, "start", evidence.Start(), "end", evidence.End())
+}
+
+// reportDoubleSigning reports the validator for signing two blocks at the same height.
+func (s *Service) reportDoubleSigning(ctx context.Context, evidence *DoubleSigningEvidence) error {
+	state, header, err := s.backend.StateAndHeaderByNumberOrHash(ctx, rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber))
+	if err != nil {
+		return err
+	}
+	vmRunner := s.backend.NewEVMRunner(ctx, header, state)
+	to, err := contracts.GetRegisteredAddress(vmRunner, params.DoubleSigningSlasherRegistryId)
+	if err != nil {
+		return err
+	}
+	incentives, err := slashing.GetDoubleSigningSlashingIncentives(vmRunner)
+	if err != nil {
+		return err
+	}
+	number := evidence.HeaderA.Number.Uint64()
+	target, err := s.slashingTarget(vmRunner, evidence.Signer, number, incentives.Penalty)
+	if err != nil {
+		return err
+	}
+	headerA, err := rlp.EncodeToBytes(evidence.HeaderA)
+	if err != nil {
+		return err
+	}
+	headerB, err := rlp.EncodeToBytes(evidence.HeaderB)
+	if err != nil {
+		return err
+	}
+	data, err := abis.DoubleSigningSlasher.Pack("slash", evidence.Signer, new(big.Int).SetUint64(evidence.Index), headerA, headerB, target.historyIndex,
+		target.decrements[0].Lessers, target.decrements[0].Greaters, target.decrements[0].Indices,
+		target.decrements[1].Lessers, target.decrements[1].Greaters, target.decrements[1].Indices)
+	if err != nil {
+		return err
+	}
+	calls := []*call{{to: to, data: data}}
+
+	return s.submit(ctx, state, header, calls, "offence", "double signing", "validator", target.account, "group", target.group, "number", number)
+}
+
+// slashingTarget looks up the account of the signer, its group when the offence
+// was committed, and the votes to revoke for slashing both by the penalty.
+func (s *Service) slashingTarget(vmRunner vm.EVMRunner, signer common.Address, number uint64, penalty *big.Int) (*slashingTarget, error) {
+	account, err := celoaccounts.SignerToAccount(vmRunner, signer)
+	if err != nil {
+		return nil, err
+	}
+	history, err := validators.GetMembershipHistory(vmRunner, account)
+	if err != nil {
+		return nil, err
+	}
+	group, historyIndex, err := history.MembershipAt(istanbul.GetEpochNumber(number, s.engine.EpochSize()))
+	if err != nil {
+		return nil, err
+	}
+	decrements, err := election.GetSlashingVoteDecrements(vmRunner, []common.Address{account, group}, penalty)
+	if err != nil {
+		return nil, err
+	}
+	return &slashingTarget{
+		account:      account,
+		group:        group,
+		historyIndex: historyIndex,
+		decrements:   decrements,
+	}, nil
+}
+
+// simulate executes the calls one after the other on top of the given state, as
+// if included in the next block, setting their gas limits and returning the
+// reward of the reporter.
+func (s *Service) simulate(ctx context.Context, statedb *state.StateDB, head *types.Header, calls []*call) (*big.Int, error) {
+	header := types.CopyHeader(head)
+	header.ParentHash = head.Hash()
+	header.Number = new(big.Int).Add(head.Number, common.Big1)
+
+	gasLimit := s.backend.GetBlockGasLimit(ctx, rpc.BlockNumberOrHashWithHash(head.Hash(), false))
+	vmRunner := s.backend.NewEVMRunner(ctx, header, statedb)
+	before, err := locked_gold.GetAccountNonvotingLockedGold(vmRunner, s.config.Account)
+	if err != nil {
+		return nil, err
+	}
+	for i, call := range calls {
+		msg := types.NewMessage(s.config.Account, &call.to, 0, common.Big0, gasLimit, common.Big0, nil, nil, nil, call.data, false, false)
+		evm, vmError, err := s.backend.GetEVM(ctx, msg, statedb, header)
+		if err != nil {
+			return nil, err
+		}
+		gp := new(core.GasPool).AddGas(math.MaxUint64)
+		result, err := core.ApplyMessageWithoutGasPriceMinimum(evm, msg, gp, vmRunner)
+		if err := vmError(); err != nil {
+			return nil, err
+		}
+		if err != nil {
+			return nil, fmt.Errorf("call %d: %w", i, err)
+		}
+		if result.Failed() {
+			return nil, fmt.Errorf("call %d: %w", i, result.Err)
+		}
+		// Leave room for the gas refunded at the end and the 63/64 rule
+		call.gas = (result.UsedGas + result.RefundedGas + params.CallStipend) * 64 / 63
+		statedb.Finalise(true)
+	}
+	after, err := locked_gold.GetAccountNonvotingLockedGold(vmRunner, s.config.Account)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).Sub(after, before), nil
+}
+
+// submit simulates the calls to estimate their profit, then signs and sends them
+// if submission is enabled and the profit is high enough, or logs them otherwise.
+func (s *Service) submit(ctx context.Context, statedb *state.StateDB, header *types.Header, calls []*call, logCtx ...interface{}) error {
+	reward, err := s.simulate(ctx, statedb, header, calls)
+	if err != nil {
+		return err
+	}
+	price, err := s.backend.SuggestPrice(ctx, nil)
+	if err != nil {
+		return err
+	}
+	var gas uint64
+	for _, call := range calls {
+		gas += call.gas
+	}
+	profit := new(big.Int).Sub(reward, new(big.Int).Mul(price, new(big.Int).SetUint64(gas)))
+	logCtx = append(logCtx, "txs", len(calls), "gas", gas, "reward", reward, "profit", profit)
+
+	if !s.config.Submit {
+		log.Info("Slashing transactions not submitted", logCtx...)
+		return nil
+	}
+	if s.config.MinProfit != nil && profit.Cmp(s.config.MinProfit) < 0 {
+		log.Info("Slashing transactions not profitable enough", append(logCtx, "minprofit", s.config.MinProfit)...)
+		return nil
+	}
+	account := accounts.Account{Address: s.config.Account}
+	wallet, err := s.backend.AccountManager().Find(account)
+	if err != nil {
+		return err
+	}
+	nonce, err := s.backend.GetPoolNonce(ctx, s.config.Account)
+	if err != nil {
+		return err
+	}
+	for i, call := range calls {
+		tx := types.NewTransaction(nonce+uint64(i), call.to, common.Big0, call.gas, price, nil, nil, nil, call.data)
+		signed, err := wallet.SignTx(account, tx, s.backend.ChainConfig().ChainID)
+		if err != nil {
+			return err
+		}
+		if err := s.backend.SendTx(ctx, signed); err != nil {
+			return err
+		}
+	}
+	log.Info("Submitted slashing transactions", logCtx...)
+	return nil
+}
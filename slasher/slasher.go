@@ -0,0 +1,245 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package slasher implements a service watching the chain for validators
+// misbehaving, and reporting the evidence to the slashing contracts.
+package slasher
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+
+	"github.com/celo-org/celo-blockchain/accounts"
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/consensus"
+	"github.com/celo-org/celo-blockchain/contracts/slashing"
+	"github.com/celo-org/celo-blockchain/core"
+	"github.com/celo-org/celo-blockchain/core/state"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/core/vm"
+	"github.com/celo-org/celo-blockchain/event"
+	"github.com/celo-org/celo-blockchain/log"
+	"github.com/celo-org/celo-blockchain/node"
+	"github.com/celo-org/celo-blockchain/params"
+	"github.com/celo-org/celo-blockchain/rpc"
+)
+
+const (
+	// chainHeadChanSize is the size of channel listening to ChainHeadEvent.
+	chainHeadChanSize = 10
+	// chainSideChanSize is the size of channel listening to ChainSideEvent.
+	chainSideChanSize = 10
+
+	// reportedRetention is the number of blocks double signing offences are
+	// remembered for, to avoid reporting them again.
+	reportedRetention = 4096
+)
+
+var errNoAccount = errors.New("an account is required to submit slashing transactions")
+
+// Config are the configuration parameters of the slasher.
+type Config struct {
+	Enabled   bool           // Whether to watch the chain for slashable misbehaviour
+	Submit    bool           // Whether to submit the slashing transactions, or only log them
+	Account   common.Address `toml:",omitempty"` // Account submitting the slashing transactions and receiving the rewards
+	MinProfit *big.Int       `toml:",omitempty"` // Minimum reward, net of the transaction fees, to submit the slashing transactions
+}
+
+// backend encompasses the functionality needed to find and report evidence
+type backend interface {
+	SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription
+	SubscribeChainSideEvent(ch chan<- core.ChainSideEvent) event.Subscription
+	HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error)
+	HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error)
+	StateAndHeaderByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, error)
+	NewEVMRunner(ctx context.Context, header *types.Header, state vm.StateDB) vm.EVMRunner
+	GetEVM(ctx context.Context, msg core.Message, state *state.StateDB, header *types.Header) (*vm.EVM, func() error, error)
+	GetBlockGasLimit(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) uint64
+	SuggestPrice(ctx context.Context, currencyAddress *common.Address) (*big.Int, error)
+	GetPoolNonce(ctx context.Context, addr common.Address) (uint64, error)
+	SendTx(ctx context.Context, signedTx *types.Transaction) error
+	AccountManager() *accounts.Manager
+	ChainConfig() *params.ChainConfig
+}
+
+// doubleSigningKey identifies a double signing offence, which is only reported once.
+type doubleSigningKey struct {
+	signer common.Address
+	number uint64
+}
+
+// Service watches the blocks imported by the node for validators signing two
+// blocks at the same height, or missing to sign blocks for longer than the
+// slashable downtime, and reports them to the slashing contracts.
+type Service struct {
+	config  *Config
+	backend backend
+	engine  consensus.Engine
+
+	downtime *downtimeTracker
+	reported map[doubleSigningKey]struct{}
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates a slasher and registers it in the node.
+func New(stack *node.Node, backend backend, engine consensus.Engine, config *Config) error {
+	if config.Submit && config.Account == (common.Address{}) {
+		return errNoAccount
+	}
+	s := &Service{
+		config:   config,
+		backend:  backend,
+		engine:   engine,
+		downtime: newDowntimeTracker(engine.EpochSize()),
+		reported: make(map[doubleSigningKey]struct{}),
+		quit:     make(chan struct{}),
+	}
+	stack.RegisterLifecycle(s)
+	return nil
+}
+
+// Start implements node.Lifecycle, starting to watch the chain.
+func (s *Service) Start() error {
+	var (
+		headCh  = make(chan core.ChainHeadEvent, chainHeadChanSize)
+		headSub = s.backend.SubscribeChainHeadEvent(headCh)
+		sideCh  = make(chan core.ChainSideEvent, chainSideChanSize)
+		sideSub = s.backend.SubscribeChainSideEvent(sideCh)
+	)
+	s.wg.Add(1)
+	go s.loop(headCh, headSub, sideCh, sideSub)
+
+	log.Info("Slasher started", "submit", s.config.Submit, "account", s.config.Account)
+	return nil
+}
+
+// Stop implements node.Lifecycle, terminating the slasher.
+func (s *Service) Stop() error {
+	close(s.quit)
+	s.wg.Wait()
+	log.Info("Slasher stopped")
+	return nil
+}
+
+func (s *Service) loop(headCh chan core.ChainHeadEvent, headSub event.Subscription, sideCh chan core.ChainSideEvent, sideSub event.Subscription) {
+	defer s.wg.Done()
+	defer headSub.Unsubscribe()
+	defer sideSub.Unsubscribe()
+
+	for {
+		select {
+		case ev := <-headCh:
+			if err := s.handleHead(ev.Block.Header()); err != nil {
+				log.Debug("Failed to check block signatures", "number", ev.Block.Number(), "hash", ev.Block.Hash(), "err", err)
+			}
+		case ev := <-sideCh:
+			if err := s.handleSide(ev.Block.Header()); err != nil {
+				log.Debug("Failed to check side block", "number", ev.Block.Number(), "hash", ev.Block.Hash(), "err", err)
+			}
+		case <-headSub.Err():
+			return
+		case <-sideSub.Err():
+			return
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// handleHead checks the signers of the parent of the new head, which are only
+// known from the parent seal of the head, for downtime.
+func (s *Service) handleHead(head *types.Header) error {
+	number := head.Number.Uint64()
+	if number < 2 {
+		return nil
+	}
+	extra, err := types.ExtractIstanbulExtra(head)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	parent, err := s.backend.HeaderByHash(ctx, head.ParentHash)
+	if err != nil {
+		return err
+	}
+	if parent == nil {
+		s.downtime.reset()
+		return nil
+	}
+	validators := s.engine.GetValidators(new(big.Int).SetUint64(number-2), parent.ParentHash)
+	for key := range s.reported {
+		if key.number+reportedRetention < number {
+			delete(s.reported, key)
+		}
+	}
+
+	state, header, err := s.backend.StateAndHeaderByNumberOrHash(ctx, rpc.BlockNumberOrHashWithHash(head.Hash(), false))
+	if err != nil {
+		return err
+	}
+	window, err := slashing.GetSlashableDowntime(s.backend.NewEVMRunner(ctx, header, state))
+	if err != nil {
+		// Keep tracking, the streaks are only reported once the slasher is deployed
+		log.Trace("Slashable downtime unavailable", "err", err)
+		window = 0
+	}
+	for _, evidence := range s.downtime.observe(number-1, head.ParentHash, parent.ParentHash, extra.ParentAggregatedSeal.Bitmap, validators, window) {
+		log.Info("Found validator downtime", "signer", evidence.Signer, "start", evidence.Start(), "end", evidence.End())
+		if err := s.reportDowntime(ctx, evidence); err != nil {
+			log.Warn("Failed to report validator downtime", "signer", evidence.Signer, "start", evidence.Start(), "end", evidence.End(), "err", err)
+		}
+	}
+	return nil
+}
+
+// handleSide checks whether validators signed both a block imported on a side
+// chain and the canonical block at the same height.
+func (s *Service) handleSide(side *types.Header) error {
+	number := side.Number.Uint64()
+	if number == 0 {
+		return nil
+	}
+	ctx := context.Background()
+	canonical, err := s.backend.HeaderByNumber(ctx, rpc.BlockNumber(number))
+	if err != nil {
+		return err
+	}
+	if canonical == nil || canonical.Hash() == side.Hash() {
+		return nil
+	}
+	validators := s.engine.GetValidators(new(big.Int).SetUint64(number-1), canonical.ParentHash)
+	evidences, err := doubleSigningEvidence(canonical, side, validators)
+	if err != nil {
+		return err
+	}
+	for _, evidence := range evidences {
+		key := doubleSigningKey{signer: evidence.Signer, number: number}
+		if _, ok := s.reported[key]; ok {
+			continue
+		}
+		s.reported[key] = struct{}{}
+
+		log.Info("Found validator double signing", "signer", evidence.Signer, "number", number, "hashA", evidence.HeaderA.Hash(), "hashB", evidence.HeaderB.Hash())
+		if err := s.reportDoubleSigning(ctx, evidence); err != nil {
+			log.Warn("Failed to report validator double signing", "signer", evidence.Signer, "number", number, "err", err)
+		}
+	}
+	return nil
+}
@@ -91,8 +91,12 @@ type Node struct {
 // NewNode creates a new running node with the provided config.
 func NewNode(c *NodeConfig, genesis *core.Genesis) (*Node, error) {
 
-	// p2p key and address
-	c.P2P.PrivateKey = c.ValidatorAccount.PrivateKey
+	// p2p key, kept separate from the validator key as in production setups
+	p2pKey, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	c.P2P.PrivateKey = p2pKey
 
 	// Make temp datadir
 	datadir, err := ioutil.TempDir("", "celo_datadir")
@@ -208,17 +212,19 @@ func (n *Node) Start() error {
 	return n.Eth.StartMining()
 }
 
-// Close shuts down the node and releases all resources and removes the datadir
-// unless an error is returned, in which case there is no guarantee that all
-// resources are released.
+// Close shuts down the node, releases all resources and removes the datadir.
+// Resources are released even if an error is encountered on the way, the first
+// error is returned.
 func (n *Node) Close() error {
 	err := n.Tracker.StopTracking()
-	if err != nil {
-		return err
+	if n.WsClient != nil {
+		n.WsClient.Close()
 	}
-	n.WsClient.Close()
 	if n.Node != nil {
-		err = n.Node.Close() // This also shuts down the Eth service
+		// This also shuts down the Eth service
+		if closeErr := n.Node.Close(); err == nil {
+			err = closeErr
+		}
 	}
 	os.RemoveAll(n.Config.DataDir)
 	return err
@@ -374,7 +380,7 @@ func NewNetwork(accounts *env.AccountsConfig, gc *genesis.Config) (Network, erro
 		if err != nil {
 			return nil, err
 		}
-		en := enode.NewV4(&n.Key.PublicKey, net.ParseIP(host), portNum, portNum)
+		en := enode.NewV4(&n.Config.P2P.PrivateKey.PublicKey, net.ParseIP(host), portNum, portNum)
 		enodes[i] = en
 	}
 	// Connect nodes to each other, although this means that nodes can reach
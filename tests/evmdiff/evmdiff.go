@@ -0,0 +1,148 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package evmdiff runs the same programs through the Celo EVM and the upstream
+// go-ethereum one, and reports where their gas and state outcomes diverge.
+//
+// Programs only use the opcodes both EVMs share with the same environment,
+// leaving out the calls which could reach the Celo specific precompiles. The
+// upstream executor is built with the evmdiff tag, which requires adding the
+// upstream module the Celo EVM was merged from:
+//
+//	go get github.com/ethereum/go-ethereum@v1.9.25
+//	go test -tags evmdiff ./tests/evmdiff
+package evmdiff
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"math/rand"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/core/rawdb"
+	"github.com/celo-org/celo-blockchain/core/state"
+	"github.com/celo-org/celo-blockchain/core/vm"
+	"github.com/celo-org/celo-blockchain/core/vm/runtime"
+	"github.com/celo-org/celo-blockchain/params"
+)
+
+var (
+	// Environment shared by the executions of both EVMs
+	Origin      = common.HexToAddress("0x3000000000000000000000000000000000000001")
+	Address     = common.HexToAddress("0x3000000000000000000000000000000000000002")
+	BlockNumber = big.NewInt(1)
+	BlockTime   = big.NewInt(1)
+)
+
+// Outcome is the result of executing a program.
+type Outcome struct {
+	Ret     []byte
+	GasUsed uint64
+	Err     string      // Execution error, empty if the program succeeded
+	Root    common.Hash // State root after the execution
+	Logs    int
+}
+
+// Executor executes the code with the given input and gas.
+type Executor func(code, input []byte, gas uint64) Outcome
+
+// Diff describes the first difference between the outcomes of both EVMs, or
+// returns nil if they match.
+func Diff(celo, upstream Outcome) error {
+	switch {
+	case (celo.Err == "") != (upstream.Err == ""):
+		return fmt.Errorf("error %q, %q upstream", celo.Err, upstream.Err)
+	case celo.GasUsed != upstream.GasUsed:
+		return fmt.Errorf("gas used %d, %d upstream", celo.GasUsed, upstream.GasUsed)
+	case !bytes.Equal(celo.Ret, upstream.Ret):
+		return fmt.Errorf("returned %x, %x upstream", celo.Ret, upstream.Ret)
+	case celo.Logs != upstream.Logs:
+		return fmt.Errorf("%d logs, %d upstream", celo.Logs, upstream.Logs)
+	case celo.Root != upstream.Root:
+		return fmt.Errorf("state root %x, %x upstream", celo.Root, upstream.Root)
+	}
+	return nil
+}
+
+// CeloExecutor executes the code with the Celo EVM, with the Istanbul rules and
+// none of the Celo hard forks.
+func CeloExecutor(code, input []byte, gas uint64) Outcome {
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.CreateAccount(Address)
+	statedb.SetCode(Address, code)
+
+	ret, leftOverGas, err := runtime.Call(Address, input, &runtime.Config{
+		ChainConfig: celoChainConfig,
+		Origin:      Origin,
+		BlockNumber: BlockNumber,
+		Time:        BlockTime,
+		GasLimit:    gas,
+		State:       statedb,
+	})
+	outcome := Outcome{
+		Ret:     ret,
+		GasUsed: gas - leftOverGas,
+		Root:    statedb.IntermediateRoot(true),
+		Logs:    len(statedb.Logs()),
+	}
+	if err != nil {
+		outcome.Err = err.Error()
+	}
+	return outcome
+}
+
+var celoChainConfig = &params.ChainConfig{
+	ChainID:             big.NewInt(1),
+	HomesteadBlock:      new(big.Int),
+	EIP150Block:         new(big.Int),
+	EIP155Block:         new(big.Int),
+	EIP158Block:         new(big.Int),
+	ByzantiumBlock:      new(big.Int),
+	ConstantinopleBlock: new(big.Int),
+	PetersburgBlock:     new(big.Int),
+	IstanbulBlock:       new(big.Int),
+}
+
+// sharedOps are the opcodes behaving the same in both EVMs given the same
+// environment. The calls and creations are left out, as they could reach the
+// Celo specific precompiles, and so are BALANCE, EXTCODEHASH and SLOAD, as
+// Celo did not adopt their EIP-1884 repricing.
+var sharedOps = []vm.OpCode{
+	vm.STOP, vm.ADD, vm.MUL, vm.SUB, vm.DIV, vm.SDIV, vm.MOD, vm.SMOD, vm.ADDMOD, vm.MULMOD, vm.EXP, vm.SIGNEXTEND,
+	vm.LT, vm.GT, vm.SLT, vm.SGT, vm.EQ, vm.ISZERO, vm.AND, vm.OR, vm.XOR, vm.NOT, vm.BYTE, vm.SHL, vm.SHR, vm.SAR,
+	vm.SHA3,
+	vm.ADDRESS, vm.ORIGIN, vm.CALLER, vm.CALLVALUE, vm.CALLDATALOAD, vm.CALLDATASIZE, vm.CALLDATACOPY,
+	vm.CODESIZE, vm.CODECOPY, vm.GASPRICE, vm.EXTCODESIZE, vm.EXTCODECOPY, vm.RETURNDATASIZE,
+	vm.NUMBER, vm.TIMESTAMP, vm.CHAINID, vm.SELFBALANCE,
+	vm.POP, vm.MLOAD, vm.MSTORE, vm.MSTORE8, vm.SSTORE, vm.JUMP, vm.JUMPI, vm.PC, vm.MSIZE, vm.GAS, vm.JUMPDEST,
+	vm.DUP1, vm.DUP2, vm.DUP3, vm.DUP4, vm.SWAP1, vm.SWAP2, vm.SWAP3, vm.SWAP4,
+	vm.LOG0, vm.LOG1, vm.LOG2,
+	vm.RETURN, vm.REVERT,
+}
+
+// RandomProgram generates a program of the given number of operations, each
+// preceded by small pushes so most of them find their operands on the stack.
+func RandomProgram(rng *rand.Rand, ops int) []byte {
+	var code []byte
+	for i := 0; i < ops; i++ {
+		for j := rng.Intn(4); j > 0; j-- {
+			code = append(code, byte(vm.PUSH1), byte(rng.Intn(64)))
+		}
+		code = append(code, byte(sharedOps[rng.Intn(len(sharedOps))]))
+	}
+	return code
+}
@@ -0,0 +1,61 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package evmdiff
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/celo-org/celo-blockchain/common"
+)
+
+func TestCeloExecutor(t *testing.T) {
+	// SSTORE(0, 1) costs two pushes and a fresh storage slot
+	outcome := CeloExecutor(common.Hex2Bytes("6001600055"), nil, 100000)
+	if outcome.Err != "" {
+		t.Fatalf("execution failed: %s", outcome.Err)
+	}
+	if outcome.GasUsed != 20006 {
+		t.Errorf("gas used mismatch: have %d, want %d", outcome.GasUsed, 20006)
+	}
+	// Random programs must execute deterministically for the outcomes to be diffed
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		code := RandomProgram(rng, 32)
+		if err := Diff(CeloExecutor(code, nil, 100000), CeloExecutor(code, nil, 100000)); err != nil {
+			t.Fatalf("program %x executed differently: %v", code, err)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	outcome := Outcome{Ret: []byte{1}, GasUsed: 21000, Root: common.HexToHash("0x01"), Logs: 1}
+	if err := Diff(outcome, outcome); err != nil {
+		t.Errorf("identical outcomes diverged: %v", err)
+	}
+	for _, diverged := range []Outcome{
+		{Ret: []byte{1}, GasUsed: 21000, Root: common.HexToHash("0x01"), Logs: 1, Err: "out of gas"},
+		{Ret: []byte{1}, GasUsed: 22000, Root: common.HexToHash("0x01"), Logs: 1},
+		{Ret: []byte{2}, GasUsed: 21000, Root: common.HexToHash("0x01"), Logs: 1},
+		{Ret: []byte{1}, GasUsed: 21000, Root: common.HexToHash("0x02"), Logs: 1},
+		{Ret: []byte{1}, GasUsed: 21000, Root: common.HexToHash("0x01"), Logs: 2},
+	} {
+		if err := Diff(outcome, diverged); err == nil {
+			t.Errorf("divergence from %+v not reported", diverged)
+		}
+	}
+}
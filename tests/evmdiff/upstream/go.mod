@@ -0,0 +1,12 @@
+module github.com/celo-org/celo-blockchain/tests/evmdiff/upstream
+
+go 1.13
+
+require (
+	github.com/celo-org/celo-blockchain v0.0.0
+	github.com/ethereum/go-ethereum v1.9.25
+)
+
+replace github.com/celo-org/celo-blockchain => ../../..
+
+replace golang.org/x/mobile => github.com/celo-org/mobile v0.0.0-20210324213558-66ac87d7fb95
@@ -0,0 +1,72 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package upstream executes the evmdiff programs with the upstream go-ethereum
+// EVM. It is a separate module, so that the upstream release is only required
+// to run the differential tests.
+package upstream
+
+import (
+	"math/big"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/tests/evmdiff"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Executor executes the code with the upstream go-ethereum EVM, with the
+// Istanbul rules.
+func Executor(code, input []byte, gas uint64) evmdiff.Outcome {
+	addr := ethcommon.Address(evmdiff.Address)
+	statedb, _ := state.New(ethcommon.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.CreateAccount(addr)
+	statedb.SetCode(addr, code)
+
+	ret, leftOverGas, err := runtime.Call(addr, input, &runtime.Config{
+		ChainConfig: upstreamChainConfig,
+		Origin:      ethcommon.Address(evmdiff.Origin),
+		BlockNumber: evmdiff.BlockNumber,
+		Time:        evmdiff.BlockTime,
+		GasLimit:    gas,
+		State:       statedb,
+	})
+	outcome := evmdiff.Outcome{
+		Ret:     ret,
+		GasUsed: gas - leftOverGas,
+		Root:    common.Hash(statedb.IntermediateRoot(true)),
+		Logs:    len(statedb.Logs()),
+	}
+	if err != nil {
+		outcome.Err = err.Error()
+	}
+	return outcome
+}
+
+var upstreamChainConfig = &params.ChainConfig{
+	ChainID:             big.NewInt(1),
+	HomesteadBlock:      new(big.Int),
+	EIP150Block:         new(big.Int),
+	EIP155Block:         new(big.Int),
+	EIP158Block:         new(big.Int),
+	ByzantiumBlock:      new(big.Int),
+	ConstantinopleBlock: new(big.Int),
+	PetersburgBlock:     new(big.Int),
+	IstanbulBlock:       new(big.Int),
+}
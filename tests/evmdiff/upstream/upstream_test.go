@@ -0,0 +1,98 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package upstream
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/core/vm"
+	"github.com/celo-org/celo-blockchain/tests"
+	"github.com/celo-org/celo-blockchain/tests/evmdiff"
+	ethvm "github.com/ethereum/go-ethereum/core/vm"
+	ethtests "github.com/ethereum/go-ethereum/tests"
+)
+
+var stateTestDir = filepath.Join("..", "..", "testdata", "GeneralStateTests")
+
+func TestDifferentialPrograms(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		code := evmdiff.RandomProgram(rng, 1+rng.Intn(64))
+		input := make([]byte, rng.Intn(64))
+		rng.Read(input)
+
+		if err := evmdiff.Diff(evmdiff.CeloExecutor(code, input, 1000000), Executor(code, input, 1000000)); err != nil {
+			t.Errorf("program %x with input %x diverged: %v", code, input, err)
+		}
+	}
+}
+
+func TestDifferentialStateTests(t *testing.T) {
+	if _, err := os.Stat(stateTestDir); err != nil {
+		t.Skipf("state tests not found: %v", err)
+	}
+	err := filepath.Walk(stateTestDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !strings.HasSuffix(path, ".json") {
+			return err
+		}
+		// Time consuming and memory hungry tests are left out, as in the state tests
+		if strings.Contains(path, "stTimeConsuming") || strings.Contains(path, "static_Call1MB") {
+			return nil
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var (
+			celoTests     map[string]tests.StateTest
+			upstreamTests map[string]ethtests.StateTest
+		)
+		if err := json.Unmarshal(data, &celoTests); err != nil {
+			return err
+		}
+		if err := json.Unmarshal(data, &upstreamTests); err != nil {
+			return err
+		}
+		for name, test := range celoTests {
+			upstreamTest := upstreamTests[name]
+			upstreamSubtests := upstreamTest.Subtests()
+			for i, subtest := range test.Subtests() {
+				if subtest.Fork != "Istanbul" {
+					continue
+				}
+				_, _, root, err := test.RunNoVerify(subtest, vm.Config{}, false)
+				_, _, upstreamRoot, upstreamErr := upstreamTest.RunNoVerify(upstreamSubtests[i], ethvm.Config{}, false)
+				if (err == nil) != (upstreamErr == nil) {
+					t.Errorf("%s/%s/%d: error %v, %v upstream", path, name, subtest.Index, err, upstreamErr)
+				} else if root != common.Hash(upstreamRoot) {
+					t.Errorf("%s/%s/%d: state root %x, %x upstream", path, name, subtest.Index, root, upstreamRoot)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package celotx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/core"
+	"github.com/celo-org/celo-blockchain/core/rawdb"
+	"github.com/celo-org/celo-blockchain/core/state"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/core/vm"
+	"github.com/celo-org/celo-blockchain/core/vm/vmcontext"
+	"github.com/celo-org/celo-blockchain/params"
+)
+
+var (
+	sender      = common.HexToAddress("0x1000000000000000000000000000000000000001")
+	recipient   = common.HexToAddress("0x1000000000000000000000000000000000000002")
+	coinbase    = common.HexToAddress("0x1000000000000000000000000000000000000003")
+	gateway     = common.HexToAddress("0x1000000000000000000000000000000000000004")
+	feeCurrency = common.HexToAddress("0x1000000000000000000000000000000000000005")
+
+	// feeCurrencyCode returns the value of the first storage slot to any call,
+	// standing for the balance of the fee payer, and accepts the gas fee debits
+	// and credits.
+	feeCurrencyCode = []byte{
+		0x60, 0x00, 0x54, // SLOAD(0)
+		0x60, 0x00, 0x52, // MSTORE(0)
+		0x60, 0x20, 0x60, 0x00, 0xf3, // RETURN(0, 32)
+	}
+)
+
+// fuzzInput reads the fields of the fuzzed message from the input.
+type fuzzInput struct {
+	*bytes.Reader
+}
+
+func (in fuzzInput) byte() byte {
+	b, _ := in.ReadByte()
+	return b
+}
+
+func (in fuzzInput) uint64() uint64 {
+	var buf [8]byte
+	in.Read(buf[:])
+	return binary.BigEndian.Uint64(buf[:])
+}
+
+func (in fuzzInput) bigInt(size int) *big.Int {
+	buf := make([]byte, size)
+	in.Read(buf)
+	return new(big.Int).SetBytes(buf)
+}
+
+// Fuzz applies the fuzzed message to a state holding a fee currency, checking
+// the Celo specific intrinsic gas and fee accounting.
+func Fuzz(input []byte) int {
+	if len(input) < 32 || len(input) > 4096 {
+		return -1
+	}
+	in := fuzzInput{bytes.NewReader(input)}
+
+	flags := in.byte()
+	var (
+		useFeeCurrency = flags&0x01 != 0
+		useGateway     = flags&0x02 != 0
+		creation       = flags&0x04 != 0
+		eHardfork      = flags&0x08 != 0
+		gas            = in.uint64() % params.DefaultGasLimit
+		gasPrice       = in.bigInt(4)
+		value          = in.bigInt(8)
+		gatewayFee     = in.bigInt(8)
+		balance        = in.bigInt(12)
+		data           = make([]byte, int(in.byte())%128)
+	)
+	in.Read(data)
+
+	var currency, gatewayRecipient, to *common.Address
+	if useFeeCurrency {
+		currency = &feeCurrency
+	}
+	if useGateway {
+		gatewayRecipient = &gateway
+	}
+	if !creation {
+		to = &recipient
+	}
+	checkIntrinsicGas(data, creation, currency, gatewayRecipient)
+
+	config := *params.IstanbulTestChainConfig
+	if eHardfork {
+		config.DonutBlock, config.EBlock = big.NewInt(0), big.NewInt(0)
+	}
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.SetBalance(sender, balance)
+	statedb.SetCode(feeCurrency, feeCurrencyCode)
+	statedb.SetState(feeCurrency, common.Hash{}, common.BigToHash(balance))
+
+	header := &types.Header{Number: big.NewInt(1), Coinbase: coinbase, Time: 1}
+	msg := types.NewMessage(sender, to, 0, value, gas, gasPrice, currency, gatewayRecipient, gatewayFee, data, false, true)
+	evm := vm.NewEVM(core.NewEVMContext(msg, header, nil, &coinbase), statedb, &config, vm.Config{})
+	vmRunner := &vmcontext.SharedEVMRunner{EVM: evm}
+
+	before := nativeSupply(statedb)
+	result, err := core.ApplyMessage(evm, msg, new(core.GasPool).AddGas(params.DefaultGasLimit), vmRunner)
+	if err != nil {
+		return 0
+	}
+	if result.UsedGas > gas {
+		panic(fmt.Sprintf("used %d gas out of %d", result.UsedGas, gas))
+	}
+	// Contract creations run arbitrary code, which may move the value anywhere
+	if creation {
+		return 1
+	}
+	// Fees in the native currency move between the accounts, and the ones in the
+	// fee currency don't touch the native balances
+	if after := nativeSupply(statedb); before.Cmp(after) != 0 {
+		panic(fmt.Sprintf("native supply changed from %v to %v", before, after))
+	}
+	if useFeeCurrency && result.Err == nil {
+		if have, want := statedb.GetBalance(sender), new(big.Int).Sub(balance, value); have.Cmp(want) != 0 {
+			panic(fmt.Sprintf("native balance %v after paying fees in another currency, want %v", have, want))
+		}
+	}
+	return 1
+}
+
+// checkIntrinsicGas checks the Celo specific costs are added on top of the
+// Ethereum intrinsic gas.
+func checkIntrinsicGas(data []byte, creation bool, currency, gatewayRecipient *common.Address) {
+	schedule := &params.IntrinsicGasSchedule{AlternativeFeeCurrency: params.IntrinsicGasForAlternativeFeeCurrency, GatewayFee: 1000}
+
+	base, err := core.IntrinsicGas(data, creation, nil, nil, schedule.AlternativeFeeCurrency, schedule, true)
+	if err != nil {
+		return
+	}
+	gas, err := core.IntrinsicGas(data, creation, currency, gatewayRecipient, schedule.AlternativeFeeCurrency, schedule, true)
+	if err != nil {
+		panic(fmt.Sprintf("intrinsic gas overflow with Celo costs: %v", err))
+	}
+	want := base
+	if currency != nil {
+		want += schedule.AlternativeFeeCurrency
+	}
+	if gatewayRecipient != nil {
+		want += schedule.GatewayFee
+	}
+	if gas != want {
+		panic(fmt.Sprintf("intrinsic gas %d, want %d", gas, want))
+	}
+}
+
+// nativeSupply returns the native balance held by the accounts of the message.
+func nativeSupply(statedb *state.StateDB) *big.Int {
+	supply := new(big.Int)
+	for _, addr := range []common.Address{sender, recipient, coinbase, gateway, feeCurrency} {
+		supply.Add(supply, statedb.GetBalance(addr))
+	}
+	return supply
+}
@@ -0,0 +1,59 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package celotx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// seedInput encodes the fields read by Fuzz, with an empty payload.
+func seedInput(flags byte, gas uint64, gasPrice, value, gatewayFee, balance []byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte(flags)
+	binary.Write(&buf, binary.BigEndian, gas)
+	for _, field := range []struct {
+		val  []byte
+		size int
+	}{{gasPrice, 4}, {value, 8}, {gatewayFee, 8}, {balance, 12}} {
+		buf.Write(make([]byte, field.size-len(field.val)))
+		buf.Write(field.val)
+	}
+	buf.WriteByte(0)
+	return buf.Bytes()
+}
+
+// FuzzCeloTx runs the fuzzer with the native Go fuzzing engine, for instance
+// go test -fuzz FuzzCeloTx ./tests/fuzzers/celotx
+func FuzzCeloTx(f *testing.F) {
+	// Native and fee currency transfers, with and without gateway fee
+	for _, flags := range []byte{0x00, 0x01, 0x02, 0x03, 0x09, 0x0b} {
+		f.Add(seedInput(flags, 100000, []byte{0x01}, []byte{0x10}, []byte{0x05}, []byte{0x01, 0x00, 0x00, 0x00, 0x00, 0x00}))
+	}
+	f.Fuzz(func(t *testing.T, input []byte) {
+		Fuzz(input)
+	})
+}
+
+func TestSeedsApplied(t *testing.T) {
+	for _, flags := range []byte{0x00, 0x01, 0x02, 0x03, 0x09, 0x0b} {
+		if Fuzz(seedInput(flags, 100000, []byte{0x01}, []byte{0x10}, []byte{0x05}, []byte{0x01, 0x00, 0x00, 0x00, 0x00, 0x00})) != 1 {
+			t.Errorf("seed with flags %#x not applied", flags)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package contractcomm
+
+import (
+	"math/big"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/contracts/blockchain_parameters"
+	"github.com/celo-org/celo-blockchain/contracts/currency"
+	"github.com/celo-org/celo-blockchain/contracts/election"
+	"github.com/celo-org/celo-blockchain/contracts/epoch_rewards"
+	"github.com/celo-org/celo-blockchain/contracts/freezer"
+	gpm "github.com/celo-org/celo-blockchain/contracts/gasprice_minimum"
+	"github.com/celo-org/celo-blockchain/contracts/random"
+	"github.com/celo-org/celo-blockchain/contracts/reserve"
+	"github.com/celo-org/celo-blockchain/contracts/slashing"
+	"github.com/celo-org/celo-blockchain/contracts/validators"
+	"github.com/celo-org/celo-blockchain/core/vm"
+	"github.com/celo-org/celo-blockchain/params"
+)
+
+var (
+	account  = common.HexToAddress("0x2000000000000000000000000000000000000001")
+	contract = common.HexToAddress("0x2000000000000000000000000000000000000002")
+)
+
+// outputRunner returns the fuzzed output to any call of the core contracts,
+// which the registry resolves to a fixed contract.
+type outputRunner struct {
+	output []byte
+}
+
+func (r *outputRunner) Execute(recipient common.Address, input []byte, gas uint64, value *big.Int) ([]byte, error) {
+	return r.Query(recipient, input, gas)
+}
+
+func (r *outputRunner) ExecuteFrom(sender, recipient common.Address, input []byte, gas uint64, value *big.Int) ([]byte, error) {
+	return r.Query(recipient, input, gas)
+}
+
+func (r *outputRunner) Query(recipient common.Address, input []byte, gas uint64) ([]byte, error) {
+	if recipient == params.RegistrySmartContractAddress {
+		return common.LeftPadBytes(contract.Bytes(), 32), nil
+	}
+	return r.output, nil
+}
+
+func (r *outputRunner) StopGasMetering()  {}
+func (r *outputRunner) StartGasMetering() {}
+
+// Fuzz decodes the fuzzed output as the result of the calls the node makes to
+// the core contracts, which must fail gracefully on malformed outputs.
+func Fuzz(input []byte) int {
+	if len(input) > 16*1024 {
+		return -1
+	}
+	var vmRunner vm.EVMRunner = &outputRunner{output: input}
+
+	currency.GetBalanceOf(vmRunner, account, contract)
+	currency.CurrencyWhitelist(vmRunner)
+	currency.GetExchangeRate(vmRunner, &contract)
+	gpm.GetGasPriceMinimum(vmRunner, &contract)
+	blockchain_parameters.GetBlockGasLimitOrDefault(vmRunner)
+	blockchain_parameters.GetLookbackWindow(vmRunner)
+
+	election.GetElectedValidators(vmRunner)
+	election.ElectNValidatorSigners(vmRunner, 1)
+	election.DistributeEpochRewards(vmRunner, []common.Address{account}, big.NewInt(1), nil)
+	validators.RetrieveRegisteredValidators(vmRunner)
+	validators.GetValidator(vmRunner, account)
+	if history, err := validators.GetMembershipHistory(vmRunner, account); err == nil {
+		history.MembershipAt(1)
+	}
+	epoch_rewards.CalculateTargetEpochRewards(vmRunner)
+	random.GetLastCommitment(vmRunner, account)
+	reserve.ComputeTobinTax(vmRunner, account, big.NewInt(1))
+	slashing.GetDowntimeSlashingIncentives(vmRunner)
+	slashing.GetSlashableDowntime(vmRunner)
+	freezer.IsFrozen(vmRunner, params.ElectionRegistryId)
+	return 1
+}
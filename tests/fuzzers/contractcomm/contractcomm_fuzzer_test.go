@@ -0,0 +1,45 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package contractcomm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/celo-org/celo-blockchain/common"
+)
+
+// FuzzContractComm runs the fuzzer with the native Go fuzzing engine, for
+// instance go test -fuzz FuzzContractComm ./tests/fuzzers/contractcomm
+func FuzzContractComm(f *testing.F) {
+	word := func(n int64) []byte { return common.LeftPadBytes(big.NewInt(n).Bytes(), 32) }
+
+	f.Add([]byte{})
+	f.Add(word(1))
+	f.Add(append(word(1), word(2)...))
+	// Dynamic array of a single address
+	f.Add(append(append(word(32), word(1)...), common.LeftPadBytes(account.Bytes(), 32)...))
+	// Pair of dynamic arrays of different lengths
+	var mismatched []byte
+	for _, w := range []int64{128, 224, 0, 0, 2, 0, 0, 1, 5} {
+		mismatched = append(mismatched, word(w)...)
+	}
+	f.Add(mismatched)
+	f.Fuzz(func(t *testing.T, input []byte) {
+		Fuzz(input)
+	})
+}
@@ -55,7 +55,7 @@ func (tt *TransactionTest) Run(config *params.ChainConfig) error {
 			return nil, nil, err
 		}
 		// Intrinsic gas
-		requiredGas, err := core.IntrinsicGas(tx.Data(), tx.To() == nil, nil, 0, false)
+		requiredGas, err := core.IntrinsicGas(tx.Data(), tx.To() == nil, nil, nil, 0, params.DefaultIntrinsicGasSchedule, false)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -0,0 +1,253 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package upgrades watches the Governance contract for approved hard fork
+// proposals, and warns when this version is not configured to activate them.
+package upgrades
+
+import (
+	"context"
+	"math/big"
+	"net/url"
+	"sync"
+
+	"github.com/celo-org/celo-blockchain/common/hexutil"
+	"github.com/celo-org/celo-blockchain/contracts/governance"
+	"github.com/celo-org/celo-blockchain/core"
+	"github.com/celo-org/celo-blockchain/core/state"
+	"github.com/celo-org/celo-blockchain/core/types"
+	"github.com/celo-org/celo-blockchain/core/vm"
+	"github.com/celo-org/celo-blockchain/event"
+	"github.com/celo-org/celo-blockchain/log"
+	"github.com/celo-org/celo-blockchain/node"
+	"github.com/celo-org/celo-blockchain/params"
+	"github.com/celo-org/celo-blockchain/rpc"
+)
+
+const (
+	// chainHeadChanSize is the size of channel listening to ChainHeadEvent.
+	chainHeadChanSize = 10
+
+	// refreshInterval is the number of blocks between two lookups of the proposals.
+	refreshInterval = 100
+
+	// Query parameters of the description URL of a proposal, carrying the name and
+	// activation block of the hard fork it schedules.
+	hardforkParam = "hardfork"
+	blockParam    = "block"
+)
+
+// backend encompasses the functionality needed to look up the proposals
+type backend interface {
+	SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription
+	CurrentHeader() *types.Header
+	StateAndHeaderByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, error)
+	NewEVMRunner(ctx context.Context, header *types.Header, state vm.StateDB) vm.EVMRunner
+	ChainConfig() *params.ChainConfig
+}
+
+// PendingHardfork is a hard fork scheduled by an approved governance proposal,
+// which is not activated yet.
+type PendingHardfork struct {
+	ProposalID      *hexutil.Big `json:"proposalId"`
+	Stage           string       `json:"stage"`
+	DescriptionURL  string       `json:"descriptionUrl"`
+	Name            string       `json:"name"`
+	Block           *hexutil.Big `json:"block"`           // Activation block proposed
+	Known           bool         `json:"known"`           // Whether this version implements the fork
+	ConfiguredBlock *hexutil.Big `json:"configuredBlock"` // Activation block configured in this version, nil if none
+}
+
+// Scheduled returns whether this version activates the fork at the proposed block.
+func (h *PendingHardfork) Scheduled() bool {
+	return h.Known && h.ConfiguredBlock != nil && h.ConfiguredBlock.ToInt().Cmp(h.Block.ToInt()) == 0
+}
+
+// Watcher periodically looks up the approved hard fork proposals, and warns
+// about the ones this version does not schedule.
+type Watcher struct {
+	backend backend
+
+	lock    sync.RWMutex
+	pending []*PendingHardfork
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates a watcher and registers it in the node, along with its API.
+func New(stack *node.Node, backend backend) *Watcher {
+	w := &Watcher{
+		backend: backend,
+		pending: make([]*PendingHardfork, 0),
+		quit:    make(chan struct{}),
+	}
+	stack.RegisterAPIs([]rpc.API{{
+		Namespace: "celo",
+		Version:   "1.0",
+		Service:   &PublicUpgradesAPI{w},
+		Public:    true,
+	}})
+	stack.RegisterLifecycle(w)
+	return w
+}
+
+// Start implements node.Lifecycle, starting to watch the proposals.
+func (w *Watcher) Start() error {
+	headCh := make(chan core.ChainHeadEvent, chainHeadChanSize)
+	headSub := w.backend.SubscribeChainHeadEvent(headCh)
+
+	w.wg.Add(1)
+	go w.loop(headCh, headSub)
+	return nil
+}
+
+// Stop implements node.Lifecycle, terminating the watcher.
+func (w *Watcher) Stop() error {
+	close(w.quit)
+	w.wg.Wait()
+	return nil
+}
+
+func (w *Watcher) loop(headCh chan core.ChainHeadEvent, headSub event.Subscription) {
+	defer w.wg.Done()
+	defer headSub.Unsubscribe()
+
+	w.refresh(w.backend.CurrentHeader())
+	for {
+		select {
+		case ev := <-headCh:
+			if ev.Block.NumberU64()%refreshInterval == 0 {
+				w.refresh(ev.Block.Header())
+			}
+		case <-headSub.Err():
+			return
+		case <-w.quit:
+			return
+		}
+	}
+}
+
+// refresh looks up the pending hard forks as of the given header.
+func (w *Watcher) refresh(head *types.Header) {
+	statedb, header, err := w.backend.StateAndHeaderByNumberOrHash(context.Background(), rpc.BlockNumberOrHashWithHash(head.Hash(), false))
+	if err != nil {
+		log.Debug("Failed to look up hard fork proposals", "number", head.Number, "err", err)
+		return
+	}
+	pending, err := pendingHardforks(w.backend.NewEVMRunner(context.Background(), header, statedb), w.backend.ChainConfig(), header.Number)
+	if err != nil {
+		log.Debug("Failed to look up hard fork proposals", "number", header.Number, "err", err)
+		return
+	}
+	for _, fork := range pending {
+		switch {
+		case !fork.Known:
+			log.Warn("Approved hard fork unknown to this version, upgrade required", "name", fork.Name, "block", fork.Block, "proposal", fork.ProposalID, "url", fork.DescriptionURL)
+		case !fork.Scheduled():
+			log.Warn("Approved hard fork not scheduled at the proposed block, upgrade required", "name", fork.Name, "block", fork.Block, "configured", fork.ConfiguredBlock, "proposal", fork.ProposalID, "url", fork.DescriptionURL)
+		}
+	}
+	w.lock.Lock()
+	w.pending = pending
+	w.lock.Unlock()
+}
+
+// Pending returns the pending hard forks found at the last lookup.
+func (w *Watcher) Pending() []*PendingHardfork {
+	w.lock.RLock()
+	defer w.lock.RUnlock()
+	return w.pending
+}
+
+// pendingHardforks returns the hard forks scheduled after the given block by the
+// dequeued proposals which were approved and have not expired.
+func pendingHardforks(vmRunner vm.EVMRunner, config *params.ChainConfig, number *big.Int) ([]*PendingHardfork, error) {
+	dequeue, err := governance.GetDequeue(vmRunner)
+	if err != nil {
+		return nil, err
+	}
+	pending := make([]*PendingHardfork, 0)
+	for _, id := range dequeue {
+		if id.Sign() == 0 {
+			continue
+		}
+		stage, err := governance.GetProposalStage(vmRunner, id)
+		if err != nil {
+			return nil, err
+		}
+		if stage != governance.StageReferendum && stage != governance.StageExecution {
+			continue
+		}
+		approved, err := governance.IsApproved(vmRunner, id)
+		if err != nil {
+			return nil, err
+		}
+		if !approved {
+			continue
+		}
+		proposal, err := governance.GetProposal(vmRunner, id)
+		if err != nil {
+			return nil, err
+		}
+		name, block, ok := parseHardforkMetadata(proposal.DescriptionURL)
+		if !ok || block.Cmp(number) <= 0 {
+			continue
+		}
+		configured, known := config.CeloHardforkBlock(name)
+		pending = append(pending, &PendingHardfork{
+			ProposalID:      (*hexutil.Big)(id),
+			Stage:           stage.String(),
+			DescriptionURL:  proposal.DescriptionURL,
+			Name:            name,
+			Block:           (*hexutil.Big)(block),
+			Known:           known,
+			ConfiguredBlock: (*hexutil.Big)(configured),
+		})
+	}
+	return pending, nil
+}
+
+// parseHardforkMetadata extracts the name and activation block of the hard fork
+// from the query parameters of a proposal description URL, for instance
+// https://github.com/celo-org/celo-proposals/blob/master/CGPs/0042.md?hardfork=e&block=1000000
+func parseHardforkMetadata(descriptionURL string) (string, *big.Int, bool) {
+	u, err := url.Parse(descriptionURL)
+	if err != nil {
+		return "", nil, false
+	}
+	query := u.Query()
+	name := query.Get(hardforkParam)
+	if name == "" {
+		return "", nil, false
+	}
+	block, ok := new(big.Int).SetString(query.Get(blockParam), 10)
+	if !ok || block.Sign() <= 0 {
+		return "", nil, false
+	}
+	return name, block, true
+}
+
+// PublicUpgradesAPI provides an API to access the pending hard forks.
+type PublicUpgradesAPI struct {
+	w *Watcher
+}
+
+// PendingHardforks returns the hard forks scheduled by approved governance
+// proposals which are not activated yet, as found at the last lookup.
+func (api *PublicUpgradesAPI) PendingHardforks() []*PendingHardfork {
+	return api.w.Pending()
+}
@@ -0,0 +1,122 @@
+// Copyright 2021 The celo Authors
+// This file is part of the celo library.
+//
+// The celo library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The celo library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the celo library. If not, see <http://www.gnu.org/licenses/>.
+
+package upgrades
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/contracts/abis"
+	"github.com/celo-org/celo-blockchain/contracts/governance"
+	"github.com/celo-org/celo-blockchain/contracts/testutil"
+	"github.com/celo-org/celo-blockchain/params"
+	. "github.com/onsi/gomega"
+)
+
+type testProposal struct {
+	stage          governance.Stage
+	approved       bool
+	descriptionURL string
+}
+
+type governanceMock struct {
+	dequeue   []*big.Int
+	proposals map[uint64]testProposal
+}
+
+func (m *governanceMock) GetDequeue() []*big.Int {
+	return m.dequeue
+}
+
+func (m *governanceMock) GetProposalStage(id *big.Int) uint8 {
+	return uint8(m.proposals[id.Uint64()].stage)
+}
+
+func (m *governanceMock) IsApproved(id *big.Int) bool {
+	return m.proposals[id.Uint64()].approved
+}
+
+func (m *governanceMock) GetProposal(id *big.Int) (common.Address, *big.Int, *big.Int, *big.Int, string) {
+	return common.ZeroAddress, common.Big0, common.Big0, common.Big1, m.proposals[id.Uint64()].descriptionURL
+}
+
+func TestParseHardforkMetadata(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	name, block, ok := parseHardforkMetadata("https://github.com/celo-org/celo-proposals/blob/master/CGPs/0042.md?hardfork=e&block=1000")
+	g.Expect(ok).To(BeTrue())
+	g.Expect(name).To(Equal("e"))
+	g.Expect(block).To(Equal(big.NewInt(1000)))
+
+	for _, url := range []string{
+		"https://github.com/celo-org/celo-proposals/blob/master/CGPs/0042.md",
+		"https://example.com/?hardfork=e",
+		"https://example.com/?hardfork=e&block=soon",
+		"https://example.com/?block=1000",
+		"%zz",
+	} {
+		_, _, ok := parseHardforkMetadata(url)
+		g.Expect(ok).To(BeFalse(), url)
+	}
+}
+
+func TestPendingHardforks(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	mock := &governanceMock{
+		dequeue: []*big.Int{big.NewInt(1), big.NewInt(0), big.NewInt(2), big.NewInt(3), big.NewInt(4), big.NewInt(5), big.NewInt(6)},
+		proposals: map[uint64]testProposal{
+			1: {governance.StageExecution, true, "https://example.com/1.md?hardfork=e&block=1000"},
+			2: {governance.StageReferendum, true, "https://example.com/2.md?hardfork=f&block=2000"},
+			3: {governance.StageReferendum, false, "https://example.com/3.md?hardfork=e&block=1000"},
+			4: {governance.StageExpiration, true, "https://example.com/4.md?hardfork=e&block=1000"},
+			5: {governance.StageExecution, true, "https://example.com/5.md?hardfork=donut&block=10"},
+			6: {governance.StageExecution, true, "https://example.com/6.md"},
+		},
+	}
+	runner := testutil.NewMockEVMRunner()
+	registry := testutil.NewRegistryMock()
+	runner.RegisterContract(params.RegistrySmartContractAddress, registry)
+	contract := testutil.NewContractMock(abis.Governance, mock)
+	governanceAddress := common.HexToAddress("0x01")
+	registry.AddContract(params.GovernanceRegistryId, governanceAddress)
+	runner.RegisterContract(governanceAddress, &contract)
+
+	config := &params.ChainConfig{DonutBlock: big.NewInt(10), EBlock: big.NewInt(1000)}
+	pending, err := pendingHardforks(runner, config, big.NewInt(100))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(pending).To(HaveLen(2))
+
+	g.Expect(pending[0].ProposalID.ToInt()).To(Equal(big.NewInt(1)))
+	g.Expect(pending[0].Stage).To(Equal("execution"))
+	g.Expect(pending[0].Known).To(BeTrue())
+	g.Expect(pending[0].Scheduled()).To(BeTrue())
+
+	g.Expect(pending[1].ProposalID.ToInt()).To(Equal(big.NewInt(2)))
+	g.Expect(pending[1].Name).To(Equal("f"))
+	g.Expect(pending[1].Known).To(BeFalse())
+	g.Expect(pending[1].Scheduled()).To(BeFalse())
+
+	// The binary lacking the fork block
+	config.EBlock = nil
+	pending, err = pendingHardforks(runner, config, big.NewInt(100))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(pending[0].Known).To(BeTrue())
+	g.Expect(pending[0].ConfiguredBlock).To(BeNil())
+	g.Expect(pending[0].Scheduled()).To(BeFalse())
+}